@@ -18,7 +18,7 @@ const (
 )
 
 var (
-	buildPlatforms = []dagger.Platform{"linux/amd64", "linux/arm64", "darwin/arm64"}
+	buildPlatforms = []dagger.Platform{"linux/amd64", "linux/arm64", "darwin/arm64", "windows/amd64"}
 )
 
 // Build an git-remote-oci executable.
@@ -47,7 +47,7 @@ func (g *Gnoci) BuildGit(ctx context.Context,
 			Trimpath: true,
 			Ldflags:  ldflags,
 		}).
-		WithName(gitExecName)
+		WithName(execName(gitExecName, platform))
 }
 
 // Build an git-lfs-remote-oci executable.
@@ -76,7 +76,7 @@ func (g *Gnoci) BuildGitLFS(ctx context.Context,
 			Trimpath: true,
 			Ldflags:  ldflags,
 		}).
-		WithName(gitLFSExecName)
+		WithName(execName(gitLFSExecName, platform))
 }
 
 // Build git-remote-oci binaries for multiple platforms, nested in directories
@@ -91,7 +91,7 @@ func (g *Gnoci) BuildGitPlatforms(ctx context.Context,
 	// +optional
 	version string,
 	// build platforms
-	// +default=["linux/amd64","linux/arm64","darwin/arm64"]
+	// +default=["linux/amd64","linux/arm64","darwin/arm64","windows/amd64"]
 	platforms []dagger.Platform,
 ) *dagger.Directory {
 	var mux sync.Mutex
@@ -109,7 +109,7 @@ func (g *Gnoci) BuildGitPlatforms(ctx context.Context,
 			mux.Lock()
 			defer mux.Unlock()
 			builds = builds.WithFile(
-				path.Join(strings.ReplaceAll(string(platform), "/", "-"), gitExecName),
+				path.Join(strings.ReplaceAll(string(platform), "/", "-"), execName(gitExecName, platform)),
 				bin)
 
 			return nil
@@ -132,7 +132,7 @@ func (g *Gnoci) BuildGitLFSPlatforms(ctx context.Context,
 	// +optional
 	version string,
 	// build platforms
-	// +default=["linux/amd64","linux/arm64","darwin/arm64"]
+	// +default=["linux/amd64","linux/arm64","darwin/arm64","windows/amd64"]
 	platforms []dagger.Platform,
 ) *dagger.Directory {
 	var mux sync.Mutex
@@ -150,7 +150,7 @@ func (g *Gnoci) BuildGitLFSPlatforms(ctx context.Context,
 			mux.Lock()
 			defer mux.Unlock()
 			builds = builds.WithFile(
-				path.Join(strings.ReplaceAll(string(platform), "/", "-"), gitLFSExecName),
+				path.Join(strings.ReplaceAll(string(platform), "/", "-"), execName(gitLFSExecName, platform)),
 				bin)
 
 			return nil
@@ -171,7 +171,7 @@ func (g *Gnoci) BuildAllPlatforms(ctx context.Context,
 	// +optional
 	version string,
 	// build platforms
-	// +default=["linux/amd64","linux/arm64","darwin/arm64"]
+	// +default=["linux/amd64","linux/arm64","darwin/arm64","windows/amd64"]
 	platforms []dagger.Platform,
 ) *dagger.Directory {
 	ctx, span := Tracer().Start(ctx, fmt.Sprintf("Building remote helpers for git and git-lfs for %v", platforms))
@@ -192,7 +192,7 @@ func (g *Gnoci) BuildAllPlatforms(ctx context.Context,
 			mux.Lock()
 			defer mux.Unlock()
 			builds = builds.WithFile(
-				path.Join(strings.ReplaceAll(string(platform), "/", "-"), gitExecName),
+				path.Join(strings.ReplaceAll(string(platform), "/", "-"), execName(gitExecName, platform)),
 				bin)
 
 			return nil
@@ -207,7 +207,7 @@ func (g *Gnoci) BuildAllPlatforms(ctx context.Context,
 			mux.Lock()
 			defer mux.Unlock()
 			builds = builds.WithFile(
-				path.Join(strings.ReplaceAll(string(platform), "/", "-"), gitLFSExecName),
+				path.Join(strings.ReplaceAll(string(platform), "/", "-"), execName(gitLFSExecName, platform)),
 				bin)
 
 			return nil
@@ -218,6 +218,15 @@ func (g *Gnoci) BuildAllPlatforms(ctx context.Context,
 	return builds
 }
 
+// execName returns the name of an executable built for platform, with the
+// ".exe" extension Git requires of remote helpers on Windows.
+func execName(name string, platform dagger.Platform) string {
+	if strings.HasPrefix(string(platform), "windows/") {
+		return name + ".exe"
+	}
+	return name
+}
+
 // Initializes a container with Go and the source.
 func (g *Gnoci) goWithSource(src *dagger.Directory) *dagger.GoWithSource {
 	return dag.Go().
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"dagger/gnoci/internal/dagger"
+	"errors"
 	"path/filepath"
 )
 
@@ -23,12 +24,14 @@ func (t *Test) All(ctx context.Context,
 	src *dagger.Directory,
 ) (string, error) {
 	unitResults, unitErr := t.Unit(ctx, src)
+	sha256Results, sha256Err := t.UnitSHA256(ctx, src)
 
 	// TODO: add functional  tests here
 
 	out := "Unit Test Results:\n" + unitResults
+	out += "\nSHA-256 Unit Test Results:\n" + sha256Results
 
-	return out, unitErr // TODO: use errors.Join when functional tests are added
+	return out, errors.Join(unitErr, sha256Err)
 }
 
 // Run unit tests.
@@ -42,6 +45,17 @@ func (t *Test) Unit(ctx context.Context,
 				Stdout(ctx)
 }
 
+// Run unit tests built with the sha256 tag, supporting SHA-256 repositories.
+func (t *Test) UnitSHA256(ctx context.Context,
+	src *dagger.Directory,
+) (string, error) {
+	return dag.Go(). //nolint:wrapcheck
+				WithSource(src).
+				Container().
+				WithExec([]string{"go", "test", "-tags", "sha256", "./..."}).
+				Stdout(ctx)
+}
+
 // Push pushes a git repository to an OCI registry.
 //
 //nolint:wrapcheck
@@ -14,6 +14,7 @@ import (
 	vv "github.com/act3-ai/go-common/pkg/version"
 
 	"github.com/act3-ai/gnoci/cmd/git-lfs-remote-oci/cli"
+	"github.com/act3-ai/gnoci/internal/interrupt"
 )
 
 // getVersionInfo retrieves build info.
@@ -26,7 +27,8 @@ func getVersionInfo() vv.Info {
 }
 
 func main() {
-	ctx := context.Background()
+	// canceled on interrupt, cleaning up before exiting
+	ctx, stop := interrupt.NotifyContext(context.Background())
 
 	info := getVersionInfo()                        // Load the version info from the build
 	root := cli.NewGitLFSRemoteHelper(info.Version) // Create the root command
@@ -60,7 +62,9 @@ func main() {
 	}
 
 	// Run the root command
-	if err := runner.Run(ctx, root, "GNOCI_VERBOSITY"); err != nil {
+	err := runner.Run(ctx, root, "GNOCI_VERBOSITY")
+	stop()
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,14 @@
+// Package cli exports the gnoci command for administering Git repositories
+// in OCI Registries.
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/act3-ai/gnoci/internal/cli"
+)
+
+// NewGnoci creates the base gnoci command.
+func NewGnoci(version string) *cobra.Command {
+	return cli.NewGnociCLI(version)
+}
@@ -0,0 +1,4 @@
+package main
+
+// version is overwritten at link time in the CI build system.
+var version string
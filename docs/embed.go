@@ -21,6 +21,7 @@ var GeneralDocumentation embed.FS
 // APIs is embedded API documentation.
 //
 //go:embed apis/gnoci.act3-ai.io/v1alpha1.md
+//go:embed apis/gnoci.act3-ai.io/v1alpha2.md
 var APIs embed.FS
 
 //go:embed apis/schemas/*.schema.json
@@ -68,6 +69,11 @@ func Embedded(root *cobra.Command) *embedutil.Documentation {
 					"v1alpha1 API Documentation",
 					"apis/gnoci.act3-ai.io/v1alpha1.md",
 					APIs),
+				embedutil.LoadMarkdown(
+					"config-v1alpha2",
+					"v1alpha2 API Documentation",
+					"apis/gnoci.act3-ai.io/v1alpha2.md",
+					APIs),
 			),
 		},
 	}
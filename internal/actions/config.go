@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
+)
+
+// loadConfig loads the first of cfgFiles that exists, converting deprecated
+// v1alpha1 configurations to v1alpha2, then defaults unset fields. Without a
+// configuration file, the defaults are returned.
+func loadConfig(ctx context.Context, scheme *runtime.Scheme, cfgFiles []string) (*v1alpha2.Configuration, error) {
+	slog.DebugContext(ctx, "searching for configuration files", slog.Any("cfgFiles", cfgFiles))
+
+	c := &v1alpha2.Configuration{}
+	codecs := serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+	for _, filename := range cfgFiles {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			slog.DebugContext(ctx, "skipping config file", slog.String("path", filename), slog.String("reason", err.Error()))
+			continue
+		}
+
+		_, gvk, err := codecs.UniversalDecoder().Decode(content, nil, c)
+		if err != nil {
+			return nil, fmt.Errorf("decoding configuration file %s: %w", filename, err)
+		}
+		if gvk != nil && gvk.GroupVersion() == v1alpha1.GroupVersion {
+			slog.WarnContext(ctx, "configuration file uses a deprecated API version, migrate to "+v1alpha2.GroupVersion.String(),
+				slog.String("path", filename), slog.String("apiVersion", gvk.GroupVersion().String()))
+		}
+
+		slog.InfoContext(ctx, "using config file", slog.String("path", filename))
+		break
+	}
+	scheme.Default(c)
+
+	slog.DebugContext(ctx, "using config", slog.Any("configuration", c))
+
+	return c, nil
+}
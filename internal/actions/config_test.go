@@ -0,0 +1,60 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/apis"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
+)
+
+func Test_loadConfig(t *testing.T) {
+	writeConfig := func(t *testing.T, contents string) string {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte(contents), 0o666))
+		return path
+	}
+
+	t.Run("v1alpha2", func(t *testing.T) {
+		path := writeConfig(t, "apiVersion: gnoci.act3-ai.io/v1alpha2\nkind: Configuration\nregistries:\n  127.0.0.1:5000:\n    plainHTTP: true\ntransfer:\n  concurrency: 8\n")
+
+		cfg, err := loadConfig(t.Context(), apis.NewScheme(), []string{filepath.Join(t.TempDir(), "missing.yaml"), path})
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha2.GroupVersion.String(), cfg.APIVersion)
+		assert.True(t, cfg.Registries["127.0.0.1:5000"].PlainHTTP)
+		assert.Equal(t, 8, cfg.Transfer.Concurrency)
+		assert.Equal(t, v1alpha2.DefaultMaxAttempts, cfg.Transfer.Retry.MaxAttempts)
+	})
+
+	t.Run("Deprecated v1alpha1", func(t *testing.T) {
+		path := writeConfig(t, "apiVersion: gnoci.act3-ai.io/v1alpha1\nkind: Configuration\nregistryConfig:\n  registries:\n    127.0.0.1:5000:\n      plainHTTP: true\npush:\n  concurrency: 8\n  atomic: true\nretry:\n  maxAttempts: 2\n")
+
+		cfg, err := loadConfig(t.Context(), apis.NewScheme(), []string{path})
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha2.GroupVersion.String(), cfg.APIVersion)
+		assert.True(t, cfg.Registries["127.0.0.1:5000"].PlainHTTP)
+		assert.Equal(t, 8, cfg.Transfer.Concurrency)
+		assert.Equal(t, 2, cfg.Transfer.Retry.MaxAttempts)
+		assert.True(t, cfg.Push.Atomic)
+	})
+
+	t.Run("Unknown Field", func(t *testing.T) {
+		// v1alpha1 fields are rejected by v1alpha2
+		path := writeConfig(t, "apiVersion: gnoci.act3-ai.io/v1alpha2\nkind: Configuration\nregistryConfig:\n  registries: {}\n")
+
+		_, err := loadConfig(t.Context(), apis.NewScheme(), []string{path})
+		assert.Error(t, err)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := loadConfig(t.Context(), apis.NewScheme(), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha2.DefaultConcurrency, cfg.Transfer.Concurrency)
+		assert.Equal(t, v1alpha2.CompressionNone, cfg.Push.Compression)
+	})
+}
@@ -2,35 +2,102 @@ package actions
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 
 	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/internal/tracing"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content/file"
-	"oras.land/oras-go/v2/registry"
 )
 
 // initRemoteConn initializes intermediary objects used when fetching from or
-// pushing to the OCI remote.
+// pushing to the OCI remote at addr, a registry repository or OCI image
+// layout.
 //
-// It is the caller's responsibility to clean all three return types up.
-func initRemoteConn(ctx context.Context, ref registry.Reference, opts *ociutil.RepositoryOptions) (oras.GraphTarget, string, *file.Store, error) {
-	gt, err := ociutil.NewGraphTarget(ctx, ref, opts)
+// It is the caller's responsibility to clean all three return types up,
+// closing the graph target if it is an [io.Closer].
+func initRemoteConn(ctx context.Context, addr ociutil.Address, opts *ociutil.RepositoryOptions) (oras.GraphTarget, string, *file.Store, error) {
+	gt, err := newGraphTarget(ctx, addr, opts)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("initializing remote graph target: %w", err)
+		return nil, "", nil, err
 	}
 
-	tmpDir := os.TempDir()
-	fstorePath, err := os.MkdirTemp(tmpDir, "GnOCI-fstore-*")
+	fstorePath, fstore, err := newFileStore()
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("creating temporary directory for intermediate OCI file store: %w", err)
+		return nil, "", nil, errors.Join(err, closeGraphTarget(gt))
+	}
+
+	return gt, fstorePath, fstore, nil
+}
+
+// newFileStore initializes an intermediate OCI file store in a new temporary
+// directory. It is the caller's responsibility to close the file store and
+// remove the directory.
+func newFileStore() (string, *file.Store, error) {
+	fstorePath, err := os.MkdirTemp(os.TempDir(), "GnOCI-fstore-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temporary directory for intermediate OCI file store: %w", err)
 	}
 
 	fstore, err := file.New(fstorePath)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("initializing OCI filestore: %w", err)
+		err = fmt.Errorf("initializing OCI filestore: %w", err)
+		return "", nil, errors.Join(err, os.RemoveAll(fstorePath))
 	}
 
-	return gt, fstorePath, fstore, nil
+	return fstorePath, fstore, nil
+}
+
+// newGraphTarget initializes the graph target of addr, a registry repository
+// or OCI image layout. Registries of "oci+http://" addresses are connected to
+// over plain HTTP, regardless of opts. It is the caller's responsibility to close the graph
+// target if it is an [io.Closer].
+func newGraphTarget(ctx context.Context, addr ociutil.Address, opts *ociutil.RepositoryOptions) (oras.GraphTarget, error) {
+	var gt oras.GraphTarget
+	var err error
+	if addr.Layout != "" {
+		gt, err = ociutil.OpenLayout(addr)
+	} else {
+		if addr.PlainHTTP {
+			slog.WarnContext(ctx, "connecting to registry over insecure plain HTTP", slog.String("registry", addr.Ref.Registry))
+			opts.PlainHTTP = true
+		}
+		gt, err = ociutil.NewGraphTarget(ctx, addr.Ref, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("initializing remote graph target: %w", err)
+	}
+
+	return gt, nil
+}
+
+// closeGraphTarget closes gt, if it is an [io.Closer].
+func closeGraphTarget(gt oras.GraphTarget) error {
+	if closer, ok := gt.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("closing OCI remote: %w", err)
+		}
+	}
+	return nil
+}
+
+// initTracing exports spans of the remote helper service, if an OTLP endpoint
+// is configured. Tracing is best effort, failures are only logged. The
+// returned function flushes pending spans.
+func initTracing(ctx context.Context, service, version string) func() {
+	shutdown, err := tracing.Setup(ctx, service, version)
+	if err != nil {
+		slog.WarnContext(ctx, "tracing disabled", slog.String("error", err.Error()))
+		return func() {}
+	}
+	return func() {
+		// spans of an interrupted operation are still flushed
+		if err := shutdown(context.WithoutCancel(ctx)); err != nil {
+			slog.WarnContext(ctx, "flushing trace spans", slog.String("error", err.Error()))
+		}
+	}
 }
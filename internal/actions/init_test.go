@@ -1,11 +1,15 @@
 package actions
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
 	"github.com/act3-ai/gnoci/internal/ociutil"
 	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote/credentials"
 )
@@ -26,7 +30,7 @@ func Test_initRemoteConn(t *testing.T) {
 			RegistryCreds: credentials.NewMemoryStore(),
 		}
 
-		gt, fstorePath, fstore, err := initRemoteConn(t.Context(), testRemote, &expectedOpts)
+		gt, fstorePath, fstore, err := initRemoteConn(t.Context(), ociutil.Address{Ref: testRemote}, &expectedOpts)
 		assert.NoError(t, err)
 		assert.False(t, fstorePath == "")
 		defer func() {
@@ -37,3 +41,28 @@ func Test_initRemoteConn(t *testing.T) {
 		assert.NotNil(t, gt)
 	})
 }
+
+func Test_newGraphTarget(t *testing.T) {
+	t.Run("Plain HTTP Scheme", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+		u, err := url.Parse(srv.URL)
+		assert.NoError(t, err)
+
+		addr, err := ociutil.ParseAddress(ociutil.HTTPScheme + u.Host + "/repo:tag")
+		assert.NoError(t, err)
+		opts := ociutil.RepositoryOptions{
+			UserAgent:     "foo",
+			RegistryCreds: credentials.NewMemoryStore(),
+			Retry:         ociutil.RetryPolicy{MaxAttempts: 1},
+		}
+
+		gt, err := newGraphTarget(t.Context(), addr, &opts)
+		assert.NoError(t, err)
+		assert.True(t, opts.PlainHTTP)
+
+		// reached over plain HTTP, rather than failing the TLS handshake
+		_, err = gt.Resolve(t.Context(), addr.Ref.String())
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+}
@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Output formats of the gnoci admin actions.
+const (
+	// OutputTable writes a human readable table.
+	OutputTable = "table"
+	// OutputJSON writes a JSON document, see [OutputAPIVersion].
+	OutputJSON = "json"
+)
+
+// OutputAPIVersion identifies the schema of JSON output. Fields may be added
+// within a version, while removing or changing fields requires a new version.
+const OutputAPIVersion = "gnoci.act3-ai.io/output/v1"
+
+// errUnsupportedOutput indicates an output format is not supported.
+var errUnsupportedOutput = errors.New("unsupported output format")
+
+// OutputMeta identifies the schema and kind of a JSON output document.
+type OutputMeta struct {
+	// APIVersion is the schema version of the document, [OutputAPIVersion].
+	APIVersion string `json:"apiVersion"`
+	// Kind is the kind of the document, e.g. "LayerList".
+	Kind string `json:"kind"`
+}
+
+// outputMeta returns the [OutputMeta] of a document of kind.
+func outputMeta(kind string) OutputMeta {
+	return OutputMeta{APIVersion: OutputAPIVersion, Kind: kind}
+}
+
+// OutputList is a JSON output document listing items.
+type OutputList[T any] struct {
+	OutputMeta
+
+	// Items are the listed items, empty rather than null if there are none.
+	Items []T `json:"items"`
+}
+
+// outputFormat validates output, defaulting to [OutputTable].
+func outputFormat(output string) (string, error) {
+	switch output {
+	case "":
+		return OutputTable, nil
+	case OutputTable, OutputJSON:
+		return output, nil
+	default:
+		return "", fmt.Errorf("%w: %q", errUnsupportedOutput, output)
+	}
+}
+
+// writeJSON writes v as an indented JSON document.
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding output: %w", err)
+	}
+	return nil
+}
+
+// writeJSONList writes items as an [OutputList] of kind.
+func writeJSONList[T any](out io.Writer, kind string, items []T) error {
+	if items == nil {
+		items = []T{}
+	}
+	return writeJSON(out, OutputList[T]{OutputMeta: outputMeta(kind), Items: items})
+}
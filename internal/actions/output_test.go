@@ -0,0 +1,28 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_outputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr error
+	}{
+		{name: "Default", output: "", want: OutputTable},
+		{name: "Table", output: OutputTable, want: OutputTable},
+		{name: "JSON", output: OutputJSON, want: OutputJSON},
+		{name: "Unsupported", output: "yaml", wantErr: errUnsupportedOutput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := outputFormat(tt.output)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,36 +3,57 @@ package actions
 
 import (
 	"context"
+	"crypto"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
-	gogit "github.com/go-git/go-git/v5"
+	"github.com/opencontainers/go-digest"
 	"k8s.io/apimachinery/pkg/runtime"
-	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/errdef"
 
+	"github.com/act3-ai/gnoci/internal/cache"
 	"github.com/act3-ai/gnoci/internal/cmd"
 	"github.com/act3-ai/gnoci/internal/git"
 	"github.com/act3-ai/gnoci/internal/model"
 	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/internal/scan"
+	"github.com/act3-ai/gnoci/internal/tracing"
 	"github.com/act3-ai/gnoci/pkg/apis"
-	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
 	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
-	"github.com/act3-ai/go-common/pkg/config"
 )
 
+// sourceDateEpochEnv is the environment variable overriding the creation time
+// of reproducible manifests, see https://reproducible-builds.org/specs/source-date-epoch/.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
 // Git represents the base action.
 type Git struct {
 	version   string
 	apiScheme *runtime.Scheme
 	// ConfigFiles contains a list of potential configuration file locations.
 	ConfigFiles []string
+	// Level is the dynamic log level of the helper, set by Git's verbosity
+	// option. A nil Level ignores the option.
+	Level *slog.LevelVar
 
 	comm comms.Communicator
+	opts cmd.Options
+	// write the submodule URL mappings of the remote on fetch
+	submoduleURLs bool
+	// lock the remote on push, if set
+	lockOpts *model.LockOptions
+	// check the registry before the first push, if set
+	preflight func(ctx context.Context) error
 
 	// local repository
 	gitDir string
@@ -45,34 +66,55 @@ type Git struct {
 }
 
 // NewGit creates a new Tool with default values.
-func NewGit(in io.Reader, out io.Writer, gitDir, shortname, address, version string, cfgFiles []string) *Git {
+func NewGit(in io.Reader, out, errOut io.Writer, gitDir, shortname, address, version string, cfgFiles []string) *Git {
 	return &Git{
 		version:     version,
 		apiScheme:   apis.NewScheme(),
 		ConfigFiles: cfgFiles,
 		comm:        comms.NewCommunicator(in, out),
+		opts:        cmd.Options{ProgressOut: errOut, AdviceOut: errOut},
 		gitDir:      gitDir,
 		name:        shortname,
-		address:     strings.TrimPrefix(address, "oci://"),
+		address:     strings.TrimPrefix(address, ociutil.Scheme),
 	}
 }
 
 // Run runs the the primary git-remote-oci action.
-func (action *Git) Run(ctx context.Context) error {
+func (action *Git) Run(ctx context.Context) (err error) {
+	action.opts.Level = action.Level
+
 	cfg, err := action.GetConfig(ctx)
 	if err != nil {
 		return fmt.Errorf("getting configuration: %w", err)
 	}
 
-	parsedRef, err := registry.ParseReference(action.address)
+	addr, err := ociutil.ParseAddressDefaultTag(action.address, cfg.DefaultTag)
 	if err != nil {
-		return fmt.Errorf("invalid reference %s: %w", action.address, err)
+		return fmt.Errorf("parsing remote address: %w", err)
 	}
 
-	gt, fstorePath, fstore, err := initRemoteConn(ctx, parsedRef, repoOptsFromConfig(parsedRef.Host(), cfg))
+	defer initTracing(ctx, ociutil.GitUserAgent, action.version)()
+	ctx, span := tracing.Start(ctx, ociutil.GitUserAgent, tracing.Remote(addr.Ref)...)
+	defer tracing.End(span, &err)
+
+	repoOpts := repoOptsFromConfig(addr.Ref.Host(), cfg)
+	gt, fstorePath, fstore, err := initRemoteConn(ctx, addr, repoOpts)
 	if err != nil {
 		return fmt.Errorf("initializing: %w", err)
 	}
+	if addr.Layout == "" {
+		action.preflight = func(ctx context.Context) error {
+			return ociutil.Ping(ctx, addr.Ref, repoOpts)
+		}
+	}
+	if closer, ok := gt.(io.Closer); ok {
+		// written back only once all commands are handled
+		defer func() {
+			if cerr := closer.Close(); cerr != nil {
+				err = errors.Join(err, fmt.Errorf("closing OCI remote: %w", cerr))
+			}
+		}()
+	}
 	defer func() {
 		if err := os.RemoveAll(fstorePath); err != nil {
 			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
@@ -84,10 +126,43 @@ func (action *Git) Run(ctx context.Context) error {
 		}
 	}()
 
-	action.remote = model.NewModeler(parsedRef, fstore, gt)
+	modelOpts, err := modelOptsFromConfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	modelOpts = append(modelOpts, model.WithCreator(ociutil.GitUserAgent+"/"+action.version))
+	modelOpts = append(modelOpts, registryModelOpts(addr.Ref.Host(), cfg)...)
+
+	lfsGT, err := action.lfsStore(ctx, addr, cfg)
+	if err != nil {
+		return err
+	}
+	if lfsGT != nil {
+		if closer, ok := lfsGT.(io.Closer); ok {
+			defer func() {
+				if cerr := closer.Close(); cerr != nil {
+					err = errors.Join(err, fmt.Errorf("closing LFS OCI remote: %w", cerr))
+				}
+			}()
+		}
+		modelOpts = append(modelOpts, model.WithLFSStore(lfsGT))
+	}
+
+	action.remote = model.NewModeler(addr.Ref, fstore, gt, modelOpts...)
+	if err := applyPushConfig(&action.opts, cfg.Push); err != nil {
+		return err
+	}
+	if err := applyFetchConfig(&action.opts, cfg.Fetch); err != nil {
+		return err
+	}
+	action.submoduleURLs = cfg.Fetch.SubmoduleURLs
+	action.lockOpts = lockOptsFromConfig(cfg.Push.Lock)
 
 	var done bool
 	for !done {
+		if ctx.Err() != nil {
+			return fmt.Errorf("handling commands: %w", context.Cause(ctx))
+		}
 		done, err = action.handleCmd(ctx)
 		if err != nil {
 			return err
@@ -118,7 +193,7 @@ func (action *Git) handleCmd(ctx context.Context) (bool, error) {
 			return false, fmt.Errorf("handling capabilities request: %w", err)
 		}
 	case gittypes.Options:
-		if err := cmd.HandleOption(ctx, action.comm); err != nil {
+		if err := cmd.HandleOption(ctx, action.comm, &action.opts); err != nil {
 			return false, fmt.Errorf("handling option request: %w", err)
 		}
 	case gittypes.List:
@@ -140,6 +215,32 @@ func (action *Git) handleCmd(ctx context.Context) (bool, error) {
 	return false, nil
 }
 
+// lfsStore initializes the graph target of the repository storing LFS files,
+// if configured separately from the remote at addr, such that pushes update
+// its LFS manifests. Returns nil if LFS files are stored with the remote, or
+// the local repository is unavailable.
+func (action *Git) lfsStore(ctx context.Context, addr ociutil.Address, cfg *v1alpha2.Configuration) (oras.GraphTarget, error) {
+	if action.gitDir == "" {
+		return nil, nil
+	}
+	local, err := action.localRepo(ctx)
+	if err != nil {
+		slog.DebugContext(ctx, "local repository unavailable, storing LFS files with the remote", slog.String("error", err.Error()))
+		return nil, nil
+	}
+
+	lfsAddr, ok := lfsStoreAddress(ctx, local, action.name, addr, cfg.DefaultTag)
+	if !ok {
+		return nil, nil
+	}
+	lfsGT, err := newGraphTarget(ctx, lfsAddr, repoOptsFromConfig(lfsAddr.Ref.Host(), cfg))
+	if err != nil {
+		return nil, fmt.Errorf("initializing LFS remote connection: %w", err)
+	}
+
+	return lfsGT, nil
+}
+
 // localRepo opens the local repository if it hasn't been opened already.
 func (action *Git) localRepo(ctx context.Context) (git.Repository, error) {
 	if action.local == nil {
@@ -148,7 +249,7 @@ func (action *Git) localRepo(ctx context.Context) (git.Repository, error) {
 		}
 
 		slog.DebugContext(ctx, "opening local repository")
-		r, err := gogit.PlainOpen(action.gitDir)
+		r, err := git.OpenDir(action.gitDir, os.Getenv(git.EnvWorkTree))
 		if err != nil {
 			return nil, fmt.Errorf("opening local repository: %w", err)
 		}
@@ -169,12 +270,12 @@ func (action *Git) handleList(ctx context.Context) error {
 		}
 	}
 
-	_, err = action.remote.FetchOrDefault(ctx)
-	if err != nil {
+	// listed references only need the config, even when listing for a push
+	if err := action.fetchRemote(ctx, true); err != nil {
 		return err
 	}
 
-	if err := cmd.HandleList(ctx, local, action.remote, action.comm); err != nil {
+	if err := cmd.HandleList(ctx, local, action.remote, action.comm, &action.opts); err != nil {
 		return fmt.Errorf("running list command: %w", err)
 	}
 
@@ -188,62 +289,477 @@ func (action *Git) handlePush(ctx context.Context) error {
 		return err
 	}
 
-	_, err = action.remote.FetchOrDefault(ctx)
-	if err != nil {
+	if !action.opts.DryRun && action.preflight != nil {
+		// fail before transferring anything, rather than mid-push
+		if err := action.preflight(ctx); err != nil {
+			return fmt.Errorf("checking remote: %w", err)
+		}
+		action.preflight = nil
+	}
+
+	if !action.opts.DryRun {
+		unlock, err := lockRemote(ctx, action.remote, action.lockOpts)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	if err := action.fetchRemote(ctx, false); err != nil {
 		return err
 	}
 
-	if err := cmd.HandlePush(ctx, local, action.gitDir, action.remote, action.comm); err != nil {
+	if err := cmd.HandlePush(ctx, local, action.gitDir, action.remote, action.comm, &action.opts); err != nil {
 		return fmt.Errorf("running push commands: %w", err)
 	}
 
 	return nil
 }
 
+// fetchRemote fetches the remote metadata, initializing the remote if it does
+// not exist unless pushes are a dry run. If configOnly, only the config of an
+// existing remote is fetched, see [model.ReadOnlyModeler.FetchConfigOnly].
+func (action *Git) fetchRemote(ctx context.Context, configOnly bool) error {
+	if configOnly {
+		_, err := action.remote.FetchConfigOnly(ctx)
+		if !errors.Is(err, errdef.ErrNotFound) {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	if action.opts.DryRun {
+		_, err := action.remote.FetchOrEmpty(ctx)
+		return err //nolint:wrapcheck
+	}
+
+	_, err := action.remote.FetchOrDefault(ctx)
+	return err //nolint:wrapcheck
+}
+
 func (action *Git) handleFetch(ctx context.Context) error {
 	local, err := action.localRepo(ctx)
 	if err != nil {
 		return err
 	}
 
-	if err := cmd.HandleFetch(ctx, local, action.remote, action.comm); err != nil {
+	if err := cmd.HandleFetch(ctx, local, action.remote, action.comm, &action.opts); err != nil {
 		return fmt.Errorf("running fetch command: %w", err)
 	}
 
+	if action.submoduleURLs {
+		action.rewriteSubmoduleURLs(ctx, local)
+	}
+
+	// failures are logged rather than failing the fetch, as the fetched
+	// objects are unaffected
+	if ok, err := cmd.RecordPinned(ctx, local, action.name, action.remote); err != nil {
+		slog.WarnContext(ctx, "recording pinned manifest digest", slog.String("error", err.Error()))
+	} else if ok {
+		slog.InfoContext(ctx, "recorded pinned manifest digest", slog.String("remote", action.name))
+	}
+
 	return nil
 }
 
+// rewriteSubmoduleURLs writes the submodule URL mappings of the remote's
+// metadata to the local repository's configuration. Failures are logged rather
+// than failing the fetch, as the fetched objects are unaffected.
+func (action *Git) rewriteSubmoduleURLs(ctx context.Context, local git.Repository) {
+	meta, err := action.remote.FetchMetadata(ctx)
+	switch {
+	case errors.Is(err, model.ErrMetadataNotFound):
+		return
+	case err != nil:
+		slog.WarnContext(ctx, "fetching repository metadata, submodule URLs not rewritten", slog.String("error", err.Error()))
+		return
+	}
+
+	n, err := setSubmoduleURLs(ctx, local, meta)
+	if err != nil {
+		slog.WarnContext(ctx, "rewriting submodule URLs", slog.String("error", err.Error()))
+		return
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "rewrote submodule URLs", slog.Int("mappings", n))
+	}
+}
+
 // GetScheme returns the runtime scheme used for configuration file loading.
 func (action *Git) GetScheme() *runtime.Scheme {
 	return action.apiScheme
 }
 
 // GetConfig loads Configuration using the current git-remote-oci options.
-func (action *Git) GetConfig(ctx context.Context) (c *v1alpha1.Configuration, err error) {
-	c = &v1alpha1.Configuration{}
-
-	slog.DebugContext(ctx, "searching for configuration files", slog.Any("cfgFiles", action.ConfigFiles))
-
-	err = config.Load(slog.Default(), action.GetScheme(), c, action.ConfigFiles)
+func (action *Git) GetConfig(ctx context.Context) (*v1alpha2.Configuration, error) {
+	c, err := loadConfig(ctx, action.GetScheme(), action.ConfigFiles)
 	if err != nil {
-		return c, fmt.Errorf("loading configuration: %w", err)
+		return nil, fmt.Errorf("loading configuration: %w", err)
 	}
-
-	defer slog.DebugContext(ctx, "using config", slog.Any("configuration", c))
-
 	return c, nil
 }
 
-func repoOptsFromConfig(host string, cfg *v1alpha1.Configuration) *ociutil.RepositoryOptions {
+func repoOptsFromConfig(host string, cfg *v1alpha2.Configuration) *ociutil.RepositoryOptions {
 	repoOpts := &ociutil.RepositoryOptions{
-		UserAgent: ociutil.GitUserAgent,
+		UserAgent:   ociutil.GitUserAgent,
+		CredHelpers: cfg.CredHelpers,
+		Retry:       retryPolicyFromConfig(cfg.Transfer.Retry),
 	}
 
-	regCfg, ok := cfg.RegistryConfig.Registries[host]
+	for reg, regCfg := range cfg.Registries {
+		if regCfg.OAuth2 == nil {
+			continue
+		}
+		if repoOpts.AuthProviders == nil {
+			repoOpts.AuthProviders = make(map[string]ociutil.AuthProvider)
+		}
+		repoOpts.AuthProviders[reg] = oauth2ProviderFromConfig(*regCfg.OAuth2)
+	}
+
+	regCfg, ok := cfg.Registries[host]
 	if ok {
 		repoOpts.PlainHTTP = regCfg.PlainHTTP
 		repoOpts.NonCompliant = regCfg.NonCompliant
+		repoOpts.ReferrersTagSchema = regCfg.ReferrersTagSchema
+		repoOpts.Proxy = proxyFromConfig(regCfg)
+		repoOpts.Socket = regCfg.Socket
+		repoOpts.TLS = tlsFromConfig(regCfg)
+
+		for _, mirror := range regCfg.Mirrors {
+			mirrorCfg := cfg.Registries[mirror]
+			repoOpts.Mirrors = append(repoOpts.Mirrors, ociutil.Mirror{
+				Registry:           mirror,
+				PlainHTTP:          mirrorCfg.PlainHTTP,
+				NonCompliant:       mirrorCfg.NonCompliant,
+				ReferrersTagSchema: mirrorCfg.ReferrersTagSchema,
+				Proxy:              proxyFromConfig(mirrorCfg),
+				Socket:             mirrorCfg.Socket,
+				TLS:                tlsFromConfig(mirrorCfg),
+			})
+		}
 	}
 
 	return repoOpts
 }
+
+// oauth2ProviderFromConfig returns the OAuth2 provider of a registry, caching
+// tokens in the default token cache.
+func oauth2ProviderFromConfig(cfg v1alpha2.OAuth2Config) *ociutil.OAuth2Provider {
+	return &ociutil.OAuth2Provider{
+		Flow:                   ociutil.OAuth2Flow(cfg.Flow),
+		TokenURL:               cfg.TokenURL,
+		DeviceAuthorizationURL: cfg.DeviceAuthorizationURL,
+		ClientID:               cfg.ClientID,
+		ClientSecretFile:       cfg.ClientSecretFile,
+		ClientAssertionFile:    cfg.ClientAssertionFile,
+		Scopes:                 cfg.Scopes,
+		Username:               cfg.Username,
+		CacheDir:               ociutil.DefaultTokenCacheDir(),
+	}
+}
+
+// registryModelOpts returns the model options of the registry at host.
+func registryModelOpts(host string, cfg *v1alpha2.Configuration) []model.Option {
+	var opts []model.Option
+	if cfg.Registries[host].TagHistory {
+		opts = append(opts, model.WithTagHistory())
+	}
+	return opts
+}
+
+// proxyFromConfig returns the proxy of a registry.
+func proxyFromConfig(cfg v1alpha2.Registry) ociutil.Proxy {
+	return ociutil.Proxy{
+		URL:          cfg.ProxyURL,
+		Username:     cfg.ProxyUsername,
+		PasswordFile: cfg.ProxyPasswordFile,
+		NoProxy:      cfg.NoProxy,
+	}
+}
+
+// tlsFromConfig returns the TLS configuration of a registry.
+func tlsFromConfig(cfg v1alpha2.Registry) ociutil.TLS {
+	if cfg.TLS == nil {
+		return ociutil.TLS{}
+	}
+	tlsOpts := ociutil.TLS{
+		CertDir:            cfg.TLS.CertDir,
+		InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		MinVersion:         cfg.TLS.MinVersion,
+	}
+	for _, c := range cfg.TLS.ClientCertificates {
+		tlsOpts.ClientCertificates = append(tlsOpts.ClientCertificates, ociutil.ClientCertificate(c))
+	}
+	return tlsOpts
+}
+
+func retryPolicyFromConfig(cfg v1alpha2.RetryConfig) ociutil.RetryPolicy {
+	policy := ociutil.RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+	}
+	if cfg.InitialBackoff != nil {
+		policy.InitialBackoff = cfg.InitialBackoff.Duration
+	}
+	if cfg.MaxBackoff != nil {
+		policy.MaxBackoff = cfg.MaxBackoff.Duration
+	}
+	if cfg.RetryTooManyRequests != nil {
+		policy.NoRetryTooManyRequests = !*cfg.RetryTooManyRequests
+	}
+
+	return policy
+}
+
+// applyPushConfig sets the push options of opts from the push configuration.
+func applyPushConfig(opts *cmd.Options, cfg v1alpha2.PushConfig) error {
+	opts.Atomic = cfg.Atomic
+	opts.Snapshot = cfg.Snapshots
+	opts.MirrorPrune = cfg.MirrorPrune
+	opts.Policy = pushPolicyFromConfig(cfg.Policy)
+	opts.PushDepth = cfg.Depth
+	if cfg.MaxPackLayerSize != nil {
+		opts.MaxPackLayerSize = cfg.MaxPackLayerSize.Value()
+	}
+
+	switch cfg.SecretScan.Action {
+	case "", v1alpha2.SecretScanOff:
+	case v1alpha2.SecretScanWarn, v1alpha2.SecretScanBlock:
+		scanner, err := scan.NewRegexScanner(cfg.SecretScan.Rules)
+		if err != nil {
+			return fmt.Errorf("initializing secret scanner: %w", err)
+		}
+		opts.Scanner = scanner
+		opts.BlockFindings = cfg.SecretScan.Action == v1alpha2.SecretScanBlock
+	default:
+		return fmt.Errorf("unsupported secret scan action %q", cfg.SecretScan.Action)
+	}
+
+	return nil
+}
+
+// applyFetchConfig sets the fetch options of cfg.
+func applyFetchConfig(opts *cmd.Options, cfg v1alpha2.FetchConfig) error {
+	if cfg.IndexConcurrency < 0 {
+		return fmt.Errorf("fetch index concurrency must not be negative, got %d", cfg.IndexConcurrency)
+	}
+	opts.IndexWorkers = cfg.IndexConcurrency
+
+	return nil
+}
+
+// lockOptsFromConfig returns the options of the push lock, nil if pushes do
+// not lock the remote.
+func lockOptsFromConfig(cfg v1alpha2.PushLockConfig) *model.LockOptions {
+	if !cfg.Enabled {
+		return nil
+	}
+	opts := &model.LockOptions{}
+	if cfg.Timeout != nil {
+		opts.Timeout = cfg.Timeout.Duration
+	}
+	if cfg.TTL != nil {
+		opts.TTL = cfg.TTL.Duration
+	}
+	return opts
+}
+
+// lockRemote acquires the push lock of remote, unless opts is nil, returning a
+// function releasing it. Failures to release are logged, as the push itself is
+// complete.
+func lockRemote(ctx context.Context, remote model.Modeler, opts *model.LockOptions) (func(), error) {
+	if opts == nil {
+		return func() {}, nil
+	}
+
+	lock, err := remote.Lock(ctx, *opts)
+	if err != nil {
+		return nil, fmt.Errorf("locking remote: %w", err)
+	}
+
+	return func() {
+		if err := lock.Release(ctx); err != nil {
+			slog.WarnContext(ctx, "releasing remote lock", slog.String("error", err.Error()))
+		}
+	}, nil
+}
+
+// pushPolicyFromConfig converts the configured push policy.
+func pushPolicyFromConfig(cfg v1alpha2.PushPolicy) cmd.Policy {
+	policy := cmd.Policy{
+		ProtectedBranches: cfg.ProtectedBranches,
+		DenyForcePush:     cfg.DenyForcePush,
+		ImmutableTags:     cfg.ImmutableTags,
+	}
+	if cfg.MaxPackSize != nil {
+		policy.MaxPackSize = cfg.MaxPackSize.Value()
+	}
+	return policy
+}
+
+func modelOptsFromConfig(ctx context.Context, cfg *v1alpha2.Configuration) ([]model.Option, error) {
+	var opts []model.Option
+
+	switch cfg.Push.Compression {
+	case "", v1alpha2.CompressionNone:
+	case v1alpha2.CompressionZstd:
+		opts = append(opts, model.WithZstdPacks())
+	default:
+		return nil, fmt.Errorf("unsupported packfile compression %q", cfg.Push.Compression)
+	}
+
+	switch cfg.Push.DigestAlgorithm {
+	case "", v1alpha2.DigestSHA256:
+	case v1alpha2.DigestSHA512:
+		opts = append(opts, model.WithDigestAlgorithm(digest.SHA512))
+	default:
+		return nil, fmt.Errorf("unsupported layer digest algorithm %q", cfg.Push.DigestAlgorithm)
+	}
+
+	switch {
+	case cfg.Transfer.Concurrency < 0:
+		return nil, fmt.Errorf("transfer concurrency must not be negative, got %d", cfg.Transfer.Concurrency)
+	case cfg.Transfer.Concurrency > 0:
+		opts = append(opts, model.WithConcurrency(cfg.Transfer.Concurrency))
+	}
+
+	if cfg.Push.DeleteOrphanedLayers {
+		opts = append(opts, model.WithOrphanedLayerDeletion())
+	}
+
+	if cfg.Push.PackIndex {
+		opts = append(opts, model.WithPackIndexes())
+	}
+
+	if cfg.Push.LFSChunking {
+		opts = append(opts, model.WithLFSChunking())
+	}
+
+	if len(cfg.Push.MountFrom) > 0 {
+		opts = append(opts, model.WithMountFrom(cfg.Push.MountFrom...))
+	}
+
+	if cfg.Push.SigningKey != "" {
+		signer, err := model.LoadSigner(cfg.Push.SigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading signing key: %w", err)
+		}
+		opts = append(opts, model.WithSigner(signer))
+	}
+
+	if len(cfg.VerifyPolicy.Keys) > 0 {
+		keys, err := loadPublicKeys(cfg.VerifyPolicy.Keys)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, model.WithVerifyPolicy(keys...))
+	}
+
+	if cfg.Cache.Enabled {
+		opts = append(opts, model.WithCache(cacheFromConfig(cfg.Cache)))
+	}
+
+	if len(cfg.Encryption.Keys) > 0 {
+		keys, err := encryptionKeysFromConfig(ctx, cfg.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, model.WithEncryption(keys...))
+	}
+
+	switch cfg.Push.Timestamp {
+	case "", v1alpha2.TimestampReproducible:
+		if epoch := os.Getenv(sourceDateEpochEnv); epoch != "" {
+			sec, err := strconv.ParseInt(epoch, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", sourceDateEpochEnv, err)
+			}
+			created := time.Unix(sec, 0)
+			opts = append(opts, model.WithCreated(func() time.Time { return created }))
+		}
+	case v1alpha2.TimestampNow:
+		opts = append(opts, model.WithCreated(time.Now))
+	default:
+		return nil, fmt.Errorf("unsupported manifest timestamp %q", cfg.Push.Timestamp)
+	}
+
+	if cfg.Push.SourceURL != "" {
+		opts = append(opts, model.WithSourceURL(cfg.Push.SourceURL))
+	}
+
+	if a := cfg.Push.Annotations; len(a.Manifest) > 0 || len(a.Config) > 0 || len(a.Layers) > 0 {
+		annotations := model.Annotations(a)
+		if err := annotations.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid push annotations: %w", err)
+		}
+		opts = append(opts, model.WithAnnotations(annotations))
+	}
+
+	return opts, nil
+}
+
+// cacheFromConfig returns the local blob cache, whether or not it is enabled.
+func cacheFromConfig(cfg v1alpha2.CacheConfig) *cache.Cache {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = cache.DefaultDir()
+	}
+	maxSize := cache.DefaultMaxSize
+	if cfg.MaxSize != nil {
+		maxSize = cfg.MaxSize.Value()
+	}
+	return cache.New(dir, maxSize)
+}
+
+func loadPublicKeys(paths []string) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0, len(paths))
+	for _, path := range paths {
+		key, err := model.LoadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading verification key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// encryptionKeysFromConfig loads the configured encryption keys, in order.
+func encryptionKeysFromConfig(ctx context.Context, cfg v1alpha2.EncryptionConfig) ([]model.EncryptionKey, error) {
+	keys := make([]model.EncryptionKey, 0, len(cfg.Keys))
+	for i, src := range cfg.Keys {
+		key, err := loadEncryptionKey(ctx, src)
+		if err != nil {
+			return nil, fmt.Errorf("loading encryption key %d: %w", i, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// loadEncryptionKey reads an encryption key from its file, or the output of
+// its command.
+func loadEncryptionKey(ctx context.Context, src v1alpha2.EncryptionKey) (model.EncryptionKey, error) {
+	var encoded []byte
+	switch {
+	case src.File != "" && len(src.Command) > 0:
+		return model.EncryptionKey{}, errors.New("only one of file or command may be set")
+	case src.File != "":
+		var err error
+		encoded, err = os.ReadFile(src.File)
+		if err != nil {
+			return model.EncryptionKey{}, fmt.Errorf("reading key file: %w", err)
+		}
+	case len(src.Command) > 0:
+		var err error
+		encoded, err = exec.CommandContext(ctx, src.Command[0], src.Command[1:]...).Output()
+		if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return model.EncryptionKey{}, fmt.Errorf("running key command %s: %w: %s", src.Command[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		} else if err != nil {
+			return model.EncryptionKey{}, fmt.Errorf("running key command %s: %w", src.Command[0], err)
+		}
+	default:
+		return model.EncryptionKey{}, errors.New("one of file or command must be set")
+	}
+
+	return model.ParseEncryptionKey(src.ID, encoded) //nolint:wrapcheck
+}
@@ -6,10 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -17,15 +21,46 @@ import (
 	"oras.land/oras-go/v2/content/file"
 	"oras.land/oras-go/v2/registry"
 
+	"github.com/act3-ai/gnoci/internal/cache"
+	"github.com/act3-ai/gnoci/internal/filelock"
+	"github.com/act3-ai/gnoci/internal/git"
 	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/ociutil"
 	"github.com/act3-ai/gnoci/internal/progress"
+	"github.com/act3-ai/gnoci/internal/tracing"
 	"github.com/act3-ai/gnoci/pkg/apis"
-	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
 	"github.com/act3-ai/gnoci/pkg/protocol/lfs"
 	"github.com/act3-ai/gnoci/pkg/protocol/lfs/comms"
-	"github.com/act3-ai/go-common/pkg/config"
-	"github.com/go-git/go-git/v5"
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 	"github.com/opencontainers/go-digest"
+	"github.com/sourcegraph/conc/pool"
+)
+
+const (
+	// lfsPullDir is the directory, within the Git directory of the
+	// repository, LFS files are downloaded to, beside the temporary files of
+	// git-lfs, such that they are moved rather than copied once complete.
+	lfsPullDir = "lfs/tmp/remote-oci"
+	// partialSuffix identifies incomplete LFS file downloads.
+	partialSuffix = ".partial"
+	// partialTTL is the age after which abandoned LFS file downloads are
+	// removed, rather than resumed.
+	partialTTL = 7 * 24 * time.Hour
+	// lockSuffix identifies the lock of an LFS file download, held while
+	// resuming it such that concurrent downloads do not interleave, and
+	// removed once released.
+	lockSuffix = ".lock"
+	// lfsTransferAgent is the name of the git-lfs custom transfer agent.
+	lfsTransferAgent = "oci"
+	// defaultConcurrentTransfers matches the git-lfs default of
+	// lfs.concurrenttransfers.
+	defaultConcurrentTransfers = 8
+	// lfsProgressInterval is the interval at which LFS transfer progress is
+	// reported to git-lfs.
+	lfsProgressInterval = time.Second / 2
 )
 
 // GitLFS represents the base action.
@@ -36,15 +71,43 @@ type GitLFS struct {
 	ConfigFiles []string
 
 	// local temp files
-	ociStore *file.Store
 	lfsStore string
 
-	// OCI remote
-	ref registry.Reference
-	gt  oras.GraphTarget
+	// OCI remotes, one per URL of the Git remote, see [GitLFS.init]
+	targets []lfsTarget
+	// local cache of fetched LFS files, if enabled
+	cache *cache.Cache
+	// keys encrypting pushed LFS files and decrypting fetched ones, if any
+	encryptionKeys []model.EncryptionKey
+	// chunked upload sizes of pushed LFS files, model defaults if zero
+	chunkThreshold int64
+	chunkSize      int64
 
 	// git-lfs request and response handler
 	comm comms.Communicator
+	// transfers handled concurrently, see [GitLFS.runTransfers]
+	workers int
+}
+
+// lfsTarget is an OCI remote LFS files are transferred to or from.
+type lfsTarget struct {
+	ref        registry.Reference
+	ociStore   *file.Store
+	fstorePath string
+	// model options of the target's registry
+	opts []model.Option
+	gt   oras.GraphTarget
+	// LFS files and manifests, if stored separately from the Git remote
+	lfsGT oras.GraphTarget
+}
+
+// lfsRemote is the data model of an [lfsTarget].
+type lfsRemote struct {
+	model.LFSModeler
+
+	ref registry.Reference
+	// Git manifest, the subject of the LFS manifest
+	subject ocispec.Descriptor
 }
 
 // NewGitLFS creates a new Tool with default values.
@@ -63,51 +126,101 @@ func (action *GitLFS) init(ctx context.Context, initReq *lfs.InitRequest) (func(
 		return nil, fmt.Errorf("getting configuration: %w", err)
 	}
 
-	// TODO: How can we get the actual git dir?
-	repo, err := git.PlainOpen(".")
+	// git-lfs runs within the repository, which may be bare
+	repo, err := git.OpenEnv()
 	if err != nil {
 		return nil, fmt.Errorf("opening local repository: %w", err)
 	}
 
-	ref, err := resolveAddress(ctx, initReq.Remote, repo)
+	addrs, err := resolveAddresses(ctx, initReq.Remote, repo, cfg.DefaultTag)
 	if err != nil {
 		return nil, fmt.Errorf("resolving remote URL: %w", err)
 	}
-	action.ref = ref
-
-	// var fstorePath string
-	action.gt, _, action.ociStore, err = initRemoteConn(ctx, ref, repoOptsFromConfig(ref.Host(), cfg))
+	action.workers = transferWorkers(ctx, repo, initReq)
+	if cfg.Cache.Enabled {
+		action.cache = cacheFromConfig(cfg.Cache)
+	}
+	action.encryptionKeys, err = encryptionKeysFromConfig(ctx, cfg.Encryption)
 	if err != nil {
-		return nil, fmt.Errorf("initializing remote connection: %w", err)
+		return nil, err
 	}
+	action.chunkThreshold, action.chunkSize = uploadChunkingFromConfig(cfg.Push)
 
-	if initReq.Operation == lfs.DownloadOperation {
-		action.lfsStore, err = os.MkdirTemp(os.TempDir(), "git-lfs-remote-oci-pull-*")
-		if err != nil {
-			return nil, fmt.Errorf("preparing temporary LFS pull directory: %w", err)
-		}
+	lfsAddr, separate := lfsStoreAddress(ctx, repo, initReq.Remote, addrs[0], cfg.DefaultTag)
+	if initReq.Operation == lfs.DownloadOperation || separate {
+		// as with Git, downloads use the first URL, and a separate LFS
+		// repository is shared by all URLs
+		addrs = addrs[:1]
 	}
 
+	// closes the connections initialized so far, if any fail
 	cleanUpFn := func() error {
+		// the LFS pull directory is not removed, git-lfs moves completed
+		// files and partial downloads are kept for resumption until stale
 		var errs []error
-		if err := action.ociStore.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("closing oci file store: %w", err))
+		for _, target := range action.targets {
+			if err := target.ociStore.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing oci file store: %w", err))
+			}
+			if err := os.RemoveAll(target.fstorePath); err != nil {
+				errs = append(errs, fmt.Errorf("removing temporary files: %w", err))
+			}
+			if closer, ok := target.gt.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					errs = append(errs, fmt.Errorf("closing OCI remote: %w", err))
+				}
+			}
+			if closer, ok := target.lfsGT.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					errs = append(errs, fmt.Errorf("closing LFS OCI remote: %w", err))
+				}
+			}
 		}
 
-		if err := os.RemoveAll(action.lfsStore); err != nil {
-			errs = append(errs, fmt.Errorf("removing temporary LFS file store: %w", err))
+		return errors.Join(errs...)
+	}
+
+	for _, addr := range addrs {
+		var target lfsTarget
+		target.ref = addr.Ref
+		target.opts = registryModelOpts(addr.Ref.Host(), cfg)
+		target.gt, target.fstorePath, target.ociStore, err = initRemoteConn(ctx, addr, repoOptsFromConfig(addr.Ref.Host(), cfg))
+		if err != nil {
+			return cleanUpFn, fmt.Errorf("initializing remote connection: %w", err)
 		}
+		action.targets = append(action.targets, target)
+	}
 
-		return errors.Join(errs...)
+	if separate {
+		action.targets[0].lfsGT, err = newGraphTarget(ctx, lfsAddr, repoOptsFromConfig(lfsAddr.Ref.Host(), cfg))
+		if err != nil {
+			return cleanUpFn, fmt.Errorf("initializing LFS remote connection: %w", err)
+		}
+	}
+
+	if initReq.Operation == lfs.DownloadOperation {
+		// persisted across runs, allowing interrupted downloads to resume
+		action.lfsStore, err = lfsPullPath(repo)
+		if err != nil {
+			return cleanUpFn, err
+		}
+		if err := os.MkdirAll(action.lfsStore, 0o700); err != nil {
+			return cleanUpFn, fmt.Errorf("preparing temporary LFS pull directory: %w", err)
+		}
+		removeStaleDownloads(ctx, action.lfsStore, partialTTL)
 	}
 
 	return cleanUpFn, nil
 }
 
 // Run runs the the primary git-remote-oci action.
-func (action *GitLFS) Run(ctx context.Context) error {
+func (action *GitLFS) Run(ctx context.Context) (err error) {
 	slog.DebugContext(ctx, "running git-lfs-remote-oci")
 
+	defer initTracing(ctx, ociutil.GitLFSUserAgent, action.version)()
+	ctx, span := tracing.Start(ctx, ociutil.GitLFSUserAgent)
+	defer tracing.End(span, &err)
+
 	initReq, err := action.comm.ReceiveInitRequest(ctx)
 	if err != nil {
 		return fmt.Errorf("receiving InitRequest: %w", err)
@@ -125,14 +238,8 @@ func (action *GitLFS) Run(ctx context.Context) error {
 		return action.comm.WriteInitResponse(ctx, err)
 	}
 
-	remote := model.NewLFSModeler(action.ref, action.ociStore, action.gt)
-
-	subject, err := remote.FetchOrDefault(ctx)
+	remotes, err := action.fetchRemotes(ctx)
 	if err != nil {
-		return action.comm.WriteInitResponse(ctx, fmt.Errorf("fetching base git OCI metadata: %w", err))
-	}
-
-	if _, err = remote.FetchLFSOrDefault(ctx); err != nil {
 		return action.comm.WriteInitResponse(ctx, err)
 	}
 
@@ -142,202 +249,553 @@ func (action *GitLFS) Run(ctx context.Context) error {
 
 	switch initReq.Operation {
 	case lfs.DownloadOperation:
-		return action.runDownload(ctx, remote)
+		return action.runDownload(ctx, remotes[0])
 	case lfs.UploadOperation:
-		return action.runUpload(ctx, subject, remote)
+		return action.runUpload(ctx, remotes)
 	default:
 		// theoretically impossible
 		return fmt.Errorf("%w: %s", lfs.ErrInvalidOperation, initReq.Operation)
 	}
 }
 
+// fetchRemotes fetches the Git and LFS metadata of each target. Targets which
+// fail are skipped with a warning, failing only if all do.
+func (action *GitLFS) fetchRemotes(ctx context.Context) ([]lfsRemote, error) {
+	remotes := make([]lfsRemote, 0, len(action.targets))
+	var errs []error
+	for _, target := range action.targets {
+		opts := slices.Clone(target.opts)
+		if target.lfsGT != nil {
+			opts = append(opts, model.WithLFSStore(target.lfsGT))
+		}
+		if action.cache != nil {
+			opts = append(opts, model.WithCache(action.cache))
+		}
+		if len(action.encryptionKeys) > 0 {
+			opts = append(opts, model.WithEncryption(action.encryptionKeys...))
+		}
+		remote := lfsRemote{
+			LFSModeler: model.NewLFSModeler(target.ref, target.ociStore, target.gt, opts...),
+			ref:        target.ref,
+		}
+
+		var err error
+		remote.subject, err = remote.FetchOrDefault(ctx)
+		if err != nil {
+			err = fmt.Errorf("fetching base git OCI metadata from %s: %w", target.ref, err)
+		} else if _, err = remote.FetchLFSOrDefault(ctx); err != nil {
+			err = fmt.Errorf("fetching LFS OCI metadata from %s: %w", target.ref, err)
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "skipping OCI remote", slog.String("error", err.Error()))
+			errs = append(errs, err)
+			continue
+		}
+		remotes = append(remotes, remote)
+	}
+
+	if len(remotes) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return remotes, nil
+}
+
 func (action *GitLFS) runDownload(ctx context.Context, remote model.ReadOnlyLFSModeler) error {
 	slog.DebugContext(ctx, "handling download requests")
 
-	for {
-		slog.DebugContext(ctx, "waiting for download request")
+	return action.runTransfers(ctx, lfs.DownloadEvent,
+		func(ctx context.Context, transferReq *lfs.TransferRequest) (string, error) {
+			return action.downloadLFSLayer(ctx, transferReq, remote)
+		},
+		func(ctx context.Context, transferReq *lfs.TransferRequest, path string, err error) error {
+			return action.comm.WriteTransferDownloadResponse(ctx, transferReq.Oid, path, err)
+		})
+}
 
-		transferReq, err := action.comm.ReceiveTransferRequest(ctx)
-		switch {
-		case err != nil:
-			return err
-		case transferReq.Event == lfs.TerminateEvent:
-			// done
-			slog.DebugContext(ctx, "received terminate request")
-			return nil
-		case transferReq.Event != lfs.DownloadEvent:
-			// git-lfs did not adhere to it's own protocol
-			return fmt.Errorf("unexpected event %s, expected %s", transferReq.Event, lfs.DownloadEvent)
-		default:
-			path, err := action.downloadLFSLayer(ctx, transferReq, remote)
-			err = action.comm.WriteTransferDownloadResponse(ctx, transferReq.Oid, path, err)
+// transferFunc transfers a single LFS file, returning its local path if
+// downloaded.
+type transferFunc func(ctx context.Context, transferReq *lfs.TransferRequest) (string, error)
+
+// respondFunc writes the completion response of a transfer. As with
+// [comms.ResponseHandler], transferErr is returned joined with any response
+// handling errors.
+type respondFunc func(ctx context.Context, transferReq *lfs.TransferRequest, path string, transferErr error) error
+
+// pendingTransfer is an in-flight transfer request.
+type pendingTransfer struct {
+	req  *lfs.TransferRequest
+	path string
+	err  error
+	done chan struct{}
+}
+
+// runTransfers handles transfer requests of event until git-lfs terminates the
+// transfer agent. Up to action.workers requests are transferred concurrently,
+// with progress messages keyed by oid interleaved, while completion responses
+// are written in the order requests were received. Transfer failures are
+// reported to git-lfs, failures to communicate with git-lfs end the transfer.
+func (action *GitLFS) runTransfers(ctx context.Context, event lfs.Event, transfer transferFunc, respond respondFunc) error {
+	workers := max(action.workers, 1)
+
+	// in order of request
+	pending := make(chan *pendingTransfer, workers)
+	respondErr := make(chan error, 1)
+	go func() {
+		var err error
+		for t := range pending {
+			<-t.done
 			if err != nil {
-				return fmt.Errorf("writing transfer response: %w", err)
+				// drain remaining transfers
+				continue
+			}
+			// the transfer error itself is returned once the response is written
+			if writeErr := respond(ctx, t.req, t.path, t.err); writeErr != nil && writeErr != t.err { //nolint:errorlint
+				err = fmt.Errorf("writing transfer response: %w", writeErr)
+			} else if t.err != nil {
+				slog.WarnContext(ctx, "transferring LFS file", slog.String("oid", t.req.Oid), slog.String("error", t.err.Error()))
 			}
 		}
-	}
+		respondErr <- err
+	}()
+
+	p := pool.New().WithMaxGoroutines(workers)
+	receiveErr := func() error {
+		for {
+			slog.DebugContext(ctx, "waiting for transfer request", slog.String("event", string(event)))
+
+			transferReq, err := action.comm.ReceiveTransferRequest(ctx)
+			switch {
+			case err != nil:
+				return err
+			case transferReq.Event == lfs.TerminateEvent:
+				// done
+				slog.DebugContext(ctx, "received terminate request")
+				return nil
+			case transferReq.Event != event:
+				// git-lfs did not adhere to it's own protocol
+				return fmt.Errorf("unexpected event %s, expected %s", transferReq.Event, event)
+			}
+
+			t := &pendingTransfer{req: transferReq, done: make(chan struct{})}
+			pending <- t
+			p.Go(func() {
+				defer close(t.done)
+				t.path, t.err = transfer(ctx, t.req)
+			})
+		}
+	}()
+
+	p.Wait()
+	close(pending)
+
+	return errors.Join(receiveErr, <-respondErr)
 }
 
 func (action *GitLFS) downloadLFSLayer(ctx context.Context, transferReq *lfs.TransferRequest, remote model.ReadOnlyLFSModeler) (string, error) {
-	pChan := make(chan progress.Progress)
-	done := make(chan struct{})
-	go func() {
-		for pUpdate := range pChan {
-			err := action.comm.WriteProgress(ctx,
-				transferReq.Oid,
-				pUpdate.Total,
-				pUpdate.Delta)
-			if err != nil {
-				slog.WarnContext(ctx, "writing progress",
-					slog.String("error", err.Error()))
-			}
+	unlock, err := filelock.LockTemp(filepath.Join(action.lfsStore, transferReq.Oid+lockSuffix))
+	if err != nil {
+		return "", fmt.Errorf("locking LFS file download: %w", err)
+	}
+	defer func() {
+		if err := unlock(); err != nil {
+			slog.WarnContext(ctx, "unlocking LFS file download", slog.String("error", err.Error()))
 		}
-		done <- struct{}{}
 	}()
 
+	// resume a previously interrupted download, if one exists
+	partialPath := filepath.Join(action.lfsStore, transferReq.Oid+partialSuffix)
+	offset, err := partialOffset(partialPath, transferReq.Size)
+	if err != nil {
+		return "", err
+	}
+
+	if offset < transferReq.Size {
+		if err := action.fetchLFSLayer(ctx, transferReq, remote, partialPath, offset); err != nil {
+			return "", err
+		}
+	}
+
+	if err := verifyLFSFile(partialPath, transferReq.Oid); err != nil {
+		if err := os.Remove(partialPath); err != nil {
+			slog.WarnContext(ctx, "removing corrupt LFS file", slog.String("error", err.Error()))
+		}
+		return "", err
+	}
+
+	tmpFilePath := filepath.Join(action.lfsStore, transferReq.Oid)
+	if err := os.Rename(partialPath, tmpFilePath); err != nil {
+		return "", fmt.Errorf("moving completed LFS file: %w", err)
+	}
+
+	return tmpFilePath, nil
+}
+
+// fetchLFSLayer appends the remainder of an LFS file, starting at offset, to
+// the partial download at partialPath.
+func (action *GitLFS) fetchLFSLayer(ctx context.Context, transferReq *lfs.TransferRequest, remote model.ReadOnlyLFSModeler, partialPath string, offset int64) error {
+	// reports the final progress once the layer is fetched, or has failed
+	reporter := progress.NewReporter(ctx, lfsProgressInterval, func(p progress.Progress) error {
+		return action.comm.WriteProgress(ctx, transferReq.Oid, p.Total+int(offset), p.Delta) //nolint:wrapcheck
+	})
+	defer stopProgress(ctx, reporter)
+
 	fetchOpts := &model.FetchLFSOptions{
 		Progress: &model.ProgressOptions{
-			Info: pChan,
+			Reporter: reporter,
 		},
+		Offset: offset,
 	}
 
-	// TODO: convenient that LFS uses sha256 by default, but are other digest methods out there?
-	rc, err := remote.FetchLFSLayer(ctx, digest.NewDigestFromEncoded(digest.SHA256, transferReq.Oid), fetchOpts)
+	rc, err := remote.FetchLFSLayer(ctx, digest.NewDigestFromEncoded(model.LFSOidAlgorithm, transferReq.Oid), fetchOpts)
 	if err != nil {
-		return "", fmt.Errorf("fetching LFS file: %w", err)
+		return fmt.Errorf("fetching LFS file: %w", err)
 	}
 	defer rc.Close()
 
-	tmpFilePath := filepath.Join(action.lfsStore, transferReq.Oid)
-	f, err := os.OpenFile(tmpFilePath, os.O_RDWR|os.O_CREATE, 0644)
+	f, err := os.OpenFile(partialPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 	if err != nil {
-		return "", fmt.Errorf("opening LFS temp file: %w", err)
+		return fmt.Errorf("opening LFS temp file: %w", err)
 	}
 	defer f.Close()
 
 	n, err := io.Copy(f, rc)
-	<-done
+	n += offset
 	switch {
 	case err != nil:
-		return "", fmt.Errorf("copying LFS temp file: %w", err)
+		return fmt.Errorf("copying LFS temp file, %d of %d bytes downloaded: %w", n, transferReq.Size, err)
 	case n != transferReq.Size:
 		// TODO: double check protocol spec, LFS may handle this validation for us
-		return "", fmt.Errorf("unexpected LFS file size, expected %d, got %d", transferReq.Size, n)
-	default:
-		return tmpFilePath, nil
+		return fmt.Errorf("unexpected LFS file size, expected %d, got %d", transferReq.Size, n)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing LFS temp file: %w", err)
 	}
+
+	return nil
 }
 
-func (action *GitLFS) runUpload(ctx context.Context, subject ocispec.Descriptor, remote model.LFSModeler) error {
-	slog.DebugContext(ctx, "handling upload requests")
+// lfsPullPath returns the directory LFS files are downloaded to, within the
+// Git directory of repo.
+func lfsPullPath(repo *gogit.Repository) (string, error) {
+	st, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errors.New("repository storer is not a filesystem.Storage")
+	}
+	dir, err := filepath.Abs(filepath.Join(st.Filesystem().Root(), filepath.FromSlash(lfsPullDir)))
+	if err != nil {
+		return "", fmt.Errorf("resolving LFS pull directory: %w", err)
+	}
+	return dir, nil
+}
 
-	for {
-		slog.DebugContext(ctx, "waiting for upload request")
+// removeStaleDownloads removes the partial and completed downloads in dir not
+// modified within ttl, abandoned by interrupted transfers. Failures are
+// logged, as stale downloads only take up space.
+func removeStaleDownloads(ctx context.Context, dir string, ttl time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		slog.WarnContext(ctx, "listing LFS pull directory", slog.String("error", err.Error()))
+		return
+	}
 
-		transferReq, err := action.comm.ReceiveTransferRequest(ctx)
+	cutoff := time.Now().Add(-ttl)
+	for _, entry := range entries {
+		// locks may be held by concurrent downloads
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), lockSuffix) {
+			continue
+		}
+		info, err := entry.Info()
 		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
 		case err != nil:
-			return err
-		case transferReq.Event == lfs.TerminateEvent:
-			// done with LFS files
-			slog.DebugContext(ctx, "received terminate request")
-			_, err := remote.PushLFSManifest(ctx, subject)
-			if err != nil {
-				return fmt.Errorf("pushing LFS manifest to OCI: %w", err)
-			}
-			return nil
-		case transferReq.Event != lfs.UploadEvent:
-			// git-lfs did not adhere to it's own protocol
-			return fmt.Errorf("unexpected event %s, expected %s", transferReq.Event, lfs.UploadEvent)
-		default:
-			err := action.uploadLFSLayer(ctx, transferReq, remote)
-			err = action.comm.WriteTransferUploadResponse(ctx, transferReq.Oid, err)
-			if err != nil {
-				return fmt.Errorf("writing transfer response: %w", err)
-			}
+			slog.WarnContext(ctx, "resolving LFS download", slog.String("name", entry.Name()), slog.String("error", err.Error()))
+			continue
+		case info.ModTime().After(cutoff):
+			continue
+		}
+
+		slog.DebugContext(ctx, "removing stale LFS download", slog.String("name", entry.Name()))
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.WarnContext(ctx, "removing stale LFS download", slog.String("name", entry.Name()), slog.String("error", err.Error()))
 		}
 	}
 }
 
-func (action *GitLFS) uploadLFSLayer(ctx context.Context, transferReq *lfs.TransferRequest, remote model.LFSModeler) error {
-	pChan := make(chan progress.Progress) // closed by [progress.NewTicker] when reading completes
-	done := make(chan struct{})
-	go func() {
-		for pUpdate := range pChan {
-			err := action.comm.WriteProgress(ctx, transferReq.Oid, pUpdate.Total, pUpdate.Delta)
-			if err != nil {
-				slog.WarnContext(ctx, "writing progress update", slog.String("error", err.Error()))
-			}
+// partialOffset returns the size of a partial download, discarding it if it
+// exceeds the expected size.
+func partialOffset(partialPath string, size int64) (int64, error) {
+	fi, err := os.Stat(partialPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return 0, nil
+	case err != nil:
+		return 0, fmt.Errorf("inspecting partial LFS download: %w", err)
+	case fi.Size() > size:
+		if err := os.Remove(partialPath); err != nil {
+			return 0, fmt.Errorf("removing invalid partial LFS download: %w", err)
 		}
-		done <- struct{}{}
-	}()
+		return 0, nil
+	default:
+		return fi.Size(), nil
+	}
+}
 
-	pushOpts := &model.PushLFSOptions{
-		Progress: &model.ProgressOptions{
-			Info: pChan,
+// verifyLFSFile ensures the contents of a downloaded LFS file match its oid,
+// guarding against corrupt resumed downloads.
+func verifyLFSFile(path, oid string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening LFS file for verification: %w", err)
+	}
+	defer f.Close()
+
+	got, err := model.LFSOidAlgorithm.FromReader(f)
+	if err != nil {
+		return fmt.Errorf("calculating LFS file digest: %w", err)
+	}
+	if got.Encoded() != oid {
+		return fmt.Errorf("LFS file digest mismatch, expected %s, got %s", oid, got.Encoded())
+	}
+
+	return nil
+}
+
+// runUpload uploads LFS files to every remote, as Git pushes to every URL of
+// a remote. An upload, or LFS manifest push, fails only if it fails for all
+// remotes.
+func (action *GitLFS) runUpload(ctx context.Context, remotes []lfsRemote) error {
+	slog.DebugContext(ctx, "handling upload requests", slog.Int("remotes", len(remotes)))
+
+	err := action.runTransfers(ctx, lfs.UploadEvent,
+		func(ctx context.Context, transferReq *lfs.TransferRequest) (string, error) {
+			return "", action.uploadLFSLayers(ctx, transferReq, remotes)
 		},
+		func(ctx context.Context, transferReq *lfs.TransferRequest, _ string, err error) error {
+			return action.comm.WriteTransferUploadResponse(ctx, transferReq.Oid, err)
+		})
+	if err != nil {
+		return err
+	}
+
+	// done with LFS files, each manifest includes the files uploaded to its remote
+	var errs []error
+	for _, remote := range remotes {
+		if _, err := remote.PushLFSManifest(ctx, remote.subject); err != nil {
+			err = fmt.Errorf("pushing LFS manifest to %s: %w", remote.ref, err)
+			slog.WarnContext(ctx, "pushing LFS manifest", slog.String("error", err.Error()))
+			errs = append(errs, err)
+		}
 	}
-	if _, err := remote.PushLFSFile(ctx, transferReq.Path, pushOpts); err != nil {
-		err := action.comm.WriteTransferUploadResponse(ctx, transferReq.Oid, fmt.Errorf("preparing git-lfs file for transfer: %w", err))
+	if len(errs) == len(remotes) {
+		return fmt.Errorf("pushing LFS manifest to OCI: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// uploadLFSLayers uploads an LFS file to each remote, succeeding if any
+// upload does. Progress is reported for the first remote only.
+func (action *GitLFS) uploadLFSLayers(ctx context.Context, transferReq *lfs.TransferRequest, remotes []lfsRemote) error {
+	errs := make([]error, 0, len(remotes))
+	for i, remote := range remotes {
+		var err error
+		if i == 0 {
+			err = action.uploadLFSLayer(ctx, transferReq, remote)
+		} else if _, err = remote.PushLFSFile(ctx, transferReq.Path, action.pushLFSOptions(transferReq)); err != nil {
+			err = fmt.Errorf("preparing git-lfs file for transfer: %w", err)
+		}
 		if err != nil {
-			<-done
-			return err
+			err = fmt.Errorf("uploading to %s: %w", remote.ref, err)
+			if len(remotes) > 1 {
+				slog.WarnContext(ctx, "uploading LFS file", slog.String("oid", transferReq.Oid), slog.String("error", err.Error()))
+			}
+			errs = append(errs, err)
 		}
 	}
-	<-done
 
+	if len(errs) == len(remotes) {
+		return errors.Join(errs...)
+	}
 	return nil
 }
 
+func (action *GitLFS) uploadLFSLayer(ctx context.Context, transferReq *lfs.TransferRequest, remote model.LFSModeler) error {
+	var soFar int
+	reporter := progress.NewReporter(ctx, lfsProgressInterval, func(p progress.Progress) error {
+		soFar = p.Total
+		return action.comm.WriteProgress(ctx, transferReq.Oid, p.Total, p.Delta) //nolint:wrapcheck
+	})
+
+	// nothing is read if the LFS file already exists, or the push fails early
+	pushOpts := action.pushLFSOptions(transferReq)
+	pushOpts.Progress = &model.ProgressOptions{
+		Reporter: reporter,
+	}
+	_, err := remote.PushLFSFile(ctx, transferReq.Path, pushOpts)
+	stopProgress(ctx, reporter)
+	if err != nil {
+		return fmt.Errorf("preparing git-lfs file for transfer: %w", err)
+	}
+
+	// report the final progress, which may not have been read
+	if remaining := int(transferReq.Size) - soFar; remaining > 0 {
+		if err := action.comm.WriteProgress(ctx, transferReq.Oid, int(transferReq.Size), remaining); err != nil {
+			slog.WarnContext(ctx, "writing progress update", slog.String("error", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// stopProgress stops reporting the progress of an LFS transfer, which is best
+// effort, logging any failure to write it.
+// pushLFSOptions returns the options of pushing the LFS file of transferReq.
+func (action *GitLFS) pushLFSOptions(transferReq *lfs.TransferRequest) *model.PushLFSOptions {
+	return &model.PushLFSOptions{
+		ChunkThreshold: action.chunkThreshold,
+		ChunkSize:      action.chunkSize,
+		Oid:            transferReq.Oid,
+		Size:           transferReq.Size,
+	}
+}
+
+// uploadChunkingFromConfig returns the chunked upload threshold and chunk
+// size of pushed LFS files, zero if unset.
+func uploadChunkingFromConfig(cfg v1alpha2.PushConfig) (threshold, size int64) {
+	if cfg.LFSUploadChunkThreshold != nil {
+		threshold = cfg.LFSUploadChunkThreshold.Value()
+	}
+	if cfg.LFSUploadChunkSize != nil {
+		size = cfg.LFSUploadChunkSize.Value()
+	}
+	return threshold, size
+}
+
+func stopProgress(ctx context.Context, reporter *progress.Reporter) {
+	if err := reporter.Stop(); err != nil {
+		slog.WarnContext(ctx, "writing progress update", slog.String("error", err.Error()))
+	}
+}
+
 // GetScheme returns the runtime scheme used for configuration file loading.
 func (action *GitLFS) GetScheme() *runtime.Scheme {
 	return action.apiScheme
 }
 
 // GetConfig loads Configuration using the current git-remote-oci options.
-func (action *GitLFS) GetConfig(ctx context.Context) (c *v1alpha1.Configuration, err error) {
-	c = &v1alpha1.Configuration{}
-
-	slog.DebugContext(ctx, "searching for configuration files", slog.Any("cfgFiles", action.ConfigFiles))
-
-	err = config.Load(slog.Default(), action.GetScheme(), c, action.ConfigFiles)
+func (action *GitLFS) GetConfig(ctx context.Context) (*v1alpha2.Configuration, error) {
+	c, err := loadConfig(ctx, action.GetScheme(), action.ConfigFiles)
 	if err != nil {
-		return c, fmt.Errorf("loading configuration: %w", err)
+		return nil, fmt.Errorf("loading configuration: %w", err)
 	}
-
-	defer slog.DebugContext(ctx, "using config", slog.Any("configuration", c))
-
 	return c, nil
 }
 
-// resolveAddress trims an OCI URL or resolves a shortname to a URL.
-func resolveAddress(ctx context.Context, remote string, repo *git.Repository) (registry.Reference, error) {
-	var remoteURL string
-	if strings.HasPrefix(remote, "oci://") {
+// resolveAddresses parses an OCI URL or resolves a shortname to the URLs of
+// the remote, in the order configured. Addresses without a tag or digest
+// reference defaultTag.
+func resolveAddresses(ctx context.Context, remote string, repo *gogit.Repository, defaultTag string) ([]ociutil.Address, error) {
+	var remoteURLs []string
+	if ociutil.HasScheme(remote) {
 		slog.DebugContext(ctx, "received full remote URL", slog.String("url", remote))
-		remoteURL = trimProtocol(remote)
+		remoteURLs = []string{remote}
 	} else {
 		slog.DebugContext(ctx, "received remote shortname", slog.String("shortname", remote))
 
 		// Look up the remote by name
 		remote, err := repo.Remote(trimProtocol(remote)) // sanity?
 		if err != nil {
-			return registry.Reference{}, fmt.Errorf("resolving remote URL for %s: %w", remote, err)
+			return nil, fmt.Errorf("resolving remote URL for %s: %w", remote, err)
 		}
-		remoteURLs := remote.Config().URLs
+		// includes push URLs
+		remoteURLs = remote.Config().URLs
 		if len(remoteURLs) < 1 {
-			return registry.Reference{}, fmt.Errorf("no URLs configured for remote %s", remote)
+			return nil, fmt.Errorf("no URLs configured for remote %s", remote)
+		}
+		slog.DebugContext(ctx, "resolved remote URLs", slog.Any("urls", remoteURLs))
+	}
+
+	addrs := make([]ociutil.Address, 0, len(remoteURLs))
+	for _, remoteURL := range remoteURLs {
+		if !ociutil.HasScheme(remoteURL) {
+			// handled by another remote helper
+			slog.DebugContext(ctx, "skipping non-OCI remote URL", slog.String("url", remoteURL))
+			continue
+		}
+		addr, err := ociutil.ParseAddressDefaultTag(remoteURL, defaultTag)
+		if err != nil {
+			return nil, fmt.Errorf("parsing remote URL %s: %w", remoteURL, err)
 		}
-		remoteURL = trimProtocol(remoteURLs[0]) // TODO: do we just push to multiple if more than one URL is provided? How would git-remote-oci handle this?
-		slog.DebugContext(ctx, "resolved remote URL", "url", remoteURL)
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no OCI URLs configured for remote %s", remote)
+	}
+
+	return addrs, nil
+}
+
+// transferWorkers returns the number of transfers handled concurrently. Unless
+// batch mode is enabled with lfs.customtransfer.oci.batch, transfers are
+// handled one at a time. In batch mode, the lfs.concurrenttransfers setting
+// sent by git-lfs is honored.
+func transferWorkers(ctx context.Context, repo *gogit.Repository, initReq *lfs.InitRequest) int {
+	cfg, err := repo.ConfigScoped(gitconfig.SystemScope)
+	if err != nil {
+		slog.WarnContext(ctx, "reading git config, disabling LFS batch mode", slog.String("error", err.Error()))
+		return 1
+	}
+
+	raw := cfg.Raw.Section("lfs").Subsection("customtransfer." + lfsTransferAgent).Option("batch")
+	if batch, err := strconv.ParseBool(raw); err != nil || !batch {
+		return 1
+	}
+
+	workers := defaultConcurrentTransfers
+	if initReq.ConcurrentTransfers > 0 {
+		workers = initReq.ConcurrentTransfers
+	}
+	slog.DebugContext(ctx, "LFS batch mode enabled", slog.Int("workers", workers))
+
+	return workers
+}
+
+// configScoper reads merged git configuration, e.g. [gogit.Repository].
+type configScoper interface {
+	ConfigScoped(scope gitconfig.Scope) (*gitconfig.Config, error)
+}
+
+// lfsStoreAddress returns the address of the repository storing LFS files, if
+// remote.<remote>.lfsurl or lfs.url is an OCI address of a repository other
+// than the Git remote at gitAddr, e.g. a repository shared as an LFS cache.
+func lfsStoreAddress(ctx context.Context, repo configScoper, remote string, gitAddr ociutil.Address, defaultTag string) (ociutil.Address, bool) {
+	cfg, err := repo.ConfigScoped(gitconfig.SystemScope)
+	if err != nil {
+		slog.WarnContext(ctx, "reading git config, storing LFS files with the Git remote", slog.String("error", err.Error()))
+		return ociutil.Address{}, false
+	}
+
+	lfsURL := cfg.Raw.Section("remote").Subsection(remote).Option("lfsurl")
+	if lfsURL == "" {
+		lfsURL = cfg.Raw.Section("lfs").Option("url")
+	}
+	if !ociutil.HasScheme(lfsURL) {
+		return ociutil.Address{}, false
 	}
 
-	parsedRef, err := registry.ParseReference(remoteURL)
+	addr, err := ociutil.ParseAddressDefaultTag(lfsURL, defaultTag)
 	if err != nil {
-		return registry.Reference{}, fmt.Errorf("invalid reference %s: %w", remoteURL, err)
+		slog.WarnContext(ctx, "parsing LFS URL, storing LFS files with the Git remote", slog.String("url", lfsURL), slog.String("error", err.Error()))
+		return ociutil.Address{}, false
+	}
+	if addr.Layout == gitAddr.Layout && addr.Ref.Registry == gitAddr.Ref.Registry && addr.Ref.Repository == gitAddr.Ref.Repository {
+		return ociutil.Address{}, false
 	}
 
-	return parsedRef, nil
+	slog.DebugContext(ctx, "storing LFS files in a separate repository", slog.String("address", addr.String()))
+	return addr, true
 }
 
 // trimProtocol trims a oci:// protocol prefix.
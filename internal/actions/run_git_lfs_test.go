@@ -3,13 +3,28 @@ package actions
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/ociutil"
 	"github.com/act3-ai/gnoci/pkg/apis"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
+	"github.com/act3-ai/gnoci/pkg/protocol/lfs"
+	"github.com/act3-ai/gnoci/pkg/protocol/lfs/comms"
+	"github.com/go-git/go-git/v5"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestNewGitLFS(t *testing.T) {
@@ -63,3 +78,385 @@ func Test_trimProtocol(t *testing.T) {
 		assert.False(t, strings.Contains(got, "oci://"))
 	})
 }
+
+func Test_resolveAddresses(t *testing.T) {
+	dir := t.TempDir()
+	_, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, err = f.WriteString("[remote \"origin\"]\n\turl = oci://example.com/team/project:main\n\turl = https://example.com/team/project.git\n" +
+		"\tpushurl = oci://backup.example.com/team/project:main\n[remote \"http\"]\n\turl = https://example.com/team/project.git\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+
+	t.Run("Full URL", func(t *testing.T) {
+		got, err := resolveAddresses(t.Context(), "oci://example.com/other/project:main", repo, "")
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, "other/project", got[0].Ref.Repository)
+	})
+
+	t.Run("Default Tag", func(t *testing.T) {
+		got, err := resolveAddresses(t.Context(), "oci://example.com/other/project", repo, "main")
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, "main", got[0].Ref.Reference)
+	})
+
+	t.Run("Multiple URLs", func(t *testing.T) {
+		got, err := resolveAddresses(t.Context(), "origin", repo, "")
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, "example.com", got[0].Ref.Registry)
+		assert.Equal(t, "backup.example.com", got[1].Ref.Registry)
+	})
+
+	t.Run("No OCI URLs", func(t *testing.T) {
+		_, err := resolveAddresses(t.Context(), "http", repo, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown Remote", func(t *testing.T) {
+		_, err := resolveAddresses(t.Context(), "missing", repo, "")
+		assert.Error(t, err)
+	})
+}
+
+func Test_partialOffset(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("No Partial Download", func(t *testing.T) {
+		offset, err := partialOffset(filepath.Join(tmpDir, "missing"+partialSuffix), 10)
+		assert.NoError(t, err)
+		assert.Zero(t, offset)
+	})
+
+	t.Run("Partial Download", func(t *testing.T) {
+		partialPath := filepath.Join(tmpDir, "partial"+partialSuffix)
+		err := os.WriteFile(partialPath, []byte("foo"), 0644)
+		assert.NoError(t, err)
+
+		offset, err := partialOffset(partialPath, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), offset)
+	})
+
+	t.Run("Exceeds Size", func(t *testing.T) {
+		partialPath := filepath.Join(tmpDir, "invalid"+partialSuffix)
+		err := os.WriteFile(partialPath, []byte("foobar"), 0644)
+		assert.NoError(t, err)
+
+		offset, err := partialOffset(partialPath, 3)
+		assert.NoError(t, err)
+		assert.Zero(t, offset)
+		assert.NoFileExists(t, partialPath)
+	})
+}
+
+func Test_lfsPullPath(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+
+	got, err := lfsPullPath(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".git", "lfs", "tmp", "remote-oci"), got)
+}
+
+func Test_removeStaleDownloads(t *testing.T) {
+	dir := t.TempDir()
+	stale := time.Now().Add(-2 * time.Hour)
+	for name, modTime := range map[string]time.Time{
+		"stale" + partialSuffix: stale,
+		"stale":                 stale,
+		"stale" + lockSuffix:    stale,
+		"fresh" + partialSuffix: time.Now(),
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte("foo"), 0o600))
+		assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	removeStaleDownloads(t.Context(), dir, time.Hour)
+
+	assert.NoFileExists(t, filepath.Join(dir, "stale"+partialSuffix))
+	assert.NoFileExists(t, filepath.Join(dir, "stale"))
+	assert.FileExists(t, filepath.Join(dir, "stale"+lockSuffix), "locks may be held")
+	assert.FileExists(t, filepath.Join(dir, "fresh"+partialSuffix))
+}
+
+func Test_verifyLFSFile(t *testing.T) {
+	lfsPath := filepath.Join(t.TempDir(), "foo")
+	err := os.WriteFile(lfsPath, []byte("foo"), 0644)
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		err := verifyLFSFile(lfsPath, digest.FromString("foo").Encoded())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Digest Mismatch", func(t *testing.T) {
+		err := verifyLFSFile(lfsPath, digest.FromString("bar").Encoded())
+		assert.Error(t, err)
+	})
+}
+
+func Test_uploadChunkingFromConfig(t *testing.T) {
+	t.Run("Unset", func(t *testing.T) {
+		threshold, size := uploadChunkingFromConfig(v1alpha2.PushConfig{})
+		assert.Zero(t, threshold)
+		assert.Zero(t, size)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		thresholdQty := resource.MustParse("512Mi")
+		sizeQty := resource.MustParse("16Mi")
+
+		threshold, size := uploadChunkingFromConfig(v1alpha2.PushConfig{
+			LFSUploadChunkThreshold: &thresholdQty,
+			LFSUploadChunkSize:      &sizeQty,
+		})
+		assert.Equal(t, int64(512<<20), threshold)
+		assert.Equal(t, int64(16<<20), size)
+	})
+}
+
+func TestGitLFS_runTransfers(t *testing.T) {
+	requests := func(t *testing.T, oids ...string) *bytes.Buffer {
+		t.Helper()
+		in := new(bytes.Buffer)
+		enc := json.NewEncoder(in)
+		for _, oid := range oids {
+			assert.NoError(t, enc.Encode(lfs.TransferRequest{Event: lfs.UploadEvent, Oid: oid, Size: 1, Path: oid}))
+		}
+		assert.NoError(t, enc.Encode(lfs.TransferRequest{Event: lfs.TerminateEvent}))
+		return in
+	}
+
+	responses := func(t *testing.T, out *bytes.Buffer) []lfs.TransferResponse {
+		t.Helper()
+		var got []lfs.TransferResponse
+		for line := range strings.Lines(out.String()) {
+			var resp lfs.TransferResponse
+			assert.NoError(t, json.Unmarshal([]byte(line), &resp))
+			got = append(got, resp)
+		}
+		return got
+	}
+
+	respond := func(action *GitLFS) respondFunc {
+		return func(ctx context.Context, transferReq *lfs.TransferRequest, _ string, err error) error {
+			return action.comm.WriteTransferUploadResponse(ctx, transferReq.Oid, err)
+		}
+	}
+
+	t.Run("Batch", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		action := &GitLFS{comm: comms.NewCommunicator(requests(t, "a", "b", "c"), out), workers: 3}
+
+		var mu sync.Mutex
+		var inFlight, maxInFlight int
+		delays := map[string]time.Duration{"a": 30 * time.Millisecond, "b": 10 * time.Millisecond, "c": 0}
+		err := action.runTransfers(t.Context(), lfs.UploadEvent, func(_ context.Context, transferReq *lfs.TransferRequest) (string, error) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+
+			time.Sleep(delays[transferReq.Oid])
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return "", nil
+		}, respond(action))
+		assert.NoError(t, err)
+
+		// completed out of order, responses in order of request
+		got := responses(t, out)
+		assert.Len(t, got, 3)
+		for i, oid := range []string{"a", "b", "c"} {
+			assert.Equal(t, lfs.CompleteEvent, got[i].Event)
+			assert.Equal(t, oid, got[i].Oid)
+			assert.Nil(t, got[i].Error)
+		}
+		assert.Greater(t, maxInFlight, 1)
+	})
+
+	t.Run("Transfer Failure", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		action := &GitLFS{comm: comms.NewCommunicator(requests(t, "a", "b"), out)}
+
+		err := action.runTransfers(t.Context(), lfs.UploadEvent, func(_ context.Context, transferReq *lfs.TransferRequest) (string, error) {
+			if transferReq.Oid == "a" {
+				return "", errors.New("registry unavailable")
+			}
+			return "", nil
+		}, respond(action))
+		assert.NoError(t, err)
+
+		got := responses(t, out)
+		assert.Len(t, got, 2)
+		if assert.NotNil(t, got[0].Error) {
+			assert.Equal(t, "registry unavailable", got[0].Error.Message)
+		}
+		assert.Nil(t, got[1].Error)
+	})
+
+	t.Run("Unexpected Event", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		assert.NoError(t, json.NewEncoder(in).Encode(lfs.TransferRequest{Event: lfs.DownloadEvent, Oid: "a", Size: 1}))
+		action := &GitLFS{comm: comms.NewCommunicator(in, new(bytes.Buffer))}
+
+		err := action.runTransfers(t.Context(), lfs.UploadEvent, func(context.Context, *lfs.TransferRequest) (string, error) {
+			return "", nil
+		}, respond(action))
+		assert.Error(t, err)
+	})
+}
+
+func Test_transferWorkers(t *testing.T) {
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("HOME", t.TempDir())
+
+	newRepo := func(t *testing.T, batch string) *git.Repository {
+		t.Helper()
+		repo, err := git.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+		if batch != "" {
+			cfg, err := repo.Config()
+			assert.NoError(t, err)
+			cfg.Raw.Section("lfs").Subsection("customtransfer.oci").SetOption("batch", batch)
+			assert.NoError(t, repo.SetConfig(cfg))
+		}
+		return repo
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		got := transferWorkers(t.Context(), newRepo(t, ""), &lfs.InitRequest{ConcurrentTransfers: 3})
+		assert.Equal(t, 1, got)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		got := transferWorkers(t.Context(), newRepo(t, "true"), &lfs.InitRequest{ConcurrentTransfers: 3})
+		assert.Equal(t, 3, got)
+	})
+
+	t.Run("Default Concurrency", func(t *testing.T) {
+		got := transferWorkers(t.Context(), newRepo(t, "true"), &lfs.InitRequest{})
+		assert.Equal(t, defaultConcurrentTransfers, got)
+	})
+}
+
+func Test_lfsStoreAddress(t *testing.T) {
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("HOME", t.TempDir())
+
+	gitAddr, err := ociutil.ParseAddress("oci://example.com/team/project:main")
+	assert.NoError(t, err)
+
+	newRepo := func(t *testing.T, config string) *git.Repository {
+		t.Helper()
+		dir := t.TempDir()
+		_, err := git.PlainInit(dir, false)
+		assert.NoError(t, err)
+		f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0644)
+		assert.NoError(t, err)
+		_, err = f.WriteString(config)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+
+		repo, err := git.PlainOpen(dir)
+		assert.NoError(t, err)
+		return repo
+	}
+
+	t.Run("Remote LFS URL", func(t *testing.T) {
+		repo := newRepo(t, "[remote \"origin\"]\n\turl = oci://example.com/team/project:main\n\tlfsurl = oci://example.com/team/lfs-cache:main\n"+
+			"[lfs]\n\turl = oci://example.com/other/lfs:main\n")
+		got, ok := lfsStoreAddress(t.Context(), repo, "origin", gitAddr, "")
+		assert.True(t, ok)
+		assert.Equal(t, "example.com", got.Ref.Registry)
+		assert.Equal(t, "team/lfs-cache", got.Ref.Repository)
+	})
+
+	t.Run("LFS URL", func(t *testing.T) {
+		repo := newRepo(t, "[lfs]\n\turl = oci://example.com/other/lfs:main\n")
+		got, ok := lfsStoreAddress(t.Context(), repo, "origin", gitAddr, "")
+		assert.True(t, ok)
+		assert.Equal(t, "other/lfs", got.Ref.Repository)
+	})
+
+	t.Run("Same Repository", func(t *testing.T) {
+		repo := newRepo(t, "[lfs]\n\turl = oci://example.com/team/project:other\n")
+		_, ok := lfsStoreAddress(t.Context(), repo, "origin", gitAddr, "")
+		assert.False(t, ok)
+	})
+
+	t.Run("Not OCI", func(t *testing.T) {
+		repo := newRepo(t, "[lfs]\n\turl = https://example.com/team/project.git/info/lfs\n")
+		_, ok := lfsStoreAddress(t.Context(), repo, "origin", gitAddr, "")
+		assert.False(t, ok)
+	})
+
+	t.Run("Not Configured", func(t *testing.T) {
+		_, ok := lfsStoreAddress(t.Context(), newRepo(t, ""), "origin", gitAddr, "")
+		assert.False(t, ok)
+	})
+}
+
+// fakeLFSModeler overrides the LFS file transfers of a [model.LFSModeler].
+type fakeLFSModeler struct {
+	model.LFSModeler
+
+	pushErr  error
+	fetchErr error
+}
+
+func (f *fakeLFSModeler) PushLFSFile(context.Context, string, *model.PushLFSOptions) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, f.pushErr
+}
+
+func (f *fakeLFSModeler) FetchLFSLayer(context.Context, digest.Digest, *model.FetchLFSOptions) (io.ReadCloser, error) {
+	return nil, f.fetchErr
+}
+
+func TestGitLFS_transferProgress(t *testing.T) {
+	const oid = "ae1b1aa2a1af3ae1c1e1c4ec6a3a5b52ef7ae4f4b8e7ca5a3e4b1cd1a0aa1c5d"
+
+	t.Run("Upload Already Exists", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		action := &GitLFS{comm: comms.NewCommunicator(new(bytes.Buffer), out)}
+
+		// nothing is read, yet the final progress is reported
+		transferReq := &lfs.TransferRequest{Event: lfs.UploadEvent, Oid: oid, Size: 4, Path: oid}
+		err := action.uploadLFSLayer(t.Context(), transferReq, &fakeLFSModeler{})
+		assert.NoError(t, err)
+
+		var got lfs.ProgressResponse
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		assert.Equal(t, lfs.ProgressResponse{Event: lfs.ProgessEvent, Oid: oid, BytesSoFar: 4, BytesSinceLast: 4}, got)
+	})
+
+	t.Run("Upload Failure", func(t *testing.T) {
+		action := &GitLFS{comm: comms.NewCommunicator(new(bytes.Buffer), new(bytes.Buffer))}
+
+		transferReq := &lfs.TransferRequest{Event: lfs.UploadEvent, Oid: oid, Size: 4, Path: oid}
+		err := action.uploadLFSLayer(t.Context(), transferReq, &fakeLFSModeler{pushErr: errors.New("registry unavailable")})
+		assert.Error(t, err)
+	})
+
+	t.Run("Download Failure", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		action := &GitLFS{comm: comms.NewCommunicator(new(bytes.Buffer), out)}
+
+		// returns, rather than waiting for progress that never starts
+		transferReq := &lfs.TransferRequest{Event: lfs.DownloadEvent, Oid: oid, Size: 4}
+		err := action.fetchLFSLayer(t.Context(), transferReq, &fakeLFSModeler{fetchErr: errors.New("registry unavailable")}, filepath.Join(t.TempDir(), oid), 0)
+		assert.Error(t, err)
+		assert.Empty(t, out.String())
+	})
+}
@@ -3,15 +3,20 @@ package actions
 
 import (
 	"bytes"
+	"encoding/base64"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/ociutil"
 	"github.com/act3-ai/gnoci/internal/testutils"
 	"github.com/act3-ai/gnoci/pkg/apis"
-	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
 	"github.com/act3-ai/gnoci/pkg/oci"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
 	gogit "github.com/go-git/go-git/v5"
@@ -20,19 +25,22 @@ import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"oras.land/oras-go/v2/errdef"
 )
 
 func TestNewGit(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		in := new(bytes.Buffer)
 		out := new(bytes.Buffer)
+		errOut := new(bytes.Buffer)
 		gitDir := ".git"
 		shortname := "foo"
 		address := "oci://example.com/repo/test:sync"
 		version := "v1.0.0"
 		cfgFiles := []string{"/tmp/foo"}
 
-		gotGit := NewGit(in, out, gitDir, shortname, address, version, cfgFiles)
+		gotGit := NewGit(in, out, errOut, gitDir, shortname, address, version, cfgFiles)
 		assert.NotNil(t, gotGit)
 		assert.Equal(t, version, gotGit.version)
 		assert.NotNil(t, gotGit.apiScheme)
@@ -40,6 +48,7 @@ func TestNewGit(t *testing.T) {
 		assert.Equal(t, gitDir, gotGit.gitDir)
 		assert.Equal(t, shortname, gotGit.name)
 		assert.NotNil(t, gotGit.comm)
+		assert.Equal(t, errOut, gotGit.opts.ProgressOut)
 		assert.False(t, strings.Contains(gotGit.address, "oci://"))
 	})
 }
@@ -108,7 +117,7 @@ func TestGit_handleList(t *testing.T) {
 		}
 
 		modelMock.EXPECT().
-			FetchOrDefault(gomock.Any()).
+			FetchConfigOnly(gomock.Any()).
 			Return(ocispec.Descriptor{}, nil)
 
 		modelMock.EXPECT().
@@ -121,6 +130,16 @@ func TestGit_handleList(t *testing.T) {
 			Return(expectedTags).
 			Times(1)
 
+		modelMock.EXPECT().
+			NoteRefs().
+			Return(map[plumbing.ReferenceName]oci.ReferenceInfo{}).
+			Times(1)
+
+		modelMock.EXPECT().
+			DefaultBranch().
+			Return(plumbing.Main).
+			Times(1)
+
 		in := new(bytes.Buffer)
 		out := new(bytes.Buffer)
 
@@ -140,6 +159,40 @@ func TestGit_handleList(t *testing.T) {
 		err = action.handleList(t.Context())
 		assert.NoError(t, err)
 	})
+
+	t.Run("Remote Not Found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockModeler(ctrl)
+
+		gomock.InOrder(
+			modelMock.EXPECT().
+				FetchConfigOnly(gomock.Any()).
+				Return(ocispec.Descriptor{}, fmt.Errorf("resolving: %w", errdef.ErrNotFound)),
+			modelMock.EXPECT().
+				FetchOrDefault(gomock.Any()).
+				Return(ocispec.Descriptor{}, nil),
+		)
+		modelMock.EXPECT().HeadRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+		modelMock.EXPECT().TagRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+		modelMock.EXPECT().NoteRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendListRequest(true)
+		assert.NoError(t, err)
+
+		action := &Git{
+			remote: modelMock,
+			comm:   comm,
+		}
+
+		err = action.handleList(t.Context())
+		assert.NoError(t, err)
+	})
 }
 
 func TestGit_GetScheme(t *testing.T) {
@@ -174,13 +227,11 @@ func TestGit_GetConfig(t *testing.T) {
 func Test_repoOptsFromConfig(t *testing.T) {
 	t.Run("Plain HTTP Enabled", func(t *testing.T) {
 		host := "example.com"
-		cfg := v1alpha1.Configuration{
-			ConfigurationSpec: v1alpha1.ConfigurationSpec{
-				RegistryConfig: v1alpha1.RegistryConfig{
-					Registries: map[string]v1alpha1.Registry{
-						host: {
-							PlainHTTP: true,
-						},
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					host: {
+						PlainHTTP: true,
 					},
 				},
 			},
@@ -192,15 +243,37 @@ func Test_repoOptsFromConfig(t *testing.T) {
 		assert.True(t, gotOpts.PlainHTTP)
 	})
 
+	t.Run("Retry", func(t *testing.T) {
+		retryTooManyRequests := false
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Transfer: v1alpha2.TransferConfig{
+					Retry: v1alpha2.RetryConfig{
+						MaxAttempts:          3,
+						InitialBackoff:       &metav1.Duration{Duration: time.Second},
+						MaxBackoff:           &metav1.Duration{Duration: 10 * time.Second},
+						RetryTooManyRequests: &retryTooManyRequests,
+					},
+				},
+			},
+		}
+
+		gotOpts := repoOptsFromConfig("example.com", &cfg)
+		assert.Equal(t, ociutil.RetryPolicy{
+			MaxAttempts:            3,
+			InitialBackoff:         time.Second,
+			MaxBackoff:             10 * time.Second,
+			NoRetryTooManyRequests: true,
+		}, gotOpts.Retry)
+	})
+
 	t.Run("NonCompliant Enabled", func(t *testing.T) {
 		host := "example.com"
-		cfg := v1alpha1.Configuration{
-			ConfigurationSpec: v1alpha1.ConfigurationSpec{
-				RegistryConfig: v1alpha1.RegistryConfig{
-					Registries: map[string]v1alpha1.Registry{
-						host: {
-							NonCompliant: true,
-						},
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					host: {
+						NonCompliant: true,
 					},
 				},
 			},
@@ -211,4 +284,466 @@ func Test_repoOptsFromConfig(t *testing.T) {
 
 		assert.True(t, gotOpts.NonCompliant)
 	})
+
+	t.Run("Referrers Tag Schema Enabled", func(t *testing.T) {
+		host := "example.com"
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					host: {
+						ReferrersTagSchema: true,
+					},
+				},
+			},
+		}
+
+		gotOpts := repoOptsFromConfig(host, &cfg)
+		assert.NotNil(t, gotOpts)
+
+		assert.True(t, gotOpts.ReferrersTagSchema)
+	})
+
+	t.Run("Mirrors", func(t *testing.T) {
+		host := "example.com"
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					host: {
+						Mirrors: []string{"127.0.0.1:5000", "mirror.example.com"},
+					},
+					"127.0.0.1:5000": {
+						PlainHTTP: true,
+					},
+				},
+			},
+		}
+
+		gotOpts := repoOptsFromConfig(host, &cfg)
+		assert.NotNil(t, gotOpts)
+
+		assert.Equal(t, []ociutil.Mirror{
+			{Registry: "127.0.0.1:5000", PlainHTTP: true},
+			{Registry: "mirror.example.com"},
+		}, gotOpts.Mirrors)
+	})
+
+	t.Run("Credential Helpers", func(t *testing.T) {
+		helpers := map[string]string{"example.com": "ecr-login"}
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				CredHelpers: helpers,
+			},
+		}
+
+		gotOpts := repoOptsFromConfig("example.com", &cfg)
+		assert.NotNil(t, gotOpts)
+
+		assert.Equal(t, helpers, gotOpts.CredHelpers)
+	})
+
+	t.Run("Proxy", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					"example.com": {
+						ProxyURL:          "http://proxy.example.com:3128",
+						ProxyUsername:     "gnoci",
+						ProxyPasswordFile: "/run/secrets/proxy-password",
+						NoProxy:           []string{".s3.example.com"},
+						Mirrors:           []string{"mirror.example.com"},
+					},
+					"mirror.example.com": {
+						ProxyURL: "socks5://proxy.example.com:1080",
+					},
+				},
+			},
+		}
+
+		gotOpts := repoOptsFromConfig("example.com", &cfg)
+		assert.NotNil(t, gotOpts)
+
+		assert.Equal(t, ociutil.Proxy{
+			URL:          "http://proxy.example.com:3128",
+			Username:     "gnoci",
+			PasswordFile: "/run/secrets/proxy-password",
+			NoProxy:      []string{".s3.example.com"},
+		}, gotOpts.Proxy)
+		assert.Equal(t, []ociutil.Mirror{
+			{Registry: "mirror.example.com", Proxy: ociutil.Proxy{URL: "socks5://proxy.example.com:1080"}},
+		}, gotOpts.Mirrors)
+	})
+
+	t.Run("Socket", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					"localhost:5000": {
+						PlainHTTP: true,
+						Socket:    "/var/run/registry/registry.sock",
+						Mirrors:   []string{"mirror.example.com"},
+					},
+					"mirror.example.com": {
+						Socket: "/var/run/mirror/mirror.sock",
+					},
+				},
+			},
+		}
+
+		gotOpts := repoOptsFromConfig("localhost:5000", &cfg)
+		assert.NotNil(t, gotOpts)
+
+		assert.Equal(t, "/var/run/registry/registry.sock", gotOpts.Socket)
+		assert.Equal(t, []ociutil.Mirror{
+			{Registry: "mirror.example.com", Socket: "/var/run/mirror/mirror.sock"},
+		}, gotOpts.Mirrors)
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					"example.com": {
+						TLS: &v1alpha2.TLSConfig{
+							CertDir:    "/etc/gnoci/certs/example.com",
+							MinVersion: "1.3",
+						},
+						Mirrors: []string{"mirror.example.com"},
+					},
+					"mirror.example.com": {
+						TLS: &v1alpha2.TLSConfig{
+							InsecureSkipVerify: true,
+							ClientCertificates: []v1alpha2.ClientCertificate{
+								{Repository: "team-a", CertFile: "team-a.crt", KeyFile: "team-a.key"},
+								{CertEnv: "CLIENT_CERT", KeyEnv: "CLIENT_KEY"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		gotOpts := repoOptsFromConfig("example.com", &cfg)
+		assert.NotNil(t, gotOpts)
+
+		assert.Equal(t, ociutil.TLS{CertDir: "/etc/gnoci/certs/example.com", MinVersion: "1.3"}, gotOpts.TLS)
+		assert.Equal(t, []ociutil.Mirror{
+			{Registry: "mirror.example.com", TLS: ociutil.TLS{
+				InsecureSkipVerify: true,
+				ClientCertificates: []ociutil.ClientCertificate{
+					{Repository: "team-a", CertFile: "team-a.crt", KeyFile: "team-a.key"},
+					{CertEnv: "CLIENT_CERT", KeyEnv: "CLIENT_KEY"},
+				},
+			}},
+		}, gotOpts.Mirrors)
+	})
+
+	t.Run("OAuth2", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Registries: map[string]v1alpha2.Registry{
+					"example.com": {
+						Mirrors: []string{"mirror.example.com"},
+					},
+					"mirror.example.com": {
+						OAuth2: &v1alpha2.OAuth2Config{
+							Flow:     v1alpha2.OAuth2ClientCredentials,
+							TokenURL: "https://auth.example.com/token",
+							ClientID: "gnoci",
+						},
+					},
+				},
+			},
+		}
+
+		gotOpts := repoOptsFromConfig("example.com", &cfg)
+		assert.NotNil(t, gotOpts)
+
+		// providers of all registries are configured, as mirrors share the
+		// credential store
+		assert.Len(t, gotOpts.AuthProviders, 1)
+		provider, ok := gotOpts.AuthProviders["mirror.example.com"].(*ociutil.OAuth2Provider)
+		assert.True(t, ok)
+		assert.Equal(t, ociutil.OAuth2ClientCredentials, provider.Flow)
+		assert.Equal(t, "https://auth.example.com/token", provider.TokenURL)
+		assert.Equal(t, ociutil.DefaultTokenCacheDir(), provider.CacheDir)
+	})
+}
+
+func Test_modelOptsFromConfig(t *testing.T) {
+	t.Setenv(sourceDateEpochEnv, "")
+
+	t.Run("Default", func(t *testing.T) {
+		gotOpts, err := modelOptsFromConfig(t.Context(), &v1alpha2.Configuration{})
+		assert.NoError(t, err)
+		assert.Empty(t, gotOpts)
+	})
+
+	t.Run("Zstd", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{Compression: v1alpha2.CompressionZstd},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Pack Index", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{PackIndex: true},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("LFS Chunking", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{LFSChunking: true},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("SHA-512", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{DigestAlgorithm: v1alpha2.DigestSHA512},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Unsupported Digest Algorithm", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{DigestAlgorithm: "md5"},
+			},
+		}
+
+		_, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete Orphaned Layers", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{DeleteOrphanedLayers: true},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Transfer: v1alpha2.TransferConfig{Concurrency: 8},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Negative Concurrency", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Transfer: v1alpha2.TransferConfig{Concurrency: -1},
+			},
+		}
+
+		_, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Mount From", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{MountFrom: []string{"team/project"}},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Cache: v1alpha2.CacheConfig{Enabled: true, Dir: t.TempDir()},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Timestamp Now", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{Timestamp: v1alpha2.TimestampNow, SourceURL: "https://example.com/team/project.git"},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 2)
+	})
+
+	t.Run("Source Date Epoch", func(t *testing.T) {
+		t.Setenv(sourceDateEpochEnv, "1700000000")
+		gotOpts, err := modelOptsFromConfig(t.Context(), &v1alpha2.Configuration{})
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+
+		t.Setenv(sourceDateEpochEnv, "yesterday")
+		_, err = modelOptsFromConfig(t.Context(), &v1alpha2.Configuration{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Unsupported Timestamp", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{Timestamp: "later"},
+			},
+		}
+
+		_, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Annotations", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{Annotations: v1alpha2.PushAnnotations{
+					Manifest: map[string]string{"com.example.team": "platform"},
+				}},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Reserved Annotation", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{Annotations: v1alpha2.PushAnnotations{
+					Layers: map[string]string{"vnd.ai.act3.git.pack.refs": "{}"},
+				}},
+			},
+		}
+
+		_, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.ErrorIs(t, err, model.ErrReservedAnnotation)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{Compression: "gzip"},
+			},
+		}
+
+		_, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing Signing Key", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Push: v1alpha2.PushConfig{SigningKey: filepath.Join(t.TempDir(), "missing.key")},
+			},
+		}
+
+		_, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Encryption", func(t *testing.T) {
+		encoded := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+		keyPath := filepath.Join(t.TempDir(), "layers.key")
+		assert.NoError(t, os.WriteFile(keyPath, []byte(encoded+"\n"), 0o600))
+
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				Encryption: v1alpha2.EncryptionConfig{Keys: []v1alpha2.EncryptionKey{
+					{ID: "file", File: keyPath},
+					{Command: []string{"echo", encoded}},
+				}},
+			},
+		}
+
+		gotOpts, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.NoError(t, err)
+		assert.Len(t, gotOpts, 1)
+	})
+
+	t.Run("Invalid Encryption Key", func(t *testing.T) {
+		for _, key := range []v1alpha2.EncryptionKey{
+			{},
+			{File: filepath.Join(t.TempDir(), "missing.key")},
+			{File: "layers.key", Command: []string{"echo"}},
+			{Command: []string{"false"}},
+			{Command: []string{"echo", "not a key"}},
+		} {
+			cfg := v1alpha2.Configuration{
+				ConfigurationSpec: v1alpha2.ConfigurationSpec{
+					Encryption: v1alpha2.EncryptionConfig{Keys: []v1alpha2.EncryptionKey{key}},
+				},
+			}
+
+			_, err := modelOptsFromConfig(t.Context(), &cfg)
+			assert.Error(t, err, "%+v", key)
+		}
+	})
+
+	t.Run("Missing Verification Key", func(t *testing.T) {
+		cfg := v1alpha2.Configuration{
+			ConfigurationSpec: v1alpha2.ConfigurationSpec{
+				VerifyPolicy: v1alpha2.VerifyPolicy{Keys: []string{filepath.Join(t.TempDir(), "missing.pub")}},
+			},
+		}
+
+		_, err := modelOptsFromConfig(t.Context(), &cfg)
+		assert.Error(t, err)
+	})
+}
+
+func Test_lockOptsFromConfig(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		assert.Nil(t, lockOptsFromConfig(v1alpha2.PushLockConfig{Timeout: &metav1.Duration{Duration: time.Minute}}))
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		assert.Equal(t, &model.LockOptions{}, lockOptsFromConfig(v1alpha2.PushLockConfig{Enabled: true}))
+	})
+
+	t.Run("Durations", func(t *testing.T) {
+		got := lockOptsFromConfig(v1alpha2.PushLockConfig{
+			Enabled: true,
+			Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+			TTL:     &metav1.Duration{Duration: 30 * time.Second},
+		})
+		assert.Equal(t, &model.LockOptions{Timeout: 10 * time.Minute, TTL: 30 * time.Second}, got)
+	})
 }
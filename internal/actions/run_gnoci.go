@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"oras.land/oras-go/v2"
+
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/pkg/apis"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
+)
+
+// Gnoci represents the base action of the gnoci command, shared by its
+// subcommands.
+type Gnoci struct {
+	version   string
+	apiScheme *runtime.Scheme
+	// ConfigFiles contains a list of potential configuration file locations.
+	ConfigFiles []string
+}
+
+// NewGnoci creates a new Gnoci with default values.
+func NewGnoci(version string, cfgFiles []string) *Gnoci {
+	return &Gnoci{
+		version:     version,
+		apiScheme:   apis.NewScheme(),
+		ConfigFiles: cfgFiles,
+	}
+}
+
+// GetScheme returns the runtime scheme used for configuration file loading.
+func (action *Gnoci) GetScheme() *runtime.Scheme {
+	return action.apiScheme
+}
+
+// GetConfig loads Configuration using the current gnoci options.
+func (action *Gnoci) GetConfig(ctx context.Context) (*v1alpha2.Configuration, error) {
+	c, err := loadConfig(ctx, action.GetScheme(), action.ConfigFiles)
+	if err != nil {
+		return nil, fmt.Errorf("loading configuration: %w", err)
+	}
+	return c, nil
+}
+
+// remote initializes a connection to the OCI remote at address, an oci://
+// reference or oci+layout:// or oci+tar:// OCI image layout. If verify is false, the
+// configured verification policy is not enforced on fetch. The model options
+// opts are applied after those of the configuration.
+//
+// It is the caller's responsibility to call the returned cleanup function.
+func (action *Gnoci) remote(ctx context.Context, address string, verify bool, opts ...model.Option) (model.LFSModeler, func() error, error) {
+	addr, gt, modelOpts, err := action.connect(ctx, address, verify, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fstorePath, fstore, err := newFileStore()
+	if err != nil {
+		return nil, nil, fmt.Errorf("initializing: %w", errors.Join(err, closeGraphTarget(gt)))
+	}
+
+	cleanup := func() error {
+		var errs []error
+		if err := fstore.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing OCI file store: %w", err))
+		}
+		if err := os.RemoveAll(fstorePath); err != nil {
+			errs = append(errs, fmt.Errorf("removing temporary files: %w", err))
+		}
+		if err := closeGraphTarget(gt); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	return model.NewLFSModeler(addr.Ref, fstore, gt, modelOpts...), cleanup, nil
+}
+
+// readOnlyRemote extends [Gnoci.remote] for actions only reading the remote,
+// which need no intermediate file store, and so no temporary files.
+//
+// It is the caller's responsibility to call the returned cleanup function.
+func (action *Gnoci) readOnlyRemote(ctx context.Context, address string, verify bool, opts ...model.Option) (model.ReadOnlyLFSModeler, func() error, error) {
+	addr, gt, modelOpts, err := action.connect(ctx, address, verify, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() error {
+		return closeGraphTarget(gt)
+	}
+
+	return model.NewReadOnlyLFSModeler(addr.Ref, gt, modelOpts...), cleanup, nil
+}
+
+// connect initializes the graph target of the OCI remote at address and the
+// model options of the configuration, followed by opts. See [Gnoci.remote].
+//
+// It is the caller's responsibility to close the graph target if it is an
+// [io.Closer].
+func (action *Gnoci) connect(ctx context.Context, address string, verify bool, opts ...model.Option) (ociutil.Address, oras.GraphTarget, []model.Option, error) {
+	cfg, err := action.GetConfig(ctx)
+	if err != nil {
+		return ociutil.Address{}, nil, nil, fmt.Errorf("getting configuration: %w", err)
+	}
+
+	addr, err := ociutil.ParseAddressDefaultTag(address, cfg.DefaultTag)
+	if err != nil {
+		return ociutil.Address{}, nil, nil, fmt.Errorf("parsing remote address: %w", err)
+	}
+
+	if !verify {
+		cfg.VerifyPolicy.Keys = nil
+	}
+	modelOpts, err := modelOptsFromConfig(ctx, cfg)
+	if err != nil {
+		return ociutil.Address{}, nil, nil, err
+	}
+	modelOpts = append(modelOpts, registryModelOpts(addr.Ref.Host(), cfg)...)
+	modelOpts = append(modelOpts, opts...)
+
+	repoOpts := repoOptsFromConfig(addr.Ref.Host(), cfg)
+	repoOpts.UserAgent = ociutil.GnociUserAgent
+
+	gt, err := newGraphTarget(ctx, addr, repoOpts)
+	if err != nil {
+		return ociutil.Address{}, nil, nil, fmt.Errorf("initializing: %w", err)
+	}
+
+	return addr, gt, modelOpts, nil
+}
+
+// annotationsOpt returns the model option adding the manifest annotations of
+// the --annotation flag, after validating none are reserved.
+func annotationsOpt(annotations map[string]string) (model.Option, error) {
+	a := model.Annotations{Manifest: annotations}
+	if err := a.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid annotations: %w", err)
+	}
+	return model.WithAnnotations(a), nil
+}
@@ -0,0 +1,331 @@
+package actions
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+
+	"github.com/act3-ai/gnoci/internal/bundle"
+	"github.com/act3-ai/gnoci/internal/cmd"
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+var (
+	// ErrBundlePush indicates some references of a bundle failed to push.
+	ErrBundlePush = errors.New("failed to push bundle references")
+	// ErrBundlePrerequisite indicates a bundle depends on commits missing from
+	// the remote repository.
+	ErrBundlePrerequisite = errors.New("bundle prerequisite not in remote repository")
+)
+
+// BundleCreate represents the gnoci bundle create action.
+type BundleCreate struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// File is the path of the Git bundle to write.
+	File string
+}
+
+// Run fetches the entire remote repository into a temporary repository, and
+// writes all of its heads, tags, and notes to a Git bundle. HEAD is included
+// if the remote has a default branch, such that cloning the bundle checks it
+// out.
+func (action *BundleCreate) Run(ctx context.Context, out io.Writer) error {
+	remote, cleanup, err := action.remote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gnoci-bundle-*")
+	if err != nil {
+		return fmt.Errorf("initializing temp directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.ErrorContext(ctx, "removing temporary git repository", slog.String("error", err.Error()))
+		}
+	}()
+
+	repo, err := git.PlainInit(tmpDir, true)
+	if err != nil {
+		return fmt.Errorf("initializing temporary repository: %w", err)
+	}
+
+	refs, err := fetchAllRefs(ctx, git.NewRepository(repo), remote)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("%w: remote repository has no references", model.ErrReferenceNotFound)
+	}
+
+	header := &bundle.Header{ObjectFormat: remote.ObjectFormat()}
+	if head := remote.DefaultBranch(); head != "" {
+		i := slices.IndexFunc(refs, func(ref *plumbing.Reference) bool { return ref.Name() == head })
+		if i >= 0 {
+			header.References = append(header.References, plumbing.NewHashReference(plumbing.HEAD, refs[i].Hash()))
+		}
+	}
+	header.References = append(header.References, refs...)
+
+	if err := writeBundle(ctx, repo, header, action.File); err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if _, err := fmt.Fprintf(out, " + %s\n", ref.Name()); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeBundle writes a Git bundle of the references of header, and all
+// objects reachable from them, to path. The file is removed on failure.
+func writeBundle(ctx context.Context, repo *gogit.Repository, header *bundle.Header, path string) (err error) {
+	tips := make([]plumbing.Hash, 0, len(header.References))
+	for _, ref := range header.References {
+		tips = append(tips, ref.Hash())
+	}
+	objs, err := revlist.Objects(repo.Storer, tips, nil)
+	if err != nil {
+		return fmt.Errorf("listing objects: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating bundle: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, f.Close(), os.Remove(path))
+		}
+	}()
+
+	slog.InfoContext(ctx, "writing bundle", slog.String("path", path), slog.Int("objects", len(objs)))
+	w := bufio.NewWriter(f)
+	if err := header.Encode(w); err != nil {
+		return err //nolint:wrapcheck
+	}
+	if _, err := packfile.NewEncoder(w, repo.Storer, false).Encode(objs, 10); err != nil {
+		return fmt.Errorf("encoding packfile: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing bundle: %w", err)
+	}
+
+	return nil
+}
+
+// BundlePush represents the gnoci bundle push action.
+type BundlePush struct {
+	*Gnoci
+
+	// File is the path of the Git bundle to read.
+	File string
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Force overwrites references of the remote repository which are not
+	// ancestors of the bundled references.
+	Force bool
+	// Annotations are added to the pushed Git manifest, in addition to
+	// those of the configuration.
+	Annotations map[string]string
+}
+
+// Run unpacks a Git bundle into a temporary repository, and pushes its heads,
+// tags, and notes to the remote repository, failing with [ErrBundlePush] if
+// any are rejected. The remote repository is fetched first if the bundle has
+// prerequisites, failing with [ErrBundlePrerequisite] if any are missing.
+func (action *BundlePush) Run(ctx context.Context, out io.Writer) error {
+	cfg, err := action.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("getting configuration: %w", err)
+	}
+
+	f, err := os.Open(action.File)
+	if err != nil {
+		return fmt.Errorf("opening bundle: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header, err := bundle.Decode(r)
+	if err != nil {
+		return fmt.Errorf("reading bundle %s: %w", action.File, err)
+	}
+	if err := model.CheckObjectFormat(header.ObjectFormat); err != nil {
+		return fmt.Errorf("reading bundle %s: %w", action.File, err)
+	}
+
+	annotations, err := annotationsOpt(action.Annotations)
+	if err != nil {
+		return err
+	}
+	remote, cleanup, err := action.remote(ctx, action.Address, true, annotations)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	unlock, err := lockRemote(ctx, remote, lockOptsFromConfig(cfg.Push.Lock))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := remote.FetchOrEmpty(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gnoci-bundle-*")
+	if err != nil {
+		return fmt.Errorf("initializing temp directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.ErrorContext(ctx, "removing temporary git repository", slog.String("error", err.Error()))
+		}
+	}()
+
+	repo, err := git.PlainInit(tmpDir, true)
+	if err != nil {
+		return fmt.Errorf("initializing temporary repository: %w", err)
+	}
+	local := git.NewRepository(repo)
+
+	if err := unpackBundle(ctx, local, remote, header, r); err != nil {
+		return err
+	}
+
+	reqs, err := bundleRequests(ctx, local, remote, header, action.Force)
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		if _, err := fmt.Fprintln(out, "Everything up-to-date"); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		return nil
+	}
+
+	opts := cmd.Options{}
+	if err := applyPushConfig(&opts, cfg.Push); err != nil {
+		return err
+	}
+	results, err := cmd.Push(ctx, local, remote, reqs, &opts)
+	if err != nil {
+		return fmt.Errorf("pushing to remote: %w", err)
+	}
+
+	return writePushResults(out, reqs, results, ErrBundlePush)
+}
+
+// unpackBundle writes the packfile of a bundle, read by r, to local. The
+// prerequisites of the bundle are fetched from remote first, as the packfile
+// may be thin.
+func unpackBundle(ctx context.Context, local git.Repository, remote model.ReadOnlyModeler, header *bundle.Header, r io.Reader) error {
+	thin := len(header.Prerequisites) > 0
+	if thin {
+		if _, err := fetchAllRefs(ctx, local, remote); err != nil {
+			return err
+		}
+		st := local.Storer()
+		for _, hash := range header.Prerequisites {
+			if err := st.HasEncodedObject(hash); err != nil {
+				return fmt.Errorf("%w: %s", ErrBundlePrerequisite, hash)
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "unpacking bundle")
+	if err := model.UnpackPack(local.Storer(), r, thin); err != nil {
+		return fmt.Errorf("unpacking bundle packfile: %w", err)
+	}
+
+	return nil
+}
+
+// bundleRequests creates the bundled heads, tags, and notes in local,
+// returning the push requests updating those of remote. References already up
+// to date are omitted, and other bundled references skipped. HEAD points to a bundled branch at
+// the same commit, if any, such that the remote's default branch follows it.
+func bundleRequests(ctx context.Context, local git.Repository, remote model.Modeler, header *bundle.Header, force bool) ([]gittypes.PushRequest, error) {
+	remoteRefs := make(map[plumbing.ReferenceName]string)
+	for _, refs := range []map[plumbing.ReferenceName]oci.ReferenceInfo{remote.HeadRefs(), remote.TagRefs(), remote.NoteRefs()} {
+		for name, info := range refs {
+			remoteRefs[name] = info.Commit
+		}
+	}
+
+	st := local.Storer()
+	var head *plumbing.Reference
+	var reqs []gittypes.PushRequest
+	for _, ref := range header.References {
+		name := ref.Name()
+		switch {
+		case name == plumbing.HEAD:
+			head = ref
+			continue
+		case !name.IsBranch() && !name.IsTag() && !name.IsNote():
+			slog.WarnContext(ctx, "skipping bundled reference", slog.String("reference", name.String()))
+			continue
+		}
+		if err := st.SetReference(ref); err != nil {
+			return nil, fmt.Errorf("creating reference %s: %w", name, err)
+		}
+		if remoteRefs[name] == ref.Hash().String() {
+			continue
+		}
+		reqs = append(reqs, gittypes.PushRequest{Cmd: gittypes.Push, Force: force, Src: name, Remote: name})
+	}
+
+	if head != nil {
+		i := slices.IndexFunc(header.References, func(ref *plumbing.Reference) bool {
+			return ref.Name().IsBranch() && ref.Hash() == head.Hash()
+		})
+		if i >= 0 {
+			if err := st.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, header.References[i].Name())); err != nil {
+				return nil, fmt.Errorf("setting HEAD: %w", err)
+			}
+		}
+	}
+
+	slices.SortFunc(reqs, func(a, b gittypes.PushRequest) int {
+		return cmp.Compare(a.Remote, b.Remote)
+	})
+	return reqs, nil
+}
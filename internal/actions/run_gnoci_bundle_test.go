@@ -0,0 +1,127 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/bundle"
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/apis"
+)
+
+func TestBundle_Run(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	first, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	second, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateTag("v1", first)
+	assert.NoError(t, err)
+
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	address := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+	err = (&Mirror{Gnoci: base, Source: srcDir, Address: address}).Run(t.Context(), new(bytes.Buffer))
+	assert.NoError(t, err)
+
+	bundlePath := filepath.Join(t.TempDir(), "repo.bundle")
+	out := new(bytes.Buffer)
+	err = (&BundleCreate{Gnoci: base, Address: address, File: bundlePath}).Run(t.Context(), out)
+	assert.NoError(t, err)
+	assert.Equal(t, " + refs/heads/master\n + refs/tags/v1\n", out.String())
+
+	f, err := os.Open(bundlePath)
+	assert.NoError(t, err)
+	header, err := bundle.Decode(bufio.NewReader(f))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	assert.Empty(t, header.Prerequisites)
+	assert.Equal(t, []*plumbing.Reference{
+		plumbing.NewHashReference(plumbing.HEAD, second),
+		plumbing.NewHashReference("refs/heads/master", second),
+		plumbing.NewHashReference("refs/tags/v1", first),
+	}, header.References)
+
+	t.Run("Push", func(t *testing.T) {
+		dst := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+		out := new(bytes.Buffer)
+		err := (&BundlePush{Gnoci: base, File: bundlePath, Address: dst}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Equal(t, " + refs/heads/master\n + refs/tags/v1\n", out.String())
+
+		restored := t.TempDir()
+		_, err = gogit.PlainInit(restored, true)
+		assert.NoError(t, err)
+		err = (&Restore{Gnoci: base, Address: dst, Destination: restored}).Run(t.Context(), new(bytes.Buffer))
+		assert.NoError(t, err)
+		repo, err := gogit.PlainOpen(restored)
+		assert.NoError(t, err)
+		_, err = repo.CommitObject(second)
+		assert.NoError(t, err)
+
+		remote, cleanup, err := base.remote(t.Context(), dst, true)
+		assert.NoError(t, err)
+		defer cleanup() //nolint:errcheck
+		_, err = remote.Fetch(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.ReferenceName("refs/heads/master"), remote.DefaultBranch())
+
+		out.Reset()
+		err = (&BundlePush{Gnoci: base, File: bundlePath, Address: dst}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Equal(t, "Everything up-to-date\n", out.String())
+	})
+
+	// a bundle of the second commit alone
+	thinBundle := func(t *testing.T) string {
+		t.Helper()
+
+		st := builder.Repo().Storer
+		objs, err := revlist.Objects(st, []plumbing.Hash{second}, []plumbing.Hash{first})
+		assert.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "thin.bundle")
+		buf := new(bytes.Buffer)
+		header := &bundle.Header{
+			Prerequisites: []plumbing.Hash{first},
+			References:    []*plumbing.Reference{plumbing.NewHashReference("refs/heads/feature", second)},
+		}
+		assert.NoError(t, header.Encode(buf))
+		_, err = packfile.NewEncoder(buf, st, false).Encode(objs, 10)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+		return path
+	}
+
+	t.Run("Prerequisites", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&BundlePush{Gnoci: base, File: thinBundle(t), Address: address}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Equal(t, " + refs/heads/feature\n", out.String())
+	})
+
+	t.Run("Missing Prerequisites", func(t *testing.T) {
+		dst := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+		err := (&BundlePush{Gnoci: base, File: thinBundle(t), Address: dst}).Run(t.Context(), new(bytes.Buffer))
+		assert.ErrorIs(t, err, ErrBundlePrerequisite)
+	})
+
+	t.Run("Invalid Bundle", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "invalid.bundle")
+		assert.NoError(t, os.WriteFile(path, []byte("PACK"), 0o600))
+		err := (&BundlePush{Gnoci: base, File: path, Address: address}).Run(t.Context(), new(bytes.Buffer))
+		assert.ErrorIs(t, err, bundle.ErrInvalidBundle)
+	})
+}
@@ -0,0 +1,36 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// CachePrune represents the gnoci cache prune action.
+type CachePrune struct {
+	*Gnoci
+
+	// KeepSize is the total size, in bytes, of cached blobs to keep, evicting
+	// the least recently used. Zero empties the cache.
+	KeepSize int64
+}
+
+// Run evicts blobs from the local cache, whether or not it is enabled.
+func (action *CachePrune) Run(ctx context.Context, out io.Writer) error {
+	cfg, err := action.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("getting configuration: %w", err)
+	}
+
+	c := cacheFromConfig(cfg.Cache)
+	removed, freed, err := c.Prune(ctx, action.KeepSize)
+	if err != nil {
+		return fmt.Errorf("pruning cache %s: %w", c.Dir(), err)
+	}
+
+	if _, err := fmt.Fprintf(out, "Removed %d cached blobs, freeing %d bytes\n", removed, freed); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// Delete represents the gnoci delete action.
+type Delete struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// DryRun lists the content that would be deleted, without deleting it.
+	DryRun bool
+	// KeepLFS keeps the LFS manifest and LFS files of the repository.
+	KeepLFS bool
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// Run deletes the Git manifest of the remote repository, its config and
+// layers, and its referrers, writing the deleted content.
+func (action *Delete) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	// deleting does not trust the content, no need to verify it
+	remote, cleanup, err := action.remote(ctx, action.Address, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	deletions, err := remote.Delete(ctx, model.DeleteOptions{DryRun: action.DryRun, KeepLFS: action.KeepLFS})
+	if err != nil {
+		return fmt.Errorf("deleting remote repository: %w", err)
+	}
+
+	if output == OutputJSON {
+		return writeDeletionsJSON(out, deletions)
+	}
+	return writeDeletions(out, deletions, action.DryRun)
+}
+
+// writeDeletions writes a table of deleted content, followed by a summary.
+func writeDeletions(out io.Writer, deletions []model.Deletion, dryRun bool) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "KIND\tDIGEST\tSIZE"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	var size int64
+	for _, d := range deletions {
+		size += d.Size
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%d\n", d.Kind, d.Digest, d.Size); err != nil {
+			return fmt.Errorf("writing %s %s: %w", d.Kind, d.Digest, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	verb := "Deleted"
+	if dryRun {
+		verb = "Would delete"
+	}
+	if _, err := fmt.Fprintf(out, "%s %d manifests and blobs, %d bytes\n", verb, len(deletions), size); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeDeletionsJSON writes deleted content as a JSON DeletionList document.
+func writeDeletionsJSON(out io.Writer, deletions []model.Deletion) error {
+	return writeJSONList(out, "DeletionList", deletions)
+}
@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/apis"
+)
+
+func TestDelete_Run(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	_, err = builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	address := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+	err = (&Mirror{Gnoci: base, Source: srcDir, Address: address}).Run(t.Context(), new(bytes.Buffer))
+	assert.NoError(t, err)
+
+	t.Run("Dry Run", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&Delete{Gnoci: base, Address: address, DryRun: true, Output: OutputJSON}).Run(t.Context(), out)
+		assert.NoError(t, err)
+
+		var deletions OutputList[model.Deletion]
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &deletions))
+		assert.Equal(t, outputMeta("DeletionList"), deletions.OutputMeta)
+		kinds := make([]string, 0, len(deletions.Items))
+		for _, d := range deletions.Items {
+			kinds = append(kinds, d.Kind)
+		}
+		assert.Contains(t, kinds, "manifest")
+		assert.Contains(t, kinds, "config")
+		assert.Contains(t, kinds, "layer")
+
+		// nothing is deleted
+		err = (&List{Gnoci: base, Address: address}).Run(t.Context(), new(bytes.Buffer))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&Delete{Gnoci: base, Address: address}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "KIND")
+		assert.Contains(t, out.String(), "Deleted ")
+
+		err = (&List{Gnoci: base, Address: address}).Run(t.Context(), new(bytes.Buffer))
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+}
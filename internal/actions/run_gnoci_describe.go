@@ -0,0 +1,167 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// Describe represents the gnoci describe action.
+type Describe struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+
+	// Description, if set, updates the repository description.
+	Description string
+	// Website, if set, updates the repository website.
+	Website string
+	// Topics, if set, replace the repository topics.
+	Topics []string
+	// Readme, if set, is the path to a README pushed with the metadata.
+	Readme string
+	// SubmoduleURLs, if set, add mappings of submodule URL prefixes to the
+	// prefixes replacing them. An empty replacement removes the mapping.
+	SubmoduleURLs map[string]string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// MetadataInfo is the JSON output of the gnoci describe action.
+type MetadataInfo struct {
+	OutputMeta
+	oci.Metadata
+
+	// DefaultBranch is the default branch of the repository, if any.
+	DefaultBranch plumbing.ReferenceName `json:"defaultBranch,omitempty"`
+}
+
+// update returns true if the action updates the repository metadata.
+func (action *Describe) update() bool {
+	return action.Description != "" || action.Website != "" || action.Topics != nil || action.Readme != "" ||
+		len(action.SubmoduleURLs) > 0
+}
+
+// Run writes the metadata of the remote repository, updating it first if
+// requested.
+func (action *Describe) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.remote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	manDesc, err := remote.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	meta, err := remote.FetchMetadata(ctx)
+	if err != nil && !errors.Is(err, model.ErrMetadataNotFound) {
+		return fmt.Errorf("fetching repository metadata: %w", err)
+	}
+
+	if action.update() {
+		var readme []byte
+		if action.Readme != "" {
+			readme, err = os.ReadFile(action.Readme)
+			if err != nil {
+				return fmt.Errorf("reading README: %w", err)
+			}
+		}
+		if action.Description != "" {
+			meta.Description = action.Description
+		}
+		if action.Website != "" {
+			meta.Website = action.Website
+		}
+		if action.Topics != nil {
+			meta.Topics = action.Topics
+		}
+		for prefix, base := range action.SubmoduleURLs {
+			if base == "" {
+				delete(meta.SubmoduleURLs, prefix)
+				continue
+			}
+			if meta.SubmoduleURLs == nil {
+				meta.SubmoduleURLs = make(map[string]string)
+			}
+			meta.SubmoduleURLs[prefix] = base
+		}
+
+		if _, err := remote.PushMetadata(ctx, manDesc, meta, readme); err != nil {
+			return fmt.Errorf("pushing repository metadata: %w", err)
+		}
+		meta, err = remote.FetchMetadata(ctx)
+		if err != nil {
+			return fmt.Errorf("fetching updated repository metadata: %w", err)
+		}
+	}
+
+	if output == OutputJSON {
+		return writeMetadataJSON(out, meta, remote.DefaultBranch())
+	}
+	return writeMetadata(out, meta, remote.DefaultBranch())
+}
+
+// writeMetadata writes repository metadata, one field per line.
+func writeMetadata(out io.Writer, meta oci.Metadata, defaultBranch plumbing.ReferenceName) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	fields := []struct {
+		name, value string
+	}{
+		{"Description:", meta.Description},
+		{"Website:", meta.Website},
+		{"Topics:", strings.Join(meta.Topics, ", ")},
+		{"Default Branch:", defaultBranch.String()},
+		{"README:", meta.Readme.String()},
+	}
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", f.name, f.value); err != nil {
+			return fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+	for _, prefix := range slices.Sorted(maps.Keys(meta.SubmoduleURLs)) {
+		if _, err := fmt.Fprintf(tw, "Submodule URL:\t%s -> %s\n", prefix, meta.SubmoduleURLs[prefix]); err != nil {
+			return fmt.Errorf("writing submodule URL %s: %w", prefix, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeMetadataJSON writes repository metadata as a JSON MetadataInfo
+// document.
+func writeMetadataJSON(out io.Writer, meta oci.Metadata, defaultBranch plumbing.ReferenceName) error {
+	return writeJSON(out, MetadataInfo{
+		OutputMeta:    outputMeta("MetadataInfo"),
+		Metadata:      meta,
+		DefaultBranch: defaultBranch,
+	})
+}
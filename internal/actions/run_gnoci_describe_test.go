@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_writeMetadata(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		readme := digest.FromString("# Gnocchi")
+		meta := oci.Metadata{
+			Description: "Pasta-like dumplings",
+			Website:     "https://example.com",
+			Topics:      []string{"food", "italian"},
+			Readme:      readme,
+			SubmoduleURLs: map[string]string{
+				"https://github.com/pasta/": "oci://example.com/pasta/",
+				"git@github.com:sauce/":     "oci://example.com/sauce/",
+			},
+		}
+
+		out := new(bytes.Buffer)
+		err := writeMetadata(out, meta, plumbing.Main)
+		assert.NoError(t, err)
+
+		expected := "Description:    Pasta-like dumplings\n" +
+			"Website:        https://example.com\n" +
+			"Topics:         food, italian\n" +
+			"Default Branch: refs/heads/main\n" +
+			"README:         " + readme.String() + "\n" +
+			"Submodule URL:  git@github.com:sauce/ -> oci://example.com/sauce/\n" +
+			"Submodule URL:  https://github.com/pasta/ -> oci://example.com/pasta/\n"
+		assert.Equal(t, expected, out.String())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeMetadata(out, oci.Metadata{}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "Description:    \nWebsite:        \nTopics:         \nDefault Branch: \nREADME:         \n", out.String())
+	})
+}
+
+func Test_writeMetadataJSON(t *testing.T) {
+	readme := digest.FromString("# Gnocchi")
+	meta := oci.Metadata{
+		Description: "Pasta-like dumplings",
+		Topics:      []string{"food", "italian"},
+		Readme:      readme,
+	}
+
+	out := new(bytes.Buffer)
+	err := writeMetadataJSON(out, meta, plumbing.Main)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"apiVersion": "gnoci.act3-ai.io/output/v1",
+		"kind": "MetadataInfo",
+		"description": "Pasta-like dumplings",
+		"topics": ["food", "italian"],
+		"readme": "`+readme.String()+`",
+		"defaultBranch": "refs/heads/main"
+	}`, out.String())
+}
@@ -0,0 +1,352 @@
+package actions
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/act3-ai/gnoci/internal/cmd"
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// ArchiveFormat is the format of an exported archive.
+type ArchiveFormat string
+
+const (
+	// ArchiveTar exports an uncompressed tar archive.
+	ArchiveTar ArchiveFormat = "tar"
+	// ArchiveZip exports a zip archive.
+	ArchiveZip ArchiveFormat = "zip"
+)
+
+// errUnsupportedArchiveFormat indicates an archive format is not supported.
+var errUnsupportedArchiveFormat = errors.New("unsupported archive format")
+
+// Export represents the gnoci export action.
+type Export struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Revision is the branch, tag, full reference name, or commit hash to export.
+	Revision string
+	// Format of the archive, defaults to tar.
+	Format ArchiveFormat
+	// Prefix is prepended to each path in the archive, e.g. "project/".
+	Prefix string
+}
+
+// Run writes an archive of the tree of a remote revision to out. Only the
+// packfile layers needed to materialize the tree are fetched, into memory.
+func (action *Export) Run(ctx context.Context, out io.Writer) error {
+	format := action.Format
+	if format == "" {
+		format = ArchiveTar
+	}
+	if format != ArchiveTar && format != ArchiveZip {
+		return fmt.Errorf("%w: %q", errUnsupportedArchiveFormat, format)
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	commit, err := fetchRevision(ctx, remote, action.Revision)
+	if err != nil {
+		return err
+	}
+
+	return writeArchive(out, format, action.Prefix, commit)
+}
+
+// fetchRevision fetches the commit of a remote revision, see
+// [resolveRevision], and its tree into an in-memory repository.
+func fetchRevision(ctx context.Context, remote model.ReadOnlyModeler, rev string) (*object.Commit, error) {
+	ref, err := resolveRevision(ctx, remote, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gogit.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("initializing in-memory repository: %w", err)
+	}
+	local := git.NewRepository(r)
+
+	reqs := []gittypes.FetchRequest{{Cmd: gittypes.Fetch, Ref: ref}}
+	if err := cmd.Fetch(ctx, local, remote, reqs, &cmd.Options{Depth: 1}); err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", rev, err)
+	}
+
+	return peelCommit(local.Storer(), ref.Hash())
+}
+
+// resolveRevision resolves a revision to a remote reference. Short names are
+// resolved to branches, then tags. Revisions that are not references are
+// assumed to be commit hashes, fetched by the commit index of each layer.
+func resolveRevision(ctx context.Context, remote model.ReadOnlyModeler, rev string) (*plumbing.Reference, error) {
+	candidates := []plumbing.ReferenceName{plumbing.ReferenceName(rev)}
+	if !strings.HasPrefix(rev, "refs/") {
+		candidates = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(rev), plumbing.NewTagReferenceName(rev)}
+	}
+	for _, name := range candidates {
+		ref, _, err := remote.ResolveRef(ctx, name)
+		switch {
+		case errors.Is(err, model.ErrReferenceNotFound), errors.Is(err, model.ErrUnsupportedReferenceType):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("resolving reference %s: %w", name, err)
+		}
+		return ref, nil
+	}
+
+	if !plumbing.IsHash(rev) {
+		return nil, fmt.Errorf("%w: %s", model.ErrReferenceNotFound, rev)
+	}
+	hash := plumbing.NewHash(rev)
+	if _, err := remote.ResolveCommit(ctx, hash); err != nil {
+		return nil, fmt.Errorf("resolving commit %s: %w", rev, err)
+	}
+	return plumbing.NewHashReference(plumbing.ReferenceName(rev), hash), nil
+}
+
+// peelCommit resolves the commit an object refers to, peeling annotated tags.
+func peelCommit(st storer.EncodedObjectStorer, hash plumbing.Hash) (*object.Commit, error) {
+	for {
+		obj, err := object.GetObject(st, hash)
+		if err != nil {
+			return nil, fmt.Errorf("resolving object %s: %w", hash, err)
+		}
+
+		switch o := obj.(type) {
+		case *object.Commit:
+			return o, nil
+		case *object.Tag:
+			hash = o.Target
+		default:
+			return nil, fmt.Errorf("%w: %s is a %s, not a commit", plumbing.ErrInvalidType, hash, obj.Type())
+		}
+	}
+}
+
+// archiver writes the entries of an archive.
+type archiver interface {
+	// writeDir writes a directory entry.
+	writeDir(name string) error
+	// writeFile writes a regular, executable, or symlink entry, whose content
+	// is the symlink target.
+	writeFile(name string, mode filemode.FileMode, size int64, r io.Reader) error
+	// Close finishes the archive.
+	Close() error
+}
+
+// writeArchive writes an archive of the tree of commit to out. Entries are
+// timestamped with the commit time, as with git archive. Submodules are
+// written as empty directories.
+func writeArchive(out io.Writer, format ArchiveFormat, prefix string, commit *object.Commit) error {
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("resolving tree of commit %s: %w", commit.Hash, err)
+	}
+
+	modTime := commit.Committer.When
+	var a archiver
+	switch format {
+	case ArchiveTar:
+		a = &tarArchiver{tw: tar.NewWriter(out), modTime: modTime}
+	case ArchiveZip:
+		a = &zipArchiver{zw: zip.NewWriter(out), modTime: modTime}
+	default:
+		return fmt.Errorf("%w: %q", errUnsupportedArchiveFormat, format)
+	}
+
+	if err := writeTree(a, prefix, tree); err != nil {
+		return errors.Join(err, a.Close())
+	}
+	if err := a.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+
+	return nil
+}
+
+// writeTree writes the entries of tree to a, in tree order.
+func writeTree(a archiver, prefix string, tree *object.Tree) error {
+	if strings.HasSuffix(prefix, "/") {
+		if err := a.writeDir(prefix); err != nil {
+			return err
+		}
+	}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return fmt.Errorf("walking tree %s: %w", tree.Hash, err)
+		}
+		name = prefix + name
+
+		switch entry.Mode {
+		case filemode.Dir, filemode.Submodule:
+			if err := a.writeDir(name + "/"); err != nil {
+				return err
+			}
+		case filemode.Regular, filemode.Deprecated, filemode.Executable, filemode.Symlink:
+			if err := writeBlob(a, tree, name, entry); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported file mode %s of %s", entry.Mode, name)
+		}
+	}
+}
+
+// writeBlob writes the file of a tree entry to a.
+func writeBlob(a archiver, tree *object.Tree, name string, entry object.TreeEntry) error {
+	file, err := tree.TreeEntryFile(&entry)
+	if err != nil {
+		return fmt.Errorf("resolving blob %s of %s: %w", entry.Hash, name, err)
+	}
+	rc, err := file.Reader()
+	if err != nil {
+		return fmt.Errorf("reading blob %s of %s: %w", entry.Hash, name, err)
+	}
+	defer rc.Close()
+
+	return a.writeFile(name, entry.Mode, file.Size, rc)
+}
+
+// tarArchiver writes a tar archive.
+type tarArchiver struct {
+	tw      *tar.Writer
+	modTime time.Time
+}
+
+func (t *tarArchiver) writeDir(name string) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name,
+		Mode:     0o755,
+		ModTime:  t.modTime,
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing directory %s: %w", name, err)
+	}
+	return nil
+}
+
+func (t *tarArchiver) writeFile(name string, mode filemode.FileMode, size int64, r io.Reader) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     size,
+		ModTime:  t.modTime,
+	}
+	switch mode {
+	case filemode.Executable:
+		hdr.Mode = 0o755
+	case filemode.Symlink:
+		target, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("reading symlink %s: %w", name, err)
+		}
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Mode = 0o777
+		hdr.Size = 0
+		hdr.Linkname = string(target)
+	}
+
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing header of %s: %w", name, err)
+	}
+	if hdr.Typeflag == tar.TypeReg {
+		if _, err := io.Copy(t.tw, r); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (t *tarArchiver) Close() error {
+	return t.tw.Close() //nolint:wrapcheck
+}
+
+// zipArchiver writes a zip archive.
+type zipArchiver struct {
+	zw      *zip.Writer
+	modTime time.Time
+}
+
+func (z *zipArchiver) writeDir(name string) error {
+	hdr := &zip.FileHeader{
+		Name:     name,
+		Modified: z.modTime,
+	}
+	hdr.SetMode(os.ModeDir | 0o755)
+	if _, err := z.zw.CreateHeader(hdr); err != nil {
+		return fmt.Errorf("writing directory %s: %w", name, err)
+	}
+	return nil
+}
+
+func (z *zipArchiver) writeFile(name string, mode filemode.FileMode, _ int64, r io.Reader) error {
+	hdr := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: z.modTime,
+	}
+	switch mode {
+	case filemode.Executable:
+		hdr.SetMode(0o755)
+	case filemode.Symlink:
+		// the target is stored as the file content
+		hdr.Method = zip.Store
+		hdr.SetMode(os.ModeSymlink | 0o777)
+	default:
+		hdr.SetMode(0o644)
+	}
+
+	w, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return fmt.Errorf("writing header of %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+func (z *zipArchiver) Close() error {
+	return z.zw.Close() //nolint:wrapcheck
+}
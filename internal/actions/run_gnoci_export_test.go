@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// storeObject encodes and stores obj, returning its hash.
+func storeObject(t *testing.T, st storer.EncodedObjectStorer, obj interface {
+	Encode(plumbing.EncodedObject) error
+},
+) plumbing.Hash {
+	t.Helper()
+	enc := st.NewEncodedObject()
+	assert.NoError(t, obj.Encode(enc))
+	hash, err := st.SetEncodedObject(enc)
+	assert.NoError(t, err)
+	return hash
+}
+
+// storeBlob stores a blob with content, returning its hash.
+func storeBlob(t *testing.T, st storer.EncodedObjectStorer, content string) plumbing.Hash {
+	t.Helper()
+	enc := st.NewEncodedObject()
+	enc.SetType(plumbing.BlobObject)
+	w, err := enc.Writer()
+	assert.NoError(t, err)
+	_, err = io.WriteString(w, content)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	hash, err := st.SetEncodedObject(enc)
+	assert.NoError(t, err)
+	return hash
+}
+
+// archiveCommit creates a commit with a regular file, an executable, a symlink,
+// and a nested directory.
+func archiveCommit(t *testing.T, when time.Time) *object.Commit {
+	t.Helper()
+	st := memory.NewStorage()
+
+	subtree := storeObject(t, st, &object.Tree{Entries: []object.TreeEntry{
+		{Name: "nested.txt", Mode: filemode.Regular, Hash: storeBlob(t, st, "nested")},
+	}})
+	tree := storeObject(t, st, &object.Tree{Entries: []object.TreeEntry{
+		{Name: "README.md", Mode: filemode.Regular, Hash: storeBlob(t, st, "readme")},
+		{Name: "dir", Mode: filemode.Dir, Hash: subtree},
+		{Name: "link", Mode: filemode.Symlink, Hash: storeBlob(t, st, "README.md")},
+		{Name: "run.sh", Mode: filemode.Executable, Hash: storeBlob(t, st, "#!/bin/sh")},
+	}})
+	sig := object.Signature{Name: "test", Email: "test@example.com", When: when}
+	hash := storeObject(t, st, &object.Commit{Author: sig, Committer: sig, Message: "test", TreeHash: tree})
+
+	commit, err := object.GetCommit(st, hash)
+	assert.NoError(t, err)
+	return commit
+}
+
+func Test_writeArchive(t *testing.T) {
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	commit := archiveCommit(t, when)
+
+	t.Run("Tar", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := writeArchive(out, ArchiveTar, "project/", commit)
+		assert.NoError(t, err)
+
+		type entry struct {
+			typeflag byte
+			mode     int64
+			content  string
+			linkname string
+		}
+		got := map[string]entry{}
+		tr := tar.NewReader(out)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			content, err := io.ReadAll(tr)
+			assert.NoError(t, err)
+			assert.True(t, hdr.ModTime.Equal(when))
+			got[hdr.Name] = entry{typeflag: hdr.Typeflag, mode: hdr.Mode, content: string(content), linkname: hdr.Linkname}
+		}
+
+		assert.Equal(t, map[string]entry{
+			"project/":               {typeflag: tar.TypeDir, mode: 0o755},
+			"project/README.md":      {typeflag: tar.TypeReg, mode: 0o644, content: "readme"},
+			"project/dir/":           {typeflag: tar.TypeDir, mode: 0o755},
+			"project/dir/nested.txt": {typeflag: tar.TypeReg, mode: 0o644, content: "nested"},
+			"project/link":           {typeflag: tar.TypeSymlink, mode: 0o777, linkname: "README.md"},
+			"project/run.sh":         {typeflag: tar.TypeReg, mode: 0o755, content: "#!/bin/sh"},
+		}, got)
+	})
+
+	t.Run("Zip", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := writeArchive(out, ArchiveZip, "", commit)
+		assert.NoError(t, err)
+
+		zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+		assert.NoError(t, err)
+
+		type entry struct {
+			mode    os.FileMode
+			content string
+		}
+		got := map[string]entry{}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			assert.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			assert.NoError(t, err)
+			assert.NoError(t, rc.Close())
+			got[f.Name] = entry{mode: f.Mode(), content: string(content)}
+		}
+
+		assert.Equal(t, map[string]entry{
+			"README.md":      {mode: 0o644, content: "readme"},
+			"dir/":           {mode: os.ModeDir | 0o755},
+			"dir/nested.txt": {mode: 0o644, content: "nested"},
+			"link":           {mode: os.ModeSymlink | 0o777, content: "README.md"},
+			"run.sh":         {mode: 0o755, content: "#!/bin/sh"},
+		}, got)
+	})
+
+	t.Run("Unsupported Format", func(t *testing.T) {
+		err := writeArchive(io.Discard, "rar", "", commit)
+		assert.ErrorIs(t, err, errUnsupportedArchiveFormat)
+	})
+}
+
+func Test_resolveRevision(t *testing.T) {
+	commit := plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74").String()
+
+	t.Run("Short Branch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		remote := modelmock.NewMockReadOnlyModeler(ctrl)
+		want := plumbing.NewHashReference(plumbing.Main, plumbing.NewHash(commit))
+		remote.EXPECT().ResolveRef(gomock.Any(), plumbing.Main).Return(want, "", nil)
+
+		got, err := resolveRevision(t.Context(), remote, "main")
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Short Tag", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		remote := modelmock.NewMockReadOnlyModeler(ctrl)
+		want := plumbing.NewHashReference("refs/tags/v1", plumbing.NewHash(commit))
+		remote.EXPECT().ResolveRef(gomock.Any(), plumbing.NewBranchReferenceName("v1")).Return(nil, "", model.ErrReferenceNotFound)
+		remote.EXPECT().ResolveRef(gomock.Any(), plumbing.NewTagReferenceName("v1")).Return(want, "", nil)
+
+		got, err := resolveRevision(t.Context(), remote, "v1")
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		remote := modelmock.NewMockReadOnlyModeler(ctrl)
+		remote.EXPECT().ResolveRef(gomock.Any(), gomock.Any()).Return(nil, "", model.ErrReferenceNotFound).Times(2)
+		remote.EXPECT().ResolveCommit(gomock.Any(), plumbing.NewHash(commit)).Return("", nil)
+
+		got, err := resolveRevision(t.Context(), remote, commit)
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.NewHash(commit), got.Hash())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		remote := modelmock.NewMockReadOnlyModeler(ctrl)
+		remote.EXPECT().ResolveRef(gomock.Any(), gomock.Any()).Return(nil, "", model.ErrReferenceNotFound).Times(2)
+
+		_, err := resolveRevision(t.Context(), remote, "feature")
+		assert.ErrorIs(t, err, model.ErrReferenceNotFound)
+	})
+}
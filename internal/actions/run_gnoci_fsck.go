@@ -0,0 +1,125 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// ErrIntegrity indicates the Git OCI data model of a remote repository is
+// corrupt.
+var ErrIntegrity = errors.New("integrity check failed")
+
+// Fsck represents the gnoci fsck action.
+type Fsck struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// FsckResult is the JSON output of the gnoci fsck action.
+type FsckResult struct {
+	OutputMeta
+
+	// Manifest is the digest of the checked Git manifest.
+	Manifest digest.Digest `json:"manifest"`
+	// Layers is the number of packfile layers checked.
+	Layers int `json:"layers"`
+	// Commits is the number of commits found within the packfile layers.
+	Commits int `json:"commits"`
+	// Refs is the number of references checked.
+	Refs int `json:"refs"`
+	// Problems describes each integrity violation found, empty if none.
+	Problems []string `json:"problems"`
+	// OK is true if no problems were found.
+	OK bool `json:"ok"`
+}
+
+// Run checks the integrity of the remote repository, failing with
+// [ErrIntegrity] if any problems are found.
+func (action *Fsck) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	manDesc, err := remote.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	report, err := remote.Fsck(ctx)
+	if err != nil {
+		return fmt.Errorf("checking integrity: %w", err)
+	}
+
+	if output == OutputJSON {
+		err = writeFsckReportJSON(out, manDesc.Digest, report)
+	} else {
+		err = writeFsckReport(out, manDesc.Digest.String(), report)
+	}
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return fmt.Errorf("%w: %d problems found", ErrIntegrity, len(report.Problems))
+	}
+
+	return nil
+}
+
+// writeFsckReport writes each problem of report, followed by a summary.
+func writeFsckReport(out io.Writer, manifest string, report *model.FsckReport) error {
+	for _, problem := range report.Problems {
+		if _, err := fmt.Fprintf(out, "error: %s\n", problem); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+
+	status := "ok"
+	if !report.OK() {
+		status = fmt.Sprintf("%d problems", len(report.Problems))
+	}
+	if _, err := fmt.Fprintf(out, "Checked %s: %d packfile layers, %d commits, %d references, %s\n",
+		manifest, report.Layers, report.Commits, report.Refs, status); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeFsckReportJSON writes report as a JSON FsckResult document.
+func writeFsckReportJSON(out io.Writer, manifest digest.Digest, report *model.FsckReport) error {
+	problems := report.Problems
+	if problems == nil {
+		problems = []string{}
+	}
+	return writeJSON(out, FsckResult{
+		OutputMeta: outputMeta("FsckResult"),
+		Manifest:   manifest,
+		Layers:     report.Layers,
+		Commits:    report.Commits,
+		Refs:       report.Refs,
+		Problems:   problems,
+		OK:         report.OK(),
+	})
+}
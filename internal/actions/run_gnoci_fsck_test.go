@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+func Test_writeFsckReport(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeFsckReport(out, "sha256:aaaa", &model.FsckReport{Layers: 2, Commits: 10, Refs: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, "Checked sha256:aaaa: 2 packfile layers, 10 commits, 3 references, ok\n", out.String())
+	})
+
+	t.Run("Problems", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeFsckReport(out, "sha256:aaaa", &model.FsckReport{
+			Layers:   1,
+			Refs:     1,
+			Problems: []string{"packfile layer sha256:bbbb: verifying digest: mismatched digest", "default branch refs/heads/dne does not exist"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "error: packfile layer sha256:bbbb: verifying digest: mismatched digest\n"+
+			"error: default branch refs/heads/dne does not exist\n"+
+			"Checked sha256:aaaa: 1 packfile layers, 0 commits, 1 references, 2 problems\n", out.String())
+	})
+}
+
+func Test_writeFsckReportJSON(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeFsckReportJSON(out, "sha256:aaaa", &model.FsckReport{Layers: 2, Commits: 10, Refs: 3})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"apiVersion": "gnoci.act3-ai.io/output/v1",
+			"kind": "FsckResult",
+			"manifest": "sha256:aaaa",
+			"layers": 2,
+			"commits": 10,
+			"refs": 3,
+			"problems": [],
+			"ok": true
+		}`, out.String())
+	})
+
+	t.Run("Problems", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeFsckReportJSON(out, "sha256:aaaa", &model.FsckReport{
+			Layers:   1,
+			Refs:     1,
+			Problems: []string{"default branch refs/heads/dne does not exist"},
+		})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"apiVersion": "gnoci.act3-ai.io/output/v1",
+			"kind": "FsckResult",
+			"manifest": "sha256:aaaa",
+			"layers": 1,
+			"commits": 0,
+			"refs": 1,
+			"problems": ["default branch refs/heads/dne does not exist"],
+			"ok": false
+		}`, out.String())
+	})
+}
@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// GC represents the gnoci gc action.
+type GC struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+}
+
+// Run consolidates the packfile layers of the remote repository into a single
+// layer.
+func (action *GC) Run(ctx context.Context, out io.Writer) error {
+	remote, cleanup, err := action.remote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+	before := len(remote.Layers())
+
+	desc, err := remote.Consolidate(ctx, model.UpdateLFSReferrer(remote), model.UpdateMetadataReferrer(remote))
+	if err != nil {
+		return fmt.Errorf("consolidating packfile layers: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(out, "Consolidated %d packfile layers into %d, %s\n", before, len(remote.Layers()), desc.Digest); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
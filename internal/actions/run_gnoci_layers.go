@@ -0,0 +1,156 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// Layers represents the gnoci layers action.
+type Layers struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// LayerInfo describes a packfile layer and the provenance recorded with it.
+type LayerInfo struct {
+	// Digest is the digest of the layer.
+	Digest digest.Digest `json:"digest"`
+	// Size is the size of the layer in bytes.
+	Size int64 `json:"size"`
+	// Base is the digest of the layer a thin packfile depends on, if any.
+	Base digest.Digest `json:"base,omitempty"`
+	// Creator is the user agent and version of the client that pushed the
+	// layer, if recorded.
+	Creator string `json:"creator,omitempty"`
+	// Refs map the references pushed with the layer to their tip commits.
+	Refs map[plumbing.ReferenceName]string `json:"refs,omitempty"`
+	// Commits is the number of commits within the layer, -1 if not recorded.
+	Commits int `json:"commits"`
+	// Created is the time the layer was pushed, RFC 3339 formatted, if
+	// recorded.
+	Created string `json:"created,omitempty"`
+}
+
+// Run lists the packfile layers of the remote repository, oldest first, with
+// the provenance recorded in their annotations.
+func (action *Layers) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	layers := layerInfos(remote.Layers())
+	if output == OutputJSON {
+		return writeLayersJSON(out, layers)
+	}
+	return writeLayers(out, layers)
+}
+
+// layerInfos describes each packfile layer descriptor.
+func layerInfos(descs []ocispec.Descriptor) []LayerInfo {
+	layers := make([]LayerInfo, 0, len(descs))
+	for _, desc := range descs {
+		prov := model.LayerProvenance(desc)
+		info := LayerInfo{
+			Digest:  desc.Digest,
+			Size:    desc.Size,
+			Base:    model.PackBase(desc),
+			Creator: prov.Creator,
+			Commits: prov.Commits,
+			Created: prov.Created,
+		}
+		if len(prov.Refs) > 0 {
+			info.Refs = make(map[plumbing.ReferenceName]string, len(prov.Refs))
+			for name, tip := range prov.Refs {
+				info.Refs[name] = tip.String()
+			}
+		}
+		layers = append(layers, info)
+	}
+	return layers
+}
+
+// writeLayers writes a table of packfile layers. Unrecorded provenance is
+// written as "-".
+func writeLayers(out io.Writer, layers []LayerInfo) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "DIGEST\tSIZE\tCOMMITS\tCREATOR\tBASE\tREFS"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, l := range layers {
+		commits := "-"
+		if l.Commits >= 0 {
+			commits = strconv.Itoa(l.Commits)
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			l.Digest, l.Size, commits, orDash(l.Creator), orDash(l.Base.String()), formatRefTips(l.Refs)); err != nil {
+			return fmt.Errorf("writing layer %s: %w", l.Digest, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeLayersJSON writes packfile layers as a JSON LayerList document.
+func writeLayersJSON(out io.Writer, layers []LayerInfo) error {
+	return writeJSONList(out, "LayerList", layers)
+}
+
+// formatRefTips formats references and their tip commits as a sorted, comma
+// separated list of "name=commit" pairs.
+func formatRefTips(refs map[plumbing.ReferenceName]string) string {
+	if len(refs) == 0 {
+		return "-"
+	}
+
+	pairs := make([]string, 0, len(refs))
+	for name, tip := range refs {
+		pairs = append(pairs, name.String()+"="+tip)
+	}
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// orDash returns s, or "-" if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
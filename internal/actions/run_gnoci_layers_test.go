@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_writeLayers(t *testing.T) {
+	tip := plumbing.NewHash("1111111111111111111111111111111111111111")
+	base := digest.FromString("base")
+	descs := []ocispec.Descriptor{
+		{
+			MediaType: oci.MediaTypePackLayer,
+			Digest:    base,
+			Size:      4,
+		},
+		{
+			MediaType: oci.MediaTypePackLayer,
+			Digest:    digest.FromString("thin"),
+			Size:      4,
+			Annotations: map[string]string{
+				oci.AnnotationPackBase:        base.String(),
+				oci.AnnotationPackCreator:     "git-remote-oci/v0.1.0",
+				oci.AnnotationPackCommitCount: "3",
+				ocispec.AnnotationCreated:     "2025-01-02T03:04:05Z",
+				oci.AnnotationPackRefs:        `{"refs/tags/v1":"` + tip.String() + `","refs/heads/main":"` + tip.String() + `"}`,
+			},
+		},
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeLayers(out, layerInfos(descs))
+		assert.NoError(t, err)
+		assert.Equal(t, "DIGEST                                                                    SIZE   COMMITS   CREATOR                 BASE                                                                      REFS\n"+
+			base.String()+"   4      -         -                       -                                                                         -\n"+
+			digest.FromString("thin").String()+"   4      3         git-remote-oci/v0.1.0   "+base.String()+"   refs/heads/main="+tip.String()+",refs/tags/v1="+tip.String()+"\n", out.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeLayersJSON(out, layerInfos(descs[1:]))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"apiVersion": "gnoci.act3-ai.io/output/v1",
+			"kind": "LayerList",
+			"items": [{
+				"digest": "`+digest.FromString("thin").String()+`",
+				"size": 4,
+				"base": "`+base.String()+`",
+				"creator": "git-remote-oci/v0.1.0",
+				"refs": {"refs/heads/main": "`+tip.String()+`", "refs/tags/v1": "`+tip.String()+`"},
+				"commits": 3,
+				"created": "2025-01-02T03:04:05Z"
+			}]
+		}`, out.String())
+	})
+}
@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// LFSList represents the gnoci lfs ls action.
+type LFSList struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// Run lists the LFS files stored with the remote repository, in layer order.
+// Repositories without an LFS manifest have no LFS files.
+func (action *LFSList) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+	if _, err := remote.FetchLFS(ctx); err != nil && !errors.Is(err, model.ErrLFSManifestNotFound) {
+		return fmt.Errorf("fetching LFS metadata: %w", err)
+	}
+
+	objs := remote.ListLFSObjects()
+	if output == OutputJSON {
+		return writeLFSObjectsJSON(out, objs)
+	}
+	return writeLFSObjects(out, objs)
+}
+
+// writeLFSObjects writes a table of LFS files. Missing titles are written as
+// "-".
+func writeLFSObjects(out io.Writer, objs []model.LFSObject) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "OID\tSIZE\tTITLE\tENCRYPTED"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, obj := range objs {
+		if _, err := fmt.Fprintf(tw, "%s\t%d\t%s\t%t\n", obj.Oid, obj.Size, orDash(obj.Title), obj.Encrypted); err != nil {
+			return fmt.Errorf("writing LFS file %s: %w", obj.Oid, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeLFSObjectsJSON writes LFS files as a JSON LFSObjectList document.
+func writeLFSObjectsJSON(out io.Writer, objs []model.LFSObject) error {
+	return writeJSONList(out, "LFSObjectList", objs)
+}
+
+// LFSPrune represents the gnoci lfs prune action.
+type LFSPrune struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// DeleteLayers deletes the layers of pruned LFS files from the registry.
+	DeleteLayers bool
+}
+
+// Run removes the LFS files of the remote repository no longer referenced by a
+// pointer file reachable from its references.
+func (action *LFSPrune) Run(ctx context.Context, out io.Writer) error {
+	remote, cleanup, err := action.remote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+	_, err = remote.FetchLFS(ctx)
+	switch {
+	case errors.Is(err, model.ErrLFSManifestNotFound):
+		if _, err := fmt.Fprintln(out, "No LFS files to prune"); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("fetching LFS metadata: %w", err)
+	}
+
+	pruned, err := remote.PruneLFS(ctx, model.PruneLFSOptions{DeleteLayers: action.DeleteLayers})
+	if err != nil {
+		return fmt.Errorf("pruning LFS files: %w", err)
+	}
+
+	var size int64
+	for _, obj := range pruned {
+		size += obj.Size
+		if _, err := fmt.Fprintf(out, "Pruned %s\n", obj.Oid); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintf(out, "Pruned %d unreferenced LFS files, %d bytes\n", len(pruned), size); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+func Test_writeLFSObjects(t *testing.T) {
+	plain := digest.FromString("plain")
+	encrypted := digest.FromString("encrypted")
+	objs := []model.LFSObject{
+		{Oid: plain.Encoded(), Size: 5, Title: plain.Encoded(), Digest: plain},
+		{Oid: encrypted.Encoded(), Size: 9, Digest: digest.FromString("ciphertext"), Encrypted: true},
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeLFSObjects(out, objs)
+		assert.NoError(t, err)
+		assert.Equal(t, "OID                                                                SIZE   TITLE                                                              ENCRYPTED\n"+
+			plain.Encoded()+"   5      "+plain.Encoded()+"   false\n"+
+			encrypted.Encoded()+"   9      -                                                                  true\n", out.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeLFSObjectsJSON(out, objs[1:])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"apiVersion": "gnoci.act3-ai.io/output/v1",
+			"kind": "LFSObjectList",
+			"items": [{
+				"oid": "`+encrypted.Encoded()+`",
+				"size": 9,
+				"digest": "`+digest.FromString("ciphertext").String()+`",
+				"encrypted": true
+			}]
+		}`, out.String())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeLFSObjects(out, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "OID   SIZE   TITLE   ENCRYPTED\n", out.String())
+	})
+}
@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// List represents the gnoci ls action.
+type List struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Patterns, if any, limit the listed references to those matching at
+	// least one pattern, see [matchRefPatterns].
+	Patterns []string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// RefInfo describes a reference of the remote repository.
+type RefInfo struct {
+	// Name is the full name of the reference.
+	Name plumbing.ReferenceName `json:"name"`
+	// Commit is the commit the reference points to.
+	Commit string `json:"commit"`
+	// Layer is the digest of the packfile layer containing the commit.
+	Layer digest.Digest `json:"layer"`
+}
+
+// Run lists the heads, tags, and notes of the remote repository, with their commits
+// and the packfile layers containing them. Only the Git config of the remote is
+// fetched, as with listing references for Git.
+func (action *List) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.FetchConfigOnly(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	heads := filterRefs(remote.HeadRefs(), action.Patterns)
+	tags := filterRefs(remote.TagRefs(), action.Patterns)
+	notes := filterRefs(remote.NoteRefs(), action.Patterns)
+	if output == OutputJSON {
+		return writeRefsJSON(out, refInfos(heads, tags, notes))
+	}
+	return writeRefs(out, heads, tags, notes)
+}
+
+// filterRefs returns the references matching patterns, or all references if
+// there are no patterns.
+func filterRefs(refs map[plumbing.ReferenceName]oci.ReferenceInfo, patterns []string) map[plumbing.ReferenceName]oci.ReferenceInfo {
+	if len(patterns) == 0 {
+		return refs
+	}
+	filtered := make(map[plumbing.ReferenceName]oci.ReferenceInfo)
+	for name, info := range refs {
+		if matchRefPatterns(name, patterns) {
+			filtered[name] = info
+		}
+	}
+	return filtered
+}
+
+// matchRefPatterns reports whether name matches any of patterns, as matched by
+// git ls-remote. Each pattern is a glob matched against the tail of the
+// reference name, starting either at the beginning of the name or after a
+// slash, such that "main" matches "refs/heads/main" but not
+// "refs/heads/domain". Malformed patterns match nothing.
+func matchRefPatterns(name plumbing.ReferenceName, patterns []string) bool {
+	for _, pattern := range patterns {
+		for tail := name.String(); ; {
+			if ok, _ := path.Match(pattern, tail); ok {
+				return true
+			}
+			_, rest, found := strings.Cut(tail, "/")
+			if !found {
+				break
+			}
+			tail = rest
+		}
+	}
+	return false
+}
+
+// refInfos describes heads, tags, and notes, in that order, each sorted by
+// name.
+func refInfos(heads, tags, notes map[plumbing.ReferenceName]oci.ReferenceInfo) []RefInfo {
+	infos := make([]RefInfo, 0, len(heads)+len(tags)+len(notes))
+	for _, refs := range []map[plumbing.ReferenceName]oci.ReferenceInfo{heads, tags, notes} {
+		for _, name := range slices.Sorted(maps.Keys(refs)) {
+			infos = append(infos, RefInfo{Name: name, Commit: refs[name].Commit, Layer: refs[name].Layer})
+		}
+	}
+	return infos
+}
+
+// writeRefs writes a table of heads, tags, and notes, each sorted by name.
+func writeRefs(out io.Writer, heads, tags, notes map[plumbing.ReferenceName]oci.ReferenceInfo) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "REFERENCE\tCOMMIT\tLAYER"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, ref := range refInfos(heads, tags, notes) {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", ref.Name, ref.Commit, ref.Layer); err != nil {
+			return fmt.Errorf("writing reference %s: %w", ref.Name, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeRefsJSON writes references as a JSON RefList document.
+func writeRefsJSON(out io.Writer, refs []RefInfo) error {
+	return writeJSONList(out, "RefList", refs)
+}
@@ -0,0 +1,119 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_writeRefs(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		layer := digest.FromString("foo")
+		heads := map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.NewBranchReferenceName("main"):    {Commit: "aaaa", Layer: layer},
+			plumbing.NewBranchReferenceName("feature"): {Commit: "bbbb", Layer: layer},
+		}
+		tags := map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.NewTagReferenceName("v1.0.0"): {Commit: "cccc", Layer: layer},
+		}
+
+		notes := map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.NewNoteReferenceName("commits"): {Commit: "dddd", Layer: layer},
+		}
+
+		out := new(bytes.Buffer)
+		err := writeRefs(out, heads, tags, notes)
+		assert.NoError(t, err)
+
+		expected := "REFERENCE            COMMIT   LAYER\n" +
+			"refs/heads/feature   bbbb     " + layer.String() + "\n" +
+			"refs/heads/main      aaaa     " + layer.String() + "\n" +
+			"refs/tags/v1.0.0     cccc     " + layer.String() + "\n" +
+			"refs/notes/commits   dddd     " + layer.String() + "\n"
+		assert.Equal(t, expected, out.String())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeRefs(out, nil, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "REFERENCE   COMMIT   LAYER\n", out.String())
+	})
+}
+
+func Test_writeRefsJSON(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		layer := digest.FromString("foo")
+		heads := map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.NewBranchReferenceName("main"): {Commit: "aaaa", Layer: layer},
+		}
+		tags := map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.NewTagReferenceName("v1.0.0"): {Commit: "cccc", Layer: layer},
+		}
+
+		out := new(bytes.Buffer)
+		err := writeRefsJSON(out, refInfos(heads, tags, nil))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"apiVersion": "gnoci.act3-ai.io/output/v1",
+			"kind": "RefList",
+			"items": [
+				{"name": "refs/heads/main", "commit": "aaaa", "layer": "`+layer.String()+`"},
+				{"name": "refs/tags/v1.0.0", "commit": "cccc", "layer": "`+layer.String()+`"}
+			]
+		}`, out.String())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := writeRefsJSON(out, refInfos(nil, nil, nil))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"apiVersion": "gnoci.act3-ai.io/output/v1", "kind": "RefList", "items": []}`, out.String())
+	})
+}
+
+func Test_matchRefPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      plumbing.ReferenceName
+		patterns []string
+		want     bool
+	}{
+		{name: "Full Name", ref: "refs/heads/main", patterns: []string{"refs/heads/main"}, want: true},
+		{name: "Tail", ref: "refs/heads/main", patterns: []string{"main"}, want: true},
+		{name: "Partial Component", ref: "refs/heads/domain", patterns: []string{"main"}, want: false},
+		{name: "Glob", ref: "refs/tags/v1.2.0", patterns: []string{"refs/tags/v1.*"}, want: true},
+		{name: "Glob Mismatch", ref: "refs/tags/v2.0.0", patterns: []string{"refs/tags/v1.*"}, want: false},
+		{name: "Any Pattern", ref: "refs/tags/v2.0.0", patterns: []string{"v1.*", "v2.*"}, want: true},
+		{name: "Malformed Pattern", ref: "refs/tags/v1", patterns: []string{"v1["}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchRefPatterns(tt.ref, tt.patterns))
+		})
+	}
+}
+
+func Test_filterRefs(t *testing.T) {
+	tags := map[plumbing.ReferenceName]oci.ReferenceInfo{
+		plumbing.NewTagReferenceName("v1.0.0"): {Commit: "aaaa"},
+		plumbing.NewTagReferenceName("v1.1.0"): {Commit: "bbbb"},
+		plumbing.NewTagReferenceName("v2.0.0"): {Commit: "cccc"},
+	}
+
+	t.Run("No Patterns", func(t *testing.T) {
+		assert.Equal(t, tags, filterRefs(tags, nil))
+	})
+
+	t.Run("Patterns", func(t *testing.T) {
+		assert.Equal(t, map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.NewTagReferenceName("v1.0.0"): {Commit: "aaaa"},
+			plumbing.NewTagReferenceName("v1.1.0"): {Commit: "bbbb"},
+		}, filterRefs(tags, []string{"refs/tags/v1.*"}))
+	})
+}
@@ -0,0 +1,251 @@
+package actions
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/act3-ai/gnoci/internal/cmd"
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// ErrMirror indicates some references failed to mirror.
+var ErrMirror = errors.New("failed to mirror references")
+
+// mirrorSource is the name of the source remote in the temporary repository.
+const mirrorSource = "source"
+
+// Mirror represents the gnoci mirror action.
+type Mirror struct {
+	*Gnoci
+
+	// Source is the URL of the Git repository to mirror, any URL supported
+	// by go-git, e.g. https://, ssh://, or a local path.
+	Source string
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Prune deletes branches and tags of the remote repository which no
+	// longer exist in the source.
+	Prune bool
+	// Annotations are added to the pushed Git manifest, in addition to
+	// those of the configuration.
+	Annotations map[string]string
+}
+
+// Run fetches the branches and tags of the source repository into a
+// temporary repository, and pushes them to the remote repository, failing
+// with [ErrMirror] if any are rejected.
+func (action *Mirror) Run(ctx context.Context, out io.Writer) error {
+	cfg, err := action.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("getting configuration: %w", err)
+	}
+
+	annotations, err := annotationsOpt(action.Annotations)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gnoci-mirror-*")
+	if err != nil {
+		return fmt.Errorf("initializing temp directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.ErrorContext(ctx, "removing temporary git repository", slog.String("error", err.Error()))
+		}
+	}()
+
+	local, err := fetchSource(ctx, tmpDir, action.Source)
+	if err != nil {
+		return err
+	}
+
+	modelOpts := []model.Option{annotations}
+	if cfg.Push.SourceURL == "" {
+		modelOpts = append(modelOpts, model.WithSourceURL(redactUserinfo(action.Source)))
+	}
+	remote, cleanup, err := action.remote(ctx, action.Address, true, modelOpts...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	unlock, err := lockRemote(ctx, remote, lockOptsFromConfig(cfg.Push.Lock))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := remote.FetchOrEmpty(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	reqs, err := mirrorRequests(local, remote, action.Prune)
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		if _, err := fmt.Fprintln(out, "Everything up-to-date"); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		return nil
+	}
+
+	opts := cmd.Options{}
+	if err := applyPushConfig(&opts, cfg.Push); err != nil {
+		return err
+	}
+	results, err := cmd.Push(ctx, local, remote, reqs, &opts)
+	if err != nil {
+		return fmt.Errorf("pushing to remote: %w", err)
+	}
+
+	return writePushResults(out, reqs, results, ErrMirror)
+}
+
+// fetchSource fetches the branches and tags of the Git repository at source into
+// a new bare repository in dir.
+func fetchSource(ctx context.Context, dir, source string) (git.Repository, error) {
+	repo, err := git.PlainInit(dir, true)
+	if err != nil {
+		return nil, fmt.Errorf("initializing temporary repository: %w", err)
+	}
+
+	src, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: mirrorSource,
+		URLs: []string{source},
+		Fetch: []config.RefSpec{
+			"+refs/heads/*:refs/heads/*",
+			"+refs/tags/*:refs/tags/*",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adding source remote: %w", err)
+	}
+
+	slog.InfoContext(ctx, "fetching source repository", slog.String("url", redactUserinfo(source)))
+	err = repo.FetchContext(ctx, &gogit.FetchOptions{RemoteName: mirrorSource, Tags: gogit.NoTags})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("fetching source repository %s: %w", redactUserinfo(source), err)
+	}
+
+	// the default branch of the remote follows the source's HEAD
+	refs, err := src.ListContext(ctx, &gogit.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing source references: %w", err)
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			if err := repo.Storer.SetReference(ref); err != nil {
+				return nil, fmt.Errorf("setting HEAD: %w", err)
+			}
+		}
+	}
+
+	return git.NewRepository(repo), nil
+}
+
+// redactUserinfo removes credentials from a URL, if any.
+func redactUserinfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// mirrorRequests returns the push requests updating the branches and tags of
+// remote to those of local, deleting those missing from local if prune is set.
+// References already up to date are omitted.
+func mirrorRequests(local git.Repository, remote model.Modeler, prune bool) ([]gittypes.PushRequest, error) {
+	remoteRefs := make(map[plumbing.ReferenceName]string)
+	for name, info := range remote.HeadRefs() {
+		remoteRefs[name] = info.Commit
+	}
+	for name, info := range remote.TagRefs() {
+		remoteRefs[name] = info.Commit
+	}
+
+	refs, err := local.References()
+	if err != nil {
+		return nil, fmt.Errorf("listing source references: %w", err)
+	}
+	defer refs.Close()
+
+	var reqs []gittypes.PushRequest
+	localRefs := make(map[plumbing.ReferenceName]struct{})
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || (!ref.Name().IsBranch() && !ref.Name().IsTag()) {
+			return nil
+		}
+		localRefs[ref.Name()] = struct{}{}
+		if remoteRefs[ref.Name()] == ref.Hash().String() {
+			return nil
+		}
+		reqs = append(reqs, gittypes.PushRequest{Cmd: gittypes.Push, Force: true, Src: ref.Name(), Remote: ref.Name()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterating source references: %w", err)
+	}
+
+	if prune {
+		for name := range remoteRefs {
+			if _, ok := localRefs[name]; !ok {
+				reqs = append(reqs, gittypes.PushRequest{Cmd: gittypes.Push, Remote: name})
+			}
+		}
+	}
+
+	slices.SortFunc(reqs, func(a, b gittypes.PushRequest) int {
+		return cmp.Compare(a.Remote, b.Remote)
+	})
+	return reqs, nil
+}
+
+// writePushResults writes the result of each push request, returning
+// errRejected if any failed.
+func writePushResults(out io.Writer, reqs []gittypes.PushRequest, results []gittypes.PushResponse, errRejected error) error {
+	var failed int
+	for _, req := range reqs {
+		i := slices.IndexFunc(results, func(r gittypes.PushResponse) bool { return r.Remote == req.Remote })
+		var line string
+		switch {
+		case i < 0:
+			continue
+		case results[i].Error != nil:
+			failed++
+			line = fmt.Sprintf(" ! %s (%s)", req.Remote, results[i].Error)
+		case req.Src == "":
+			line = fmt.Sprintf(" - %s (deleted)", req.Remote)
+		default:
+			line = fmt.Sprintf(" + %s", req.Remote)
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d rejected", errRejected, failed, len(reqs))
+	}
+	return nil
+}
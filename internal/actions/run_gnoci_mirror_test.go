@@ -0,0 +1,180 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/apis"
+)
+
+func TestMirror_Run(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	first, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	second, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("feature", first)
+	assert.NoError(t, err)
+	_, err = builder.CreateTag("v1", first)
+	assert.NoError(t, err)
+
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	address := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+	mirror := &Mirror{Gnoci: base, Source: srcDir, Address: address}
+
+	remoteRefs := func(t *testing.T) map[plumbing.ReferenceName]string {
+		t.Helper()
+
+		remote, cleanup, err := base.remote(t.Context(), address, false)
+		assert.NoError(t, err)
+		defer func() { assert.NoError(t, cleanup()) }()
+		_, err = remote.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		assert.Equal(t, plumbing.ReferenceName("refs/heads/master"), remote.DefaultBranch())
+
+		refs := make(map[plumbing.ReferenceName]string)
+		for name, info := range remote.HeadRefs() {
+			refs[name] = info.Commit
+		}
+		for name, info := range remote.TagRefs() {
+			refs[name] = info.Commit
+		}
+		return refs
+	}
+
+	t.Run("Initial", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := mirror.Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Equal(t, " + refs/heads/feature\n + refs/heads/master\n + refs/tags/v1\n", out.String())
+		assert.Equal(t, map[plumbing.ReferenceName]string{
+			"refs/heads/master":  second.String(),
+			"refs/heads/feature": first.String(),
+			"refs/tags/v1":       first.String(),
+		}, remoteRefs(t))
+	})
+
+	t.Run("Up To Date", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := mirror.Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Equal(t, "Everything up-to-date\n", out.String())
+	})
+
+	assert.NoError(t, builder.DeleteBranch("feature"))
+
+	t.Run("Without Prune", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := mirror.Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Contains(t, remoteRefs(t), plumbing.ReferenceName("refs/heads/feature"))
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		mirror.Prune = true
+		out := new(bytes.Buffer)
+		err := mirror.Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Equal(t, " - refs/heads/feature (deleted)\n", out.String())
+		assert.NotContains(t, remoteRefs(t), plumbing.ReferenceName("refs/heads/feature"))
+	})
+}
+
+func TestMirror_Reproducible(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	t.Setenv(sourceDateEpochEnv, "")
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	first, err := builder.CreateRandomCommit(256)
+	assert.NoError(t, err)
+	for range 2 {
+		_, err = builder.CreateRandomCommit(256)
+		assert.NoError(t, err)
+	}
+	_, err = builder.CreateTag("v1", first)
+	assert.NoError(t, err)
+
+	// mirrors of the same repository into separate remotes are identical
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	var manifests []digest.Digest
+	for range 2 {
+		address := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+		err := (&Mirror{Gnoci: base, Source: srcDir, Address: address}).Run(t.Context(), new(bytes.Buffer))
+		assert.NoError(t, err)
+
+		remote, cleanup, err := base.remote(t.Context(), address, false)
+		assert.NoError(t, err)
+		manDesc, err := remote.Fetch(t.Context())
+		assert.NoError(t, err)
+		assert.NoError(t, cleanup())
+		manifests = append(manifests, manDesc.Digest)
+	}
+	assert.Equal(t, manifests[0], manifests[1])
+}
+
+func TestMirror_Annotations(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	_, err = builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	layoutDir := filepath.Join(t.TempDir(), "repo")
+	address := "oci+layout://" + layoutDir + ":sync"
+
+	t.Run("Reserved", func(t *testing.T) {
+		mirror := &Mirror{Gnoci: base, Source: srcDir, Address: address, Annotations: map[string]string{ocispec.AnnotationCreated: "now"}}
+		err := mirror.Run(t.Context(), new(bytes.Buffer))
+		assert.ErrorIs(t, err, model.ErrReservedAnnotation)
+	})
+
+	t.Run("Manifest", func(t *testing.T) {
+		mirror := &Mirror{Gnoci: base, Source: srcDir, Address: address, Annotations: map[string]string{"com.example.team": "platform"}}
+		err := mirror.Run(t.Context(), new(bytes.Buffer))
+		assert.NoError(t, err)
+
+		store, err := oci.New(layoutDir)
+		assert.NoError(t, err)
+		manDesc, err := store.Resolve(t.Context(), "sync")
+		assert.NoError(t, err)
+		raw, err := content.FetchAll(t.Context(), store, manDesc)
+		assert.NoError(t, err)
+		var man ocispec.Manifest
+		assert.NoError(t, json.Unmarshal(raw, &man))
+		assert.Equal(t, "platform", man.Annotations["com.example.team"])
+		assert.Equal(t, srcDir, man.Annotations[ocispec.AnnotationSource])
+	})
+}
+
+// skipUnlessSHA1 skips tests mirroring a local repository when built with the
+// "sha256" tag, go-git transports only support SHA-1 repositories.
+func skipUnlessSHA1(t *testing.T) {
+	t.Helper()
+	if git.ObjectFormat() != formatcfg.SHA1 {
+		t.Skip("go-git transports only support SHA-1 repositories")
+	}
+}
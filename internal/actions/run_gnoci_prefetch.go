@@ -0,0 +1,124 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// Prefetch represents the gnoci prefetch action.
+type Prefetch struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Revisions are the branches, tags, full reference names, or commit
+	// hashes whose packfile layers are fetched. Empty fetches all layers.
+	Revisions []string
+	// LFS additionally fetches all LFS files.
+	LFS bool
+}
+
+// Run fetches the packfile layers needed by the revisions, and LFS files if
+// requested, into the local cache without creating a local repository.
+func (action *Prefetch) Run(ctx context.Context, out io.Writer) error {
+	cfg, err := action.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("getting configuration: %w", err)
+	}
+	c := cacheFromConfig(cfg.Cache)
+	if !cfg.Cache.Enabled {
+		slog.WarnContext(ctx, "cache is not enabled, prefetched layers are unused until cache.enabled is set", slog.String("dir", c.Dir()))
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true, model.WithCache(c))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	layers, err := prefetchLayers(ctx, remote, action.Revisions)
+	if err != nil {
+		return err
+	}
+	dgsts := make([]digest.Digest, 0, len(layers))
+	for _, desc := range layers {
+		dgsts = append(dgsts, desc.Digest)
+	}
+	fetched, err := remote.Prefetch(ctx, dgsts...)
+	if err != nil {
+		return fmt.Errorf("prefetching packfile layers: %w", err)
+	}
+	if _, err := fmt.Fprintf(out, "Cached %d packfile layers, %d fetched\n", len(layers), fetched); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	if !action.LFS {
+		return nil
+	}
+	_, err = remote.FetchLFS(ctx)
+	switch {
+	case errors.Is(err, model.ErrLFSManifestNotFound):
+		_, err = fmt.Fprintln(out, "No LFS files")
+		if err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("fetching LFS metadata: %w", err)
+	}
+	fetched, err = remote.PrefetchLFS(ctx)
+	if err != nil {
+		return fmt.Errorf("prefetching LFS files: %w", err)
+	}
+	if _, err := fmt.Fprintf(out, "Cached %d LFS files, %d fetched\n", len(remote.ListLFSObjects()), fetched); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
+
+// prefetchLayers returns the packfile layers needed to fetch the revisions,
+// all layers if none are given. Each layer may be thin, with delta bases in
+// the layers before it, so a revision needs its layer and all older layers.
+func prefetchLayers(ctx context.Context, remote model.ReadOnlyModeler, revs []string) ([]ocispec.Descriptor, error) {
+	layers := remote.Layers()
+	if len(revs) == 0 {
+		return layers, nil
+	}
+
+	newest := -1
+	for _, rev := range revs {
+		ref, err := resolveRevision(ctx, remote, rev)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := remote.ResolveCommit(ctx, ref.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("resolving layer of %s: %w", rev, err)
+		}
+		i := slices.IndexFunc(layers, func(desc ocispec.Descriptor) bool { return desc.Digest == layer })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: layer %s of %s", model.ErrCommitNotFound, layer, rev)
+		}
+		newest = max(newest, i)
+	}
+
+	return layers[:newest+1], nil
+}
@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+func Test_prefetchLayers(t *testing.T) {
+	layers := []ocispec.Descriptor{
+		{Digest: digest.FromString("first")},
+		{Digest: digest.FromString("second")},
+		{Digest: digest.FromString("third")},
+	}
+	commit := plumbing.NewHash("1111111111111111111111111111111111111111")
+	newRemote := func(t *testing.T) *modelmock.MockModeler {
+		t.Helper()
+		remote := modelmock.NewMockModeler(gomock.NewController(t))
+		remote.EXPECT().Layers().Return(layers).AnyTimes()
+		return remote
+	}
+
+	t.Run("All Layers", func(t *testing.T) {
+		got, err := prefetchLayers(t.Context(), newRemote(t), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, layers, got)
+	})
+
+	t.Run("Branch", func(t *testing.T) {
+		remote := newRemote(t)
+		remote.EXPECT().ResolveRef(gomock.Any(), plumbing.Main).Return(plumbing.NewHashReference(plumbing.Main, commit), layers[1].Digest, nil)
+		remote.EXPECT().ResolveCommit(gomock.Any(), commit).Return(layers[1].Digest, nil)
+
+		// older layers hold the delta bases of newer ones
+		got, err := prefetchLayers(t.Context(), remote, []string{"main"})
+		assert.NoError(t, err)
+		assert.Equal(t, layers[:2], got)
+	})
+
+	t.Run("Unknown Revision", func(t *testing.T) {
+		remote := newRemote(t)
+		remote.EXPECT().ResolveRef(gomock.Any(), gomock.Any()).Return(nil, digest.Digest(""), model.ErrReferenceNotFound).Times(2)
+
+		_, err := prefetchLayers(t.Context(), remote, []string{"missing"})
+		assert.ErrorIs(t, err, model.ErrReferenceNotFound)
+	})
+}
@@ -0,0 +1,154 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// Projects represents the gnoci ls-projects action.
+type Projects struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the repository, any tag is ignored.
+	Address string
+	// Prefix is the tag namespace of the projects, e.g. "projects.", an empty
+	// prefix listing every Git manifest of the repository.
+	Prefix string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// Project describes a Git repository stored under a tag of a repository
+// shared with other Git repositories.
+type Project struct {
+	// Name is the tag, excluding the prefix of its namespace.
+	Name string `json:"name"`
+	// Tag is the full name of the tag.
+	Tag string `json:"tag"`
+	// Digest is the digest of the tagged Git manifest.
+	Digest digest.Digest `json:"digest"`
+}
+
+// Run lists the tags of a repository within a tag namespace that hold Git
+// manifests, each a separate Git repository pushed and fetched by its full
+// reference.
+func (action *Projects) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := action.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("getting configuration: %w", err)
+	}
+
+	addr, err := ociutil.ParseAddressDefaultTag(action.Address, cfg.DefaultTag)
+	if err != nil {
+		return fmt.Errorf("parsing remote address: %w", err)
+	}
+	if addr.Layout != "" {
+		return errors.New("listing projects requires a registry repository")
+	}
+
+	repoOpts := repoOptsFromConfig(addr.Ref.Host(), cfg)
+	repoOpts.UserAgent = ociutil.GnociUserAgent
+	repoOpts.PlainHTTP = repoOpts.PlainHTTP || addr.PlainHTTP
+
+	reg, err := ociutil.NewRegistry(ctx, addr.Ref.Registry, repoOpts)
+	if err != nil {
+		return fmt.Errorf("initializing registry %s: %w", addr.Ref.Registry, err)
+	}
+
+	tags, err := listTags(ctx, reg, addr.Ref.Repository, action.Prefix)
+	if err != nil {
+		return err
+	}
+
+	snapshots := snapshotTags(ctx, reg, addr.Ref.Repository, tags)
+
+	projects := make([]Project, 0, len(tags))
+	for _, t := range tags {
+		if _, ok := snapshots[t.Tag]; !t.Git || ok {
+			continue
+		}
+		projects = append(projects, Project{
+			Name:   strings.TrimPrefix(t.Tag, action.Prefix),
+			Tag:    t.Tag,
+			Digest: t.Digest,
+		})
+	}
+
+	if output == OutputJSON {
+		return writeJSONList(out, "ProjectList", projects)
+	}
+	return writeProjects(out, projects)
+}
+
+// snapshotTags returns the tags recorded by the snapshot indexes among tags.
+// Snapshot tags hold the Git manifests of projects, but are not projects
+// themselves.
+func snapshotTags(ctx context.Context, reg *remote.Registry, name string, tags []RepoTag) map[string]struct{} {
+	snapshots := make(map[string]struct{})
+	repo, err := reg.Repository(ctx, name)
+	if err != nil {
+		slog.WarnContext(ctx, "unable to list snapshots", slog.String("repository", name), slog.String("error", err.Error()))
+		return snapshots
+	}
+
+	for _, t := range tags {
+		if t.Git || !strings.HasSuffix(t.Tag, oci.SnapshotIndexSuffix) {
+			continue
+		}
+		_, raw, err := oras.FetchBytes(ctx, repo, t.Tag, oras.DefaultFetchBytesOptions)
+		if err != nil {
+			slog.WarnContext(ctx, "unable to fetch snapshot index", slog.String("repository", name), slog.String("tag", t.Tag), slog.String("error", err.Error()))
+			continue
+		}
+		var idx ocispec.Index
+		if err := json.Unmarshal(raw, &idx); err != nil || idx.ArtifactType != oci.ArtifactTypeGitSnapshots {
+			continue
+		}
+		for _, desc := range idx.Manifests {
+			if tag := desc.Annotations[ocispec.AnnotationRefName]; tag != "" {
+				snapshots[tag] = struct{}{}
+			}
+		}
+	}
+
+	return snapshots
+}
+
+// writeProjects writes a table of projects.
+func writeProjects(out io.Writer, projects []Project) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "PROJECT\tTAG\tDIGEST"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, p := range projects {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Name, p.Tag, p.Digest); err != nil {
+			return fmt.Errorf("writing project %s: %w", p.Name, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/pkg/apis"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func TestProjects_Run(t *testing.T) {
+	gitMan := testManifest(t, oci.ArtifactTypeGitManifest)
+	otherMan := testManifest(t, "application/vnd.example")
+	snapshotTag := "sync-refs-heads-main-eaba08b8fae9"
+	snapshotIdx := ocispec.Index{
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: oci.ArtifactTypeGitSnapshots,
+		Manifests: []ocispec.Descriptor{{
+			MediaType:   ocispec.MediaTypeImageManifest,
+			Digest:      digest.FromBytes(gitMan),
+			Size:        int64(len(gitMan)),
+			Annotations: map[string]string{ocispec.AnnotationRefName: snapshotTag},
+		}},
+	}
+	snapshotIdx.SchemaVersion = 2
+	snapshotIdxRaw, err := json.Marshal(snapshotIdx)
+	assert.NoError(t, err)
+	srv := newCatalogRegistry(t,
+		[]string{"team/monorepo"},
+		map[string][]byte{
+			"team/monorepo:projects.foo":   gitMan,
+			"team/monorepo:projects.bar":   gitMan,
+			"team/monorepo:projects.image": otherMan,
+			"team/monorepo:sync":           gitMan,
+			"team/monorepo:" + snapshotTag: gitMan,
+			"team/monorepo:sync-snapshots": snapshotIdxRaw,
+		})
+	u, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	address := ociutil.HTTPScheme + u.Host + "/team/monorepo"
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+
+	t.Run("Prefix", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&Projects{Gnoci: base, Address: address, Prefix: "projects.", Output: OutputJSON}).Run(t.Context(), out)
+		assert.NoError(t, err)
+
+		var got OutputList[Project]
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		assert.Equal(t, outputMeta("ProjectList"), got.OutputMeta)
+		assert.ElementsMatch(t, []Project{
+			{Name: "foo", Tag: "projects.foo", Digest: digest.FromBytes(gitMan)},
+			{Name: "bar", Tag: "projects.bar", Digest: digest.FromBytes(gitMan)},
+		}, got.Items)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&Projects{Gnoci: base, Address: address}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 4)
+		assert.Equal(t, []string{"PROJECT", "TAG", "DIGEST"}, strings.Fields(lines[0]))
+	})
+
+	t.Run("Snapshots Excluded", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&Projects{Gnoci: base, Address: address, Prefix: "sync", Output: OutputJSON}).Run(t.Context(), out)
+		assert.NoError(t, err)
+
+		var got OutputList[Project]
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		assert.Equal(t, []Project{{Name: "", Tag: "sync", Digest: digest.FromBytes(gitMan)}}, got.Items)
+	})
+
+	t.Run("Layout", func(t *testing.T) {
+		err := (&Projects{Gnoci: base, Address: "oci+layout://" + t.TempDir()}).Run(t.Context(), new(bytes.Buffer))
+		assert.Error(t, err)
+	})
+}
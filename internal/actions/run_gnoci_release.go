@@ -0,0 +1,118 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// ReleaseCreate represents the gnoci release create action.
+type ReleaseCreate struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Tag is the tag of the release, in the same OCI repository.
+	Tag string
+}
+
+// Run snapshots the current Git manifest of the remote repository, and its
+// LFS manifest if any, into an image index tagged as the release.
+func (action *ReleaseCreate) Run(ctx context.Context, out io.Writer) error {
+	remote, cleanup, err := action.remote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	manDesc, err := remote.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+	if _, err := remote.FetchLFS(ctx); err != nil && !errors.Is(err, model.ErrLFSManifestNotFound) {
+		return fmt.Errorf("fetching LFS metadata: %w", err)
+	}
+
+	idxDesc, err := remote.TagRelease(ctx, action.Tag)
+	if err != nil {
+		return fmt.Errorf("tagging release: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(out, "Released %s as %s, index %s\n", manDesc.Digest, action.Tag, idxDesc.Digest); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseList represents the gnoci release list action.
+type ReleaseList struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// Run lists the releases of the remote repository, oldest first.
+func (action *ReleaseList) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.remote(ctx, action.Address, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	releases, err := remote.Releases(ctx)
+	if err != nil {
+		return fmt.Errorf("listing releases: %w", err)
+	}
+
+	if output == OutputJSON {
+		return writeReleasesJSON(out, releases)
+	}
+	return writeReleases(out, releases)
+}
+
+// writeReleases writes a table of releases.
+func writeReleases(out io.Writer, releases []model.Release) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "TAG\tDIGEST\tREVISION\tCREATED"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, r := range releases {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Tag, r.Digest, orDash(r.Revision), orDash(r.Created)); err != nil {
+			return fmt.Errorf("writing release %s: %w", r.Tag, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeReleasesJSON writes releases as a JSON ReleaseList document.
+func writeReleasesJSON(out io.Writer, releases []model.Release) error {
+	return writeJSONList(out, "ReleaseList", releases)
+}
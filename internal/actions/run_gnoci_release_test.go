@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/apis"
+)
+
+func TestRelease_Run(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	address := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+	err = (&Mirror{Gnoci: base, Source: srcDir, Address: address}).Run(t.Context(), new(bytes.Buffer))
+	assert.NoError(t, err)
+
+	t.Run("Create", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&ReleaseCreate{Gnoci: base, Address: address, Tag: "v1.0.0"}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "as v1.0.0, index sha256:")
+	})
+
+	t.Run("Fetch Release", func(t *testing.T) {
+		releaseAddress := address[:len(address)-len("sync")] + "v1.0.0"
+		out := new(bytes.Buffer)
+		err := (&List{Gnoci: base, Address: releaseAddress}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), commit.String())
+	})
+
+	t.Run("Create Existing", func(t *testing.T) {
+		err := (&ReleaseCreate{Gnoci: base, Address: address, Tag: "v1.0.0"}).Run(t.Context(), new(bytes.Buffer))
+		assert.ErrorIs(t, err, model.ErrReleaseExists)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&ReleaseList{Gnoci: base, Address: address, Output: OutputJSON}).Run(t.Context(), out)
+		assert.NoError(t, err)
+
+		var releases OutputList[model.Release]
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &releases))
+		assert.Equal(t, outputMeta("ReleaseList"), releases.OutputMeta)
+		assert.Len(t, releases.Items, 1)
+		assert.Equal(t, "v1.0.0", releases.Items[0].Tag)
+		assert.Equal(t, commit.String(), releases.Items[0].Revision)
+	})
+
+	t.Run("Unsupported Output", func(t *testing.T) {
+		err := (&ReleaseList{Gnoci: base, Address: address, Output: "yaml"}).Run(t.Context(), new(bytes.Buffer))
+		assert.ErrorIs(t, err, errUnsupportedOutput)
+	})
+}
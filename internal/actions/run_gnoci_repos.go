@@ -0,0 +1,192 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// Repos represents the gnoci repos action.
+type Repos struct {
+	*Gnoci
+
+	// Address is the registry, optionally followed by a repository namespace,
+	// e.g. example.com/team.
+	Address string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+	// GitOnly omits tags not holding a Git manifest.
+	GitOnly bool
+}
+
+// RepoTag describes a tag of a repository.
+type RepoTag struct {
+	// Repository is the name of the repository, excluding the registry.
+	Repository string `json:"repository"`
+	// Tag is the name of the tag.
+	Tag string `json:"tag"`
+	// Digest is the digest of the tagged manifest.
+	Digest digest.Digest `json:"digest"`
+	// Git is true if the tag holds a Git manifest.
+	Git bool `json:"git"`
+}
+
+// Run lists the tags of each repository in a registry namespace, noting which
+// hold Git manifests. The registry must support the catalog API.
+func (action *Repos) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := action.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("getting configuration: %w", err)
+	}
+
+	host, namespace, _ := strings.Cut(strings.TrimPrefix(action.Address, "oci://"), "/")
+	repoOpts := repoOptsFromConfig(host, cfg)
+	repoOpts.UserAgent = ociutil.GnociUserAgent
+
+	reg, err := ociutil.NewRegistry(ctx, host, repoOpts)
+	if err != nil {
+		return fmt.Errorf("initializing registry %s: %w", host, err)
+	}
+
+	tags, err := listRepoTags(ctx, reg, namespace)
+	if err != nil {
+		return err
+	}
+	if action.GitOnly {
+		tags = slices.DeleteFunc(tags, func(t RepoTag) bool { return !t.Git })
+	}
+
+	if output == OutputJSON {
+		return writeRepoTagsJSON(out, tags)
+	}
+	return writeRepoTags(out, tags)
+}
+
+// listRepoTags lists the tags of each repository within namespace, an empty
+// namespace listing all repositories.
+func listRepoTags(ctx context.Context, reg *remote.Registry, namespace string) ([]RepoTag, error) {
+	namespace = strings.Trim(namespace, "/")
+
+	var repos []string
+	err := reg.Repositories(ctx, "", func(page []string) error {
+		for _, repo := range page {
+			if namespace == "" || repo == namespace || strings.HasPrefix(repo, namespace+"/") {
+				repos = append(repos, repo)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing repositories: %w", err)
+	}
+
+	tags := make([]RepoTag, 0, len(repos))
+	for _, name := range repos {
+		repoTags, err := listTags(ctx, reg, name, "")
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, repoTags...)
+	}
+
+	return tags, nil
+}
+
+// listTags lists the tags of a repository beginning with prefix, resolving
+// whether each holds a Git manifest.
+func listTags(ctx context.Context, reg *remote.Registry, name, prefix string) ([]RepoTag, error) {
+	repo, err := reg.Repository(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("initializing repository %s: %w", name, err)
+	}
+
+	var tagNames []string
+	if err := repo.Tags(ctx, "", func(page []string) error {
+		for _, tag := range page {
+			if strings.HasPrefix(tag, prefix) {
+				tagNames = append(tagNames, tag)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("listing tags of repository %s: %w", name, err)
+	}
+
+	tags := make([]RepoTag, 0, len(tagNames))
+	for _, tag := range tagNames {
+		desc, err := repo.Resolve(ctx, tag)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s:%s: %w", name, tag, err)
+		}
+
+		isGit, err := isGitManifest(ctx, repo, desc)
+		if err != nil {
+			slog.WarnContext(ctx, "unable to resolve artifact type", slog.String("repository", name), slog.String("tag", tag), slog.String("error", err.Error()))
+		}
+		tags = append(tags, RepoTag{Repository: name, Tag: tag, Digest: desc.Digest, Git: isGit})
+	}
+
+	return tags, nil
+}
+
+// isGitManifest returns true if desc is a Git manifest. The manifest is only
+// fetched if its media type is an image manifest.
+func isGitManifest(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (bool, error) {
+	if desc.ArtifactType != "" || desc.MediaType != ocispec.MediaTypeImageManifest {
+		return desc.ArtifactType == oci.ArtifactTypeGitManifest, nil
+	}
+
+	manRaw, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return false, fmt.Errorf("fetching manifest: %w", err)
+	}
+	var man ocispec.Manifest
+	if err := json.Unmarshal(manRaw, &man); err != nil {
+		return false, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	return man.ArtifactType == oci.ArtifactTypeGitManifest, nil
+}
+
+// writeRepoTags writes a table of repository tags.
+func writeRepoTags(out io.Writer, tags []RepoTag) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "REPOSITORY\tTAG\tGIT\tDIGEST"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, t := range tags {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n", t.Repository, t.Tag, t.Git, t.Digest); err != nil {
+			return fmt.Errorf("writing tag %s:%s: %w", t.Repository, t.Tag, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeRepoTagsJSON writes repository tags as a JSON RepoTagList document.
+func writeRepoTagsJSON(out io.Writer, tags []RepoTag) error {
+	return writeJSONList(out, "RepoTagList", tags)
+}
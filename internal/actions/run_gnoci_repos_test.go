@@ -0,0 +1,158 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// newCatalogRegistry serves a registry supporting the catalog API, with
+// manifests keyed by "<repository>:<tag>".
+func newCatalogRegistry(t *testing.T, repos []string, manifests map[string][]byte) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v2/")
+		switch {
+		case path == "_catalog":
+			_ = json.NewEncoder(w).Encode(map[string]any{"repositories": repos})
+		case strings.HasSuffix(path, "/tags/list"):
+			repo := strings.TrimSuffix(path, "/tags/list")
+			tags := []string{}
+			for ref := range manifests {
+				if name, tag, _ := strings.Cut(ref, ":"); name == repo {
+					tags = append(tags, tag)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": repo, "tags": tags})
+		case strings.Contains(path, "/manifests/"):
+			repo, ref, _ := strings.Cut(path, "/manifests/")
+			man, ok := manifests[repo+":"+ref]
+			for key, m := range manifests {
+				if strings.HasPrefix(key, repo+":") && digest.FromBytes(m).String() == ref {
+					man, ok = m, true
+				}
+			}
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(man).String())
+			w.Header().Set("Content-Length", strconv.Itoa(len(man)))
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(man)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testManifest encodes an empty image manifest of artifactType.
+func testManifest(t *testing.T, artifactType string) []byte {
+	t.Helper()
+	man := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{},
+	}
+	man.SchemaVersion = 2
+	raw, err := json.Marshal(man)
+	assert.NoError(t, err)
+	return raw
+}
+
+func Test_listRepoTags(t *testing.T) {
+	gitMan := testManifest(t, oci.ArtifactTypeGitManifest)
+	otherMan := testManifest(t, "application/vnd.example")
+	srv := newCatalogRegistry(t,
+		[]string{"team/project", "team/image", "other/project"},
+		map[string][]byte{
+			"team/project:main":  gitMan,
+			"team/image:latest":  otherMan,
+			"other/project:main": gitMan,
+		})
+
+	u, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	reg, err := ociutil.NewRegistry(t.Context(), u.Host, &ociutil.RepositoryOptions{PlainHTTP: true})
+	assert.NoError(t, err)
+
+	got, err := listRepoTags(t.Context(), reg, "team")
+	assert.NoError(t, err)
+	assert.Equal(t, []RepoTag{
+		{Repository: "team/project", Tag: "main", Digest: digest.FromBytes(gitMan), Git: true},
+		{Repository: "team/image", Tag: "latest", Digest: digest.FromBytes(otherMan), Git: false},
+	}, got)
+}
+
+func Test_isGitManifest(t *testing.T) {
+	store := orasmemory.New()
+
+	t.Run("Artifact Type Descriptor", func(t *testing.T) {
+		desc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, ArtifactType: oci.ArtifactTypeGitManifest}
+		got, err := isGitManifest(t.Context(), store, desc)
+		assert.NoError(t, err)
+		assert.True(t, got)
+	})
+
+	t.Run("Fetched Manifest", func(t *testing.T) {
+		desc, err := oras.PushBytes(t.Context(), store, ocispec.MediaTypeImageManifest, testManifest(t, oci.ArtifactTypeGitManifest))
+		assert.NoError(t, err)
+		got, err := isGitManifest(t.Context(), store, desc)
+		assert.NoError(t, err)
+		assert.True(t, got)
+	})
+
+	t.Run("Other Artifact", func(t *testing.T) {
+		desc, err := oras.PushBytes(t.Context(), store, ocispec.MediaTypeImageManifest, testManifest(t, "application/vnd.example"))
+		assert.NoError(t, err)
+		got, err := isGitManifest(t.Context(), store, desc)
+		assert.NoError(t, err)
+		assert.False(t, got)
+	})
+}
+
+func Test_writeRepoTags(t *testing.T) {
+	tags := []RepoTag{
+		{Repository: "team/project", Tag: "main", Digest: digest.FromString("a"), Git: true},
+		{Repository: "team/image", Tag: "latest", Digest: digest.FromString("b")},
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NoError(t, writeRepoTags(out, tags))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, []string{"REPOSITORY", "TAG", "GIT", "DIGEST"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"team/project", "main", "true", digest.FromString("a").String()}, strings.Fields(lines[1]))
+		assert.Equal(t, []string{"team/image", "latest", "false", digest.FromString("b").String()}, strings.Fields(lines[2]))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NoError(t, writeRepoTagsJSON(out, tags))
+		var got OutputList[RepoTag]
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		assert.Equal(t, outputMeta("RepoTagList"), got.OutputMeta)
+		assert.Equal(t, tags, got.Items)
+	})
+}
@@ -0,0 +1,148 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/act3-ai/gnoci/internal/cmd"
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// restoreDestination is the name of the destination remote in the temporary
+// repository.
+const restoreDestination = "destination"
+
+// Restore represents the gnoci restore action.
+type Restore struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Destination is the URL of the Git repository to push to, any URL
+	// supported by go-git, e.g. https://, ssh://, or a local path.
+	Destination string
+	// Force overwrites references of the destination which are not ancestors
+	// of the restored references.
+	Force bool
+}
+
+// Run fetches the entire remote repository into a temporary repository, and
+// pushes all of its references to the destination.
+func (action *Restore) Run(ctx context.Context, out io.Writer) error {
+	remote, cleanup, err := action.remote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gnoci-restore-*")
+	if err != nil {
+		return fmt.Errorf("initializing temp directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.ErrorContext(ctx, "removing temporary git repository", slog.String("error", err.Error()))
+		}
+	}()
+
+	repo, err := git.PlainInit(tmpDir, true)
+	if err != nil {
+		return fmt.Errorf("initializing temporary repository: %w", err)
+	}
+
+	refs, err := fetchAllRefs(ctx, git.NewRepository(repo), remote)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("%w: remote repository has no references", model.ErrReferenceNotFound)
+	}
+
+	if err := pushDestination(ctx, repo, action.Destination, action.Force); err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if _, err := fmt.Fprintf(out, " + %s\n", ref.Name()); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchAllRefs fetches every packfile layer of remote into local, and creates
+// each of its heads, tags, and notes, returning them sorted by name.
+func fetchAllRefs(ctx context.Context, local git.Repository, remote model.ReadOnlyModeler) ([]*plumbing.Reference, error) {
+	infos := maps.Clone(remote.HeadRefs())
+	maps.Copy(infos, remote.TagRefs())
+	maps.Copy(infos, remote.NoteRefs())
+
+	refs := make([]*plumbing.Reference, 0, len(infos))
+	reqs := make([]gittypes.FetchRequest, 0, len(infos))
+	for _, name := range slices.Sorted(maps.Keys(infos)) {
+		ref := plumbing.NewHashReference(name, plumbing.NewHash(infos[name].Commit))
+		refs = append(refs, ref)
+		reqs = append(reqs, gittypes.FetchRequest{Cmd: gittypes.Fetch, Ref: ref})
+	}
+
+	if err := cmd.Fetch(ctx, local, remote, reqs, &cmd.Options{}); err != nil {
+		return nil, fmt.Errorf("fetching remote repository: %w", err)
+	}
+
+	st := local.Storer()
+	for _, ref := range refs {
+		if err := st.SetReference(ref); err != nil {
+			return nil, fmt.Errorf("creating reference %s: %w", ref.Name(), err)
+		}
+	}
+
+	return refs, nil
+}
+
+// pushDestination pushes all heads, tags, and notes of repo to the Git
+// repository at destination.
+func pushDestination(ctx context.Context, repo *gogit.Repository, destination string, force bool) error {
+	_, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: restoreDestination,
+		URLs: []string{destination},
+	})
+	if err != nil {
+		return fmt.Errorf("adding destination remote: %w", err)
+	}
+
+	specs := []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*", "refs/notes/*:refs/notes/*"}
+	if force {
+		for i := range specs {
+			specs[i] = "+" + specs[i]
+		}
+	}
+
+	slog.InfoContext(ctx, "pushing to destination repository", slog.String("url", redactUserinfo(destination)))
+	err = repo.PushContext(ctx, &gogit.PushOptions{RemoteName: restoreDestination, RefSpecs: specs})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("pushing to destination repository %s: %w", redactUserinfo(destination), err)
+	}
+
+	return nil
+}
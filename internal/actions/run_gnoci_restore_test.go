@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/apis"
+)
+
+func TestRestore_Run(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	first, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	second, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateTag("v1", first)
+	assert.NoError(t, err)
+
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	address := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+	err = (&Mirror{Gnoci: base, Source: srcDir, Address: address}).Run(t.Context(), new(bytes.Buffer))
+	assert.NoError(t, err)
+
+	dstDir := t.TempDir()
+	dst, err := gogit.PlainInit(dstDir, true)
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	err = (&Restore{Gnoci: base, Address: address, Destination: dstDir}).Run(t.Context(), out)
+	assert.NoError(t, err)
+	assert.Equal(t, " + refs/heads/master\n + refs/tags/v1\n", out.String())
+
+	for name, want := range map[plumbing.ReferenceName]plumbing.Hash{"refs/heads/master": second, "refs/tags/v1": first} {
+		ref, err := dst.Reference(name, false)
+		assert.NoError(t, err)
+		assert.Equal(t, want, ref.Hash())
+	}
+	_, err = dst.CommitObject(first)
+	assert.NoError(t, err)
+
+	t.Run("Up To Date", func(t *testing.T) {
+		err := (&Restore{Gnoci: base, Address: address, Destination: dstDir}).Run(t.Context(), new(bytes.Buffer))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,127 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// Sign represents the gnoci sign action.
+type Sign struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Key is the path to the private key used to sign. Defaults to the
+	// configured signing key.
+	Key string
+}
+
+// Run signs the Git manifest of the remote repository, attaching the
+// signature as a referrer.
+func (action *Sign) Run(ctx context.Context, out io.Writer) error {
+	keyPath := action.Key
+	if keyPath == "" {
+		cfg, err := action.GetConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("getting configuration: %w", err)
+		}
+		keyPath = cfg.Push.SigningKey
+	}
+	if keyPath == "" {
+		return errors.New("a signing key is required, specify one with --key or push.signingKey in the configuration")
+	}
+
+	signer, err := model.LoadSigner(keyPath)
+	if err != nil {
+		return fmt.Errorf("loading signing key: %w", err)
+	}
+
+	// the repository may not be signed yet, do not enforce the verification policy
+	remote, cleanup, err := action.remote(ctx, action.Address, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	manDesc, err := remote.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	sigDesc, err := remote.Sign(ctx, signer)
+	if err != nil {
+		return fmt.Errorf("signing git manifest: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(out, "Signed %s, signature %s\n", manDesc.Digest, sigDesc.Digest); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
+
+// Verify represents the gnoci verify action.
+type Verify struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Keys are paths to the public keys trusted to sign. Defaults to the keys
+	// of the configured verification policy.
+	Keys []string
+}
+
+// Run verifies the Git manifest of the remote repository is signed by a
+// trusted key.
+func (action *Verify) Run(ctx context.Context, out io.Writer) error {
+	keyPaths := action.Keys
+	if len(keyPaths) == 0 {
+		cfg, err := action.GetConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("getting configuration: %w", err)
+		}
+		keyPaths = cfg.VerifyPolicy.Keys
+	}
+	if len(keyPaths) == 0 {
+		return errors.New("a verification key is required, specify one with --key or verifyPolicy.keys in the configuration")
+	}
+
+	keys, err := loadPublicKeys(keyPaths)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.remote(ctx, action.Address, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	manDesc, err := remote.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	if err := remote.Verify(ctx, keys...); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if _, err := fmt.Fprintf(out, "Verified %s\n", manDesc.Digest); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
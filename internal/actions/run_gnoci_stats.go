@@ -0,0 +1,189 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// DefaultStatsHistory is the default number of Git manifests described by the
+// gnoci stats action.
+const DefaultStatsHistory = 10
+
+// Stats represents the gnoci stats action.
+type Stats struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// History is the number of most recent Git manifests to describe,
+	// defaulting to [DefaultStatsHistory].
+	History int
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// RepoStats is the JSON output of the gnoci stats action.
+type RepoStats struct {
+	OutputMeta
+
+	// Manifest is the digest of the Git manifest.
+	Manifest digest.Digest `json:"manifest"`
+	// Size is the total size of the packfile layers and LFS files in bytes.
+	Size int64 `json:"size"`
+	// PackSize is the total size of the packfile layers in bytes.
+	PackSize int64 `json:"packSize"`
+	// Refs is the number of heads, tags, and notes.
+	Refs int `json:"refs"`
+	// Commits is the number of commits recorded by the packfile layers, -1 if
+	// any layer does not record its commits.
+	Commits int `json:"commits"`
+	// Layers describes each packfile layer, oldest first.
+	Layers []LayerInfo `json:"layers"`
+	// LFSFiles is the number of LFS files.
+	LFSFiles int `json:"lfsFiles"`
+	// LFSSize is the total size of the LFS files in bytes.
+	LFSSize int64 `json:"lfsSize"`
+	// History describes the most recent Git manifests, oldest first, ending
+	// with this one.
+	History []model.ManifestStats `json:"history"`
+}
+
+// Run computes statistics of the remote repository: its size, references,
+// commits, packfile layers, LFS files, and growth across recent pushes.
+func (action *Stats) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+	n := action.History
+	if n < 1 {
+		n = DefaultStatsHistory
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	manDesc, err := remote.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+	if _, err := remote.FetchLFS(ctx); err != nil && !errors.Is(err, model.ErrLFSManifestNotFound) {
+		return fmt.Errorf("fetching LFS metadata: %w", err)
+	}
+	history, err := remote.History(ctx, n)
+	if err != nil {
+		return fmt.Errorf("resolving history: %w", err)
+	}
+
+	stats := repoStats(remote, history)
+	stats.Manifest = manDesc.Digest
+	if output == OutputJSON {
+		return writeJSON(out, stats)
+	}
+	return writeRepoStats(out, stats)
+}
+
+// repoStats computes the statistics of a fetched remote.
+func repoStats(remote model.ReadOnlyLFSModeler, history []model.ManifestStats) RepoStats {
+	stats := RepoStats{
+		OutputMeta: outputMeta("RepoStats"),
+		Refs:       len(remote.HeadRefs()) + len(remote.TagRefs()) + len(remote.NoteRefs()),
+		Layers:     layerInfos(remote.Layers()),
+		History:    history,
+	}
+	for _, l := range stats.Layers {
+		stats.PackSize += l.Size
+		switch {
+		case stats.Commits < 0:
+		case l.Commits < 0:
+			stats.Commits = -1
+		default:
+			stats.Commits += l.Commits
+		}
+	}
+	for _, obj := range remote.ListLFSObjects() {
+		stats.LFSFiles++
+		stats.LFSSize += obj.Size
+	}
+	stats.Size = stats.PackSize + stats.LFSSize
+	if stats.History == nil {
+		stats.History = []model.ManifestStats{}
+	}
+
+	return stats
+}
+
+// writeRepoStats writes a summary of stats, followed by tables of its
+// packfile layers and history. Growth is the change in packfile size from the
+// previous Git manifest.
+func writeRepoStats(out io.Writer, stats RepoStats) error {
+	commits := "-"
+	if stats.Commits >= 0 {
+		commits = strconv.Itoa(stats.Commits)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	fields := []struct {
+		name, value string
+	}{
+		{"Manifest:", stats.Manifest.String()},
+		{"Size:", fmt.Sprintf("%d bytes", stats.Size)},
+		{"Packfile Size:", fmt.Sprintf("%d bytes in %d layers", stats.PackSize, len(stats.Layers))},
+		{"LFS Size:", fmt.Sprintf("%d bytes in %d files", stats.LFSSize, stats.LFSFiles)},
+		{"References:", strconv.Itoa(stats.Refs)},
+		{"Commits:", commits},
+	}
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", f.name, f.value); err != nil {
+			return fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(out); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if err := writeLayers(out, stats.Layers); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(out); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	tw = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "MANIFEST\tCREATED\tLAYERS\tSIZE\tGROWTH"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	for i, h := range stats.History {
+		growth := "-"
+		if i > 0 {
+			growth = fmt.Sprintf("%+d", h.Size-stats.History[i-1].Size)
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", h.Digest, orDash(h.Created), h.Layers, h.Size, growth); err != nil {
+			return fmt.Errorf("writing manifest %s: %w", h.Digest, err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/apis"
+)
+
+func TestStats_Run(t *testing.T) {
+	skipUnlessSHA1(t)
+
+	srcDir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	_, err = builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+
+	base := &Gnoci{apiScheme: apis.NewScheme()}
+	address := "oci+layout://" + filepath.Join(t.TempDir(), "repo") + ":sync"
+	err = (&Mirror{Gnoci: base, Source: srcDir, Address: address}).Run(t.Context(), new(bytes.Buffer))
+	assert.NoError(t, err)
+
+	t.Run("JSON", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&Stats{Gnoci: base, Address: address, Output: OutputJSON}).Run(t.Context(), out)
+		assert.NoError(t, err)
+
+		var stats RepoStats
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &stats))
+		assert.Equal(t, outputMeta("RepoStats"), stats.OutputMeta)
+		assert.Equal(t, 1, stats.Refs)
+		assert.Equal(t, 1, stats.Commits)
+		assert.Len(t, stats.Layers, 1)
+		assert.Equal(t, stats.Layers[0].Size, stats.PackSize)
+		assert.Equal(t, stats.PackSize, stats.Size)
+		assert.Zero(t, stats.LFSFiles)
+		assert.Len(t, stats.History, 1)
+		assert.Equal(t, stats.Manifest, stats.History[0].Digest)
+		assert.Equal(t, stats.PackSize, stats.History[0].Size)
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := (&Stats{Gnoci: base, Address: address}).Run(t.Context(), out)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "References:    1\n")
+		assert.Contains(t, out.String(), "Commits:       1\n")
+		assert.Contains(t, out.String(), "LFS Size:      0 bytes in 0 files\n")
+		assert.Contains(t, out.String(), "MANIFEST")
+	})
+}
@@ -0,0 +1,219 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// gitmodulesPath is the path of the file declaring the submodules of a tree.
+const gitmodulesPath = ".gitmodules"
+
+// SubmoduleResolve represents the gnoci submodule resolve action.
+type SubmoduleResolve struct {
+	*Gnoci
+
+	// Address is the oci:// reference of the remote repository.
+	Address string
+	// Revision is the branch, tag, full reference name, or commit hash whose
+	// submodules are resolved. Defaults to the default branch.
+	Revision string
+	// Output is the output format, one of [OutputTable] or [OutputJSON].
+	Output string
+}
+
+// Submodule is a submodule of a revision, resolved by the submodule URL
+// mappings of the repository metadata.
+type Submodule struct {
+	// Name is the name of the submodule in .gitmodules.
+	Name string `json:"name"`
+	// Path is the path of the submodule within the tree.
+	Path string `json:"path"`
+	// Commit is the commit of the submodule recorded in the tree, empty if
+	// the tree has no submodule at Path.
+	Commit string `json:"commit,omitempty"`
+	// URL is the URL of the submodule in .gitmodules.
+	URL string `json:"url"`
+	// Resolved is URL rewritten by the submodule URL mappings.
+	Resolved string `json:"resolved"`
+}
+
+// Run writes the submodules of a remote revision, with their URLs rewritten
+// by the submodule URL mappings of the repository metadata. Only the packfile
+// layers needed to materialize the tree of the revision are fetched.
+func (action *SubmoduleResolve) Run(ctx context.Context, out io.Writer) error {
+	output, err := outputFormat(action.Output)
+	if err != nil {
+		return err
+	}
+
+	remote, cleanup, err := action.readOnlyRemote(ctx, action.Address, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			slog.ErrorContext(ctx, "cleaning up temporary files", slog.String("error", err.Error()))
+		}
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	rev := action.Revision
+	if rev == "" {
+		head := remote.DefaultBranch()
+		if head == "" {
+			return fmt.Errorf("%w: remote repository has no default branch, specify a revision", model.ErrReferenceNotFound)
+		}
+		rev = head.String()
+	}
+
+	commit, err := fetchRevision(ctx, remote, rev)
+	if err != nil {
+		return err
+	}
+
+	meta, err := remote.FetchMetadata(ctx)
+	if err != nil && !errors.Is(err, model.ErrMetadataNotFound) {
+		return fmt.Errorf("fetching repository metadata: %w", err)
+	}
+
+	submodules, err := readSubmodules(commit, meta)
+	if err != nil {
+		return err
+	}
+
+	if output == OutputJSON {
+		return writeSubmodulesJSON(out, submodules)
+	}
+	return writeSubmodules(out, submodules)
+}
+
+// readSubmodules reads the submodules declared by the .gitmodules file of the
+// tree of commit, sorted by path, resolving their URLs by meta. Returns nil if
+// the tree has no .gitmodules file.
+func readSubmodules(commit *object.Commit, meta oci.Metadata) ([]Submodule, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("resolving tree of commit %s: %w", commit.Hash, err)
+	}
+
+	f, err := tree.File(gitmodulesPath)
+	switch {
+	case errors.Is(err, object.ErrFileNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("resolving %s: %w", gitmodulesPath, err)
+	}
+	contents, err := f.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", gitmodulesPath, err)
+	}
+
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(contents)); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", gitmodulesPath, err)
+	}
+
+	submodules := make([]Submodule, 0, len(modules.Submodules))
+	for _, sub := range modules.Submodules {
+		s := Submodule{
+			Name:     sub.Name,
+			Path:     sub.Path,
+			URL:      sub.URL,
+			Resolved: meta.SubmoduleURL(sub.URL),
+		}
+		entry, err := tree.FindEntry(sub.Path)
+		switch {
+		case errors.Is(err, object.ErrEntryNotFound), errors.Is(err, object.ErrDirectoryNotFound):
+		case err != nil:
+			return nil, fmt.Errorf("resolving submodule %s: %w", sub.Name, err)
+		case entry.Mode == filemode.Submodule:
+			s.Commit = entry.Hash.String()
+		}
+		submodules = append(submodules, s)
+	}
+	slices.SortFunc(submodules, func(a, b Submodule) int { return strings.Compare(a.Path, b.Path) })
+
+	return submodules, nil
+}
+
+// writeSubmodules writes a table of submodules.
+func writeSubmodules(out io.Writer, submodules []Submodule) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "PATH\tCOMMIT\tURL"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, s := range submodules {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Path, orDash(s.Commit), s.Resolved); err != nil {
+			return fmt.Errorf("writing submodule %s: %w", s.Name, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeSubmodulesJSON writes submodules as a JSON SubmoduleList document.
+func writeSubmodulesJSON(out io.Writer, submodules []Submodule) error {
+	return writeJSONList(out, "SubmoduleList", submodules)
+}
+
+// setSubmoduleURLs records the submodule URL mappings of meta in the
+// configuration of local as url.<base>.insteadOf, such that Git clones
+// submodules from the mapped remotes. Returns the number of mappings written.
+// Mappings whose base already rewrites another prefix are skipped, as a base
+// holds a single prefix.
+func setSubmoduleURLs(ctx context.Context, local git.Repository, meta oci.Metadata) (int, error) {
+	if len(meta.SubmoduleURLs) == 0 {
+		return 0, nil
+	}
+
+	cfg, err := local.Config()
+	if err != nil {
+		return 0, fmt.Errorf("reading repository configuration: %w", err)
+	}
+
+	var n int
+	for _, prefix := range slices.Sorted(maps.Keys(meta.SubmoduleURLs)) {
+		base := meta.SubmoduleURLs[prefix]
+		if existing, ok := cfg.URLs[base]; ok {
+			if existing.InsteadOf != prefix {
+				slog.WarnContext(ctx, "skipping submodule URL mapping, base already rewrites another URL",
+					slog.String("base", base), slog.String("insteadOf", existing.InsteadOf), slog.String("skipped", prefix))
+			}
+			continue
+		}
+		cfg.URLs[base] = &config.URL{Name: base, InsteadOf: prefix}
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	if err := local.SetConfig(cfg); err != nil {
+		return 0, fmt.Errorf("writing repository configuration: %w", err)
+	}
+
+	return n, nil
+}
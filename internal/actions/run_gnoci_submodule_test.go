@@ -0,0 +1,133 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_readSubmodules(t *testing.T) {
+	gitlink := plumbing.NewHash("a94a8fe5ccb19ba61c4c0873d391e987982fbbd3")
+	meta := oci.Metadata{SubmoduleURLs: map[string]string{
+		"https://github.com/pasta/":       "oci://example.com/pasta/",
+		"https://github.com/pasta/sauce/": "oci://example.com/sauce/",
+	}}
+
+	// commit creates a commit of a tree with .gitmodules holding gitmodules,
+	// and a gitlink at lib/sauce
+	commit := func(t *testing.T, gitmodules string) *object.Commit {
+		t.Helper()
+
+		st := memory.NewStorage()
+		lib := storeObject(t, st, &object.Tree{Entries: []object.TreeEntry{
+			{Name: "sauce", Mode: filemode.Submodule, Hash: gitlink},
+		}})
+		entries := []object.TreeEntry{{Name: "lib", Mode: filemode.Dir, Hash: lib}}
+		if gitmodules != "" {
+			entries = append([]object.TreeEntry{{Name: ".gitmodules", Mode: filemode.Regular, Hash: storeBlob(t, st, gitmodules)}}, entries...)
+		}
+		tree := storeObject(t, st, &object.Tree{Entries: entries})
+
+		sig := object.Signature{Name: "test", Email: "test@example.com"}
+		hash := storeObject(t, st, &object.Commit{Author: sig, Committer: sig, Message: "test", TreeHash: tree})
+		c, err := object.GetCommit(st, hash)
+		assert.NoError(t, err)
+		return c
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		gitmodules := `[submodule "sauce"]
+	path = lib/sauce
+	url = https://github.com/pasta/sauce/tomato.git
+[submodule "cheese"]
+	path = lib/cheese
+	url = https://github.com/pasta/cheese.git
+[submodule "herbs"]
+	path = herbs
+	url = ../herbs.git
+`
+		submodules, err := readSubmodules(commit(t, gitmodules), meta)
+		assert.NoError(t, err)
+		assert.Equal(t, []Submodule{
+			{Name: "herbs", Path: "herbs", URL: "../herbs.git", Resolved: "../herbs.git"},
+			{Name: "cheese", Path: "lib/cheese", URL: "https://github.com/pasta/cheese.git", Resolved: "oci://example.com/pasta/cheese.git"},
+			{Name: "sauce", Path: "lib/sauce", Commit: gitlink.String(), URL: "https://github.com/pasta/sauce/tomato.git", Resolved: "oci://example.com/sauce/tomato.git"},
+		}, submodules)
+	})
+
+	t.Run("No Submodules", func(t *testing.T) {
+		submodules, err := readSubmodules(commit(t, ""), meta)
+		assert.NoError(t, err)
+		assert.Nil(t, submodules)
+	})
+}
+
+func Test_writeSubmodules(t *testing.T) {
+	submodules := []Submodule{
+		{Name: "cheese", Path: "lib/cheese", URL: "https://github.com/pasta/cheese.git", Resolved: "oci://example.com/pasta/cheese.git"},
+		{Name: "sauce", Path: "lib/sauce", Commit: "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3", URL: "../sauce.git", Resolved: "../sauce.git"},
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		assert.NoError(t, writeSubmodules(out, submodules))
+		assert.Equal(t, "PATH         COMMIT                                     URL\n"+
+			"lib/cheese   -                                          oci://example.com/pasta/cheese.git\n"+
+			"lib/sauce    a94a8fe5ccb19ba61c4c0873d391e987982fbbd3   ../sauce.git\n", out.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		assert.NoError(t, writeSubmodulesJSON(out, submodules[1:]))
+		assert.JSONEq(t, `{
+			"apiVersion": "gnoci.act3-ai.io/output/v1",
+			"kind": "SubmoduleList",
+			"items": [{
+				"name": "sauce",
+				"path": "lib/sauce",
+				"commit": "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3",
+				"url": "../sauce.git",
+				"resolved": "../sauce.git"
+			}]
+		}`, out.String())
+	})
+}
+
+func Test_setSubmoduleURLs(t *testing.T) {
+	r, err := gogit.Init(memory.NewStorage(), nil)
+	assert.NoError(t, err)
+	local := git.NewRepository(r)
+
+	cfg, err := local.Config()
+	assert.NoError(t, err)
+	cfg.URLs["oci://example.com/cheese/"] = &config.URL{Name: "oci://example.com/cheese/", InsteadOf: "git@github.com:cheese/"}
+	assert.NoError(t, local.SetConfig(cfg))
+
+	meta := oci.Metadata{SubmoduleURLs: map[string]string{
+		"https://github.com/pasta/":  "oci://example.com/pasta/",
+		"https://github.com/cheese/": "oci://example.com/cheese/",
+	}}
+	n, err := setSubmoduleURLs(t.Context(), local, meta)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	cfg, err = local.Config()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/pasta/", cfg.URLs["oci://example.com/pasta/"].InsteadOf)
+	assert.Equal(t, "git@github.com:cheese/", cfg.URLs["oci://example.com/cheese/"].InsteadOf)
+
+	// mappings already written are not written again
+	n, err = setSubmoduleURLs(t.Context(), local, meta)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/act3-ai/gnoci/pkg/apis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGnoci(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		version := "v1.0.0"
+		cfgFiles := []string{"/tmp/foo"}
+
+		gotGnoci := NewGnoci(version, cfgFiles)
+		assert.Equal(t, version, gotGnoci.version)
+		assert.NotNil(t, gotGnoci.apiScheme)
+		assert.Equal(t, cfgFiles, gotGnoci.ConfigFiles)
+	})
+}
+
+func TestGnoci_GetConfig(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		action := &Gnoci{
+			apiScheme: apis.NewScheme(),
+		}
+
+		cfg, err := action.GetConfig(t.Context())
+		assert.NoError(t, err)
+		assert.NotNil(t, cfg)
+	})
+}
+
+func TestGnoci_remote(t *testing.T) {
+	t.Run("Invalid Reference", func(t *testing.T) {
+		action := &Gnoci{
+			apiScheme: apis.NewScheme(),
+		}
+
+		_, _, err := action.remote(t.Context(), "oci://example.com/foo:bar:baz", true)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,132 @@
+// Package bundle reads and writes the header of Git bundles, a list of
+// references followed by a packfile of their objects. See
+// https://git-scm.com/docs/gitformat-bundle.
+package bundle
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// ErrInvalidBundle indicates a Git bundle header is malformed or uses an
+// unsupported version or capability.
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+const (
+	signatureV2 = "# v2 git bundle"
+	signatureV3 = "# v3 git bundle"
+
+	capabilityObjectFormat = "object-format"
+)
+
+// Header is the header of a Git bundle.
+type Header struct {
+	// ObjectFormat is the hash algorithm of the bundled objects, SHA-1 if
+	// empty.
+	ObjectFormat formatcfg.ObjectFormat
+	// Prerequisites are the commits the packfile depends on, which are not
+	// bundled.
+	Prerequisites []plumbing.Hash
+	// References are the bundled references, in order. A HEAD reference
+	// records the commit checked out when cloning the bundle.
+	References []*plumbing.Reference
+}
+
+// Encode writes the header to w, in the version 2 format unless the objects
+// are not SHA-1.
+func (h *Header) Encode(w io.Writer) error {
+	var b strings.Builder
+	if h.ObjectFormat == "" || h.ObjectFormat == formatcfg.SHA1 {
+		b.WriteString(signatureV2 + "\n")
+	} else {
+		b.WriteString(signatureV3 + "\n")
+		fmt.Fprintf(&b, "@%s=%s\n", capabilityObjectFormat, h.ObjectFormat)
+	}
+	for _, hash := range h.Prerequisites {
+		fmt.Fprintf(&b, "-%s\n", hash)
+	}
+	for _, ref := range h.References {
+		fmt.Fprintf(&b, "%s %s\n", ref.Hash(), ref.Name())
+	}
+	b.WriteString("\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing bundle header: %w", err)
+	}
+	return nil
+}
+
+// Decode reads a bundle header from r, leaving r at the start of the
+// packfile. Prerequisite comments are discarded.
+func Decode(r *bufio.Reader) (*Header, error) {
+	signature, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if signature != signatureV2 && signature != signatureV3 {
+		return nil, fmt.Errorf("%w: unsupported signature %q", ErrInvalidBundle, signature)
+	}
+
+	h := &Header{}
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case line == "":
+			return h, nil
+		case strings.HasPrefix(line, "@"):
+			if signature != signatureV3 {
+				return nil, fmt.Errorf("%w: capability %q in version 2 bundle", ErrInvalidBundle, line)
+			}
+			if err := h.setCapability(line[1:]); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "-"):
+			hex, _, _ := strings.Cut(line[1:], " ")
+			if !plumbing.IsHash(hex) {
+				return nil, fmt.Errorf("%w: invalid prerequisite %q", ErrInvalidBundle, line)
+			}
+			h.Prerequisites = append(h.Prerequisites, plumbing.NewHash(hex))
+		default:
+			hex, name, ok := strings.Cut(line, " ")
+			if !ok || !plumbing.IsHash(hex) || name == "" {
+				return nil, fmt.Errorf("%w: invalid reference %q", ErrInvalidBundle, line)
+			}
+			h.References = append(h.References, plumbing.NewHashReference(plumbing.ReferenceName(name), plumbing.NewHash(hex)))
+		}
+	}
+}
+
+// setCapability records a version 3 capability, "key" or "key=value".
+func (h *Header) setCapability(capability string) error {
+	key, value, _ := strings.Cut(capability, "=")
+	switch key {
+	case capabilityObjectFormat:
+		h.ObjectFormat = formatcfg.ObjectFormat(value)
+		return nil
+	default:
+		// capabilities alter how the bundle is read, unknown ones cannot be
+		// ignored
+		return fmt.Errorf("%w: unsupported capability %q", ErrInvalidBundle, key)
+	}
+}
+
+// readLine reads a line of the header, without its line feed.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	switch {
+	case errors.Is(err, io.EOF):
+		return "", fmt.Errorf("%w: truncated header", ErrInvalidBundle)
+	case err != nil:
+		return "", fmt.Errorf("reading bundle header: %w", err)
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
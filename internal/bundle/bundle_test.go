@@ -0,0 +1,115 @@
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader_Encode(t *testing.T) {
+	commit := plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc")
+	parent := plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74")
+
+	t.Run("Version 2", func(t *testing.T) {
+		h := &Header{
+			Prerequisites: []plumbing.Hash{parent},
+			References: []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.HEAD, commit),
+				plumbing.NewHashReference(plumbing.Main, commit),
+			},
+		}
+		buf := new(bytes.Buffer)
+		assert.NoError(t, h.Encode(buf))
+		assert.Equal(t, "# v2 git bundle\n"+
+			"-"+parent.String()+"\n"+
+			commit.String()+" HEAD\n"+
+			commit.String()+" refs/heads/main\n"+
+			"\n", buf.String())
+	})
+
+	t.Run("Version 3", func(t *testing.T) {
+		h := &Header{ObjectFormat: formatcfg.SHA256}
+		buf := new(bytes.Buffer)
+		assert.NoError(t, h.Encode(buf))
+		assert.Equal(t, "# v3 git bundle\n@object-format=sha256\n\n", buf.String())
+	})
+}
+
+func TestDecode(t *testing.T) {
+	commit := plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc")
+	parent := plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74")
+
+	tests := []struct {
+		name    string
+		header  string
+		want    *Header
+		wantErr error
+	}{
+		{
+			name: "Version 2",
+			header: "# v2 git bundle\n" +
+				"-" + parent.String() + " Parent commit subject\n" +
+				commit.String() + " refs/heads/main\n" +
+				"\n",
+			want: &Header{
+				Prerequisites: []plumbing.Hash{parent},
+				References:    []*plumbing.Reference{plumbing.NewHashReference(plumbing.Main, commit)},
+			},
+		},
+		{
+			name: "Version 3",
+			header: "# v3 git bundle\n" +
+				"@object-format=sha1\n" +
+				commit.String() + " refs/heads/main\n" +
+				"\n",
+			want: &Header{
+				ObjectFormat: formatcfg.SHA1,
+				References:   []*plumbing.Reference{plumbing.NewHashReference(plumbing.Main, commit)},
+			},
+		},
+		{
+			name:    "Unsupported Capability",
+			header:  "# v3 git bundle\n@filter=blob:none\n\n",
+			wantErr: ErrInvalidBundle,
+		},
+		{
+			name:    "Capability In Version 2",
+			header:  "# v2 git bundle\n@object-format=sha1\n\n",
+			wantErr: ErrInvalidBundle,
+		},
+		{
+			name:    "Unsupported Signature",
+			header:  "# v4 git bundle\n\n",
+			wantErr: ErrInvalidBundle,
+		},
+		{
+			name:    "Invalid Reference",
+			header:  "# v2 git bundle\n9f9daae4 refs/heads/main\n\n",
+			wantErr: ErrInvalidBundle,
+		},
+		{
+			name:    "Truncated",
+			header:  "# v2 git bundle\n" + commit.String() + " refs/heads/main\n",
+			wantErr: ErrInvalidBundle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.header + "PACK"))
+			got, err := Decode(r)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr == nil {
+				rest, err := io.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Equal(t, "PACK", string(rest), "reader left at the packfile")
+			}
+		})
+	}
+}
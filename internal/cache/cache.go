@@ -0,0 +1,396 @@
+// Package cache implements a local content-addressable cache of blobs fetched
+// from OCI remotes, e.g. packfile layers, shared by all repositories. The
+// cache is bounded in size by evicting the least recently used blobs.
+package cache
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/act3-ai/gnoci/internal/filelock"
+)
+
+// DefaultMaxSize is the default size limit of the cache, 5 GiB.
+const DefaultMaxSize int64 = 5 << 30
+
+const (
+	// blobsDir is the directory, within the cache directory, blobs are stored
+	// in by digest.
+	blobsDir = "blobs"
+	// ingestDir is the directory, within the cache directory, blobs are
+	// written to while being fetched.
+	ingestDir = "ingest"
+	// lockFile is the file, within the cache directory, locked by processes
+	// evicting blobs.
+	lockFile = "lock"
+)
+
+// DefaultDir returns the default cache directory, within the XDG cache
+// directory.
+func DefaultDir() string {
+	return filepath.Join(xdg.CacheHome, "gnoci")
+}
+
+// Cache is a local content-addressable blob cache. It is safe for concurrent
+// use, including by multiple processes.
+type Cache struct {
+	dir     string
+	maxSize int64
+
+	// guards eviction
+	mu sync.Mutex
+}
+
+// New creates a cache in dir, created on first use, holding at most maxSize
+// bytes. A maxSize of zero or less is unlimited.
+func New(dir string, maxSize int64) *Cache {
+	return &Cache{dir: dir, maxSize: maxSize}
+}
+
+// Dir returns the cache directory.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// blobPath returns the path of the blob with digest dgst.
+func (c *Cache) blobPath(dgst digest.Digest) string {
+	return filepath.Join(c.dir, blobsDir, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+// Fetch returns the content of desc from the cache. On a miss, the content is
+// fetched with fetcher and cached once completely read and verified.
+func (c *Cache) Fetch(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	if f, ok := c.open(ctx, desc); ok {
+		return f, nil
+	}
+
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return c.tee(ctx, desc, rc), nil
+}
+
+// Warm fetches desc into the cache with fetcher, unless already cached, in
+// which case it is marked as recently used. Returns true if desc was fetched.
+func (c *Cache) Warm(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (bool, error) {
+	if f, ok := c.open(ctx, desc); ok {
+		return false, f.Close() //nolint:wrapcheck
+	}
+
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return false, err //nolint:wrapcheck
+	}
+	trc := c.tee(ctx, desc, rc)
+	if _, err := io.Copy(io.Discard, trc); err != nil {
+		return false, errors.Join(fmt.Errorf("reading blob %s: %w", desc.Digest, err), trc.Close())
+	}
+	if err := trc.Close(); err != nil {
+		return false, fmt.Errorf("closing blob %s: %w", desc.Digest, err)
+	}
+
+	return true, nil
+}
+
+// open returns the cached blob of desc, if any, marking it as recently used.
+func (c *Cache) open(ctx context.Context, desc ocispec.Descriptor) (*os.File, bool) {
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, false
+	}
+
+	path := c.blobPath(desc.Digest)
+	f, err := os.Open(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		slog.DebugContext(ctx, "blob not cached", slog.String("digest", desc.Digest.String()))
+		return nil, false
+	case err != nil:
+		slog.WarnContext(ctx, "opening cached blob", slog.String("error", err.Error()))
+		return nil, false
+	}
+
+	fi, err := f.Stat()
+	if err != nil || fi.Size() != desc.Size {
+		// truncated, discarded on the next write
+		f.Close()
+		return nil, false
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		slog.DebugContext(ctx, "marking cached blob as used", slog.String("error", err.Error()))
+	}
+
+	slog.DebugContext(ctx, "using cached blob", slog.String("digest", desc.Digest.String()))
+	return f, true
+}
+
+// tee returns rc, writing its content to the cache as it is read.
+func (c *Cache) tee(ctx context.Context, desc ocispec.Descriptor, rc io.ReadCloser) io.ReadCloser {
+	if err := desc.Digest.Validate(); err != nil {
+		return rc
+	}
+
+	ingest := filepath.Join(c.dir, ingestDir)
+	if err := os.MkdirAll(ingest, 0o755); err != nil {
+		slog.WarnContext(ctx, "creating cache directory, not caching", slog.String("error", err.Error()))
+		return rc
+	}
+	f, err := os.CreateTemp(ingest, desc.Digest.Encoded()+"-*")
+	if err != nil {
+		slog.WarnContext(ctx, "creating cache file, not caching", slog.String("error", err.Error()))
+		return rc
+	}
+
+	t := &teeReadCloser{
+		ReadCloser: rc,
+		ctx:        ctx,
+		cache:      c,
+		desc:       desc,
+		f:          f,
+		verifier:   desc.Digest.Verifier(),
+	}
+	if seeker, ok := rc.(io.Seeker); ok {
+		return &teeReadSeekCloser{teeReadCloser: t, seeker: seeker}
+	}
+	return t
+}
+
+// commit moves a completely fetched blob into the cache, then evicts blobs
+// exceeding the size limit.
+func (c *Cache) commit(ctx context.Context, dgst digest.Digest, tmpPath string) error {
+	path := c.blobPath(dgst)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		if !filelock.IsInUse(err) {
+			return fmt.Errorf("moving blob into cache: %w", err)
+		}
+		// cached concurrently, and open in another process on Windows
+		slog.DebugContext(ctx, "blob cached by another process", slog.String("digest", dgst.String()))
+		if err := os.Remove(tmpPath); err != nil {
+			return fmt.Errorf("removing cache file: %w", err)
+		}
+		return nil
+	}
+	slog.DebugContext(ctx, "cached blob", slog.String("digest", dgst.String()))
+
+	if c.maxSize <= 0 {
+		return nil
+	}
+	_, _, err := c.Prune(ctx, c.maxSize)
+	return err
+}
+
+// entry is a cached blob.
+type entry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// entries returns the cached blobs.
+func (c *Cache) entries() ([]entry, error) {
+	var entries []entry
+	err := filepath.WalkDir(filepath.Join(c.dir, blobsDir), func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil
+		case err != nil:
+			return err
+		case d.IsDir():
+			return nil
+		}
+
+		fi, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// evicted concurrently
+			return nil
+		} else if err != nil {
+			return err //nolint:wrapcheck
+		}
+		entries = append(entries, entry{path: path, size: fi.Size(), modTime: fi.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing cached blobs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Size returns the number of cached blobs and their total size in bytes.
+func (c *Cache) Size() (int, int64, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var size int64
+	for _, e := range entries {
+		size += e.size
+	}
+	return len(entries), size, nil
+}
+
+// Prune removes the least recently used blobs until the cache totals at most
+// size bytes, returning the number of blobs removed and the bytes freed. A
+// size of zero empties the cache. Blobs open in other processes on Windows
+// cannot be removed, and are skipped.
+func (c *Cache) Prune(ctx context.Context, size int64) (_ int, _ int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// serialize eviction with other processes
+	unlock, err := filelock.Lock(filepath.Join(c.dir, lockFile))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// nothing cached yet
+		return 0, 0, nil
+	case err != nil:
+		return 0, 0, fmt.Errorf("locking cache: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, unlock())
+	}()
+
+	entries, err := c.entries()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+
+	// oldest first
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Compare(a.modTime.UnixNano(), b.modTime.UnixNano())
+	})
+
+	var removed int
+	var freed int64
+	for _, e := range entries {
+		if total-freed <= size {
+			break
+		}
+		err := os.Remove(e.path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case filelock.IsInUse(err):
+			slog.DebugContext(ctx, "cached blob in use, not evicting", slog.String("path", e.path))
+			continue
+		case err != nil:
+			return removed, freed, fmt.Errorf("evicting cached blob: %w", err)
+		}
+		slog.DebugContext(ctx, "evicted cached blob", slog.String("path", e.path), slog.Int64("size", e.size))
+		removed++
+		freed += e.size
+	}
+
+	return removed, freed, nil
+}
+
+// teeReadCloser writes the content of a fetched blob to a temporary file,
+// which is moved into the cache once the blob is completely read and
+// verified.
+type teeReadCloser struct {
+	io.ReadCloser
+
+	ctx      context.Context //nolint:containedctx
+	cache    *Cache
+	desc     ocispec.Descriptor
+	f        *os.File
+	verifier digest.Verifier
+	n        int64
+}
+
+// Read reads from the fetched blob, writing to the cache file.
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 && t.f != nil {
+		if _, werr := io.MultiWriter(t.f, t.verifier).Write(p[:n]); werr != nil {
+			slog.WarnContext(t.ctx, "writing cache file, not caching", slog.String("error", werr.Error()))
+			t.discard()
+		}
+		t.n += int64(n)
+	}
+	if t.f != nil && t.n == t.desc.Size {
+		// readers may stop short of EOF, e.g. the packfile parser
+		t.finish()
+	}
+	return n, err //nolint:wrapcheck
+}
+
+// Close closes the fetched blob, discarding the cache file if incomplete.
+func (t *teeReadCloser) Close() error {
+	t.discard()
+	return t.ReadCloser.Close() //nolint:wrapcheck
+}
+
+// finish moves the cache file into the cache, if verified.
+func (t *teeReadCloser) finish() {
+	if !t.verifier.Verified() {
+		slog.WarnContext(t.ctx, "fetched blob does not match its digest, not caching", slog.String("digest", t.desc.Digest.String()))
+		t.discard()
+		return
+	}
+
+	tmpPath := t.f.Name()
+	err := t.f.Close()
+	t.f = nil
+	if err == nil {
+		err = t.cache.commit(t.ctx, t.desc.Digest, tmpPath)
+	}
+	if err != nil {
+		slog.WarnContext(t.ctx, "caching blob", slog.String("error", err.Error()))
+		if err := os.Remove(tmpPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.WarnContext(t.ctx, "removing cache file", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// discard removes the cache file, if not yet moved into the cache.
+func (t *teeReadCloser) discard() {
+	if t.f == nil {
+		return
+	}
+	t.f.Close()
+	if err := os.Remove(t.f.Name()); err != nil {
+		slog.WarnContext(t.ctx, "removing cache file", slog.String("error", err.Error()))
+	}
+	t.f = nil
+}
+
+// teeReadSeekCloser is a [teeReadCloser] of a seekable blob, e.g. one fetched
+// from a registry supporting range requests. Seeking stops caching, as the
+// blob is no longer read in full.
+type teeReadSeekCloser struct {
+	*teeReadCloser
+	seeker io.Seeker
+}
+
+// Seek seeks the fetched blob, discarding the cache file.
+func (t *teeReadSeekCloser) Seek(offset int64, whence int) (int64, error) {
+	t.discard()
+	return t.seeker.Seek(offset, whence) //nolint:wrapcheck
+}
@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCache_Fetch(t *testing.T) {
+	blob := []byte("packfile layer")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+
+	t.Run("Miss Then Hit", func(t *testing.T) {
+		c := New(t.TempDir(), 0)
+		remote := memory.New()
+		assert.NoError(t, remote.Push(t.Context(), desc, bytes.NewReader(blob)))
+
+		rc, err := c.Fetch(t.Context(), remote, desc)
+		assert.NoError(t, err)
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.Equal(t, blob, got)
+		assert.FileExists(t, c.blobPath(desc.Digest))
+
+		// fetched from the cache, the remote is empty
+		rc, err = c.Fetch(t.Context(), memory.New(), desc)
+		assert.NoError(t, err)
+		got, err = io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.Equal(t, blob, got)
+	})
+
+	t.Run("Partial Read", func(t *testing.T) {
+		c := New(t.TempDir(), 0)
+		remote := memory.New()
+		assert.NoError(t, remote.Push(t.Context(), desc, bytes.NewReader(blob)))
+
+		rc, err := c.Fetch(t.Context(), remote, desc)
+		assert.NoError(t, err)
+		_, err = rc.Read(make([]byte, 4))
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.NoFileExists(t, c.blobPath(desc.Digest))
+
+		entries, err := os.ReadDir(filepath.Join(c.Dir(), ingestDir))
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Digest Mismatch", func(t *testing.T) {
+		c := New(t.TempDir(), 0)
+		rc := c.tee(t.Context(), desc, io.NopCloser(bytes.NewReader([]byte("corrupt layer!"))))
+		_, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.NoFileExists(t, c.blobPath(desc.Digest))
+	})
+
+	t.Run("Seek", func(t *testing.T) {
+		c := New(t.TempDir(), 0)
+		rc := c.tee(t.Context(), desc, &nopSeekCloser{bytes.NewReader(blob)})
+		seeker, ok := rc.(io.Seeker)
+		assert.True(t, ok)
+		_, err := seeker.Seek(4, io.SeekStart)
+		assert.NoError(t, err)
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.Equal(t, blob[4:], got)
+		assert.NoFileExists(t, c.blobPath(desc.Digest))
+	})
+}
+
+func TestCache_Warm(t *testing.T) {
+	blob := []byte("packfile layer")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	c := New(t.TempDir(), 0)
+	remote := memory.New()
+	assert.NoError(t, remote.Push(t.Context(), desc, bytes.NewReader(blob)))
+
+	fetched, err := c.Warm(t.Context(), remote, desc)
+	assert.NoError(t, err)
+	assert.True(t, fetched)
+	assert.FileExists(t, c.blobPath(desc.Digest))
+
+	// already cached, the remote is empty
+	fetched, err = c.Warm(t.Context(), memory.New(), desc)
+	assert.NoError(t, err)
+	assert.False(t, fetched)
+
+	_, err = New(t.TempDir(), 0).Warm(t.Context(), memory.New(), desc)
+	assert.Error(t, err)
+}
+
+func TestCache_Prune(t *testing.T) {
+	c := New(t.TempDir(), 0)
+	remote := memory.New()
+
+	// cached oldest to newest, each 8 bytes
+	var descs []ocispec.Descriptor
+	for i, blob := range []string{"blob one", "blob two", "blob 3!!"} {
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte(blob))
+		assert.NoError(t, remote.Push(t.Context(), desc, bytes.NewReader([]byte(blob))))
+		rc, err := c.Fetch(t.Context(), remote, desc)
+		assert.NoError(t, err)
+		_, err = io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+
+		modTime := time.Now().Add(time.Duration(i-3) * time.Hour)
+		assert.NoError(t, os.Chtimes(c.blobPath(desc.Digest), modTime, modTime))
+		descs = append(descs, desc)
+	}
+
+	count, size, err := c.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, int64(24), size)
+
+	removed, freed, err := c.Prune(t.Context(), 16)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, int64(8), freed)
+	assert.NoFileExists(t, c.blobPath(descs[0].Digest))
+	assert.FileExists(t, c.blobPath(descs[1].Digest))
+
+	removed, freed, err = c.Prune(t.Context(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, int64(16), freed)
+
+	count, _, err = c.Size()
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	t.Run("Not Created", func(t *testing.T) {
+		c := New(filepath.Join(t.TempDir(), "missing"), 0)
+		removed, freed, err := c.Prune(t.Context(), 0)
+		assert.NoError(t, err)
+		assert.Zero(t, removed)
+		assert.Zero(t, freed)
+		assert.NoDirExists(t, c.Dir())
+	})
+}
+
+func TestCache_commit(t *testing.T) {
+	t.Run("Evicts Least Recently Used", func(t *testing.T) {
+		c := New(t.TempDir(), 10)
+		remote := memory.New()
+
+		first := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("blob one"))
+		second := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("blob two"))
+		for _, blob := range []string{"blob one", "blob two"} {
+			desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte(blob))
+			assert.NoError(t, remote.Push(t.Context(), desc, bytes.NewReader([]byte(blob))))
+			rc, err := c.Fetch(t.Context(), remote, desc)
+			assert.NoError(t, err)
+			_, err = io.ReadAll(rc)
+			assert.NoError(t, err)
+			assert.NoError(t, rc.Close())
+
+			if desc.Digest == first.Digest {
+				modTime := time.Now().Add(-time.Hour)
+				assert.NoError(t, os.Chtimes(c.blobPath(desc.Digest), modTime, modTime))
+			}
+		}
+
+		assert.NoFileExists(t, c.blobPath(first.Digest))
+		assert.FileExists(t, c.blobPath(second.Digest))
+	})
+}
+
+// nopSeekCloser is a seekable blob, e.g. one supporting range requests.
+type nopSeekCloser struct {
+	*bytes.Reader
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
@@ -2,16 +2,21 @@
 package cli
 
 import (
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/act3-ai/gnoci/internal/actions"
+	"github.com/act3-ai/gnoci/internal/git"
 	"github.com/act3-ai/go-common/pkg/config"
 )
 
 // NewGitCLI creates the base git-remote-oci command.
 func NewGitCLI(version string) *cobra.Command {
+	// adjusted by Git's verbosity option
+	level := new(slog.LevelVar)
+
 	// cmd represents the base command when called without any subcommands
 	cmd := &cobra.Command{
 		Use:          "git-remote-oci REPOSITORY [URL]",
@@ -29,15 +34,18 @@ func NewGitCLI(version string) *cobra.Command {
 			action := actions.NewGit(
 				cmd.InOrStdin(),
 				cmd.OutOrStdout(),
-				os.Getenv("GIT_DIR"),
+				cmd.ErrOrStderr(),
+				os.Getenv(git.EnvGitDir),
 				name,
 				address,
 				version,
 				config.EnvPathOr("GNOCI_CONFIG", config.DefaultConfigSearchPath("gnoci", "config.yaml")),
 			)
+			action.Level = level
 			return action.Run(cmd.Context())
 		},
 	}
+	addLogFormatFlag(cmd, level)
 
 	return cmd
 }
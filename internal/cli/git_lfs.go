@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"log/slog"
+	"os"
+
 	"github.com/act3-ai/gnoci/internal/actions"
 	"github.com/act3-ai/go-common/pkg/config"
 	"github.com/spf13/cobra"
@@ -8,6 +11,9 @@ import (
 
 // NewGitLFSCLI creates the base git-lfs-remote-oci command.
 func NewGitLFSCLI(version string) *cobra.Command {
+	// git-lfs does not forward Git's verbosity, follow its GIT_TRACE instead
+	level := new(slog.LevelVar)
+
 	// cmd represents the base command when called without any subcommands
 	cmd := &cobra.Command{
 		Use:          "git-lfs-remote-oci",
@@ -15,6 +21,10 @@ func NewGitLFSCLI(version string) *cobra.Command {
 		SilenceUsage: true,
 		Args:         cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if gitTraceEnabled(os.Getenv("GIT_TRACE")) {
+				level.Set(min(level.Level(), slog.LevelDebug))
+			}
+
 			action := actions.NewGitLFS(
 				cmd.InOrStdin(),
 				cmd.OutOrStdout(),
@@ -24,6 +34,7 @@ func NewGitLFSCLI(version string) *cobra.Command {
 			return action.Run(cmd.Context())
 		},
 	}
+	addLogFormatFlag(cmd, level)
 
 	return cmd
 }
@@ -0,0 +1,834 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/act3-ai/gnoci/internal/actions"
+	"github.com/act3-ai/go-common/pkg/config"
+)
+
+// NewGnociCLI creates the base gnoci command.
+func NewGnociCLI(version string) *cobra.Command {
+	action := actions.NewGnoci(
+		version,
+		config.EnvPathOr("GNOCI_CONFIG", config.DefaultConfigSearchPath("gnoci", "config.yaml")),
+	)
+
+	// cmd represents the base command when called without any subcommands
+	cmd := &cobra.Command{
+		Use:          "gnoci",
+		Short:        "Administer Git repositories stored in OCI Registries.",
+		SilenceUsage: true,
+	}
+
+	cmd.AddCommand(
+		newListCmd(action),
+		newGCCmd(action),
+		newDeleteCmd(action),
+		newFsckCmd(action),
+		newLayersCmd(action),
+		newStatsCmd(action),
+		newDescribeCmd(action),
+		newSignCmd(action),
+		newVerifyCmd(action),
+		newExportCmd(action),
+		newReposCmd(action),
+		newProjectsCmd(action),
+		newMirrorCmd(action),
+		newRestoreCmd(action),
+		newBundleCmd(action),
+		newSubmoduleCmd(action),
+		newCacheCmd(action),
+		newPrefetchCmd(action),
+		newReleaseCmd(action),
+		newLFSCmd(action),
+	)
+	addLogFormatFlag(cmd, nil)
+
+	return cmd
+}
+
+// newListCmd creates the gnoci ls command.
+func newListCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.List{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "ls REFERENCE [PATTERN...]",
+		Short: "List the references of a Git repository stored in an OCI Registry.",
+		Long: `List the references of a Git repository stored in an OCI Registry.
+
+Only the Git config of the repository is fetched. If patterns are given, only
+references matching at least one pattern are listed. As with git ls-remote, each
+pattern is a glob matched against the tail of the reference name, starting at the
+beginning of the name or after a slash.`,
+		Example: `  # list the heads and tags of a remote repository
+  gnoci ls oci://example.com/repo/test:sync
+
+  # list the v1 tags of a remote repository
+  gnoci ls oci://example.com/repo/test:sync 'refs/tags/v1.*'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			action.Patterns = args[1:]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newGCCmd creates the gnoci gc command.
+func newGCCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.GC{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "gc REFERENCE",
+		Short: "Consolidate the packfile layers of a Git repository stored in an OCI Registry.",
+		Long: `Consolidate the packfile layers of a Git repository stored in an OCI Registry.
+
+All packfile layers are rebuilt into a single packfile containing only the objects
+reachable from the current heads and tags. Superseded layers are deleted if the
+registry supports deletion, take care if layers are shared with other tags in the
+same repository.`,
+		Example: `  # consolidate the packfile layers of a remote repository
+  gnoci gc oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+// newDeleteCmd creates the gnoci delete command.
+func newDeleteCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Delete{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "delete REFERENCE",
+		Short: "Delete a Git repository stored in an OCI Registry.",
+		Long: `Delete a Git repository stored in an OCI Registry.
+
+The tagged Git manifest is deleted along with its config, packfile layers, and
+referrers, e.g. the LFS manifest and its LFS files, signatures, and repository
+metadata, provided the registry supports deletion. Each referrer is deleted before
+its subject. LFS files stored in a separate LFS repository are never deleted, as
+they may be shared. The snapshots, releases, and tag history of the repository
+are deleted too, as they share its manifests and layers.`,
+		Example: `  # list the content that would be deleted
+  gnoci delete --dry-run oci://example.com/repo/test:sync
+
+  # delete a remote repository, keeping its LFS files
+  gnoci delete --keep-lfs oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVar(&action.DryRun, "dry-run", false, "list the content that would be deleted, without deleting it")
+	cmd.Flags().BoolVar(&action.KeepLFS, "keep-lfs", false, "keep the LFS manifest and LFS files of the repository")
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newFsckCmd creates the gnoci fsck command.
+func newFsckCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Fsck{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "fsck REFERENCE",
+		Short: "Check the integrity of a Git repository stored in an OCI Registry.",
+		Long: `Check the integrity of a Git repository stored in an OCI Registry.
+
+Every packfile layer is downloaded and indexed, verifying its digest. The commit of
+each reference, and those indexed in layer annotations, must exist within the claimed
+layer or those before it, and reference names must be valid. Problems are reported
+and the command exits non-zero if any are found.`,
+		Example: `  # check the integrity of a remote repository
+  gnoci fsck oci://example.com/repo/test:sync
+
+  # check the integrity of a remote repository, reporting problems as JSON
+  gnoci fsck --json oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newLayersCmd creates the gnoci layers command.
+func newLayersCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Layers{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "layers REFERENCE",
+		Short: "List the packfile layers of a Git repository stored in an OCI Registry.",
+		Long: `List the packfile layers of a Git repository stored in an OCI Registry.
+
+Layers are listed oldest first with the provenance recorded in their annotations:
+the client that pushed each layer, the number of commits it contains, and the
+references pushed with it along with their tip commits. Layers pushed by older
+clients may not record provenance.`,
+		Example: `  # list the packfile layers of a remote repository
+  gnoci layers oci://example.com/repo/test:sync
+
+  # list the packfile layers as JSON
+  gnoci layers --output json oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newStatsCmd creates the gnoci stats command.
+func newStatsCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Stats{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "stats REFERENCE",
+		Short: "Show statistics of a Git repository stored in an OCI Registry.",
+		Long: `Show statistics of a Git repository stored in an OCI Registry.
+
+The total size of the repository, its packfile layers and LFS files, the number of
+references, and the commits recorded by each packfile layer are shown without
+downloading any layers. Growth is shown across the most recent Git manifests pushed,
+which are only known for remotes with tagHistory configured; otherwise only the
+current Git manifest is shown.`,
+		Example: `  # show statistics of a remote repository
+  gnoci stats oci://example.com/repo/test:sync
+
+  # show statistics, with growth across the last 30 pushes, as JSON
+  gnoci stats --history 30 --json oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().IntVar(&action.History, "history", actions.DefaultStatsHistory, "number of most recent Git manifests to show growth across")
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newSignCmd creates the gnoci sign command.
+func newSignCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Sign{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "sign REFERENCE",
+		Short: "Sign a Git repository stored in an OCI Registry.",
+		Long: `Sign a Git repository stored in an OCI Registry.
+
+The current Git manifest is signed with a PEM encoded PKCS #8 private key, and the
+signature is attached to it as a referrer. ECDSA, Ed25519, and RSA keys are supported.
+Pushes update the Git manifest, set push.signingKey in the configuration to sign
+each push.`,
+		Example: `  # sign a remote repository
+  gnoci sign --key cosign.key oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&action.Key, "key", "", "path to the private key, defaults to push.signingKey in the configuration")
+
+	return cmd
+}
+
+// newVerifyCmd creates the gnoci verify command.
+func newVerifyCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Verify{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "verify REFERENCE",
+		Short: "Verify the signature of a Git repository stored in an OCI Registry.",
+		Example: `  # verify a remote repository is signed by a trusted key
+  gnoci verify --key cosign.pub oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&action.Keys, "key", nil, "path to a trusted public key, may be repeated, defaults to verifyPolicy.keys in the configuration")
+
+	return cmd
+}
+
+// newDescribeCmd creates the gnoci describe command.
+func newDescribeCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Describe{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "describe REFERENCE",
+		Short: "Show or update the metadata of a Git repository stored in an OCI Registry.",
+		Long: `Show or update the metadata of a Git repository stored in an OCI Registry.
+
+Metadata is stored as a referrer of the Git manifest, such that registry UIs may
+display it without cloning. Metadata is carried over to new Git manifests on push.`,
+		Example: `  # show the metadata of a remote repository
+  gnoci describe oci://example.com/repo/test:sync
+
+  # update the description and topics of a remote repository
+  gnoci describe --description "Pasta recipes" --topic food --topic italian oci://example.com/repo/test:sync
+
+  # map submodules hosted on GitHub to a registry
+  gnoci describe --submodule-url https://github.com/act3-ai/=oci://example.com/act3-ai/ oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&action.Description, "description", "", "update the repository description")
+	cmd.Flags().StringVar(&action.Website, "website", "", "update the repository website")
+	cmd.Flags().StringSliceVar(&action.Topics, "topic", nil, "replace the repository topics, may be repeated")
+	cmd.Flags().StringVar(&action.Readme, "readme", "", "path to a README to push with the metadata")
+	cmd.Flags().StringToStringVar(&action.SubmoduleURLs, "submodule-url", nil, "map a submodule URL prefix to the prefix replacing it, PREFIX=BASE, an empty BASE removes the mapping, may be repeated")
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newExportCmd creates the gnoci export command.
+func newExportCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Export{Gnoci: base}
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export REFERENCE REVISION",
+		Short: "Write an archive of a revision of a Git repository stored in an OCI Registry.",
+		Long: `Write an archive of a revision of a Git repository stored in an OCI Registry.
+
+The revision is a branch, tag, full reference name, or commit hash. Only the packfile
+layers needed to materialize its tree are fetched, without creating a local repository.
+The archive is written to stdout, similar to git archive which does not support remote
+helpers.`,
+		Example: `  # export the main branch of a remote repository as a tar archive
+  gnoci export oci://example.com/repo/test:sync main > test.tar
+
+  # export a tag as a zip archive, with paths prefixed by the project name
+  gnoci export --format zip --prefix test/ oci://example.com/repo/test:sync v1.0.0 > test.zip`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			action.Revision = args[1]
+			action.Format = actions.ArchiveFormat(format)
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", string(actions.ArchiveTar), `archive format, one of "tar" or "zip"`)
+	cmd.Flags().StringVar(&action.Prefix, "prefix", "", `prepended to each path in the archive, e.g. "project/"`)
+
+	return cmd
+}
+
+// newReposCmd creates the gnoci repos command.
+func newReposCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Repos{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "repos REGISTRY[/NAMESPACE]",
+		Short: "List the repositories of an OCI Registry, noting tags holding Git repositories.",
+		Long: `List the repositories of an OCI Registry, noting tags holding Git repositories.
+
+Each tag of each repository within the namespace is resolved to determine whether it
+holds a Git manifest. The registry must support the catalog API, which is commonly
+restricted or disabled on public registries.`,
+		Example: `  # list all repositories of a registry
+  gnoci repos example.com
+
+  # list the Git repositories within a namespace as JSON
+  gnoci repos --git-only --output json example.com/team`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+	cmd.Flags().BoolVar(&action.GitOnly, "git-only", false, "only list tags holding Git repositories")
+
+	return cmd
+}
+
+// newProjectsCmd creates the gnoci ls-projects command.
+func newProjectsCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Projects{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "ls-projects REFERENCE [PREFIX]",
+		Short: "List the Git repositories stored under the tags of one OCI repository.",
+		Long: `List the Git repositories stored under the tags of one OCI repository.
+
+A single OCI repository may hold many Git repositories, each pushed to and fetched
+from its own tag, e.g. oci://example.com/team/monorepo:projects.foo. Tags sharing a
+prefix form a namespace of projects. Each tag beginning with PREFIX is resolved, and
+those holding a Git manifest are listed, named by the remainder of the tag. Pushes
+never overwrite a tag holding another artifact type.`,
+		Example: `  # list the projects of the "projects." tag namespace
+  gnoci ls-projects oci://example.com/team/monorepo projects.
+
+  # clone one of the projects
+  git clone oci://example.com/team/monorepo:projects.foo`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			if len(args) > 1 {
+				action.Prefix = args[1]
+			}
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newMirrorCmd creates the gnoci mirror command.
+func newMirrorCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Mirror{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "mirror SOURCE REFERENCE",
+		Short: "Mirror the branches and tags of a Git repository to an OCI Registry.",
+		Long: `Mirror the branches and tags of a Git repository to an OCI Registry.
+
+The branches and tags of the source repository, any URL supported by go-git such as
+https:// or ssh://, are fetched into a temporary repository and force pushed to the
+OCI remote. No local clone is required, making the command suitable for scheduled
+mirroring. Branches and tags removed from the source are kept unless --prune is set.`,
+		Example: `  # mirror a repository to a registry
+  gnoci mirror https://github.com/act3-ai/gnoci.git oci://example.com/mirror/gnoci:sync
+
+  # mirror a repository, deleting branches and tags removed upstream
+  gnoci mirror --prune git@github.com:act3-ai/gnoci.git oci://example.com/mirror/gnoci:sync`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Source = args[0]
+			action.Address = args[1]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVar(&action.Prune, "prune", false, "delete branches and tags no longer in the source repository")
+	cmd.Flags().StringToStringVar(&action.Annotations, "annotation", nil, "add an annotation to the pushed manifest, KEY=VALUE, may be repeated")
+
+	return cmd
+}
+
+// newRestoreCmd creates the gnoci restore command.
+func newRestoreCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Restore{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "restore REFERENCE DESTINATION",
+		Short: "Push all references of a Git repository stored in an OCI Registry to a Git server.",
+		Long: `Push all references of a Git repository stored in an OCI Registry to a Git server.
+
+Every packfile layer is fetched into a temporary repository, and all heads, tags, and
+notes are pushed to the destination, any URL supported by go-git such as https:// or
+ssh://. The inverse of gnoci mirror, useful for repopulating a Git server from a
+registry backup. References of the destination are only overwritten if --force is set.`,
+		Example: `  # restore a repository to a new Git server
+  gnoci restore oci://example.com/backup/project:sync https://git.example.com/team/project.git`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			action.Destination = args[1]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&action.Force, "force", "f", false, "overwrite references of the destination which are not fast forwarded")
+
+	return cmd
+}
+
+// newBundleCmd creates the gnoci bundle command.
+func newBundleCmd(base *actions.Gnoci) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Convert between Git bundles and Git repositories stored in OCI Registries.",
+		Long: `Convert between Git bundles and Git repositories stored in OCI Registries.
+
+Git bundles are the native file format of Git for transferring repositories without a
+network connection, see git-bundle(1). Bundles may be verified, cloned, and fetched
+from with Git itself, easing air-gap processes already built around them.`,
+	}
+
+	cmd.AddCommand(
+		newBundleCreateCmd(base),
+		newBundlePushCmd(base),
+	)
+
+	return cmd
+}
+
+// newBundleCreateCmd creates the gnoci bundle create command.
+func newBundleCreateCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.BundleCreate{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "create REFERENCE FILE",
+		Short: "Write a Git repository stored in an OCI Registry to a Git bundle.",
+		Long: `Write a Git repository stored in an OCI Registry to a Git bundle.
+
+Every packfile layer is fetched into a temporary repository, and all heads, tags, and
+notes are written to the bundle with the objects reachable from them. HEAD is
+included if the repository has a default branch, such that cloning the bundle checks
+it out.`,
+		Example: `  # write a repository to a bundle
+  gnoci bundle create oci://example.com/repo/test:sync test.bundle
+
+  # clone the bundle
+  git clone test.bundle test`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			action.File = args[1]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+// newBundlePushCmd creates the gnoci bundle push command.
+func newBundlePushCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.BundlePush{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "push FILE REFERENCE",
+		Short: "Push the references of a Git bundle to an OCI Registry.",
+		Long: `Push the references of a Git bundle to an OCI Registry.
+
+The bundle is unpacked into a temporary repository, and its heads, tags, and notes are
+pushed to the OCI remote. Incremental bundles, created by git bundle with a revision
+range, are supported if their prerequisite commits are in the remote repository.
+References of the remote are only overwritten if --force is set.`,
+		Example: `  # push a full bundle
+  git bundle create test.bundle --all
+  gnoci bundle push test.bundle oci://example.com/repo/test:sync
+
+  # push the commits of main since the v1.0.0 tag
+  git bundle create update.bundle v1.0.0..main
+  gnoci bundle push update.bundle oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.File = args[0]
+			action.Address = args[1]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&action.Force, "force", "f", false, "overwrite references of the remote which are not fast forwarded")
+	cmd.Flags().StringToStringVar(&action.Annotations, "annotation", nil, "add an annotation to the pushed manifest, KEY=VALUE, may be repeated")
+
+	return cmd
+}
+
+// newSubmoduleCmd creates the gnoci submodule command.
+func newSubmoduleCmd(base *actions.Gnoci) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submodule",
+		Short: "Inspect the submodules of a Git repository stored in an OCI Registry.",
+		Long: `Inspect the submodules of a Git repository stored in an OCI Registry.
+
+Submodule URLs are rewritten by the mappings of the repository metadata, set with
+gnoci describe --submodule-url, such that submodules stored in registries are
+resolved to their oci:// remotes. Set fetch.submoduleURLs in the configuration to
+write the mappings to the local repository on clone, such that git submodule update
+fetches submodules from the registries.`,
+	}
+
+	cmd.AddCommand(newSubmoduleResolveCmd(base))
+
+	return cmd
+}
+
+// newSubmoduleResolveCmd creates the gnoci submodule resolve command.
+func newSubmoduleResolveCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.SubmoduleResolve{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "resolve REFERENCE [REVISION]",
+		Short: "List the submodules of a revision with their resolved URLs.",
+		Long: `List the submodules of a revision with their resolved URLs.
+
+The revision is a branch, tag, full reference name, or commit hash, defaulting to the
+default branch. Only the packfile layers needed to materialize its tree are fetched,
+without creating a local repository. The commit of each submodule is listed as
+recorded in the tree, or "-" if missing.`,
+		Example: `  # list the submodules of the default branch
+  gnoci submodule resolve oci://example.com/repo/test:sync
+
+  # list the submodules of a tag as JSON
+  gnoci submodule resolve -o json oci://example.com/repo/test:sync v1.0.0`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			if len(args) > 1 {
+				action.Revision = args[1]
+			}
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newCacheCmd creates the gnoci cache command.
+func newCacheCmd(base *actions.Gnoci) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache of fetched packfile layers and LFS files.",
+		Long: `Manage the local cache of fetched packfile layers and LFS files.
+
+The cache is enabled with cache.enabled in the configuration file, and is shared by
+all repositories.`,
+	}
+
+	cmd.AddCommand(newCachePruneCmd(base))
+
+	return cmd
+}
+
+// newCachePruneCmd creates the gnoci cache prune command.
+func newCachePruneCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.CachePrune{Gnoci: base}
+	var keep string
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove blobs from the local cache.",
+		Example: `  # empty the cache
+  gnoci cache prune
+
+  # evict the least recently used blobs, keeping at most 1 GiB
+  gnoci cache prune --keep 1Gi`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			q, err := resource.ParseQuantity(keep)
+			if err != nil {
+				return fmt.Errorf("parsing --keep: %w", err)
+			}
+			action.KeepSize = q.Value()
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&keep, "keep", "0", "total size of the most recently used blobs to keep, e.g. 1Gi")
+
+	return cmd
+}
+
+// newPrefetchCmd creates the gnoci prefetch command.
+func newPrefetchCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.Prefetch{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "prefetch REFERENCE [REVISION...]",
+		Short: "Download the layers of a Git repository stored in an OCI Registry into the local cache.",
+		Long: `Download the layers of a Git repository stored in an OCI Registry into the local cache.
+
+The packfile layers needed to fetch each revision, a branch, tag, full reference name,
+or commit hash, are downloaded into the local cache without creating a repository. All
+layers are downloaded if no revision is given, and all LFS files if --lfs is set. Useful
+for pre-warming the cache of CI images in a separate step, before git clone oci://
+runs with cache.enabled set. Layers already cached are not downloaded again.`,
+		Example: `  # cache every packfile layer and LFS file of a repository
+  gnoci prefetch --lfs oci://example.com/repo/test:sync
+
+  # cache the packfile layers needed to fetch the main branch and a tag
+  gnoci prefetch oci://example.com/repo/test:sync main v1.0.0`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			action.Revisions = args[1:]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVar(&action.LFS, "lfs", false, "also download all LFS files")
+
+	return cmd
+}
+
+// newReleaseCmd creates the gnoci release command.
+func newReleaseCmd(base *actions.Gnoci) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Manage immutable releases of a Git repository stored in an OCI Registry.",
+		Long: `Manage immutable releases of a Git repository stored in an OCI Registry.
+
+A release is an OCI image index of the current Git manifest and its LFS manifest,
+tagged in the same OCI repository. The release may be pulled as a unit even as the
+tag of the repository moves with later pushes.`,
+	}
+
+	cmd.AddCommand(
+		newReleaseCreateCmd(base),
+		newReleaseListCmd(base),
+	)
+
+	return cmd
+}
+
+// newReleaseCreateCmd creates the gnoci release create command.
+func newReleaseCreateCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.ReleaseCreate{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "create REFERENCE TAG",
+		Short: "Tag the current state of a Git repository as a release.",
+		Long: `Tag the current state of a Git repository as a release.
+
+The current Git manifest, and its LFS manifest if any, are referenced by an image
+index tagged TAG. Existing tags are not overwritten. LFS manifests stored in a
+separate repository are not included.`,
+		Example: `  # release the current state of a remote repository
+  gnoci release create oci://example.com/repo/test:sync v1.0.0
+
+  # clone the release, fetching the Git manifest within the index
+  git clone oci://example.com/repo/test:v1.0.0`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			action.Tag = args[1]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+// newReleaseListCmd creates the gnoci release list command.
+func newReleaseListCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.ReleaseList{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "list REFERENCE",
+		Short: "List the releases of a Git repository.",
+		Example: `  # list the releases of a remote repository
+  gnoci release list oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newLFSCmd creates the gnoci lfs command.
+func newLFSCmd(base *actions.Gnoci) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lfs",
+		Short: "Manage the Git LFS files stored with a Git repository in an OCI Registry.",
+	}
+
+	cmd.AddCommand(
+		newLFSListCmd(base),
+		newLFSPruneCmd(base),
+	)
+
+	return cmd
+}
+
+// newLFSListCmd creates the gnoci lfs ls command.
+func newLFSListCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.LFSList{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "ls REFERENCE",
+		Short: "List the Git LFS files stored with a Git repository.",
+		Long: `List the Git LFS files stored with a Git repository.
+
+Each layer of the LFS manifest referring to the Git manifest is listed with the
+object ID and size of its LFS file, without running git-lfs. Encrypted layers are
+listed by the object ID of the decrypted file.`,
+		Example: `  # list the LFS files of a remote repository
+  gnoci lfs ls oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	addOutputFlags(cmd, &action.Output)
+
+	return cmd
+}
+
+// newLFSPruneCmd creates the gnoci lfs prune command.
+func newLFSPruneCmd(base *actions.Gnoci) *cobra.Command {
+	action := &actions.LFSPrune{Gnoci: base}
+
+	cmd := &cobra.Command{
+		Use:   "prune REFERENCE",
+		Short: "Remove Git LFS files no longer referenced by a Git repository.",
+		Long: `Remove Git LFS files no longer referenced by a Git repository.
+
+The packfile layers of the repository are fetched to find the LFS pointer files
+reachable from its current heads, tags, and notes. LFS files without a reachable
+pointer are removed from the LFS manifest, leaving their layers to the
+registry's garbage collection.
+
+With --delete, their layers are deleted if the registry supports deletion, except
+those of releases. Layers stored in a separate LFS repository are never deleted,
+as they may be shared. LFS files uploaded by a concurrent push, before the
+pointer files referring to them, may be deleted, so only delete layers while
+the repository is not being pushed to.`,
+		Example: `  # prune the unreferenced LFS files of a remote repository
+  gnoci lfs prune oci://example.com/repo/test:sync
+
+  # prune and delete their layers
+  gnoci lfs prune --delete oci://example.com/repo/test:sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action.Address = args[0]
+			return action.Run(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVar(&action.DeleteLayers, "delete", false, "delete the layers of pruned LFS files from the registry")
+
+	return cmd
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/act3-ai/gnoci/internal/logutil"
+	"github.com/act3-ai/go-common/pkg/logger"
+)
+
+// logFormatEnv is the environment variable setting the default log format.
+const logFormatEnv = "GNOCI_LOG_FORMAT"
+
+// addLogFormatFlag adds the persistent --log-format flag to cmd, replacing the
+// logger of the command context before any command runs. If level is not nil,
+// logs are filtered by level, initially the verbosity of the CLI runner, such
+// that it may be changed while the command runs.
+func addLogFormatFlag(cmd *cobra.Command, level *slog.LevelVar) {
+	format := logutil.FormatDefault
+	envErr := format.Set(os.Getenv(logFormatEnv))
+
+	cmd.PersistentFlags().Var(&format, "log-format",
+		`Logging output format, "json" always includes telemetry events (also setable with environment variable `+logFormatEnv+`)`)
+
+	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		if envErr != nil && !cmd.Flags().Changed("log-format") {
+			slog.WarnContext(ctx, "ignoring log format from environment", slog.String("error", envErr.Error()))
+		}
+		if level == nil && format != logutil.FormatJSON {
+			return
+		}
+
+		handler := logger.FromContext(ctx).Handler()
+		if level != nil {
+			handler = logutil.NewLevelHandler(ctx, cmd.ErrOrStderr(), handler, level)
+		}
+		if format == logutil.FormatJSON {
+			handler = logutil.NewJSONHandler(cmd.ErrOrStderr(), handler)
+		}
+
+		log := slog.New(handler)
+		slog.SetDefault(log)
+		cmd.SetContext(logger.NewContext(ctx, log))
+	}
+}
+
+// gitTraceEnabled reports whether the value of the GIT_TRACE environment
+// variable enables tracing. Git also accepts a file descriptor or path, both
+// of which enable tracing.
+func gitTraceEnabled(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "0", "false", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
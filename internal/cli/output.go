@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/act3-ai/gnoci/internal/actions"
+)
+
+// jsonFlag is a boolean flag selecting JSON output.
+type jsonFlag struct {
+	output *string
+}
+
+func (f *jsonFlag) String() string {
+	return strconv.FormatBool(f.output != nil && *f.output == actions.OutputJSON)
+}
+
+func (f *jsonFlag) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if v {
+		*f.output = actions.OutputJSON
+	}
+	return nil
+}
+
+func (f *jsonFlag) Type() string {
+	return "bool"
+}
+
+// addOutputFlags adds the --output flag to cmd, and --json as shorthand for
+// --output json, setting output.
+func addOutputFlags(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, "output", "o", actions.OutputTable, `output format, one of "table" or "json"`)
+	cmd.Flags().Var(&jsonFlag{output: output}, "json", "shorthand for --output json")
+	cmd.Flags().Lookup("json").NoOptDefVal = "true"
+	cmd.MarkFlagsMutuallyExclusive("output", "json")
+}
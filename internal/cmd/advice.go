@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// writeAdvice writes advice to the user, prefixing each line with "hint: " as
+// Git does.
+func writeAdvice(ctx context.Context, w io.Writer, advice []string) {
+	for _, line := range advice {
+		if _, err := fmt.Fprintf(w, "hint: %s\n", line); err != nil {
+			slog.DebugContext(ctx, "writing advice", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
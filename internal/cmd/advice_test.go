@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/refcomp"
+)
+
+func Test_writeAdvice(t *testing.T) {
+	derr := &refcomp.DivergedError{
+		Local:  plumbing.NewHashReference("refs/heads/main", plumbing.ZeroHash),
+		Remote: plumbing.NewHashReference("refs/heads/main", plumbing.ZeroHash),
+		Ahead:  2,
+		Behind: 3,
+	}
+
+	out := new(bytes.Buffer)
+	writeAdvice(t.Context(), out, derr.Advice())
+	assert.Equal(t, "hint: The remote main has 3 commits not in your local main, which has 2 commits not in the remote.\n"+
+		"hint: Integrate the remote changes, e.g. 'git pull', before pushing again.\n"+
+		"hint: Or overwrite them with 'git push --force-with-lease', if they may be discarded.\n", out.String())
+}
@@ -18,7 +18,7 @@ func HandleCapabilities(ctx context.Context, comm comms.Communicator) error {
 		return fmt.Errorf("parsing capabilities request: %w", err)
 	}
 
-	capabilities := []git.Capability{git.CapabilityOption, git.CapabilityFetch, git.CapabilityPush}
+	capabilities := []git.Capability{git.CapabilityOption, git.CapabilityFetch, git.CapabilityPush, git.CapabilityCheckConnectivity, git.CapabilityObjectFormat}
 
 	slog.DebugContext(ctx, "writing supported capabilities", "capabilities", fmt.Sprintf("%v", capabilities))
 	if err := comm.WriteCapabilitiesResponse(capabilities); err != nil {
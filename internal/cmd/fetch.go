@@ -2,28 +2,152 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"slices"
+	"time"
 
-	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sourcegraph/conc/pool"
 
 	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/logutil"
 	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/tracing"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
 )
 
+// errIncompleteHistory indicates the remote does not contain the history
+// needed to satisfy a fetch request.
+var errIncompleteHistory = errors.New("incomplete history in remote")
+
+// receivingTitle is the title of fetch progress reports.
+const receivingTitle = "Receiving packfile layers"
+
 // HandleFetch executes a batch of fetch commands.
-func HandleFetch(ctx context.Context, local git.Repository, remote model.ReadOnlyModeler, comm comms.Communicator) error {
-	_, err := remote.Fetch(ctx)
+func HandleFetch(ctx context.Context, local git.Repository, remote model.ReadOnlyModeler, comm comms.Communicator, opts *Options) (err error) {
+	ctx, span := tracing.Start(ctx, "HandleFetch")
+	defer tracing.End(span, &err)
+
+	_, err = remote.Fetch(ctx)
 	if err != nil {
 		return fmt.Errorf("fetching remote metadata: %w", err)
 	}
 
-	_, err = comm.ParseFetchRequestBatch()
+	reqs, err := comm.ParseFetchRequestBatch()
 	if err != nil {
 		return fmt.Errorf("parsing fetch request batch: %w", err)
 	}
+	span.SetAttributes(tracing.KeyRequests.Int(len(reqs)))
+
+	if err := Fetch(ctx, local, remote, reqs, opts); err != nil {
+		return err
+	}
+
+	if opts != nil && opts.CheckConnectivity && opts.Depth == 0 {
+		// git's connectivity check of shallow clones is not skipped
+		err := checkConnected(local.Storer(), reqs, graftsOf(remote))
+		switch {
+		case errors.Is(err, errIncompleteHistory):
+			slog.WarnContext(ctx, "fetched objects are not connected, deferring to git's connectivity check", slog.String("error", err.Error()))
+		case err != nil:
+			return fmt.Errorf("checking connectivity: %w", err)
+		default:
+			if err := comm.WriteConnectivityOK(); err != nil {
+				return fmt.Errorf("writing connectivity response: %w", err)
+			}
+		}
+	}
+
+	if err := comm.WriteFetchResponse(); err != nil {
+		return fmt.Errorf("writing fetch response: %w", err)
+	}
+
+	return nil
+}
+
+// Fetch writes the objects needed to satisfy a batch of fetch requests to the
+// local repository. The remote metadata must already be fetched.
+func Fetch(ctx context.Context, local git.Repository, remote model.ReadOnlyModeler, reqs []gittypes.FetchRequest, opts *Options) error {
+	if err := model.CheckObjectFormat(remote.ObjectFormat()); err != nil {
+		return fmt.Errorf("remote repository: %w", err)
+	}
+
+	start := time.Now()
+	logutil.Event(ctx, logutil.EventFetchStart, slog.Int("requests", len(reqs)))
+
+	grafts := graftsOf(remote)
+	switch {
+	case opts != nil && opts.Depth > 0:
+		if err := fetchShallow(ctx, local.Storer(), remote, reqs, grafts, opts); err != nil {
+			return err
+		}
+	default:
+		if err := fetchAll(ctx, local, remote, opts); err != nil {
+			return err
+		}
+		if err := markGrafts(local.Storer(), reqs, grafts); err != nil {
+			return err
+		}
+	}
+	if opts != nil && opts.FollowTags {
+		if err := fetchFollowedTags(ctx, local.Storer(), remote, opts); err != nil {
+			return err
+		}
+	}
+	slog.InfoContext(ctx, "done fetching packfiles")
+	logutil.Event(ctx, logutil.EventFetchDone, slog.Int("requests", len(reqs)), logutil.Since(start))
+
+	return nil
+}
+
+// fetchAll fetches all packfile layers, ensuring all history is complete.
+// Layers whose commits already exist in the local repository, e.g. fetched
+// from a mirror, are skipped.
+func fetchAll(ctx context.Context, local git.Repository, remote model.ReadOnlyModeler, opts *Options) error {
+	st := local.Storer()
+	layers := remote.Layers()
+	missing := slices.DeleteFunc(slices.Clone(layers), func(desc ocispec.Descriptor) bool {
+		return layerPresent(local, desc)
+	})
+	if skipped := len(layers) - len(missing); skipped > 0 {
+		slog.InfoContext(ctx, "skipping packfile layers with commits present locally", slog.Int("skipped", skipped), slog.Int("remaining", len(missing)))
+	}
+	m := opts.meter(receivingTitle, len(missing))
+
+	thin := slices.ContainsFunc(layers, isThin)
+	if fst, ok := st.(*filesystem.Storage); ok && !thin && len(missing) > 1 {
+		// self-contained packfiles are independent of each other, written to
+		// the repository concurrently
+		if err := fetchLayersConcurrently(ctx, fst, remote, missing, opts.indexWorkers(), m); err != nil {
+			return err
+		}
+		m.done()
+		return nil
+	}
+
+	if len(missing) < len(layers) || thin {
+		// thin packfiles depend on older layers, fetch oldest to newest.
+		// Delta bases in skipped layers are resolved from the local repository.
+		for _, desc := range missing {
+			if err := fetchLayer(ctx, st, remote, desc, m); err != nil {
+				return err
+			}
+		}
+		m.done()
+		return nil
+	}
 
 	// HACK: Performance here is terrible, we always fetch all packfiles to ensure
 	// all history is complete. The main difficulty here is we don't know what's
@@ -32,25 +156,544 @@ func HandleFetch(ctx context.Context, local git.Repository, remote model.ReadOnl
 		if err != nil {
 			return fmt.Errorf("fetching packfile: %w", err)
 		}
-		defer rc.Close() //nolint:revive
 
-		st, ok := local.Storer().(storer.Storer)
-		if !ok {
-			return fmt.Errorf("repository storer is not a storer.Storer")
+		if err := unpackLayer(ctx, st, rc, false, m); err != nil {
+			return err
 		}
+	}
+	m.done()
+
+	return nil
+}
+
+// fetchLayersConcurrently fetches self-contained packfile layers with up to
+// workers at once, each writing its packfile and pack index to object storage.
+// The pack index of a layer is installed if available, otherwise the packfile
+// is indexed locally. Object storage is reindexed once all are written,
+// including when any fail, as written packfiles are valid.
+func fetchLayersConcurrently(ctx context.Context, st *filesystem.Storage, remote model.ReadOnlyModeler, layers []ocispec.Descriptor, workers int, m *meter) error {
+	slog.DebugContext(ctx, "fetching packfile layers concurrently", slog.Int("layers", len(layers)), slog.Int("workers", workers))
 
-		if err := packfile.UpdateObjectStorage(st, rc); err != nil {
-			return fmt.Errorf("updating object storage with packfile: %w", err)
+	p := pool.New().WithErrors().WithContext(ctx).WithMaxGoroutines(workers)
+	for _, desc := range layers {
+		p.Go(func(ctx context.Context) error {
+			return writeLayer(ctx, st, remote, desc, m)
+		})
+	}
+	err := p.Wait()
+	st.Reindex()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// writeLayer fetches a self-contained packfile layer, writing it to object
+// storage without reindexing it.
+func writeLayer(ctx context.Context, st *filesystem.Storage, remote model.ReadOnlyModeler, desc ocispec.Descriptor, m *meter) error {
+	var idx io.Reader
+	if model.PackIndex(desc) != "" {
+		rc, err := remote.FetchPackIndex(ctx, desc.Digest)
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "failed to fetch pack index, indexing packfile locally", slog.String("digest", desc.Digest.String()), slog.String("error", err.Error()))
+		default:
+			defer rc.Close()
+			idx = rc
 		}
-		if err := rc.Close(); err != nil {
-			return fmt.Errorf("closing packfile reader: %w", err)
+	}
+
+	rc, err := remote.FetchLayer(ctx, desc.Digest)
+	if err != nil {
+		return fmt.Errorf("fetching packfile: %w", err)
+	}
+	defer rc.Close()
+
+	prc, stop := trackReader(ctx, rc, m)
+	err = model.WritePack(ctx, st, prc, idx)
+	stop()
+	if err != nil {
+		return fmt.Errorf("writing packfile %s: %w", desc.Digest, err)
+	}
+	if err := rc.Close(); err != nil {
+		return fmt.Errorf("closing packfile reader: %w", err)
+	}
+	m.increment(1)
+
+	return nil
+}
+
+// fetchShallow fetches packfile layers, newest to oldest, until the history
+// within depth commits of each requested commit is complete. Commits on the
+// shallow boundary, including grafts of a remote with truncated history, are
+// recorded in the local repository.
+func fetchShallow(ctx context.Context, st storage.Storer, remote model.ReadOnlyModeler, reqs []gittypes.FetchRequest, grafts map[plumbing.Hash]struct{}, opts *Options) error {
+	depth := opts.Depth
+	tips := make([]plumbing.Hash, 0, len(reqs))
+	for _, req := range reqs {
+		tips = append(tips, req.Ref.Hash())
+	}
+
+	layers := remote.Layers()
+	next := newestRequestedLayer(ctx, remote, reqs, layers)
+	m := opts.meter(receivingTitle, 0) // number of layers needed is unknown
+	unpacked := make(map[digest.Digest]struct{}, next+1)
+	for {
+		boundary, complete, err := walkDepth(st, tips, depth, grafts)
+		if err != nil {
+			return fmt.Errorf("walking commit history: %w", err)
+		}
+		if complete {
+			m.done()
+			slog.InfoContext(ctx, "fetched history to depth", slog.Int("depth", depth), slog.Int("layersRemaining", next+1))
+			return updateShallow(st, boundary)
 		}
 
+		if next < 0 {
+			return fmt.Errorf("%w: unable to resolve history to depth %d", errIncompleteHistory, depth)
+		}
+
+		desc := layers[next]
+		next--
+		if _, ok := unpacked[desc.Digest]; ok {
+			continue
+		}
+		slog.DebugContext(ctx, "history incomplete, fetching packfile layer", slog.String("digest", desc.Digest.String()))
+		if err := fetchThinLayer(ctx, st, remote, layers, desc, unpacked, m); err != nil {
+			return err
+		}
 	}
-	slog.InfoContext(ctx, "done fetching packfiles")
+}
 
-	if err := comm.WriteFetchResponse(); err != nil {
-		return fmt.Errorf("writing fetch response: %w", err)
+// fetchFollowedTags fetches the packfile layers of annotated tags pointing to
+// objects in object storage, whose tag objects are missing, such that Git
+// records the tags without fetching them again. Tags pushed without the object
+// they peel to are only followed if their layers were fetched otherwise.
+func fetchFollowedTags(ctx context.Context, st storer.Storer, remote model.ReadOnlyModeler, opts *Options) error {
+	needed := make(map[digest.Digest]struct{})
+	for name, info := range remote.TagRefs() {
+		if info.Peeled == "" || info.Layer == "" {
+			continue
+		}
+		if st.HasEncodedObject(plumbing.NewHash(info.Commit)) == nil || st.HasEncodedObject(plumbing.NewHash(info.Peeled)) != nil {
+			continue
+		}
+		slog.DebugContext(ctx, "following tag of fetched object", slog.String("reference", name.String()), slog.String("layer", info.Layer.String()))
+		for _, layer := range info.AllLayers() {
+			needed[layer] = struct{}{}
+		}
+	}
+	if len(needed) == 0 {
+		return nil
+	}
+
+	// thin packfiles depend on older layers, fetch oldest to newest
+	layers := remote.Layers()
+	m := opts.meter(receivingTitle, len(needed))
+	unpacked := make(map[digest.Digest]struct{}, len(needed))
+	for _, desc := range layers {
+		if _, ok := needed[desc.Digest]; !ok {
+			continue
+		}
+		if _, ok := unpacked[desc.Digest]; ok {
+			continue
+		}
+		if err := fetchThinLayer(ctx, st, remote, layers, desc, unpacked, m); err != nil {
+			return err
+		}
+	}
+	m.done()
+	slog.InfoContext(ctx, "fetched packfile layers of followed tags", slog.Int("layers", len(needed)))
+
+	return nil
+}
+
+// fetchThinLayer fetches a packfile layer, which may be thin. If delta bases
+// are missing from object storage, the layers it depends on are fetched first.
+func fetchThinLayer(ctx context.Context, st storer.Storer, remote model.ReadOnlyModeler, layers []ocispec.Descriptor, desc ocispec.Descriptor, unpacked map[digest.Digest]struct{}, m *meter) error {
+	err := fetchLayer(ctx, st, remote, desc, m)
+	base := model.PackBase(desc)
+	if base == "" || !errors.Is(err, plumbing.ErrObjectNotFound) {
+		if err == nil {
+			unpacked[desc.Digest] = struct{}{}
+		}
+		return err
+	}
+
+	baseIdx := slices.IndexFunc(layers, func(d ocispec.Descriptor) bool {
+		return d.Digest == base
+	})
+	if baseIdx < 0 {
+		return fmt.Errorf("%w: base layer %s of thin packfile %s not found", errIncompleteHistory, base, desc.Digest)
+	}
+
+	slog.DebugContext(ctx, "delta bases missing, fetching base layers", slog.String("digest", desc.Digest.String()), slog.String("base", base.String()))
+	for _, d := range layers[:baseIdx+1] {
+		if _, ok := unpacked[d.Digest]; ok {
+			continue
+		}
+		if err := fetchLayer(ctx, st, remote, d, m); err != nil {
+			return err
+		}
+		unpacked[d.Digest] = struct{}{}
+	}
+
+	if err := fetchLayer(ctx, st, remote, desc, m); err != nil {
+		return err
+	}
+	unpacked[desc.Digest] = struct{}{}
+
+	return nil
+}
+
+// layerPresent returns true if all commits indexed in a packfile layer exist in
+// the local repository, in which case the objects they reference are assumed
+// to exist as well. Unindexed layers are never present.
+func layerPresent(local git.Repository, desc ocispec.Descriptor) bool {
+	commits := model.PackCommits(desc)
+	if len(commits) == 0 {
+		return false
+	}
+
+	for _, h := range commits {
+		if _, err := local.CommitObject(h); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fetchLayer fetches and unpacks a single packfile layer. The pack index of
+// the layer, if any, is installed rather than indexing the packfile locally.
+func fetchLayer(ctx context.Context, st storer.Storer, remote model.ReadOnlyModeler, desc ocispec.Descriptor, m *meter) error {
+	if fst, ok := st.(*filesystem.Storage); ok && model.PackIndex(desc) != "" && !isThin(desc) {
+		idx, err := remote.FetchPackIndex(ctx, desc.Digest)
+		if err == nil {
+			return installLayer(ctx, fst, remote, desc, idx, m)
+		}
+		slog.WarnContext(ctx, "failed to fetch pack index, indexing packfile locally", slog.String("digest", desc.Digest.String()), slog.String("error", err.Error()))
+	}
+
+	rc, err := remote.FetchLayer(ctx, desc.Digest)
+	if err != nil {
+		return fmt.Errorf("fetching packfile: %w", err)
+	}
+
+	return unpackLayer(ctx, st, rc, isThin(desc), m)
+}
+
+// installLayer fetches a packfile layer, installing it alongside its pack
+// index read by idx.
+func installLayer(ctx context.Context, st *filesystem.Storage, remote model.ReadOnlyModeler, desc ocispec.Descriptor, idx io.ReadCloser, m *meter) error {
+	defer idx.Close()
+
+	rc, err := remote.FetchLayer(ctx, desc.Digest)
+	if err != nil {
+		return fmt.Errorf("fetching packfile: %w", err)
+	}
+	defer rc.Close()
+
+	prc, stop := trackReader(ctx, rc, m)
+	err = model.InstallPack(ctx, st, prc, idx)
+	stop()
+	if err != nil {
+		return fmt.Errorf("installing packfile: %w", err)
+	}
+	if err := rc.Close(); err != nil {
+		return fmt.Errorf("closing packfile reader: %w", err)
+	}
+	m.increment(1)
+
+	return nil
+}
+
+// isThin returns true if a packfile layer is a thin packfile.
+func isThin(desc ocispec.Descriptor) bool {
+	return model.PackBase(desc) != ""
+}
+
+// newestRequestedLayer returns the index of the newest layer holding objects
+// of a requested reference, the union of the layers of each reference. Layers
+// newer than it are not needed to satisfy the requests. Requests for an exact
+// commit, rather than a remote reference, are resolved with the commit index
+// of each layer. If a request cannot be resolved to a layer, the newest layer
+// is used.
+func newestRequestedLayer(ctx context.Context, remote model.ReadOnlyModeler, reqs []gittypes.FetchRequest, layers []ocispec.Descriptor) int {
+	newest := -1
+	for _, req := range reqs {
+		dgsts, err := resolveRequestedLayers(ctx, remote, req)
+		if err != nil {
+			slog.DebugContext(ctx, "unable to resolve layer for requested reference", slog.String("reference", req.Ref.Name().String()), slog.String("error", err.Error()))
+			return len(layers) - 1
+		}
+		for _, dgst := range dgsts {
+			idx := slices.IndexFunc(layers, func(desc ocispec.Descriptor) bool {
+				return desc.Digest == dgst
+			})
+			if idx < 0 {
+				return len(layers) - 1
+			}
+			newest = max(newest, idx)
+		}
+	}
+
+	return newest
+}
+
+// resolveRequestedLayers resolves the layers holding the objects of a fetch
+// request, falling back to the commit index if the requested name is not a
+// remote reference, e.g. "fetch <sha> <sha>".
+func resolveRequestedLayers(ctx context.Context, remote model.ReadOnlyModeler, req gittypes.FetchRequest) ([]digest.Digest, error) {
+	dgsts, err := remote.ResolveRefLayers(ctx, req.Ref.Name())
+	if err == nil && len(dgsts) > 0 {
+		return dgsts, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%w: %s is not backed by a packfile", model.ErrReferenceNotFound, req.Ref.Name())
+	}
+
+	dgst, commitErr := remote.ResolveCommit(ctx, req.Ref.Hash())
+	if commitErr != nil {
+		return nil, errors.Join(err, commitErr)
+	}
+
+	return []digest.Digest{dgst}, nil
+}
+
+// unpackLayer writes the objects of a packfile layer to object storage,
+// reporting progress to m.
+func unpackLayer(ctx context.Context, st storer.Storer, rc io.ReadCloser, thin bool, m *meter) error {
+	defer rc.Close()
+
+	prc, stop := trackReader(ctx, rc, m)
+	err := model.UnpackPack(st, prc, thin)
+	stop()
+	if err != nil {
+		return fmt.Errorf("updating object storage with packfile: %w", err)
+	}
+	if err := rc.Close(); err != nil {
+		return fmt.Errorf("closing packfile reader: %w", err)
+	}
+	m.increment(1)
+
+	return nil
+}
+
+// checkConnected verifies the full history of each requested commit, and the
+// objects of its tree, exist in object storage. History ends at grafts.
+func checkConnected(st storer.EncodedObjectStorer, reqs []gittypes.FetchRequest, grafts map[plumbing.Hash]struct{}) error {
+	tips := make([]plumbing.Hash, 0, len(reqs))
+	for _, req := range reqs {
+		tips = append(tips, req.Ref.Hash())
+	}
+
+	_, complete, err := walkDepth(st, tips, math.MaxInt, grafts)
+	switch {
+	case err != nil:
+		return fmt.Errorf("walking commit history: %w", err)
+	case !complete:
+		return errIncompleteHistory
+	}
+
+	return nil
+}
+
+// graftsOf returns the shallow boundary of a remote with truncated history.
+func graftsOf(remote model.ReadOnlyModeler) map[plumbing.Hash]struct{} {
+	shallow := remote.Shallow()
+	if len(shallow) == 0 {
+		return nil
+	}
+
+	grafts := make(map[plumbing.Hash]struct{}, len(shallow))
+	for _, h := range shallow {
+		grafts[h] = struct{}{}
+	}
+	return grafts
+}
+
+// markGrafts records the grafts reachable from the requested commits as
+// shallow in the local repository, such that Git does not expect their
+// parents to exist.
+func markGrafts(st storage.Storer, reqs []gittypes.FetchRequest, grafts map[plumbing.Hash]struct{}) error {
+	if len(grafts) == 0 {
+		return nil
+	}
+
+	tips := make([]plumbing.Hash, 0, len(reqs))
+	for _, req := range reqs {
+		tips = append(tips, req.Ref.Hash())
+	}
+
+	boundary, _, err := walkDepth(st, tips, math.MaxInt, grafts)
+	if err != nil {
+		return fmt.Errorf("walking commit history: %w", err)
+	}
+
+	return updateShallow(st, boundary)
+}
+
+// walkDepth walks the commit graph from tips to depth, returning the commits
+// on the shallow boundary. Grafts, commits whose parents are known to be
+// missing, are always on the boundary. complete is false if any commit, or
+// the objects of its tree, within depth are missing from object storage.
+func walkDepth(st storer.EncodedObjectStorer, tips []plumbing.Hash, depth int, grafts map[plumbing.Hash]struct{}) (boundary []plumbing.Hash, complete bool, err error) {
+	type entry struct {
+		hash  plumbing.Hash
+		depth int
+	}
+
+	queue := make([]entry, 0, len(tips))
+	seen := make(map[plumbing.Hash]struct{}, len(tips))
+	for _, tip := range tips {
+		if _, ok := seen[tip]; !ok {
+			queue = append(queue, entry{hash: tip, depth: 1})
+			seen[tip] = struct{}{}
+		}
+	}
+	seenTrees := make(map[plumbing.Hash]struct{})
+
+	// breadth first, ensuring commits are visited at their minimum depth
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+
+		obj, err := st.EncodedObject(plumbing.AnyObject, e.hash)
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			return nil, false, nil
+		case err != nil:
+			return nil, false, fmt.Errorf("resolving object %s: %w", e.hash, err)
+		}
+
+		switch obj.Type() { //nolint:exhaustive
+		case plumbing.TagObject:
+			// annotated tags do not count towards depth
+			tag, err := object.DecodeTag(st, obj)
+			if err != nil {
+				return nil, false, fmt.Errorf("decoding tag %s: %w", e.hash, err)
+			}
+			if _, ok := seen[tag.Target]; !ok {
+				queue = append(queue, entry{hash: tag.Target, depth: e.depth})
+				seen[tag.Target] = struct{}{}
+			}
+			continue
+		case plumbing.CommitObject:
+		default:
+			continue
+		}
+
+		commit, err := object.DecodeCommit(st, obj)
+		if err != nil {
+			return nil, false, fmt.Errorf("decoding commit %s: %w", e.hash, err)
+		}
+
+		ok, err := treeComplete(st, commit.TreeHash, seenTrees)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+
+		if e.depth >= depth || isGraft(st, commit, grafts) {
+			if commit.NumParents() > 0 {
+				boundary = append(boundary, commit.Hash)
+			}
+			continue
+		}
+
+		for _, parent := range commit.ParentHashes {
+			if _, ok := seen[parent]; !ok {
+				queue = append(queue, entry{hash: parent, depth: e.depth + 1})
+				seen[parent] = struct{}{}
+			}
+		}
+	}
+
+	return boundary, true, nil
+}
+
+// isGraft returns true if commit is a graft with parents missing from object
+// storage. Grafts whose parents were fetched otherwise, e.g. from another
+// remote, are not on the shallow boundary.
+func isGraft(st storer.EncodedObjectStorer, commit *object.Commit, grafts map[plumbing.Hash]struct{}) bool {
+	if _, ok := grafts[commit.Hash]; !ok {
+		return false
+	}
+
+	return slices.ContainsFunc(commit.ParentHashes, func(parent plumbing.Hash) bool {
+		return st.HasEncodedObject(parent) != nil
+	})
+}
+
+// treeComplete returns true if a tree and all objects it references exist in
+// object storage. Submodule commits are not considered.
+func treeComplete(st storer.EncodedObjectStorer, h plumbing.Hash, seen map[plumbing.Hash]struct{}) (bool, error) {
+	if _, ok := seen[h]; ok {
+		return true, nil
+	}
+
+	tree, err := object.GetTree(st, h)
+	switch {
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("resolving tree %s: %w", h, err)
+	}
+
+	for _, e := range tree.Entries {
+		switch e.Mode {
+		case filemode.Submodule:
+			continue
+		case filemode.Dir:
+			ok, err := treeComplete(st, e.Hash, seen)
+			if err != nil || !ok {
+				return false, err
+			}
+		default:
+			err := st.HasEncodedObject(e.Hash)
+			switch {
+			case errors.Is(err, plumbing.ErrObjectNotFound):
+				return false, nil
+			case err != nil:
+				return false, fmt.Errorf("resolving object %s: %w", e.Hash, err)
+			}
+		}
+	}
+	seen[h] = struct{}{}
+
+	return true, nil
+}
+
+// updateShallow records the shallow boundary commits in the local repository,
+// retaining previously shallow commits whose parents are still missing.
+func updateShallow(st storage.Storer, boundary []plumbing.Hash) error {
+	existing, err := st.Shallow()
+	if err != nil {
+		return fmt.Errorf("resolving existing shallow commits: %w", err)
+	}
+
+	shallow := slices.Clone(boundary)
+	for _, h := range existing {
+		if slices.Contains(shallow, h) {
+			continue
+		}
+
+		commit, err := object.GetCommit(st, h)
+		if err != nil {
+			return fmt.Errorf("resolving shallow commit %s: %w", h, err)
+		}
+		for _, parent := range commit.ParentHashes {
+			if err := st.HasEncodedObject(parent); err != nil {
+				// parent still missing
+				shallow = append(shallow, h)
+				break
+			}
+		}
+	}
+
+	if err := st.SetShallow(shallow); err != nil {
+		return fmt.Errorf("updating shallow commits: %w", err)
 	}
 
 	return nil
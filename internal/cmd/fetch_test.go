@@ -0,0 +1,566 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
+)
+
+// buildLinearHistory creates a repository with n linear commits, returning
+// the commit hashes oldest to newest.
+func buildLinearHistory(t *testing.T, n int) (*gogit.Repository, []plumbing.Hash) {
+	t.Helper()
+
+	builder, err := testutils.NewRepoBuilder(t.TempDir())
+	assert.NoError(t, err)
+
+	commits := make([]plumbing.Hash, 0, n)
+	for range n {
+		h, err := builder.CreateRandomCommit(64)
+		assert.NoError(t, err)
+		commits = append(commits, h)
+	}
+
+	return builder.Repo(), commits
+}
+
+// encodePack encodes a packfile containing the objects reachable from commits,
+// excluding those reachable from ignore.
+func encodePack(t *testing.T, repo *gogit.Repository, commits, ignore []plumbing.Hash) []byte {
+	t.Helper()
+
+	objs, err := revlist.Objects(repo.Storer, commits, ignore)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	_, err = packfile.NewEncoder(buf, repo.Storer, false).Encode(objs, 10)
+	assert.NoError(t, err)
+
+	return buf.Bytes()
+}
+
+func Test_walkDepth(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+
+	tests := []struct {
+		name         string
+		tips         []plumbing.Hash
+		depth        int
+		grafts       map[plumbing.Hash]struct{}
+		wantBoundary []plumbing.Hash
+		wantComplete bool
+	}{
+		{
+			name:         "Depth One",
+			tips:         []plumbing.Hash{commits[2]},
+			depth:        1,
+			wantBoundary: []plumbing.Hash{commits[2]},
+			wantComplete: true,
+		},
+		{
+			name:         "Depth Two",
+			tips:         []plumbing.Hash{commits[2]},
+			depth:        2,
+			wantBoundary: []plumbing.Hash{commits[1]},
+			wantComplete: true,
+		},
+		{
+			name:         "Depth Exceeds History",
+			tips:         []plumbing.Hash{commits[2]},
+			depth:        10,
+			wantBoundary: nil,
+			wantComplete: true,
+		},
+		{
+			// parents fetched otherwise are walked
+			name:         "Graft With Parents Present",
+			tips:         []plumbing.Hash{commits[2]},
+			depth:        10,
+			grafts:       map[plumbing.Hash]struct{}{commits[1]: {}},
+			wantBoundary: nil,
+			wantComplete: true,
+		},
+		{
+			name:         "Missing Commit",
+			tips:         []plumbing.Hash{plumbing.ComputeHash(plumbing.CommitObject, []byte("foo"))},
+			depth:        1,
+			wantBoundary: nil,
+			wantComplete: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boundary, complete, err := walkDepth(repo.Storer, tt.tips, tt.depth, tt.grafts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantComplete, complete)
+			assert.Equal(t, tt.wantBoundary, boundary)
+		})
+	}
+}
+
+func TestHandleFetch(t *testing.T) {
+	remoteRepo, commits := buildLinearHistory(t, 3)
+
+	// layer 0 holds the first two commits, layer 1 holds the last
+	pack0 := encodePack(t, remoteRepo, []plumbing.Hash{commits[1]}, nil)
+	pack1 := encodePack(t, remoteRepo, []plumbing.Hash{commits[2]}, []plumbing.Hash{commits[1]})
+	layers := []ocispec.Descriptor{
+		{Digest: digest.FromBytes(pack0), Size: int64(len(pack0))},
+		{Digest: digest.FromBytes(pack1), Size: int64(len(pack1))},
+	}
+
+	tip := plumbing.NewHashReference(plumbing.Main, commits[2])
+
+	t.Run("Success - Depth Within Newest Layer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		// the newest layer contains the full tree of the tip commit, so
+		// the older layer is never fetched
+		full := encodePack(t, remoteRepo, []plumbing.Hash{commits[2]}, nil)
+		fullLayers := []ocispec.Descriptor{
+			layers[0],
+			{Digest: digest.FromBytes(full), Size: int64(len(full))},
+		}
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(fullLayers)
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), plumbing.Main).Return([]digest.Digest{fullLayers[1].Digest}, nil)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), fullLayers[1].Digest).Return(io.NopCloser(bytes.NewReader(full)), nil).Times(1)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{Depth: 1})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+
+		shallow, err := localRepo.Storer.Shallow()
+		assert.NoError(t, err)
+		assert.Equal(t, []plumbing.Hash{commits[2]}, shallow)
+	})
+
+	t.Run("Success - Depth Spans Layers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(layers)
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), plumbing.Main).Return([]digest.Digest{layers[1].Digest}, nil)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), layers[1].Digest).Return(io.NopCloser(bytes.NewReader(pack1)), nil).Times(1)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), layers[0].Digest).Return(io.NopCloser(bytes.NewReader(pack0)), nil).Times(1)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{Depth: 2})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+
+		shallow, err := localRepo.Storer.Shallow()
+		assert.NoError(t, err)
+		assert.Equal(t, []plumbing.Hash{commits[1]}, shallow)
+	})
+
+	t.Run("Success - Exact Commit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		// git requests commits not pointed to by a remote reference by hash
+		exact := plumbing.NewHashReference(plumbing.ReferenceName(commits[1].String()), commits[1])
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(layers)
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), exact.Name()).Return(nil, model.ErrUnsupportedReferenceType)
+		modelMock.EXPECT().ResolveCommit(gomock.Any(), commits[1]).Return(layers[0].Digest, nil)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), layers[0].Digest).Return(io.NopCloser(bytes.NewReader(pack0)), nil).Times(1)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*exact})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{Depth: 1})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+
+		shallow, err := localRepo.Storer.Shallow()
+		assert.NoError(t, err)
+		assert.Equal(t, []plumbing.Hash{commits[1]}, shallow)
+	})
+
+	t.Run("Success - HEAD", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		// git fetch <remote> HEAD requests the commit of the default branch by name
+		head := plumbing.NewHashReference(plumbing.HEAD, commits[2])
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(layers)
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), plumbing.HEAD).Return([]digest.Digest{layers[1].Digest}, nil)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), layers[1].Digest).Return(io.NopCloser(bytes.NewReader(pack1)), nil).Times(1)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), layers[0].Digest).Return(io.NopCloser(bytes.NewReader(pack0)), nil).Times(1)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*head})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{Depth: 2})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+
+		shallow, err := localRepo.Storer.Shallow()
+		assert.NoError(t, err)
+		assert.Equal(t, []plumbing.Hash{commits[1]}, shallow)
+	})
+
+	t.Run("Unsupported Object Format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(formatcfg.ObjectFormat("md5"))
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{})
+		assert.ErrorIs(t, err, model.ErrUnsupportedObjectFormat)
+	})
+
+	t.Run("Success - Full History", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(layers)
+		// self-contained layers are fetched concurrently
+		modelMock.EXPECT().FetchLayer(gomock.Any(), layers[0].Digest).Return(io.NopCloser(bytes.NewReader(pack0)), nil)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), layers[1].Digest).Return(io.NopCloser(bytes.NewReader(pack1)), nil)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{IndexWorkers: 2})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+
+		_, err = localRepo.CommitObject(commits[0])
+		assert.NoError(t, err)
+
+		shallow, err := localRepo.Storer.Shallow()
+		assert.NoError(t, err)
+		assert.Empty(t, shallow)
+	})
+
+	t.Run("Success - Skip Layers Present Locally", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		indexedLayers := []ocispec.Descriptor{
+			{
+				Digest:      layers[0].Digest,
+				Size:        layers[0].Size,
+				Annotations: map[string]string{oci.AnnotationPackCommits: commits[0].String() + "," + commits[1].String()},
+			},
+			{
+				Digest:      layers[1].Digest,
+				Size:        layers[1].Size,
+				Annotations: map[string]string{oci.AnnotationPackCommits: commits[2].String()},
+			},
+		}
+
+		// the older layer was fetched from another remote
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(indexedLayers)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), indexedLayers[1].Digest).Return(io.NopCloser(bytes.NewReader(pack1)), nil)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+		err = model.UnpackPack(localRepo.Storer, bytes.NewReader(pack0), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{CheckConnectivity: true})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveConnectivityOK()
+		assert.NoError(t, err)
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Check Connectivity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(layers)
+		modelMock.EXPECT().FetchLayersReverse(gomock.Any()).Return(func(yield func(io.ReadCloser, error) bool) {
+			for _, pack := range [][]byte{pack1, pack0} {
+				if !yield(io.NopCloser(bytes.NewReader(pack)), nil) {
+					return
+				}
+			}
+		})
+
+		// in-memory storage fetches one layer at a time
+		localRepo, err := gogit.Init(memory.NewStorage(), nil)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{CheckConnectivity: true})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveConnectivityOK()
+		assert.NoError(t, err)
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Thin Packfile Missing Delta Bases", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		thinObjs, err := revlist.Objects(remoteRepo.Storer, []plumbing.Hash{commits[2]}, []plumbing.Hash{commits[1]})
+		assert.NoError(t, err)
+		bases, err := deltaBases(remoteRepo.Storer, thinObjs)
+		assert.NoError(t, err)
+		buf := new(bytes.Buffer)
+		_, err = encodeThinPack(buf, remoteRepo.Storer, thinObjs, bases)
+		assert.NoError(t, err)
+		thin := buf.Bytes()
+
+		thinLayers := []ocispec.Descriptor{
+			layers[0],
+			{
+				Digest:      digest.FromBytes(thin),
+				Size:        int64(len(thin)),
+				Annotations: map[string]string{oci.AnnotationPackBase: layers[0].Digest.String()},
+			},
+		}
+
+		// the thin layer is fetched again once its base layer is unpacked
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(thinLayers)
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), plumbing.Main).Return([]digest.Digest{thinLayers[1].Digest}, nil)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), thinLayers[1].Digest).DoAndReturn(func(_ context.Context, _ digest.Digest) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(thin)), nil
+		}).Times(2)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), thinLayers[0].Digest).Return(io.NopCloser(bytes.NewReader(pack0)), nil).Times(1)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{Depth: 1})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+
+		_, err = localRepo.CommitObject(commits[2])
+		assert.NoError(t, err)
+	})
+	t.Run("Success - Follow Tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockReadOnlyModeler(ctrl)
+
+		// the annotated tag was pushed after its commit, in its own layer
+		tagRef, err := remoteRepo.CreateTag("v1.0.0", commits[2], &gogit.CreateTagOptions{
+			Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(0, 0)},
+			Message: "v1.0.0",
+		})
+		assert.NoError(t, err)
+		tagPack := new(bytes.Buffer)
+		_, err = packfile.NewEncoder(tagPack, remoteRepo.Storer, false).Encode([]plumbing.Hash{tagRef.Hash()}, 10)
+		assert.NoError(t, err)
+
+		full := encodePack(t, remoteRepo, []plumbing.Hash{commits[2]}, nil)
+		tagLayers := []ocispec.Descriptor{
+			layers[0],
+			{Digest: digest.FromBytes(full), Size: int64(len(full))},
+			{Digest: digest.FromBytes(tagPack.Bytes()), Size: int64(tagPack.Len())},
+		}
+
+		modelMock.EXPECT().Fetch(gomock.Any()).Return(ocispec.Descriptor{}, nil)
+		modelMock.EXPECT().ObjectFormat().Return(model.SupportedObjectFormat())
+		modelMock.EXPECT().Shallow().Return(nil).AnyTimes()
+		modelMock.EXPECT().Layers().Return(tagLayers).Times(2)
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), plumbing.Main).Return([]digest.Digest{tagLayers[1].Digest}, nil)
+		modelMock.EXPECT().TagRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{
+			tagRef.Name(): {Commit: tagRef.Hash().String(), Layer: tagLayers[2].Digest, Peeled: commits[2].String()},
+			// the peeled commit was not fetched
+			"refs/tags/v0.1.0": {
+				Commit: plumbing.ComputeHash(plumbing.TagObject, []byte("v0.1.0")).String(),
+				Layer:  layers[0].Digest,
+				Peeled: plumbing.ComputeHash(plumbing.CommitObject, []byte("unfetched")).String(),
+			},
+			// lightweight
+			"refs/tags/latest": {Commit: commits[2].String(), Layer: tagLayers[1].Digest},
+		})
+		modelMock.EXPECT().FetchLayer(gomock.Any(), tagLayers[1].Digest).Return(io.NopCloser(bytes.NewReader(full)), nil).Times(1)
+		modelMock.EXPECT().FetchLayer(gomock.Any(), tagLayers[2].Digest).Return(io.NopCloser(bytes.NewReader(tagPack.Bytes())), nil).Times(1)
+
+		localRepo, err := gogit.PlainInit(t.TempDir(), false)
+		assert.NoError(t, err)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err = revcomm.SendFetchRequestBatch([]plumbing.Reference{*tip})
+		assert.NoError(t, err)
+
+		err = HandleFetch(t.Context(), git.NewRepository(localRepo), modelMock, comm, &Options{Depth: 1, FollowTags: true})
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+
+		_, err = localRepo.TagObject(tagRef.Hash())
+		assert.NoError(t, err)
+	})
+}
+
+func Test_newestRequestedLayer(t *testing.T) {
+	layers := []ocispec.Descriptor{
+		{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString("layer0")},
+		{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString("layer1")},
+		{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString("layer2")},
+	}
+	tip := plumbing.NewHashReference(plumbing.Main, plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"))
+	reqs := []gittypes.FetchRequest{{Cmd: gittypes.Fetch, Ref: tip}}
+
+	t.Run("Union of Reference Layers", func(t *testing.T) {
+		modelMock := modelmock.NewMockReadOnlyModeler(gomock.NewController(t))
+		// history of the tip pushed after it
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), plumbing.Main).Return([]digest.Digest{layers[0].Digest, layers[2].Digest}, nil)
+
+		assert.Equal(t, 2, newestRequestedLayer(t.Context(), modelMock, reqs, layers))
+	})
+
+	t.Run("Unknown Layer", func(t *testing.T) {
+		modelMock := modelmock.NewMockReadOnlyModeler(gomock.NewController(t))
+		modelMock.EXPECT().ResolveRefLayers(gomock.Any(), plumbing.Main).Return([]digest.Digest{layers[0].Digest, digest.FromString("pruned")}, nil)
+
+		assert.Equal(t, 2, newestRequestedLayer(t.Context(), modelMock, reqs, layers))
+	})
+}
@@ -9,60 +9,69 @@ import (
 
 	"github.com/act3-ai/gnoci/internal/git"
 	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/pkg/oci"
 	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
 )
 
+// peeledSuffix denotes the object an annotated tag points to in a list
+// response, e.g. "refs/tags/v1.0.0^{}".
+const peeledSuffix = "^{}"
+
 // HandleList executes the list command. Lists refs one per line.
-func HandleList(ctx context.Context, local git.Repository, remote model.Modeler, comm comms.Communicator) error {
+func HandleList(ctx context.Context, local git.Repository, remote model.Modeler, comm comms.Communicator, opts *Options) error {
 	req, err := comm.ParseListRequest()
 	if err != nil {
 		return fmt.Errorf("parsing list request: %w", err)
 	}
 	slog.DebugContext(ctx, "handling list request", slog.Bool("forPush", req.ForPush), slog.Bool("localRepoAccess", local != nil))
 
-	var headRef *plumbing.Reference
-	if !req.ForPush && local != nil {
-		// discover local HEAD ref, so we know what to resolve in the remote
-		headRef, err = local.Head()
-		if err != nil {
-			// TODO: can we assume main/master if local HEAD DNE?
-			slog.InfoContext(ctx, "local HEAD not found")
-		} else {
-			slog.InfoContext(ctx, "head ref", "target", headRef.Hash().String(), "name", headRef.Name().String())
-		}
-	}
-
 	// TODO: what about refs/remotes/<shortname>/<ref>
 
 	headRefs := remote.HeadRefs()
 	tagRefs := remote.TagRefs()
-	results := make([]gittypes.ListResponse, 0, len(headRefs)+len(tagRefs))
+	noteRefs := remote.NoteRefs()
+	results := make([]gittypes.ListResponse, 0, len(headRefs)+2*len(tagRefs)+len(noteRefs)+2)
+
+	if opts != nil && opts.ObjectFormat {
+		results = append(results, gittypes.ListResponse{ObjectFormat: remote.ObjectFormat()})
+	}
+
+	if !req.ForPush {
+		if head := remoteHead(ctx, local, remote, headRefs); head != "" {
+			slog.DebugContext(ctx, "adding symbolic reference for HEAD", slog.String("target", head.String()))
+			results = append(results, gittypes.ListResponse{Reference: plumbing.HEAD, Symref: head})
+		}
+	}
 
 	// list remote branch references
 	for k, v := range headRefs {
 		slog.DebugContext(ctx, "handling head reference", slog.String("ref", k.String()))
-		// list HEAD if one exists locally
-		if headRef != nil && (k.String() == headRef.Name().String()) {
-			slog.DebugContext(ctx, "adding head reference for HEAD")
-			result := gittypes.ListResponse{
-				// HACK: HEAD is a symbolic reference, we should update ListResponse
-				// to be a bit more generic when handling these, check git list docs
-				Reference: "HEAD",
-				Commit:    fmt.Sprintf("@%s", headRef.Name()),
-			}
-			results = append(results, result)
+		result := gittypes.ListResponse{
+			Reference: k,
+			Commit:    v.Commit,
 		}
+		results = append(results, result)
+	}
 
+	// list remote tag references, followed by the object annotated tags peel
+	// to, allowing git to follow tags of fetched commits
+	for k, v := range tagRefs {
 		result := gittypes.ListResponse{
 			Reference: k,
 			Commit:    v.Commit,
 		}
 		results = append(results, result)
+		if v.Peeled != "" {
+			results = append(results, gittypes.ListResponse{
+				Reference: k + peeledSuffix,
+				Commit:    v.Peeled,
+			})
+		}
 	}
 
-	// list remote tag references
-	for k, v := range tagRefs {
+	// list remote notes references
+	for k, v := range noteRefs {
 		result := gittypes.ListResponse{
 			Reference: k,
 			Commit:    v.Commit,
@@ -76,3 +85,28 @@ func HandleList(ctx context.Context, local git.Repository, remote model.Modeler,
 
 	return nil
 }
+
+// remoteHead resolves the branch the remote HEAD points to, preferring the
+// remote's default branch over the local HEAD. Returns an empty name if
+// neither exists in the remote.
+func remoteHead(ctx context.Context, local git.Repository, remote model.Modeler, headRefs map[plumbing.ReferenceName]oci.ReferenceInfo) plumbing.ReferenceName {
+	if branch := remote.DefaultBranch(); branch != "" {
+		return branch
+	}
+	if local == nil {
+		return ""
+	}
+
+	// fallback to the local HEAD, pushed before default branches were recorded
+	headRef, err := local.Head()
+	if err != nil {
+		slog.InfoContext(ctx, "local HEAD not found")
+		return ""
+	}
+	slog.InfoContext(ctx, "head ref", "target", headRef.Hash().String(), "name", headRef.Name().String())
+	if _, ok := headRefs[headRef.Name()]; !ok {
+		return ""
+	}
+
+	return headRef.Name()
+}
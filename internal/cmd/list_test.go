@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/act3-ai/gnoci/internal/mocks/gitmock"
@@ -11,6 +12,7 @@ import (
 	"github.com/act3-ai/gnoci/pkg/oci"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
 	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -45,6 +47,16 @@ func TestHandleList(t *testing.T) {
 			Return(expectedTags).
 			Times(1)
 
+		modelMock.EXPECT().
+			NoteRefs().
+			Return(map[plumbing.ReferenceName]oci.ReferenceInfo{}).
+			Times(1)
+
+		modelMock.EXPECT().
+			DefaultBranch().
+			Return(plumbing.ReferenceName("")).
+			Times(1)
+
 		in := new(bytes.Buffer)
 		out := new(bytes.Buffer)
 
@@ -54,13 +66,67 @@ func TestHandleList(t *testing.T) {
 		err := revcomm.SendListRequest(false)
 		assert.NoError(t, err)
 
-		err = HandleList(t.Context(), nil, modelMock, comm)
+		err = HandleList(t.Context(), nil, modelMock, comm, nil)
 		assert.NoError(t, err)
 
 		err = revcomm.ReceiveListResponse()
 		assert.NoError(t, err)
 	})
 
+	t.Run("Success - Not For Push with Default Branch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockModeler(ctrl)
+		gitMock := gitmock.NewMockRepository(ctrl)
+
+		expectedHeads := map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.ReferenceName("refs/heads/main"): {
+				Commit: "32396c14a264a71cbd47cc7a8678cebb2cdd15ed",
+				Layer:  digest.Digest("sha256:eba70958398124d1699b1d5733b916677c9bc2f7629153191eed4d7086976070"),
+			},
+			plumbing.ReferenceName("refs/heads/dev"): {
+				Commit: "32396c14a264a71cbd47cc7a8678cebb2cdd15ed",
+				Layer:  digest.Digest("sha256:eba70958398124d1699b1d5733b916677c9bc2f7629153191eed4d7086976070"),
+			},
+		}
+
+		modelMock.EXPECT().
+			HeadRefs().
+			Return(expectedHeads).
+			Times(1)
+
+		modelMock.EXPECT().
+			TagRefs().
+			Return(map[plumbing.ReferenceName]oci.ReferenceInfo{}).
+			Times(1)
+
+		modelMock.EXPECT().
+			NoteRefs().
+			Return(map[plumbing.ReferenceName]oci.ReferenceInfo{}).
+			Times(1)
+
+		// the local HEAD is not consulted
+		modelMock.EXPECT().
+			DefaultBranch().
+			Return(plumbing.ReferenceName("refs/heads/dev")).
+			Times(1)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendListRequest(false)
+		assert.NoError(t, err)
+
+		err = HandleList(t.Context(), gitMock, modelMock, comm, nil)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out.String(), "@refs/heads/dev HEAD\n"))
+
+		err = revcomm.ReceiveListResponse()
+		assert.NoError(t, err)
+	})
+
 	t.Run("Success - Not For Push with HEAD", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		modelMock := modelmock.NewMockModeler(ctrl)
@@ -92,6 +158,16 @@ func TestHandleList(t *testing.T) {
 			Return(expectedTags).
 			Times(1)
 
+		modelMock.EXPECT().
+			NoteRefs().
+			Return(map[plumbing.ReferenceName]oci.ReferenceInfo{}).
+			Times(1)
+
+		modelMock.EXPECT().
+			DefaultBranch().
+			Return(plumbing.ReferenceName("")).
+			Times(1)
+
 		gitMock.EXPECT().
 			Head().
 			Return(plumbing.NewHashReference(headRef, headHash), nil)
@@ -105,7 +181,7 @@ func TestHandleList(t *testing.T) {
 		err := revcomm.SendListRequest(false)
 		assert.NoError(t, err)
 
-		err = HandleList(t.Context(), gitMock, modelMock, comm)
+		err = HandleList(t.Context(), gitMock, modelMock, comm, nil)
 		assert.NoError(t, err)
 
 		err = revcomm.ReceiveListResponse()
@@ -143,6 +219,16 @@ func TestHandleList(t *testing.T) {
 			Return(expectedTags).
 			Times(1)
 
+		modelMock.EXPECT().
+			NoteRefs().
+			Return(map[plumbing.ReferenceName]oci.ReferenceInfo{}).
+			Times(1)
+
+		modelMock.EXPECT().
+			DefaultBranch().
+			Return(plumbing.ReferenceName("")).
+			Times(1)
+
 		gitMock.EXPECT().
 			Head().
 			Return(nil, errors.New("head not found"))
@@ -156,13 +242,63 @@ func TestHandleList(t *testing.T) {
 		err := revcomm.SendListRequest(false)
 		assert.NoError(t, err)
 
-		err = HandleList(t.Context(), gitMock, modelMock, comm)
+		err = HandleList(t.Context(), gitMock, modelMock, comm, nil)
 		assert.NoError(t, err)
 
 		err = revcomm.ReceiveListResponse()
 		assert.NoError(t, err)
 	})
 
+	t.Run("Success - Object Format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockModeler(ctrl)
+
+		modelMock.EXPECT().HeadRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+		modelMock.EXPECT().TagRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+		modelMock.EXPECT().NoteRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+		modelMock.EXPECT().ObjectFormat().Return(formatcfg.SHA256)
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+
+		_, err := in.WriteString("list for-push\n")
+		assert.NoError(t, err)
+
+		err = HandleList(t.Context(), nil, modelMock, comm, &Options{ObjectFormat: true})
+		assert.NoError(t, err)
+		assert.Equal(t, ":object-format sha256\n\n", out.String())
+	})
+
+	t.Run("Success - Peeled Tag", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		modelMock := modelmock.NewMockModeler(ctrl)
+
+		modelMock.EXPECT().HeadRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+		modelMock.EXPECT().TagRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.ReferenceName("refs/tags/v1.0.0"): {
+				Commit: "8ab686eafeb1f44702738c8b0f24f2567c36da6d",
+				Layer:  digest.Digest("sha256:eba70958398124d1699b1d5733b916677c9bc2f7629153191eed4d7086976070"),
+				Peeled: "32396c14a264a71cbd47cc7a8678cebb2cdd15ed",
+			},
+		})
+		modelMock.EXPECT().NoteRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{})
+
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+
+		_, err := in.WriteString("list for-push\n")
+		assert.NoError(t, err)
+
+		err = HandleList(t.Context(), nil, modelMock, comm, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "8ab686eafeb1f44702738c8b0f24f2567c36da6d refs/tags/v1.0.0\n"+
+			"32396c14a264a71cbd47cc7a8678cebb2cdd15ed refs/tags/v1.0.0^{}\n\n", out.String())
+	})
+
 	t.Run("Invalid List Request", func(t *testing.T) {
 		in := new(bytes.Buffer)
 		out := new(bytes.Buffer)
@@ -173,7 +309,7 @@ func TestHandleList(t *testing.T) {
 		err := revcomm.SendCapabilitiesRequest()
 		assert.NoError(t, err)
 
-		err = HandleList(t.Context(), nil, nil, comm)
+		err = HandleList(t.Context(), nil, nil, comm, nil)
 		assert.Error(t, err)
 	})
 }
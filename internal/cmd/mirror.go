@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// isMirrorPush reports whether reqs are those of a mirror push, e.g.
+// "git push --mirror", forcing at least one reference to the remote reference
+// of the same name and deleting at least one, e.g.
+//
+//	push :refs/heads/old
+//	push +refs/heads/main:refs/heads/main
+//
+// Git never forces deletions. Git does not otherwise identify mirror pushes
+// to remote helpers.
+func isMirrorPush(reqs []gittypes.PushRequest) bool {
+	var updates, deletes bool
+	for _, req := range reqs {
+		switch {
+		case req.Src == "":
+			deletes = true
+		case !req.Force, req.Src != req.Remote:
+			return false
+		default:
+			updates = true
+		}
+	}
+	return updates && deletes
+}
+
+// mirrorPrunes returns requests deleting the remote references absent both
+// locally and from reqs, if MirrorPrune is set and reqs are those of a mirror
+// push. Git only deletes the remote references it lists, such that references
+// it does not, e.g. those pushed concurrently, would otherwise remain.
+func (o *Options) mirrorPrunes(ctx context.Context, local git.Repository, remote model.Modeler, reqs []gittypes.PushRequest) []gittypes.PushRequest {
+	if o == nil || !o.MirrorPrune || !isMirrorPush(reqs) {
+		return nil
+	}
+
+	requested := make(map[plumbing.ReferenceName]struct{}, len(reqs))
+	for _, req := range reqs {
+		requested[req.Remote] = struct{}{}
+	}
+
+	var prunes []gittypes.PushRequest
+	for _, refs := range []map[plumbing.ReferenceName]oci.ReferenceInfo{remote.HeadRefs(), remote.TagRefs(), remote.NoteRefs()} {
+		for _, name := range slices.Sorted(maps.Keys(refs)) {
+			if _, ok := requested[name]; ok {
+				continue
+			}
+			_, err := local.Reference(name, false)
+			switch {
+			case errors.Is(err, plumbing.ErrReferenceNotFound):
+				slog.InfoContext(ctx, "pruning remote reference absent from mirror push", slog.String("reference", name.String()))
+				prunes = append(prunes, gittypes.PushRequest{Cmd: gittypes.Push, Force: true, Remote: name})
+			case err != nil:
+				// kept, pruning is best effort
+				slog.WarnContext(ctx, "resolving local reference for mirror pruning", slog.String("reference", name.String()), slog.String("error", err.Error()))
+			}
+		}
+	}
+
+	return prunes
+}
+
+// requestedResults returns the results of reqs, omitting those of references
+// pruned by a mirror push, which Git did not request. Pruning failures are
+// logged instead.
+func requestedResults(ctx context.Context, results []gittypes.PushResponse, reqs []gittypes.PushRequest) []gittypes.PushResponse {
+	requested := make(map[plumbing.ReferenceName]struct{}, len(reqs))
+	for _, req := range reqs {
+		requested[req.Remote] = struct{}{}
+	}
+
+	return slices.DeleteFunc(results, func(result gittypes.PushResponse) bool {
+		if _, ok := requested[result.Remote]; ok {
+			return false
+		}
+		if result.Error != nil {
+			slog.WarnContext(ctx, "failed to prune remote reference", slog.String("reference", result.Remote.String()), slog.String("error", result.Error.Error()))
+		}
+		return true
+	})
+}
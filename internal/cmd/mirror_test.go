@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+func Test_isMirrorPush(t *testing.T) {
+	update := gittypes.PushRequest{Cmd: gittypes.Push, Force: true, Src: plumbing.Main, Remote: plumbing.Main}
+	// as sent by git, which never forces deletions
+	deletion := gittypes.PushRequest{Cmd: gittypes.Push, Remote: "refs/heads/old"}
+
+	tests := []struct {
+		name string
+		reqs []gittypes.PushRequest
+		want bool
+	}{
+		{name: "Mirror", reqs: []gittypes.PushRequest{deletion, update}, want: true},
+		{name: "Mirror - Forced Deletion", reqs: []gittypes.PushRequest{{Cmd: gittypes.Push, Force: true, Remote: "refs/heads/old"}, update}, want: true},
+		{name: "No Deletes", reqs: []gittypes.PushRequest{update}},
+		{name: "Only Deletes", reqs: []gittypes.PushRequest{deletion}},
+		{name: "Not Forced", reqs: []gittypes.PushRequest{{Cmd: gittypes.Push, Src: plumbing.Main, Remote: plumbing.Main}, deletion}},
+		{name: "Renamed", reqs: []gittypes.PushRequest{{Cmd: gittypes.Push, Force: true, Src: plumbing.Main, Remote: "refs/heads/other"}, deletion}},
+		{name: "Empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isMirrorPush(tt.reqs))
+		})
+	}
+}
+
+func TestOptions_mirrorPrunes(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 1)
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.Main, commits[0])))
+	local := git.NewRepository(repo)
+
+	reqs := []gittypes.PushRequest{
+		{Cmd: gittypes.Push, Remote: "refs/heads/listed"},
+		{Cmd: gittypes.Push, Force: true, Src: plumbing.Main, Remote: plumbing.Main},
+	}
+	newRemote := func(t *testing.T) *modelmock.MockModeler {
+		t.Helper()
+		ctrl := gomock.NewController(t)
+		remote := modelmock.NewMockModeler(ctrl)
+		remote.EXPECT().HeadRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.Main:         {},
+			"refs/heads/listed":   {},
+			"refs/heads/unlisted": {},
+		}).AnyTimes()
+		remote.EXPECT().TagRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{"refs/tags/v1": {}}).AnyTimes()
+		remote.EXPECT().NoteRefs().Return(nil).AnyTimes()
+		return remote
+	}
+
+	t.Run("Pruned", func(t *testing.T) {
+		opts := &Options{MirrorPrune: true}
+		got := opts.mirrorPrunes(t.Context(), local, newRemote(t), reqs)
+		assert.Equal(t, []gittypes.PushRequest{
+			{Cmd: gittypes.Push, Force: true, Remote: "refs/heads/unlisted"},
+			{Cmd: gittypes.Push, Force: true, Remote: "refs/tags/v1"},
+		}, got)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert.Empty(t, (&Options{}).mirrorPrunes(t.Context(), local, newRemote(t), reqs))
+		assert.Empty(t, (*Options)(nil).mirrorPrunes(t.Context(), local, newRemote(t), reqs))
+	})
+
+	t.Run("Not Mirror", func(t *testing.T) {
+		opts := &Options{MirrorPrune: true}
+		assert.Empty(t, opts.mirrorPrunes(t.Context(), local, newRemote(t), reqs[1:]))
+	})
+}
+
+func Test_requestedResults(t *testing.T) {
+	reqs := []gittypes.PushRequest{{Cmd: gittypes.Push, Remote: "refs/heads/listed"}}
+	results := []gittypes.PushResponse{
+		{Remote: "refs/heads/listed"},
+		{Remote: "refs/heads/unlisted"},
+		{Remote: "refs/tags/v1", Error: assert.AnError},
+	}
+	assert.Equal(t, []gittypes.PushResponse{{Remote: "refs/heads/listed"}}, requestedResults(t.Context(), results, reqs))
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// localObjectFormat returns the hash algorithm of a local repository.
+func localObjectFormat(local git.Repository) (formatcfg.ObjectFormat, error) {
+	cfg, err := local.Config()
+	if err != nil {
+		return "", fmt.Errorf("reading local repository config: %w", err)
+	}
+
+	// go-git does not decode the extension, read it from the raw config
+	format := formatcfg.ObjectFormat(cfg.Raw.Section("extensions").Option("objectformat"))
+	if format == "" {
+		return formatcfg.DefaultObjectFormat, nil
+	}
+
+	return format, nil
+}
+
+// syncObjectFormat ensures the hash algorithm of the local repository is
+// supported and matches the remote, recording it in a new remote.
+func syncObjectFormat(ctx context.Context, local git.Repository, remote model.Modeler) error {
+	format, err := localObjectFormat(local)
+	if err != nil {
+		return err
+	}
+	if err := model.CheckObjectFormat(format); err != nil {
+		return fmt.Errorf("local repository: %w", err)
+	}
+	if err := remote.SetObjectFormat(ctx, format); err != nil {
+		return fmt.Errorf("updating remote object format: %w", err)
+	}
+
+	return nil
+}
@@ -4,15 +4,144 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"runtime"
 	"strconv"
 
+	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+
+	"github.com/act3-ai/gnoci/internal/logutil"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/scan"
 	"github.com/act3-ai/gnoci/pkg/protocol/git"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
 )
 
-// HandleOption executes an option command.
-func HandleOption(ctx context.Context, comm comms.Communicator) error {
+// Options holds the state of options set by Git with option commands. The
+// zero value is Git's default behavior.
+type Options struct {
+	// Depth limits fetched history to the given number of commits from
+	// each requested tip. Zero indicates full history.
+	Depth int
+	// Progress enables progress reporting to ProgressOut.
+	Progress bool
+	// ProgressOut is the destination of progress reports, typically stderr.
+	ProgressOut io.Writer
+	// AdviceOut is the destination of advice on rejected reference updates,
+	// typically stderr. Nil discards advice.
+	AdviceOut io.Writer
+	// DryRun reports the results of a push without updating the remote.
+	DryRun bool
+	// Atomic updates all references of a push, or none of them.
+	Atomic bool
+	// CheckConnectivity verifies the objects of a full fetch are connected,
+	// such that Git may skip its own connectivity check of a clone.
+	CheckConnectivity bool
+	// FollowTags fetches the annotated tags pointing to fetched commits,
+	// such that Git records them without fetching again.
+	FollowTags bool
+	// ObjectFormat lists the hash algorithm of the remote before its
+	// references.
+	ObjectFormat bool
+	// IndexWorkers is the maximum number of self-contained packfile layers
+	// fetched and indexed at once by full fetches. Zero defaults to
+	// GOMAXPROCS.
+	IndexWorkers int
+	// MaxPackLayerSize splits pushes into multiple packfile layers, each
+	// with objects totaling at most this many bytes uncompressed. Zero
+	// pushes a single layer.
+	MaxPackLayerSize int64
+	// Snapshot tags the pushed Git manifest for the new commit of each
+	// updated branch, pinning the state of the branch.
+	Snapshot bool
+	// PushDepth truncates the history of an initial push to the given number
+	// of commits from each pushed tip, recording the shallow boundary in the
+	// remote. Zero pushes full history.
+	PushDepth int
+	// Policy restricts the reference updates of pushes.
+	Policy Policy
+	// Scanner, if set, scans the packfiles of pushes before they are
+	// uploaded, logging findings as warnings.
+	Scanner scan.Scanner
+	// BlockFindings denies the references requiring packfiles with findings
+	// of Scanner, rather than only warning.
+	BlockFindings bool
+	// MirrorPrune deletes the remote references absent locally on mirror
+	// pushes, in addition to those deleted by Git.
+	MirrorPrune bool
+	// Leases map remote references to the commits they are expected to
+	// point to, set by push --force-with-lease. The zero hash expects the
+	// reference not to exist.
+	Leases map[plumbing.ReferenceName]plumbing.Hash
+	// Level is the dynamic log level of the helper, adjusted relative to its
+	// initial value by the verbosity option. A nil Level leaves logging
+	// unchanged.
+	Level *slog.LevelVar
+
+	// initial value of Level, before any verbosity option
+	baseLevel *slog.Level
+}
+
+// pushDepth returns the depth of an initial push, zero if unlimited.
+func (o *Options) pushDepth() int {
+	if o == nil {
+		return 0
+	}
+	return o.PushDepth
+}
+
+// indexWorkers returns the maximum number of packfile layers indexed at once.
+func (o *Options) indexWorkers() int {
+	if o == nil || o.IndexWorkers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return o.IndexWorkers
+}
+
+// maxPackLayerSize returns the maximum packfile layer size, zero if unlimited.
+func (o *Options) maxPackLayerSize() int64 {
+	if o == nil {
+		return 0
+	}
+	return o.MaxPackLayerSize
+}
+
+// applyLeases sets the lease of each push request with one.
+func (o *Options) applyLeases(reqs []git.PushRequest) []git.PushRequest {
+	if o == nil || len(o.Leases) == 0 {
+		return reqs
+	}
+	for i, req := range reqs {
+		if expected, ok := o.Leases[req.Remote]; ok {
+			reqs[i].Lease = true
+			reqs[i].Expected = expected
+		}
+	}
+	return reqs
+}
+
+// adviceOut returns the destination of advice, discarding it if unset.
+func (o *Options) adviceOut() io.Writer {
+	if o == nil || o.AdviceOut == nil {
+		return io.Discard
+	}
+	return o.AdviceOut
+}
+
+// meter returns a progress meter for an operation, discarding progress if
+// reporting is disabled.
+func (o *Options) meter(title string, total int) *meter {
+	if o == nil || !o.Progress {
+		return newMeter(io.Discard, title, total)
+	}
+	return newMeter(o.ProgressOut, title, total)
+}
+
+// HandleOption executes an option command, updating opts with the requested
+// option value.
+func HandleOption(ctx context.Context, comm comms.Communicator, opts *Options) error {
 	req, err := comm.ParseOptionRequest()
 	if err != nil {
 		return fmt.Errorf("parsing option request: %w", err)
@@ -20,7 +149,7 @@ func HandleOption(ctx context.Context, comm comms.Communicator) error {
 	log := slog.With(slog.String("command", req.String()))
 
 	// https://git-scm.com/docs/gitremote-helpers#Documentation/gitremote-helpers.txt-optionnamevalue
-	err = handleOption(ctx, req)
+	err = handleOption(ctx, req, opts)
 	switch {
 	case errors.Is(err, git.ErrUnsupportedRequest):
 		log.DebugContext(ctx, "received unsupported option command")
@@ -41,38 +170,150 @@ func HandleOption(ctx context.Context, comm comms.Communicator) error {
 	return nil
 }
 
-func handleOption(ctx context.Context, req *git.OptionRequest) error {
+func handleOption(ctx context.Context, req *git.OptionRequest, opts *Options) error {
 	slog.DebugContext(ctx, "handling option", slog.String("command", req.String()))
 
 	switch req.Opt {
 	case git.Verbosity:
-		return verbosity(req.Value)
+		return verbosity(req.Value, opts)
+	case git.Depth:
+		return depth(req.Value, opts)
+	case git.Progress:
+		return showProgress(req.Value, opts)
+	case git.DryRun:
+		return dryRun(req.Value, opts)
+	case git.Atomic:
+		return atomic(req.Value, opts)
+	case git.CheckConnectivity:
+		return checkConnectivity(req.Value, opts)
+	case git.FollowTags:
+		return followTags(req.Value, opts)
+	case git.ObjectFormat:
+		return objectFormat(req.Value, opts)
+	case git.CAS:
+		return cas(req.Value, opts)
 	default:
 		return fmt.Errorf("%w: %s", git.ErrUnsupportedRequest, req.String())
 	}
 }
 
 // verbosity handles the verbosity option.
-func verbosity(value string) error {
+func verbosity(value string, opts *Options) error {
 	val, err := strconv.Atoi(value)
 	if err != nil {
 		return fmt.Errorf("converting verbosity value to int: %w", err)
 	}
 
-	// TODO: We should tune these
-	var lvl slog.Level
-	switch {
-	case val <= 0:
-		lvl = slog.LevelError
-	case val == 1:
-		lvl = slog.LevelWarn
-	case val == 2:
-		lvl = slog.LevelInfo
-	default:
-		lvl = slog.LevelDebug
+	if opts.Level == nil {
+		return nil
+	}
+	if opts.baseLevel == nil {
+		base := opts.Level.Level()
+		opts.baseLevel = &base
 	}
+	opts.Level.Set(logutil.GitVerbosityLevel(*opts.baseLevel, val))
+
+	return nil
+}
+
+// depth handles the depth option.
+func depth(value string, opts *Options) error {
+	val, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("converting depth value to int: %w", err)
+	}
+
+	opts.Depth = val
+
+	return nil
+}
+
+// showProgress handles the progress option.
+func showProgress(value string, opts *Options) error {
+	val, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("converting progress value to bool: %w", err)
+	}
+
+	opts.Progress = val
 
-	slog.SetLogLoggerLevel(lvl)
+	return nil
+}
+
+// dryRun handles the dry-run option.
+func dryRun(value string, opts *Options) error {
+	val, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("converting dry-run value to bool: %w", err)
+	}
+
+	opts.DryRun = val
+
+	return nil
+}
+
+// atomic handles the atomic option.
+func atomic(value string, opts *Options) error {
+	val, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("converting atomic value to bool: %w", err)
+	}
+
+	opts.Atomic = val
+
+	return nil
+}
+
+// checkConnectivity handles the check-connectivity option.
+func checkConnectivity(value string, opts *Options) error {
+	val, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("converting check-connectivity value to bool: %w", err)
+	}
+
+	opts.CheckConnectivity = val
+
+	return nil
+}
+
+// followTags handles the followtags option.
+func followTags(value string, opts *Options) error {
+	val, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("converting followtags value to bool: %w", err)
+	}
+
+	opts.FollowTags = val
+
+	return nil
+}
+
+// objectFormat handles the object-format option. "true" requests the hash
+// algorithm of the remote be listed, otherwise the value is the algorithm
+// Git uses, unsupported unless gnoci was built with it.
+func objectFormat(value string, opts *Options) error {
+	if value == "true" {
+		opts.ObjectFormat = true
+		return nil
+	}
+	if err := model.CheckObjectFormat(formatcfg.ObjectFormat(value)); err != nil {
+		return fmt.Errorf("%w: %w", git.ErrUnsupportedRequest, err)
+	}
+
+	return nil
+}
+
+// cas handles the cas option, recording the lease of a reference.
+func cas(value string, opts *Options) error {
+	name, expected, err := git.ParseLease(value)
+	if err != nil {
+		return fmt.Errorf("parsing cas value: %w", err)
+	}
+
+	if opts.Leases == nil {
+		opts.Leases = make(map[plumbing.ReferenceName]plumbing.Hash)
+	}
+	opts.Leases[name] = expected
 
 	return nil
 }
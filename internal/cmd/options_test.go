@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"log/slog"
 	"testing"
 
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/act3-ai/gnoci/internal/model"
 	"github.com/act3-ai/gnoci/internal/testutils"
 	"github.com/act3-ai/gnoci/pkg/protocol/git"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
@@ -21,13 +25,171 @@ func TestHandleOption(t *testing.T) {
 		err := revcomm.SendOptionRequest(git.Verbosity, "10")
 		assert.NoError(t, err)
 
-		err = HandleOption(t.Context(), comm)
+		level := new(slog.LevelVar)
+		level.Set(slog.LevelWarn)
+		opts := &Options{Level: level}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, slog.LevelWarn-36, opts.Level.Level())
+
+		err = revcomm.ReceiveOptionResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - CAS", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		expected := plumbing.ComputeHash(plumbing.CommitObject, []byte("foo"))
+		err := revcomm.SendOptionRequest(git.CAS, "refs/heads/main:"+expected.String())
+		assert.NoError(t, err)
+
+		opts := &Options{}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, map[plumbing.ReferenceName]plumbing.Hash{"refs/heads/main": expected}, opts.Leases)
+
+		err = revcomm.ReceiveOptionResponse()
+		assert.NoError(t, err)
+
+		reqs := opts.applyLeases([]git.PushRequest{
+			{Cmd: git.Push, Force: true, Src: "refs/heads/main", Remote: "refs/heads/main"},
+			{Cmd: git.Push, Src: "refs/heads/other", Remote: "refs/heads/other"},
+		})
+		assert.True(t, reqs[0].Lease)
+		assert.Equal(t, expected, reqs[0].Expected)
+		assert.False(t, reqs[1].Lease)
+	})
+
+	t.Run("Success - Progress", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendOptionRequest(git.Progress, "true")
+		assert.NoError(t, err)
+
+		opts := &Options{}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.True(t, opts.Progress)
+
+		err = revcomm.ReceiveOptionResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Dry Run", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendOptionRequest(git.DryRun, "true")
+		assert.NoError(t, err)
+
+		opts := &Options{}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.True(t, opts.DryRun)
+
+		err = revcomm.ReceiveOptionResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Atomic", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendOptionRequest(git.Atomic, "true")
+		assert.NoError(t, err)
+
+		opts := &Options{}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.True(t, opts.Atomic)
+
+		err = revcomm.ReceiveOptionResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Check Connectivity", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendOptionRequest(git.CheckConnectivity, "true")
+		assert.NoError(t, err)
+
+		opts := &Options{}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.True(t, opts.CheckConnectivity)
+
+		err = revcomm.ReceiveOptionResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Follow Tags", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendOptionRequest(git.FollowTags, "true")
+		assert.NoError(t, err)
+
+		opts := &Options{}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.True(t, opts.FollowTags)
+
+		err = revcomm.ReceiveOptionResponse()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - Object Format", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := comms.NewCommunicator(in, out)
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := revcomm.SendOptionRequest(git.ObjectFormat, "true")
 		assert.NoError(t, err)
 
+		opts := &Options{}
+		err = HandleOption(t.Context(), comm, opts)
+		assert.NoError(t, err)
+		assert.True(t, opts.ObjectFormat)
+
 		err = revcomm.ReceiveOptionResponse()
 		assert.NoError(t, err)
 	})
 
+	t.Run("Object Format Algorithm", func(t *testing.T) {
+		req := &git.OptionRequest{Cmd: git.Options, Opt: git.ObjectFormat, Value: string(model.SupportedObjectFormat())}
+		opts := &Options{}
+		err := handleOption(t.Context(), req, opts)
+		assert.NoError(t, err)
+		assert.False(t, opts.ObjectFormat)
+
+		req.Value = "md5"
+		err = handleOption(t.Context(), req, opts)
+		assert.ErrorIs(t, err, git.ErrUnsupportedRequest)
+	})
+
 	t.Run("Success - Verbosity Info", func(t *testing.T) {
 		in := new(bytes.Buffer)
 		out := new(bytes.Buffer)
@@ -38,8 +200,12 @@ func TestHandleOption(t *testing.T) {
 		err := revcomm.SendOptionRequest(git.Verbosity, "2")
 		assert.NoError(t, err)
 
-		err = HandleOption(t.Context(), comm)
+		level := new(slog.LevelVar)
+		level.Set(slog.LevelWarn)
+		opts := &Options{Level: level}
+		err = HandleOption(t.Context(), comm, opts)
 		assert.NoError(t, err)
+		assert.Equal(t, slog.LevelInfo, opts.Level.Level())
 
 		err = revcomm.ReceiveOptionResponse()
 		assert.NoError(t, err)
@@ -55,8 +221,12 @@ func TestHandleOption(t *testing.T) {
 		err := revcomm.SendOptionRequest(git.Verbosity, "1")
 		assert.NoError(t, err)
 
-		err = HandleOption(t.Context(), comm)
+		level := new(slog.LevelVar)
+		level.Set(slog.LevelWarn)
+		opts := &Options{Level: level}
+		err = HandleOption(t.Context(), comm, opts)
 		assert.NoError(t, err)
+		assert.Equal(t, slog.LevelWarn, opts.Level.Level())
 
 		err = revcomm.ReceiveOptionResponse()
 		assert.NoError(t, err)
@@ -72,8 +242,12 @@ func TestHandleOption(t *testing.T) {
 		err := revcomm.SendOptionRequest(git.Verbosity, "-1")
 		assert.NoError(t, err)
 
-		err = HandleOption(t.Context(), comm)
+		level := new(slog.LevelVar)
+		level.Set(slog.LevelWarn)
+		opts := &Options{Level: level}
+		err = HandleOption(t.Context(), comm, opts)
 		assert.NoError(t, err)
+		assert.Equal(t, slog.LevelError+4, opts.Level.Level())
 
 		err = revcomm.ReceiveOptionResponse()
 		assert.NoError(t, err)
@@ -89,7 +263,7 @@ func TestHandleOption(t *testing.T) {
 		err := revcomm.SendOptionRequest(git.Option("foo"), "bar")
 		assert.NoError(t, err)
 
-		err = HandleOption(t.Context(), comm)
+		err = HandleOption(t.Context(), comm, &Options{})
 		assert.NoError(t, err)
 
 		err = revcomm.ReceiveOptionResponse()
@@ -106,7 +280,7 @@ func TestHandleOption(t *testing.T) {
 		err := revcomm.SendOptionRequest(git.Verbosity, "foo")
 		assert.NoError(t, err)
 
-		err = HandleOption(t.Context(), comm)
+		err = HandleOption(t.Context(), comm, &Options{})
 		assert.Error(t, err)
 	})
 }
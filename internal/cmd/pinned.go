@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+// PinnedManifestOption is the option of a remote's section of the Git
+// configuration recording the Git manifest fetched from a remote referenced
+// by digest, e.g. "remote.origin.ocimanifest".
+const PinnedManifestOption = "ocimanifest"
+
+// RecordPinned records the digest of the Git manifest fetched from remote in
+// the configuration of the local remote name, if remote is referenced by
+// digest, e.g. cloned from "oci://reg.example.com/repo@sha256:<hex>", such
+// that the exact artifact state cloned is known. Returns false if remote is
+// referenced by tag, or name is not a configured remote, e.g. a URL.
+func RecordPinned(ctx context.Context, local git.Repository, name string, remote model.ReadOnlyModeler) (bool, error) {
+	if remote.Ref().ValidateReferenceAsDigest() != nil {
+		return false, nil
+	}
+
+	cfg, err := local.Config()
+	if err != nil {
+		return false, fmt.Errorf("reading repository configuration: %w", err)
+	}
+	if _, ok := cfg.Remotes[name]; !ok {
+		return false, nil
+	}
+
+	manDesc, err := remote.Fetch(ctx)
+	if err != nil {
+		return false, fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	cfg.Raw.Section("remote").Subsection(name).SetOption(PinnedManifestOption, manDesc.Digest.String())
+	if err := local.SetConfig(cfg); err != nil {
+		return false, fmt.Errorf("writing repository configuration: %w", err)
+	}
+	return true, nil
+}
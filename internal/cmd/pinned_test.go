@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+)
+
+func TestRecordPinned(t *testing.T) {
+	manDesc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("manifest")}
+	pinned := registry.Reference{Registry: "reg.example.com", Repository: "repo", Reference: manDesc.Digest.String()}
+	tagged := registry.Reference{Registry: "reg.example.com", Repository: "repo", Reference: "main"}
+
+	// newLocal returns a repository with the remote origin configured
+	newLocal := func(t *testing.T) git.Repository {
+		t.Helper()
+		repo, _ := buildLinearHistory(t, 1)
+		_, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"oci://" + pinned.String()}})
+		assert.NoError(t, err)
+		return git.NewRepository(repo)
+	}
+
+	t.Run("Pinned", func(t *testing.T) {
+		local := newLocal(t)
+		remote := modelmock.NewMockModeler(gomock.NewController(t))
+		remote.EXPECT().Ref().Return(pinned)
+		remote.EXPECT().Fetch(gomock.Any()).Return(manDesc, nil)
+
+		ok, err := RecordPinned(t.Context(), local, "origin", remote)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		cfg, err := local.Config()
+		assert.NoError(t, err)
+		assert.Equal(t, manDesc.Digest.String(), cfg.Raw.Section("remote").Subsection("origin").Option(PinnedManifestOption))
+		assert.Equal(t, []string{"oci://" + pinned.String()}, cfg.Remotes["origin"].URLs)
+	})
+
+	t.Run("Tagged", func(t *testing.T) {
+		local := newLocal(t)
+		remote := modelmock.NewMockModeler(gomock.NewController(t))
+		remote.EXPECT().Ref().Return(tagged)
+
+		ok, err := RecordPinned(t.Context(), local, "origin", remote)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		local := newLocal(t)
+		remote := modelmock.NewMockModeler(gomock.NewController(t))
+		remote.EXPECT().Ref().Return(pinned)
+
+		ok, err := RecordPinned(t.Context(), local, "oci://"+pinned.String(), remote)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// ErrPolicyViolation indicates a push request is denied by the push policy.
+var ErrPolicyViolation = errors.New("denied by push policy")
+
+// Policy restricts the reference updates of pushes. Violating push requests
+// are rejected before anything is uploaded. The zero value allows all pushes.
+type Policy struct {
+	// ProtectedBranches are patterns of branch names, excluding the
+	// "refs/heads/" prefix, as supported by [path.Match], e.g. "release/*".
+	// Protected branches may not be deleted or rewritten.
+	ProtectedBranches []string
+	// DenyForcePush rejects all rewrites of existing references, i.e. force
+	// pushes which are not fast forwards.
+	DenyForcePush bool
+	// ImmutableTags rejects moving or deleting existing tags.
+	ImmutableTags bool
+	// MaxPackSize rejects pushes whose new objects total more than this many
+	// bytes uncompressed, failing the references requiring them. Zero allows
+	// pushes of any size.
+	MaxPackSize int64
+}
+
+// policy returns the push policy, the zero value if none.
+func (o *Options) policy() *Policy {
+	if o == nil {
+		return &Policy{}
+	}
+	return &o.Policy
+}
+
+// protected returns true if name is a protected branch.
+func (p *Policy) protected(name plumbing.ReferenceName) bool {
+	if !name.IsBranch() {
+		return false
+	}
+	for _, pattern := range p.ProtectedBranches {
+		if ok, _ := path.Match(pattern, name.Short()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequests evaluates push requests against the policy, returning the
+// allowed requests and the rejected results of the others.
+func (p *Policy) checkRequests(ctx context.Context, local git.Repository, remote model.Modeler, reqs []gittypes.PushRequest) ([]gittypes.PushRequest, []gittypes.PushResponse) {
+	if len(p.ProtectedBranches) == 0 && !p.DenyForcePush && !p.ImmutableTags {
+		return reqs, nil
+	}
+
+	allowed := make([]gittypes.PushRequest, 0, len(reqs))
+	var denied []gittypes.PushResponse
+	for _, req := range reqs {
+		if err := p.checkRequest(local, remote, req); err != nil {
+			slog.ErrorContext(ctx, "push request denied by policy", slog.String("reference", req.Remote.String()), slog.String("error", err.Error()))
+			denied = append(denied, gittypes.PushResponse{Remote: req.Remote, Error: err})
+			continue
+		}
+		allowed = append(allowed, req)
+	}
+	return allowed, denied
+}
+
+// checkRequest evaluates a push request against the policy.
+func (p *Policy) checkRequest(local git.Repository, remote model.Modeler, req gittypes.PushRequest) error {
+	current, exists := remoteCommit(remote, req.Remote)
+	if !exists {
+		return nil
+	}
+
+	deleting := req.Src == ""
+	switch {
+	case req.Remote.IsTag() && p.ImmutableTags && deleting:
+		return fmt.Errorf("%w: deleting tag %s", ErrPolicyViolation, req.Remote.Short())
+	case p.protected(req.Remote) && deleting:
+		return fmt.Errorf("%w: deleting protected branch %s", ErrPolicyViolation, req.Remote.Short())
+	case deleting:
+		return nil
+	}
+
+	localRef, err := local.Reference(req.Src, true)
+	if err != nil {
+		// reported when comparing references
+		return nil //nolint:nilerr
+	}
+	if localRef.Hash() == current {
+		return nil
+	}
+
+	switch {
+	case req.Remote.IsTag() && p.ImmutableTags:
+		return fmt.Errorf("%w: moving tag %s", ErrPolicyViolation, req.Remote.Short())
+	case !req.Force || (!p.DenyForcePush && !p.protected(req.Remote)):
+		// non fast forwards are rejected when comparing references
+		return nil
+	case !isAncestor(local, current, localRef.Hash()):
+		if p.protected(req.Remote) {
+			return fmt.Errorf("%w: rewriting protected branch %s", ErrPolicyViolation, req.Remote.Short())
+		}
+		return fmt.Errorf("%w: force pushing %s", ErrPolicyViolation, req.Remote.Short())
+	default:
+		return nil
+	}
+}
+
+// checkPackSize evaluates the total uncompressed size of the new objects of a
+// push against the policy.
+func (p *Policy) checkPackSize(st storer.EncodedObjectStorer, objs []plumbing.Hash) error {
+	if p.MaxPackSize <= 0 {
+		return nil
+	}
+
+	size, err := objsSize(st, objs)
+	if err != nil {
+		return err
+	}
+	if size > p.MaxPackSize {
+		return fmt.Errorf("%w: new objects total %d bytes, exceeding the maximum of %d", ErrPolicyViolation, size, p.MaxPackSize)
+	}
+	return nil
+}
+
+// remoteCommit returns the commit of a head or tag in the remote, and whether
+// it exists.
+func remoteCommit(remote model.Modeler, name plumbing.ReferenceName) (plumbing.Hash, bool) {
+	refs := remote.HeadRefs()
+	if name.IsTag() {
+		refs = remote.TagRefs()
+	}
+	info, ok := refs[name]
+	if !ok {
+		return plumbing.ZeroHash, false
+	}
+	return plumbing.NewHash(info.Commit), true
+}
+
+// isAncestor returns true if commit ancestor is reachable from commit. An
+// ancestor missing from the local repository is not reachable.
+func isAncestor(local git.Repository, ancestor, commit plumbing.Hash) bool {
+	ancestorCommit, err := local.CommitObject(ancestor)
+	if err != nil {
+		return false
+	}
+	c, err := local.CommitObject(commit)
+	if err != nil {
+		return false
+	}
+	ok, err := ancestorCommit.IsAncestor(c)
+	return err == nil && ok
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+func TestPolicy_checkRequests(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+	local := git.NewRepository(repo)
+
+	// local refs, named after their commit
+	for i, name := range []plumbing.ReferenceName{"refs/heads/old", "refs/heads/mid", "refs/heads/new"} {
+		err := repo.Storer.SetReference(plumbing.NewHashReference(name, commits[i]))
+		assert.NoError(t, err)
+	}
+
+	ctrl := gomock.NewController(t)
+	modelMock := modelmock.NewMockModeler(ctrl)
+	modelMock.EXPECT().HeadRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{
+		"refs/heads/main":      {Commit: commits[1].String()},
+		"refs/heads/release/1": {Commit: commits[1].String()},
+		"refs/heads/feature":   {Commit: commits[1].String()},
+	}).AnyTimes()
+	modelMock.EXPECT().TagRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{
+		"refs/tags/v1": {Commit: commits[1].String()},
+	}).AnyTimes()
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		req     gittypes.PushRequest
+		wantErr bool
+	}{
+		{
+			name:    "Protected Branch Deleted",
+			policy:  Policy{ProtectedBranches: []string{"release/*"}},
+			req:     gittypes.PushRequest{Remote: "refs/heads/release/1"},
+			wantErr: true,
+		},
+		{
+			name:    "Protected Branch Rewritten",
+			policy:  Policy{ProtectedBranches: []string{"main"}},
+			req:     gittypes.PushRequest{Force: true, Src: "refs/heads/old", Remote: "refs/heads/main"},
+			wantErr: true,
+		},
+		{
+			name:   "Protected Branch Fast Forward",
+			policy: Policy{ProtectedBranches: []string{"main"}},
+			req:    gittypes.PushRequest{Force: true, Src: "refs/heads/new", Remote: "refs/heads/main"},
+		},
+		{
+			name:   "Unprotected Branch Deleted",
+			policy: Policy{ProtectedBranches: []string{"main", "release/*"}},
+			req:    gittypes.PushRequest{Remote: "refs/heads/feature"},
+		},
+		{
+			name:    "Force Push Denied",
+			policy:  Policy{DenyForcePush: true},
+			req:     gittypes.PushRequest{Force: true, Src: "refs/heads/old", Remote: "refs/heads/feature"},
+			wantErr: true,
+		},
+		{
+			name:   "Force Push New Branch",
+			policy: Policy{DenyForcePush: true},
+			req:    gittypes.PushRequest{Force: true, Src: "refs/heads/old", Remote: "refs/heads/other"},
+		},
+		{
+			name:    "Tag Moved",
+			policy:  Policy{ImmutableTags: true},
+			req:     gittypes.PushRequest{Force: true, Src: "refs/heads/new", Remote: "refs/tags/v1"},
+			wantErr: true,
+		},
+		{
+			name:    "Tag Deleted",
+			policy:  Policy{ImmutableTags: true},
+			req:     gittypes.PushRequest{Remote: "refs/tags/v1"},
+			wantErr: true,
+		},
+		{
+			name:   "Tag Unchanged",
+			policy: Policy{ImmutableTags: true},
+			req:    gittypes.PushRequest{Src: "refs/heads/mid", Remote: "refs/tags/v1"},
+		},
+		{
+			name:   "New Tag",
+			policy: Policy{ImmutableTags: true},
+			req:    gittypes.PushRequest{Src: "refs/heads/new", Remote: "refs/tags/v2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, denied := tt.policy.checkRequests(t.Context(), local, modelMock, []gittypes.PushRequest{tt.req})
+			if tt.wantErr {
+				assert.Empty(t, allowed)
+				assert.Len(t, denied, 1)
+				assert.Equal(t, tt.req.Remote, denied[0].Remote)
+				assert.ErrorIs(t, denied[0].Error, ErrPolicyViolation)
+				return
+			}
+			assert.Equal(t, []gittypes.PushRequest{tt.req}, allowed)
+			assert.Empty(t, denied)
+		})
+	}
+}
+
+func TestPolicy_checkPackSize(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 2)
+
+	objs, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[1]}, nil)
+	assert.NoError(t, err)
+	size, err := objsSize(repo.Storer, objs)
+	assert.NoError(t, err)
+
+	t.Run("Within Limit", func(t *testing.T) {
+		p := Policy{MaxPackSize: size}
+		assert.NoError(t, p.checkPackSize(repo.Storer, objs))
+	})
+
+	t.Run("Exceeds Limit", func(t *testing.T) {
+		p := Policy{MaxPackSize: size - 1}
+		assert.ErrorIs(t, p.checkPackSize(repo.Storer, objs), ErrPolicyViolation)
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		assert.NoError(t, (&Policy{}).checkPackSize(repo.Storer, objs))
+	})
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/act3-ai/gnoci/internal/progress"
+)
+
+// progressInterval is the interval at which transfer progress is reported,
+// matching Git's native transports.
+const progressInterval = time.Second
+
+// meter writes the progress of an operation in the style of Git's native
+// transports, e.g.
+//
+//	Receiving packfile layers:  50% (1/2), 1.20 MiB | 2.40 MiB/s
+type meter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	title string
+	total int // zero if unknown
+	count int
+	bytes int64
+	start time.Time
+}
+
+// newMeter initializes a meter writing to w. A total of zero indicates the
+// total count is unknown.
+func newMeter(w io.Writer, title string, total int) *meter {
+	if w == nil {
+		w = io.Discard
+	}
+
+	return &meter{
+		w:     w,
+		title: title,
+		total: total,
+		start: time.Now(),
+	}
+}
+
+// add records n bytes transferred.
+func (m *meter) add(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bytes += int64(n)
+	m.render("")
+}
+
+// setBytes records the total number of bytes transferred.
+func (m *meter) setBytes(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bytes = n
+	m.render("")
+}
+
+// increment records the completion of n units.
+func (m *meter) increment(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.count += n
+	m.render("")
+}
+
+// done writes the final progress line.
+func (m *meter) done() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.render(", done.\n")
+}
+
+// render writes the current progress, overwriting the previous line. Callers
+// must hold m.mu.
+func (m *meter) render(suffix string) {
+	line := "\r" + m.title + ": "
+	if m.total > 0 {
+		line += fmt.Sprintf("%3d%% (%d/%d)", m.count*100/m.total, m.count, m.total)
+	} else {
+		line += fmt.Sprintf("%d", m.count)
+	}
+
+	if m.bytes > 0 {
+		line += ", " + humanizeBytes(m.bytes)
+		if elapsed := time.Since(m.start).Seconds(); elapsed > 0 {
+			line += " | " + humanizeBytes(int64(float64(m.bytes)/elapsed)) + "/s"
+		}
+	}
+
+	// progress is best effort
+	_, _ = io.WriteString(m.w, line+suffix)
+}
+
+// trackReader reports the bytes read from rc to m at [progressInterval]
+// until the returned stop function is called.
+func trackReader(ctx context.Context, rc io.ReadCloser, m *meter) (io.ReadCloser, func()) {
+	reporter := progress.NewReporter(ctx, progressInterval, func(p progress.Progress) error {
+		m.add(p.Delta)
+		return nil
+	})
+
+	// the meter never fails
+	stop := func() { _ = reporter.Stop() }
+
+	return reporter.Track(rc), stop
+}
+
+// humanizeBytes formats n bytes as Git does, e.g. "1.20 MiB".
+func humanizeBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%d.%02d GiB", n>>30, (n&(1<<30-1))*100>>30)
+	case n >= 1<<20:
+		return fmt.Sprintf("%d.%02d MiB", n>>20, (n&(1<<20-1))*100>>20)
+	case n >= 1<<10:
+		return fmt.Sprintf("%d.%02d KiB", n>>10, (n&(1<<10-1))*100>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
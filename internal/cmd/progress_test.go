@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_humanizeBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int64
+		want string
+	}{
+		{name: "Bytes", n: 512, want: "512 bytes"},
+		{name: "KiB", n: 1536, want: "1.50 KiB"},
+		{name: "MiB", n: 5 << 20, want: "5.00 MiB"},
+		{name: "GiB", n: 3<<30 + 1<<29, want: "3.50 GiB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, humanizeBytes(tt.n))
+		})
+	}
+}
+
+func Test_meter(t *testing.T) {
+	t.Run("Known Total", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		m := newMeter(out, "Receiving packfile layers", 2)
+
+		m.increment(1)
+		m.done()
+
+		lines := strings.Split(out.String(), "\r")
+		assert.Equal(t, "Receiving packfile layers:  50% (1/2), done.\n", lines[len(lines)-1])
+	})
+
+	t.Run("Unknown Total", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		m := newMeter(out, "Enumerating objects", 0)
+
+		m.increment(42)
+		m.done()
+
+		lines := strings.Split(out.String(), "\r")
+		assert.Equal(t, "Enumerating objects: 42, done.\n", lines[len(lines)-1])
+	})
+
+	t.Run("Track Reader", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		m := newMeter(out, "Receiving packfile layers", 1)
+
+		rc, stop := trackReader(t.Context(), io.NopCloser(strings.NewReader("foobar")), m)
+		_, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		stop()
+
+		assert.Equal(t, int64(6), m.bytes)
+		assert.Contains(t, out.String(), "6 bytes")
+	})
+}
@@ -4,44 +4,114 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/revlist"
 	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/logutil"
 	"github.com/act3-ai/gnoci/internal/model"
 	"github.com/act3-ai/gnoci/internal/refcomp"
+	"github.com/act3-ai/gnoci/internal/tracing"
 	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
 	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
 )
 
+// uploadingTitle is the title of push upload progress reports.
+const uploadingTitle = "Uploading packfile layers"
+
 // HandlePush executes a batch of push commands.
-func HandlePush(ctx context.Context, local git.Repository, localDir string, remote model.Modeler, comm comms.Communicator) error {
+func HandlePush(ctx context.Context, local git.Repository, localDir string, remote model.Modeler, comm comms.Communicator, opts *Options) (err error) {
+	ctx, span := tracing.Start(ctx, "HandlePush")
+	defer tracing.End(span, &err)
+
 	reqs, err := comm.ParsePushRequestBatch()
 	if err != nil {
 		return fmt.Errorf("parsing push request batch: %w", err)
 	}
+	span.SetAttributes(tracing.KeyRequests.Int(len(reqs)))
+
+	results, err := Push(ctx, local, remote, reqs, opts)
+	if err != nil {
+		return err
+	}
+	results = requestedResults(ctx, results, reqs)
+
+	if err := comm.WritePushResponse(results); err != nil {
+		return fmt.Errorf("writing push response: %w", err)
+	}
+
+	return nil
+}
+
+// Push updates the remote with a batch of push requests, returning the result
+// of each request.
+func Push(ctx context.Context, local git.Repository, remote model.Modeler, reqs []gittypes.PushRequest, opts *Options) ([]gittypes.PushResponse, error) {
+	start := time.Now()
+	logutil.Event(ctx, logutil.EventPushStart, slog.String("address", remote.Ref().String()), slog.Int("requests", len(reqs)))
+
+	if err := syncObjectFormat(ctx, local, remote); err != nil {
+		return nil, err
+	}
+
+	reqs = opts.applyLeases(reqs)
+	reqs = append(reqs, opts.mirrorPrunes(ctx, local, remote, reqs)...)
+
+	// rejected before any reference is updated in the data model
+	reqs, denied := opts.policy().checkRequests(ctx, local, remote, reqs)
 
 	// compare local refs to remote
-	newCommits, refsInNewPack, results := compareRefs(ctx, local, remote, reqs)
+	newCommits, refsInNewPack, results := compareRefs(ctx, local, remote, reqs, opts.adviceOut())
+	results = append(results, denied...)
+	if opts != nil && opts.Atomic && rejectAtomic(results) {
+		slog.InfoContext(ctx, "reference rejected in atomic push, skipping push to remote", "address", remote.Ref())
+		return results, nil
+	}
 
 	// resolve new reachable objects from new commit set
-	newReachableObjs, err := reachableObjs(local, remote, newCommits)
+	newReachableObjs, err := pushObjs(ctx, local, remote, newCommits, opts.pushDepth())
 	if err != nil {
-		return fmt.Errorf("resolving reachable objects not already in remote: %w", err)
+		return nil, err
+	}
+	if err := opts.policy().checkPackSize(local.Storer(), newReachableObjs); err != nil {
+		if !errors.Is(err, ErrPolicyViolation) {
+			return nil, err
+		}
+		// other references are unaffected, as they do not need new objects
+		denyRefs(ctx, results, refsInNewPack, err)
+		newReachableObjs, refsInNewPack = nil, nil
+		if opts != nil && opts.Atomic && rejectAtomic(results) {
+			slog.InfoContext(ctx, "reference rejected in atomic push, skipping push to remote", "address", remote.Ref())
+			return results, nil
+		}
 	}
+	enumerating := opts.meter("Enumerating objects", 0)
+	enumerating.increment(len(newReachableObjs))
+	enumerating.done()
 
-	// make temp repo for writing the packfile, without affecting the true local
-	tmpDir, err := os.MkdirTemp("", "*")
+	if opts != nil && opts.DryRun {
+		slog.InfoContext(ctx, "dry run, skipping push to remote", "address", remote.Ref(), "objects", len(newReachableObjs))
+		return results, nil
+	}
+
+	// temp directory for writing the packfile, without affecting the true local
+	tmpDir, err := os.MkdirTemp("", "gnoci-push-*")
 	if err != nil {
-		return fmt.Errorf("initializing temp directory: %w", err)
+		return nil, fmt.Errorf("initializing temp directory: %w", err)
 	}
 	defer func() {
 		if err := os.RemoveAll(tmpDir); err != nil {
@@ -49,59 +119,189 @@ func HandlePush(ctx context.Context, local git.Repository, localDir string, remo
 		}
 	}()
 
-	tmpRepo, err := gogit.PlainInitWithOptions(tmpDir, &gogit.PlainInitOptions{})
+	// large pushes are split into multiple layers, avoiding registry blob size limits
+	batches, err := partitionObjs(local.Storer(), newReachableObjs, opts.maxPackLayerSize())
 	if err != nil {
-		return fmt.Errorf("initializing temp repository for packfile storage: %w", err)
+		return nil, fmt.Errorf("partitioning objects into packfiles: %w", err)
 	}
+	if len(batches) > 1 {
+		slog.InfoContext(ctx, "splitting push into multiple packfile layers", "layers", len(batches))
+	}
+	batchRefs := refsByBatch(batches, refsInNewPack)
 
-	var thin bool
-	if len(remote.HeadRefs()) > 0 {
-		thin = true
+	writing := opts.meter("Writing objects", len(newReachableObjs))
+	var written int64
+	packPaths := make([]string, len(batches))
+	bases := make([]digest.Digest, len(batches))
+	for i, objs := range batches {
+		// encoding is not interruptible, stop between packfiles
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("writing packfiles: %w", context.Cause(ctx))
+		}
+		packPaths[i], bases[i], err = createBatchPack(filepath.Join(tmpDir, strconv.Itoa(i)), local, remote, objs)
+		if err != nil {
+			return nil, err
+		}
+
+		writing.increment(len(objs))
+		if fi, err := os.Stat(packPaths[i]); err == nil {
+			written += fi.Size()
+			writing.setBytes(written)
+		}
 	}
+	writing.done()
 
-	packHash, err := createPack(local, git.NewRepository(tmpRepo), newReachableObjs, thin)
-	if err != nil {
-		return fmt.Errorf("creating packfile: %w", err)
+	// scanned before any packfile is added, such that denied packfiles are never uploaded
+	if err := opts.scanPacks(ctx, packPaths); err != nil {
+		if !errors.Is(err, ErrPolicyViolation) {
+			return nil, err
+		}
+		denyRefs(ctx, results, refsInNewPack, err)
+		batches, newReachableObjs = nil, nil
+		if opts != nil && opts.Atomic && rejectAtomic(results) {
+			slog.InfoContext(ctx, "reference rejected in atomic push, skipping push to remote", "address", remote.Ref())
+			return results, nil
+		}
 	}
 
-	// TODO: hopefully this isn't necessary, and we can open a reader using go-git methods
-	packPath, err := filepath.Abs(path.Join(tmpDir, ".git", "objects", "pack", fmt.Sprintf("pack-%s.pack", packHash.String())))
-	if err != nil {
-		return fmt.Errorf("resolving absolute path: %w", err)
+	var prev digest.Digest
+	for i, objs := range batches {
+		commits, err := model.CommitsOf(local.Storer(), objs)
+		if err != nil {
+			return nil, err
+		}
+
+		// thin packfiles may deltify against objects of the preceding
+		// batches, only added to the remote once their packfiles were built
+		base := bases[i]
+		if base != "" && prev != "" {
+			base = prev
+		}
+		desc, err := remote.AddPack(ctx, packPaths[i], base, commits, batchRefs[i]...)
+		if err != nil && !failRefs(ctx, results, err) {
+			return nil, fmt.Errorf("adding packfile to OCI data model: %w", err)
+		}
+		prev = desc.Digest
+	}
+	if opts != nil && opts.Atomic && rejectAtomic(results) {
+		slog.InfoContext(ctx, "reference rejected in atomic push, skipping push to remote", "address", remote.Ref())
+		return results, nil
 	}
 
-	_, err = remote.AddPack(ctx, packPath, refsInNewPack...)
-	switch {
-	case errors.Is(err, model.ErrUnsupportedReferenceType):
-		// TODO: this should be reported to git, but we need to change how the errors a propagated as we need to report them by reference, not a single error
-		slog.ErrorContext(ctx, "failed to update remote with unsupported reference", slog.String("error", err.Error()))
-	case err != nil:
-		return fmt.Errorf("adding packfile to OCI data model: %w", err)
+	if err := updateDefaultBranch(ctx, local, remote); err != nil {
+		// not fatal, clones fall back to guessing
+		slog.WarnContext(ctx, "failed to update remote default branch", slog.String("error", err.Error()))
 	}
+	peelTags(ctx, local, remote, results)
 
 	var referrerUpdates []model.ReferrerUpdater
 	lfsModeler, ok := remote.(model.LFSModeler)
 	if ok {
 		referrerUpdates = append(referrerUpdates, model.UpdateLFSReferrer(lfsModeler))
 	}
+	referrerUpdates = append(referrerUpdates, model.UpdateMetadataReferrer(remote))
 
-	desc, err := remote.Push(ctx, referrerUpdates...)
+	push := remote.Push
+	if opts != nil && opts.Atomic {
+		push = remote.PushAtomic
+	}
+	uploading := opts.meter(uploadingTitle, 0) // pruned layers are not uploaded
+	if opts != nil && opts.Progress {
+		ctx = model.WithUploadTracker(ctx, func(ctx context.Context, rc io.ReadCloser) (io.ReadCloser, func()) {
+			prc, stop := trackReader(ctx, rc, uploading)
+			return prc, func() {
+				stop()
+				uploading.increment(1)
+			}
+		})
+	}
+	desc, err := push(ctx, referrerUpdates...)
 	if err != nil {
-		return fmt.Errorf("pushing to remote: %w", err)
+		return nil, fmt.Errorf("pushing to remote: %w", err)
 	}
+	uploading.done()
 	slog.InfoContext(ctx, "successfully pushed to remote", "address", remote.Ref(), "digest", desc.Digest, "size", desc.Size)
+	for _, result := range results {
+		if result.Error == nil {
+			logutil.Event(ctx, logutil.EventRefUpdate, slog.String("reference", result.Remote.String()))
+		}
+	}
+	if opts != nil && opts.Snapshot {
+		tagSnapshots(ctx, remote, results)
+	}
+	logutil.Event(ctx, logutil.EventPushDone, slog.String("address", remote.Ref().String()), slog.String("digest", desc.Digest.String()),
+		slog.Int("layers", len(batches)), slog.Int("objects", len(newReachableObjs)), slog.Int64("bytes", written), logutil.Since(start))
 
-	if err := comm.WritePushResponse(results); err != nil {
-		return fmt.Errorf("writing push response: %w", err)
+	return results, nil
+}
+
+// failRefs reports the reference errors joined in err as the results of their
+// originating push requests. It returns false if err includes other errors,
+// or errors for references not in results.
+func failRefs(ctx context.Context, results []gittypes.PushResponse, err error) bool {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
 	}
 
-	return nil
+	refErrs := make(map[plumbing.ReferenceName]error, len(errs))
+	for _, err := range errs {
+		var refErr *model.RefError
+		if !errors.As(err, &refErr) {
+			return false
+		}
+		if !slices.ContainsFunc(results, func(r gittypes.PushResponse) bool { return r.Remote == refErr.Ref }) {
+			return false
+		}
+		refErrs[refErr.Ref] = refErr.Err
+	}
+
+	for i := range results {
+		if err, ok := refErrs[results[i].Remote]; ok {
+			slog.ErrorContext(ctx, "failed to update remote reference", slog.String("reference", results[i].Remote.String()), slog.String("error", err.Error()))
+			results[i].Error = fmt.Errorf("updating remote reference: %w", err)
+		}
+	}
+	return true
+}
+
+// denyRefs fails the results of refs with err.
+func denyRefs(ctx context.Context, results []gittypes.PushResponse, refs []*plumbing.Reference, err error) {
+	for i := range results {
+		if slices.ContainsFunc(refs, func(ref *plumbing.Reference) bool { return ref.Name() == results[i].Remote }) {
+			slog.ErrorContext(ctx, "push request denied by policy", slog.String("reference", results[i].Remote.String()), slog.String("error", err.Error()))
+			results[i].Error = err
+		}
+	}
+}
+
+// tagSnapshots tags a snapshot of the pushed Git manifest for each updated
+// branch. Failures are not fatal, as the references are already updated.
+func tagSnapshots(ctx context.Context, remote model.Modeler, results []gittypes.PushResponse) {
+	heads := remote.HeadRefs()
+	for _, result := range results {
+		if result.Error != nil || !result.Remote.IsBranch() {
+			continue
+		}
+		if _, ok := heads[result.Remote]; !ok {
+			// deleted
+			continue
+		}
+
+		tag, err := remote.TagSnapshot(ctx, result.Remote)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to tag branch snapshot", slog.String("reference", result.Remote.String()), slog.String("error", err.Error()))
+			continue
+		}
+		slog.InfoContext(ctx, "tagged branch snapshot", slog.String("reference", result.Remote.String()), slog.String("tag", tag))
+	}
 }
 
 // compareRefs compares all references in the set of push cmds between the local
 // and remote repositories, returning a set of new commit hashes, references to
 // commits in the to-be-created packfile, and a list of results to be written to Git.
-func compareRefs(ctx context.Context, local git.Repository, remote model.Modeler, reqs []gittypes.PushRequest) ([]plumbing.Hash, []*plumbing.Reference, []gittypes.PushResponse) {
+// Advice on resolving rejected updates is written to adviceOut.
+func compareRefs(ctx context.Context, local git.Repository, remote model.Modeler, reqs []gittypes.PushRequest, adviceOut io.Writer) ([]plumbing.Hash, []*plumbing.Reference, []gittypes.PushResponse) {
 	rc := refcomp.NewCachedRefComparer(local, remote)
 
 	// resolve state of refs in remote
@@ -109,7 +309,20 @@ func compareRefs(ctx context.Context, local git.Repository, remote model.Modeler
 	refsInNewPack := make([]*plumbing.Reference, 0) // len <= newCommites
 	results := make([]gittypes.PushResponse, 0, len(reqs))
 	for _, req := range reqs {
-		rp, err := rc.Compare(ctx, req.Force, req.Src, req.Remote)
+		rp, err := rc.Compare(ctx, req)
+		if errors.Is(err, refcomp.ErrStaleLease) {
+			// reported by Git as a rejected stale info update
+			slog.InfoContext(ctx, "rejecting push, lease does not hold", slog.String("error", err.Error()))
+			results = append(results, gittypes.PushResponse{Remote: req.Remote, Error: refcomp.ErrStaleLease})
+			continue
+		}
+		if derr := (*refcomp.DivergedError)(nil); errors.As(err, &derr) {
+			// reported by Git as a rejected non-fast-forward or fetch first update
+			slog.InfoContext(ctx, "rejecting push, not a fast forward", slog.String("error", err.Error()))
+			writeAdvice(ctx, adviceOut, derr.Advice())
+			results = append(results, gittypes.PushResponse{Remote: req.Remote, Error: derr.Unwrap()})
+			continue
+		}
 		if errors.Is(err, model.ErrUnsupportedReferenceType) {
 			result := gittypes.PushResponse{
 				Remote: req.Remote,
@@ -196,12 +409,244 @@ func compareRefs(ctx context.Context, local git.Repository, remote model.Modeler
 	return dedupNewCommits, refsInNewPack, results
 }
 
+// updateDefaultBranch sets the remote's default branch to the branch of the
+// local HEAD, if the remote has none or it no longer exists. The branch must
+// exist in the remote.
+func updateDefaultBranch(ctx context.Context, local git.Repository, remote model.Modeler) error {
+	if remote.DefaultBranch() != "" {
+		return nil
+	}
+
+	head, err := local.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return fmt.Errorf("resolving local HEAD: %w", err)
+	}
+	if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+		// detached HEAD
+		return nil
+	}
+	if _, ok := remote.HeadRefs()[head.Target()]; !ok {
+		return nil
+	}
+
+	return remote.SetDefaultBranch(ctx, head.Target()) //nolint:wrapcheck
+}
+
+// peelTags records the object each updated annotated tag points to, allowing
+// fetches to follow the tag. Failures are not fatal, fetches fall back to
+// following tags whose objects are fetched.
+func peelTags(ctx context.Context, local git.Repository, remote model.Modeler, results []gittypes.PushResponse) {
+	for _, result := range results {
+		if result.Error != nil || !result.Remote.IsTag() {
+			continue
+		}
+		ref, _, err := remote.ResolveRef(ctx, result.Remote)
+		if err != nil {
+			// deleted
+			continue
+		}
+
+		target, err := peel(local.Storer(), ref.Hash())
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// lightweight tag
+			continue
+		case err != nil:
+			slog.WarnContext(ctx, "failed to peel tag", slog.String("reference", result.Remote.String()), slog.String("error", err.Error()))
+			continue
+		}
+		if err := remote.SetPeeled(ctx, result.Remote, target); err != nil {
+			slog.WarnContext(ctx, "failed to record peeled tag", slog.String("reference", result.Remote.String()), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// peel resolves the non-tag object an annotated tag points to, following tags
+// of tags. Throws [plumbing.ErrObjectNotFound] if h is not a tag object.
+func peel(st storer.EncodedObjectStorer, h plumbing.Hash) (plumbing.Hash, error) {
+	tag, err := object.GetTag(st, h)
+	if err != nil {
+		return plumbing.ZeroHash, err //nolint:wrapcheck
+	}
+	for tag.TargetType == plumbing.TagObject {
+		target := tag.Target
+		tag, err = object.GetTag(st, target)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("resolving tag %s: %w", target, err)
+		}
+	}
+
+	return tag.Target, nil
+}
+
+// rejectAtomic fails all results if any failed, returning true if so.
+func rejectAtomic(results []gittypes.PushResponse) bool {
+	if !slices.ContainsFunc(results, func(r gittypes.PushResponse) bool { return r.Error != nil }) {
+		return false
+	}
+
+	for i := range results {
+		if results[i].Error == nil {
+			results[i].Error = errors.New("atomic push failed")
+		}
+	}
+
+	return true
+}
+
+// pushObjs resolves the objects to push, sorted such that identical pushes
+// produce byte-identical packfiles. An initial push with a positive depth
+// truncates history, recording the shallow boundary in the remote.
+func pushObjs(ctx context.Context, local git.Repository, remote model.Modeler, newCommits []plumbing.Hash, depth int) ([]plumbing.Hash, error) {
+	switch {
+	case depth > 0 && len(remote.Layers()) == 0:
+		objs, boundary, err := shallowObjs(local.Storer(), newCommits, depth)
+		if err != nil {
+			return nil, fmt.Errorf("resolving objects within depth %d: %w", depth, err)
+		}
+		if err := remote.SetShallow(ctx, boundary); err != nil {
+			return nil, fmt.Errorf("recording shallow boundary: %w", err)
+		}
+		slog.InfoContext(ctx, "truncating pushed history", slog.Int("depth", depth), slog.Int("shallow", len(boundary)))
+		model.SortHashes(objs)
+		return objs, nil
+	case depth > 0:
+		slog.WarnContext(ctx, "push depth only applies to the initial push, pushing full history", slog.Int("depth", depth))
+	}
+
+	objs, err := reachableObjs(ctx, local, remote, newCommits)
+	if err != nil {
+		return nil, fmt.Errorf("resolving reachable objects not already in remote: %w", err)
+	}
+	model.SortHashes(objs)
+	return objs, nil
+}
+
+// shallowObjs resolves the objects reachable from tips within depth commits,
+// returning them and the commits on the shallow boundary, whose parents are
+// excluded. Annotated tags do not count towards depth.
+func shallowObjs(st storer.EncodedObjectStorer, tips []plumbing.Hash, depth int) (objs, boundary []plumbing.Hash, err error) {
+	type entry struct {
+		hash  plumbing.Hash
+		depth int
+	}
+
+	queue := make([]entry, 0, len(tips))
+	seen := make(map[plumbing.Hash]struct{})
+	for _, tip := range tips {
+		if _, ok := seen[tip]; !ok {
+			queue = append(queue, entry{hash: tip, depth: 1})
+			seen[tip] = struct{}{}
+		}
+	}
+
+	// breadth first, ensuring commits are visited at their minimum depth
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+
+		obj, err := st.EncodedObject(plumbing.AnyObject, e.hash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolving object %s: %w", e.hash, err)
+		}
+		objs = append(objs, e.hash)
+
+		switch obj.Type() { //nolint:exhaustive
+		case plumbing.TagObject:
+			tag, err := object.DecodeTag(st, obj)
+			if err != nil {
+				return nil, nil, fmt.Errorf("decoding tag %s: %w", e.hash, err)
+			}
+			if _, ok := seen[tag.Target]; !ok {
+				queue = append(queue, entry{hash: tag.Target, depth: e.depth})
+				seen[tag.Target] = struct{}{}
+			}
+			continue
+		case plumbing.TreeObject:
+			objs, err = appendTreeObjs(st, objs, e.hash, seen, false)
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+		case plumbing.CommitObject:
+		default:
+			continue
+		}
+
+		commit, err := object.DecodeCommit(st, obj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decoding commit %s: %w", e.hash, err)
+		}
+		objs, err = appendTreeObjs(st, objs, commit.TreeHash, seen, true)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if e.depth >= depth {
+			if commit.NumParents() > 0 {
+				boundary = append(boundary, commit.Hash)
+			}
+			continue
+		}
+
+		for _, parent := range commit.ParentHashes {
+			if _, ok := seen[parent]; !ok {
+				queue = append(queue, entry{hash: parent, depth: e.depth + 1})
+				seen[parent] = struct{}{}
+			}
+		}
+	}
+
+	return objs, boundary, nil
+}
+
+// appendTreeObjs appends a tree and the objects it references to objs, unless
+// already seen. Submodule commits are not included. With includeRoot false,
+// the root tree is assumed to already be in objs.
+func appendTreeObjs(st storer.EncodedObjectStorer, objs []plumbing.Hash, h plumbing.Hash, seen map[plumbing.Hash]struct{}, includeRoot bool) ([]plumbing.Hash, error) {
+	if includeRoot {
+		if _, ok := seen[h]; ok {
+			return objs, nil
+		}
+		seen[h] = struct{}{}
+		objs = append(objs, h)
+	}
+
+	tree, err := object.GetTree(st, h)
+	if err != nil {
+		return nil, fmt.Errorf("resolving tree %s: %w", h, err)
+	}
+
+	for _, e := range tree.Entries {
+		if e.Mode == filemode.Submodule {
+			continue
+		}
+		if _, ok := seen[e.Hash]; ok {
+			continue
+		}
+		if e.Mode == filemode.Dir {
+			objs, err = appendTreeObjs(st, objs, e.Hash, seen, true)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		seen[e.Hash] = struct{}{}
+		objs = append(objs, e.Hash)
+	}
+
+	return objs, nil
+}
+
 // reachableObjs resolves ALL commits reachable from newCommits, excluding those
-// existing in the remote.
-func reachableObjs(local git.Repository, remote model.Modeler, newCommits []plumbing.Hash) ([]plumbing.Hash, error) {
+// existing in the remote. Commits indexed by the remote are excluded even when
+// not reachable from a remote reference, e.g. the unchanged history of a force
+// push whose previous tip is missing locally.
+func reachableObjs(ctx context.Context, local git.Repository, remote model.Modeler, newCommits []plumbing.Hash) ([]plumbing.Hash, error) {
 	headRefs := remote.HeadRefs()
 	tagRefs := remote.TagRefs()
-	ignoreCommits := make([]plumbing.Hash, 0, len(tagRefs)+len(headRefs))
+	noteRefs := remote.NoteRefs()
+	ignoreCommits := make([]plumbing.Hash, 0, len(tagRefs)+len(headRefs)+len(noteRefs))
 
 	for _, refInfo := range headRefs {
 		ignoreCommits = append(ignoreCommits, plumbing.NewHash(refInfo.Commit)) // TODO: is NewHash what we want?
@@ -210,6 +655,20 @@ func reachableObjs(local git.Repository, remote model.Modeler, newCommits []plum
 	for _, refInfo := range tagRefs {
 		ignoreCommits = append(ignoreCommits, plumbing.NewHash(refInfo.Commit))
 	}
+	for _, refInfo := range noteRefs {
+		ignoreCommits = append(ignoreCommits, plumbing.NewHash(refInfo.Commit))
+	}
+
+	if len(remote.Layers()) > 0 {
+		known, err := knownCommits(ctx, local.Storer(), remote, newCommits, ignoreCommits)
+		if err != nil {
+			return nil, err
+		}
+		if len(known) > 0 {
+			slog.DebugContext(ctx, "excluding history already in remote", slog.Int("commits", len(known)))
+		}
+		ignoreCommits = append(ignoreCommits, known...)
+	}
 
 	newReachableObjs, err := revlist.Objects(local.Storer(), newCommits, ignoreCommits)
 	if err != nil {
@@ -219,8 +678,135 @@ func reachableObjs(local git.Repository, remote model.Modeler, newCommits []plum
 	return newReachableObjs, nil
 }
 
+// knownCommits walks the local history of tips, returning the first commits
+// along each path which the remote holds in a packfile layer. The walk stops
+// at the commits of remote references, whose history is already excluded.
+func knownCommits(ctx context.Context, st storer.EncodedObjectStorer, remote model.Modeler, tips, remoteCommits []plumbing.Hash) ([]plumbing.Hash, error) {
+	seen := make(map[plumbing.Hash]struct{}, len(remoteCommits))
+	for _, h := range remoteCommits {
+		seen[h] = struct{}{}
+	}
+
+	var known []plumbing.Hash
+	queue := make([]plumbing.Hash, 0, len(tips))
+	for _, tip := range tips {
+		if _, ok := seen[tip]; !ok {
+			queue = append(queue, tip)
+			seen[tip] = struct{}{}
+		}
+	}
+	for len(queue) > 0 {
+		h := queue[0]
+		queue = queue[1:]
+
+		_, err := remote.ResolveCommit(ctx, h)
+		switch {
+		case err == nil:
+			known = append(known, h)
+			continue
+		case !errors.Is(err, model.ErrCommitNotFound):
+			return nil, fmt.Errorf("resolving commit %s in remote: %w", h, err)
+		}
+
+		obj, err := st.EncodedObject(plumbing.AnyObject, h)
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// shallow local history
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("resolving object %s: %w", h, err)
+		}
+
+		var next []plumbing.Hash
+		switch obj.Type() { //nolint:exhaustive
+		case plumbing.TagObject:
+			tag, err := object.DecodeTag(st, obj)
+			if err != nil {
+				return nil, fmt.Errorf("decoding tag %s: %w", h, err)
+			}
+			next = []plumbing.Hash{tag.Target}
+		case plumbing.CommitObject:
+			commit, err := object.DecodeCommit(st, obj)
+			if err != nil {
+				return nil, fmt.Errorf("decoding commit %s: %w", h, err)
+			}
+			next = commit.ParentHashes
+		}
+		for _, parent := range next {
+			if _, ok := seen[parent]; !ok {
+				queue = append(queue, parent)
+				seen[parent] = struct{}{}
+			}
+		}
+	}
+
+	return known, nil
+}
+
+// createBatchPack builds a packfile of a batch of objects in dir, returning its
+// path and, for a thin packfile, the newest layer of the remote. Delta bases
+// are within it, or within the batches pushed before.
+func createBatchPack(dir string, local git.Repository, remote model.Modeler, objs []plumbing.Hash) (string, digest.Digest, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", "", fmt.Errorf("creating packfile directory: %w", err)
+	}
+
+	// objects are deltified against those in the remote where possible
+	bases, err := deltaBases(local.Storer(), objs)
+	if err != nil {
+		return "", "", fmt.Errorf("resolving delta bases: %w", err)
+	}
+
+	// delta bases beyond the shallow boundary of a truncated history may not
+	// exist in the remote, such remotes only hold self-contained packfiles
+	layers := remote.Layers()
+	if len(bases) > 0 && len(layers) > 0 && len(remote.Shallow()) == 0 {
+		// bases are within the current layers, but we don't know which
+		packPath, err := createThinPack(dir, local, objs, bases)
+		if err != nil {
+			return "", "", fmt.Errorf("creating thin packfile: %w", err)
+		}
+		return packPath, layers[len(layers)-1].Digest, nil
+	}
+
+	packPath, err := createFullPack(dir, local, objs)
+	if err != nil {
+		return "", "", err
+	}
+
+	return packPath, "", nil
+}
+
+// createFullPack builds a self-contained packfile in dir using a set of
+// hashes, returning its path.
+func createFullPack(dir string, local git.Repository, hashes []plumbing.Hash) (string, error) {
+	// make temp repo for writing the packfile, without affecting the true local
+	tmpRepo, err := gogit.PlainInitWithOptions(dir, &gogit.PlainInitOptions{})
+	if err != nil {
+		return "", fmt.Errorf("initializing temp repository for packfile storage: %w", err)
+	}
+
+	packHash, err := createPack(local, git.NewRepository(tmpRepo), hashes)
+	if err != nil {
+		return "", fmt.Errorf("creating packfile: %w", err)
+	}
+
+	// locate the packfile within the git directory of the temp repository,
+	// rather than assuming its layout and path separator
+	st, ok := tmpRepo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", fmt.Errorf("temp repository storer is not a filesystem.Storage")
+	}
+	packPath, err := filepath.Abs(filepath.Join(st.Filesystem().Root(), "objects", "pack", fmt.Sprintf("pack-%s.pack", packHash.String())))
+	if err != nil {
+		return "", fmt.Errorf("resolving absolute path: %w", err)
+	}
+
+	return packPath, nil
+}
+
 // createPack builds a packfile using a set of hashes.
-func createPack(local, tmp git.Repository, hashes []plumbing.Hash, thin bool) (h plumbing.Hash, err error) {
+func createPack(local, tmp git.Repository, hashes []plumbing.Hash) (h plumbing.Hash, err error) {
 	// reference implementation: https://github.com/go-git/go-git/blob/v5.16.2/repository.go#L1815
 	pfw, ok := tmp.Storer().(storer.PackfileWriter)
 	if !ok {
@@ -232,14 +818,40 @@ func createPack(local, tmp git.Repository, hashes []plumbing.Hash, thin bool) (h
 	}
 	defer wc.Close()
 
-	// If we're creating a thin packfile we MUST use OBJ_REF_DELTA,
 	// a fully qualified packfile can use OBJ_OFS_DELTA to save a little space
 	// via shorter headers and is faster for git to read it.
-	enc := packfile.NewEncoder(wc, local.Storer(), thin)
-	h, err = enc.Encode(hashes, 10) // git's default window, https://git-scm.com/docs/git-pack-objects#Documentation/git-pack-objects.txt---windown
+	enc := packfile.NewEncoder(wc, local.Storer(), false)
+	h, err = enc.Encode(hashes, model.PackWindow)
 	if err != nil {
 		return h, fmt.Errorf("encoding packfile: %w", err)
 	}
 
 	return h, nil
 }
+
+// createThinPack builds a thin packfile in dir using a set of hashes,
+// deltifying objects against bases outside of the packfile. Returns the
+// packfile path.
+func createThinPack(dir string, local git.Repository, hashes []plumbing.Hash, bases map[plumbing.Hash]plumbing.Hash) (string, error) {
+	f, err := os.CreateTemp(dir, "*.pack")
+	if err != nil {
+		return "", fmt.Errorf("creating packfile: %w", err)
+	}
+	defer f.Close()
+
+	h, err := encodeThinPack(f, local.Storer(), hashes, bases)
+	if err != nil {
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("closing packfile: %w", err)
+	}
+
+	// match the naming of self-contained packfiles
+	packPath := filepath.Join(dir, fmt.Sprintf("pack-%s.pack", h.String()))
+	if err := os.Rename(f.Name(), packPath); err != nil {
+		return "", fmt.Errorf("renaming packfile: %w", err)
+	}
+
+	return packPath, nil
+}
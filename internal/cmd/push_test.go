@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+func Test_failRefs(t *testing.T) {
+	const (
+		main   = plumbing.ReferenceName("refs/heads/main")
+		remote = plumbing.ReferenceName("refs/remotes/origin/main")
+	)
+	newResults := func() []gittypes.PushResponse {
+		return []gittypes.PushResponse{{Remote: main}, {Remote: remote}}
+	}
+	unsupported := fmt.Errorf("%w: %s", model.ErrUnsupportedReferenceType, remote)
+
+	t.Run("Reference Errors", func(t *testing.T) {
+		results := newResults()
+		err := errors.Join(&model.RefError{Ref: remote, Err: unsupported})
+
+		assert.True(t, failRefs(t.Context(), results, err))
+		assert.NoError(t, results[0].Error)
+		assert.ErrorIs(t, results[1].Error, model.ErrUnsupportedReferenceType)
+		assert.Equal(t, "error refs/remotes/origin/main updating remote reference: unsupported reference type: refs/remotes/origin/main", results[1].String())
+	})
+
+	t.Run("Other Error", func(t *testing.T) {
+		results := newResults()
+		err := errors.Join(&model.RefError{Ref: remote, Err: unsupported}, errors.New("pushing layer"))
+
+		assert.False(t, failRefs(t.Context(), results, err))
+		assert.NoError(t, results[1].Error)
+	})
+
+	t.Run("Unknown Reference", func(t *testing.T) {
+		results := newResults()
+		err := errors.Join(&model.RefError{Ref: "refs/heads/other", Err: unsupported})
+
+		assert.False(t, failRefs(t.Context(), results, err))
+	})
+}
+
+func Test_peelTags(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 1)
+	tagger := &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(0, 0)}
+	annotated, err := repo.CreateTag("v1.0.0", commits[0], &gogit.CreateTagOptions{Tagger: tagger, Message: "v1.0.0"})
+	assert.NoError(t, err)
+	// a tag of the annotated tag peels to the commit
+	nested, err := repo.CreateTag("v1.0.0-signed", annotated.Hash(), &gogit.CreateTagOptions{Tagger: tagger, Message: "v1.0.0-signed"})
+	assert.NoError(t, err)
+	lightweight := plumbing.NewHashReference("refs/tags/latest", commits[0])
+
+	ctrl := gomock.NewController(t)
+	modelMock := modelmock.NewMockModeler(ctrl)
+	for _, ref := range []*plumbing.Reference{annotated, nested, lightweight} {
+		modelMock.EXPECT().ResolveRef(gomock.Any(), ref.Name()).Return(ref, digest.Digest(""), nil)
+	}
+	modelMock.EXPECT().ResolveRef(gomock.Any(), plumbing.ReferenceName("refs/tags/deleted")).Return(nil, digest.Digest(""), model.ErrReferenceNotFound)
+	modelMock.EXPECT().SetPeeled(gomock.Any(), annotated.Name(), commits[0]).Return(nil)
+	modelMock.EXPECT().SetPeeled(gomock.Any(), nested.Name(), commits[0]).Return(nil)
+
+	peelTags(t.Context(), git.NewRepository(repo), modelMock, []gittypes.PushResponse{
+		{Remote: annotated.Name()},
+		{Remote: nested.Name()},
+		{Remote: lightweight.Name()},
+		{Remote: "refs/tags/deleted"},
+		{Remote: "refs/tags/rejected", Error: errors.New("rejected")},
+		{Remote: plumbing.Main},
+	})
+}
+
+func Test_createFullPack(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+	local := git.NewRepository(repo)
+
+	objs, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[2]}, nil)
+	assert.NoError(t, err)
+
+	t.Run("Deterministic", func(t *testing.T) {
+		// objects resolved in any order produce a byte-identical packfile
+		var packs [][]byte
+		for i := range 3 {
+			shuffled := slices.Clone(objs)
+			rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			model.SortHashes(shuffled)
+
+			packPath, err := createFullPack(filepath.Join(t.TempDir(), strconv.Itoa(i)), local, shuffled)
+			assert.NoError(t, err)
+			pack, err := os.ReadFile(packPath)
+			assert.NoError(t, err)
+			packs = append(packs, pack)
+		}
+		assert.Equal(t, packs[0], packs[1])
+		assert.Equal(t, packs[0], packs[2])
+	})
+}
+
+func Test_reachableObjs(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+	local := git.NewRepository(repo)
+	layer := digest.FromString("layer")
+
+	// commits[2] is a rewrite of the remote tip, which is missing locally
+	remoteTip := plumbing.NewHash("1111111111111111111111111111111111111111")
+	ctrl := gomock.NewController(t)
+	modelMock := modelmock.NewMockModeler(ctrl)
+	modelMock.EXPECT().HeadRefs().Return(map[plumbing.ReferenceName]oci.ReferenceInfo{
+		plumbing.Main: {Commit: remoteTip.String(), Layer: layer},
+	})
+	modelMock.EXPECT().TagRefs().Return(nil)
+	modelMock.EXPECT().NoteRefs().Return(nil)
+	modelMock.EXPECT().Layers().Return([]ocispec.Descriptor{{Digest: layer}})
+	modelMock.EXPECT().ResolveCommit(gomock.Any(), commits[2]).Return(digest.Digest(""), model.ErrCommitNotFound)
+	modelMock.EXPECT().ResolveCommit(gomock.Any(), commits[1]).Return(layer, nil)
+
+	objs, err := reachableObjs(t.Context(), local, modelMock, []plumbing.Hash{commits[2]})
+	assert.NoError(t, err)
+
+	// only the objects of the rewritten commit are pushed
+	want, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[2]}, []plumbing.Hash{commits[1]})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, want, objs)
+	assert.NotContains(t, objs, commits[0])
+	assert.NotContains(t, objs, commits[1])
+}
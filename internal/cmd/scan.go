@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/act3-ai/gnoci/internal/scan"
+)
+
+// scanPacks scans the packfiles of a push with the scanner of opts, if any.
+// Findings are logged as warnings, or deny the push if blocking, returning an
+// [ErrPolicyViolation].
+func (o *Options) scanPacks(ctx context.Context, packPaths []string) error {
+	if o == nil || o.Scanner == nil {
+		return nil
+	}
+
+	var findings []scan.Finding
+	for _, packPath := range packPaths {
+		f, err := o.Scanner.ScanPack(ctx, packPath)
+		if err != nil {
+			return fmt.Errorf("scanning packfile: %w", err)
+		}
+		findings = append(findings, f...)
+	}
+
+	for _, f := range findings {
+		slog.WarnContext(ctx, "suspected secret in pushed objects", slog.String("rule", f.Rule), slog.String("location", f.String()))
+	}
+	if len(findings) > 0 && o.BlockFindings {
+		return fmt.Errorf("%w: %d suspected secrets found in pushed objects", ErrPolicyViolation, len(findings))
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/scan"
+)
+
+// fakeScanner is a [scan.Scanner] finding one secret in each packfile.
+type fakeScanner struct {
+	scanned []string
+}
+
+func (s *fakeScanner) ScanPack(_ context.Context, packPath string) ([]scan.Finding, error) {
+	s.scanned = append(s.scanned, packPath)
+	return []scan.Finding{{Rule: "fake", Type: plumbing.BlobObject, Line: 1}}, nil
+}
+
+func TestOptions_scanPacks(t *testing.T) {
+	ctx := context.Background()
+	packPaths := []string{"a.pack", "b.pack"}
+
+	t.Run("No Scanner", func(t *testing.T) {
+		assert.NoError(t, (&Options{}).scanPacks(ctx, packPaths))
+		assert.NoError(t, (*Options)(nil).scanPacks(ctx, packPaths))
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		s := &fakeScanner{}
+		opts := &Options{Scanner: s}
+		assert.NoError(t, opts.scanPacks(ctx, packPaths))
+		assert.Equal(t, packPaths, s.scanned)
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		opts := &Options{Scanner: &fakeScanner{}, BlockFindings: true}
+		err := opts.scanPacks(ctx, packPaths)
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+		assert.ErrorContains(t, err, "2 suspected secrets")
+	})
+
+	t.Run("Block No Findings", func(t *testing.T) {
+		opts := &Options{Scanner: &fakeScanner{}, BlockFindings: true}
+		assert.NoError(t, opts.scanPacks(ctx, nil))
+	})
+}
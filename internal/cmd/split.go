@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// partitionObjs splits the new objects of a push into batches, each packed
+// as a separate layer, whose total uncompressed object size does not exceed
+// maxSize. Objects are grouped by the oldest new commit introducing them, and
+// batches are ordered such that each only depends on objects in the remote or
+// prior batches. Commits are never split, a batch exceeds maxSize if a single
+// commit does. A non-positive maxSize returns a single batch.
+func partitionObjs(st storer.EncodedObjectStorer, objs []plumbing.Hash, maxSize int64) ([][]plumbing.Hash, error) {
+	if maxSize <= 0 || len(objs) == 0 {
+		return [][]plumbing.Hash{objs}, nil
+	}
+
+	isNew := make(map[plumbing.Hash]struct{}, len(objs))
+	for _, h := range objs {
+		isNew[h] = struct{}{}
+	}
+
+	commits, err := sortCommits(st, objs)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := make(map[plumbing.Hash]struct{}, len(objs))
+	var batches [][]plumbing.Hash
+	var batch []plumbing.Hash
+	var batchSize int64
+	for _, commit := range commits {
+		group := []plumbing.Hash{commit.Hash}
+		assigned[commit.Hash] = struct{}{}
+		group, err = collectTree(st, commit.TreeHash, isNew, assigned, group)
+		if err != nil {
+			return nil, err
+		}
+
+		groupSize, err := objsSize(st, group)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(batch) > 0 && batchSize+groupSize > maxSize {
+			batches = append(batches, batch)
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, group...)
+		batchSize += groupSize
+	}
+
+	// objects not introduced by a commit, e.g. annotated tags, depend on the
+	// objects they point to
+	for _, h := range objs {
+		if _, ok := assigned[h]; !ok {
+			batch = append(batch, h)
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
+
+// sortCommits returns the commits among objs, parents before children.
+func sortCommits(st storer.EncodedObjectStorer, objs []plumbing.Hash) ([]*object.Commit, error) {
+	commits := make(map[plumbing.Hash]*object.Commit)
+	var tips []plumbing.Hash
+	for _, h := range objs {
+		obj, err := st.EncodedObject(plumbing.AnyObject, h)
+		if err != nil {
+			return nil, fmt.Errorf("resolving object %s: %w", h, err)
+		}
+		if obj.Type() != plumbing.CommitObject {
+			continue
+		}
+		commit, err := object.DecodeCommit(st, obj)
+		if err != nil {
+			return nil, fmt.Errorf("decoding commit %s: %w", h, err)
+		}
+		commits[h] = commit
+		tips = append(tips, h)
+	}
+
+	// iterative post-order traversal, histories may be too deep for recursion
+	type frame struct {
+		hash     plumbing.Hash
+		expanded bool
+	}
+	sorted := make([]*object.Commit, 0, len(commits))
+	visited := make(map[plumbing.Hash]struct{}, len(commits))
+	for _, tip := range tips {
+		stack := []frame{{hash: tip}}
+		for len(stack) > 0 {
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if f.expanded {
+				sorted = append(sorted, commits[f.hash])
+				continue
+			}
+			if _, ok := visited[f.hash]; ok {
+				continue
+			}
+			visited[f.hash] = struct{}{}
+
+			stack = append(stack, frame{hash: f.hash, expanded: true})
+			for _, parent := range slices.Backward(commits[f.hash].ParentHashes) {
+				if _, ok := commits[parent]; !ok {
+					// in the remote
+					continue
+				}
+				if _, ok := visited[parent]; !ok {
+					stack = append(stack, frame{hash: parent})
+				}
+			}
+		}
+	}
+
+	return sorted, nil
+}
+
+// collectTree appends the new, unassigned objects of a tree to group,
+// assigning them. Trees already in the remote or assigned are not walked.
+func collectTree(st storer.EncodedObjectStorer, root plumbing.Hash, isNew, assigned map[plumbing.Hash]struct{}, group []plumbing.Hash) ([]plumbing.Hash, error) {
+	if _, ok := isNew[root]; !ok {
+		return group, nil
+	}
+	if _, ok := assigned[root]; ok {
+		return group, nil
+	}
+	assigned[root] = struct{}{}
+	group = append(group, root)
+
+	tree, err := object.GetTree(st, root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving tree %s: %w", root, err)
+	}
+
+	for _, entry := range tree.Entries {
+		switch {
+		case entry.Mode == filemode.Submodule:
+			continue
+		case entry.Mode == filemode.Dir:
+			group, err = collectTree(st, entry.Hash, isNew, assigned, group)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			if _, ok := isNew[entry.Hash]; !ok {
+				continue
+			}
+			if _, ok := assigned[entry.Hash]; ok {
+				continue
+			}
+			assigned[entry.Hash] = struct{}{}
+			group = append(group, entry.Hash)
+		}
+	}
+
+	return group, nil
+}
+
+// objsSize returns the total uncompressed size of objs.
+func objsSize(st storer.EncodedObjectStorer, objs []plumbing.Hash) (int64, error) {
+	var size int64
+	for _, h := range objs {
+		obj, err := st.EncodedObject(plumbing.AnyObject, h)
+		if err != nil {
+			return 0, fmt.Errorf("resolving object %s: %w", h, err)
+		}
+		size += obj.Size()
+	}
+
+	return size, nil
+}
+
+// refsByBatch groups references by the batch containing the object they
+// point to. References to objects in no batch are grouped with the last.
+func refsByBatch(batches [][]plumbing.Hash, refs []*plumbing.Reference) [][]*plumbing.Reference {
+	batchOf := make(map[plumbing.Hash]int)
+	for i, batch := range batches {
+		for _, h := range batch {
+			batchOf[h] = i
+		}
+	}
+
+	grouped := make([][]*plumbing.Reference, len(batches))
+	for _, ref := range refs {
+		i, ok := batchOf[ref.Hash()]
+		if !ok {
+			i = len(batches) - 1
+		}
+		grouped[i] = append(grouped[i], ref)
+	}
+
+	return grouped
+}
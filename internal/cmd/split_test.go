@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_partitionObjs(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+
+	objs, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[2]}, nil)
+	assert.NoError(t, err)
+
+	t.Run("Unlimited", func(t *testing.T) {
+		batches, err := partitionObjs(repo.Storer, objs, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]plumbing.Hash{objs}, batches)
+	})
+
+	t.Run("Within Limit", func(t *testing.T) {
+		batches, err := partitionObjs(repo.Storer, objs, 1<<30)
+		assert.NoError(t, err)
+		assert.Len(t, batches, 1)
+		assert.ElementsMatch(t, objs, batches[0])
+	})
+
+	t.Run("Batch Per Commit", func(t *testing.T) {
+		batches, err := partitionObjs(repo.Storer, objs, 1)
+		assert.NoError(t, err)
+		assert.Len(t, batches, 3)
+
+		var all []plumbing.Hash
+		for i, batch := range batches {
+			// parents before children
+			assert.Contains(t, batch, commits[i])
+
+			// each batch is complete given those before it
+			reachable, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[i]}, nil)
+			assert.NoError(t, err)
+			all = append(all, batch...)
+			assert.Subset(t, all, reachable)
+		}
+		assert.ElementsMatch(t, objs, all)
+	})
+}
+
+func Test_refsByBatch(t *testing.T) {
+	a := plumbing.NewHash("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	b := plumbing.NewHash("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+	c := plumbing.NewHash("cccccccccccccccccccccccccccccccccccccccc")
+
+	refA := plumbing.NewHashReference("refs/heads/a", a)
+	refB := plumbing.NewHashReference("refs/heads/b", b)
+	refC := plumbing.NewHashReference("refs/tags/c", c)
+
+	got := refsByBatch([][]plumbing.Hash{{a}, {b}}, []*plumbing.Reference{refA, refB, refC})
+	assert.Equal(t, [][]*plumbing.Reference{{refA}, {refB, refC}}, got)
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/hash"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// maxDeltaObjectSize is the maximum size of an object considered for
+// deltification against a remote base, bounding memory usage.
+const maxDeltaObjectSize = 32 << 20 // 32 MiB
+
+// deltaBases resolves candidate delta bases for the new objects of a push.
+// Objects are paired by path with those in the trees of the boundary commits,
+// the parents of new commits already in the remote. The returned map is keyed
+// by new object.
+func deltaBases(st storer.EncodedObjectStorer, newObjs []plumbing.Hash) (map[plumbing.Hash]plumbing.Hash, error) {
+	isNew := make(map[plumbing.Hash]struct{}, len(newObjs))
+	for _, h := range newObjs {
+		isNew[h] = struct{}{}
+	}
+
+	// the common ancestor set
+	var newCommits []*object.Commit
+	var boundary []*object.Commit
+	seenBoundary := make(map[plumbing.Hash]struct{})
+	for _, h := range newObjs {
+		obj, err := st.EncodedObject(plumbing.AnyObject, h)
+		if err != nil {
+			return nil, fmt.Errorf("resolving object %s: %w", h, err)
+		}
+		if obj.Type() != plumbing.CommitObject {
+			continue
+		}
+		commit, err := object.DecodeCommit(st, obj)
+		if err != nil {
+			return nil, fmt.Errorf("decoding commit %s: %w", h, err)
+		}
+		newCommits = append(newCommits, commit)
+
+		for _, parent := range commit.ParentHashes {
+			if _, ok := isNew[parent]; ok {
+				continue
+			}
+			if _, ok := seenBoundary[parent]; ok {
+				continue
+			}
+			seenBoundary[parent] = struct{}{}
+
+			pc, err := object.GetCommit(st, parent)
+			switch {
+			case errors.Is(err, plumbing.ErrObjectNotFound):
+				// shallow local history
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("resolving boundary commit %s: %w", parent, err)
+			}
+			boundary = append(boundary, pc)
+		}
+	}
+
+	basesByPath := make(map[string]plumbing.Hash)
+	for _, commit := range boundary {
+		if err := walkTreePaths(st, commit.TreeHash, func(path string, h plumbing.Hash) {
+			if _, ok := basesByPath[path]; !ok {
+				basesByPath[path] = h
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	bases := make(map[plumbing.Hash]plumbing.Hash)
+	for _, commit := range newCommits {
+		if err := walkTreePaths(st, commit.TreeHash, func(path string, h plumbing.Hash) {
+			if _, ok := isNew[h]; !ok {
+				return
+			}
+			if _, ok := bases[h]; ok {
+				return
+			}
+			if base, ok := basesByPath[path]; ok && base != h {
+				bases[h] = base
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return bases, nil
+}
+
+// walkTreePaths calls fn with the path and hash of a tree, its subtrees, and
+// their blobs. Submodule commits are not visited.
+func walkTreePaths(st storer.EncodedObjectStorer, root plumbing.Hash, fn func(path string, h plumbing.Hash)) error {
+	tree, err := object.GetTree(st, root)
+	if err != nil {
+		return fmt.Errorf("resolving tree %s: %w", root, err)
+	}
+	fn("", root)
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		path, entry, err := walker.Next()
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return fmt.Errorf("walking tree %s: %w", root, err)
+		case entry.Mode == filemode.Submodule:
+			continue
+		}
+		fn(path, entry.Hash)
+	}
+}
+
+// encodeThinPack writes a thin packfile of objs to w, deltifying objects
+// against bases outside of the packfile where beneficial. Bases are keyed by
+// the object they are a candidate for. The packfile checksum is returned.
+func encodeThinPack(w io.Writer, st storer.EncodedObjectStorer, objs []plumbing.Hash, bases map[plumbing.Hash]plumbing.Hash) (plumbing.Hash, error) {
+	pw := &packWriter{w: w, hasher: hash.New(hash.CryptoType)}
+	if err := pw.writeHeader(uint32(len(objs))); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	for _, h := range objs {
+		obj, err := st.EncodedObject(plumbing.AnyObject, h)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("resolving object %s: %w", h, err)
+		}
+
+		base, ok := bases[h]
+		if ok && obj.Size() <= maxDeltaObjectSize {
+			delta, ok, err := deltaAgainst(st, obj, base)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			if ok {
+				if err := pw.writeRefDelta(base, delta); err != nil {
+					return plumbing.ZeroHash, err
+				}
+				continue
+			}
+		}
+
+		if err := pw.writeObject(obj); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	return pw.writeTrailer()
+}
+
+// deltaAgainst returns a delta of obj against a base object, if it is of the
+// same type and the delta is smaller than obj.
+func deltaAgainst(st storer.EncodedObjectStorer, obj plumbing.EncodedObject, base plumbing.Hash) ([]byte, bool, error) {
+	baseObj, err := st.EncodedObject(obj.Type(), base)
+	switch {
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, fmt.Errorf("resolving delta base %s: %w", base, err)
+	case baseObj.Type() != obj.Type(), baseObj.Size() > maxDeltaObjectSize:
+		return nil, false, nil
+	}
+
+	baseContent, err := readObject(baseObj)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := readObject(obj)
+	if err != nil {
+		return nil, false, err
+	}
+
+	delta := packfile.DiffDelta(baseContent, content)
+	if len(delta) >= len(content) {
+		return nil, false, nil
+	}
+
+	return delta, true, nil
+}
+
+// readObject reads the contents of an object.
+func readObject(obj plumbing.EncodedObject) ([]byte, error) {
+	r, err := obj.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("opening object %s: %w", obj.Hash(), err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading object %s: %w", obj.Hash(), err)
+	}
+
+	return content, nil
+}
+
+// packWriter writes packfile entries, maintaining the packfile checksum.
+//
+// Reference: https://git-scm.com/docs/pack-format
+type packWriter struct {
+	w      io.Writer
+	hasher hash.Hash
+}
+
+// Write writes p to the packfile, updating the checksum.
+func (pw *packWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.hasher.Write(p[:n]) // never returns an error
+	if err != nil {
+		return n, fmt.Errorf("writing packfile: %w", err)
+	}
+
+	return n, nil
+}
+
+func (pw *packWriter) write(p []byte) error {
+	_, err := pw.Write(p)
+	return err
+}
+
+// writeHeader writes the packfile signature, version, and object count.
+func (pw *packWriter) writeHeader(count uint32) error {
+	header := make([]byte, 0, 12)
+	header = append(header, "PACK"...)
+	header = binary.BigEndian.AppendUint32(header, 2)
+	header = binary.BigEndian.AppendUint32(header, count)
+
+	return pw.write(header)
+}
+
+// writeObject writes an undeltified object entry.
+func (pw *packWriter) writeObject(obj plumbing.EncodedObject) error {
+	if err := pw.write(entryHeader(obj.Type(), obj.Size())); err != nil {
+		return err
+	}
+
+	r, err := obj.Reader()
+	if err != nil {
+		return fmt.Errorf("opening object %s: %w", obj.Hash(), err)
+	}
+	defer r.Close()
+
+	return pw.writeCompressed(r)
+}
+
+// writeRefDelta writes a delta entry against a base object by name.
+func (pw *packWriter) writeRefDelta(base plumbing.Hash, delta []byte) error {
+	if err := pw.write(entryHeader(plumbing.REFDeltaObject, int64(len(delta)))); err != nil {
+		return err
+	}
+	if err := pw.write(base[:]); err != nil {
+		return err
+	}
+
+	return pw.writeCompressed(bytes.NewReader(delta))
+}
+
+// writeCompressed writes the zlib compressed contents of r.
+func (pw *packWriter) writeCompressed(r io.Reader) error {
+	zw := zlib.NewWriter(pw)
+	if _, err := io.Copy(zw, r); err != nil {
+		return fmt.Errorf("compressing packfile entry: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compressing packfile entry: %w", err)
+	}
+
+	return nil
+}
+
+// writeTrailer writes the packfile checksum, returning it.
+func (pw *packWriter) writeTrailer() (plumbing.Hash, error) {
+	var h plumbing.Hash
+	copy(h[:], pw.hasher.Sum(nil))
+	if _, err := pw.w.Write(h[:]); err != nil {
+		return h, fmt.Errorf("writing packfile checksum: %w", err)
+	}
+
+	return h, nil
+}
+
+// entryHeader encodes the type and uncompressed size of a packfile entry.
+func entryHeader(typ plumbing.ObjectType, size int64) []byte {
+	b := byte(typ)<<4 | byte(size&0x0f)
+	size >>= 4
+
+	header := make([]byte, 0, 10)
+	for size != 0 {
+		header = append(header, b|0x80)
+		b = byte(size & 0x7f)
+		size >>= 7
+	}
+
+	return append(header, b)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/internal/model"
+)
+
+func Test_deltaBases(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+
+	newObjs, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[2]}, []plumbing.Hash{commits[1]})
+	assert.NoError(t, err)
+
+	bases, err := deltaBases(repo.Storer, newObjs)
+	assert.NoError(t, err)
+
+	// the root tree is paired with the root tree of the parent commit
+	newCommit, err := repo.CommitObject(commits[2])
+	assert.NoError(t, err)
+	parentCommit, err := repo.CommitObject(commits[1])
+	assert.NoError(t, err)
+	assert.Equal(t, parentCommit.TreeHash, bases[newCommit.TreeHash])
+
+	t.Run("No Boundary", func(t *testing.T) {
+		allObjs, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[2]}, nil)
+		assert.NoError(t, err)
+
+		bases, err := deltaBases(repo.Storer, allObjs)
+		assert.NoError(t, err)
+		assert.Empty(t, bases)
+	})
+}
+
+func Test_encodeThinPack(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+
+	newObjs, err := revlist.Objects(repo.Storer, []plumbing.Hash{commits[2]}, []plumbing.Hash{commits[1]})
+	assert.NoError(t, err)
+
+	bases, err := deltaBases(repo.Storer, newObjs)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	_, err = encodeThinPack(buf, repo.Storer, newObjs, bases)
+	assert.NoError(t, err)
+	thin := buf.Bytes()
+
+	t.Run("Success", func(t *testing.T) {
+		st := memory.NewStorage()
+		err := model.UnpackPack(st, bytes.NewReader(encodePack(t, repo, []plumbing.Hash{commits[1]}, nil)), false)
+		assert.NoError(t, err)
+
+		err = model.UnpackPack(st, bytes.NewReader(thin), true)
+		assert.NoError(t, err)
+
+		for _, h := range newObjs {
+			assert.NoError(t, st.HasEncodedObject(h))
+		}
+	})
+
+	t.Run("Missing Delta Base", func(t *testing.T) {
+		err := model.UnpackPack(memory.NewStorage(), bytes.NewReader(thin), true)
+		assert.ErrorIs(t, err, plumbing.ErrObjectNotFound)
+	})
+}
+
+func TestUnpackPack(t *testing.T) {
+	repo, commits := buildLinearHistory(t, 3)
+	pack := encodePack(t, repo, []plumbing.Hash{commits[2]}, nil)
+
+	t.Run("Interrupted", func(t *testing.T) {
+		dir := t.TempDir()
+		localRepo, err := gogit.PlainInit(dir, true)
+		assert.NoError(t, err)
+
+		r := io.MultiReader(bytes.NewReader(pack[:len(pack)/2]), iotest.ErrReader(context.Canceled))
+		err = model.UnpackPack(localRepo.Storer, r, false)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// the partially written packfile is removed
+		packs, err := os.ReadDir(filepath.Join(dir, "objects", "pack"))
+		assert.NoError(t, err)
+		assert.Empty(t, packs)
+	})
+}
+
+func Test_entryHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  plumbing.ObjectType
+		size int64
+		want []byte
+	}{
+		{name: "Small", typ: plumbing.BlobObject, size: 10, want: []byte{0x3a}},
+		{name: "Multi Byte", typ: plumbing.CommitObject, size: 300, want: []byte{0x9c, 0x12}},
+		{name: "Ref Delta", typ: plumbing.REFDeltaObject, size: 16, want: []byte{0xf0, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, entryHeader(tt.typ, tt.size))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Package fastcdc splits content into content-defined chunks with FastCDC,
+// such that inserting or removing bytes only changes the chunks around the
+// edit, rather than every chunk after it.
+//
+// Reference: https://www.usenix.org/conference/atc16/technical-sessions/presentation/xia
+package fastcdc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Default chunk sizes.
+const (
+	// DefaultMinSize is the default minimum size of a chunk.
+	DefaultMinSize = 512 << 10 // 512 KiB
+	// DefaultAvgSize is the default average size of a chunk.
+	DefaultAvgSize = 1 << 20 // 1 MiB
+	// DefaultMaxSize is the default maximum size of a chunk.
+	DefaultMaxSize = 8 << 20 // 8 MiB
+)
+
+// ErrInvalidOptions indicates chunk sizes are not usable.
+var ErrInvalidOptions = errors.New("invalid chunking options")
+
+// Options are the sizes of chunks, in bytes. Chunks are at least MinSize and
+// at most MaxSize, except for the last chunk, which may be smaller.
+type Options struct {
+	// MinSize is the minimum size of a chunk. Defaults to [DefaultMinSize].
+	MinSize int
+	// AvgSize is the expected size of a chunk, a power of two. Defaults to
+	// [DefaultAvgSize].
+	AvgSize int
+	// MaxSize is the maximum size of a chunk. Defaults to [DefaultMaxSize].
+	MaxSize int
+}
+
+// defaulted returns opts with unset sizes defaulted.
+func (opts Options) defaulted() Options {
+	if opts.MinSize == 0 {
+		opts.MinSize = DefaultMinSize
+	}
+	if opts.AvgSize == 0 {
+		opts.AvgSize = DefaultAvgSize
+	}
+	if opts.MaxSize == 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	return opts
+}
+
+// validate returns an error if the sizes of opts are not usable.
+func (opts Options) validate() error {
+	switch {
+	case opts.MinSize < 64:
+		// the fingerprint covers the last 64 bytes
+		return fmt.Errorf("%w: minimum size %d is less than 64 bytes", ErrInvalidOptions, opts.MinSize)
+	case opts.AvgSize < opts.MinSize || opts.MaxSize < opts.AvgSize:
+		return fmt.Errorf("%w: sizes must satisfy min %d <= avg %d <= max %d", ErrInvalidOptions, opts.MinSize, opts.AvgSize, opts.MaxSize)
+	case bits.OnesCount(uint(opts.AvgSize)) != 1:
+		return fmt.Errorf("%w: average size %d is not a power of two", ErrInvalidOptions, opts.AvgSize)
+	}
+	return nil
+}
+
+// Chunker splits the content of a reader into chunks.
+type Chunker struct {
+	r    io.Reader
+	opts Options
+	// maskS is harder to match than the average, used before the average
+	// size is reached, maskL is easier, used after, normalizing chunk sizes.
+	maskS, maskL uint64
+
+	buf        []byte
+	start, end int // unread content of buf
+	eof        bool
+}
+
+// NewChunker returns a chunker of the content of r. Unset sizes of opts are
+// defaulted.
+func NewChunker(r io.Reader, opts Options) (*Chunker, error) {
+	opts = opts.defaulted()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	avgBits := bits.TrailingZeros(uint(opts.AvgSize))
+	return &Chunker{
+		r:     r,
+		opts:  opts,
+		maskS: topBits(avgBits + 1),
+		maskL: topBits(avgBits - 1),
+		buf:   make([]byte, opts.MaxSize),
+	}, nil
+}
+
+// topBits returns a mask of the n most significant bits. The most significant
+// bits of the fingerprint depend on the most bytes.
+func topBits(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
+
+// Next returns the next chunk, valid until the following call. Returns
+// [io.EOF] once all content is chunked.
+func (c *Chunker) Next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+
+	n := c.boundary(c.buf[c.start:c.end])
+	chunk := c.buf[c.start : c.start+n]
+	c.start += n
+	return chunk, nil
+}
+
+// fill reads until buf holds at least a maximum size chunk, or all remaining
+// content.
+func (c *Chunker) fill() error {
+	if c.eof || c.end-c.start >= c.opts.MaxSize {
+		return nil
+	}
+
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	for c.end < len(c.buf) {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		switch {
+		case errors.Is(err, io.EOF):
+			c.eof = true
+			return nil
+		case err != nil:
+			return fmt.Errorf("reading content: %w", err)
+		}
+	}
+	return nil
+}
+
+// boundary returns the size of the chunk at the start of b.
+func (c *Chunker) boundary(b []byte) int {
+	n := len(b)
+	if n <= c.opts.MinSize {
+		return n
+	}
+	n = min(n, c.opts.MaxSize)
+	normal := min(n, c.opts.AvgSize)
+
+	var fp uint64
+	i := c.opts.MinSize
+	for ; i < normal; i++ {
+		fp = fp<<1 + gear[b[i]]
+		if fp&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = fp<<1 + gear[b[i]]
+		if fp&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// gear maps bytes to random values rolled into the fingerprint. The values
+// must never change, as chunk boundaries, and so deduplication against
+// previously pushed chunks, depend on them.
+var gear = func() [256]uint64 {
+	// splitmix64 of a fixed seed
+	var table [256]uint64
+	state := uint64(0x6a09e667f3bcc909)
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
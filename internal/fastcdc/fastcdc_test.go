@@ -0,0 +1,130 @@
+package fastcdc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testOptions are small chunk sizes, keeping test content small.
+var testOptions = Options{MinSize: 1 << 10, AvgSize: 4 << 10, MaxSize: 16 << 10}
+
+// randomContent returns deterministic pseudo-random content of size n.
+func randomContent(seed uint64, n int) []byte {
+	r := rand.New(rand.NewPCG(seed, seed))
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(r.Uint32())
+	}
+	return b
+}
+
+// chunks returns the chunks of content, copied.
+func chunks(t *testing.T, r io.Reader, opts Options) [][]byte {
+	t.Helper()
+
+	c, err := NewChunker(r, opts)
+	assert.NoError(t, err)
+
+	var got [][]byte
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return got
+		}
+		assert.NoError(t, err)
+		got = append(got, bytes.Clone(chunk))
+	}
+}
+
+func TestChunker(t *testing.T) {
+	content := randomContent(1, 1<<20)
+
+	t.Run("Sizes", func(t *testing.T) {
+		got := chunks(t, bytes.NewReader(content), testOptions)
+		assert.Greater(t, len(got), 1<<20/testOptions.MaxSize)
+		assert.Equal(t, content, bytes.Join(got, nil))
+
+		for i, chunk := range got {
+			assert.LessOrEqual(t, len(chunk), testOptions.MaxSize)
+			if i < len(got)-1 {
+				assert.GreaterOrEqual(t, len(chunk), testOptions.MinSize)
+			}
+		}
+	})
+
+	t.Run("Independent of Reads", func(t *testing.T) {
+		want := chunks(t, bytes.NewReader(content), testOptions)
+		got := chunks(t, iotest.OneByteReader(bytes.NewReader(content)), testOptions)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Edit Shares Chunks", func(t *testing.T) {
+		edited := bytes.Clone(content)
+		edited = append(edited[:1000], append([]byte("inserted"), edited[1000:]...)...)
+
+		before := chunks(t, bytes.NewReader(content), testOptions)
+		after := chunks(t, bytes.NewReader(edited), testOptions)
+
+		seen := make(map[string]bool, len(before))
+		for _, chunk := range before {
+			seen[string(chunk)] = true
+		}
+		var shared int
+		for _, chunk := range after {
+			if seen[string(chunk)] {
+				shared++
+			}
+		}
+		// only the chunks around the edit differ
+		assert.GreaterOrEqual(t, shared, len(after)-2)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Empty(t, chunks(t, bytes.NewReader(nil), testOptions))
+	})
+
+	t.Run("Smaller Than Minimum", func(t *testing.T) {
+		got := chunks(t, bytes.NewReader(content[:100]), testOptions)
+		assert.Equal(t, [][]byte{content[:100]}, got)
+	})
+
+	t.Run("Read Error", func(t *testing.T) {
+		expectedErr := errors.New("read error")
+		c, err := NewChunker(iotest.ErrReader(expectedErr), testOptions)
+		assert.NoError(t, err)
+
+		_, err = c.Next()
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestNewChunker(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "Defaults"},
+		{name: "Custom", opts: testOptions},
+		{name: "Average Not Power of Two", opts: Options{MinSize: 1 << 10, AvgSize: 3 << 10, MaxSize: 16 << 10}, wantErr: true},
+		{name: "Minimum Above Average", opts: Options{MinSize: 8 << 10, AvgSize: 4 << 10, MaxSize: 16 << 10}, wantErr: true},
+		{name: "Maximum Below Average", opts: Options{MinSize: 1 << 10, AvgSize: 4 << 10, MaxSize: 2 << 10}, wantErr: true},
+		{name: "Minimum Too Small", opts: Options{MinSize: 32, AvgSize: 64, MaxSize: 128}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewChunker(bytes.NewReader(nil), tt.opts)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidOptions)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package filelock provides advisory locks of files shared by concurrent
+// processes, e.g. the local blob cache, using flock on Unix and LockFileEx on
+// Windows, such that locks also hold on NTFS.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Lock blocks until it holds an exclusive lock of the file at path, created
+// if it does not exist. The lock is released by calling unlock, or once the
+// process exits.
+func Lock(path string) (unlock func() error, err error) {
+	f, err := lock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return release(path, f)
+	}, nil
+}
+
+// LockTemp is Lock for lock files removed once released, e.g. per-object
+// locks that would otherwise accumulate.
+func LockTemp(path string) (unlock func() error, err error) {
+	f, err := lock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		// a lock file is removed while locked where open files may be
+		// removed, else once closed; waiters lock a removed file, then retry
+		var removeErr error
+		if removeOpen {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				removeErr = fmt.Errorf("removing lock file: %w", err)
+			}
+		}
+		err := release(path, f)
+		if !removeOpen {
+			// another process opening the file prevents its removal
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) && !isInUse(err) {
+				removeErr = fmt.Errorf("removing lock file: %w", err)
+			}
+		}
+		return errors.Join(removeErr, err)
+	}, nil
+}
+
+// lock opens and locks the file at path, retrying until the locked file is
+// the one at path, as it may be removed by LockTemp while waiting.
+func lock(path string) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening lock file: %w", err)
+		}
+		if err := lockFile(f); err != nil {
+			return nil, errors.Join(fmt.Errorf("locking %s: %w", path, err), f.Close())
+		}
+
+		same, err := isLocked(path, f)
+		switch {
+		case err != nil:
+			return nil, errors.Join(err, release(path, f))
+		case same:
+			return f, nil
+		}
+		if err := release(path, f); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isLocked returns true if f, a locked file, is still the file at path.
+func isLocked(path string, f *os.File) (bool, error) {
+	lockedInfo, err := f.Stat()
+	if err != nil {
+		return false, fmt.Errorf("inspecting lock file: %w", err)
+	}
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("inspecting lock file: %w", err)
+	default:
+		return os.SameFile(lockedInfo, info), nil
+	}
+}
+
+// release unlocks and closes f, the locked file at path.
+func release(path string, f *os.File) error {
+	err := unlockFile(f)
+	if err != nil {
+		err = fmt.Errorf("unlocking %s: %w", path, err)
+	}
+	return errors.Join(err, f.Close())
+}
+
+// IsInUse returns true if err indicates a file could not be modified as it is
+// open in another process. Only Windows prevents removing or replacing open
+// files.
+func IsInUse(err error) bool {
+	return isInUse(err)
+}
@@ -0,0 +1,20 @@
+//go:build !unix && !windows
+
+package filelock
+
+import "os"
+
+// files are not locked on other platforms, e.g. Plan 9
+const removeOpen = true
+
+func lockFile(*os.File) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
+
+func isInUse(error) bool {
+	return false
+}
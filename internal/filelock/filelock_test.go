@@ -0,0 +1,62 @@
+package filelock
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	unlock, err := Lock(path)
+	assert.NoError(t, err)
+
+	// a second lock, from another file descriptor, waits for the first
+	locked := make(chan func() error)
+	go func() {
+		unlock, err := Lock(path)
+		assert.NoError(t, err)
+		locked <- unlock
+	}()
+
+	select {
+	case <-locked:
+		assert.Fail(t, "lock acquired while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, unlock())
+	assert.NoError(t, (<-locked)())
+}
+
+func TestLockTemp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+	unlock, err := LockTemp(path)
+	assert.NoError(t, err)
+
+	// a waiter locks the lock file once removed, so must retry
+	locked := make(chan func() error)
+	go func() {
+		unlock, err := LockTemp(path)
+		assert.NoError(t, err)
+		locked <- unlock
+	}()
+	select {
+	case <-locked:
+		assert.Fail(t, "lock acquired while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, unlock())
+	unlock = <-locked
+	assert.FileExists(t, path)
+
+	assert.NoError(t, unlock())
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -7,7 +7,7 @@ import (
 	"os"
 
 	"github.com/act3-ai/gnoci/pkg/apis"
-	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
 
 	"github.com/act3-ai/go-common/pkg/genschema"
 )
@@ -23,8 +23,8 @@ func main() {
 	if err := genschema.GenerateGroupSchemas(
 		os.Args[1],
 		scheme,
-		[]string{v1alpha1.Group},
-		v1alpha1.Repository,
+		[]string{v1alpha2.Group},
+		v1alpha2.Repository,
 	); err != nil {
 		log.Fatal(fmt.Errorf("JSON Schema generation failed: %w", err))
 	}
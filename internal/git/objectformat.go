@@ -0,0 +1,29 @@
+package git
+
+import (
+	"crypto"
+
+	gogit "github.com/go-git/go-git/v5"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/hash"
+)
+
+// ObjectFormat returns the hash algorithm go-git was built with. SHA-256
+// requires building with the "sha256" tag.
+func ObjectFormat() formatcfg.ObjectFormat {
+	if hash.CryptoType == crypto.SHA256 {
+		return formatcfg.SHA256
+	}
+	return formatcfg.SHA1
+}
+
+// PlainInit creates an empty repository at path, as [gogit.PlainInit], using
+// the hash algorithm go-git was built with.
+func PlainInit(path string, bare bool) (*gogit.Repository, error) {
+	opts := &gogit.PlainInitOptions{Bare: bare}
+	if format := ObjectFormat(); format != formatcfg.DefaultObjectFormat {
+		opts.ObjectFormat = format
+	}
+
+	return gogit.PlainInitWithOptions(path, opts)
+}
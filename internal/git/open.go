@@ -0,0 +1,133 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/filesystem/dotgit"
+)
+
+const (
+	// EnvGitDir is the environment variable Git sets to the Git directory
+	// of the repository when invoking remote helpers.
+	EnvGitDir = "GIT_DIR"
+	// EnvWorkTree is the environment variable overriding the worktree of
+	// the repository.
+	EnvWorkTree = "GIT_WORK_TREE"
+)
+
+// OpenEnv opens the local repository as Git would, from the [EnvGitDir] and
+// [EnvWorkTree] environment variables. Without [EnvGitDir], the repository is
+// discovered from the current directory or its parents.
+func OpenEnv() (*gogit.Repository, error) {
+	gitDir := os.Getenv(EnvGitDir)
+	if gitDir == "" {
+		repo, err := gogit.PlainOpenWithOptions(".", &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+		if err != nil {
+			return nil, fmt.Errorf("discovering repository from current directory: %w", err)
+		}
+		return repo, nil
+	}
+
+	return OpenDir(gitDir, os.Getenv(EnvWorkTree))
+}
+
+// OpenDir opens the repository in Git directory gitDir, which may be the
+// Git directory of a bare repository or of a linked worktree, or a worktree
+// containing a .git directory or file. An empty workTree defaults to the
+// parent of a .git directory, or the worktree recorded by a linked worktree.
+// Bare repositories have no worktree.
+func OpenDir(gitDir, workTree string) (*gogit.Repository, error) {
+	gitDir, err := filepath.Abs(gitDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving git directory: %w", err)
+	}
+	fi, err := os.Stat(gitDir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("%w: %s", gogit.ErrRepositoryNotExists, gitDir)
+	case err != nil:
+		return nil, fmt.Errorf("resolving git directory: %w", err)
+	case !fi.IsDir():
+		return nil, fmt.Errorf("%w: %s is not a directory", gogit.ErrRepositoryNotExists, gitDir)
+	}
+
+	if _, err := os.Stat(filepath.Join(gitDir, gogit.GitDirName)); err == nil {
+		// a worktree rather than a Git directory, as opened by [gogit.PlainOpen]
+		repo, err := gogit.PlainOpenWithOptions(gitDir, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+		if err != nil {
+			return nil, fmt.Errorf("opening repository %s: %w", gitDir, err)
+		}
+		return repo, nil
+	}
+
+	dot := osfs.New(gitDir)
+	common, err := readGitDirFile(gitDir, "commondir")
+	if err != nil {
+		return nil, err
+	}
+	var commonFS billy.Filesystem
+	if common != "" {
+		commonFS = osfs.New(common)
+	}
+
+	if workTree == "" {
+		workTree, err = defaultWorkTree(gitDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var wt billy.Filesystem
+	if workTree != "" {
+		wt = osfs.New(workTree)
+	}
+
+	st := filesystem.NewStorage(dotgit.NewRepositoryFilesystem(dot, commonFS), cache.NewObjectLRUDefault())
+	repo, err := gogit.Open(st, wt)
+	if err != nil {
+		return nil, fmt.Errorf("opening repository %s: %w", gitDir, err)
+	}
+
+	return repo, nil
+}
+
+// defaultWorkTree returns the worktree of gitDir, or an empty string if it is
+// bare.
+func defaultWorkTree(gitDir string) (string, error) {
+	if filepath.Base(gitDir) == gogit.GitDirName {
+		return filepath.Dir(gitDir), nil
+	}
+
+	// linked worktrees record the path of the .git file in their worktree
+	dotGitFile, err := readGitDirFile(gitDir, "gitdir")
+	if err != nil || dotGitFile == "" {
+		return "", err
+	}
+	return filepath.Dir(dotGitFile), nil
+}
+
+// readGitDirFile reads a path from a file in gitDir, resolved relative to
+// gitDir. An empty string is returned if the file does not exist.
+func readGitDirFile(gitDir, name string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(gitDir, name))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	path := strings.TrimSpace(string(b))
+	if path == "" || filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Join(gitDir, path), nil
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenDir(t *testing.T) {
+	t.Run("Bare", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := gogit.PlainInit(dir, true)
+		assert.NoError(t, err)
+
+		repo, err := OpenDir(dir, "")
+		assert.NoError(t, err)
+		_, err = repo.Worktree()
+		assert.ErrorIs(t, err, gogit.ErrIsBareRepository)
+	})
+
+	t.Run("Worktree", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := gogit.PlainInit(dir, false)
+		assert.NoError(t, err)
+
+		repo, err := OpenDir(filepath.Join(dir, gogit.GitDirName), "")
+		assert.NoError(t, err)
+		wt, err := repo.Worktree()
+		assert.NoError(t, err)
+		assert.Equal(t, dir, wt.Filesystem.Root())
+	})
+
+	t.Run("Linked Worktree", func(t *testing.T) {
+		common := t.TempDir()
+		_, err := gogit.PlainInit(common, true)
+		assert.NoError(t, err)
+
+		// the Git directory of a linked worktree only holds its HEAD
+		gitDir := filepath.Join(common, "worktrees", "linked")
+		wtDir := t.TempDir()
+		assert.NoError(t, os.MkdirAll(gitDir, 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/linked\n"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(gitDir, "gitdir"), []byte(filepath.Join(wtDir, gogit.GitDirName)+"\n"), 0o644))
+
+		repo, err := OpenDir(gitDir, "")
+		assert.NoError(t, err)
+		head, err := repo.Storer.Reference(plumbing.HEAD)
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.ReferenceName("refs/heads/linked"), head.Target())
+		_, err = repo.Config()
+		assert.NoError(t, err)
+		wt, err := repo.Worktree()
+		assert.NoError(t, err)
+		assert.Equal(t, wtDir, wt.Filesystem.Root())
+	})
+
+	t.Run("Not Exist", func(t *testing.T) {
+		_, err := OpenDir(filepath.Join(t.TempDir(), "missing"), "")
+		assert.ErrorIs(t, err, gogit.ErrRepositoryNotExists)
+	})
+}
+
+func TestOpenEnv(t *testing.T) {
+	dir := t.TempDir()
+	_, err := gogit.PlainInit(dir, true)
+	assert.NoError(t, err)
+	wtDir := t.TempDir()
+	t.Setenv(EnvGitDir, dir)
+	t.Setenv(EnvWorkTree, wtDir)
+
+	repo, err := OpenEnv()
+	assert.NoError(t, err)
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.Equal(t, wtDir, wt.Filesystem.Root())
+}
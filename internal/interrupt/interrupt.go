@@ -0,0 +1,47 @@
+// Package interrupt cancels the remote helpers on SIGINT and SIGTERM, such
+// that in-flight uploads are aborted and temporary files are removed before
+// exiting, rather than left behind by an abrupt exit.
+package interrupt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ErrInterrupted is the cause of a context canceled by a signal.
+var ErrInterrupted = errors.New("interrupted")
+
+// NotifyContext returns a copy of parent canceled with [ErrInterrupted] as its
+// cause on the first SIGINT or SIGTERM. Subsequent signals are no longer
+// handled, terminating the process immediately if cleanup hangs. Calling stop
+// releases the signal handler.
+func NotifyContext(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	ctx, cancel := cancelOnSignal(parent, sigs, func() { signal.Stop(sigs) })
+	return ctx, cancel
+}
+
+// cancelOnSignal returns a copy of parent canceled once a signal is received
+// from sigs, calling release once a signal is received or the context is
+// otherwise done.
+func cancelOnSignal(parent context.Context, sigs <-chan os.Signal, release func()) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		defer release()
+		select {
+		case sig := <-sigs:
+			slog.WarnContext(ctx, "received signal, cleaning up before exiting", slog.String("signal", sig.String()))
+			cancel(fmt.Errorf("%w: %s", ErrInterrupted, sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() { cancel(context.Canceled) }
+}
@@ -0,0 +1,34 @@
+package interrupt
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_cancelOnSignal(t *testing.T) {
+	t.Run("Signal", func(t *testing.T) {
+		sigs := make(chan os.Signal, 1)
+		released := make(chan struct{})
+		ctx, stop := cancelOnSignal(t.Context(), sigs, func() { close(released) })
+		defer stop()
+
+		sigs <- os.Interrupt
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), ErrInterrupted)
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+		<-released
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		sigs := make(chan os.Signal, 1)
+		released := make(chan struct{})
+		ctx, stop := cancelOnSignal(t.Context(), sigs, func() { close(released) })
+
+		stop()
+		<-released
+		assert.NotErrorIs(t, context.Cause(ctx), ErrInterrupted)
+	})
+}
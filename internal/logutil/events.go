@@ -0,0 +1,46 @@
+package logutil
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Attribute keys of telemetry events. Keys and event names are stable, such
+// that tools wrapping the remote helpers may parse them.
+const (
+	// KeyEvent is the key of the event name attribute.
+	KeyEvent = "event"
+	// KeyDuration is the key of the event duration attribute, in milliseconds.
+	KeyDuration = "durationMs"
+)
+
+// Telemetry event names.
+const (
+	// EventPushStart is emitted before a batch of push requests is processed.
+	EventPushStart = "push.start"
+	// EventPushDone is emitted once a push is tagged in the remote.
+	EventPushDone = "push.done"
+	// EventFetchStart is emitted before a batch of fetch requests is processed.
+	EventFetchStart = "fetch.start"
+	// EventFetchDone is emitted once all fetched objects are written locally.
+	EventFetchDone = "fetch.done"
+	// EventLayerUpload is emitted for each packfile layer uploaded to the remote.
+	EventLayerUpload = "layer.upload"
+	// EventLayerDownload is emitted for each packfile layer downloaded from the remote.
+	EventLayerDownload = "layer.download"
+	// EventRefUpdate is emitted for each reference updated in the remote.
+	EventRefUpdate = "ref.update"
+	// EventManifestTag is emitted once a new Git manifest is tagged.
+	EventManifestTag = "manifest.tag"
+)
+
+// Event logs a telemetry event, using the event name as the message.
+func Event(ctx context.Context, name string, args ...any) {
+	slog.Default().Log(ctx, slog.LevelInfo, name, append([]any{slog.String(KeyEvent, name)}, args...)...)
+}
+
+// Since returns the duration since start as a telemetry attribute.
+func Since(start time.Time) slog.Attr {
+	return slog.Int64(KeyDuration, time.Since(start).Milliseconds())
+}
@@ -0,0 +1,94 @@
+package logutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+)
+
+// Format is the output format of logs.
+type Format string
+
+const (
+	// FormatDefault retains the handler installed by the CLI runner.
+	FormatDefault Format = ""
+	// FormatJSON writes machine-readable JSON records, always including
+	// telemetry events regardless of verbosity.
+	FormatJSON Format = "json"
+)
+
+// ErrUnsupportedFormat indicates a log format is not supported.
+var ErrUnsupportedFormat = errors.New("unsupported log format")
+
+// String implements [pflag.Value].
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set implements [pflag.Value].
+func (f *Format) Set(s string) error {
+	if !slices.Contains([]Format{FormatDefault, FormatJSON}, Format(s)) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, s)
+	}
+	*f = Format(s)
+	return nil
+}
+
+// Type implements [pflag.Value].
+func (f *Format) Type() string {
+	return "format"
+}
+
+// NewJSONHandler returns a handler writing JSON records to w. Records are
+// filtered by the levels enabled in base, except for telemetry events which
+// are always written.
+func NewJSONHandler(w io.Writer, base slog.Handler) slog.Handler {
+	return &eventHandler{
+		Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.Level(math.MinInt)}),
+		base:    base,
+	}
+}
+
+// eventHandler is a [slog.Handler] passing telemetry events through regardless
+// of the enabled levels of base.
+type eventHandler struct {
+	slog.Handler
+	base slog.Handler
+}
+
+// Enabled reports whether the level is enabled by base, or may be a telemetry event.
+func (h *eventHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || h.base.Enabled(ctx, level)
+}
+
+// Handle writes r if it is enabled by base or is a telemetry event.
+func (h *eventHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.base.Enabled(ctx, r.Level) && !isEvent(r) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r) //nolint:wrapcheck
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *eventHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &eventHandler{Handler: h.Handler.WithAttrs(attrs), base: h.base.WithAttrs(attrs)}
+}
+
+// WithGroup implements [slog.Handler].
+func (h *eventHandler) WithGroup(name string) slog.Handler {
+	return &eventHandler{Handler: h.Handler.WithGroup(name), base: h.base.WithGroup(name)}
+}
+
+// isEvent reports whether r is a telemetry event.
+func isEvent(r slog.Record) bool {
+	var found bool
+	r.Attrs(func(a slog.Attr) bool {
+		found = a.Key == KeyEvent
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,84 @@
+package logutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_Set(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		var f Format
+		assert.NoError(t, f.Set("json"))
+		assert.Equal(t, FormatJSON, f)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		f := FormatJSON
+		assert.NoError(t, f.Set(""))
+		assert.Equal(t, FormatDefault, f)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		f := FormatJSON
+		assert.ErrorIs(t, f.Set("yaml"), ErrUnsupportedFormat)
+		assert.Equal(t, FormatJSON, f)
+	})
+}
+
+func TestNewJSONHandler(t *testing.T) {
+	ctx := context.Background()
+	base := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelWarn})
+
+	t.Run("Events Always Written", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		log := slog.New(NewJSONHandler(buf, base))
+
+		log.InfoContext(ctx, "filtered")
+		log.InfoContext(ctx, EventPushStart, slog.String(KeyEvent, EventPushStart), Since(time.Now()))
+		log.WarnContext(ctx, "written")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 2)
+
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+		assert.Equal(t, EventPushStart, event[KeyEvent])
+		assert.Contains(t, event, KeyDuration)
+		assert.Contains(t, lines[1], `"msg":"written"`)
+	})
+
+	t.Run("Events With Attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		log := slog.New(NewJSONHandler(buf, base)).With(slog.String("address", "example.com/repo:tag"))
+
+		log.InfoContext(ctx, EventRefUpdate, slog.String(KeyEvent, EventRefUpdate))
+
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+		assert.Equal(t, EventRefUpdate, event[KeyEvent])
+		assert.Equal(t, "example.com/repo:tag", event["address"])
+	})
+}
+
+func TestEvent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	defaultLog := slog.Default()
+	slog.SetDefault(slog.New(NewJSONHandler(buf, slog.NewJSONHandler(io.Discard, nil))))
+	defer slog.SetDefault(defaultLog)
+
+	Event(context.Background(), EventLayerUpload, slog.String("digest", "sha256:abc"))
+
+	var event map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	assert.Equal(t, EventLayerUpload, event["msg"])
+	assert.Equal(t, EventLayerUpload, event[KeyEvent])
+	assert.Equal(t, "sha256:abc", event["digest"])
+}
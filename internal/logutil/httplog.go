@@ -2,7 +2,6 @@
 package logutil
 
 import (
-	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -17,7 +16,7 @@ import (
 
 var requestNumber atomic.Int64
 
-// LoggingTransport logs to the request's context.
+// LoggingTransport logs to the request's context at [LevelHTTP].
 // The output can be processed by jq to format it nicely.
 type LoggingTransport struct {
 	Base http.RoundTripper
@@ -26,12 +25,12 @@ type LoggingTransport struct {
 // RoundTrip logs http requests and reponses while redacting sensistive information.
 func (s *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
-	log := logger.V(logger.FromContext(ctx).WithGroup("http").With("requestID", requestNumber.Add(1)), 8)
+	log := logger.FromContext(ctx).WithGroup("http").With("requestID", requestNumber.Add(1))
 	var err error
 
 	// TODO: We can avoid double storing the body in memory if we add our own DumpRequestOut. For now,
 	// 20KiB is acceptable.
-	enabled := log.Enabled(ctx, slog.LevelInfo) // true if verbosity = 16
+	enabled := log.Enabled(ctx, LevelHTTP)
 	if enabled {
 		req := req.Clone(ctx)
 		// redact the URL credentials and query string (S3 signed URLs have credentials there)
@@ -43,7 +42,7 @@ func (s *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		if err != nil {
 			log.ErrorContext(ctx, "Failed to dump the HTTP request", "error", err.Error())
 		} else {
-			log.InfoContext(ctx, "HTTP Request", "contents", string(reqBytes))
+			log.Log(ctx, LevelHTTP, "HTTP Request", "contents", string(reqBytes))
 		}
 	}
 
@@ -60,7 +59,7 @@ func (s *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		if err != nil {
 			log.ErrorContext(ctx, "Failed to dump the HTTP response", "error", err.Error())
 		} else {
-			log.InfoContext(ctx, "HTTP Response", "contents", string(respBytes))
+			log.Log(ctx, LevelHTTP, "HTTP Response", "contents", string(respBytes))
 		}
 
 		// restore then
@@ -0,0 +1,47 @@
+package logutil
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+)
+
+// LevelHTTP is the level at which [LoggingTransport] logs HTTP requests and
+// responses, below [slog.LevelDebug].
+const LevelHTTP = slog.LevelDebug - 4
+
+// GitVerbosityLevel maps a Git verbosity, as set by the verbosity option of
+// the remote helper protocol, to a log level relative to base. Git defaults
+// to a verbosity of 1, logging at base, each -v logs one level more and each
+// -q one level less, e.g. "git fetch -vvv" logs HTTP requests at [LevelHTTP]
+// from a base of [slog.LevelWarn].
+func GitVerbosityLevel(base slog.Level, verbosity int) slog.Level {
+	return base - slog.Level(4*(verbosity-1))
+}
+
+// EnabledLevel returns the lowest level enabled by h.
+func EnabledLevel(ctx context.Context, h slog.Handler) slog.Level {
+	lo, hi := int64(math.MinInt32), int64(math.MaxInt32)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if h.Enabled(ctx, slog.Level(mid)) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return slog.Level(lo)
+}
+
+// NewLevelHandler returns a handler writing JSON records to w, like the handler
+// installed by the CLI runner, filtered by the dynamic level. level is set to
+// the lowest level enabled by base, retaining the verbosity of the runner
+// until level is changed.
+func NewLevelHandler(ctx context.Context, w io.Writer, base slog.Handler, level *slog.LevelVar) slog.Handler {
+	level.Set(EnabledLevel(ctx, base))
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
+	})
+}
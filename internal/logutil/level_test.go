@@ -0,0 +1,39 @@
+package logutil
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitVerbosityLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelWarn, GitVerbosityLevel(slog.LevelWarn, 1))
+	assert.Equal(t, slog.LevelError, GitVerbosityLevel(slog.LevelWarn, 0))
+	assert.Equal(t, slog.LevelInfo, GitVerbosityLevel(slog.LevelWarn, 2))
+	assert.Equal(t, LevelHTTP, GitVerbosityLevel(slog.LevelWarn, 4))
+	assert.Equal(t, slog.LevelDebug, GitVerbosityLevel(slog.LevelInfo, 2))
+}
+
+func TestEnabledLevel(t *testing.T) {
+	for _, lvl := range []slog.Level{slog.LevelError, slog.LevelWarn, slog.LevelDebug, LevelHTTP - 20} {
+		h := slog.NewTextHandler(new(bytes.Buffer), &slog.HandlerOptions{Level: lvl})
+		assert.Equal(t, lvl, EnabledLevel(t.Context(), h))
+	}
+}
+
+func TestNewLevelHandler(t *testing.T) {
+	base := slog.NewTextHandler(new(bytes.Buffer), &slog.HandlerOptions{Level: slog.LevelWarn})
+	out := new(bytes.Buffer)
+	level := new(slog.LevelVar)
+	log := slog.New(NewLevelHandler(t.Context(), out, base, level))
+	assert.Equal(t, slog.LevelWarn, level.Level())
+
+	log.InfoContext(t.Context(), "hidden")
+	assert.Empty(t, out.String())
+
+	level.Set(slog.LevelInfo)
+	log.InfoContext(t.Context(), "shown")
+	assert.Contains(t, out.String(), `"msg":"shown"`)
+}
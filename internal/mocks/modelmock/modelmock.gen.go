@@ -11,6 +11,7 @@ package modelmock
 
 import (
 	context "context"
+	crypto "crypto"
 	io "io"
 	iter "iter"
 	reflect "reflect"
@@ -19,6 +20,7 @@ import (
 	model "github.com/act3-ai/gnoci/internal/model"
 	oci "github.com/act3-ai/gnoci/pkg/oci"
 	plumbing "github.com/go-git/go-git/v5/plumbing"
+	config "github.com/go-git/go-git/v5/plumbing/format/config"
 	object "github.com/go-git/go-git/v5/plumbing/object"
 	digest "github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
@@ -51,18 +53,18 @@ func (m *MockReadOnlyModeler) EXPECT() *MockReadOnlyModelerMockRecorder {
 }
 
 // CommitExists mocks base method.
-func (m *MockReadOnlyModeler) CommitExists(localRepo git.Repository, commit *object.Commit) (digest.Digest, error) {
+func (m *MockReadOnlyModeler) CommitExists(ctx context.Context, localRepo git.Repository, commit *object.Commit) (digest.Digest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CommitExists", localRepo, commit)
+	ret := m.ctrl.Call(m, "CommitExists", ctx, localRepo, commit)
 	ret0, _ := ret[0].(digest.Digest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CommitExists indicates an expected call of CommitExists.
-func (mr *MockReadOnlyModelerMockRecorder) CommitExists(localRepo, commit any) *MockReadOnlyModelerCommitExistsCall {
+func (mr *MockReadOnlyModelerMockRecorder) CommitExists(ctx, localRepo, commit any) *MockReadOnlyModelerCommitExistsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitExists", reflect.TypeOf((*MockReadOnlyModeler)(nil).CommitExists), localRepo, commit)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitExists", reflect.TypeOf((*MockReadOnlyModeler)(nil).CommitExists), ctx, localRepo, commit)
 	return &MockReadOnlyModelerCommitExistsCall{Call: call}
 }
 
@@ -78,13 +80,51 @@ func (c *MockReadOnlyModelerCommitExistsCall) Return(arg0 digest.Digest, arg1 er
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockReadOnlyModelerCommitExistsCall) Do(f func(git.Repository, *object.Commit) (digest.Digest, error)) *MockReadOnlyModelerCommitExistsCall {
+func (c *MockReadOnlyModelerCommitExistsCall) Do(f func(context.Context, git.Repository, *object.Commit) (digest.Digest, error)) *MockReadOnlyModelerCommitExistsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockReadOnlyModelerCommitExistsCall) DoAndReturn(f func(git.Repository, *object.Commit) (digest.Digest, error)) *MockReadOnlyModelerCommitExistsCall {
+func (c *MockReadOnlyModelerCommitExistsCall) DoAndReturn(f func(context.Context, git.Repository, *object.Commit) (digest.Digest, error)) *MockReadOnlyModelerCommitExistsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DefaultBranch mocks base method.
+func (m *MockReadOnlyModeler) DefaultBranch() plumbing.ReferenceName {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DefaultBranch")
+	ret0, _ := ret[0].(plumbing.ReferenceName)
+	return ret0
+}
+
+// DefaultBranch indicates an expected call of DefaultBranch.
+func (mr *MockReadOnlyModelerMockRecorder) DefaultBranch() *MockReadOnlyModelerDefaultBranchCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultBranch", reflect.TypeOf((*MockReadOnlyModeler)(nil).DefaultBranch))
+	return &MockReadOnlyModelerDefaultBranchCall{Call: call}
+}
+
+// MockReadOnlyModelerDefaultBranchCall wrap *gomock.Call
+type MockReadOnlyModelerDefaultBranchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerDefaultBranchCall) Return(arg0 plumbing.ReferenceName) *MockReadOnlyModelerDefaultBranchCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerDefaultBranchCall) Do(f func() plumbing.ReferenceName) *MockReadOnlyModelerDefaultBranchCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerDefaultBranchCall) DoAndReturn(f func() plumbing.ReferenceName) *MockReadOnlyModelerDefaultBranchCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -128,6 +168,45 @@ func (c *MockReadOnlyModelerFetchCall) DoAndReturn(f func(context.Context) (v1.D
 	return c
 }
 
+// FetchConfigOnly mocks base method.
+func (m *MockReadOnlyModeler) FetchConfigOnly(ctx context.Context) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchConfigOnly", ctx)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchConfigOnly indicates an expected call of FetchConfigOnly.
+func (mr *MockReadOnlyModelerMockRecorder) FetchConfigOnly(ctx any) *MockReadOnlyModelerFetchConfigOnlyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchConfigOnly", reflect.TypeOf((*MockReadOnlyModeler)(nil).FetchConfigOnly), ctx)
+	return &MockReadOnlyModelerFetchConfigOnlyCall{Call: call}
+}
+
+// MockReadOnlyModelerFetchConfigOnlyCall wrap *gomock.Call
+type MockReadOnlyModelerFetchConfigOnlyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerFetchConfigOnlyCall) Return(arg0 v1.Descriptor, arg1 error) *MockReadOnlyModelerFetchConfigOnlyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerFetchConfigOnlyCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockReadOnlyModelerFetchConfigOnlyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerFetchConfigOnlyCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockReadOnlyModelerFetchConfigOnlyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // FetchLayer mocks base method.
 func (m *MockReadOnlyModeler) FetchLayer(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -205,6 +284,45 @@ func (c *MockReadOnlyModelerFetchLayersReverseCall) DoAndReturn(f func(context.C
 	return c
 }
 
+// FetchMetadata mocks base method.
+func (m *MockReadOnlyModeler) FetchMetadata(ctx context.Context) (oci.Metadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchMetadata", ctx)
+	ret0, _ := ret[0].(oci.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchMetadata indicates an expected call of FetchMetadata.
+func (mr *MockReadOnlyModelerMockRecorder) FetchMetadata(ctx any) *MockReadOnlyModelerFetchMetadataCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchMetadata", reflect.TypeOf((*MockReadOnlyModeler)(nil).FetchMetadata), ctx)
+	return &MockReadOnlyModelerFetchMetadataCall{Call: call}
+}
+
+// MockReadOnlyModelerFetchMetadataCall wrap *gomock.Call
+type MockReadOnlyModelerFetchMetadataCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerFetchMetadataCall) Return(arg0 oci.Metadata, arg1 error) *MockReadOnlyModelerFetchMetadataCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerFetchMetadataCall) Do(f func(context.Context) (oci.Metadata, error)) *MockReadOnlyModelerFetchMetadataCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerFetchMetadataCall) DoAndReturn(f func(context.Context) (oci.Metadata, error)) *MockReadOnlyModelerFetchMetadataCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // FetchOrDefault mocks base method.
 func (m *MockReadOnlyModeler) FetchOrDefault(ctx context.Context) (v1.Descriptor, error) {
 	m.ctrl.T.Helper()
@@ -244,616 +362,2109 @@ func (c *MockReadOnlyModelerFetchOrDefaultCall) DoAndReturn(f func(context.Conte
 	return c
 }
 
-// HeadRefs mocks base method.
-func (m *MockReadOnlyModeler) HeadRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
+// FetchOrEmpty mocks base method.
+func (m *MockReadOnlyModeler) FetchOrEmpty(ctx context.Context) (v1.Descriptor, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HeadRefs")
-	ret0, _ := ret[0].(map[plumbing.ReferenceName]oci.ReferenceInfo)
-	return ret0
+	ret := m.ctrl.Call(m, "FetchOrEmpty", ctx)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// HeadRefs indicates an expected call of HeadRefs.
-func (mr *MockReadOnlyModelerMockRecorder) HeadRefs() *MockReadOnlyModelerHeadRefsCall {
+// FetchOrEmpty indicates an expected call of FetchOrEmpty.
+func (mr *MockReadOnlyModelerMockRecorder) FetchOrEmpty(ctx any) *MockReadOnlyModelerFetchOrEmptyCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadRefs", reflect.TypeOf((*MockReadOnlyModeler)(nil).HeadRefs))
-	return &MockReadOnlyModelerHeadRefsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrEmpty", reflect.TypeOf((*MockReadOnlyModeler)(nil).FetchOrEmpty), ctx)
+	return &MockReadOnlyModelerFetchOrEmptyCall{Call: call}
 }
 
-// MockReadOnlyModelerHeadRefsCall wrap *gomock.Call
-type MockReadOnlyModelerHeadRefsCall struct {
+// MockReadOnlyModelerFetchOrEmptyCall wrap *gomock.Call
+type MockReadOnlyModelerFetchOrEmptyCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockReadOnlyModelerHeadRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerHeadRefsCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockReadOnlyModelerFetchOrEmptyCall) Return(arg0 v1.Descriptor, arg1 error) *MockReadOnlyModelerFetchOrEmptyCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockReadOnlyModelerHeadRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerHeadRefsCall {
+func (c *MockReadOnlyModelerFetchOrEmptyCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockReadOnlyModelerFetchOrEmptyCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockReadOnlyModelerHeadRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerHeadRefsCall {
+func (c *MockReadOnlyModelerFetchOrEmptyCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockReadOnlyModelerFetchOrEmptyCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Ref mocks base method.
-func (m *MockReadOnlyModeler) Ref() registry.Reference {
+// FetchPackIndex mocks base method.
+func (m *MockReadOnlyModeler) FetchPackIndex(ctx context.Context, layer digest.Digest) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Ref")
-	ret0, _ := ret[0].(registry.Reference)
-	return ret0
+	ret := m.ctrl.Call(m, "FetchPackIndex", ctx, layer)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Ref indicates an expected call of Ref.
-func (mr *MockReadOnlyModelerMockRecorder) Ref() *MockReadOnlyModelerRefCall {
+// FetchPackIndex indicates an expected call of FetchPackIndex.
+func (mr *MockReadOnlyModelerMockRecorder) FetchPackIndex(ctx, layer any) *MockReadOnlyModelerFetchPackIndexCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ref", reflect.TypeOf((*MockReadOnlyModeler)(nil).Ref))
-	return &MockReadOnlyModelerRefCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPackIndex", reflect.TypeOf((*MockReadOnlyModeler)(nil).FetchPackIndex), ctx, layer)
+	return &MockReadOnlyModelerFetchPackIndexCall{Call: call}
 }
 
-// MockReadOnlyModelerRefCall wrap *gomock.Call
-type MockReadOnlyModelerRefCall struct {
+// MockReadOnlyModelerFetchPackIndexCall wrap *gomock.Call
+type MockReadOnlyModelerFetchPackIndexCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockReadOnlyModelerRefCall) Return(arg0 registry.Reference) *MockReadOnlyModelerRefCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockReadOnlyModelerFetchPackIndexCall) Return(arg0 io.ReadCloser, arg1 error) *MockReadOnlyModelerFetchPackIndexCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockReadOnlyModelerRefCall) Do(f func() registry.Reference) *MockReadOnlyModelerRefCall {
+func (c *MockReadOnlyModelerFetchPackIndexCall) Do(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockReadOnlyModelerFetchPackIndexCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockReadOnlyModelerRefCall) DoAndReturn(f func() registry.Reference) *MockReadOnlyModelerRefCall {
+func (c *MockReadOnlyModelerFetchPackIndexCall) DoAndReturn(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockReadOnlyModelerFetchPackIndexCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ResolveRef mocks base method.
-func (m *MockReadOnlyModeler) ResolveRef(ctx context.Context, refName plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error) {
+// Fsck mocks base method.
+func (m *MockReadOnlyModeler) Fsck(ctx context.Context) (*model.FsckReport, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ResolveRef", ctx, refName)
-	ret0, _ := ret[0].(*plumbing.Reference)
-	ret1, _ := ret[1].(digest.Digest)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "Fsck", ctx)
+	ret0, _ := ret[0].(*model.FsckReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// ResolveRef indicates an expected call of ResolveRef.
-func (mr *MockReadOnlyModelerMockRecorder) ResolveRef(ctx, refName any) *MockReadOnlyModelerResolveRefCall {
+// Fsck indicates an expected call of Fsck.
+func (mr *MockReadOnlyModelerMockRecorder) Fsck(ctx any) *MockReadOnlyModelerFsckCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveRef", reflect.TypeOf((*MockReadOnlyModeler)(nil).ResolveRef), ctx, refName)
-	return &MockReadOnlyModelerResolveRefCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fsck", reflect.TypeOf((*MockReadOnlyModeler)(nil).Fsck), ctx)
+	return &MockReadOnlyModelerFsckCall{Call: call}
 }
 
-// MockReadOnlyModelerResolveRefCall wrap *gomock.Call
-type MockReadOnlyModelerResolveRefCall struct {
+// MockReadOnlyModelerFsckCall wrap *gomock.Call
+type MockReadOnlyModelerFsckCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockReadOnlyModelerResolveRefCall) Return(arg0 *plumbing.Reference, arg1 digest.Digest, arg2 error) *MockReadOnlyModelerResolveRefCall {
-	c.Call = c.Call.Return(arg0, arg1, arg2)
+func (c *MockReadOnlyModelerFsckCall) Return(arg0 *model.FsckReport, arg1 error) *MockReadOnlyModelerFsckCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockReadOnlyModelerResolveRefCall) Do(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockReadOnlyModelerResolveRefCall {
+func (c *MockReadOnlyModelerFsckCall) Do(f func(context.Context) (*model.FsckReport, error)) *MockReadOnlyModelerFsckCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockReadOnlyModelerResolveRefCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockReadOnlyModelerResolveRefCall {
+func (c *MockReadOnlyModelerFsckCall) DoAndReturn(f func(context.Context) (*model.FsckReport, error)) *MockReadOnlyModelerFsckCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// TagRefs mocks base method.
-func (m *MockReadOnlyModeler) TagRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
+// HeadRefs mocks base method.
+func (m *MockReadOnlyModeler) HeadRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TagRefs")
+	ret := m.ctrl.Call(m, "HeadRefs")
 	ret0, _ := ret[0].(map[plumbing.ReferenceName]oci.ReferenceInfo)
 	return ret0
 }
 
-// TagRefs indicates an expected call of TagRefs.
-func (mr *MockReadOnlyModelerMockRecorder) TagRefs() *MockReadOnlyModelerTagRefsCall {
+// HeadRefs indicates an expected call of HeadRefs.
+func (mr *MockReadOnlyModelerMockRecorder) HeadRefs() *MockReadOnlyModelerHeadRefsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagRefs", reflect.TypeOf((*MockReadOnlyModeler)(nil).TagRefs))
-	return &MockReadOnlyModelerTagRefsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadRefs", reflect.TypeOf((*MockReadOnlyModeler)(nil).HeadRefs))
+	return &MockReadOnlyModelerHeadRefsCall{Call: call}
 }
 
-// MockReadOnlyModelerTagRefsCall wrap *gomock.Call
-type MockReadOnlyModelerTagRefsCall struct {
+// MockReadOnlyModelerHeadRefsCall wrap *gomock.Call
+type MockReadOnlyModelerHeadRefsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockReadOnlyModelerTagRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerTagRefsCall {
+func (c *MockReadOnlyModelerHeadRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerHeadRefsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockReadOnlyModelerTagRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerTagRefsCall {
+func (c *MockReadOnlyModelerHeadRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerHeadRefsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockReadOnlyModelerTagRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerTagRefsCall {
+func (c *MockReadOnlyModelerHeadRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerHeadRefsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// MockModeler is a mock of Modeler interface.
-type MockModeler struct {
-	ctrl     *gomock.Controller
-	recorder *MockModelerMockRecorder
-	isgomock struct{}
-}
-
-// MockModelerMockRecorder is the mock recorder for MockModeler.
-type MockModelerMockRecorder struct {
-	mock *MockModeler
-}
-
-// NewMockModeler creates a new mock instance.
-func NewMockModeler(ctrl *gomock.Controller) *MockModeler {
-	mock := &MockModeler{ctrl: ctrl}
-	mock.recorder = &MockModelerMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockModeler) EXPECT() *MockModelerMockRecorder {
-	return m.recorder
-}
-
-// AddPack mocks base method.
-func (m *MockModeler) AddPack(ctx context.Context, path string, refs ...*plumbing.Reference) (v1.Descriptor, error) {
+// History mocks base method.
+func (m *MockReadOnlyModeler) History(ctx context.Context, n int) ([]model.ManifestStats, error) {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx, path}
-	for _, a := range refs {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "AddPack", varargs...)
-	ret0, _ := ret[0].(v1.Descriptor)
+	ret := m.ctrl.Call(m, "History", ctx, n)
+	ret0, _ := ret[0].([]model.ManifestStats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AddPack indicates an expected call of AddPack.
-func (mr *MockModelerMockRecorder) AddPack(ctx, path any, refs ...any) *MockModelerAddPackCall {
+// History indicates an expected call of History.
+func (mr *MockReadOnlyModelerMockRecorder) History(ctx, n any) *MockReadOnlyModelerHistoryCall {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx, path}, refs...)
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPack", reflect.TypeOf((*MockModeler)(nil).AddPack), varargs...)
-	return &MockModelerAddPackCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockReadOnlyModeler)(nil).History), ctx, n)
+	return &MockReadOnlyModelerHistoryCall{Call: call}
 }
 
-// MockModelerAddPackCall wrap *gomock.Call
-type MockModelerAddPackCall struct {
+// MockReadOnlyModelerHistoryCall wrap *gomock.Call
+type MockReadOnlyModelerHistoryCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerAddPackCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerAddPackCall {
+func (c *MockReadOnlyModelerHistoryCall) Return(arg0 []model.ManifestStats, arg1 error) *MockReadOnlyModelerHistoryCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerAddPackCall) Do(f func(context.Context, string, ...*plumbing.Reference) (v1.Descriptor, error)) *MockModelerAddPackCall {
+func (c *MockReadOnlyModelerHistoryCall) Do(f func(context.Context, int) ([]model.ManifestStats, error)) *MockReadOnlyModelerHistoryCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerAddPackCall) DoAndReturn(f func(context.Context, string, ...*plumbing.Reference) (v1.Descriptor, error)) *MockModelerAddPackCall {
+func (c *MockReadOnlyModelerHistoryCall) DoAndReturn(f func(context.Context, int) ([]model.ManifestStats, error)) *MockReadOnlyModelerHistoryCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// CommitExists mocks base method.
-func (m *MockModeler) CommitExists(localRepo git.Repository, commit *object.Commit) (digest.Digest, error) {
+// IsAncestor mocks base method.
+func (m *MockReadOnlyModeler) IsAncestor(ctx context.Context, ancestor, commit plumbing.Hash) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CommitExists", localRepo, commit)
-	ret0, _ := ret[0].(digest.Digest)
+	ret := m.ctrl.Call(m, "IsAncestor", ctx, ancestor, commit)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CommitExists indicates an expected call of CommitExists.
-func (mr *MockModelerMockRecorder) CommitExists(localRepo, commit any) *MockModelerCommitExistsCall {
+// IsAncestor indicates an expected call of IsAncestor.
+func (mr *MockReadOnlyModelerMockRecorder) IsAncestor(ctx, ancestor, commit any) *MockReadOnlyModelerIsAncestorCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitExists", reflect.TypeOf((*MockModeler)(nil).CommitExists), localRepo, commit)
-	return &MockModelerCommitExistsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAncestor", reflect.TypeOf((*MockReadOnlyModeler)(nil).IsAncestor), ctx, ancestor, commit)
+	return &MockReadOnlyModelerIsAncestorCall{Call: call}
 }
 
-// MockModelerCommitExistsCall wrap *gomock.Call
-type MockModelerCommitExistsCall struct {
+// MockReadOnlyModelerIsAncestorCall wrap *gomock.Call
+type MockReadOnlyModelerIsAncestorCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerCommitExistsCall) Return(arg0 digest.Digest, arg1 error) *MockModelerCommitExistsCall {
+func (c *MockReadOnlyModelerIsAncestorCall) Return(arg0 bool, arg1 error) *MockReadOnlyModelerIsAncestorCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerCommitExistsCall) Do(f func(git.Repository, *object.Commit) (digest.Digest, error)) *MockModelerCommitExistsCall {
+func (c *MockReadOnlyModelerIsAncestorCall) Do(f func(context.Context, plumbing.Hash, plumbing.Hash) (bool, error)) *MockReadOnlyModelerIsAncestorCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerCommitExistsCall) DoAndReturn(f func(git.Repository, *object.Commit) (digest.Digest, error)) *MockModelerCommitExistsCall {
+func (c *MockReadOnlyModelerIsAncestorCall) DoAndReturn(f func(context.Context, plumbing.Hash, plumbing.Hash) (bool, error)) *MockReadOnlyModelerIsAncestorCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// DeleteRef mocks base method.
-func (m *MockModeler) DeleteRef(ctx context.Context, refName plumbing.ReferenceName) error {
+// Layers mocks base method.
+func (m *MockReadOnlyModeler) Layers() []v1.Descriptor {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRef", ctx, refName)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "Layers")
+	ret0, _ := ret[0].([]v1.Descriptor)
 	return ret0
 }
 
-// DeleteRef indicates an expected call of DeleteRef.
-func (mr *MockModelerMockRecorder) DeleteRef(ctx, refName any) *MockModelerDeleteRefCall {
+// Layers indicates an expected call of Layers.
+func (mr *MockReadOnlyModelerMockRecorder) Layers() *MockReadOnlyModelerLayersCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRef", reflect.TypeOf((*MockModeler)(nil).DeleteRef), ctx, refName)
-	return &MockModelerDeleteRefCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Layers", reflect.TypeOf((*MockReadOnlyModeler)(nil).Layers))
+	return &MockReadOnlyModelerLayersCall{Call: call}
 }
 
-// MockModelerDeleteRefCall wrap *gomock.Call
-type MockModelerDeleteRefCall struct {
+// MockReadOnlyModelerLayersCall wrap *gomock.Call
+type MockReadOnlyModelerLayersCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerDeleteRefCall) Return(arg0 error) *MockModelerDeleteRefCall {
+func (c *MockReadOnlyModelerLayersCall) Return(arg0 []v1.Descriptor) *MockReadOnlyModelerLayersCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerDeleteRefCall) Do(f func(context.Context, plumbing.ReferenceName) error) *MockModelerDeleteRefCall {
+func (c *MockReadOnlyModelerLayersCall) Do(f func() []v1.Descriptor) *MockReadOnlyModelerLayersCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerDeleteRefCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) error) *MockModelerDeleteRefCall {
+func (c *MockReadOnlyModelerLayersCall) DoAndReturn(f func() []v1.Descriptor) *MockReadOnlyModelerLayersCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Fetch mocks base method.
-func (m *MockModeler) Fetch(ctx context.Context) (v1.Descriptor, error) {
+// NoteRefs mocks base method.
+func (m *MockReadOnlyModeler) NoteRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Fetch", ctx)
-	ret0, _ := ret[0].(v1.Descriptor)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "NoteRefs")
+	ret0, _ := ret[0].(map[plumbing.ReferenceName]oci.ReferenceInfo)
+	return ret0
 }
 
-// Fetch indicates an expected call of Fetch.
-func (mr *MockModelerMockRecorder) Fetch(ctx any) *MockModelerFetchCall {
+// NoteRefs indicates an expected call of NoteRefs.
+func (mr *MockReadOnlyModelerMockRecorder) NoteRefs() *MockReadOnlyModelerNoteRefsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockModeler)(nil).Fetch), ctx)
-	return &MockModelerFetchCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NoteRefs", reflect.TypeOf((*MockReadOnlyModeler)(nil).NoteRefs))
+	return &MockReadOnlyModelerNoteRefsCall{Call: call}
 }
 
-// MockModelerFetchCall wrap *gomock.Call
-type MockModelerFetchCall struct {
+// MockReadOnlyModelerNoteRefsCall wrap *gomock.Call
+type MockReadOnlyModelerNoteRefsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerFetchCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerFetchCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockReadOnlyModelerNoteRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerNoteRefsCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerFetchCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchCall {
+func (c *MockReadOnlyModelerNoteRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerNoteRefsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerFetchCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchCall {
+func (c *MockReadOnlyModelerNoteRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerNoteRefsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// FetchLayer mocks base method.
-func (m *MockModeler) FetchLayer(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+// ObjectFormat mocks base method.
+func (m *MockReadOnlyModeler) ObjectFormat() config.ObjectFormat {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchLayer", ctx, dgst)
-	ret0, _ := ret[0].(io.ReadCloser)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ObjectFormat")
+	ret0, _ := ret[0].(config.ObjectFormat)
+	return ret0
 }
 
-// FetchLayer indicates an expected call of FetchLayer.
-func (mr *MockModelerMockRecorder) FetchLayer(ctx, dgst any) *MockModelerFetchLayerCall {
+// ObjectFormat indicates an expected call of ObjectFormat.
+func (mr *MockReadOnlyModelerMockRecorder) ObjectFormat() *MockReadOnlyModelerObjectFormatCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLayer", reflect.TypeOf((*MockModeler)(nil).FetchLayer), ctx, dgst)
-	return &MockModelerFetchLayerCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObjectFormat", reflect.TypeOf((*MockReadOnlyModeler)(nil).ObjectFormat))
+	return &MockReadOnlyModelerObjectFormatCall{Call: call}
 }
 
-// MockModelerFetchLayerCall wrap *gomock.Call
-type MockModelerFetchLayerCall struct {
+// MockReadOnlyModelerObjectFormatCall wrap *gomock.Call
+type MockReadOnlyModelerObjectFormatCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerFetchLayerCall) Return(arg0 io.ReadCloser, arg1 error) *MockModelerFetchLayerCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockReadOnlyModelerObjectFormatCall) Return(arg0 config.ObjectFormat) *MockReadOnlyModelerObjectFormatCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerFetchLayerCall) Do(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockModelerFetchLayerCall {
+func (c *MockReadOnlyModelerObjectFormatCall) Do(f func() config.ObjectFormat) *MockReadOnlyModelerObjectFormatCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerFetchLayerCall) DoAndReturn(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockModelerFetchLayerCall {
+func (c *MockReadOnlyModelerObjectFormatCall) DoAndReturn(f func() config.ObjectFormat) *MockReadOnlyModelerObjectFormatCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// FetchLayersReverse mocks base method.
-func (m *MockModeler) FetchLayersReverse(ctx context.Context) iter.Seq2[io.ReadCloser, error] {
+// Prefetch mocks base method.
+func (m *MockReadOnlyModeler) Prefetch(ctx context.Context, dgsts ...digest.Digest) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchLayersReverse", ctx)
+	varargs := []any{ctx}
+	for _, a := range dgsts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Prefetch", varargs...)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prefetch indicates an expected call of Prefetch.
+func (mr *MockReadOnlyModelerMockRecorder) Prefetch(ctx any, dgsts ...any) *MockReadOnlyModelerPrefetchCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, dgsts...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prefetch", reflect.TypeOf((*MockReadOnlyModeler)(nil).Prefetch), varargs...)
+	return &MockReadOnlyModelerPrefetchCall{Call: call}
+}
+
+// MockReadOnlyModelerPrefetchCall wrap *gomock.Call
+type MockReadOnlyModelerPrefetchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerPrefetchCall) Return(arg0 int, arg1 error) *MockReadOnlyModelerPrefetchCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerPrefetchCall) Do(f func(context.Context, ...digest.Digest) (int, error)) *MockReadOnlyModelerPrefetchCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerPrefetchCall) DoAndReturn(f func(context.Context, ...digest.Digest) (int, error)) *MockReadOnlyModelerPrefetchCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Ref mocks base method.
+func (m *MockReadOnlyModeler) Ref() registry.Reference {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ref")
+	ret0, _ := ret[0].(registry.Reference)
+	return ret0
+}
+
+// Ref indicates an expected call of Ref.
+func (mr *MockReadOnlyModelerMockRecorder) Ref() *MockReadOnlyModelerRefCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ref", reflect.TypeOf((*MockReadOnlyModeler)(nil).Ref))
+	return &MockReadOnlyModelerRefCall{Call: call}
+}
+
+// MockReadOnlyModelerRefCall wrap *gomock.Call
+type MockReadOnlyModelerRefCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerRefCall) Return(arg0 registry.Reference) *MockReadOnlyModelerRefCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerRefCall) Do(f func() registry.Reference) *MockReadOnlyModelerRefCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerRefCall) DoAndReturn(f func() registry.Reference) *MockReadOnlyModelerRefCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ResolveCommit mocks base method.
+func (m *MockReadOnlyModeler) ResolveCommit(ctx context.Context, commit plumbing.Hash) (digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveCommit", ctx, commit)
+	ret0, _ := ret[0].(digest.Digest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveCommit indicates an expected call of ResolveCommit.
+func (mr *MockReadOnlyModelerMockRecorder) ResolveCommit(ctx, commit any) *MockReadOnlyModelerResolveCommitCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveCommit", reflect.TypeOf((*MockReadOnlyModeler)(nil).ResolveCommit), ctx, commit)
+	return &MockReadOnlyModelerResolveCommitCall{Call: call}
+}
+
+// MockReadOnlyModelerResolveCommitCall wrap *gomock.Call
+type MockReadOnlyModelerResolveCommitCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerResolveCommitCall) Return(arg0 digest.Digest, arg1 error) *MockReadOnlyModelerResolveCommitCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerResolveCommitCall) Do(f func(context.Context, plumbing.Hash) (digest.Digest, error)) *MockReadOnlyModelerResolveCommitCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerResolveCommitCall) DoAndReturn(f func(context.Context, plumbing.Hash) (digest.Digest, error)) *MockReadOnlyModelerResolveCommitCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ResolveRef mocks base method.
+func (m *MockReadOnlyModeler) ResolveRef(ctx context.Context, refName plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveRef", ctx, refName)
+	ret0, _ := ret[0].(*plumbing.Reference)
+	ret1, _ := ret[1].(digest.Digest)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResolveRef indicates an expected call of ResolveRef.
+func (mr *MockReadOnlyModelerMockRecorder) ResolveRef(ctx, refName any) *MockReadOnlyModelerResolveRefCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveRef", reflect.TypeOf((*MockReadOnlyModeler)(nil).ResolveRef), ctx, refName)
+	return &MockReadOnlyModelerResolveRefCall{Call: call}
+}
+
+// MockReadOnlyModelerResolveRefCall wrap *gomock.Call
+type MockReadOnlyModelerResolveRefCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerResolveRefCall) Return(arg0 *plumbing.Reference, arg1 digest.Digest, arg2 error) *MockReadOnlyModelerResolveRefCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerResolveRefCall) Do(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockReadOnlyModelerResolveRefCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerResolveRefCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockReadOnlyModelerResolveRefCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ResolveRefLayers mocks base method.
+func (m *MockReadOnlyModeler) ResolveRefLayers(ctx context.Context, refName plumbing.ReferenceName) ([]digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveRefLayers", ctx, refName)
+	ret0, _ := ret[0].([]digest.Digest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveRefLayers indicates an expected call of ResolveRefLayers.
+func (mr *MockReadOnlyModelerMockRecorder) ResolveRefLayers(ctx, refName any) *MockReadOnlyModelerResolveRefLayersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveRefLayers", reflect.TypeOf((*MockReadOnlyModeler)(nil).ResolveRefLayers), ctx, refName)
+	return &MockReadOnlyModelerResolveRefLayersCall{Call: call}
+}
+
+// MockReadOnlyModelerResolveRefLayersCall wrap *gomock.Call
+type MockReadOnlyModelerResolveRefLayersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerResolveRefLayersCall) Return(arg0 []digest.Digest, arg1 error) *MockReadOnlyModelerResolveRefLayersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerResolveRefLayersCall) Do(f func(context.Context, plumbing.ReferenceName) ([]digest.Digest, error)) *MockReadOnlyModelerResolveRefLayersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerResolveRefLayersCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) ([]digest.Digest, error)) *MockReadOnlyModelerResolveRefLayersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Shallow mocks base method.
+func (m *MockReadOnlyModeler) Shallow() []plumbing.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shallow")
+	ret0, _ := ret[0].([]plumbing.Hash)
+	return ret0
+}
+
+// Shallow indicates an expected call of Shallow.
+func (mr *MockReadOnlyModelerMockRecorder) Shallow() *MockReadOnlyModelerShallowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shallow", reflect.TypeOf((*MockReadOnlyModeler)(nil).Shallow))
+	return &MockReadOnlyModelerShallowCall{Call: call}
+}
+
+// MockReadOnlyModelerShallowCall wrap *gomock.Call
+type MockReadOnlyModelerShallowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerShallowCall) Return(arg0 []plumbing.Hash) *MockReadOnlyModelerShallowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerShallowCall) Do(f func() []plumbing.Hash) *MockReadOnlyModelerShallowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerShallowCall) DoAndReturn(f func() []plumbing.Hash) *MockReadOnlyModelerShallowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// TagRefs mocks base method.
+func (m *MockReadOnlyModeler) TagRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagRefs")
+	ret0, _ := ret[0].(map[plumbing.ReferenceName]oci.ReferenceInfo)
+	return ret0
+}
+
+// TagRefs indicates an expected call of TagRefs.
+func (mr *MockReadOnlyModelerMockRecorder) TagRefs() *MockReadOnlyModelerTagRefsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagRefs", reflect.TypeOf((*MockReadOnlyModeler)(nil).TagRefs))
+	return &MockReadOnlyModelerTagRefsCall{Call: call}
+}
+
+// MockReadOnlyModelerTagRefsCall wrap *gomock.Call
+type MockReadOnlyModelerTagRefsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerTagRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerTagRefsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerTagRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerTagRefsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerTagRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockReadOnlyModelerTagRefsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Verify mocks base method.
+func (m *MockReadOnlyModeler) Verify(ctx context.Context, keys ...crypto.PublicKey) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Verify", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockReadOnlyModelerMockRecorder) Verify(ctx any, keys ...any) *MockReadOnlyModelerVerifyCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, keys...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockReadOnlyModeler)(nil).Verify), varargs...)
+	return &MockReadOnlyModelerVerifyCall{Call: call}
+}
+
+// MockReadOnlyModelerVerifyCall wrap *gomock.Call
+type MockReadOnlyModelerVerifyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReadOnlyModelerVerifyCall) Return(arg0 error) *MockReadOnlyModelerVerifyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReadOnlyModelerVerifyCall) Do(f func(context.Context, ...crypto.PublicKey) error) *MockReadOnlyModelerVerifyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReadOnlyModelerVerifyCall) DoAndReturn(f func(context.Context, ...crypto.PublicKey) error) *MockReadOnlyModelerVerifyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockModeler is a mock of Modeler interface.
+type MockModeler struct {
+	ctrl     *gomock.Controller
+	recorder *MockModelerMockRecorder
+	isgomock struct{}
+}
+
+// MockModelerMockRecorder is the mock recorder for MockModeler.
+type MockModelerMockRecorder struct {
+	mock *MockModeler
+}
+
+// NewMockModeler creates a new mock instance.
+func NewMockModeler(ctrl *gomock.Controller) *MockModeler {
+	mock := &MockModeler{ctrl: ctrl}
+	mock.recorder = &MockModelerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModeler) EXPECT() *MockModelerMockRecorder {
+	return m.recorder
+}
+
+// AddPack mocks base method.
+func (m *MockModeler) AddPack(ctx context.Context, path string, base digest.Digest, commits []*object.Commit, refs ...*plumbing.Reference) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, path, base, commits}
+	for _, a := range refs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddPack", varargs...)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPack indicates an expected call of AddPack.
+func (mr *MockModelerMockRecorder) AddPack(ctx, path, base, commits any, refs ...any) *MockModelerAddPackCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, path, base, commits}, refs...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPack", reflect.TypeOf((*MockModeler)(nil).AddPack), varargs...)
+	return &MockModelerAddPackCall{Call: call}
+}
+
+// MockModelerAddPackCall wrap *gomock.Call
+type MockModelerAddPackCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerAddPackCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerAddPackCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerAddPackCall) Do(f func(context.Context, string, digest.Digest, []*object.Commit, ...*plumbing.Reference) (v1.Descriptor, error)) *MockModelerAddPackCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerAddPackCall) DoAndReturn(f func(context.Context, string, digest.Digest, []*object.Commit, ...*plumbing.Reference) (v1.Descriptor, error)) *MockModelerAddPackCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CommitExists mocks base method.
+func (m *MockModeler) CommitExists(ctx context.Context, localRepo git.Repository, commit *object.Commit) (digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitExists", ctx, localRepo, commit)
+	ret0, _ := ret[0].(digest.Digest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitExists indicates an expected call of CommitExists.
+func (mr *MockModelerMockRecorder) CommitExists(ctx, localRepo, commit any) *MockModelerCommitExistsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitExists", reflect.TypeOf((*MockModeler)(nil).CommitExists), ctx, localRepo, commit)
+	return &MockModelerCommitExistsCall{Call: call}
+}
+
+// MockModelerCommitExistsCall wrap *gomock.Call
+type MockModelerCommitExistsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerCommitExistsCall) Return(arg0 digest.Digest, arg1 error) *MockModelerCommitExistsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerCommitExistsCall) Do(f func(context.Context, git.Repository, *object.Commit) (digest.Digest, error)) *MockModelerCommitExistsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerCommitExistsCall) DoAndReturn(f func(context.Context, git.Repository, *object.Commit) (digest.Digest, error)) *MockModelerCommitExistsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Consolidate mocks base method.
+func (m *MockModeler) Consolidate(ctx context.Context, referrerUpdates ...model.ReferrerUpdater) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range referrerUpdates {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Consolidate", varargs...)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consolidate indicates an expected call of Consolidate.
+func (mr *MockModelerMockRecorder) Consolidate(ctx any, referrerUpdates ...any) *MockModelerConsolidateCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, referrerUpdates...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consolidate", reflect.TypeOf((*MockModeler)(nil).Consolidate), varargs...)
+	return &MockModelerConsolidateCall{Call: call}
+}
+
+// MockModelerConsolidateCall wrap *gomock.Call
+type MockModelerConsolidateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerConsolidateCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerConsolidateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerConsolidateCall) Do(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerConsolidateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerConsolidateCall) DoAndReturn(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerConsolidateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DefaultBranch mocks base method.
+func (m *MockModeler) DefaultBranch() plumbing.ReferenceName {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DefaultBranch")
+	ret0, _ := ret[0].(plumbing.ReferenceName)
+	return ret0
+}
+
+// DefaultBranch indicates an expected call of DefaultBranch.
+func (mr *MockModelerMockRecorder) DefaultBranch() *MockModelerDefaultBranchCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultBranch", reflect.TypeOf((*MockModeler)(nil).DefaultBranch))
+	return &MockModelerDefaultBranchCall{Call: call}
+}
+
+// MockModelerDefaultBranchCall wrap *gomock.Call
+type MockModelerDefaultBranchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerDefaultBranchCall) Return(arg0 plumbing.ReferenceName) *MockModelerDefaultBranchCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerDefaultBranchCall) Do(f func() plumbing.ReferenceName) *MockModelerDefaultBranchCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerDefaultBranchCall) DoAndReturn(f func() plumbing.ReferenceName) *MockModelerDefaultBranchCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Delete mocks base method.
+func (m *MockModeler) Delete(ctx context.Context, opts model.DeleteOptions) ([]model.Deletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, opts)
+	ret0, _ := ret[0].([]model.Deletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockModelerMockRecorder) Delete(ctx, opts any) *MockModelerDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockModeler)(nil).Delete), ctx, opts)
+	return &MockModelerDeleteCall{Call: call}
+}
+
+// MockModelerDeleteCall wrap *gomock.Call
+type MockModelerDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerDeleteCall) Return(arg0 []model.Deletion, arg1 error) *MockModelerDeleteCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerDeleteCall) Do(f func(context.Context, model.DeleteOptions) ([]model.Deletion, error)) *MockModelerDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerDeleteCall) DoAndReturn(f func(context.Context, model.DeleteOptions) ([]model.Deletion, error)) *MockModelerDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteRef mocks base method.
+func (m *MockModeler) DeleteRef(ctx context.Context, refName plumbing.ReferenceName) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRef", ctx, refName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRef indicates an expected call of DeleteRef.
+func (mr *MockModelerMockRecorder) DeleteRef(ctx, refName any) *MockModelerDeleteRefCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRef", reflect.TypeOf((*MockModeler)(nil).DeleteRef), ctx, refName)
+	return &MockModelerDeleteRefCall{Call: call}
+}
+
+// MockModelerDeleteRefCall wrap *gomock.Call
+type MockModelerDeleteRefCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerDeleteRefCall) Return(arg0 error) *MockModelerDeleteRefCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerDeleteRefCall) Do(f func(context.Context, plumbing.ReferenceName) error) *MockModelerDeleteRefCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerDeleteRefCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) error) *MockModelerDeleteRefCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Fetch mocks base method.
+func (m *MockModeler) Fetch(ctx context.Context) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", ctx)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockModelerMockRecorder) Fetch(ctx any) *MockModelerFetchCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockModeler)(nil).Fetch), ctx)
+	return &MockModelerFetchCall{Call: call}
+}
+
+// MockModelerFetchCall wrap *gomock.Call
+type MockModelerFetchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerFetchCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FetchConfigOnly mocks base method.
+func (m *MockModeler) FetchConfigOnly(ctx context.Context) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchConfigOnly", ctx)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchConfigOnly indicates an expected call of FetchConfigOnly.
+func (mr *MockModelerMockRecorder) FetchConfigOnly(ctx any) *MockModelerFetchConfigOnlyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchConfigOnly", reflect.TypeOf((*MockModeler)(nil).FetchConfigOnly), ctx)
+	return &MockModelerFetchConfigOnlyCall{Call: call}
+}
+
+// MockModelerFetchConfigOnlyCall wrap *gomock.Call
+type MockModelerFetchConfigOnlyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchConfigOnlyCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerFetchConfigOnlyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchConfigOnlyCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchConfigOnlyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchConfigOnlyCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchConfigOnlyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FetchLayer mocks base method.
+func (m *MockModeler) FetchLayer(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchLayer", ctx, dgst)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchLayer indicates an expected call of FetchLayer.
+func (mr *MockModelerMockRecorder) FetchLayer(ctx, dgst any) *MockModelerFetchLayerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLayer", reflect.TypeOf((*MockModeler)(nil).FetchLayer), ctx, dgst)
+	return &MockModelerFetchLayerCall{Call: call}
+}
+
+// MockModelerFetchLayerCall wrap *gomock.Call
+type MockModelerFetchLayerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchLayerCall) Return(arg0 io.ReadCloser, arg1 error) *MockModelerFetchLayerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchLayerCall) Do(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockModelerFetchLayerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchLayerCall) DoAndReturn(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockModelerFetchLayerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FetchLayersReverse mocks base method.
+func (m *MockModeler) FetchLayersReverse(ctx context.Context) iter.Seq2[io.ReadCloser, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchLayersReverse", ctx)
 	ret0, _ := ret[0].(iter.Seq2[io.ReadCloser, error])
 	return ret0
 }
 
-// FetchLayersReverse indicates an expected call of FetchLayersReverse.
-func (mr *MockModelerMockRecorder) FetchLayersReverse(ctx any) *MockModelerFetchLayersReverseCall {
+// FetchLayersReverse indicates an expected call of FetchLayersReverse.
+func (mr *MockModelerMockRecorder) FetchLayersReverse(ctx any) *MockModelerFetchLayersReverseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLayersReverse", reflect.TypeOf((*MockModeler)(nil).FetchLayersReverse), ctx)
+	return &MockModelerFetchLayersReverseCall{Call: call}
+}
+
+// MockModelerFetchLayersReverseCall wrap *gomock.Call
+type MockModelerFetchLayersReverseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchLayersReverseCall) Return(arg0 iter.Seq2[io.ReadCloser, error]) *MockModelerFetchLayersReverseCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchLayersReverseCall) Do(f func(context.Context) iter.Seq2[io.ReadCloser, error]) *MockModelerFetchLayersReverseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchLayersReverseCall) DoAndReturn(f func(context.Context) iter.Seq2[io.ReadCloser, error]) *MockModelerFetchLayersReverseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FetchMetadata mocks base method.
+func (m *MockModeler) FetchMetadata(ctx context.Context) (oci.Metadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchMetadata", ctx)
+	ret0, _ := ret[0].(oci.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchMetadata indicates an expected call of FetchMetadata.
+func (mr *MockModelerMockRecorder) FetchMetadata(ctx any) *MockModelerFetchMetadataCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchMetadata", reflect.TypeOf((*MockModeler)(nil).FetchMetadata), ctx)
+	return &MockModelerFetchMetadataCall{Call: call}
+}
+
+// MockModelerFetchMetadataCall wrap *gomock.Call
+type MockModelerFetchMetadataCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchMetadataCall) Return(arg0 oci.Metadata, arg1 error) *MockModelerFetchMetadataCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchMetadataCall) Do(f func(context.Context) (oci.Metadata, error)) *MockModelerFetchMetadataCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchMetadataCall) DoAndReturn(f func(context.Context) (oci.Metadata, error)) *MockModelerFetchMetadataCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FetchOrDefault mocks base method.
+func (m *MockModeler) FetchOrDefault(ctx context.Context) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOrDefault", ctx)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOrDefault indicates an expected call of FetchOrDefault.
+func (mr *MockModelerMockRecorder) FetchOrDefault(ctx any) *MockModelerFetchOrDefaultCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrDefault", reflect.TypeOf((*MockModeler)(nil).FetchOrDefault), ctx)
+	return &MockModelerFetchOrDefaultCall{Call: call}
+}
+
+// MockModelerFetchOrDefaultCall wrap *gomock.Call
+type MockModelerFetchOrDefaultCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchOrDefaultCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerFetchOrDefaultCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchOrDefaultCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchOrDefaultCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchOrDefaultCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchOrDefaultCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FetchOrEmpty mocks base method.
+func (m *MockModeler) FetchOrEmpty(ctx context.Context) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOrEmpty", ctx)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOrEmpty indicates an expected call of FetchOrEmpty.
+func (mr *MockModelerMockRecorder) FetchOrEmpty(ctx any) *MockModelerFetchOrEmptyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrEmpty", reflect.TypeOf((*MockModeler)(nil).FetchOrEmpty), ctx)
+	return &MockModelerFetchOrEmptyCall{Call: call}
+}
+
+// MockModelerFetchOrEmptyCall wrap *gomock.Call
+type MockModelerFetchOrEmptyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchOrEmptyCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerFetchOrEmptyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchOrEmptyCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchOrEmptyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchOrEmptyCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchOrEmptyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FetchPackIndex mocks base method.
+func (m *MockModeler) FetchPackIndex(ctx context.Context, layer digest.Digest) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchPackIndex", ctx, layer)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchPackIndex indicates an expected call of FetchPackIndex.
+func (mr *MockModelerMockRecorder) FetchPackIndex(ctx, layer any) *MockModelerFetchPackIndexCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPackIndex", reflect.TypeOf((*MockModeler)(nil).FetchPackIndex), ctx, layer)
+	return &MockModelerFetchPackIndexCall{Call: call}
+}
+
+// MockModelerFetchPackIndexCall wrap *gomock.Call
+type MockModelerFetchPackIndexCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFetchPackIndexCall) Return(arg0 io.ReadCloser, arg1 error) *MockModelerFetchPackIndexCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFetchPackIndexCall) Do(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockModelerFetchPackIndexCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFetchPackIndexCall) DoAndReturn(f func(context.Context, digest.Digest) (io.ReadCloser, error)) *MockModelerFetchPackIndexCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Fsck mocks base method.
+func (m *MockModeler) Fsck(ctx context.Context) (*model.FsckReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fsck", ctx)
+	ret0, _ := ret[0].(*model.FsckReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fsck indicates an expected call of Fsck.
+func (mr *MockModelerMockRecorder) Fsck(ctx any) *MockModelerFsckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fsck", reflect.TypeOf((*MockModeler)(nil).Fsck), ctx)
+	return &MockModelerFsckCall{Call: call}
+}
+
+// MockModelerFsckCall wrap *gomock.Call
+type MockModelerFsckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerFsckCall) Return(arg0 *model.FsckReport, arg1 error) *MockModelerFsckCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerFsckCall) Do(f func(context.Context) (*model.FsckReport, error)) *MockModelerFsckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerFsckCall) DoAndReturn(f func(context.Context) (*model.FsckReport, error)) *MockModelerFsckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// HeadRefs mocks base method.
+func (m *MockModeler) HeadRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeadRefs")
+	ret0, _ := ret[0].(map[plumbing.ReferenceName]oci.ReferenceInfo)
+	return ret0
+}
+
+// HeadRefs indicates an expected call of HeadRefs.
+func (mr *MockModelerMockRecorder) HeadRefs() *MockModelerHeadRefsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadRefs", reflect.TypeOf((*MockModeler)(nil).HeadRefs))
+	return &MockModelerHeadRefsCall{Call: call}
+}
+
+// MockModelerHeadRefsCall wrap *gomock.Call
+type MockModelerHeadRefsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerHeadRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerHeadRefsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerHeadRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerHeadRefsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerHeadRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerHeadRefsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// History mocks base method.
+func (m *MockModeler) History(ctx context.Context, n int) ([]model.ManifestStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, n)
+	ret0, _ := ret[0].([]model.ManifestStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockModelerMockRecorder) History(ctx, n any) *MockModelerHistoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockModeler)(nil).History), ctx, n)
+	return &MockModelerHistoryCall{Call: call}
+}
+
+// MockModelerHistoryCall wrap *gomock.Call
+type MockModelerHistoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerHistoryCall) Return(arg0 []model.ManifestStats, arg1 error) *MockModelerHistoryCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerHistoryCall) Do(f func(context.Context, int) ([]model.ManifestStats, error)) *MockModelerHistoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerHistoryCall) DoAndReturn(f func(context.Context, int) ([]model.ManifestStats, error)) *MockModelerHistoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// IsAncestor mocks base method.
+func (m *MockModeler) IsAncestor(ctx context.Context, ancestor, commit plumbing.Hash) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAncestor", ctx, ancestor, commit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAncestor indicates an expected call of IsAncestor.
+func (mr *MockModelerMockRecorder) IsAncestor(ctx, ancestor, commit any) *MockModelerIsAncestorCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAncestor", reflect.TypeOf((*MockModeler)(nil).IsAncestor), ctx, ancestor, commit)
+	return &MockModelerIsAncestorCall{Call: call}
+}
+
+// MockModelerIsAncestorCall wrap *gomock.Call
+type MockModelerIsAncestorCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerIsAncestorCall) Return(arg0 bool, arg1 error) *MockModelerIsAncestorCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerIsAncestorCall) Do(f func(context.Context, plumbing.Hash, plumbing.Hash) (bool, error)) *MockModelerIsAncestorCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerIsAncestorCall) DoAndReturn(f func(context.Context, plumbing.Hash, plumbing.Hash) (bool, error)) *MockModelerIsAncestorCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Layers mocks base method.
+func (m *MockModeler) Layers() []v1.Descriptor {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Layers")
+	ret0, _ := ret[0].([]v1.Descriptor)
+	return ret0
+}
+
+// Layers indicates an expected call of Layers.
+func (mr *MockModelerMockRecorder) Layers() *MockModelerLayersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Layers", reflect.TypeOf((*MockModeler)(nil).Layers))
+	return &MockModelerLayersCall{Call: call}
+}
+
+// MockModelerLayersCall wrap *gomock.Call
+type MockModelerLayersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerLayersCall) Return(arg0 []v1.Descriptor) *MockModelerLayersCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerLayersCall) Do(f func() []v1.Descriptor) *MockModelerLayersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerLayersCall) DoAndReturn(f func() []v1.Descriptor) *MockModelerLayersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Lock mocks base method.
+func (m *MockModeler) Lock(ctx context.Context, opts model.LockOptions) (*model.Lock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, opts)
+	ret0, _ := ret[0].(*model.Lock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockModelerMockRecorder) Lock(ctx, opts any) *MockModelerLockCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockModeler)(nil).Lock), ctx, opts)
+	return &MockModelerLockCall{Call: call}
+}
+
+// MockModelerLockCall wrap *gomock.Call
+type MockModelerLockCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerLockCall) Return(arg0 *model.Lock, arg1 error) *MockModelerLockCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerLockCall) Do(f func(context.Context, model.LockOptions) (*model.Lock, error)) *MockModelerLockCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerLockCall) DoAndReturn(f func(context.Context, model.LockOptions) (*model.Lock, error)) *MockModelerLockCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// NoteRefs mocks base method.
+func (m *MockModeler) NoteRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NoteRefs")
+	ret0, _ := ret[0].(map[plumbing.ReferenceName]oci.ReferenceInfo)
+	return ret0
+}
+
+// NoteRefs indicates an expected call of NoteRefs.
+func (mr *MockModelerMockRecorder) NoteRefs() *MockModelerNoteRefsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NoteRefs", reflect.TypeOf((*MockModeler)(nil).NoteRefs))
+	return &MockModelerNoteRefsCall{Call: call}
+}
+
+// MockModelerNoteRefsCall wrap *gomock.Call
+type MockModelerNoteRefsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerNoteRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerNoteRefsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerNoteRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerNoteRefsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerNoteRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerNoteRefsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ObjectFormat mocks base method.
+func (m *MockModeler) ObjectFormat() config.ObjectFormat {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ObjectFormat")
+	ret0, _ := ret[0].(config.ObjectFormat)
+	return ret0
+}
+
+// ObjectFormat indicates an expected call of ObjectFormat.
+func (mr *MockModelerMockRecorder) ObjectFormat() *MockModelerObjectFormatCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObjectFormat", reflect.TypeOf((*MockModeler)(nil).ObjectFormat))
+	return &MockModelerObjectFormatCall{Call: call}
+}
+
+// MockModelerObjectFormatCall wrap *gomock.Call
+type MockModelerObjectFormatCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerObjectFormatCall) Return(arg0 config.ObjectFormat) *MockModelerObjectFormatCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerObjectFormatCall) Do(f func() config.ObjectFormat) *MockModelerObjectFormatCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerObjectFormatCall) DoAndReturn(f func() config.ObjectFormat) *MockModelerObjectFormatCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Prefetch mocks base method.
+func (m *MockModeler) Prefetch(ctx context.Context, dgsts ...digest.Digest) (int, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range dgsts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Prefetch", varargs...)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prefetch indicates an expected call of Prefetch.
+func (mr *MockModelerMockRecorder) Prefetch(ctx any, dgsts ...any) *MockModelerPrefetchCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, dgsts...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prefetch", reflect.TypeOf((*MockModeler)(nil).Prefetch), varargs...)
+	return &MockModelerPrefetchCall{Call: call}
+}
+
+// MockModelerPrefetchCall wrap *gomock.Call
+type MockModelerPrefetchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerPrefetchCall) Return(arg0 int, arg1 error) *MockModelerPrefetchCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerPrefetchCall) Do(f func(context.Context, ...digest.Digest) (int, error)) *MockModelerPrefetchCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerPrefetchCall) DoAndReturn(f func(context.Context, ...digest.Digest) (int, error)) *MockModelerPrefetchCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Push mocks base method.
+func (m *MockModeler) Push(ctx context.Context, referrerUpdates ...model.ReferrerUpdater) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range referrerUpdates {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Push", varargs...)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockModelerMockRecorder) Push(ctx any, referrerUpdates ...any) *MockModelerPushCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, referrerUpdates...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockModeler)(nil).Push), varargs...)
+	return &MockModelerPushCall{Call: call}
+}
+
+// MockModelerPushCall wrap *gomock.Call
+type MockModelerPushCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerPushCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerPushCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerPushCall) Do(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerPushCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerPushCall) DoAndReturn(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerPushCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PushAtomic mocks base method.
+func (m *MockModeler) PushAtomic(ctx context.Context, referrerUpdates ...model.ReferrerUpdater) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range referrerUpdates {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PushAtomic", varargs...)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushAtomic indicates an expected call of PushAtomic.
+func (mr *MockModelerMockRecorder) PushAtomic(ctx any, referrerUpdates ...any) *MockModelerPushAtomicCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, referrerUpdates...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushAtomic", reflect.TypeOf((*MockModeler)(nil).PushAtomic), varargs...)
+	return &MockModelerPushAtomicCall{Call: call}
+}
+
+// MockModelerPushAtomicCall wrap *gomock.Call
+type MockModelerPushAtomicCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerPushAtomicCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerPushAtomicCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerPushAtomicCall) Do(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerPushAtomicCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerPushAtomicCall) DoAndReturn(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerPushAtomicCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PushMetadata mocks base method.
+func (m *MockModeler) PushMetadata(ctx context.Context, subject v1.Descriptor, meta oci.Metadata, readme []byte) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushMetadata", ctx, subject, meta, readme)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushMetadata indicates an expected call of PushMetadata.
+func (mr *MockModelerMockRecorder) PushMetadata(ctx, subject, meta, readme any) *MockModelerPushMetadataCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushMetadata", reflect.TypeOf((*MockModeler)(nil).PushMetadata), ctx, subject, meta, readme)
+	return &MockModelerPushMetadataCall{Call: call}
+}
+
+// MockModelerPushMetadataCall wrap *gomock.Call
+type MockModelerPushMetadataCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerPushMetadataCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerPushMetadataCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerPushMetadataCall) Do(f func(context.Context, v1.Descriptor, oci.Metadata, []byte) (v1.Descriptor, error)) *MockModelerPushMetadataCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerPushMetadataCall) DoAndReturn(f func(context.Context, v1.Descriptor, oci.Metadata, []byte) (v1.Descriptor, error)) *MockModelerPushMetadataCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Ref mocks base method.
+func (m *MockModeler) Ref() registry.Reference {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ref")
+	ret0, _ := ret[0].(registry.Reference)
+	return ret0
+}
+
+// Ref indicates an expected call of Ref.
+func (mr *MockModelerMockRecorder) Ref() *MockModelerRefCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ref", reflect.TypeOf((*MockModeler)(nil).Ref))
+	return &MockModelerRefCall{Call: call}
+}
+
+// MockModelerRefCall wrap *gomock.Call
+type MockModelerRefCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerRefCall) Return(arg0 registry.Reference) *MockModelerRefCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerRefCall) Do(f func() registry.Reference) *MockModelerRefCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerRefCall) DoAndReturn(f func() registry.Reference) *MockModelerRefCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ResolveCommit mocks base method.
+func (m *MockModeler) ResolveCommit(ctx context.Context, commit plumbing.Hash) (digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveCommit", ctx, commit)
+	ret0, _ := ret[0].(digest.Digest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveCommit indicates an expected call of ResolveCommit.
+func (mr *MockModelerMockRecorder) ResolveCommit(ctx, commit any) *MockModelerResolveCommitCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLayersReverse", reflect.TypeOf((*MockModeler)(nil).FetchLayersReverse), ctx)
-	return &MockModelerFetchLayersReverseCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveCommit", reflect.TypeOf((*MockModeler)(nil).ResolveCommit), ctx, commit)
+	return &MockModelerResolveCommitCall{Call: call}
 }
 
-// MockModelerFetchLayersReverseCall wrap *gomock.Call
-type MockModelerFetchLayersReverseCall struct {
+// MockModelerResolveCommitCall wrap *gomock.Call
+type MockModelerResolveCommitCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerFetchLayersReverseCall) Return(arg0 iter.Seq2[io.ReadCloser, error]) *MockModelerFetchLayersReverseCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockModelerResolveCommitCall) Return(arg0 digest.Digest, arg1 error) *MockModelerResolveCommitCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerFetchLayersReverseCall) Do(f func(context.Context) iter.Seq2[io.ReadCloser, error]) *MockModelerFetchLayersReverseCall {
+func (c *MockModelerResolveCommitCall) Do(f func(context.Context, plumbing.Hash) (digest.Digest, error)) *MockModelerResolveCommitCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerFetchLayersReverseCall) DoAndReturn(f func(context.Context) iter.Seq2[io.ReadCloser, error]) *MockModelerFetchLayersReverseCall {
+func (c *MockModelerResolveCommitCall) DoAndReturn(f func(context.Context, plumbing.Hash) (digest.Digest, error)) *MockModelerResolveCommitCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// FetchOrDefault mocks base method.
-func (m *MockModeler) FetchOrDefault(ctx context.Context) (v1.Descriptor, error) {
+// ResolveRef mocks base method.
+func (m *MockModeler) ResolveRef(ctx context.Context, refName plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchOrDefault", ctx)
-	ret0, _ := ret[0].(v1.Descriptor)
+	ret := m.ctrl.Call(m, "ResolveRef", ctx, refName)
+	ret0, _ := ret[0].(*plumbing.Reference)
+	ret1, _ := ret[1].(digest.Digest)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResolveRef indicates an expected call of ResolveRef.
+func (mr *MockModelerMockRecorder) ResolveRef(ctx, refName any) *MockModelerResolveRefCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveRef", reflect.TypeOf((*MockModeler)(nil).ResolveRef), ctx, refName)
+	return &MockModelerResolveRefCall{Call: call}
+}
+
+// MockModelerResolveRefCall wrap *gomock.Call
+type MockModelerResolveRefCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerResolveRefCall) Return(arg0 *plumbing.Reference, arg1 digest.Digest, arg2 error) *MockModelerResolveRefCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerResolveRefCall) Do(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockModelerResolveRefCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerResolveRefCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockModelerResolveRefCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ResolveRefLayers mocks base method.
+func (m *MockModeler) ResolveRefLayers(ctx context.Context, refName plumbing.ReferenceName) ([]digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveRefLayers", ctx, refName)
+	ret0, _ := ret[0].([]digest.Digest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FetchOrDefault indicates an expected call of FetchOrDefault.
-func (mr *MockModelerMockRecorder) FetchOrDefault(ctx any) *MockModelerFetchOrDefaultCall {
+// ResolveRefLayers indicates an expected call of ResolveRefLayers.
+func (mr *MockModelerMockRecorder) ResolveRefLayers(ctx, refName any) *MockModelerResolveRefLayersCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrDefault", reflect.TypeOf((*MockModeler)(nil).FetchOrDefault), ctx)
-	return &MockModelerFetchOrDefaultCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveRefLayers", reflect.TypeOf((*MockModeler)(nil).ResolveRefLayers), ctx, refName)
+	return &MockModelerResolveRefLayersCall{Call: call}
 }
 
-// MockModelerFetchOrDefaultCall wrap *gomock.Call
-type MockModelerFetchOrDefaultCall struct {
+// MockModelerResolveRefLayersCall wrap *gomock.Call
+type MockModelerResolveRefLayersCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerFetchOrDefaultCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerFetchOrDefaultCall {
+func (c *MockModelerResolveRefLayersCall) Return(arg0 []digest.Digest, arg1 error) *MockModelerResolveRefLayersCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerFetchOrDefaultCall) Do(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchOrDefaultCall {
+func (c *MockModelerResolveRefLayersCall) Do(f func(context.Context, plumbing.ReferenceName) ([]digest.Digest, error)) *MockModelerResolveRefLayersCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerFetchOrDefaultCall) DoAndReturn(f func(context.Context) (v1.Descriptor, error)) *MockModelerFetchOrDefaultCall {
+func (c *MockModelerResolveRefLayersCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) ([]digest.Digest, error)) *MockModelerResolveRefLayersCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// HeadRefs mocks base method.
-func (m *MockModeler) HeadRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
+// SetDefaultBranch mocks base method.
+func (m *MockModeler) SetDefaultBranch(ctx context.Context, refName plumbing.ReferenceName) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HeadRefs")
-	ret0, _ := ret[0].(map[plumbing.ReferenceName]oci.ReferenceInfo)
+	ret := m.ctrl.Call(m, "SetDefaultBranch", ctx, refName)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// HeadRefs indicates an expected call of HeadRefs.
-func (mr *MockModelerMockRecorder) HeadRefs() *MockModelerHeadRefsCall {
+// SetDefaultBranch indicates an expected call of SetDefaultBranch.
+func (mr *MockModelerMockRecorder) SetDefaultBranch(ctx, refName any) *MockModelerSetDefaultBranchCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadRefs", reflect.TypeOf((*MockModeler)(nil).HeadRefs))
-	return &MockModelerHeadRefsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultBranch", reflect.TypeOf((*MockModeler)(nil).SetDefaultBranch), ctx, refName)
+	return &MockModelerSetDefaultBranchCall{Call: call}
 }
 
-// MockModelerHeadRefsCall wrap *gomock.Call
-type MockModelerHeadRefsCall struct {
+// MockModelerSetDefaultBranchCall wrap *gomock.Call
+type MockModelerSetDefaultBranchCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerHeadRefsCall) Return(arg0 map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerHeadRefsCall {
+func (c *MockModelerSetDefaultBranchCall) Return(arg0 error) *MockModelerSetDefaultBranchCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerHeadRefsCall) Do(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerHeadRefsCall {
+func (c *MockModelerSetDefaultBranchCall) Do(f func(context.Context, plumbing.ReferenceName) error) *MockModelerSetDefaultBranchCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerHeadRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName]oci.ReferenceInfo) *MockModelerHeadRefsCall {
+func (c *MockModelerSetDefaultBranchCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) error) *MockModelerSetDefaultBranchCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Push mocks base method.
-func (m *MockModeler) Push(ctx context.Context, referrerUpdates ...model.ReferrerUpdater) (v1.Descriptor, error) {
+// SetObjectFormat mocks base method.
+func (m *MockModeler) SetObjectFormat(ctx context.Context, format config.ObjectFormat) error {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx}
-	for _, a := range referrerUpdates {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "Push", varargs...)
-	ret0, _ := ret[0].(v1.Descriptor)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SetObjectFormat", ctx, format)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Push indicates an expected call of Push.
-func (mr *MockModelerMockRecorder) Push(ctx any, referrerUpdates ...any) *MockModelerPushCall {
+// SetObjectFormat indicates an expected call of SetObjectFormat.
+func (mr *MockModelerMockRecorder) SetObjectFormat(ctx, format any) *MockModelerSetObjectFormatCall {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx}, referrerUpdates...)
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockModeler)(nil).Push), varargs...)
-	return &MockModelerPushCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetObjectFormat", reflect.TypeOf((*MockModeler)(nil).SetObjectFormat), ctx, format)
+	return &MockModelerSetObjectFormatCall{Call: call}
 }
 
-// MockModelerPushCall wrap *gomock.Call
-type MockModelerPushCall struct {
+// MockModelerSetObjectFormatCall wrap *gomock.Call
+type MockModelerSetObjectFormatCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerPushCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerPushCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockModelerSetObjectFormatCall) Return(arg0 error) *MockModelerSetObjectFormatCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerPushCall) Do(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerPushCall {
+func (c *MockModelerSetObjectFormatCall) Do(f func(context.Context, config.ObjectFormat) error) *MockModelerSetObjectFormatCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerPushCall) DoAndReturn(f func(context.Context, ...model.ReferrerUpdater) (v1.Descriptor, error)) *MockModelerPushCall {
+func (c *MockModelerSetObjectFormatCall) DoAndReturn(f func(context.Context, config.ObjectFormat) error) *MockModelerSetObjectFormatCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Ref mocks base method.
-func (m *MockModeler) Ref() registry.Reference {
+// SetPeeled mocks base method.
+func (m *MockModeler) SetPeeled(ctx context.Context, refName plumbing.ReferenceName, target plumbing.Hash) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Ref")
-	ret0, _ := ret[0].(registry.Reference)
+	ret := m.ctrl.Call(m, "SetPeeled", ctx, refName, target)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Ref indicates an expected call of Ref.
-func (mr *MockModelerMockRecorder) Ref() *MockModelerRefCall {
+// SetPeeled indicates an expected call of SetPeeled.
+func (mr *MockModelerMockRecorder) SetPeeled(ctx, refName, target any) *MockModelerSetPeeledCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ref", reflect.TypeOf((*MockModeler)(nil).Ref))
-	return &MockModelerRefCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPeeled", reflect.TypeOf((*MockModeler)(nil).SetPeeled), ctx, refName, target)
+	return &MockModelerSetPeeledCall{Call: call}
 }
 
-// MockModelerRefCall wrap *gomock.Call
-type MockModelerRefCall struct {
+// MockModelerSetPeeledCall wrap *gomock.Call
+type MockModelerSetPeeledCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerRefCall) Return(arg0 registry.Reference) *MockModelerRefCall {
+func (c *MockModelerSetPeeledCall) Return(arg0 error) *MockModelerSetPeeledCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerRefCall) Do(f func() registry.Reference) *MockModelerRefCall {
+func (c *MockModelerSetPeeledCall) Do(f func(context.Context, plumbing.ReferenceName, plumbing.Hash) error) *MockModelerSetPeeledCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerRefCall) DoAndReturn(f func() registry.Reference) *MockModelerRefCall {
+func (c *MockModelerSetPeeledCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName, plumbing.Hash) error) *MockModelerSetPeeledCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ResolveRef mocks base method.
-func (m *MockModeler) ResolveRef(ctx context.Context, refName plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error) {
+// SetShallow mocks base method.
+func (m *MockModeler) SetShallow(ctx context.Context, commits []plumbing.Hash) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ResolveRef", ctx, refName)
-	ret0, _ := ret[0].(*plumbing.Reference)
-	ret1, _ := ret[1].(digest.Digest)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "SetShallow", ctx, commits)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ResolveRef indicates an expected call of ResolveRef.
-func (mr *MockModelerMockRecorder) ResolveRef(ctx, refName any) *MockModelerResolveRefCall {
+// SetShallow indicates an expected call of SetShallow.
+func (mr *MockModelerMockRecorder) SetShallow(ctx, commits any) *MockModelerSetShallowCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveRef", reflect.TypeOf((*MockModeler)(nil).ResolveRef), ctx, refName)
-	return &MockModelerResolveRefCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShallow", reflect.TypeOf((*MockModeler)(nil).SetShallow), ctx, commits)
+	return &MockModelerSetShallowCall{Call: call}
 }
 
-// MockModelerResolveRefCall wrap *gomock.Call
-type MockModelerResolveRefCall struct {
+// MockModelerSetShallowCall wrap *gomock.Call
+type MockModelerSetShallowCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockModelerResolveRefCall) Return(arg0 *plumbing.Reference, arg1 digest.Digest, arg2 error) *MockModelerResolveRefCall {
-	c.Call = c.Call.Return(arg0, arg1, arg2)
+func (c *MockModelerSetShallowCall) Return(arg0 error) *MockModelerSetShallowCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockModelerResolveRefCall) Do(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockModelerResolveRefCall {
+func (c *MockModelerSetShallowCall) Do(f func(context.Context, []plumbing.Hash) error) *MockModelerSetShallowCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockModelerResolveRefCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)) *MockModelerResolveRefCall {
+func (c *MockModelerSetShallowCall) DoAndReturn(f func(context.Context, []plumbing.Hash) error) *MockModelerSetShallowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Shallow mocks base method.
+func (m *MockModeler) Shallow() []plumbing.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shallow")
+	ret0, _ := ret[0].([]plumbing.Hash)
+	return ret0
+}
+
+// Shallow indicates an expected call of Shallow.
+func (mr *MockModelerMockRecorder) Shallow() *MockModelerShallowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shallow", reflect.TypeOf((*MockModeler)(nil).Shallow))
+	return &MockModelerShallowCall{Call: call}
+}
+
+// MockModelerShallowCall wrap *gomock.Call
+type MockModelerShallowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerShallowCall) Return(arg0 []plumbing.Hash) *MockModelerShallowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerShallowCall) Do(f func() []plumbing.Hash) *MockModelerShallowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerShallowCall) DoAndReturn(f func() []plumbing.Hash) *MockModelerShallowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Sign mocks base method.
+func (m *MockModeler) Sign(ctx context.Context, signer crypto.Signer) (v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", ctx, signer)
+	ret0, _ := ret[0].(v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockModelerMockRecorder) Sign(ctx, signer any) *MockModelerSignCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockModeler)(nil).Sign), ctx, signer)
+	return &MockModelerSignCall{Call: call}
+}
+
+// MockModelerSignCall wrap *gomock.Call
+type MockModelerSignCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerSignCall) Return(arg0 v1.Descriptor, arg1 error) *MockModelerSignCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerSignCall) Do(f func(context.Context, crypto.Signer) (v1.Descriptor, error)) *MockModelerSignCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerSignCall) DoAndReturn(f func(context.Context, crypto.Signer) (v1.Descriptor, error)) *MockModelerSignCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -896,6 +2507,45 @@ func (c *MockModelerTagRefsCall) DoAndReturn(f func() map[plumbing.ReferenceName
 	return c
 }
 
+// TagSnapshot mocks base method.
+func (m *MockModeler) TagSnapshot(ctx context.Context, refName plumbing.ReferenceName) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagSnapshot", ctx, refName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagSnapshot indicates an expected call of TagSnapshot.
+func (mr *MockModelerMockRecorder) TagSnapshot(ctx, refName any) *MockModelerTagSnapshotCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagSnapshot", reflect.TypeOf((*MockModeler)(nil).TagSnapshot), ctx, refName)
+	return &MockModelerTagSnapshotCall{Call: call}
+}
+
+// MockModelerTagSnapshotCall wrap *gomock.Call
+type MockModelerTagSnapshotCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerTagSnapshotCall) Return(arg0 string, arg1 error) *MockModelerTagSnapshotCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerTagSnapshotCall) Do(f func(context.Context, plumbing.ReferenceName) (string, error)) *MockModelerTagSnapshotCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerTagSnapshotCall) DoAndReturn(f func(context.Context, plumbing.ReferenceName) (string, error)) *MockModelerTagSnapshotCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateRef mocks base method.
 func (m *MockModeler) UpdateRef(ctx context.Context, ref *plumbing.Reference, ociLayer digest.Digest) error {
 	m.ctrl.T.Helper()
@@ -933,3 +2583,46 @@ func (c *MockModelerUpdateRefCall) DoAndReturn(f func(context.Context, *plumbing
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Verify mocks base method.
+func (m *MockModeler) Verify(ctx context.Context, keys ...crypto.PublicKey) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Verify", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockModelerMockRecorder) Verify(ctx any, keys ...any) *MockModelerVerifyCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, keys...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockModeler)(nil).Verify), varargs...)
+	return &MockModelerVerifyCall{Call: call}
+}
+
+// MockModelerVerifyCall wrap *gomock.Call
+type MockModelerVerifyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockModelerVerifyCall) Return(arg0 error) *MockModelerVerifyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockModelerVerifyCall) Do(f func(context.Context, ...crypto.PublicKey) error) *MockModelerVerifyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockModelerVerifyCall) DoAndReturn(f func(context.Context, ...crypto.PublicKey) error) *MockModelerVerifyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrReservedAnnotation indicates a user annotation would replace an
+// annotation set by gnoci.
+var ErrReservedAnnotation = errors.New("reserved annotation")
+
+// reservedAnnotationPrefix is the prefix of the annotations of gnoci.
+const reservedAnnotationPrefix = "vnd.ai.act3."
+
+// reservedAnnotations are the OCI annotations set by gnoci.
+var reservedAnnotations = []string{
+	ocispec.AnnotationCreated,
+	ocispec.AnnotationSource,
+	ocispec.AnnotationRevision,
+	ocispec.AnnotationTitle,
+	ocispec.AnnotationRefName,
+}
+
+// Annotations are user annotations added to pushed Git manifests, their
+// config descriptors, and new packfile layers, e.g. team ownership or ticket
+// IDs.
+type Annotations struct {
+	// Manifest annotations are added to pushed Git manifests.
+	Manifest map[string]string
+	// Config annotations are added to the config descriptors of pushed Git
+	// manifests.
+	Config map[string]string
+	// Layers annotations are added to new packfile layers.
+	Layers map[string]string
+}
+
+// Validate returns an [ErrReservedAnnotation] error if an annotation would
+// replace one set by gnoci, or an error if a key is empty.
+func (a Annotations) Validate() error {
+	var errs []error
+	for _, annotations := range []map[string]string{a.Manifest, a.Config, a.Layers} {
+		for _, key := range slices.Sorted(maps.Keys(annotations)) {
+			errs = append(errs, ValidateAnnotationKey(key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAnnotationKey returns an [ErrReservedAnnotation] error if key is an
+// annotation set by gnoci, or an error if it is empty.
+func ValidateAnnotationKey(key string) error {
+	switch {
+	case key == "":
+		return errors.New("empty annotation key")
+	case strings.HasPrefix(key, reservedAnnotationPrefix), slices.Contains(reservedAnnotations, key):
+		return fmt.Errorf("%w: %s", ErrReservedAnnotation, key)
+	}
+	return nil
+}
+
+// WithAnnotations adds user annotations to pushed manifests and packfile
+// layers, replacing those of the same keys added by earlier options. See
+// [Annotations.Validate].
+func WithAnnotations(annotations Annotations) Option {
+	return func(m *model) {
+		m.userAnnotations.Manifest = mergeAnnotations(m.userAnnotations.Manifest, annotations.Manifest)
+		m.userAnnotations.Config = mergeAnnotations(m.userAnnotations.Config, annotations.Config)
+		m.userAnnotations.Layers = mergeAnnotations(m.userAnnotations.Layers, annotations.Layers)
+	}
+}
+
+// mergeAnnotations returns the annotations of dst replaced by those of src.
+func mergeAnnotations(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	maps.Copy(dst, src)
+	return dst
+}
+
+// WithCreated sets the creation time recorded in the
+// org.opencontainers.image.created annotation of pushed manifests and packfile
+// layers, called as each is pushed. Defaults to the POSIX epoch, such that
+// pushing identical content produces identical manifests, without recording
+// the creation time of layers.
+func WithCreated(created func() time.Time) Option {
+	return func(m *model) {
+		m.createdAt = created
+	}
+}
+
+// WithSourceURL records the URL of the source repository in the
+// org.opencontainers.image.source annotation of pushed Git manifests.
+func WithSourceURL(url string) Option {
+	return func(m *model) {
+		m.sourceURL = url
+	}
+}
+
+// created returns the org.opencontainers.image.created annotation of a pushed
+// manifest.
+func (m *model) created() string {
+	t := time.Unix(0, 0) // POSIX epoch
+	if m.createdAt != nil {
+		t = m.createdAt()
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// manifestAnnotations returns the annotations of the Git manifest, following
+// the conventions of the OCI image spec. The revision is the commit of the
+// default branch, if any.
+func (m *model) manifestAnnotations() map[string]string {
+	annotations := maps.Clone(m.userAnnotations.Manifest)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[ocispec.AnnotationCreated] = m.created()
+	if m.sourceURL != "" {
+		annotations[ocispec.AnnotationSource] = m.sourceURL
+	}
+	if info, ok := m.cfg.Heads[m.cfg.DefaultBranch]; ok {
+		annotations[ocispec.AnnotationRevision] = info.Commit
+	}
+	return annotations
+}
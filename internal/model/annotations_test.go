@@ -0,0 +1,84 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_manifestAnnotations(t *testing.T) {
+	const commit = "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"
+
+	t.Run("Default", func(t *testing.T) {
+		m := &model{}
+		assert.Equal(t, map[string]string{ocispec.AnnotationCreated: "1970-01-01T00:00:00Z"}, m.manifestAnnotations())
+	})
+
+	t.Run("Source and Revision", func(t *testing.T) {
+		m := &model{
+			cfg: oci.ConfigGit{
+				DefaultBranch: plumbing.Main,
+				Heads:         map[plumbing.ReferenceName]oci.ReferenceInfo{plumbing.Main: {Commit: commit}},
+			},
+		}
+		created := time.Date(2023, 11, 14, 22, 13, 20, 0, time.FixedZone("EST", -5*60*60))
+		WithCreated(func() time.Time { return created })(m)
+		WithSourceURL("https://example.com/team/project.git")(m)
+
+		assert.Equal(t, map[string]string{
+			ocispec.AnnotationCreated:  "2023-11-15T03:13:20Z",
+			ocispec.AnnotationSource:   "https://example.com/team/project.git",
+			ocispec.AnnotationRevision: commit,
+		}, m.manifestAnnotations())
+	})
+
+	t.Run("User Annotations", func(t *testing.T) {
+		m := &model{}
+		WithAnnotations(Annotations{Manifest: map[string]string{"com.example.team": "platform", "com.example.ticket": "ABC-1"}})(m)
+		WithAnnotations(Annotations{Manifest: map[string]string{"com.example.ticket": "ABC-2"}})(m)
+
+		assert.Equal(t, map[string]string{
+			ocispec.AnnotationCreated: "1970-01-01T00:00:00Z",
+			"com.example.team":        "platform",
+			"com.example.ticket":      "ABC-2",
+		}, m.manifestAnnotations())
+	})
+}
+
+func TestAnnotations_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations Annotations
+		wantErr     error
+	}{
+		{name: "Empty"},
+		{
+			name: "Valid",
+			annotations: Annotations{
+				Manifest: map[string]string{"com.example.ticket": "ABC-123", ocispec.AnnotationAuthors: "team"},
+				Layers:   map[string]string{"com.example.team": "platform"},
+			},
+		},
+		{name: "Reserved OCI", annotations: Annotations{Manifest: map[string]string{ocispec.AnnotationCreated: "now"}}, wantErr: ErrReservedAnnotation},
+		{name: "Reserved Prefix", annotations: Annotations{Layers: map[string]string{oci.AnnotationPackRefs: "{}"}}, wantErr: ErrReservedAnnotation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.annotations.Validate()
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("Empty Key", func(t *testing.T) {
+		assert.Error(t, Annotations{Config: map[string]string{"": "value"}}.Validate())
+	})
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"context"
+	_ "crypto/sha512" // registers SHA-512 blob digests
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// LFSOidAlgorithm is the hash algorithm of Git LFS object IDs.
+const LFSOidAlgorithm = digest.SHA256
+
+// WithDigestAlgorithm describes packfile and LFS layers with digests of alg as
+// they are added, e.g. [digest.SHA512]. Defaults to [digest.Canonical].
+// Existing layers are unaffected, layers of any supported algorithm are
+// fetched. Manifests and configs are always described by SHA-256 digests.
+func WithDigestAlgorithm(alg digest.Algorithm) Option {
+	return func(m *model) {
+		m.digestAlg = alg
+	}
+}
+
+// addBlob adds the file at path to the intermediate file store as name,
+// described by a digest of the configured algorithm. Without a file store, or
+// for non-canonical algorithms, the file is read from path when pushed.
+func (m *model) addBlob(ctx context.Context, name, mediaType, path string) (ocispec.Descriptor, error) {
+	if m.usesFileStore() {
+		return m.fstore.Add(ctx, name, mediaType, path) //nolint:wrapcheck
+	}
+	alg := m.digestAlg
+	if alg == "" {
+		alg = digest.Canonical
+	}
+	if !alg.Available() {
+		return ocispec.Descriptor{}, fmt.Errorf("%w: %s", digest.ErrDigestUnsupported, alg)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("opening blob: %w", err)
+	}
+	defer f.Close()
+
+	digester := alg.Digester()
+	n, err := io.Copy(digester.Hash(), f)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("digesting blob: %w", err)
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digester.Digest(),
+		Size:      n,
+		Annotations: map[string]string{
+			ocispec.AnnotationTitle: name,
+		},
+	}
+	m.blobPaths.Store(desc.Digest, path)
+
+	return desc, nil
+}
+
+// fetchAdded fetches a blob added with [model.addBlob], from its file if
+// not added to the intermediate file store.
+func (m *model) fetchAdded(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	path, ok := m.blobPaths.Load(desc.Digest)
+	if !ok {
+		if m.fstore == nil {
+			return nil, fmt.Errorf("%w: %s", errdef.ErrNotFound, desc.Digest)
+		}
+		return m.fstore.Fetch(ctx, desc) //nolint:wrapcheck
+	}
+
+	f, err := os.Open(path.(string))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errdef.ErrNotFound, desc.Digest, err)
+	}
+	return f, nil
+}
+
+// addedBlobs returns a fetcher of the blobs added with [model.addBlob].
+func (m *model) addedBlobs() content.Fetcher {
+	if m.usesFileStore() {
+		return m.fstore
+	}
+	return addedFetcher{m}
+}
+
+// usesFileStore returns true if added blobs are stored in the intermediate
+// file store, which only describes blobs by canonical digests.
+func (m *model) usesFileStore() bool {
+	return m.fstore != nil && (m.digestAlg == "" || m.digestAlg == digest.Canonical)
+}
+
+// addedFetcher is a [content.Fetcher] of the blobs added to a model.
+type addedFetcher struct {
+	m *model
+}
+
+// Fetch implements [content.Fetcher].
+func (f addedFetcher) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	return f.m.fetchAdded(ctx, desc)
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sourcegraph/conc/pool"
+
+	"github.com/act3-ai/gnoci/internal/cache"
+)
+
+// ErrCacheDisabled indicates the local cache required by an operation is not
+// enabled, see [WithCache].
+var ErrCacheDisabled = errors.New("local cache not enabled")
+
+// WithCache fetches packfile layers and LFS files through a local blob cache,
+// shared between operations and repositories.
+func WithCache(c *cache.Cache) Option {
+	return func(m *model) {
+		m.cache = c
+	}
+}
+
+// fetchBlob fetches a blob from st, through the local cache if enabled.
+func (m *model) fetchBlob(ctx context.Context, st Store, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	if m.cache == nil {
+		return st.Fetch(ctx, desc) //nolint:wrapcheck
+	}
+	return m.cache.Fetch(ctx, st, desc) //nolint:wrapcheck
+}
+
+func (m *model) Prefetch(ctx context.Context, dgsts ...digest.Digest) (int, error) {
+	descs, err := selectLayers(m.man.Layers, dgsts, func(desc ocispec.Descriptor, dgst digest.Digest) bool {
+		return desc.Digest == dgst
+	})
+	if err != nil {
+		return 0, err
+	}
+	return m.warm(ctx, m.gt, descs)
+}
+
+func (m *model) PrefetchLFS(ctx context.Context, dgsts ...digest.Digest) (int, error) {
+	descs, err := selectLayers(m.lfsMan.Layers, dgsts, func(desc ocispec.Descriptor, dgst digest.Digest) bool {
+		return desc.Digest == dgst || lfsObjectMatches(desc, dgst)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(dgsts) > 0 {
+		// all layers already include the chunks of chunked LFS files
+		descs, err = m.withLFSChunks(ctx, descs)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return m.warm(ctx, m.lfsStore(), descs)
+}
+
+// selectLayers returns the layers matching dgsts, all layers if none are
+// given.
+func selectLayers(layers []ocispec.Descriptor, dgsts []digest.Digest, matches func(ocispec.Descriptor, digest.Digest) bool) ([]ocispec.Descriptor, error) {
+	if len(dgsts) == 0 {
+		return layers, nil
+	}
+
+	descs := make([]ocispec.Descriptor, 0, len(dgsts))
+	for _, dgst := range dgsts {
+		i := slices.IndexFunc(layers, func(desc ocispec.Descriptor) bool { return matches(desc, dgst) })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", errLayerNotInManifest, dgst)
+		}
+		descs = append(descs, layers[i])
+	}
+	return descs, nil
+}
+
+// warm fetches the blobs of descs from st into the local cache, as stored,
+// returning the number not previously cached.
+func (m *model) warm(ctx context.Context, st Store, descs []ocispec.Descriptor) (int, error) {
+	if m.cache == nil {
+		return 0, ErrCacheDisabled
+	}
+
+	concurrency := m.concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	p := pool.NewWithResults[bool]().WithErrors().WithContext(ctx).WithMaxGoroutines(concurrency)
+	for _, desc := range descs {
+		p.Go(func(ctx context.Context) (bool, error) {
+			fetched, err := m.cache.Warm(ctx, st, desc)
+			if err != nil {
+				return false, fmt.Errorf("caching layer %s: %w", desc.Digest, err)
+			}
+			return fetched, nil
+		})
+	}
+	results, err := p.Wait()
+	if err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+
+	var n int
+	for _, fetched := range results {
+		if fetched {
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/internal/cache"
+)
+
+func Test_model_Prefetch(t *testing.T) {
+	gt := memory.New()
+	gitManifest, _, lfsManifest := setupRemoteWithLFS(t, gt)
+
+	t.Run("Packfile Layers", func(t *testing.T) {
+		m := &model{gt: gt, man: gitManifest, cache: cache.New(t.TempDir(), 0)}
+
+		n, err := m.Prefetch(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		// already cached
+		n, err = m.Prefetch(t.Context(), gitManifest.Layers[0].Digest)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("LFS Files", func(t *testing.T) {
+		m := &model{gt: gt, lfsMan: lfsManifest, cache: cache.New(t.TempDir(), 0)}
+
+		n, err := m.PrefetchLFS(t.Context(), lfsManifest.Layers[0].Digest)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("Unknown Layer", func(t *testing.T) {
+		m := &model{gt: gt, man: gitManifest, cache: cache.New(t.TempDir(), 0)}
+
+		_, err := m.Prefetch(t.Context(), digest.FromString("unknown"))
+		assert.ErrorIs(t, err, errLayerNotInManifest)
+	})
+
+	t.Run("Cache Disabled", func(t *testing.T) {
+		m := &model{gt: gt, man: gitManifest}
+
+		_, err := m.Prefetch(t.Context())
+		assert.ErrorIs(t, err, ErrCacheDisabled)
+	})
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// canonicalJSON encodes v as JSON with the keys of every object sorted, such
+// that equal values are encoded identically regardless of struct field order,
+// e.g. across client versions adding fields to a config.
+func canonicalJSON(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding JSON: %w", err)
+	}
+
+	// maps are encoded with sorted keys, numbers are kept verbatim
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	raw, err = json.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("encoding canonical JSON: %w", err)
+	}
+
+	return raw, nil
+}
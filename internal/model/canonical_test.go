@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_canonicalJSON(t *testing.T) {
+	t.Run("Sorted Keys", func(t *testing.T) {
+		cfg := oci.ConfigGit{
+			Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				"refs/heads/main": {Commit: "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74", Layer: "sha256:abc"},
+			},
+			Tags:          map[plumbing.ReferenceName]oci.ReferenceInfo{},
+			DefaultBranch: "refs/heads/main",
+		}
+
+		raw, err := canonicalJSON(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"defaultBranch":"refs/heads/main","heads":{"refs/heads/main":{"commit":"eaba08b8fae96b96fe68d88dd311ffb8ca22ba74","layer":"sha256:abc"}},"tags":{}}`, string(raw))
+	})
+
+	t.Run("Field Order", func(t *testing.T) {
+		type a struct {
+			X string `json:"x"`
+			Y int64  `json:"y"`
+		}
+		type b struct {
+			Y int64  `json:"y"`
+			X string `json:"x"`
+		}
+
+		rawA, err := canonicalJSON(a{X: "x", Y: 1 << 62})
+		assert.NoError(t, err)
+		rawB, err := canonicalJSON(b{X: "x", Y: 1 << 62})
+		assert.NoError(t, err)
+		assert.Equal(t, rawA, rawB)
+		assert.Equal(t, `{"x":"x","y":4611686018427387904}`, string(rawA))
+	})
+}
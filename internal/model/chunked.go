@@ -0,0 +1,268 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// defaultChunkThreshold is the size above which blobs are pushed with
+	// chunked uploads.
+	defaultChunkThreshold int64 = 1 << 30 // 1 GiB
+	// defaultChunkSize is the size of each chunk in a chunked upload.
+	defaultChunkSize int64 = 64 << 20 // 64 MiB
+	// chunkRetries is the number of times an individual chunk upload is
+	// retried before failing the upload.
+	chunkRetries = 3
+)
+
+// errUnexpectedStatus indicates a registry responded with an unexpected HTTP
+// status code.
+var errUnexpectedStatus = errors.New("unexpected response status")
+
+// pushBlob pushes blob content to the remote, selecting a monolithic or
+// chunked upload strategy based on the size of the blob. Chunked uploads
+// are only supported by remote registries.
+func (m *model) pushBlob(ctx context.Context, desc ocispec.Descriptor, r io.Reader, opts *PushLFSOptions) error {
+	threshold := defaultChunkThreshold
+	chunkSize := defaultChunkSize
+	if opts != nil {
+		if opts.ChunkThreshold > 0 {
+			threshold = opts.ChunkThreshold
+		}
+		if opts.ChunkSize > 0 {
+			chunkSize = opts.ChunkSize
+		}
+	}
+
+	gt := m.lfsStore()
+	repo, ok := remoteRepository(gt)
+	if !ok || desc.Size <= threshold {
+		return gt.Push(ctx, desc, r) //nolint:wrapcheck
+	}
+
+	slog.DebugContext(ctx, "pushing blob with chunked upload", slog.String("digest", desc.Digest.String()),
+		slog.Int64("size", desc.Size), slog.Int64("chunkSize", chunkSize))
+	return pushChunked(ctx, repo, desc, r, chunkSize)
+}
+
+// remoteRepository returns the remote repository written to by gt, if any.
+func remoteRepository(gt Store) (*remote.Repository, bool) {
+	if mirrored, ok := gt.(interface{ Primary() oras.GraphTarget }); ok {
+		gt = mirrored.Primary()
+	}
+	repo, ok := gt.(*remote.Repository)
+	return repo, ok
+}
+
+// pushChunked pushes blob content using the chunked upload API of the OCI
+// distribution spec. Individual chunks are retried, resuming at the offset
+// acknowledged by the registry.
+//
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-a-blob-in-chunks
+func pushChunked(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor, r io.Reader, chunkSize int64) (err error) {
+	// pushing usually requires both pull and push actions, matching oras
+	ctx = auth.AppendRepositoryScope(ctx, repo.Reference, auth.ActionPull, auth.ActionPush)
+
+	u, err := startUpload(ctx, repo)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			u.abort(ctx)
+		}
+	}()
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if err := u.writeChunk(ctx, buf[:n]); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading blob content: %w", err)
+		}
+	}
+
+	if u.offset != desc.Size {
+		return fmt.Errorf("mismatched blob size, expected %d, uploaded %d", desc.Size, u.offset)
+	}
+
+	return u.complete(ctx, desc.Digest)
+}
+
+// upload is an in-progress chunked blob upload session.
+type upload struct {
+	client   remote.Client
+	location string
+	offset   int64 // bytes acknowledged by the registry
+}
+
+// startUpload opens a chunked upload session.
+func startUpload(ctx context.Context, repo *remote.Repository) (*upload, error) {
+	client := repo.Client
+	if client == nil {
+		client = auth.DefaultClient
+	}
+
+	scheme := "https"
+	if repo.PlainHTTP {
+		scheme = "http"
+	}
+	url := fmt.Sprintf("%s://%s/v2/%s/blobs/uploads/", scheme, repo.Reference.Host(), repo.Reference.Repository)
+
+	u := &upload{client: client}
+	if err := u.do(ctx, http.MethodPost, url, nil, http.StatusAccepted, nil); err != nil {
+		return nil, fmt.Errorf("starting chunked upload: %w", err)
+	}
+
+	return u, nil
+}
+
+// writeChunk uploads a chunk starting at the current offset. On failure, the
+// upload status is queried and the unacknowledged remainder of the chunk is
+// retried.
+func (u *upload) writeChunk(ctx context.Context, chunk []byte) error {
+	start := u.offset
+	end := start + int64(len(chunk))
+	for attempt := 1; ; attempt++ {
+		remaining := chunk[u.offset-start:]
+		header := http.Header{
+			"Content-Type":  {"application/octet-stream"},
+			"Content-Range": {fmt.Sprintf("%d-%d", u.offset, end-1)},
+		}
+		err := u.do(ctx, http.MethodPatch, u.location, remaining, http.StatusAccepted, header)
+		if err == nil {
+			if u.offset != end {
+				return fmt.Errorf("registry acknowledged %d bytes of chunk ending at offset %d", u.offset-start, end)
+			}
+			return nil
+		}
+		if attempt > chunkRetries || ctx.Err() != nil {
+			return fmt.Errorf("uploading chunk at offset %d after %d attempts: %w", start, attempt, err)
+		}
+		slog.WarnContext(ctx, "retrying chunk upload", slog.Int64("offset", start), slog.Int("attempt", attempt), slog.String("error", err.Error()))
+
+		// the registry may have received part of the chunk
+		if err := u.do(ctx, http.MethodGet, u.location, nil, http.StatusNoContent, nil); err != nil {
+			return fmt.Errorf("resolving chunked upload status: %w", err)
+		}
+		switch {
+		case u.offset < start || u.offset > end:
+			return fmt.Errorf("registry reported upload offset %d outside of chunk %d-%d", u.offset, start, end-1)
+		case u.offset == end:
+			return nil
+		}
+	}
+}
+
+// complete closes the upload session, validating the uploaded content.
+func (u *upload) complete(ctx context.Context, dgst digest.Digest) error {
+	url := u.location
+	if strings.Contains(url, "?") {
+		url += "&digest=" + dgst.String()
+	} else {
+		url += "?digest=" + dgst.String()
+	}
+
+	if err := u.do(ctx, http.MethodPut, url, nil, http.StatusCreated, nil); err != nil {
+		return fmt.Errorf("completing chunked upload: %w", err)
+	}
+
+	return nil
+}
+
+// abort cancels the upload session, rather than leaving the registry to
+// expire it. Failures are logged, as the upload has already failed. The
+// session is canceled even if ctx is, e.g. when interrupted.
+func (u *upload) abort(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	if err := u.do(ctx, http.MethodDelete, u.location, nil, http.StatusNoContent, nil); err != nil {
+		slog.WarnContext(ctx, "canceling chunked upload", slog.String("error", err.Error()))
+	}
+}
+
+// do sends a request within the upload session, updating the session location
+// and acknowledged offset from the response.
+func (u *upload) do(ctx context.Context, method, url string, body []byte, expected int, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		return fmt.Errorf("%w: %s %s: %s", errUnexpectedStatus, method, req.URL.Redacted(), resp.Status)
+	}
+
+	// the location may change with each request
+	location, err := resp.Location()
+	switch {
+	case errors.Is(err, http.ErrNoLocation):
+	case err != nil:
+		return fmt.Errorf("parsing upload location: %w", err)
+	default:
+		u.location = location.String()
+	}
+
+	// distribution reports the Range "0-0" when opening a session, and for
+	// the status of an empty upload, as well as after receiving one byte
+	rng := resp.Header.Get("Range")
+	switch {
+	case method == http.MethodPost:
+	case rng == "0-0" && method == http.MethodGet && u.offset == 0:
+	case rng != "":
+		offset, err := parseRangeEnd(rng)
+		if err != nil {
+			return err
+		}
+		u.offset = offset
+	case method == http.MethodPatch:
+		u.offset += int64(len(body))
+	}
+
+	return nil
+}
+
+// parseRangeEnd parses the Range header of an upload status response, e.g.
+// "0-1023", returning the number of bytes received.
+func parseRangeEnd(rng string) (int64, error) {
+	_, end, ok := strings.Cut(strings.TrimPrefix(rng, "bytes="), "-")
+	if !ok {
+		return 0, fmt.Errorf("invalid upload range %q", rng)
+	}
+
+	n, err := strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing upload range %q: %w", rng, err)
+	}
+
+	return n + 1, nil
+}
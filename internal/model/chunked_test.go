@@ -0,0 +1,232 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// chunkedRegistry is a minimal registry implementing the chunked blob upload
+// API as distribution does, optionally failing PATCH requests after receiving
+// part of a chunk.
+type chunkedRegistry struct {
+	mu       sync.Mutex
+	received []byte
+	patches  int
+	// failPatch returns true if the nth PATCH request should fail
+	failPatch func(n int) bool
+	// failEmpty fails PATCH requests before receiving any of the chunk
+	failEmpty bool
+	blob      []byte
+	aborted   bool
+}
+
+// uploadRange returns the Range header distribution reports for an upload,
+// which is "0-0" for both empty and single byte uploads.
+func (reg *chunkedRegistry) uploadRange() string {
+	return fmt.Sprintf("0-%d", max(0, len(reg.received)-1))
+}
+
+func (reg *chunkedRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	const location = "/v2/repo/blobs/uploads/session"
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/repo/blobs/uploads/":
+		w.Header().Set("Location", location)
+		w.Header().Set("Range", reg.uploadRange())
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPatch && r.URL.Path == location:
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Range") != fmt.Sprintf("%d-%d", len(reg.received), len(reg.received)+len(body)-1) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+
+		reg.patches++
+		if reg.failPatch != nil && reg.failPatch(reg.patches) {
+			if !reg.failEmpty {
+				// partially received
+				reg.received = append(reg.received, body[:len(body)/2]...)
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		reg.received = append(reg.received, body...)
+		w.Header().Set("Location", location)
+		w.Header().Set("Range", reg.uploadRange())
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodGet && r.URL.Path == location:
+		w.Header().Set("Location", location)
+		w.Header().Set("Range", reg.uploadRange())
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.URL.Path == location:
+		if r.URL.Query().Get("digest") != digest.FromBytes(reg.received).String() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reg.blob = reg.received
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete && r.URL.Path == location:
+		reg.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func Test_pushChunked(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	desc := ocispec.Descriptor{
+		MediaType: "application/octet-stream",
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+
+	newRepo := func(t *testing.T, reg *chunkedRegistry) *remote.Repository {
+		t.Helper()
+		srv := httptest.NewServer(reg)
+		t.Cleanup(srv.Close)
+
+		return &remote.Repository{
+			Client: srv.Client(),
+			Reference: registry.Reference{
+				Registry:   strings.TrimPrefix(srv.URL, "http://"),
+				Repository: "repo",
+			},
+			PlainHTTP: true,
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		reg := &chunkedRegistry{}
+		repo := newRepo(t, reg)
+
+		err := pushChunked(t.Context(), repo, desc, bytes.NewReader(data), 8)
+		assert.NoError(t, err)
+		assert.Equal(t, data, reg.blob)
+		assert.Equal(t, 6, reg.patches)
+	})
+
+	t.Run("Success - Retry Partial Chunk", func(t *testing.T) {
+		reg := &chunkedRegistry{
+			failPatch: func(n int) bool { return n == 2 },
+		}
+		repo := newRepo(t, reg)
+
+		err := pushChunked(t.Context(), repo, desc, bytes.NewReader(data), 8)
+		assert.NoError(t, err)
+		assert.Equal(t, data, reg.blob)
+	})
+
+	t.Run("Success - Retry Empty Upload", func(t *testing.T) {
+		reg := &chunkedRegistry{
+			failPatch: func(n int) bool { return n == 1 },
+			failEmpty: true,
+		}
+		repo := newRepo(t, reg)
+
+		err := pushChunked(t.Context(), repo, desc, bytes.NewReader(data), 8)
+		assert.NoError(t, err)
+		assert.Equal(t, data, reg.blob)
+	})
+
+	t.Run("Success - Single Byte Chunks", func(t *testing.T) {
+		reg := &chunkedRegistry{}
+		repo := newRepo(t, reg)
+
+		err := pushChunked(t.Context(), repo, desc, bytes.NewReader(data), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, data, reg.blob)
+		assert.Equal(t, len(data), reg.patches)
+	})
+
+	t.Run("Retries Exhausted", func(t *testing.T) {
+		reg := &chunkedRegistry{
+			failPatch: func(n int) bool { return n > 1 },
+		}
+		repo := newRepo(t, reg)
+
+		err := pushChunked(t.Context(), repo, desc, bytes.NewReader(data), 8)
+		assert.ErrorIs(t, err, errUnexpectedStatus)
+		assert.Nil(t, reg.blob)
+		assert.True(t, reg.aborted)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		reg := &chunkedRegistry{
+			failPatch: func(n int) bool {
+				if n == 2 {
+					cancel()
+				}
+				return n == 2
+			},
+		}
+		repo := newRepo(t, reg)
+
+		err := pushChunked(ctx, repo, desc, bytes.NewReader(data), 8)
+		assert.Error(t, err)
+		assert.Equal(t, 2, reg.patches, "canceled uploads are not retried")
+		assert.Nil(t, reg.blob)
+		assert.True(t, reg.aborted)
+	})
+}
+
+func Test_model_pushBlob(t *testing.T) {
+	t.Run("Monolithic", func(t *testing.T) {
+		data := []byte("foo")
+		desc := content.NewDescriptorFromBytes("application/octet-stream", data)
+
+		m := &model{gt: memory.New()}
+
+		// chunked uploads are only supported by remote registries
+		err := m.pushBlob(t.Context(), desc, bytes.NewReader(data), &PushLFSOptions{ChunkThreshold: 1, ChunkSize: 1})
+		assert.NoError(t, err)
+
+		exists, err := m.gt.Exists(t.Context(), desc)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
+func Test_parseRangeEnd(t *testing.T) {
+	tests := []struct {
+		name    string
+		rng     string
+		want    int64
+		wantErr bool
+	}{
+		{name: "Success", rng: "0-1023", want: 1024},
+		{name: "Success - Bytes Prefix", rng: "bytes=0-1023", want: 1024},
+		{name: "Invalid Format", rng: "1023", wantErr: true},
+		{name: "Invalid End", rng: "0-foo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRangeEnd(tt.rng)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/klauspost/compress/zstd"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// commitGraph maps the commits of packfile layers to the layer containing them
+// and their parents, resolving commits in the remote without fetching
+// packfiles.
+type commitGraph struct {
+	// layers whose commits are indexed
+	layers  map[digest.Digest]struct{}
+	commits map[plumbing.Hash]graphCommit
+}
+
+// graphCommit is a commit indexed by a [commitGraph].
+type graphCommit struct {
+	layer   digest.Digest
+	parents []plumbing.Hash
+}
+
+func newCommitGraph() *commitGraph {
+	return &commitGraph{
+		layers:  make(map[digest.Digest]struct{}),
+		commits: make(map[plumbing.Hash]graphCommit),
+	}
+}
+
+// add indexes the commits of a packfile layer.
+func (g *commitGraph) add(layer digest.Digest, commits []*object.Commit) {
+	g.layers[layer] = struct{}{}
+	for _, c := range commits {
+		g.commits[c.Hash] = graphCommit{layer: layer, parents: c.ParentHashes}
+	}
+}
+
+// merge indexes the commits of other not already indexed, e.g. those of
+// layers added by a push being rebased.
+func (g *commitGraph) merge(other *commitGraph) {
+	for layer := range other.layers {
+		g.layers[layer] = struct{}{}
+	}
+	for h, c := range other.commits {
+		if _, ok := g.commits[h]; !ok {
+			g.commits[h] = c
+		}
+	}
+}
+
+// complete returns true if the commits of all layers are indexed.
+func (g *commitGraph) complete(layers []ocispec.Descriptor) bool {
+	for _, desc := range layers {
+		if _, ok := g.layers[desc.Digest]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// layerOf returns the layer containing commit, if indexed and still one of
+// layers.
+func (g *commitGraph) layerOf(commit plumbing.Hash, layers []ocispec.Descriptor) (digest.Digest, bool) {
+	c, ok := g.commits[commit]
+	if !ok || !slices.ContainsFunc(layers, func(desc ocispec.Descriptor) bool { return desc.Digest == c.layer }) {
+		return "", false
+	}
+	return c.layer, true
+}
+
+// isAncestor returns true if ancestor is reachable from commit by walking the
+// indexed parents.
+func (g *commitGraph) isAncestor(ancestor, commit plumbing.Hash) bool {
+	seen := make(map[plumbing.Hash]struct{})
+	next := []plumbing.Hash{commit}
+	for len(next) > 0 {
+		h := next[len(next)-1]
+		next = next[:len(next)-1]
+		if h == ancestor {
+			return true
+		}
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		next = append(next, g.commits[h].parents...)
+	}
+	return false
+}
+
+// layersAfter returns the layers newer than layer, among layers, containing
+// commits reachable from commit by walking the indexed parents, in the order
+// of layers.
+func (g *commitGraph) layersAfter(commit plumbing.Hash, layer digest.Digest, layers []ocispec.Descriptor) []digest.Digest {
+	idx := make(map[digest.Digest]int, len(layers))
+	for i, desc := range layers {
+		idx[desc.Digest] = i
+	}
+	bound, ok := idx[layer]
+	if !ok {
+		return nil
+	}
+
+	found := make(map[int]struct{})
+	seen := make(map[plumbing.Hash]struct{})
+	next := []plumbing.Hash{commit}
+	for len(next) > 0 {
+		h := next[len(next)-1]
+		next = next[:len(next)-1]
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		c, ok := g.commits[h]
+		if !ok {
+			continue
+		}
+		if i, ok := idx[c.layer]; ok && i > bound {
+			found[i] = struct{}{}
+		}
+		next = append(next, c.parents...)
+	}
+
+	var newer []digest.Digest
+	for _, i := range slices.Sorted(maps.Keys(found)) {
+		newer = append(newer, layers[i].Digest)
+	}
+	return newer
+}
+
+// encode returns the zstd compressed commit-graph of the commits in layers,
+// dropping those of layers since pruned. Commits are sorted by hash, such
+// that identical graphs are encoded identically.
+func (g *commitGraph) encode(layers []ocispec.Descriptor) ([]byte, error) {
+	var cg oci.CommitGraph
+	layerIdx := make(map[digest.Digest]int, len(layers))
+	for _, desc := range layers {
+		if _, ok := g.layers[desc.Digest]; ok {
+			layerIdx[desc.Digest] = len(cg.Layers)
+			cg.Layers = append(cg.Layers, desc.Digest)
+		}
+	}
+
+	hashes := slices.SortedFunc(maps.Keys(g.commits), func(a, b plumbing.Hash) int { return bytes.Compare(a[:], b[:]) })
+	hashes = slices.DeleteFunc(hashes, func(h plumbing.Hash) bool {
+		_, ok := layerIdx[g.commits[h].layer]
+		return !ok
+	})
+	commitIdx := make(map[plumbing.Hash]int, len(hashes))
+	for i, h := range hashes {
+		commitIdx[h] = i
+	}
+
+	cg.Commits = make([]oci.CommitGraphEntry, 0, len(hashes))
+	for _, h := range hashes {
+		c := g.commits[h]
+		entry := oci.CommitGraphEntry{Commit: h.String(), Layer: layerIdx[c.layer]}
+		for _, p := range c.parents {
+			if i, ok := commitIdx[p]; ok {
+				entry.Parents = append(entry.Parents, i)
+			}
+		}
+		cg.Commits = append(cg.Commits, entry)
+	}
+
+	raw, err := json.Marshal(cg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding commit-graph: %w", err)
+	}
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("initializing zstd encoder: %w", err)
+	}
+	defer enc.Close()
+
+	return enc.EncodeAll(raw, nil), nil
+}
+
+// decodeCommitGraph decodes a zstd compressed commit-graph.
+func decodeCommitGraph(data []byte) (*commitGraph, error) {
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("initializing zstd decoder: %w", err)
+	}
+	defer dec.Close()
+
+	raw, err := dec.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing commit-graph: %w", err)
+	}
+	var cg oci.CommitGraph
+	if err := json.Unmarshal(raw, &cg); err != nil {
+		return nil, fmt.Errorf("decoding commit-graph: %w", err)
+	}
+
+	g := newCommitGraph()
+	for _, layer := range cg.Layers {
+		g.layers[layer] = struct{}{}
+	}
+	for _, entry := range cg.Commits {
+		if entry.Layer < 0 || entry.Layer >= len(cg.Layers) {
+			return nil, fmt.Errorf("commit %s of commit-graph refers to layer %d of %d", entry.Commit, entry.Layer, len(cg.Layers))
+		}
+		c := graphCommit{layer: cg.Layers[entry.Layer]}
+		for _, i := range entry.Parents {
+			if i < 0 || i >= len(cg.Commits) {
+				return nil, fmt.Errorf("commit %s of commit-graph refers to parent %d of %d", entry.Commit, i, len(cg.Commits))
+			}
+			c.parents = append(c.parents, plumbing.NewHash(cg.Commits[i].Commit))
+		}
+		g.commits[plumbing.NewHash(entry.Commit)] = c
+	}
+
+	return g, nil
+}
+
+// CommitsOf returns the commits among objs, in no particular order.
+func CommitsOf(st storer.EncodedObjectStorer, objs []plumbing.Hash) ([]*object.Commit, error) {
+	var commits []*object.Commit
+	for _, h := range objs {
+		obj, err := st.EncodedObject(plumbing.AnyObject, h)
+		if err != nil {
+			return nil, fmt.Errorf("resolving object %s: %w", h, err)
+		}
+		if obj.Type() != plumbing.CommitObject {
+			continue
+		}
+		c, err := object.DecodeCommit(st, obj)
+		if err != nil {
+			return nil, fmt.Errorf("decoding commit %s: %w", h, err)
+		}
+		commits = append(commits, c)
+	}
+
+	return commits, nil
+}
+
+// splitCommitGraph separates the commit-graph layer of a Git manifest from its
+// packfile layers.
+func splitCommitGraph(layers []ocispec.Descriptor) ([]ocispec.Descriptor, ocispec.Descriptor) {
+	i := slices.IndexFunc(layers, func(desc ocispec.Descriptor) bool { return desc.MediaType == oci.MediaTypeCommitGraph })
+	if i < 0 {
+		return layers, ocispec.Descriptor{}
+	}
+	graphDesc := layers[i]
+	return slices.Delete(layers, i, i+1), graphDesc
+}
+
+// commitGraph returns the commit-graph of the fetched Git manifest, fetching it
+// on first use. Remotes pushed without a commit-graph have an empty one.
+func (m *model) commitGraph(ctx context.Context) (*commitGraph, error) {
+	if m.graph != nil {
+		return m.graph, nil
+	}
+	if m.graphDesc.Digest == "" {
+		m.graph = newCommitGraph()
+		return m.graph, nil
+	}
+
+	slog.DebugContext(ctx, "fetching commit-graph", slog.String("digest", m.graphDesc.Digest.String()))
+	data, err := content.FetchAll(ctx, m.gt, m.graphDesc)
+	if err != nil {
+		return nil, fmt.Errorf("fetching commit-graph: %w", err)
+	}
+	m.graph, err = decodeCommitGraph(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.graph, nil
+}
+
+// historyLayers returns the layers newer than layer holding the history of
+// commit, per the commit-graph, e.g. if commit was pushed before its history
+// by a shallow push. Layers not indexed by the commit-graph are unknown.
+func (m *model) historyLayers(ctx context.Context, commit plumbing.Hash, layer digest.Digest) []digest.Digest {
+	if layer == m.man.Layers[len(m.man.Layers)-1].Digest {
+		// no newer layers
+		return nil
+	}
+	g, err := m.commitGraph(ctx)
+	if err != nil {
+		// best effort, fetches of all layers are unaffected
+		slog.WarnContext(ctx, "resolving layers holding the history of commit", slog.String("commit", commit.String()), slog.String("error", err.Error()))
+		return nil
+	}
+	return g.layersAfter(commit, layer, m.man.Layers)
+}
+
+// pushCommitGraph pushes the commit-graph of the current packfile layers,
+// returning false if no commits are indexed.
+func (m *model) pushCommitGraph(ctx context.Context) (ocispec.Descriptor, bool, error) {
+	g, err := m.commitGraph(ctx)
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	if len(g.commits) == 0 {
+		return ocispec.Descriptor{}, false, nil
+	}
+
+	data, err := g.encode(m.man.Layers)
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	desc, err := oras.PushBytes(ctx, existingPusher{m.gt}, oci.MediaTypeCommitGraph, data)
+	if err != nil {
+		return ocispec.Descriptor{}, false, fmt.Errorf("pushing commit-graph: %w", err)
+	}
+
+	return desc, true, nil
+}
@@ -0,0 +1,221 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_commitGraph(t *testing.T) {
+	layer0 := ocispec.Descriptor{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString("layer0")}
+	layer1 := ocispec.Descriptor{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString("layer1")}
+	layer2 := ocispec.Descriptor{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString("layer2")}
+
+	root := plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc")
+	child := plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74")
+	merge := plumbing.NewHash("1d3b2a3c4f6e1a2b3c4d5e6f7a8b9c0d1e2f3a4b")
+	side := plumbing.NewHash("5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c")
+
+	g := newCommitGraph()
+	g.add(layer0.Digest, []*object.Commit{{Hash: root}})
+	g.add(layer1.Digest, []*object.Commit{
+		{Hash: child, ParentHashes: []plumbing.Hash{root}},
+		{Hash: merge, ParentHashes: []plumbing.Hash{child, side}},
+	})
+
+	t.Run("Ancestry", func(t *testing.T) {
+		assert.True(t, g.isAncestor(root, merge))
+		assert.True(t, g.isAncestor(merge, merge))
+		assert.False(t, g.isAncestor(merge, root))
+		assert.False(t, g.isAncestor(side, child))
+	})
+
+	t.Run("Completeness", func(t *testing.T) {
+		assert.True(t, g.complete([]ocispec.Descriptor{layer0, layer1}))
+		assert.False(t, g.complete([]ocispec.Descriptor{layer0, layer1, layer2}))
+	})
+
+	t.Run("Round Trip", func(t *testing.T) {
+		data, err := g.encode([]ocispec.Descriptor{layer0, layer1})
+		assert.NoError(t, err)
+		got, err := decodeCommitGraph(data)
+		assert.NoError(t, err)
+
+		// parents outside the graph are dropped
+		want := newCommitGraph()
+		want.add(layer0.Digest, []*object.Commit{{Hash: root}})
+		want.add(layer1.Digest, []*object.Commit{
+			{Hash: child, ParentHashes: []plumbing.Hash{root}},
+			{Hash: merge, ParentHashes: []plumbing.Hash{child}},
+		})
+		assert.Equal(t, want, got)
+
+		again, err := got.encode([]ocispec.Descriptor{layer0, layer1})
+		assert.NoError(t, err)
+		assert.Equal(t, data, again)
+	})
+
+	t.Run("Pruned Layer", func(t *testing.T) {
+		data, err := g.encode([]ocispec.Descriptor{layer1})
+		assert.NoError(t, err)
+		got, err := decodeCommitGraph(data)
+		assert.NoError(t, err)
+
+		_, ok := got.layerOf(root, []ocispec.Descriptor{layer0, layer1})
+		assert.False(t, ok)
+		layer, ok := got.layerOf(child, []ocispec.Descriptor{layer1})
+		assert.True(t, ok)
+		assert.Equal(t, layer1.Digest, layer)
+		assert.False(t, got.complete([]ocispec.Descriptor{layer0, layer1}))
+	})
+
+	t.Run("Newer History", func(t *testing.T) {
+		// merge pushed before the history of its second parent
+		g := newCommitGraph()
+		g.add(layer0.Digest, []*object.Commit{{Hash: root}})
+		g.add(layer1.Digest, []*object.Commit{{Hash: merge, ParentHashes: []plumbing.Hash{root, side}}})
+		g.add(layer2.Digest, []*object.Commit{{Hash: side, ParentHashes: []plumbing.Hash{root}}})
+		layers := []ocispec.Descriptor{layer0, layer1, layer2}
+
+		assert.Equal(t, []digest.Digest{layer2.Digest}, g.layersAfter(merge, layer1.Digest, layers))
+		assert.Empty(t, g.layersAfter(side, layer2.Digest, layers))
+		assert.Empty(t, g.layersAfter(root, layer0.Digest, layers))
+		// pruned layers are dropped
+		assert.Empty(t, g.layersAfter(merge, layer1.Digest, layers[:2]))
+	})
+
+	t.Run("No Layers", func(t *testing.T) {
+		g := newCommitGraph()
+		g.add(layer0.Digest, []*object.Commit{{Hash: root}})
+		data, err := g.encode(nil)
+		assert.NoError(t, err)
+		got, err := decodeCommitGraph(data)
+		assert.NoError(t, err)
+		assert.Empty(t, got.commits)
+	})
+}
+
+func Test_model_commitGraph(t *testing.T) {
+	gt := memory.New()
+	setupRemote(t, gt)
+
+	newModel := func(t *testing.T) *model {
+		t.Helper()
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, fstore.Close())
+		})
+
+		m := NewModeler(testRemote, fstore, gt).(*model)
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+		return m
+	}
+
+	parent := plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc")
+	child := plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74")
+
+	m := newModel(t)
+	assert.Empty(t, m.graphDesc.Digest)
+
+	packPath := filepath.Join(t.TempDir(), "pack-graph.pack")
+	err := os.WriteFile(packPath, []byte("commit-graph test packfile"), 0o600)
+	assert.NoError(t, err)
+	packDesc, err := m.AddPack(t.Context(), packPath, "", []*object.Commit{
+		{Hash: parent},
+		{Hash: child, ParentHashes: []plumbing.Hash{parent}},
+	}, plumbing.NewHashReference(plumbing.Main, child))
+	assert.NoError(t, err)
+
+	_, err = m.Push(t.Context())
+	assert.NoError(t, err)
+
+	m = newModel(t)
+	assert.NotEmpty(t, m.graphDesc.Digest)
+	assert.NotContains(t, m.man.Layers, m.graphDesc)
+	assert.Contains(t, m.man.Layers, packDesc)
+
+	ok, err := m.IsAncestor(t.Context(), parent, child)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// layers pushed before the commit-graph are not indexed
+	_, err = m.IsAncestor(t.Context(), child, parent)
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+	_, err = m.IsAncestor(t.Context(), parent, plumbing.NewHash("1d3b2a3c4f6e1a2b3c4d5e6f7a8b9c0d1e2f3a4b"))
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+
+	layer, err := m.CommitExists(t.Context(), nil, &object.Commit{Hash: child})
+	assert.NoError(t, err)
+	assert.Equal(t, packDesc.Digest, layer)
+
+	layer, err = m.ResolveCommit(t.Context(), parent)
+	assert.NoError(t, err)
+	assert.Equal(t, packDesc.Digest, layer)
+}
+
+func Test_model_ResolveRefLayers(t *testing.T) {
+	gt := memory.New()
+	setupRemote(t, gt)
+
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	defer fstore.Close()
+
+	m := NewModeler(testRemote, fstore, gt).(*model)
+	_, err = m.Fetch(t.Context())
+	assert.NoError(t, err)
+
+	parent := plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc")
+	child := plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74")
+
+	addPack := func(name string, commits []*object.Commit, refs ...*plumbing.Reference) ocispec.Descriptor {
+		t.Helper()
+		packPath := filepath.Join(t.TempDir(), name+".pack")
+		assert.NoError(t, os.WriteFile(packPath, []byte(name), 0o600))
+		desc, err := m.AddPack(t.Context(), packPath, "", commits, refs...)
+		assert.NoError(t, err)
+		return desc
+	}
+
+	// a shallow push, followed by the history of its tip
+	tipLayer := addPack("tip", []*object.Commit{{Hash: child, ParentHashes: []plumbing.Hash{parent}}}, plumbing.NewHashReference(plumbing.Main, child))
+	historyLayer := addPack("history", []*object.Commit{{Hash: parent}})
+
+	layers, err := m.ResolveRefLayers(t.Context(), plumbing.Main)
+	assert.NoError(t, err)
+	assert.Equal(t, []digest.Digest{tipLayer.Digest}, layers, "history pushed after the reference is unknown")
+
+	// a branch pushed at the existing tip
+	feature := plumbing.NewBranchReferenceName("feature")
+	layer, err := m.CommitExists(t.Context(), nil, &object.Commit{Hash: child})
+	assert.NoError(t, err)
+	assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference(feature, child), layer))
+
+	layers, err = m.ResolveRefLayers(t.Context(), feature)
+	assert.NoError(t, err)
+	assert.Equal(t, []digest.Digest{tipLayer.Digest, historyLayer.Digest}, layers)
+
+	ref, layer, err := m.ResolveRef(t.Context(), feature)
+	assert.NoError(t, err)
+	assert.Equal(t, child, ref.Hash())
+	assert.Equal(t, tipLayer.Digest, layer)
+
+	// history layers are retained while referenced
+	assert.Empty(t, m.pruneLayers(t.Context()))
+
+	_, err = m.ResolveRefLayers(t.Context(), plumbing.NewBranchReferenceName("missing"))
+	assert.ErrorIs(t, err, ErrReferenceNotFound)
+}
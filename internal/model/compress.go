@@ -0,0 +1,144 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// Option configures a Modeler.
+type Option func(*model)
+
+// WithZstdPacks compresses packfile layers with zstd as they are added.
+// Existing layers are unaffected, compressed layers are always decompressed
+// when fetched.
+func WithZstdPacks() Option {
+	return func(m *model) {
+		m.zstdPacks = true
+	}
+}
+
+// addPackLayer adds a packfile to the intermediate file store, compressing
+// and encrypting it if enabled.
+func (m *model) addPackLayer(ctx context.Context, path string) (ocispec.Descriptor, error) {
+	mediaType := oci.MediaTypePackLayer
+	if m.zstdPacks {
+		var err error
+		path, err = compressPack(path)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		mediaType = oci.MediaTypePackLayerZstd
+	}
+	var annotations map[string]string
+	if len(m.encryptionKeys) > 0 {
+		var err error
+		encPath := path + ".enc"
+		mediaType, annotations, err = m.encryptLayer(path, encPath, mediaType)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("encrypting packfile: %w", err)
+		}
+		path = encPath
+	}
+
+	// filepath.Base adds an annotation for the filename, without exposing a user's filesystem
+	desc, err := m.addBlob(ctx, filepath.Base(path), mediaType, path)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("adding packfile to intermediate file store: %w", err)
+	}
+	if annotations != nil {
+		desc = withAnnotations(desc, annotations)
+	}
+
+	return desc, nil
+}
+
+// compressPack writes a zstd compressed copy of a packfile alongside it,
+// returning its path.
+func compressPack(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening packfile: %w", err)
+	}
+	defer src.Close()
+
+	dstPath := path + ".zst"
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return "", fmt.Errorf("creating compressed packfile: %w", err)
+	}
+	defer dst.Close()
+
+	zw, err := zstd.NewWriter(dst)
+	if err != nil {
+		return "", fmt.Errorf("initializing zstd encoder: %w", err)
+	}
+	if _, err := io.Copy(zw, src); err != nil {
+		return "", errors.Join(fmt.Errorf("compressing packfile: %w", err), zw.Close())
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("compressing packfile: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return "", fmt.Errorf("closing compressed packfile: %w", err)
+	}
+
+	return dstPath, nil
+}
+
+// fetchPackLayer fetches a packfile layer, decrypting and decompressing it if
+// necessary.
+func (m *model) fetchPackLayer(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := m.fetchBlob(ctx, m.gt, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.decodePackLayer(rc, desc)
+}
+
+// decodePackLayer returns the packfile of the layer read by rc, decrypting
+// and decompressing it if necessary. rc is closed on error, otherwise when the
+// returned stream is closed.
+func (m *model) decodePackLayer(rc io.ReadCloser, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	if isEncrypted(desc.MediaType) {
+		var err error
+		rc, err = m.decryptLayer(rc, desc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch desc.MediaType {
+	case oci.MediaTypePackLayerZstd, oci.MediaTypePackLayerZstdEncrypted:
+	default:
+		return rc, nil
+	}
+
+	zr, err := zstd.NewReader(rc)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("initializing zstd decoder: %w", err), rc.Close())
+	}
+
+	return &zstdReadCloser{Decoder: zr, rc: rc}, nil
+}
+
+// zstdReadCloser decompresses a zstd stream, closing the underlying stream
+// when closed.
+type zstdReadCloser struct {
+	*zstd.Decoder
+	rc io.ReadCloser
+}
+
+// Close releases the decoder and closes the underlying stream.
+func (z *zstdReadCloser) Close() error {
+	z.Decoder.Close()
+	return z.rc.Close() //nolint:wrapcheck
+}
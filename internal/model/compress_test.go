@@ -0,0 +1,122 @@
+package model
+
+import (
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_packLayerRoundTrip(t *testing.T) {
+	layerContents := "Gnocchi are a varied family of pasta-like dumplings in Italian cuisine."
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantMediaType string
+	}{
+		{
+			name:          "Uncompressed",
+			wantMediaType: oci.MediaTypePackLayer,
+		},
+		{
+			name:          "Zstd",
+			opts:          []Option{WithZstdPacks()},
+			wantMediaType: oci.MediaTypePackLayerZstd,
+		},
+		{
+			name:          "Encrypted",
+			opts:          []Option{WithEncryption(EncryptionKey{ID: "test", Key: make([]byte, encryptionKeySize)})},
+			wantMediaType: oci.MediaTypePackLayerEncrypted,
+		},
+		{
+			name:          "Zstd Encrypted",
+			opts:          []Option{WithZstdPacks(), WithEncryption(EncryptionKey{ID: "test", Key: make([]byte, encryptionKeySize)})},
+			wantMediaType: oci.MediaTypePackLayerZstdEncrypted,
+		},
+		{
+			name:          "SHA-512",
+			opts:          []Option{WithDigestAlgorithm(digest.SHA512)},
+			wantMediaType: oci.MediaTypePackLayer,
+		},
+		{
+			name:          "SHA-512 Zstd Encrypted",
+			opts:          []Option{WithDigestAlgorithm(digest.SHA512), WithZstdPacks(), WithEncryption(EncryptionKey{ID: "test", Key: make([]byte, encryptionKeySize)})},
+			wantMediaType: oci.MediaTypePackLayerZstdEncrypted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			layerPath := filepath.Join(tmpDir, "pack-test.pack")
+			err := os.WriteFile(layerPath, []byte(layerContents), 0o600)
+			assert.NoError(t, err)
+
+			fstore, err := file.New(tmpDir)
+			assert.NoError(t, err)
+			defer func() {
+				if err := fstore.Close(); err != nil {
+					t.Errorf("closing OCI filestore: %v", err)
+				}
+			}()
+
+			gt := memory.New()
+			m := NewModeler(testRemote, fstore, gt, tt.opts...).(*model)
+
+			desc, err := m.addPackLayer(t.Context(), layerPath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMediaType, desc.MediaType)
+			assert.NoError(t, desc.Digest.Validate())
+			if m.digestAlg != "" {
+				assert.Equal(t, m.digestAlg, desc.Digest.Algorithm())
+			}
+
+			// emulate a push to the remote
+			rc, err := m.addedBlobs().Fetch(t.Context(), desc)
+			assert.NoError(t, err)
+			err = gt.Push(t.Context(), desc, rc)
+			assert.NoError(t, err)
+			assert.NoError(t, rc.Close())
+
+			rc, err = m.fetchPackLayer(t.Context(), desc)
+			assert.NoError(t, err)
+			got, err := io.ReadAll(rc)
+			assert.NoError(t, err)
+			assert.NoError(t, rc.Close())
+			assert.Equal(t, layerContents, string(got))
+		})
+	}
+}
+
+func Test_compressPack(t *testing.T) {
+	t.Run("Deterministic", func(t *testing.T) {
+		// large enough to be compressed in several blocks concurrently
+		data := make([]byte, 8<<20)
+		rng := rand.New(rand.NewPCG(1, 2))
+		for i := range data {
+			data[i] = byte(rng.IntN(16))
+		}
+
+		var compressed [][]byte
+		for range 2 {
+			path := filepath.Join(t.TempDir(), "pack-test.pack")
+			assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+			zstPath, err := compressPack(path)
+			assert.NoError(t, err)
+			b, err := os.ReadFile(zstPath)
+			assert.NoError(t, err)
+			compressed = append(compressed, b)
+		}
+		assert.Equal(t, compressed[0], compressed[1])
+	})
+}
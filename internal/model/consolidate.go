@@ -0,0 +1,237 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func (m *model) Consolidate(ctx context.Context, referrerUpdates ...ReferrerUpdater) (ocispec.Descriptor, error) {
+	if err := m.checkTagged(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := m.Fetch(ctx); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("fetching remote metadata: %w", err)
+	}
+	if len(m.man.Layers) == 0 {
+		slog.InfoContext(ctx, "no packfile layers to consolidate")
+		return m.manDesc, nil
+	}
+	if len(m.cfg.Shallow) > 0 {
+		// reachability walks would stop at missing parents
+		return ocispec.Descriptor{}, errors.New("consolidating a remote with truncated history is not supported")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gnoci-consolidate-*")
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("initializing temp directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.ErrorContext(ctx, "removing temporary git repository", slog.String("error", err.Error()))
+		}
+	}()
+
+	repo, err := m.unpackLayers(ctx, tmpDir)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	packPath, commits, err := m.writeReachablePack(repo.Storer, tmpDir)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := m.addPackLayer(ctx, packPath)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if m.packIndexes && len(m.encryptionKeys) == 0 {
+		desc, err = m.addPackIndex(ctx, packPath, desc)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	// every reachable commit is in the consolidated layer, unreachable
+	// commits are dropped
+	m.graph = newCommitGraph()
+	m.graph.add(desc.Digest, commits)
+
+	// the consolidated packfile may be identical to an existing layer
+	exists, err := m.gt.Exists(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("checking for existing packfile layer: %w", err)
+	}
+
+	superseded := m.man.Layers
+	m.man.Layers = []ocispec.Descriptor{desc}
+	m.newPacks = nil
+	if !exists {
+		m.newPacks = []ocispec.Descriptor{desc}
+	}
+	for _, refs := range []map[plumbing.ReferenceName]oci.ReferenceInfo{m.cfg.Heads, m.cfg.Tags, m.cfg.Notes} {
+		for name, info := range refs {
+			if info.Layer == "" {
+				continue
+			}
+			info.Layer = desc.Digest
+			info.Layers = nil
+			refs[name] = info
+		}
+	}
+	m.sortRefsByLayer()
+
+	manDesc, err := m.Push(ctx, referrerUpdates...)
+	if err != nil {
+		return manDesc, err
+	}
+	m.manDesc = manDesc
+
+	// the index of a superseded layer identical to desc is that of desc, and
+	// layers of references pushed concurrently are kept by rebasing
+	superseded = slices.DeleteFunc(superseded, func(d ocispec.Descriptor) bool { return containsDigest(m.man.Layers, d.Digest) })
+	m.deleteLayers(ctx, desc, m.withPackIndexLayers(superseded))
+
+	return manDesc, nil
+}
+
+// unpackLayers initializes a bare repository in dir, writing the objects of
+// all packfile layers to it.
+func (m *model) unpackLayers(ctx context.Context, dir string) (*gogit.Repository, error) {
+	repo, err := git.PlainInit(filepath.Join(dir, "repo"), true)
+	if err != nil {
+		return nil, fmt.Errorf("initializing temp repository: %w", err)
+	}
+
+	// oldest to newest, ensuring delta bases exist before they're needed
+	for _, desc := range m.man.Layers {
+		if err := m.unpackLayer(ctx, repo.Storer, desc); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// unpackLayer fetches a packfile layer, writing its objects to object storage.
+func (m *model) unpackLayer(ctx context.Context, st storer.Storer, desc ocispec.Descriptor) error {
+	slog.DebugContext(ctx, "unpacking packfile layer", slog.String("digest", desc.Digest.String()))
+	rc, err := m.fetchPackLayer(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("fetching packfile layer %s: %w", desc.Digest, err)
+	}
+	defer rc.Close()
+
+	if err := UnpackPack(st, rc, PackBase(desc) != ""); err != nil {
+		return fmt.Errorf("unpacking packfile layer %s: %w", desc.Digest, err)
+	}
+
+	return rc.Close()
+}
+
+// writeReachablePack writes a packfile to dir containing all objects reachable
+// from the current references, returning its path and the commits within.
+func (m *model) writeReachablePack(st storer.Storer, dir string) (string, []*object.Commit, error) {
+	objs, err := revlist.Objects(st, m.packedTips(), nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolving reachable objects: %w", err)
+	}
+	SortHashes(objs)
+	commits, err := CommitsOf(st, objs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	f, err := os.CreateTemp(dir, "*.pack")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating packfile: %w", err)
+	}
+	defer f.Close()
+
+	h, err := packfile.NewEncoder(f, st, false).Encode(objs, PackWindow)
+	if err != nil {
+		return "", nil, fmt.Errorf("encoding packfile: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", nil, fmt.Errorf("closing packfile: %w", err)
+	}
+
+	// match the naming of packfiles created on push
+	packPath := filepath.Join(dir, fmt.Sprintf("pack-%s.pack", h.String()))
+	if err := os.Rename(f.Name(), packPath); err != nil {
+		return "", nil, fmt.Errorf("renaming packfile: %w", err)
+	}
+
+	return packPath, commits, nil
+}
+
+// packedTips returns the distinct commits of the current references backed by
+// a packfile layer.
+func (m *model) packedTips() []plumbing.Hash {
+	seen := make(map[plumbing.Hash]struct{}, len(m.cfg.Heads)+len(m.cfg.Tags))
+	tips := make([]plumbing.Hash, 0, len(m.cfg.Heads)+len(m.cfg.Tags))
+	for _, refs := range []map[plumbing.ReferenceName]oci.ReferenceInfo{m.cfg.Heads, m.cfg.Tags, m.cfg.Notes} {
+		for _, info := range refs {
+			if info.Layer == "" {
+				// not backed by a packfile, e.g. the temporary LFS manifest ref
+				continue
+			}
+			h := plumbing.NewHash(info.Commit)
+			if _, ok := seen[h]; !ok {
+				tips = append(tips, h)
+				seen[h] = struct{}{}
+			}
+		}
+	}
+
+	return tips
+}
+
+// deleteLayers removes superseded packfile layers from the remote, if
+// supported, except those of snapshots, releases, the tag's history, and
+// other tags.
+// Failures are not fatal, as the layers are no longer referenced by the Git
+// manifest.
+func (m *model) deleteLayers(ctx context.Context, current ocispec.Descriptor, superseded []ocispec.Descriptor) {
+	d, ok := deleter(m.gt)
+	if !ok {
+		slog.InfoContext(ctx, "remote does not support deletion, superseded packfile layers remain")
+		return
+	}
+
+	retained, err := m.retainedDigests(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "resolving layers of snapshots, releases, history, and other tags, superseded packfile layers remain", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, desc := range superseded {
+		if desc.Digest == current.Digest {
+			continue
+		}
+		if _, ok := retained[desc.Digest]; ok {
+			slog.DebugContext(ctx, "keeping superseded packfile layer of a snapshot, release, history, or other tag", slog.String("digest", desc.Digest.String()))
+			continue
+		}
+		if err := d.Delete(ctx, desc); err != nil {
+			slog.WarnContext(ctx, "failed to delete superseded packfile layer", slog.String("digest", desc.Digest.String()), slog.String("error", err.Error()))
+			continue
+		}
+		slog.DebugContext(ctx, "deleted superseded packfile layer", slog.String("digest", desc.Digest.String()))
+	}
+}
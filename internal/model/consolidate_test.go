@@ -0,0 +1,290 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// deleterTarget extends an [oras.GraphTarget] with recording deletions, and
+// listing the tags tagged through it.
+type deleterTarget struct {
+	oras.GraphTarget
+	deleted []digest.Digest
+	tags    []string
+}
+
+func (d *deleterTarget) Delete(_ context.Context, target ocispec.Descriptor) error {
+	d.deleted = append(d.deleted, target.Digest)
+	return nil
+}
+
+func (d *deleterTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := d.GraphTarget.Tag(ctx, desc, reference); err != nil {
+		return err //nolint:wrapcheck
+	}
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if !slices.Contains(d.tags, ref.Reference) {
+		d.tags = append(d.tags, ref.Reference)
+	}
+	return nil
+}
+
+func (d *deleterTarget) Tags(_ context.Context, _ string, fn func(tags []string) error) error {
+	return fn(slices.Sorted(slices.Values(d.tags)))
+}
+
+var (
+	_ content.Deleter    = (*deleterTarget)(nil)
+	_ registry.TagLister = (*deleterTarget)(nil)
+)
+
+// newConsolidateModel returns a model of two packfile layers, the first
+// holding the first two commits, the second holding a commit that is no longer
+// referenced.
+func newConsolidateModel(t *testing.T) (*model, *deleterTarget, []plumbing.Hash) {
+	t.Helper()
+
+	builder, err := testutils.NewRepoBuilder(t.TempDir())
+	assert.NoError(t, err)
+
+	commits := make([]plumbing.Hash, 0, 3)
+	for range 3 {
+		h, err := builder.CreateRandomCommit(64)
+		assert.NoError(t, err)
+		commits = append(commits, h)
+	}
+	st := builder.Repo().Storer
+
+	pushPack := func(gt oras.GraphTarget, tips, ignore []plumbing.Hash) ocispec.Descriptor {
+		objs, err := revlist.Objects(st, tips, ignore)
+		assert.NoError(t, err)
+		buf := new(bytes.Buffer)
+		_, err = packfile.NewEncoder(buf, st, false).Encode(objs, 10)
+		assert.NoError(t, err)
+
+		desc, err := oras.PushBytes(t.Context(), gt, oci.MediaTypePackLayer, buf.Bytes())
+		assert.NoError(t, err)
+		return desc
+	}
+
+	gt := &deleterTarget{GraphTarget: orasmemory.New()}
+	layer0 := pushPack(gt, []plumbing.Hash{commits[1]}, nil)
+	layer1 := pushPack(gt, []plumbing.Hash{commits[2]}, []plumbing.Hash{commits[1]})
+
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, fstore.Close())
+	})
+
+	m := &model{
+		ref:     testRemote,
+		gt:      gt,
+		fstore:  fstore,
+		fetched: true,
+		man: ocispec.Manifest{
+			Layers: []ocispec.Descriptor{layer0, layer1},
+		},
+		cfg: oci.ConfigGit{
+			Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				plumbing.Main: {Commit: commits[1].String(), Layer: layer0.Digest},
+			},
+			Tags: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				"refs/tags/v1": {Commit: commits[0].String(), Layer: layer0.Digest},
+			},
+		},
+		refsByLayer: map[digest.Digest][]plumbing.Hash{},
+	}
+
+	return m, gt, commits
+}
+
+func Test_model_Consolidate(t *testing.T) {
+	m, gt, commits := newConsolidateModel(t)
+	layer0, layer1 := m.man.Layers[0], m.man.Layers[1]
+
+	manDesc, err := m.Consolidate(t.Context())
+	assert.NoError(t, err)
+
+	// validate model
+	assert.Len(t, m.man.Layers, 1)
+	newLayer := m.man.Layers[0]
+	assert.Equal(t, newLayer.Digest, m.cfg.Heads[plumbing.Main].Layer)
+	assert.Equal(t, newLayer.Digest, m.cfg.Tags["refs/tags/v1"].Layer)
+
+	// the consolidated packfile may be identical to the first layer
+	expectedDeleted := make([]digest.Digest, 0, 2)
+	for _, desc := range []ocispec.Descriptor{layer0, layer1} {
+		if desc.Digest != newLayer.Digest {
+			expectedDeleted = append(expectedDeleted, desc.Digest)
+		}
+	}
+	assert.ElementsMatch(t, expectedDeleted, gt.deleted)
+
+	// validate tag
+	gotManDesc, err := gt.Resolve(t.Context(), testRemote.String())
+	assert.NoError(t, err)
+	assert.Equal(t, manDesc, gotManDesc)
+
+	manRaw, err := content.FetchAll(t.Context(), gt, manDesc)
+	assert.NoError(t, err)
+	var man ocispec.Manifest
+	err = json.Unmarshal(manRaw, &man)
+	assert.NoError(t, err)
+	layers, graphDesc := splitCommitGraph(man.Layers)
+	assert.Equal(t, []ocispec.Descriptor{newLayer}, layers)
+
+	// validate the commit-graph indexes the consolidated packfile
+	graphRaw, err := content.FetchAll(t.Context(), gt, graphDesc)
+	assert.NoError(t, err)
+	graph, err := decodeCommitGraph(graphRaw)
+	assert.NoError(t, err)
+	assert.True(t, graph.complete(layers))
+	for _, c := range commits[:2] {
+		layer, ok := graph.layerOf(c, layers)
+		assert.True(t, ok)
+		assert.Equal(t, newLayer.Digest, layer)
+	}
+
+	// validate the consolidated packfile only contains reachable objects
+	rc, err := gt.Fetch(t.Context(), newLayer)
+	assert.NoError(t, err)
+	defer rc.Close()
+
+	unpacked := memory.NewStorage()
+	err = packfile.UpdateObjectStorage(unpacked, rc)
+	assert.NoError(t, err)
+
+	for _, c := range commits[:2] {
+		assert.NoError(t, unpacked.HasEncodedObject(c))
+	}
+	assert.ErrorIs(t, unpacked.HasEncodedObject(commits[2]), plumbing.ErrObjectNotFound)
+}
+
+func Test_model_Consolidate_Retained(t *testing.T) {
+	// both layers are referenced, such that consolidation supersedes both
+	newModel := func(t *testing.T) (*model, *deleterTarget) {
+		t.Helper()
+		m, gt, commits := newConsolidateModel(t)
+		m.cfg.Heads["refs/heads/feature"] = oci.ReferenceInfo{Commit: commits[2].String(), Layer: m.man.Layers[1].Digest}
+		return m, gt
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		m, gt := newModel(t)
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+		superseded := m.man.Layers
+
+		_, err = m.Consolidate(t.Context())
+		assert.NoError(t, err)
+		for _, desc := range superseded {
+			assert.Contains(t, gt.deleted, desc.Digest)
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		m, gt := newModel(t)
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+		_, err = m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		snapshotted := m.man.Layers
+
+		_, err = m.Consolidate(t.Context())
+		assert.NoError(t, err)
+		for _, desc := range snapshotted {
+			assert.NotContains(t, gt.deleted, desc.Digest)
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		m, gt := newModel(t)
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+		_, err = m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+		released := m.man.Layers
+
+		_, err = m.Consolidate(t.Context())
+		assert.NoError(t, err)
+		for _, desc := range released {
+			assert.NotContains(t, gt.deleted, desc.Digest)
+		}
+	})
+
+	t.Run("History", func(t *testing.T) {
+		m, gt := newModel(t)
+		m.tagHistory = true
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+		tagged := m.man.Layers
+
+		_, err = m.Consolidate(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, m.history, 2)
+		for _, desc := range tagged {
+			assert.NotContains(t, gt.deleted, desc.Digest)
+		}
+	})
+
+	t.Run("Other Project", func(t *testing.T) {
+		m, gt := newModel(t)
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+
+		// another project of the same repository, sharing the first layer
+		shared, unshared := m.man.Layers[0], m.man.Layers[1]
+		other, err := oras.PackManifest(t.Context(), gt, oras.PackManifestVersion1_1, oci.ArtifactTypeGitManifest, oras.PackManifestOptions{
+			Layers: []ocispec.Descriptor{shared},
+		})
+		assert.NoError(t, err)
+		otherRef := m.ref
+		otherRef.Reference = "projects.bar"
+		assert.NoError(t, gt.Tag(t.Context(), other, otherRef.String()))
+
+		_, err = m.Consolidate(t.Context())
+		assert.NoError(t, err)
+		assert.NotContains(t, gt.deleted, shared.Digest)
+		assert.Contains(t, gt.deleted, unshared.Digest)
+	})
+
+	t.Run("Orphaned", func(t *testing.T) {
+		m, gt := newModel(t)
+		m.deleteOrphans = true
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+		_, err = m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		orphan := m.man.Layers[1]
+
+		// deleting the branch orphans its layer, kept by the snapshot
+		assert.NoError(t, m.DeleteRef(t.Context(), "refs/heads/feature"))
+		_, err = m.Push(t.Context())
+		assert.NoError(t, err)
+		assert.NotContains(t, m.man.Layers, orphan)
+		assert.NotContains(t, gt.deleted, orphan.Digest)
+	})
+}
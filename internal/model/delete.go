@@ -0,0 +1,316 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/internal/tracing"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+var (
+	// ErrDeleteUnsupported indicates the remote does not support deleting
+	// manifests and blobs.
+	ErrDeleteUnsupported = errors.New("remote does not support deletion")
+	// errTagNotGitManifest indicates the remote tag refers to an image index
+	// rather than the Git manifest directly, e.g. a release.
+	errTagNotGitManifest = errors.New("tag does not refer to a Git manifest")
+)
+
+// DeleteOptions configure deleting a Git OCI data model.
+type DeleteOptions struct {
+	// DryRun plans the deletion without deleting anything.
+	DryRun bool
+	// KeepLFS keeps the LFS manifest referring to the Git manifest, and its
+	// LFS files.
+	KeepLFS bool
+}
+
+// Deletion is a manifest or blob deleted from the remote.
+type Deletion struct {
+	// Kind describes the content, one of "manifest", "config", "layer",
+	// "lfs", "signature", "metadata", "history", "release", "index", or
+	// "referrer".
+	Kind string `json:"kind"`
+	// MediaType is the media type of the content.
+	MediaType string `json:"mediaType"`
+	// Digest is the digest of the content.
+	Digest digest.Digest `json:"digest"`
+	// Size is the size of the content in bytes.
+	Size int64 `json:"size"`
+}
+
+// deletion is a planned deletion of desc from a store.
+type deletion struct {
+	Deletion
+	store Store
+	desc  ocispec.Descriptor
+}
+
+func (m *model) Delete(ctx context.Context, opts DeleteOptions) (_ []Deletion, err error) {
+	ctx, span := tracing.Start(ctx, "model.Delete", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
+	if err := m.checkTagged(); err != nil {
+		return nil, err
+	}
+
+	// with a tag history, the tag refers to the first Git manifest
+	tagged := m.manDesc
+	if m.root.Digest != "" {
+		tagged = m.root
+	}
+	if m.tagDesc.Digest != tagged.Digest || m.tagDesc.MediaType == ocispec.MediaTypeImageIndex {
+		return nil, fmt.Errorf("%w: %s resolves to %s, e.g. a release", errTagNotGitManifest, m.ref, m.tagDesc.Digest)
+	}
+
+	planned, err := m.planDelete(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	deletions := make([]Deletion, 0, len(planned))
+	for _, d := range planned {
+		deletions = append(deletions, d.Deletion)
+	}
+	if opts.DryRun {
+		return deletions, nil
+	}
+
+	for _, d := range planned {
+		if _, ok := deleter(d.store); !ok {
+			return nil, ErrDeleteUnsupported
+		}
+	}
+
+	var errs []error
+	for _, d := range planned {
+		del, _ := deleter(d.store)
+		slog.DebugContext(ctx, "deleting content", slog.String("kind", d.Kind), slog.String("digest", d.Digest.String()))
+		if err := del.Delete(ctx, d.desc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("deleting %s %s: %w", d.Kind, d.Digest, err))
+		}
+	}
+	if len(errs) > 0 {
+		return deletions, errors.Join(errs...)
+	}
+	m.resetFetched()
+
+	return deletions, nil
+}
+
+// planDelete lists the content of the fetched Git manifest in order of
+// deletion: the snapshot and release indexes and each release, then the
+// referrers of each Git manifest followed by their blobs, then each Git
+// manifest followed by its config and layers. Git manifests are those of the
+// tag and its history, snapshots, and releases, as deleting a manifest
+// deletes every tag of it, and their layers are shared. The empty blob is
+// never deleted, as it is shared by unrelated artifacts. LFS files of a
+// separate LFS repository are kept, as they may be shared with other
+// repositories. Content shared with other Git manifests or releases tagged in
+// the repository, e.g. other projects, is kept.
+func (m *model) planDelete(ctx context.Context, opts DeleteOptions) ([]deletion, error) {
+	shared := make(map[digest.Digest]struct{})
+	if err := m.retainOtherTags(ctx, shared); err != nil {
+		if errors.Is(err, errTagsUnsupported) {
+			return nil, fmt.Errorf("%w: %w", ErrDeleteUnsupported, err)
+		}
+		return nil, fmt.Errorf("resolving content of other tags: %w", err)
+	}
+
+	var planned []deletion
+	seen := make(map[digest.Digest]struct{})
+	add := func(store Store, kind string, desc ocispec.Descriptor) {
+		if _, ok := seen[desc.Digest]; ok || desc.Digest == ocispec.DescriptorEmptyJSON.Digest {
+			return
+		}
+		if _, ok := shared[desc.Digest]; ok {
+			slog.DebugContext(ctx, "keeping content shared with another tag", slog.String("kind", kind), slog.String("digest", desc.Digest.String()))
+			return
+		}
+		seen[desc.Digest] = struct{}{}
+		planned = append(planned, deletion{
+			Deletion: Deletion{Kind: kind, MediaType: desc.MediaType, Digest: desc.Digest, Size: desc.Size},
+			store:    store,
+			desc:     desc,
+		})
+	}
+
+	manifests, err := m.taggedManifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// indexes refer to the Git manifests, and are deleted first
+	for _, tag := range []string{m.snapshotIndexTag(), m.releaseIndexTag()} {
+		desc, err := m.gt.Resolve(ctx, tag)
+		switch {
+		case errors.Is(err, errdef.ErrNotFound):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("resolving index %s: %w", tag, err)
+		}
+		add(m.gt, "index", desc)
+	}
+	releases, err := m.fetchIndex(ctx, m.releaseIndexTag(), oci.ArtifactTypeGitReleases)
+	if err != nil {
+		return nil, fmt.Errorf("fetching release index: %w", err)
+	}
+	for _, desc := range releases.Manifests {
+		// the LFS manifest of a release is a referrer of its Git manifest
+		manDesc, err := m.releaseManifest(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		add(m.gt, "release", desc)
+		if !containsDigest(manifests, manDesc.Digest) {
+			manifests = append(manifests, manDesc)
+		}
+	}
+	snapshots, err := m.fetchIndex(ctx, m.snapshotIndexTag(), oci.ArtifactTypeGitSnapshots)
+	if err != nil {
+		return nil, fmt.Errorf("fetching snapshot index: %w", err)
+	}
+	for _, desc := range snapshots.Manifests {
+		if !containsDigest(manifests, desc.Digest) {
+			manifests = append(manifests, desc)
+		}
+	}
+
+	for _, manDesc := range manifests {
+		if err := m.planReferrers(ctx, manDesc, opts, add); err != nil {
+			return nil, err
+		}
+	}
+	for _, manDesc := range manifests {
+		if err := m.planManifest(ctx, m.gt, "manifest", manDesc, add); err != nil {
+			return nil, err
+		}
+	}
+
+	return planned, nil
+}
+
+// taggedManifests returns the Git manifests of the tag, the latest first. With
+// a tag history, these are the Git manifests of every history index, including
+// those diverged by concurrent pushes, and the tagged manifest.
+func (m *model) taggedManifests(ctx context.Context) ([]ocispec.Descriptor, error) {
+	if m.root.Digest == "" {
+		return []ocispec.Descriptor{m.manDesc}, nil
+	}
+
+	manifests := slices.Clone(m.history)
+	slices.Reverse(manifests)
+	histories, err := listReferrers(ctx, m.gt, m.root, oci.ArtifactTypeGitHistory)
+	if err != nil {
+		return nil, fmt.Errorf("resolving history referrers: %w", err)
+	}
+	for _, desc := range histories {
+		history, err := m.fetchHistory(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range slices.Backward(history) {
+			if !containsDigest(manifests, d.Digest) {
+				manifests = append(manifests, d)
+			}
+		}
+	}
+
+	return manifests, nil
+}
+
+// planReferrers plans the deletion of the referrers of the Git manifest
+// manDesc, each followed by its blobs.
+func (m *model) planReferrers(ctx context.Context, manDesc ocispec.Descriptor, opts DeleteOptions, add func(Store, string, ocispec.Descriptor)) error {
+	referrers, err := listReferrers(ctx, m.gt, manDesc, "")
+	if err != nil {
+		return fmt.Errorf("listing referrers of %s: %w", manDesc.Digest, err)
+	}
+	for _, desc := range referrers {
+		if desc.ArtifactType == oci.ArtifactTypeLFSManifest && (opts.KeepLFS || m.lfsGT != nil) {
+			// planned below, if stored separately
+			continue
+		}
+		if err := m.planReferrer(ctx, m.gt, desc, true, add); err != nil {
+			return err
+		}
+	}
+	if m.lfsGT != nil && !opts.KeepLFS {
+		lfsReferrers, err := m.lfsReferrers(ctx, manDesc)
+		if err != nil {
+			return err
+		}
+		for _, desc := range lfsReferrers {
+			if err := m.planReferrer(ctx, m.lfsGT, desc, false, add); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// planReferrer plans the deletion of the referrer manifest desc in store,
+// followed by its config and layers if blobs is true. Referrers which are
+// image indexes, e.g. history indexes, are deleted without the manifests
+// they list.
+func (m *model) planReferrer(ctx context.Context, store Store, desc ocispec.Descriptor, blobs bool, add func(Store, string, ocispec.Descriptor)) error {
+	kind := referrerKind(desc.ArtifactType)
+	if !blobs || desc.MediaType != ocispec.MediaTypeImageManifest {
+		add(store, kind, desc)
+		return nil
+	}
+
+	return m.planManifest(ctx, store, kind, desc, add)
+}
+
+// planManifest plans the deletion of the manifest desc in store, followed by
+// its config and layers, of the given kind. The layers of Git manifests
+// include their pack index and commit-graph layers.
+func (m *model) planManifest(ctx context.Context, store Store, kind string, desc ocispec.Descriptor, add func(Store, string, ocispec.Descriptor)) error {
+	manRaw, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		return fmt.Errorf("fetching %s %s: %w", kind, desc.Digest, err)
+	}
+	var man ocispec.Manifest
+	if err := json.Unmarshal(manRaw, &man); err != nil {
+		return fmt.Errorf("decoding %s %s: %w", kind, desc.Digest, err)
+	}
+
+	configKind, layerKind := kind, kind
+	if kind == "manifest" {
+		configKind, layerKind = "config", "layer"
+	}
+	add(store, kind, desc)
+	add(store, configKind, man.Config)
+	for _, layer := range man.Layers {
+		add(store, layerKind, layer)
+	}
+
+	return nil
+}
+
+// referrerKind returns the [Deletion] kind of a referrer of artifactType.
+func referrerKind(artifactType string) string {
+	switch artifactType {
+	case oci.ArtifactTypeLFSManifest:
+		return "lfs"
+	case oci.ArtifactTypeSignature:
+		return "signature"
+	case oci.ArtifactTypeGitMetadata:
+		return "metadata"
+	case oci.ArtifactTypeGitHistory:
+		return "history"
+	default:
+		return "referrer"
+	}
+}
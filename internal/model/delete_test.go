@@ -0,0 +1,207 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// untaggedDeleter supports deletion, but not listing tags.
+type untaggedDeleter struct {
+	oras.GraphTarget
+}
+
+func (untaggedDeleter) Delete(context.Context, ocispec.Descriptor) error {
+	return nil
+}
+
+func Test_model_Delete(t *testing.T) {
+	// setup pushes a Git manifest with an LFS and a signature referrer,
+	// returning the digests of the Git manifest content and of each referrer
+	// manifest and its layer
+	setup := func(t *testing.T, gt oras.GraphTarget) (git, lfs, sig []digest.Digest) {
+		t.Helper()
+
+		gitManifest, _ := setupRemote(t, gt)
+		subject, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		git = []digest.Digest{subject.Digest, gitManifest.Config.Digest, gitManifest.Layers[0].Digest}
+
+		referrer := func(artifactType, contents string) []digest.Digest {
+			layer, err := oras.PushBytes(t.Context(), gt, ocispec.MediaTypeImageLayer, []byte(contents))
+			assert.NoError(t, err)
+			desc, err := oras.PackManifest(t.Context(), gt, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+				Subject: &subject,
+				Layers:  []ocispec.Descriptor{layer},
+			})
+			assert.NoError(t, err)
+			return []digest.Digest{desc.Digest, layer.Digest}
+		}
+		return git, referrer(oci.ArtifactTypeLFSManifest, "lfs file"), referrer(oci.ArtifactTypeSignature, "signature")
+	}
+
+	// deleted returns the digests of deletions
+	deleted := func(deletions []Deletion) []digest.Digest {
+		dgsts := make([]digest.Digest, 0, len(deletions))
+		for _, d := range deletions {
+			dgsts = append(dgsts, d.Digest)
+		}
+		return dgsts
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		git, lfs, sig := setup(t, gt)
+
+		m := &model{ref: testRemote, gt: gt}
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		deletions, err := m.Delete(t.Context(), DeleteOptions{})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, append(append(git, lfs...), sig...), deleted(deletions))
+		assert.Equal(t, deleted(deletions), gt.deleted)
+		// referrers before their subject
+		assert.Equal(t, git, gt.deleted[len(gt.deleted)-3:])
+		assert.Equal(t, "manifest", deletions[len(deletions)-3].Kind)
+	})
+
+	t.Run("Dry Run Keep LFS", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		git, _, sig := setup(t, gt)
+
+		m := &model{ref: testRemote, gt: gt}
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		deletions, err := m.Delete(t.Context(), DeleteOptions{DryRun: true, KeepLFS: true})
+		assert.NoError(t, err)
+		assert.Equal(t, append(sig, git...), deleted(deletions))
+		assert.Equal(t, "signature", deletions[0].Kind)
+		assert.Empty(t, gt.deleted)
+	})
+
+	t.Run("Tag History", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: &immutableTagTarget{GraphTarget: orasmemory.New()}}
+		git, _, _ := setup(t, gt)
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		defer func() { assert.NoError(t, fstore.Close()) }()
+		pusher := NewModeler(testRemote, fstore, gt, WithTagHistory()).(*model)
+		_, err = pusher.Fetch(t.Context())
+		assert.NoError(t, err)
+		assert.NoError(t, pusher.UpdateRef(t.Context(), plumbing.NewHashReference("refs/heads/pushed", plumbing.ZeroHash), pusher.man.Layers[0].Digest))
+		latest, err := pusher.Push(t.Context())
+		assert.NoError(t, err)
+
+		m := NewModeler(testRemote, fstore, gt, WithTagHistory()).(*model)
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+		deletions, err := m.Delete(t.Context(), DeleteOptions{})
+		assert.NoError(t, err)
+
+		// the history index, then the latest and tagged Git manifests
+		assert.Contains(t, deleted(deletions), m.historyDesc.Digest)
+		assert.Equal(t, "history", deletions[slices.IndexFunc(deletions, func(d Deletion) bool { return d.Digest == m.historyDesc.Digest })].Kind)
+		assert.Contains(t, deleted(deletions), latest.Digest)
+		// the tagged Git manifest last, its layer shared with the latest
+		assert.Subset(t, gt.deleted, git)
+		assert.Equal(t, git[:2], gt.deleted[len(gt.deleted)-2:])
+	})
+
+	t.Run("Snapshots And Releases", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		git, _, _ := setup(t, gt)
+
+		m := &model{ref: testRemote, gt: gt}
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		release, err := m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+		snapshotIdx, err := gt.Resolve(t.Context(), m.snapshotIndexTag())
+		assert.NoError(t, err)
+		releaseIdx, err := gt.Resolve(t.Context(), m.releaseIndexTag())
+		assert.NoError(t, err)
+
+		deletions, err := m.Delete(t.Context(), DeleteOptions{})
+		assert.NoError(t, err)
+
+		// the indexes and releases referring to the Git manifest, first
+		assert.Equal(t, []digest.Digest{snapshotIdx.Digest, releaseIdx.Digest, release.Digest}, gt.deleted[:3])
+		assert.Equal(t, []string{"index", "index", "release"}, []string{deletions[0].Kind, deletions[1].Kind, deletions[2].Kind})
+		assert.Equal(t, git, gt.deleted[len(gt.deleted)-3:])
+	})
+
+	t.Run("Other Project Shares Layer", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		git, lfs, sig := setup(t, gt)
+
+		// another project of the same repository, sharing the packfile layer
+		// and the LFS file
+		shared := []digest.Digest{git[2], lfs[1]}
+		config, err := oras.PushBytes(t.Context(), gt, oci.MediaTypeGitConfig, []byte(`{"heads":{}}`))
+		assert.NoError(t, err)
+		other, err := oras.PackManifest(t.Context(), gt, oras.PackManifestVersion1_1, oci.ArtifactTypeGitManifest, oras.PackManifestOptions{
+			ConfigDescriptor: &config,
+			Layers:           []ocispec.Descriptor{{MediaType: oci.MediaTypePackLayer, Digest: git[2]}},
+		})
+		assert.NoError(t, err)
+		otherRef := testRemote
+		otherRef.Reference = "projects.bar"
+		assert.NoError(t, gt.Tag(t.Context(), other, otherRef.String()))
+		otherLFS, err := oras.PackManifest(t.Context(), gt, oras.PackManifestVersion1_1, oci.ArtifactTypeLFSManifest, oras.PackManifestOptions{
+			Subject: &other,
+			Layers:  []ocispec.Descriptor{{MediaType: ocispec.MediaTypeImageLayer, Digest: lfs[1]}},
+		})
+		assert.NoError(t, err)
+
+		m := &model{ref: testRemote, gt: gt}
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		deletions, err := m.Delete(t.Context(), DeleteOptions{})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []digest.Digest{git[0], git[1], lfs[0], sig[0], sig[1]}, deleted(deletions))
+		for _, dgst := range append(shared, other.Digest, config.Digest, otherLFS.Digest) {
+			assert.NotContains(t, gt.deleted, dgst)
+		}
+	})
+
+	t.Run("Tags Unsupported", func(t *testing.T) {
+		// content shared with other tags cannot be found
+		gt := untaggedDeleter{GraphTarget: orasmemory.New()}
+		setup(t, gt)
+
+		m := &model{ref: testRemote, gt: gt}
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.Delete(t.Context(), DeleteOptions{DryRun: true})
+		assert.ErrorIs(t, err, ErrDeleteUnsupported)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		gt := orasmemory.New()
+		setup(t, gt)
+
+		m := &model{ref: testRemote, gt: gt}
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.Delete(t.Context(), DeleteOptions{})
+		assert.ErrorIs(t, err, ErrDeleteUnsupported)
+	})
+}
@@ -0,0 +1,364 @@
+package model
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// ErrEncryptionKeyNotFound indicates a layer is encrypted with a key that is
+// not configured.
+var ErrEncryptionKeyNotFound = errors.New("encryption key not found")
+
+// Encrypted layers are a salt followed by a sequence of AES-256-GCM sealed
+// chunks. Each layer is sealed with a key derived from the salt, which is in
+// turn derived from the plaintext, such that encrypting identical content
+// produces identical layers. Chunk nonces are a counter, with the final chunk
+// flagged to detect truncation.
+const (
+	encryptionKeySize   = 32
+	encryptionSaltSize  = 32
+	encryptionChunkSize = 64 * 1024
+	encryptionTagSize   = 16
+	encryptionInfo      = "gnoci layer encryption v1"
+)
+
+// EncryptionKey is an AES-256 key encrypting packfile and LFS layers.
+type EncryptionKey struct {
+	// ID identifies the key in the annotations of layers it encrypts.
+	ID string
+	// Key is the 32 byte secret.
+	Key []byte
+}
+
+// ParseEncryptionKey decodes a base64 encoded 256-bit key, surrounding
+// whitespace ignored. An empty id defaults to a fingerprint of the key.
+func ParseEncryptionKey(id string, encoded []byte) (EncryptionKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return EncryptionKey{}, fmt.Errorf("decoding base64 encryption key: %w", err)
+	}
+	if len(key) != encryptionKeySize {
+		return EncryptionKey{}, fmt.Errorf("encryption key must be %d bytes, got %d", encryptionKeySize, len(key))
+	}
+	if id == "" {
+		sum := sha256.Sum256(key)
+		id = hex.EncodeToString(sum[:8])
+	}
+
+	return EncryptionKey{ID: id, Key: key}, nil
+}
+
+// WithEncryption encrypts packfile and LFS layers as they are added with the
+// first of keys. Layers encrypted with any of keys are decrypted when fetched,
+// allowing keys to be rotated. Existing unencrypted layers are unaffected.
+func WithEncryption(keys ...EncryptionKey) Option {
+	return func(m *model) {
+		m.encryptionKeys = keys
+	}
+}
+
+// isEncrypted reports whether a layer of mediaType is encrypted.
+func isEncrypted(mediaType string) bool {
+	return strings.HasSuffix(mediaType, oci.MediaTypeSuffixEncrypted)
+}
+
+// encryptionKey returns the key a layer is encrypted with.
+func (m *model) encryptionKey(desc ocispec.Descriptor) (EncryptionKey, error) {
+	id := desc.Annotations[oci.AnnotationEncryptionKeyID]
+	for _, key := range m.encryptionKeys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return EncryptionKey{}, fmt.Errorf("%w: layer %s encrypted with key %q", ErrEncryptionKeyNotFound, desc.Digest, id)
+}
+
+// encryptLayer writes an encrypted copy of the file at path to dst with the
+// first encryption key, returning the media type and annotations of the
+// encrypted layer.
+func (m *model) encryptLayer(path, dst, mediaType string) (string, map[string]string, error) {
+	key := m.encryptionKeys[0]
+	if err := encryptFile(key.Key, path, dst); err != nil {
+		return "", nil, err
+	}
+
+	return mediaType + oci.MediaTypeSuffixEncrypted, map[string]string{oci.AnnotationEncryptionKeyID: key.ID}, nil
+}
+
+// withAnnotations returns desc with annotations added, without modifying the
+// annotations of the original.
+func withAnnotations(desc ocispec.Descriptor, annotations map[string]string) ocispec.Descriptor {
+	desc.Annotations = maps.Clone(desc.Annotations)
+	if desc.Annotations == nil {
+		desc.Annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(desc.Annotations, annotations)
+	return desc
+}
+
+// decryptLayer decrypts the layer read by rc, closing rc when closed.
+func (m *model) decryptLayer(rc io.ReadCloser, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	key, err := m.encryptionKey(desc)
+	if err != nil {
+		return nil, errors.Join(err, rc.Close())
+	}
+
+	r, err := newDecryptReader(key.Key, rc)
+	if err != nil {
+		return nil, errors.Join(err, rc.Close())
+	}
+
+	return &decryptReadCloser{Reader: r, rc: rc}, nil
+}
+
+// decryptReadCloser decrypts a layer, closing the underlying stream when
+// closed.
+type decryptReadCloser struct {
+	io.Reader
+	rc io.ReadCloser
+}
+
+// Close closes the underlying stream.
+func (d *decryptReadCloser) Close() error {
+	return d.rc.Close() //nolint:wrapcheck
+}
+
+// encryptedSize returns the size of an encrypted layer of n plaintext bytes.
+func encryptedSize(n int64) int64 {
+	chunks := max(1, (n+encryptionChunkSize-1)/encryptionChunkSize)
+	return encryptionSaltSize + n + chunks*encryptionTagSize
+}
+
+// plaintextSize returns the size of the plaintext of an encrypted layer of n
+// bytes, the inverse of [encryptedSize].
+func plaintextSize(n int64) int64 {
+	n -= encryptionSaltSize
+	chunks := max(1, (n+encryptionChunkSize+encryptionTagSize-1)/(encryptionChunkSize+encryptionTagSize))
+	return n - chunks*encryptionTagSize
+}
+
+// lfsObjectDigest returns the digest of the LFS file stored in an LFS layer,
+// its OID, which differs from the layer digest if encrypted or digested with
+// another algorithm.
+func lfsObjectDigest(desc ocispec.Descriptor) digest.Digest {
+	if oid, ok := desc.Annotations[oci.AnnotationLFSOid]; ok {
+		return digest.Digest(oid)
+	}
+	return lfsPlaintextDigest(desc)
+}
+
+// lfsPlaintextDigest returns the digest of the LFS file stored in an LFS
+// layer, of the algorithm of the layer digest.
+func lfsPlaintextDigest(desc ocispec.Descriptor) digest.Digest {
+	if isEncrypted(desc.MediaType) {
+		return digest.Digest(desc.Annotations[oci.AnnotationEncryptionDigest])
+	}
+	return desc.Digest
+}
+
+// lfsObjectMatches returns true if the LFS file stored in an LFS layer has
+// dgst, of either the algorithm of its OID or of the layer digest. Chunk
+// layers never match, being parts of LFS files.
+func lfsObjectMatches(desc ocispec.Descriptor, dgst digest.Digest) bool {
+	if isLFSChunk(desc) {
+		return false
+	}
+	return lfsObjectDigest(desc) == dgst || lfsPlaintextDigest(desc) == dgst
+}
+
+// encryptFile writes an encrypted copy of the file at path to dst.
+func encryptFile(key []byte, path, dst string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening layer: %w", err)
+	}
+	defer src.Close()
+
+	dgst, err := digest.FromReader(src)
+	if err != nil {
+		return fmt.Errorf("digesting layer: %w", err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding layer: %w", err)
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("creating encrypted layer: %w", err)
+	}
+	defer out.Close()
+
+	w := bufio.NewWriter(out)
+	if err := encrypt(key, dgst, src, w); err != nil {
+		return fmt.Errorf("encrypting layer: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing encrypted layer: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("closing encrypted layer: %w", err)
+	}
+
+	return nil
+}
+
+// encrypt writes the encryption of r, whose digest is dgst, to w.
+func encrypt(key []byte, dgst digest.Digest, r io.Reader, w io.Writer) error {
+	// HMAC of the digest keyed by key
+	salt, err := hkdf.Extract(sha256.New, []byte(dgst), key)
+	if err != nil {
+		return fmt.Errorf("deriving layer salt: %w", err)
+	}
+
+	aead, err := newLayerAEAD(key, salt)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	buf := make([]byte, encryptionChunkSize+1)
+	sealed := make([]byte, 0, encryptionChunkSize+aead.Overhead())
+	n, err := io.ReadFull(r, buf)
+	for counter := uint64(0); ; counter++ {
+		switch {
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			// final chunk
+			sealed = aead.Seal(sealed[:0], chunkNonce(counter, true), buf[:n], nil)
+			_, err := w.Write(sealed)
+			return err //nolint:wrapcheck
+		case err != nil:
+			return err //nolint:wrapcheck
+		}
+
+		// a full chunk with one byte read ahead, ensuring the final chunk is
+		// flagged even if the plaintext is a multiple of the chunk size
+		sealed = aead.Seal(sealed[:0], chunkNonce(counter, false), buf[:encryptionChunkSize], nil)
+		if _, err := w.Write(sealed); err != nil {
+			return err //nolint:wrapcheck
+		}
+		buf[0] = buf[encryptionChunkSize]
+		n, err = io.ReadFull(r, buf[1:])
+		n++
+	}
+}
+
+// decryptReader decrypts an encrypted layer as it is read.
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	counter uint64
+	chunk   []byte
+	plain   []byte
+	err     error
+}
+
+// newDecryptReader reads the salt of an encrypted layer from r, returning a
+// reader of its plaintext.
+func newDecryptReader(key []byte, r io.Reader) (*decryptReader, error) {
+	br := bufio.NewReaderSize(r, encryptionChunkSize*2)
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := io.ReadFull(br, salt); err != nil {
+		return nil, fmt.Errorf("reading encrypted layer header: %w", err)
+	}
+
+	aead, err := newLayerAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		r:     br,
+		aead:  aead,
+		chunk: make([]byte, encryptionChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Read decrypts the layer one chunk at a time, authenticating each chunk
+// before its plaintext is returned.
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.next()
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next decrypts the next chunk, returning [io.EOF] after the final chunk.
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.chunk)
+	final := errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+	switch {
+	case final:
+	case err != nil:
+		return fmt.Errorf("reading encrypted layer: %w", err)
+	default:
+		// a full chunk is final if nothing follows it
+		if _, err := d.r.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return fmt.Errorf("reading encrypted layer: %w", err)
+		}
+	}
+
+	d.plain, err = d.aead.Open(d.chunk[:0], chunkNonce(d.counter, final), d.chunk[:n], nil)
+	if err != nil {
+		return fmt.Errorf("decrypting layer chunk %d: %w", d.counter, err)
+	}
+	d.counter++
+	if final {
+		return io.EOF
+	}
+	return nil
+}
+
+// newLayerAEAD returns the cipher sealing the chunks of a layer with salt.
+func newLayerAEAD(key, salt []byte) (cipher.AEAD, error) {
+	layerKey, err := hkdf.Key(sha256.New, key, salt, encryptionInfo, encryptionKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("deriving layer key: %w", err)
+	}
+	block, err := aes.NewCipher(layerKey)
+	if err != nil {
+		return nil, fmt.Errorf("initializing cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("initializing cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// chunkNonce returns the nonce of the chunk at counter, flagging the final
+// chunk.
+func chunkNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
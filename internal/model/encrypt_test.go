@@ -0,0 +1,202 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// testEncryptionKey returns a key of repeated b.
+func testEncryptionKey(t *testing.T, id string, b byte) EncryptionKey {
+	t.Helper()
+	key, err := ParseEncryptionKey(id, []byte(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, encryptionKeySize))))
+	assert.NoError(t, err)
+	return key
+}
+
+func Test_encrypt(t *testing.T) {
+	key := testEncryptionKey(t, "", 1)
+
+	for _, size := range []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 2*encryptionChunkSize + 5} {
+		plaintext := bytes.Repeat([]byte("gnocchi "), size/8+1)[:size]
+
+		var sealed bytes.Buffer
+		err := encrypt(key.Key, digest.FromBytes(plaintext), bytes.NewReader(plaintext), &sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, encryptedSize(int64(size)), int64(sealed.Len()), "size %d", size)
+
+		// identical content produces identical layers
+		var again bytes.Buffer
+		err = encrypt(key.Key, digest.FromBytes(plaintext), bytes.NewReader(plaintext), &again)
+		assert.NoError(t, err)
+		assert.Equal(t, sealed.Bytes(), again.Bytes(), "size %d", size)
+
+		r, err := newDecryptReader(key.Key, bytes.NewReader(sealed.Bytes()))
+		assert.NoError(t, err)
+		got, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, got, "size %d", size)
+	}
+}
+
+func Test_decryptReader(t *testing.T) {
+	key := testEncryptionKey(t, "", 1)
+	plaintext := bytes.Repeat([]byte{'g'}, 2*encryptionChunkSize)
+
+	var buf bytes.Buffer
+	err := encrypt(key.Key, digest.FromBytes(plaintext), bytes.NewReader(plaintext), &buf)
+	assert.NoError(t, err)
+	sealed := buf.Bytes()
+
+	decrypt := func(key, sealed []byte) error {
+		r, err := newDecryptReader(key, bytes.NewReader(sealed))
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	t.Run("Tampered", func(t *testing.T) {
+		tampered := bytes.Clone(sealed)
+		tampered[len(tampered)/2] ^= 1
+		assert.Error(t, decrypt(key.Key, tampered))
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		// drop the final chunk
+		assert.Error(t, decrypt(key.Key, sealed[:encryptionSaltSize+encryptionChunkSize+encryptionTagSize]))
+		assert.Error(t, decrypt(key.Key, sealed[:encryptionSaltSize]))
+	})
+
+	t.Run("Wrong Key", func(t *testing.T) {
+		assert.Error(t, decrypt(testEncryptionKey(t, "", 2).Key, sealed))
+	})
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	raw := bytes.Repeat([]byte{7}, encryptionKeySize)
+
+	t.Run("Default ID", func(t *testing.T) {
+		key, err := ParseEncryptionKey("", []byte(base64.StdEncoding.EncodeToString(raw)+"\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, raw, key.Key)
+		assert.Len(t, key.ID, 16)
+	})
+
+	t.Run("ID", func(t *testing.T) {
+		key, err := ParseEncryptionKey("kms-2025", []byte(base64.StdEncoding.EncodeToString(raw)))
+		assert.NoError(t, err)
+		assert.Equal(t, "kms-2025", key.ID)
+	})
+
+	t.Run("Short", func(t *testing.T) {
+		_, err := ParseEncryptionKey("", []byte(base64.StdEncoding.EncodeToString(raw[:16])))
+		assert.Error(t, err)
+	})
+
+	t.Run("Not Base64", func(t *testing.T) {
+		_, err := ParseEncryptionKey("", []byte("not a key!"))
+		assert.Error(t, err)
+	})
+}
+
+func Test_model_encryptedLFS(t *testing.T) {
+	gt := memory.New()
+	setupRemote(t, gt)
+
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	defer fstore.Close()
+
+	lfsFileContents := "example file contents"
+	lfsFilePath := filepath.Join(t.TempDir(), "foolfs")
+	err = os.WriteFile(lfsFilePath, []byte(lfsFileContents), 0o644)
+	assert.NoError(t, err)
+	oid := digest.FromString(lfsFileContents)
+
+	oldKey, newKey := testEncryptionKey(t, "old", 1), testEncryptionKey(t, "new", 2)
+	m := NewLFSModeler(testRemote, fstore, gt, WithEncryption(oldKey))
+	subject, err := m.Fetch(t.Context())
+	assert.NoError(t, err)
+	_, err = m.FetchLFSOrDefault(t.Context())
+	assert.NoError(t, err)
+
+	lfsDesc, err := m.PushLFSFile(t.Context(), lfsFilePath, &PushLFSOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, oci.MediaTypeLFSLayerEncrypted, lfsDesc.MediaType)
+	assert.Equal(t, "old", lfsDesc.Annotations[oci.AnnotationEncryptionKeyID])
+	assert.Equal(t, oid.String(), lfsDesc.Annotations[oci.AnnotationEncryptionDigest])
+	assert.Equal(t, "foolfs.enc", lfsDesc.Annotations[ocispec.AnnotationTitle])
+	assert.Equal(t, encryptedSize(int64(len(lfsFileContents))), lfsDesc.Size)
+
+	raw, err := content.FetchAll(t.Context(), gt, lfsDesc)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), lfsFileContents)
+
+	// deduplicated by OID, with a new filestore to avoid its complaints
+	dupStore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	defer dupStore.Close()
+	m.(*model).fstore = dupStore
+	again, err := m.PushLFSFile(t.Context(), lfsFilePath, &PushLFSOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, lfsDesc, again)
+
+	_, err = m.PushLFSManifest(t.Context(), subject)
+	assert.NoError(t, err)
+
+	t.Run("List", func(t *testing.T) {
+		assert.Equal(t, []LFSObject{{
+			Oid:       oid.Encoded(),
+			Size:      int64(len(lfsFileContents)),
+			Title:     "foolfs.enc",
+			Digest:    lfsDesc.Digest,
+			Encrypted: true,
+		}}, m.ListLFSObjects())
+	})
+
+	t.Run("Rotated Key", func(t *testing.T) {
+		other := NewLFSModeler(testRemote, fstore, gt, WithEncryption(newKey, oldKey))
+		_, err := other.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = other.FetchLFS(t.Context())
+		assert.NoError(t, err)
+
+		rc, err := other.FetchLFSLayer(t.Context(), oid, &FetchLFSOptions{Offset: 8})
+		assert.NoError(t, err)
+		defer rc.Close()
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.Equal(t, lfsFileContents[8:], string(got))
+	})
+
+	t.Run("Missing Key", func(t *testing.T) {
+		other := NewLFSModeler(testRemote, fstore, gt, WithEncryption(newKey))
+		_, err := other.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = other.FetchLFS(t.Context())
+		assert.NoError(t, err)
+
+		_, err = other.FetchLFSLayer(t.Context(), oid, nil)
+		assert.ErrorIs(t, err, ErrEncryptionKeyNotFound)
+	})
+}
+
+func Test_plaintextSize(t *testing.T) {
+	for _, n := range []int64{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3*encryptionChunkSize + 7} {
+		assert.Equal(t, n, plaintextSize(encryptedSize(n)), "plaintext size %d", n)
+	}
+}
@@ -0,0 +1,187 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// FsckReport summarizes an integrity check of a Git OCI data model.
+type FsckReport struct {
+	// Layers is the number of packfile layers checked.
+	Layers int
+	// Commits is the number of commits found within the packfile layers.
+	Commits int
+	// Refs is the number of references checked.
+	Refs int
+	// Problems describes each integrity violation found, empty if none.
+	Problems []string
+}
+
+// OK returns true if no problems were found.
+func (r *FsckReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *FsckReport) addProblem(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// Fsck downloads and indexes each packfile layer of the fetched Git manifest,
+// verifying the layer digests, that every reference name is valid, and that
+// the commit of every reference and layer commit index exists within its
+// claimed layer or those before it.
+func (m *model) Fsck(ctx context.Context) (*FsckReport, error) {
+	if !m.fetched {
+		return nil, errors.New("remote metadata must be fetched before checking integrity")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gnoci-fsck-*")
+	if err != nil {
+		return nil, fmt.Errorf("initializing temp directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.ErrorContext(ctx, "removing temporary git repository", slog.String("error", err.Error()))
+		}
+	}()
+
+	repo, err := git.PlainInit(tmpDir, true)
+	if err != nil {
+		return nil, fmt.Errorf("initializing temp repository: %w", err)
+	}
+
+	report := &FsckReport{Layers: len(m.man.Layers)}
+
+	// index of the layer each commit was first unpacked from, and of each layer
+	firstLayer := make(map[plumbing.Hash]int)
+	layerIndex := make(map[digest.Digest]int, len(m.man.Layers))
+	// oldest to newest, ensuring delta bases exist before they're needed
+	for i, desc := range m.man.Layers {
+		layerIndex[desc.Digest] = i
+		if err := m.checkLayer(ctx, repo.Storer, desc); err != nil {
+			report.addProblem("packfile layer %s: %s", desc.Digest, err)
+		}
+
+		if err := collectCommits(repo.Storer, firstLayer, i); err != nil {
+			return nil, err
+		}
+		for _, commit := range PackCommits(desc) {
+			if _, ok := firstLayer[commit]; !ok {
+				report.addProblem("packfile layer %s: indexed commit %s not found", desc.Digest, commit)
+			}
+		}
+	}
+	report.Commits = len(firstLayer)
+
+	for _, group := range []struct {
+		prefix string
+		refs   map[plumbing.ReferenceName]oci.ReferenceInfo
+	}{
+		{"refs/heads/", m.cfg.Heads},
+		{"refs/tags/", m.cfg.Tags},
+		{"refs/notes/", m.cfg.Notes},
+	} {
+		for _, name := range slices.Sorted(maps.Keys(group.refs)) {
+			report.Refs++
+			info := group.refs[name]
+			if err := name.Validate(); err != nil || !strings.HasPrefix(name.String(), group.prefix) {
+				report.addProblem("reference %s: invalid reference name", name)
+			}
+			if info.Layer == "" {
+				// not backed by a packfile, e.g. the temporary LFS manifest ref
+				continue
+			}
+
+			i, ok := layerIndex[info.Layer]
+			if !ok {
+				report.addProblem("reference %s: layer %s not found in git manifest", name, info.Layer)
+				continue
+			}
+			if first, ok := firstLayer[plumbing.NewHash(info.Commit)]; !ok || first > i {
+				report.addProblem("reference %s: commit %s not found in layer %s or earlier", name, info.Commit, info.Layer)
+			}
+			for _, layer := range info.Layers {
+				if j, ok := layerIndex[layer]; !ok || j <= i {
+					report.addProblem("reference %s: history layer %s not found in git manifest after layer %s", name, layer, info.Layer)
+				}
+			}
+		}
+	}
+
+	if m.cfg.DefaultBranch != "" {
+		if _, ok := m.cfg.Heads[m.cfg.DefaultBranch]; !ok {
+			report.addProblem("default branch %s does not exist", m.cfg.DefaultBranch)
+		}
+	}
+
+	return report, nil
+}
+
+// checkLayer fetches a packfile layer, verifying its digest while unpacking
+// its objects to object storage.
+func (m *model) checkLayer(ctx context.Context, st storer.Storer, desc ocispec.Descriptor) error {
+	slog.DebugContext(ctx, "checking packfile layer", slog.String("digest", desc.Digest.String()))
+	rc, err := m.gt.Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("fetching: %w", err)
+	}
+	defer rc.Close()
+
+	vr := content.NewVerifyReader(rc, desc)
+	r, err := m.decodePackLayer(io.NopCloser(vr), desc)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	unpackErr := UnpackPack(st, r, PackBase(desc) != "")
+	// the digest is only verified once the layer is read in full
+	if _, err := io.Copy(io.Discard, vr); err != nil {
+		return fmt.Errorf("reading: %w", err)
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("verifying digest: %w", err)
+	}
+	if unpackErr != nil {
+		return fmt.Errorf("unpacking: %w", unpackErr)
+	}
+
+	return nil
+}
+
+// collectCommits records layer as the first layer of each commit in st not
+// already in firstLayer.
+func collectCommits(st storer.EncodedObjectStorer, firstLayer map[plumbing.Hash]int, layer int) error {
+	iter, err := st.IterEncodedObjects(plumbing.CommitObject)
+	if err != nil {
+		return fmt.Errorf("iterating commits: %w", err)
+	}
+	defer iter.Close()
+
+	err = iter.ForEach(func(obj plumbing.EncodedObject) error {
+		if _, ok := firstLayer[obj.Hash()]; !ok {
+			firstLayer[obj.Hash()] = layer
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("iterating commits: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// corruptTarget flips a byte of a blob when fetched.
+type corruptTarget struct {
+	oras.GraphTarget
+	corrupt digest.Digest
+}
+
+func (c *corruptTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := c.GraphTarget.Fetch(ctx, target)
+	if err != nil || target.Digest != c.corrupt {
+		return rc, err
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	b[len(b)-1] ^= 0xff
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func Test_model_Fsck(t *testing.T) {
+	builder, err := testutils.NewRepoBuilder(t.TempDir())
+	assert.NoError(t, err)
+
+	commits := make([]plumbing.Hash, 0, 3)
+	for range 3 {
+		h, err := builder.CreateRandomCommit(64)
+		assert.NoError(t, err)
+		commits = append(commits, h)
+	}
+	st := builder.Repo().Storer
+
+	gt := orasmemory.New()
+	pushPack := func(tips, ignore []plumbing.Hash, indexed ...plumbing.Hash) ocispec.Descriptor {
+		objs, err := revlist.Objects(st, tips, ignore)
+		assert.NoError(t, err)
+		buf := new(bytes.Buffer)
+		_, err = packfile.NewEncoder(buf, st, false).Encode(objs, 10)
+		assert.NoError(t, err)
+
+		desc, err := oras.PushBytes(t.Context(), gt, oci.MediaTypePackLayer, buf.Bytes())
+		assert.NoError(t, err)
+		if index, ok := packCommitsIndex(indexed); ok {
+			desc.Annotations = map[string]string{oci.AnnotationPackCommits: index}
+		}
+		return desc
+	}
+	// layer 0 holds the first two commits, layer 1 the third
+	layer0 := pushPack([]plumbing.Hash{commits[1]}, nil, commits[0], commits[1])
+	layer1 := pushPack([]plumbing.Hash{commits[2]}, []plumbing.Hash{commits[1]}, commits[2])
+
+	newModel := func(target oras.GraphTarget, cfg oci.ConfigGit) *model {
+		return &model{
+			ref:     testRemote,
+			gt:      target,
+			fetched: true,
+			man:     ocispec.Manifest{Layers: []ocispec.Descriptor{layer0, layer1}},
+			cfg:     cfg,
+		}
+	}
+
+	t.Run("Healthy", func(t *testing.T) {
+		m := newModel(gt, oci.ConfigGit{
+			Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				plumbing.Main: {Commit: commits[2].String(), Layer: layer1.Digest},
+			},
+			Tags: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				// commits in earlier layers are available
+				"refs/tags/v1": {Commit: commits[0].String(), Layer: layer1.Digest},
+			},
+			DefaultBranch: plumbing.Main,
+		})
+
+		report, err := m.Fsck(t.Context())
+		assert.NoError(t, err)
+		assert.True(t, report.OK(), report.Problems)
+		assert.Equal(t, &FsckReport{Layers: 2, Commits: 3, Refs: 2}, report)
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		m := newModel(&corruptTarget{GraphTarget: gt, corrupt: layer1.Digest}, oci.ConfigGit{
+			Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				plumbing.Main:         {Commit: commits[2].String(), Layer: layer0.Digest},
+				"refs/heads/bad..ref": {Commit: commits[0].String(), Layer: layer0.Digest},
+			},
+			Tags: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				"refs/heads/v1": {Commit: commits[0].String(), Layer: digest.FromString("missing")},
+			},
+			DefaultBranch: "refs/heads/dne",
+		})
+
+		report, err := m.Fsck(t.Context())
+		assert.NoError(t, err)
+		assert.False(t, report.OK())
+		assert.Len(t, report.Problems, 6)
+		assert.Contains(t, report.Problems[0], "packfile layer "+layer1.Digest.String()+": verifying digest")
+		assert.Contains(t, report.Problems, "reference refs/heads/v1: layer "+digest.FromString("missing").String()+" not found in git manifest")
+		assert.Contains(t, report.Problems, "reference refs/heads/bad..ref: invalid reference name")
+		assert.Contains(t, report.Problems, "reference refs/heads/main: commit "+commits[2].String()+" not found in layer "+layer0.Digest.String()+" or earlier")
+		assert.Contains(t, report.Problems, "reference refs/heads/v1: invalid reference name")
+		assert.Contains(t, report.Problems, "default branch refs/heads/dne does not exist")
+	})
+
+	t.Run("Not Fetched", func(t *testing.T) {
+		_, err := (&model{gt: gt}).Fsck(t.Context())
+		assert.Error(t, err)
+	})
+}
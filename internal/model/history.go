@@ -0,0 +1,263 @@
+package model
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/act3-ai/gnoci/internal/tracing"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// WithTagHistory supports registries rejecting tag overwrites, e.g. with tag
+// immutability enabled. The remote tag is only set by the first push, later
+// Git manifests are pushed by digest and recorded in a history index referring
+// to the tagged manifest, which fetches follow to the latest Git manifest.
+func WithTagHistory() Option {
+	return func(m *model) {
+		m.tagHistory = true
+	}
+}
+
+// resolveHistory follows the history of the tagged manifest, m.manDesc, to the
+// latest Git manifest pushed.
+func (m *model) resolveHistory(ctx context.Context) error {
+	m.root = plainDescriptor(m.manDesc)
+	m.history = []ocispec.Descriptor{m.root}
+	m.historyDesc = ocispec.Descriptor{}
+
+	latest, err := m.latestHistory(ctx)
+	switch {
+	case err != nil:
+		return err
+	case latest.Digest == "":
+		// nothing pushed since the tagged manifest
+		return nil
+	}
+
+	history, err := m.fetchHistory(ctx, latest)
+	if err != nil {
+		return err
+	}
+
+	m.history = history
+	m.historyDesc = latest
+	m.manDesc = history[len(history)-1]
+	slog.DebugContext(ctx, "resolved latest git manifest from history", slog.String("digest", m.manDesc.Digest.String()),
+		slog.Int("length", len(m.history)))
+
+	return nil
+}
+
+// fetchHistory returns the Git manifests listed by the history index desc,
+// oldest first.
+func (m *model) fetchHistory(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	idxRaw, err := content.FetchAll(ctx, m.gt, desc)
+	if err != nil {
+		return nil, fmt.Errorf("fetching history index: %w", err)
+	}
+	var idx ocispec.Index
+	if err := json.Unmarshal(idxRaw, &idx); err != nil {
+		return nil, fmt.Errorf("decoding history index: %w", err)
+	}
+	if len(idx.Manifests) == 0 {
+		return nil, fmt.Errorf("history index %s lists no Git manifests", desc.Digest)
+	}
+
+	return idx.Manifests, nil
+}
+
+// latestHistory returns the descriptor of the longest history index referring
+// to the tagged manifest, or an empty descriptor if there is none.
+func (m *model) latestHistory(ctx context.Context) (ocispec.Descriptor, error) {
+	referrers, err := listReferrers(ctx, m.gt, m.root, oci.ArtifactTypeGitHistory)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("resolving history referrers: %w", err)
+	}
+	if len(referrers) < 1 {
+		return ocispec.Descriptor{}, nil
+	}
+
+	// ties are concurrent pushes, of which only one may be followed
+	latest := slices.MaxFunc(referrers, compareHistory)
+	if n := historyLength(latest); slices.ContainsFunc(referrers, func(desc ocispec.Descriptor) bool {
+		return historyLength(desc) == n && desc.Digest != latest.Digest
+	}) {
+		slog.WarnContext(ctx, "remote history diverged by concurrent pushes, following one", slog.String("digest", latest.Digest.String()))
+	}
+
+	return latest, nil
+}
+
+// compareHistory orders history indexes by length, then digest.
+func compareHistory(a, b ocispec.Descriptor) int {
+	return cmp.Or(cmp.Compare(historyLength(a), historyLength(b)), cmp.Compare(a.Digest, b.Digest))
+}
+
+// historyLength returns the number of Git manifests listed by a history index.
+func historyLength(desc ocispec.Descriptor) int {
+	n, err := strconv.Atoi(desc.Annotations[oci.AnnotationHistoryLength])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// pushHistory records manDesc as the latest Git manifest in a new history
+// index referring to the tagged manifest. Throws errTagMoved if the history
+// changed since it was fetched, or another client's history index is followed
+// instead of the one pushed, or [ErrConcurrentUpdate] if atomic.
+func (m *model) pushHistory(ctx context.Context, manDesc ocispec.Descriptor, atomic bool) error {
+	moved := errTagMoved
+	if atomic {
+		moved = ErrConcurrentUpdate
+	}
+
+	latest, err := m.latestHistory(ctx)
+	if err != nil {
+		return err
+	}
+	if latest.Digest != m.historyDesc.Digest {
+		return fmt.Errorf("%w: history of tag %s has %d Git manifests, expected %d", moved, m.ref, historyLength(latest), len(m.history))
+	}
+
+	history := append(slices.Clone(m.history), plainDescriptor(manDesc))
+	idx := ocispec.Index{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: oci.ArtifactTypeGitHistory,
+		Manifests:    history,
+		Subject:      &m.root,
+		Annotations: map[string]string{
+			oci.AnnotationHistoryLength: strconv.Itoa(len(history)),
+		},
+	}
+	idxRaw, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding history index: %w", err)
+	}
+	idxDesc, err := oras.PushBytes(ctx, existingPusher{m.gt}, ocispec.MediaTypeImageIndex, idxRaw)
+	if err != nil {
+		return fmt.Errorf("pushing history index: %w", err)
+	}
+
+	// a history index pushed concurrently may be followed instead, unless it
+	// builds on this one
+	latest, err = m.latestHistory(ctx)
+	if err != nil {
+		return err
+	}
+	if latest.Digest != idxDesc.Digest {
+		followed, err := m.fetchHistory(ctx, latest)
+		if err != nil {
+			return err
+		}
+		if !containsDigest(followed, manDesc.Digest) {
+			return fmt.Errorf("%w: history of tag %s follows %s rather than %s", moved, m.ref, latest.Digest, idxDesc.Digest)
+		}
+	}
+	m.history = history
+	m.historyDesc = idxDesc
+
+	return nil
+}
+
+// updateTag points the remote tag at manDesc, or records it in the history of
+// the tagged manifest if the tag is never moved.
+func (m *model) updateTag(ctx context.Context, manDesc ocispec.Descriptor, atomic bool) error {
+	switch {
+	case m.tagHistory && m.root.Digest != "":
+		return m.pushHistory(ctx, manDesc, atomic)
+	case atomic:
+		if err := m.swapTag(ctx, manDesc); err != nil {
+			return err
+		}
+	default:
+		if err := m.tagUnchanged(ctx, manDesc); err != nil {
+			return err
+		}
+	}
+
+	if m.tagHistory {
+		// the first push, later pushes are recorded in its history
+		m.root = plainDescriptor(manDesc)
+		m.history = []ocispec.Descriptor{m.root}
+		m.historyDesc = ocispec.Descriptor{}
+	}
+	return nil
+}
+
+// plainDescriptor returns desc without optional fields, as referenced by
+// history indexes.
+func plainDescriptor(desc ocispec.Descriptor) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	}
+}
+
+// ManifestStats describes a Git manifest pushed to the remote.
+type ManifestStats struct {
+	// Digest is the digest of the Git manifest.
+	Digest digest.Digest `json:"digest"`
+	// Created is the creation time annotation of the Git manifest, RFC 3339
+	// formatted, if any.
+	Created string `json:"created,omitempty"`
+	// Layers is the number of packfile layers of the Git manifest.
+	Layers int `json:"layers"`
+	// Size is the total size of the packfile layers in bytes.
+	Size int64 `json:"size"`
+}
+
+func (m *model) History(ctx context.Context, n int) (_ []ManifestStats, err error) {
+	ctx, span := tracing.Start(ctx, "model.History", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
+	if m.manDesc.Digest == "" {
+		return nil, errManifestNotPushed
+	}
+
+	history := []ocispec.Descriptor{m.manDesc}
+	if len(m.history) > 0 {
+		history = m.history
+	}
+	history = history[max(len(history)-n, 0):]
+
+	stats := make([]ManifestStats, 0, len(history))
+	for _, desc := range history {
+		man := m.man
+		if desc.Digest != m.manDesc.Digest {
+			manRaw, err := content.FetchAll(ctx, m.gt, desc)
+			if err != nil {
+				return nil, fmt.Errorf("fetching Git manifest %s: %w", desc.Digest, err)
+			}
+			man = ocispec.Manifest{}
+			if err := json.Unmarshal(manRaw, &man); err != nil {
+				return nil, fmt.Errorf("decoding Git manifest %s: %w", desc.Digest, err)
+			}
+		}
+
+		s := ManifestStats{
+			Digest:  desc.Digest,
+			Created: man.Annotations[ocispec.AnnotationCreated],
+			Layers:  len(man.Layers),
+		}
+		for _, layer := range man.Layers {
+			s.Size += layer.Size
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
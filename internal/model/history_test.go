@@ -0,0 +1,146 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// immutableTagTarget rejects moving existing tags.
+type immutableTagTarget struct {
+	oras.GraphTarget
+}
+
+func (i *immutableTagTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	existing, err := i.Resolve(ctx, reference)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+	case err != nil:
+		return err //nolint:wrapcheck
+	case existing.Digest != desc.Digest:
+		return errors.New("tag is immutable")
+	}
+	return i.GraphTarget.Tag(ctx, desc, reference) //nolint:wrapcheck
+}
+
+func Test_model_tagHistory(t *testing.T) {
+	gt := &immutableTagTarget{GraphTarget: memory.New()}
+	setupRemote(t, gt)
+	root, err := gt.Resolve(t.Context(), testRemote.String())
+	assert.NoError(t, err)
+
+	newModel := func(t *testing.T, opts ...Option) *model {
+		t.Helper()
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, fstore.Close())
+		})
+
+		m := NewModeler(testRemote, fstore, gt, opts...).(*model)
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+		return m
+	}
+
+	// pushes an update of a new tag
+	push := func(t *testing.T, m *model, tag string, atomic bool) (ocispec.Descriptor, error) {
+		t.Helper()
+
+		err := m.UpdateRef(t.Context(), plumbing.NewHashReference(plumbing.NewTagReferenceName(tag), plumbing.ZeroHash), m.man.Layers[0].Digest)
+		assert.NoError(t, err)
+		if atomic {
+			return m.PushAtomic(t.Context())
+		}
+		return m.Push(t.Context())
+	}
+
+	t.Run("Immutable Tag", func(t *testing.T) {
+		_, err := push(t, newModel(t), "rejected", false)
+		assert.ErrorContains(t, err, "tag is immutable")
+	})
+
+	first, err := push(t, newModel(t, WithTagHistory()), "first", false)
+	assert.NoError(t, err)
+	second, err := push(t, newModel(t, WithTagHistory()), "second", false)
+	assert.NoError(t, err)
+
+	// the tag is never moved
+	got, err := gt.Resolve(t.Context(), testRemote.String())
+	assert.NoError(t, err)
+	assert.Equal(t, root.Digest, got.Digest)
+
+	t.Run("Fetch Latest", func(t *testing.T) {
+		m := newModel(t, WithTagHistory())
+		assert.Equal(t, second.Digest, m.manDesc.Digest)
+		assert.Contains(t, m.TagRefs(), plumbing.NewTagReferenceName("first"))
+		assert.Contains(t, m.TagRefs(), plumbing.NewTagReferenceName("second"))
+		assert.Equal(t, []ocispec.Descriptor{plainDescriptor(root), plainDescriptor(first), plainDescriptor(second)}, m.history)
+	})
+
+	t.Run("Fetch Without History", func(t *testing.T) {
+		m := newModel(t)
+		assert.Equal(t, root.Digest, m.manDesc.Digest)
+	})
+
+	t.Run("History", func(t *testing.T) {
+		m := newModel(t, WithTagHistory())
+		size := m.man.Layers[0].Size
+
+		got, err := m.History(t.Context(), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []ManifestStats{
+			{Digest: root.Digest, Created: "1970-01-01T00:00:00Z", Layers: 1, Size: size},
+			{Digest: first.Digest, Created: "1970-01-01T00:00:00Z", Layers: 1, Size: size},
+			{Digest: second.Digest, Created: "1970-01-01T00:00:00Z", Layers: 1, Size: size},
+		}, got)
+
+		got, err = m.History(t.Context(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []digest.Digest{first.Digest, second.Digest}, []digest.Digest{got[0].Digest, got[1].Digest})
+	})
+
+	t.Run("History Without Tag History", func(t *testing.T) {
+		got, err := newModel(t).History(t.Context(), 10)
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, root.Digest, got[0].Digest)
+	})
+
+	t.Run("Concurrent Update", func(t *testing.T) {
+		m := newModel(t, WithTagHistory())
+		other := newModel(t, WithTagHistory())
+
+		_, err := push(t, other, "other", true)
+		assert.NoError(t, err)
+
+		_, err = push(t, m, "mine", true)
+		assert.ErrorIs(t, err, ErrConcurrentUpdate)
+	})
+
+	t.Run("Concurrent Update Rebased", func(t *testing.T) {
+		m := newModel(t, WithTagHistory())
+		other := newModel(t, WithTagHistory())
+
+		_, err := push(t, other, "rebased-other", false)
+		assert.NoError(t, err)
+
+		_, err = push(t, m, "rebased-mine", false)
+		assert.NoError(t, err)
+
+		// neither update is lost
+		got := newModel(t, WithTagHistory())
+		assert.Contains(t, got.TagRefs(), plumbing.NewTagReferenceName("rebased-other"))
+		assert.Contains(t, got.TagRefs(), plumbing.NewTagReferenceName("rebased-mine"))
+	})
+}
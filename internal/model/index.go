@@ -0,0 +1,265 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// fetchIndex fetches the image index tagged idxTag, or initializes an empty
+// index of artifactType if none exists.
+func (m *model) fetchIndex(ctx context.Context, idxTag, artifactType string) (ocispec.Index, error) {
+	idx := ocispec.Index{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: artifactType,
+	}
+	idxDesc, err := m.gt.Resolve(ctx, idxTag)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		slog.DebugContext(ctx, "index not found, initializing", slog.String("tag", idxTag))
+		return idx, nil
+	case err != nil:
+		return ocispec.Index{}, fmt.Errorf("resolving index: %w", err)
+	}
+
+	idxRaw, err := content.FetchAll(ctx, m.gt, idxDesc)
+	if err != nil {
+		return ocispec.Index{}, fmt.Errorf("fetching index: %w", err)
+	}
+	if err := json.Unmarshal(idxRaw, &idx); err != nil {
+		return ocispec.Index{}, fmt.Errorf("decoding index: %w", err)
+	}
+
+	return idx, nil
+}
+
+// recordInIndex records desc in the image index tagged idxTag, replacing any
+// entry with the same org.opencontainers.image.ref.name annotation.
+func (m *model) recordInIndex(ctx context.Context, idxTag, artifactType string, desc ocispec.Descriptor) error {
+	idx, err := m.fetchIndex(ctx, idxTag, artifactType)
+	if err != nil {
+		return err
+	}
+
+	name := desc.Annotations[ocispec.AnnotationRefName]
+	manifests := make([]ocispec.Descriptor, 0, len(idx.Manifests)+1)
+	for _, d := range idx.Manifests {
+		if d.Annotations[ocispec.AnnotationRefName] != name {
+			manifests = append(manifests, d)
+		}
+	}
+	idx.Manifests = append(manifests, desc)
+
+	idxRaw, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	idxDesc, err := oras.PushBytes(ctx, existingPusher{m.gt}, ocispec.MediaTypeImageIndex, idxRaw)
+	if err != nil {
+		return fmt.Errorf("pushing index: %w", err)
+	}
+	if err := m.gt.Tag(ctx, idxDesc, idxTag); err != nil {
+		return fmt.Errorf("tagging index: %w", err)
+	}
+
+	return nil
+}
+
+// retainedDigests returns the digests of the Git and LFS manifests recorded
+// in the snapshot and release indexes, and in the history of the tag, along
+// with those of their layers and of the LFS manifests referring to them,
+// which remain fetchable such that none may be deleted. The content of the other Git manifests tagged in the repository,
+// e.g. other projects, is retained as well, see [model.retainOtherTags].
+func (m *model) retainedDigests(ctx context.Context) (map[digest.Digest]struct{}, error) {
+	roots := slices.Clone(m.history)
+	for _, idx := range []struct{ tag, artifactType string }{
+		{m.snapshotIndexTag(), oci.ArtifactTypeGitSnapshots},
+		{m.releaseIndexTag(), oci.ArtifactTypeGitReleases},
+	} {
+		idx, err := m.fetchIndex(ctx, idx.tag, idx.artifactType)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, idx.Manifests...)
+	}
+
+	retained := make(map[digest.Digest]struct{})
+	for _, desc := range roots {
+		if err := m.retainManifest(ctx, m.gt, desc, retained); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.retainOtherTags(ctx, retained); err != nil {
+		return nil, err
+	}
+
+	return retained, nil
+}
+
+// retainManifest adds the digests of the manifest desc in store, its config,
+// and its layers to retained. Image indexes, e.g. releases, are walked, as are
+// the LFS manifests referring to Git manifests.
+func (m *model) retainManifest(ctx context.Context, store Store, desc ocispec.Descriptor, retained map[digest.Digest]struct{}) error {
+	if _, ok := retained[desc.Digest]; ok {
+		return nil
+	}
+	retained[desc.Digest] = struct{}{}
+
+	raw, err := content.FetchAll(ctx, store, desc)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		slog.DebugContext(ctx, "retained manifest not found", slog.String("digest", desc.Digest.String()))
+		return nil
+	case err != nil:
+		return fmt.Errorf("fetching manifest %s: %w", desc.Digest, err)
+	}
+
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		// a release, of a Git manifest and its LFS manifest
+		var idx ocispec.Index
+		if err := json.Unmarshal(raw, &idx); err != nil {
+			return fmt.Errorf("decoding index %s: %w", desc.Digest, err)
+		}
+		for _, d := range idx.Manifests {
+			if err := m.retainManifest(ctx, store, d, retained); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var man ocispec.Manifest
+	if err := json.Unmarshal(raw, &man); err != nil {
+		return fmt.Errorf("decoding manifest %s: %w", desc.Digest, err)
+	}
+	retained[man.Config.Digest] = struct{}{}
+	for _, layer := range man.Layers {
+		retained[layer.Digest] = struct{}{}
+	}
+	if man.ArtifactType != oci.ArtifactTypeGitManifest {
+		return nil
+	}
+
+	// the LFS manifest of a snapshot or tag history is not listed by an index
+	lfsReferrers, err := m.lfsReferrers(ctx, desc)
+	if err != nil {
+		return err
+	}
+	for _, d := range lfsReferrers {
+		if err := m.retainManifest(ctx, m.lfsStore(), d, retained); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errTagsUnsupported indicates the remote does not support listing tags, such
+// that content shared with other tags cannot be found.
+var errTagsUnsupported = fmt.Errorf("%w: remote does not support listing tags", errdef.ErrUnsupported)
+
+// retainOtherTags adds the digests of the content of the Git manifests and
+// releases tagged in the repository, other than those of the tag, its
+// snapshots, and its releases, to retained. Their content, including their
+// LFS referrers, is shared with the tag if pushed from the same repository,
+// e.g. another project of a monorepo. Returns [errTagsUnsupported] if the
+// remote cannot list tags.
+func (m *model) retainOtherTags(ctx context.Context, retained map[digest.Digest]struct{}) error {
+	lister, ok := tagLister(m.gt)
+	if !ok {
+		return errTagsUnsupported
+	}
+
+	own := map[string]struct{}{
+		m.ref.Reference:                       {},
+		m.ref.Reference + snapshotIndexSuffix: {},
+		m.ref.Reference + releaseIndexSuffix:  {},
+	}
+	for _, idx := range []struct{ tag, artifactType string }{
+		{m.snapshotIndexTag(), oci.ArtifactTypeGitSnapshots},
+		{m.releaseIndexTag(), oci.ArtifactTypeGitReleases},
+	} {
+		idx, err := m.fetchIndex(ctx, idx.tag, idx.artifactType)
+		if err != nil {
+			return err
+		}
+		for _, desc := range idx.Manifests {
+			own[desc.Annotations[ocispec.AnnotationRefName]] = struct{}{}
+		}
+	}
+
+	var tags []string
+	if err := lister.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("listing tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if _, ok := own[tag]; ok {
+			continue
+		}
+		desc, ok, err := m.resolveGitTag(ctx, tag)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		slog.DebugContext(ctx, "retaining content of other tag", slog.String("tag", tag), slog.String("digest", desc.Digest.String()))
+		if err := m.retainManifest(ctx, m.gt, desc, retained); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveGitTag resolves tag, returning false if it refers to neither a Git
+// manifest nor a release.
+func (m *model) resolveGitTag(ctx context.Context, tag string) (ocispec.Descriptor, bool, error) {
+	ref := m.ref
+	ref.Reference = tag
+	desc, err := m.gt.Resolve(ctx, ref.String())
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		// deleted since listed
+		return ocispec.Descriptor{}, false, nil
+	case err != nil:
+		return ocispec.Descriptor{}, false, fmt.Errorf("resolving tag %s: %w", tag, err)
+	}
+	if desc.MediaType != ocispec.MediaTypeImageManifest && desc.MediaType != ocispec.MediaTypeImageIndex {
+		return desc, false, nil
+	}
+
+	raw, err := content.FetchAll(ctx, m.gt, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, false, fmt.Errorf("fetching tag %s: %w", tag, err)
+	}
+	var artifact struct {
+		ArtifactType string `json:"artifactType"`
+	}
+	if err := json.Unmarshal(raw, &artifact); err != nil {
+		return ocispec.Descriptor{}, false, fmt.Errorf("decoding tag %s: %w", tag, err)
+	}
+
+	switch artifact.ArtifactType {
+	case oci.ArtifactTypeGitManifest, oci.ArtifactTypeGitRelease:
+		return desc, true, nil
+	default:
+		return desc, false, nil
+	}
+}
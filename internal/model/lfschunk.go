@@ -0,0 +1,369 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/internal/fastcdc"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// lfsChunkingMinSize is the size at or above which LFS files are chunked.
+// Smaller files would be few chunks, sharing little with other versions.
+const lfsChunkingMinSize = fastcdc.DefaultMaxSize
+
+// errInvalidLFSRecipe indicates an LFS recipe does not describe its LFS file.
+var errInvalidLFSRecipe = errors.New("invalid LFS recipe")
+
+// WithLFSChunking splits pushed LFS files of at least 8 MiB into
+// content-defined chunks with FastCDC. Each chunk is pushed as a layer of the
+// LFS manifest, unless already pushed, along with a recipe layer listing the
+// chunks of the file, such that versions of a file differing slightly share
+// most chunks. Encrypted LFS files are not chunked, as encryption defeats
+// deduplication.
+func WithLFSChunking() Option {
+	return func(m *model) {
+		m.lfsChunking = true
+	}
+}
+
+// isLFSRecipe returns true if desc is an LFS recipe layer.
+func isLFSRecipe(desc ocispec.Descriptor) bool {
+	return desc.MediaType == oci.MediaTypeLFSRecipe
+}
+
+// isLFSChunk returns true if desc is an LFS chunk layer.
+func isLFSChunk(desc ocispec.Descriptor) bool {
+	return desc.MediaType == oci.MediaTypeLFSChunk
+}
+
+// chunksLFSFile returns true if the LFS file at path is to be pushed as
+// content-defined chunks.
+func (m *model) chunksLFSFile(path string) (bool, error) {
+	if !m.lfsChunking || len(m.encryptionKeys) > 0 {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("resolving size of LFS file: %w", err)
+	}
+	return info.Size() >= lfsChunkingMinSize, nil
+}
+
+// pushLFSChunks pushes the LFS file at path as content-defined chunks and a
+// recipe listing them, adding each as a layer of the LFS manifest. Chunks
+// already pushed, by this or any other LFS file, are not uploaded again.
+func (m *model) pushLFSChunks(ctx context.Context, path string, opts *PushLFSOptions) (ocispec.Descriptor, error) {
+	oid, err := lfsOid(path, opts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("opening LFS file: %w", err)
+	}
+	var rc io.ReadCloser = f
+	if opts != nil {
+		rc = progressOrDefault(opts.Progress, rc)
+	}
+	defer rc.Close()
+
+	chunker, err := fastcdc.NewChunker(rc, fastcdc.Options{})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("initializing LFS file chunker: %w", err)
+	}
+
+	alg := m.digestAlg
+	if alg == "" {
+		alg = digest.Canonical
+	}
+
+	var recipe oci.LFSRecipe
+	var uploaded, reused int
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("chunking LFS file: %w", err)
+		}
+
+		desc := ocispec.Descriptor{
+			MediaType: oci.MediaTypeLFSChunk,
+			Digest:    alg.FromBytes(chunk),
+			Size:      int64(len(chunk)),
+		}
+		pushed, err := m.pushLFSBlob(ctx, desc, chunk)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("pushing LFS chunk %s: %w", desc.Digest, err)
+		}
+		if pushed {
+			uploaded++
+		} else {
+			reused++
+		}
+
+		recipe.Chunks = append(recipe.Chunks, oci.LFSChunk{Digest: desc.Digest, Size: desc.Size})
+		recipe.Size += desc.Size
+	}
+	slog.DebugContext(ctx, "chunked LFS file", slog.String("oid", oid.String()), slog.Int("uploaded", uploaded), slog.Int("reused", reused))
+
+	recipeRaw, err := json.Marshal(recipe)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("encoding LFS recipe: %w", err)
+	}
+	recipeDesc := ocispec.Descriptor{
+		MediaType: oci.MediaTypeLFSRecipe,
+		Digest:    alg.FromBytes(recipeRaw),
+		Size:      int64(len(recipeRaw)),
+		Annotations: map[string]string{
+			ocispec.AnnotationTitle: filepath.Base(path),
+			oci.AnnotationLFSOid:    oid.String(),
+			oci.AnnotationLFSSize:   strconv.FormatInt(recipe.Size, 10),
+		},
+	}
+	if _, err := m.pushLFSBlob(ctx, recipeDesc, recipeRaw); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("pushing LFS recipe: %w", err)
+	}
+	if uploaded > 0 {
+		m.observe(func(o Observer) { o.OnLFSUploaded(ctx, recipeDesc) })
+	}
+
+	return recipeDesc, nil
+}
+
+// pushLFSBlob pushes content to the LFS store, unless it is a layer of the LFS
+// manifest or exists in the remote, then adds it as a layer. Returns true if
+// content was uploaded.
+func (m *model) pushLFSBlob(ctx context.Context, desc ocispec.Descriptor, b []byte) (bool, error) {
+	m.lfsMu.Lock()
+	known := containsDigest(m.lfsMan.Layers, desc.Digest)
+	m.lfsMu.Unlock()
+	if known {
+		return false, nil
+	}
+
+	exists, err := m.lfsStore().Exists(ctx, desc)
+	if err != nil {
+		return false, fmt.Errorf("checking existence: %w", err)
+	}
+	var pushed bool
+	if !exists {
+		err := m.lfsStore().Push(ctx, desc, bytes.NewReader(b))
+		if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			return false, err //nolint:wrapcheck
+		}
+		pushed = err == nil
+	}
+
+	m.addLFSLayer(desc)
+	return pushed, nil
+}
+
+// fetchLFSRecipe fetches and decodes the recipe of an LFS recipe layer.
+func (m *model) fetchLFSRecipe(ctx context.Context, desc ocispec.Descriptor) (oci.LFSRecipe, error) {
+	rc, err := m.fetchBlob(ctx, m.lfsStore(), desc)
+	if err != nil {
+		return oci.LFSRecipe{}, fmt.Errorf("fetching LFS recipe: %w", err)
+	}
+	defer rc.Close()
+
+	recipeRaw, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return oci.LFSRecipe{}, fmt.Errorf("reading LFS recipe: %w", err)
+	}
+	var recipe oci.LFSRecipe
+	if err := json.Unmarshal(recipeRaw, &recipe); err != nil {
+		return oci.LFSRecipe{}, fmt.Errorf("decoding LFS recipe: %w", err)
+	}
+
+	var size int64
+	for _, chunk := range recipe.Chunks {
+		size += chunk.Size
+	}
+	if size != recipe.Size || size != lfsObjectOf(desc).Size {
+		return oci.LFSRecipe{}, fmt.Errorf("%w: chunks of %s total %d bytes, expected %d", errInvalidLFSRecipe, desc.Digest, size, lfsObjectOf(desc).Size)
+	}
+
+	return recipe, nil
+}
+
+// fetchLFSChunks returns the LFS file of an LFS recipe layer, fetching each
+// chunk as it is read.
+func (m *model) fetchLFSChunks(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	recipe, err := m.fetchLFSRecipe(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	return &lfsChunkReader{ctx: ctx, m: m, chunks: recipe.Chunks}, nil
+}
+
+// withLFSChunks returns descs followed by the chunk layers of its LFS recipe
+// layers, omitting duplicates.
+func (m *model) withLFSChunks(ctx context.Context, descs []ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	all := slices.Clone(descs)
+	for _, desc := range descs {
+		if !isLFSRecipe(desc) {
+			continue
+		}
+		recipe, err := m.fetchLFSRecipe(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range recipe.Chunks {
+			if !containsDigest(all, chunk.Digest) {
+				all = append(all, lfsChunkDescriptor(chunk))
+			}
+		}
+	}
+	return all, nil
+}
+
+// referencedLFSChunks returns the digests of the chunks listed by the LFS
+// recipe layers of the LFS files of oids.
+func (m *model) referencedLFSChunks(ctx context.Context, oids map[string]struct{}) (map[digest.Digest]struct{}, error) {
+	m.lfsMu.Lock()
+	layers := slices.Clone(m.lfsMan.Layers)
+	m.lfsMu.Unlock()
+
+	chunks := make(map[digest.Digest]struct{})
+	for _, desc := range layers {
+		if !isLFSRecipe(desc) {
+			continue
+		}
+		if _, ok := oids[lfsObjectDigest(desc).Encoded()]; !ok {
+			continue
+		}
+		recipe, err := m.fetchLFSRecipe(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range recipe.Chunks {
+			chunks[chunk.Digest] = struct{}{}
+		}
+	}
+	return chunks, nil
+}
+
+// lfsChunkDescriptor returns the layer descriptor of a chunk.
+func lfsChunkDescriptor(chunk oci.LFSChunk) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType: oci.MediaTypeLFSChunk,
+		Digest:    chunk.Digest,
+		Size:      chunk.Size,
+	}
+}
+
+// lfsChunkReader reads the concatenation of chunks, fetching each chunk as it
+// is reached.
+type lfsChunkReader struct {
+	ctx    context.Context
+	m      *model
+	chunks []oci.LFSChunk
+
+	next int   // index of the next chunk to fetch
+	skip int64 // bytes of the next chunk to discard, if seeking
+	cur  io.ReadCloser
+}
+
+// Read implements io.Reader.
+func (r *lfsChunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if r.next >= len(r.chunks) {
+				return 0, io.EOF
+			}
+			if err := r.open(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := r.cur.Read(p)
+		if !errors.Is(err, io.EOF) {
+			return n, err //nolint:wrapcheck
+		}
+		if err := r.closeChunk(); err != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// open fetches the next chunk, discarding the bytes skipped by seeking.
+func (r *lfsChunkReader) open() error {
+	chunk := r.chunks[r.next]
+	rc, err := r.m.fetchBlob(r.ctx, r.m.lfsStore(), lfsChunkDescriptor(chunk))
+	if err != nil {
+		return fmt.Errorf("fetching LFS chunk %s: %w", chunk.Digest, err)
+	}
+	if r.skip > 0 {
+		if err := resumeAt(r.ctx, rc, r.skip); err != nil {
+			rc.Close()
+			return fmt.Errorf("resuming LFS chunk %s at offset %d: %w", chunk.Digest, r.skip, err)
+		}
+	}
+
+	r.cur = rc
+	r.next++
+	r.skip = 0
+	return nil
+}
+
+// closeChunk closes the chunk being read, if any.
+func (r *lfsChunkReader) closeChunk() error {
+	if r.cur == nil {
+		return nil
+	}
+	err := r.cur.Close()
+	r.cur = nil
+	if err != nil {
+		return fmt.Errorf("closing LFS chunk: %w", err)
+	}
+	return nil
+}
+
+// Seek implements io.Seeker, relative to the start only, such that resumed
+// downloads only fetch the chunks from offset onward.
+func (r *lfsChunkReader) Seek(offset int64, whence int) (int64, error) {
+	if whence != io.SeekStart || offset < 0 {
+		return 0, errors.New("unsupported seek of chunked LFS file")
+	}
+	if err := r.closeChunk(); err != nil {
+		return 0, err
+	}
+
+	r.next, r.skip = len(r.chunks), 0
+	var start int64
+	for i, chunk := range r.chunks {
+		if offset < start+chunk.Size {
+			r.next, r.skip = i, offset-start
+			break
+		}
+		start += chunk.Size
+	}
+	return offset, nil
+}
+
+// Close implements io.Closer.
+func (r *lfsChunkReader) Close() error {
+	return r.closeChunk()
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// writeLFSFile writes an LFS file named by its OID to dir, returning its path.
+func writeLFSFile(t *testing.T, dir string, b []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, digest.FromBytes(b).Encoded())
+	assert.NoError(t, os.WriteFile(path, b, 0o600))
+	return path
+}
+
+func Test_model_pushLFSChunks(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 1))
+	v1 := make([]byte, 3*lfsChunkingMinSize/2)
+	for i := range v1 {
+		v1[i] = byte(r.Uint32())
+	}
+	// a slightly changed second version of the file
+	v2 := append(bytes.Clone(v1[:1<<20]), append([]byte("edited"), v1[1<<20:]...)...)
+
+	gt := memory.New()
+	m := &model{ref: testRemote, gt: gt}
+	WithLFSChunking()(m)
+
+	dir := t.TempDir()
+	desc1, err := m.PushLFSFile(t.Context(), writeLFSFile(t, dir, v1), &PushLFSOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, oci.MediaTypeLFSRecipe, desc1.MediaType)
+	chunks1 := len(m.lfsMan.Layers) - 1
+
+	desc2, err := m.PushLFSFile(t.Context(), writeLFSFile(t, dir, v2), &PushLFSOptions{})
+	assert.NoError(t, err)
+	chunks2 := len(m.lfsMan.Layers) - chunks1 - 2
+
+	// only the chunks around the edit are new
+	assert.Greater(t, chunks1, 2)
+	assert.LessOrEqual(t, chunks2, 2)
+
+	t.Run("List", func(t *testing.T) {
+		objs := m.ListLFSObjects()
+		assert.Equal(t, []LFSObject{
+			{Oid: digest.FromBytes(v1).Encoded(), Size: int64(len(v1)), Title: digest.FromBytes(v1).Encoded(), Digest: desc1.Digest, Chunked: true},
+			{Oid: digest.FromBytes(v2).Encoded(), Size: int64(len(v2)), Title: digest.FromBytes(v2).Encoded(), Digest: desc2.Digest, Chunked: true},
+		}, objs)
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		layers := len(m.lfsMan.Layers)
+		desc, err := m.PushLFSFile(t.Context(), writeLFSFile(t, dir, v2), &PushLFSOptions{Oid: digest.FromBytes(v2).Encoded(), Size: int64(len(v2))})
+		assert.NoError(t, err)
+		assert.Equal(t, desc2, desc)
+		assert.Len(t, m.lfsMan.Layers, layers)
+	})
+
+	t.Run("Fetch", func(t *testing.T) {
+		rc, err := m.FetchLFSLayer(t.Context(), digest.FromBytes(v2), &FetchLFSOptions{})
+		assert.NoError(t, err)
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.Equal(t, v2, got)
+	})
+
+	t.Run("Fetch Offset", func(t *testing.T) {
+		offset := int64(len(v1) - 100)
+		rc, err := m.FetchLFSLayer(t.Context(), digest.FromBytes(v1), &FetchLFSOptions{Offset: offset})
+		assert.NoError(t, err)
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.Equal(t, v1[offset:], got)
+	})
+
+	t.Run("Referenced Chunks", func(t *testing.T) {
+		chunks, err := m.referencedLFSChunks(t.Context(), map[string]struct{}{digest.FromBytes(v1).Encoded(): {}})
+		assert.NoError(t, err)
+		assert.Len(t, chunks, chunks1)
+	})
+
+	t.Run("Invalid Recipe", func(t *testing.T) {
+		desc := desc1
+		desc.Annotations = map[string]string{oci.AnnotationLFSSize: "1"}
+		_, err := m.fetchLFSRecipe(t.Context(), desc)
+		assert.ErrorIs(t, err, errInvalidLFSRecipe)
+	})
+}
+
+func Test_model_chunksLFSFile(t *testing.T) {
+	dir := t.TempDir()
+	small := writeLFSFile(t, dir, []byte("small"))
+	large := writeLFSFile(t, dir, make([]byte, lfsChunkingMinSize))
+
+	m := &model{}
+	chunked, err := m.chunksLFSFile(large)
+	assert.NoError(t, err)
+	assert.False(t, chunked)
+
+	WithLFSChunking()(m)
+	chunked, err = m.chunksLFSFile(small)
+	assert.NoError(t, err)
+	assert.False(t, chunked)
+
+	chunked, err = m.chunksLFSFile(large)
+	assert.NoError(t, err)
+	assert.True(t, chunked)
+
+	// encryption defeats deduplication
+	m.encryptionKeys = []EncryptionKey{{ID: "test", Key: make([]byte, 32)}}
+	chunked, err = m.chunksLFSFile(large)
+	assert.NoError(t, err)
+	assert.False(t, chunked)
+}
+
+func Test_lfsChunkReader(t *testing.T) {
+	gt := memory.New()
+	m := &model{gt: gt}
+
+	var chunks []oci.LFSChunk
+	for _, b := range [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")} {
+		desc := content.NewDescriptorFromBytes(oci.MediaTypeLFSChunk, b)
+		assert.NoError(t, gt.Push(t.Context(), desc, bytes.NewReader(b)))
+		chunks = append(chunks, oci.LFSChunk{Digest: desc.Digest, Size: desc.Size})
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   string
+	}{
+		{name: "Start", want: "foobarbaz"},
+		{name: "Chunk Boundary", offset: 3, want: "barbaz"},
+		{name: "Within Chunk", offset: 4, want: "arbaz"},
+		{name: "End", offset: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &lfsChunkReader{ctx: t.Context(), m: m, chunks: chunks}
+			_, err := r.Seek(tt.offset, io.SeekStart)
+			assert.NoError(t, err)
+
+			got, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.NoError(t, r.Close())
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
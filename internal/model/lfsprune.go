@@ -0,0 +1,238 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/internal/tracing"
+)
+
+// lfsPointerMaxSize is the size above which blobs are not considered LFS
+// pointer files, matching git-lfs.
+const lfsPointerMaxSize = 1024
+
+// lfsPointerVersions are the spec versions of LFS pointer files recognized by
+// git-lfs.
+var lfsPointerVersions = []string{
+	"https://git-lfs.github.com/spec/v1",
+	"https://hawser.github.com/spec/v1",
+}
+
+// PruneLFSOptions define optional parameters for pruning LFS files.
+type PruneLFSOptions struct {
+	// DeleteLayers deletes the layers of pruned LFS files from the remote, if
+	// supported, rather than leaving them to the registry's garbage
+	// collection. LFS files uploaded concurrently, before the pointer files
+	// referring to them are pushed, may be deleted.
+	DeleteLayers bool
+}
+
+func (m *model) PruneLFS(ctx context.Context, opts PruneLFSOptions) (_ []LFSObject, err error) {
+	ctx, span := tracing.Start(ctx, "model.PruneLFS", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
+	switch {
+	case len(m.lfsMan.Layers) == 0:
+		slog.InfoContext(ctx, "no LFS layers to prune")
+		return nil, nil
+	case len(m.cfg.Shallow) > 0:
+		// pointers in truncated history cannot be found
+		return nil, errors.New("pruning LFS files of a remote with truncated history is not supported")
+	}
+
+	oids, err := m.lfsPointerOids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := m.referencedLFSChunks(ctx, oids)
+	if err != nil {
+		return nil, err
+	}
+
+	m.lfsMu.Lock()
+	var kept, pruned []ocispec.Descriptor
+	for _, desc := range m.lfsMan.Layers {
+		// the placeholder layer of an LFS manifest without LFS files is kept,
+		// as are layers holding chunks of kept LFS files
+		_, referenced := oids[lfsObjectDigest(desc).Encoded()]
+		_, chunk := chunks[desc.Digest]
+		if (referenced && !isLFSChunk(desc)) || chunk || desc.MediaType == ocispec.MediaTypeEmptyJSON {
+			kept = append(kept, desc)
+			continue
+		}
+		pruned = append(pruned, desc)
+	}
+	m.lfsMan.Layers = kept
+	if m.lfsPruned == nil {
+		m.lfsPruned = make(map[digest.Digest]struct{}, len(pruned))
+	}
+	for _, desc := range pruned {
+		m.lfsPruned[desc.Digest] = struct{}{}
+	}
+	m.lfsMu.Unlock()
+
+	if len(pruned) == 0 {
+		slog.InfoContext(ctx, "all LFS files are referenced")
+		return nil, nil
+	}
+
+	if _, err := m.PushLFSManifest(ctx, m.manDesc); err != nil {
+		return nil, err
+	}
+	if opts.DeleteLayers {
+		m.deleteLFSLayers(ctx, pruned)
+	}
+
+	objs := make([]LFSObject, 0, len(pruned))
+	for _, desc := range pruned {
+		if isLFSChunk(desc) {
+			continue
+		}
+		slog.InfoContext(ctx, "pruned unreferenced LFS file", slog.String("digest", desc.Digest.String()))
+		objs = append(objs, lfsObjectOf(desc))
+	}
+
+	return objs, nil
+}
+
+// lfsPointerOids returns the object IDs of the LFS pointer files reachable from
+// the current references. Pointer files are recognized by their content, as
+// git-lfs does, such that pointers committed without a matching
+// .gitattributes pattern are retained.
+func (m *model) lfsPointerOids(ctx context.Context) (map[string]struct{}, error) {
+	oids := make(map[string]struct{})
+	if len(m.man.Layers) == 0 {
+		return oids, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gnoci-lfs-prune-*")
+	if err != nil {
+		return nil, fmt.Errorf("initializing temp directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.ErrorContext(ctx, "removing temporary git repository", slog.String("error", err.Error()))
+		}
+	}()
+
+	repo, err := m.unpackLayers(ctx, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := revlist.Objects(repo.Storer, m.packedTips(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("resolving reachable objects: %w", err)
+	}
+	for _, h := range objs {
+		oid, ok, err := lfsPointerOid(repo.Storer, h)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			oids[oid] = struct{}{}
+		}
+	}
+	slog.DebugContext(ctx, "found reachable LFS pointer files", slog.Int("count", len(oids)))
+
+	return oids, nil
+}
+
+// lfsPointerOid returns the LFS object ID of object h, if it is an LFS pointer
+// file.
+func lfsPointerOid(st storer.EncodedObjectStorer, h plumbing.Hash) (string, bool, error) {
+	obj, err := st.EncodedObject(plumbing.AnyObject, h)
+	if err != nil {
+		return "", false, fmt.Errorf("resolving object %s: %w", h, err)
+	}
+	if obj.Type() != plumbing.BlobObject || obj.Size() > lfsPointerMaxSize {
+		return "", false, nil
+	}
+
+	r, err := obj.Reader()
+	if err != nil {
+		return "", false, fmt.Errorf("reading blob %s: %w", h, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", false, fmt.Errorf("reading blob %s: %w", h, err)
+	}
+
+	oid, ok := parseLFSPointer(data)
+	return oid, ok, nil
+}
+
+// parseLFSPointer returns the SHA-256 object ID of an LFS pointer file. The
+// first line must declare a known spec version.
+func parseLFSPointer(data []byte) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() {
+		return "", false
+	}
+	version, ok := strings.CutPrefix(scanner.Text(), "version ")
+	if !ok || !slices.Contains(lfsPointerVersions, version) {
+		return "", false
+	}
+
+	var oid string
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "oid sha256:")
+		if !ok {
+			continue
+		}
+		if digest.SHA256.Validate(value) != nil {
+			return "", false
+		}
+		oid = value
+	}
+
+	return oid, oid != ""
+}
+
+// deleteLFSLayers deletes pruned LFS layers from the remote, if supported,
+// except those of snapshots, releases, the tag's history, and other tags. Layers of a separate LFS store may be shared with
+// other repositories, and are never deleted. Failures are logged, as pruned
+// layers are no longer referenced by the LFS manifest.
+func (m *model) deleteLFSLayers(ctx context.Context, pruned []ocispec.Descriptor) {
+	if m.lfsGT != nil {
+		slog.InfoContext(ctx, "LFS files are stored separately, pruned LFS layers remain")
+		return
+	}
+	d, ok := deleter(m.lfsStore())
+	if !ok {
+		slog.InfoContext(ctx, "remote does not support deletion, pruned LFS layers remain")
+		return
+	}
+
+	retained, err := m.retainedDigests(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "resolving layers of snapshots, releases, history, and other tags, pruned LFS layers remain", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, desc := range pruned {
+		if _, ok := retained[desc.Digest]; ok {
+			slog.DebugContext(ctx, "keeping pruned LFS layer of a snapshot, release, history, or other tag", slog.String("digest", desc.Digest.String()))
+			continue
+		}
+		if err := d.Delete(ctx, desc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+			slog.WarnContext(ctx, "failed to delete pruned LFS layer", slog.String("digest", desc.Digest.String()), slog.String("error", err.Error()))
+		}
+	}
+}
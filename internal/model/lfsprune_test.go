@@ -0,0 +1,199 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_parseLFSPointer(t *testing.T) {
+	const oid = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+	tests := []struct {
+		name    string
+		data    string
+		wantOid string
+		wantOk  bool
+	}{
+		{
+			name:    "Pointer",
+			data:    "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 12345\n",
+			wantOid: oid,
+			wantOk:  true,
+		},
+		{
+			name:    "Legacy Version",
+			data:    "version https://hawser.github.com/spec/v1\noid sha256:" + oid + "\nsize 12345\n",
+			wantOid: oid,
+			wantOk:  true,
+		},
+		{
+			name: "Unknown Version",
+			data: "version https://example.com/spec/v2\noid sha256:" + oid + "\nsize 12345\n",
+		},
+		{
+			name: "Invalid Oid",
+			data: "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 12345\n",
+		},
+		{
+			name: "Missing Oid",
+			data: "version https://git-lfs.github.com/spec/v1\nsize 12345\n",
+		},
+		{
+			name: "Not A Pointer",
+			data: "hello world\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOid, gotOk := parseLFSPointer([]byte(tt.data))
+			assert.Equal(t, tt.wantOid, gotOid)
+			assert.Equal(t, tt.wantOk, gotOk)
+		})
+	}
+}
+
+func Test_model_PruneLFS(t *testing.T) {
+	dir := t.TempDir()
+	builder, err := testutils.NewRepoBuilder(dir)
+	assert.NoError(t, err)
+	repo := builder.Repo()
+
+	kept := content.NewDescriptorFromBytes(oci.MediaTypeLFSLayer, []byte("kept"))
+	pruned := content.NewDescriptorFromBytes(oci.MediaTypeLFSLayer, []byte("pruned"))
+
+	// commit a pointer to the kept LFS file
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	pointer := fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", kept.Digest.Encoded(), kept.Size)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "data.bin"), []byte(pointer), 0o644))
+	_, err = wt.Add("data.bin")
+	assert.NoError(t, err)
+	commit, err := wt.Commit("add LFS file", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+
+	objs, err := revlist.Objects(repo.Storer, []plumbing.Hash{commit}, nil)
+	assert.NoError(t, err)
+	pack := new(bytes.Buffer)
+	_, err = packfile.NewEncoder(pack, repo.Storer, false).Encode(objs, 10)
+	assert.NoError(t, err)
+
+	newModel := func(t *testing.T) (*model, *deleterTarget) {
+		t.Helper()
+
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		for _, data := range []string{"kept", "pruned"} {
+			_, err := oras.PushBytes(t.Context(), gt, oci.MediaTypeLFSLayer, []byte(data))
+			assert.NoError(t, err)
+		}
+		packDesc, err := oras.PushBytes(t.Context(), gt, oci.MediaTypePackLayer, pack.Bytes())
+		assert.NoError(t, err)
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, fstore.Close())
+		})
+
+		m := &model{
+			ref:     testRemote,
+			gt:      gt,
+			fstore:  fstore,
+			fetched: true,
+			man:     ocispec.Manifest{Layers: []ocispec.Descriptor{packDesc}},
+			cfg: oci.ConfigGit{
+				Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					plumbing.Main: {Commit: commit.String(), Layer: packDesc.Digest},
+				},
+			},
+			refsByLayer: map[digest.Digest][]plumbing.Hash{},
+			lfsMan:      ocispec.Manifest{Layers: []ocispec.Descriptor{kept, pruned}},
+		}
+		m.manDesc, err = m.Push(t.Context())
+		assert.NoError(t, err)
+		return m, gt
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		m, gt := newModel(t)
+
+		got, err := m.PruneLFS(t.Context(), PruneLFSOptions{DeleteLayers: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []LFSObject{lfsObjectOf(pruned)}, got)
+		assert.Equal(t, []digest.Digest{pruned.Digest}, gt.deleted)
+
+		// the pushed LFS manifest no longer refers to the pruned LFS file
+		lfsMan, err := m.fetchLFSManifest(t.Context(), m.lfsManDesc)
+		assert.NoError(t, err)
+		assert.Equal(t, []ocispec.Descriptor{kept}, lfsMan.Layers)
+
+		got, err = m.PruneLFS(t.Context(), PruneLFSOptions{DeleteLayers: true})
+		assert.NoError(t, err)
+		assert.Empty(t, got, "all LFS files are referenced")
+	})
+
+	t.Run("Layers Kept", func(t *testing.T) {
+		m, gt := newModel(t)
+
+		got, err := m.PruneLFS(t.Context(), PruneLFSOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []LFSObject{lfsObjectOf(pruned)}, got)
+		assert.Empty(t, gt.deleted)
+
+		lfsMan, err := m.fetchLFSManifest(t.Context(), m.lfsManDesc)
+		assert.NoError(t, err)
+		assert.Equal(t, []ocispec.Descriptor{kept}, lfsMan.Layers)
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		m, gt := newModel(t)
+		_, err := m.PushLFSManifest(t.Context(), m.manDesc)
+		assert.NoError(t, err)
+		_, err = m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+
+		// push past the snapshot, whose LFS manifest refers to the pruned file
+		assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/heads/feature", commit), m.man.Layers[0].Digest))
+		m.manDesc, err = m.Push(t.Context())
+		assert.NoError(t, err)
+
+		got, err := m.PruneLFS(t.Context(), PruneLFSOptions{DeleteLayers: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []LFSObject{lfsObjectOf(pruned)}, got)
+		assert.Empty(t, gt.deleted, "the snapshot refers to the pruned LFS file")
+	})
+
+	t.Run("Released", func(t *testing.T) {
+		m, gt := newModel(t)
+		_, err := m.PushLFSManifest(t.Context(), m.manDesc)
+		assert.NoError(t, err)
+		_, err = m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+
+		got, err := m.PruneLFS(t.Context(), PruneLFSOptions{DeleteLayers: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []LFSObject{lfsObjectOf(pruned)}, got)
+		assert.Empty(t, gt.deleted, "the release refers to the pruned LFS file")
+	})
+}
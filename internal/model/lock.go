@@ -0,0 +1,364 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// ErrLocked indicates the push lock of a remote is held by another client.
+var ErrLocked = errors.New("remote locked by another client")
+
+const (
+	// lockTagSuffix is appended to the remote tag to form the tag of its lock.
+	lockTagSuffix = "-lock"
+
+	// DefaultLockTTL is the default time a lock is held without renewal.
+	DefaultLockTTL = time.Minute
+	// DefaultLockTimeout is the default time waited for a held lock.
+	DefaultLockTimeout = 5 * time.Minute
+	// defaultLockPollInterval is the default time between attempts to
+	// acquire a held lock.
+	defaultLockPollInterval = 2 * time.Second
+)
+
+// LockOptions configure acquiring the push lock of a remote.
+type LockOptions struct {
+	// Owner identifies the client in the lock, reported to clients waiting
+	// for it. Defaults to the user, host, and process ID.
+	Owner string
+	// TTL is the time the lock is held without renewal, after which other
+	// clients may take it over. The lock is renewed while held, such that it
+	// only expires if its owner exits without releasing it. Defaults to
+	// [DefaultLockTTL].
+	TTL time.Duration
+	// Timeout is the time waited for a lock held by another client before
+	// failing with [ErrLocked]. Defaults to [DefaultLockTimeout].
+	Timeout time.Duration
+	// PollInterval is the time between attempts to acquire a held lock.
+	PollInterval time.Duration
+}
+
+// withDefaults returns the options with unset fields defaulted.
+func (o LockOptions) withDefaults() LockOptions {
+	if o.Owner == "" {
+		o.Owner = defaultLockOwner()
+	}
+	if o.TTL <= 0 {
+		o.TTL = DefaultLockTTL
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultLockTimeout
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultLockPollInterval
+	}
+	return o
+}
+
+// defaultLockOwner identifies the current process, e.g. "alice@host (pid 42)".
+func defaultLockOwner() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return name + "@" + host + " (pid " + strconv.Itoa(os.Getpid()) + ")"
+}
+
+// Lock is an acquired push lock, renewed until released.
+type Lock struct {
+	gt    Store
+	tag   string
+	owner string
+	ttl   time.Duration
+
+	// the lock manifest the tag should resolve to, guarded by mu as it is
+	// replaced on renewal
+	mu   sync.Mutex
+	desc ocispec.Descriptor
+	lost error
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// lockInfo is the state of a lock manifest.
+type lockInfo struct {
+	owner   string
+	expires time.Time
+}
+
+func (m *model) Lock(ctx context.Context, opts LockOptions) (*Lock, error) {
+	if err := m.checkTagged(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	lockRef := m.ref
+	lockRef.Reference += lockTagSuffix
+	l := &Lock{gt: m.gt, tag: lockRef.String(), owner: opts.Owner, ttl: opts.TTL}
+
+	deadline := time.Now().Add(opts.Timeout)
+	var waiting bool
+	for {
+		held, err := l.tryAcquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if held == nil {
+			break
+		}
+
+		if time.Now().Add(opts.PollInterval).After(deadline) {
+			return nil, fmt.Errorf("%w: %s held by %s until %s, waited %s", ErrLocked, l.tag, held.owner, held.expires.Format(time.RFC3339), opts.Timeout)
+		}
+		if !waiting {
+			slog.InfoContext(ctx, "waiting for remote lock", slog.String("tag", l.tag), slog.String("owner", held.owner), slog.Time("expires", held.expires))
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for remote lock: %w", context.Cause(ctx))
+		case <-time.After(opts.PollInterval):
+		}
+	}
+	slog.DebugContext(ctx, "acquired remote lock", slog.String("tag", l.tag), slog.String("digest", l.desc.Digest.String()))
+
+	// the remote may have been updated while waiting, fetch it anew
+	m.resetFetched()
+
+	renewCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	l.stop = stop
+	l.done = make(chan struct{})
+	go l.renew(renewCtx)
+
+	return l, nil
+}
+
+// tryAcquire acquires the lock if it is free or expired, returning the state
+// of the lock if held by another client.
+func (l *Lock) tryAcquire(ctx context.Context) (*lockInfo, error) {
+	current, err := l.gt.Resolve(ctx, l.tag)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("resolving lock %s: %w", l.tag, err)
+	default:
+		info, err := fetchLockInfo(ctx, l.gt, current)
+		if err != nil {
+			return nil, err
+		}
+		if time.Now().Before(info.expires) {
+			return info, nil
+		}
+		if info.owner != "" {
+			slog.WarnContext(ctx, "taking over expired remote lock", slog.String("tag", l.tag), slog.String("owner", info.owner), slog.Time("expired", info.expires))
+		}
+	}
+
+	desc, err := l.tagLock(ctx, time.Now().Add(l.ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	// registries resolve concurrent tags by the last write, confirm ours won
+	got, err := l.gt.Resolve(ctx, l.tag)
+	if err != nil {
+		return nil, fmt.Errorf("verifying lock %s: %w", l.tag, err)
+	}
+	if got.Digest != desc.Digest {
+		info, err := fetchLockInfo(ctx, l.gt, got)
+		if err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+
+	if current.Digest != "" {
+		l.deleteLock(ctx, current)
+	}
+	l.desc = desc
+
+	return nil, nil
+}
+
+// tagLock pushes a lock manifest owned by l expiring at expires, and tags it.
+func (l *Lock) tagLock(ctx context.Context, expires time.Time) (ocispec.Descriptor, error) {
+	now := time.Now().UTC()
+	manOpts := oras.PackManifestOptions{
+		ManifestAnnotations: map[string]string{
+			ocispec.AnnotationCreated: now.Format(time.RFC3339Nano),
+			oci.AnnotationLockOwner:   l.owner,
+			oci.AnnotationLockExpires: expires.UTC().Format(time.RFC3339Nano),
+		},
+	}
+	desc, err := oras.PackManifest(ctx, existingPusher{l.gt}, oras.PackManifestVersion1_1, oci.ArtifactTypeGitLock, manOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("packing and pushing lock manifest: %w", err)
+	}
+	if err := l.gt.Tag(ctx, desc, l.tag); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("tagging lock %s: %w", l.tag, err)
+	}
+
+	return desc, nil
+}
+
+// fetchLockInfo fetches the state of a lock manifest. Manifests which are not
+// locks, or lack an expiry, are treated as expired.
+func fetchLockInfo(ctx context.Context, gt Store, desc ocispec.Descriptor) (*lockInfo, error) {
+	manRaw, err := content.FetchAll(ctx, gt, desc)
+	if err != nil {
+		return nil, fmt.Errorf("fetching lock manifest: %w", err)
+	}
+
+	var man ocispec.Manifest
+	if err := json.Unmarshal(manRaw, &man); err != nil {
+		return nil, fmt.Errorf("decoding lock manifest: %w", err)
+	}
+	if man.ArtifactType != oci.ArtifactTypeGitLock {
+		return &lockInfo{}, nil
+	}
+
+	info := &lockInfo{owner: man.Annotations[oci.AnnotationLockOwner]}
+	if expires, ok := man.Annotations[oci.AnnotationLockExpires]; ok {
+		info.expires, err = time.Parse(time.RFC3339Nano, expires)
+		if err != nil {
+			return nil, fmt.Errorf("parsing lock expiry: %w", err)
+		}
+	}
+
+	return info, nil
+}
+
+// renew extends the lock every third of its TTL until ctx is done, giving up
+// if the lock was taken over.
+func (l *Lock) renew(ctx context.Context) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := l.extend(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "renewing remote lock", slog.String("tag", l.tag), slog.String("error", err.Error()))
+			if errors.Is(err, ErrLocked) {
+				return
+			}
+		}
+	}
+}
+
+// extend tags a lock manifest with a later expiry, provided the lock is still
+// held. Throws [ErrLocked] if the lock was taken over.
+func (l *Lock) extend(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.check(ctx); err != nil {
+		return err
+	}
+
+	desc, err := l.tagLock(ctx, time.Now().Add(l.ttl))
+	if err != nil {
+		return err
+	}
+	l.deleteLock(ctx, l.desc)
+	l.desc = desc
+
+	return nil
+}
+
+// check verifies the lock is still held, recording if it was lost. Must be
+// called with mu held.
+func (l *Lock) check(ctx context.Context) error {
+	if l.lost != nil {
+		return l.lost
+	}
+
+	current, err := l.gt.Resolve(ctx, l.tag)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		l.lost = fmt.Errorf("%w: lock %s was removed", ErrLocked, l.tag)
+	case err != nil:
+		return fmt.Errorf("resolving lock %s: %w", l.tag, err)
+	case current.Digest != l.desc.Digest:
+		l.lost = fmt.Errorf("%w: lock %s was taken over", ErrLocked, l.tag)
+		if info, err := fetchLockInfo(ctx, l.gt, current); err == nil {
+			l.lost = fmt.Errorf("%w: lock %s was taken over by %s", ErrLocked, l.tag, info.owner)
+		}
+	}
+
+	return l.lost
+}
+
+// Release stops renewing the lock and releases it, deleting the lock manifest
+// if the remote supports deletion, otherwise tagging an expired lock. Throws
+// [ErrLocked] if the lock was taken over by another client while held.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stop()
+	<-l.done
+
+	// release even if interrupted, other clients otherwise wait for expiry
+	ctx = context.WithoutCancel(ctx)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.check(ctx); err != nil {
+		return err
+	}
+
+	if d, ok := deleter(l.gt); ok {
+		err := d.Delete(ctx, l.desc)
+		if err == nil || errors.Is(err, errdef.ErrNotFound) {
+			slog.DebugContext(ctx, "released remote lock", slog.String("tag", l.tag))
+			return nil
+		}
+		slog.DebugContext(ctx, "deleting lock manifest, expiring it instead", slog.String("error", err.Error()))
+	}
+
+	desc, err := l.tagLock(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("releasing lock: %w", err)
+	}
+	l.desc = desc
+	slog.DebugContext(ctx, "released remote lock", slog.String("tag", l.tag))
+
+	return nil
+}
+
+// deleteLock deletes a superseded lock manifest, if the remote supports
+// deletion. Failures are only logged, as superseded locks are not tagged.
+func (l *Lock) deleteLock(ctx context.Context, desc ocispec.Descriptor) {
+	d, ok := deleter(l.gt)
+	if !ok {
+		return
+	}
+	if err := d.Delete(ctx, desc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+		slog.DebugContext(ctx, "deleting superseded lock manifest", slog.String("digest", desc.Digest.String()), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_Lock(t *testing.T) {
+	lockTag := testRemote.String() + lockTagSuffix
+	opts := LockOptions{Owner: "alice", TTL: time.Minute, Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+
+	// fetchLock fetches the manifest of the lock tag
+	fetchLock := func(t *testing.T, gt oras.GraphTarget) (ocispec.Descriptor, ocispec.Manifest) {
+		t.Helper()
+
+		desc, err := gt.Resolve(t.Context(), lockTag)
+		assert.NoError(t, err)
+		manRaw, err := content.FetchAll(t.Context(), gt, desc)
+		assert.NoError(t, err)
+		var man ocispec.Manifest
+		assert.NoError(t, json.Unmarshal(manRaw, &man))
+		return desc, man
+	}
+
+	t.Run("Acquire and Release", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := &model{ref: testRemote, gt: gt, fetched: true, cfgFetched: true}
+
+		lock, err := m.Lock(t.Context(), opts)
+		assert.NoError(t, err)
+		assert.False(t, m.fetched, "fetched state should be discarded")
+		assert.False(t, m.cfgFetched, "fetched state should be discarded")
+
+		_, man := fetchLock(t, gt)
+		assert.Equal(t, oci.ArtifactTypeGitLock, man.ArtifactType)
+		assert.Equal(t, "alice", man.Annotations[oci.AnnotationLockOwner])
+
+		other := &model{ref: testRemote, gt: gt}
+		_, err = other.Lock(t.Context(), LockOptions{Owner: "bob", Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+		assert.ErrorIs(t, err, ErrLocked)
+		assert.ErrorContains(t, err, "held by alice")
+
+		assert.NoError(t, lock.Release(t.Context()))
+
+		// without deletion, the lock is released by expiring it
+		_, man = fetchLock(t, gt)
+		expires, err := time.Parse(time.RFC3339Nano, man.Annotations[oci.AnnotationLockExpires])
+		assert.NoError(t, err)
+		assert.False(t, expires.After(time.Now()))
+
+		lock, err = other.Lock(t.Context(), LockOptions{Owner: "bob", Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+		assert.NoError(t, err)
+		assert.NoError(t, lock.Release(t.Context()))
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := &model{ref: testRemote, gt: gt}
+
+		lock, err := m.Lock(t.Context(), opts)
+		assert.NoError(t, err)
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			assert.NoError(t, lock.Release(t.Context()))
+		}()
+
+		other := &model{ref: testRemote, gt: gt}
+		lock, err = other.Lock(t.Context(), LockOptions{Owner: "bob", Timeout: time.Second, PollInterval: 10 * time.Millisecond})
+		assert.NoError(t, err)
+		_, man := fetchLock(t, gt)
+		assert.Equal(t, "bob", man.Annotations[oci.AnnotationLockOwner])
+		assert.NoError(t, lock.Release(t.Context()))
+	})
+
+	t.Run("Stale Takeover", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		m := &model{ref: testRemote, gt: gt}
+
+		stale := &Lock{gt: gt, tag: lockTag, owner: "crashed"}
+		staleDesc, err := stale.tagLock(t.Context(), time.Now().Add(-time.Second))
+		assert.NoError(t, err)
+
+		lock, err := m.Lock(t.Context(), opts)
+		assert.NoError(t, err)
+		assert.Contains(t, gt.deleted, staleDesc.Digest, "stale lock should be deleted")
+
+		assert.NoError(t, lock.Release(t.Context()))
+		assert.Contains(t, gt.deleted, lock.desc.Digest, "lock should be deleted on release")
+	})
+
+	t.Run("Renewal", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := &model{ref: testRemote, gt: gt}
+
+		renewing := opts
+		renewing.TTL = 60 * time.Millisecond
+		lock, err := m.Lock(t.Context(), renewing)
+		assert.NoError(t, err)
+		first, _ := fetchLock(t, gt)
+
+		// held beyond its TTL
+		time.Sleep(150 * time.Millisecond)
+		desc, man := fetchLock(t, gt)
+		assert.NotEqual(t, first.Digest, desc.Digest, "lock should be renewed")
+		assert.Equal(t, "alice", man.Annotations[oci.AnnotationLockOwner])
+		other := &model{ref: testRemote, gt: gt}
+		_, err = other.Lock(t.Context(), LockOptions{Owner: "bob", Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+		assert.ErrorIs(t, err, ErrLocked)
+
+		assert.NoError(t, lock.Release(t.Context()))
+	})
+
+	t.Run("Taken Over", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := &model{ref: testRemote, gt: gt}
+
+		lock, err := m.Lock(t.Context(), opts)
+		assert.NoError(t, err)
+
+		thief := &Lock{gt: gt, tag: lockTag, owner: "mallory"}
+		_, err = thief.tagLock(t.Context(), time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+
+		err = lock.Release(t.Context())
+		assert.ErrorIs(t, err, ErrLocked)
+		assert.ErrorContains(t, err, "taken over by mallory")
+		_, man := fetchLock(t, gt)
+		assert.Equal(t, "mallory", man.Annotations[oci.AnnotationLockOwner], "lock of another client should be kept")
+	})
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// ErrMetadataNotFound indicates a Git manifest has no metadata referrer.
+var ErrMetadataNotFound = errors.New("repository metadata not found")
+
+func (m *model) FetchMetadata(ctx context.Context) (oci.Metadata, error) {
+	slog.DebugContext(ctx, "resolving metadata referrers", slog.String("subjectDigest", m.manDesc.Digest.String()))
+	if m.manDesc.Digest == "" {
+		// the remote does not exist yet
+		return oci.Metadata{}, ErrMetadataNotFound
+	}
+
+	referrers, err := listReferrers(ctx, m.gt, m.manDesc, oci.ArtifactTypeGitMetadata)
+	if err != nil {
+		return oci.Metadata{}, fmt.Errorf("resolving metadata referrers: %w", err)
+	}
+	if len(referrers) < 1 {
+		return oci.Metadata{}, ErrMetadataNotFound
+	}
+
+	// old referrers remain if the registry does not support deletion, use the newest
+	m.metaManDesc = slices.MaxFunc(referrers, func(a, b ocispec.Descriptor) int {
+		return strings.Compare(a.Annotations[ocispec.AnnotationCreated], b.Annotations[ocispec.AnnotationCreated])
+	})
+
+	manRaw, err := content.FetchAll(ctx, m.gt, m.metaManDesc)
+	if err != nil {
+		return oci.Metadata{}, fmt.Errorf("fetching metadata manifest: %w", err)
+	}
+	if err := json.Unmarshal(manRaw, &m.metaMan); err != nil {
+		return oci.Metadata{}, fmt.Errorf("decoding metadata manifest: %w", err)
+	}
+
+	cfgRaw, err := content.FetchAll(ctx, m.gt, m.metaMan.Config)
+	if err != nil {
+		return oci.Metadata{}, fmt.Errorf("fetching metadata: %w", err)
+	}
+
+	var meta oci.Metadata
+	if err := json.Unmarshal(cfgRaw, &meta); err != nil {
+		return oci.Metadata{}, fmt.Errorf("decoding metadata: %w", err)
+	}
+
+	return meta, nil
+}
+
+func (m *model) PushMetadata(ctx context.Context, subject ocispec.Descriptor, meta oci.Metadata, readme []byte) (ocispec.Descriptor, error) {
+	slog.DebugContext(ctx, "pushing metadata manifest", slog.String("subjectDigest", subject.Digest.String()))
+
+	var layers []ocispec.Descriptor
+	switch {
+	case readme != nil:
+		readmeDesc, err := oras.PushBytes(ctx, existingPusher{m.gt}, oci.MediaTypeReadme, readme)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("pushing README: %w", err)
+		}
+		meta.Readme = readmeDesc.Digest
+		layers = append(layers, readmeDesc)
+	case meta.Readme != "":
+		// carry over the README of the previous metadata
+		i := slices.IndexFunc(m.metaMan.Layers, func(desc ocispec.Descriptor) bool { return desc.Digest == meta.Readme })
+		if i < 0 {
+			return ocispec.Descriptor{}, fmt.Errorf("%w: README %s", errLayerNotInManifest, meta.Readme)
+		}
+		layers = append(layers, m.metaMan.Layers[i])
+	}
+
+	cfgRaw, err := canonicalJSON(meta)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("encoding metadata: %w", err)
+	}
+	cfgDesc, err := oras.PushBytes(ctx, existingPusher{m.gt}, oci.MediaTypeGitMetadata, cfgRaw)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("pushing metadata: %w", err)
+	}
+
+	// standard annotations, such that registry UIs may display them
+	annotations := map[string]string{ocispec.AnnotationCreated: time.Now().UTC().Format(time.RFC3339Nano)}
+	if meta.Description != "" {
+		annotations[ocispec.AnnotationDescription] = meta.Description
+	}
+	if meta.Website != "" {
+		annotations[ocispec.AnnotationURL] = meta.Website
+	}
+
+	manOpts := oras.PackManifestOptions{
+		Subject:             &subject,
+		Layers:              layers,
+		ConfigDescriptor:    &cfgDesc,
+		ManifestAnnotations: annotations,
+	}
+	manDesc, err := oras.PackManifest(ctx, m.gt, oras.PackManifestVersion1_1, oci.ArtifactTypeGitMetadata, manOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("packing and pushing metadata manifest: %w", err)
+	}
+	slog.DebugContext(ctx, "pushed metadata manifest", slog.String("digest", manDesc.Digest.String()))
+
+	if m.metaManDesc.Digest != "" {
+		// remove old referrer
+		d, ok := deleter(m.gt)
+		if !ok {
+			slog.WarnContext(ctx, "remote does not support deletion, old metadata referrer remains")
+		} else if err := d.Delete(ctx, m.metaManDesc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+			return manDesc, fmt.Errorf("deleting old metadata referrer manifest: %w", err)
+		}
+	}
+
+	m.metaManDesc = manDesc
+	m.metaMan = ocispec.Manifest{Config: cfgDesc, Layers: layers}
+
+	return manDesc, nil
+}
+
+// UpdateMetadataReferrer updates the subject of an existing metadata
+// referrer manifest to a new subject descriptor.
+func UpdateMetadataReferrer(m Modeler) ReferrerUpdater {
+	return func(ctx context.Context, subject ocispec.Descriptor) error {
+		meta, err := m.FetchMetadata(ctx) // fetch metadata from old git descriptor
+		switch {
+		case errors.Is(err, ErrMetadataNotFound):
+			slog.DebugContext(ctx, "metadata manifest not found")
+			return nil
+		case err != nil:
+			slog.ErrorContext(ctx, "failed to fetch metadata manifest", slog.String("error", err.Error()))
+			return nil
+		}
+
+		metaManDesc, err := m.PushMetadata(ctx, subject, meta, nil)
+		if err != nil {
+			return fmt.Errorf("pushing metadata manifest: %w", err)
+		}
+		slog.DebugContext(ctx, "successfully updated metadata referrer subject", slog.String("subjectDigest", subject.Digest.String()), slog.String("referrerDigest", metaManDesc.Digest.String()))
+
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_Metadata(t *testing.T) {
+	readme := []byte("# Gnocchi\n")
+	meta := oci.Metadata{
+		Description: "Pasta-like dumplings",
+		Website:     "https://en.wikipedia.org/wiki/Gnocchi",
+		Topics:      []string{"food", "italian"},
+	}
+
+	t.Run("Not Found", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt)
+
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.FetchMetadata(t.Context())
+		assert.ErrorIs(t, err, ErrMetadataNotFound)
+	})
+
+	t.Run("Push and Fetch", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt)
+
+		manDesc, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.PushMetadata(t.Context(), manDesc, meta, readme)
+		assert.NoError(t, err)
+
+		// replaces the previous metadata
+		updated := meta
+		updated.Description = "Dumplings"
+		updated.Readme = digest.FromBytes(readme)
+		_, err = m.PushMetadata(t.Context(), manDesc, updated, nil)
+		assert.NoError(t, err)
+
+		got, err := newSignatureModel(t, gt).fetchMetadata(t)
+		assert.NoError(t, err)
+
+		assert.Equal(t, updated, got)
+	})
+
+	t.Run("Carried Over on Push", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt)
+
+		manDesc, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = m.PushMetadata(t.Context(), manDesc, meta, readme)
+		assert.NoError(t, err)
+
+		err = m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/tags/new", plumbing.ZeroHash), m.man.Layers[0].Digest)
+		assert.NoError(t, err)
+		newManDesc, err := m.Push(t.Context(), UpdateMetadataReferrer(m))
+		assert.NoError(t, err)
+		assert.NotEqual(t, manDesc.Digest, newManDesc.Digest)
+
+		other := newSignatureModel(t, gt)
+		got, err := other.fetchMetadata(t)
+		assert.NoError(t, err)
+		assert.Equal(t, digest.FromBytes(readme), got.Readme)
+		assert.Equal(t, meta.Description, got.Description)
+
+		gotReadme, err := content.FetchAll(t.Context(), gt, other.metaMan.Layers[0])
+		assert.NoError(t, err)
+		assert.Equal(t, readme, gotReadme)
+	})
+}
+
+// fetchMetadata fetches the Git manifest, then its metadata.
+func (m *model) fetchMetadata(t *testing.T) (oci.Metadata, error) {
+	t.Helper()
+
+	_, err := m.Fetch(t.Context())
+	assert.NoError(t, err)
+
+	return m.FetchMetadata(t.Context())
+}
@@ -3,17 +3,20 @@ package model
 
 import (
 	"context"
+	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
 	"log/slog"
-	"path/filepath"
+	"maps"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -24,7 +27,10 @@ import (
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 
+	"github.com/act3-ai/gnoci/internal/cache"
 	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/logutil"
+	"github.com/act3-ai/gnoci/internal/tracing"
 	"github.com/act3-ai/gnoci/pkg/oci"
 )
 
@@ -33,10 +39,38 @@ var (
 	ErrUnsupportedReferenceType = errors.New("unsupported reference type")
 	// ErrReferenceNotFound indicates a reference does not exist in the OCI remote.
 	ErrReferenceNotFound = errors.New("reference not found in remote data model")
+	// ErrConcurrentUpdate indicates the remote was updated by another client
+	// since it was fetched.
+	ErrConcurrentUpdate = errors.New("remote updated concurrently")
+	// ErrCommitNotFound indicates no packfile layer is known to contain a commit.
+	ErrCommitNotFound = errors.New("commit not found in remote data model")
+	// ErrNotGitManifest indicates the remote tag holds an artifact other than
+	// a Git manifest, which is not overwritten.
+	ErrNotGitManifest = errors.New("tag does not hold a Git manifest")
+	// ErrDigestReference indicates an update of a remote referenced by
+	// manifest digest, which is immutable.
+	ErrDigestReference = errors.New("remote is referenced by digest")
+
 	// errLayerNotInManifest indicates a specified layer digest does not exist in the Git manifest.
 	errLayerNotInManifest = errors.New("layer not found for digest")
 )
 
+// RefError is an error updating a Git reference in the Git OCI data model.
+type RefError struct {
+	// Ref is the name of the reference that failed to update.
+	Ref plumbing.ReferenceName
+	// Err is the cause of the failure.
+	Err error
+}
+
+func (e *RefError) Error() string {
+	return fmt.Sprintf("updating reference %s: %s", e.Ref, e.Err)
+}
+
+func (e *RefError) Unwrap() error {
+	return e.Err
+}
+
 // tempGitManifest is used only on an initial push of an LFS manifest.
 const tempGitManifest = "temp.git.manifest"
 
@@ -47,24 +81,83 @@ type ReadOnlyModeler interface {
 	Ref() registry.Reference
 	// Fetch pulls Git OCI metadata from a remote. It does not pull layers.
 	Fetch(ctx context.Context) (ocispec.Descriptor, error)
+	// FetchConfigOnly pulls only the Git OCI config from a remote, reading the
+	// manifest just until its config descriptor. References, the default
+	// branch, and the object format are available, layers are not until
+	// [ReadOnlyModeler.Fetch] completes the manifest of the same descriptor.
+	FetchConfigOnly(ctx context.Context) (ocispec.Descriptor, error)
 	// FetchOrDefault extends [ReadOnlyModeler.Fetch] to initialize an empty OCI manifest and config
 	// if the remote ref does not exist.
 	FetchOrDefault(ctx context.Context) (ocispec.Descriptor, error)
+	// FetchOrEmpty extends [ReadOnlyModeler.Fetch] to initialize an empty OCI manifest and config
+	// if the remote ref does not exist. Unlike [ReadOnlyModeler.FetchOrDefault], the remote is not
+	// modified.
+	FetchOrEmpty(ctx context.Context) (ocispec.Descriptor, error)
 	// FetchLayer fetches a packfile layer from OCI identifies by digest.
 	FetchLayer(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error)
+	// FetchPackIndex fetches the pack index of a packfile layer, see [PackIndex].
+	// Throws [ErrPackIndexNotFound] if the layer has no pack index layer.
+	FetchPackIndex(ctx context.Context, layer digest.Digest) (io.ReadCloser, error)
 	// FetchLayersReverse returns an iterator that walks the set of packfile layers
 	// in reverse.
 	FetchLayersReverse(ctx context.Context) iter.Seq2[io.ReadCloser, error]
+	// Layers returns the packfile layer descriptors, ordered oldest to newest.
+	Layers() []ocispec.Descriptor
+	// Prefetch fetches the packfile layers of dgsts, all if none are given,
+	// into the local cache as stored, without decoding them. Returns the
+	// number of layers not previously cached. Throws [ErrCacheDisabled] if no
+	// cache is enabled.
+	Prefetch(ctx context.Context, dgsts ...digest.Digest) (int, error)
 	// ResolveRef resolves the commit hash a remote reference refers to. Returns nil, nil if
-	// the ref does not exist or if not supported (head or tag ref).
+	// the ref does not exist or if not supported (head or tag ref). HEAD resolves to the
+	// commit of the default branch.
 	ResolveRef(ctx context.Context, refName plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error)
+	// ResolveRefLayers resolves the packfile layers holding the objects
+	// reachable from a remote reference, the layer [ReadOnlyModeler.ResolveRef]
+	// resolves followed by any newer layers holding its history. HEAD resolves
+	// to the layers of the default branch.
+	ResolveRefLayers(ctx context.Context, refName plumbing.ReferenceName) ([]digest.Digest, error)
+	// ResolveCommit resolves the packfile layer containing a commit, from the
+	// remote references and the commit index of each layer. Throws
+	// [ErrCommitNotFound] if no layer is known to contain the commit.
+	ResolveCommit(ctx context.Context, commit plumbing.Hash) (digest.Digest, error)
 	// HeadRefs returns the existing head references.
 	HeadRefs() map[plumbing.ReferenceName]oci.ReferenceInfo
+	// DefaultBranch returns the head reference HEAD points to, or an empty
+	// name if unset or the branch no longer exists.
+	DefaultBranch() plumbing.ReferenceName
+	// ObjectFormat returns the hash algorithm of the remote repository's
+	// objects.
+	ObjectFormat() formatcfg.ObjectFormat
+	// Shallow returns the commits on the shallow boundary of a remote with
+	// truncated history, whose parents do not exist in the remote.
+	Shallow() []plumbing.Hash
 	// TagRefs returns the existing tag references.
 	TagRefs() map[plumbing.ReferenceName]oci.ReferenceInfo
-	// CommitExists uses a local repository to resolve the best known OCI layer containing the commit.
-	// a nil error with an empty layer digest indicates a commit does not exist.
-	CommitExists(localRepo git.Repository, commit *object.Commit) (digest.Digest, error)
+	// NoteRefs returns the existing notes references.
+	NoteRefs() map[plumbing.ReferenceName]oci.ReferenceInfo
+	// FetchMetadata pulls the repository metadata referring to the fetched Git
+	// manifest. Throws [ErrMetadataNotFound] if none exists.
+	FetchMetadata(ctx context.Context) (oci.Metadata, error)
+	// Verify verifies the fetched Git manifest has a signature referrer
+	// signed by any of keys.
+	Verify(ctx context.Context, keys ...crypto.PublicKey) error
+	// Fsck checks the integrity of the fetched Git OCI data model, downloading
+	// and indexing every packfile layer. Integrity violations are reported,
+	// only failures to perform the check are returned as errors.
+	Fsck(ctx context.Context) (*FsckReport, error)
+	// History describes up to the last n Git manifests pushed to the remote,
+	// oldest first, ending with the fetched manifest. Earlier manifests are
+	// only known from the history index of [WithTagHistory].
+	History(ctx context.Context, n int) ([]ManifestStats, error)
+	// CommitExists resolves the OCI layer containing the commit from the commit-graph, falling back
+	// to walking the local repository from each remote reference for layers the commit-graph does not index.
+	// A nil error with an empty layer digest indicates a commit does not exist.
+	CommitExists(ctx context.Context, localRepo git.Repository, commit *object.Commit) (digest.Digest, error)
+	// IsAncestor reports whether ancestor is reachable from commit, per the commit-graph, without
+	// fetching packfiles. Throws [ErrCommitNotFound] if the commit-graph cannot decide, e.g. commit
+	// is not indexed.
+	IsAncestor(ctx context.Context, ancestor, commit plumbing.Hash) (bool, error)
 }
 
 // Modeler extends [ReadOnlyModeler] with updating and pushing a Git OCI data model to
@@ -72,26 +165,106 @@ type ReadOnlyModeler interface {
 type Modeler interface {
 	ReadOnlyModeler
 
-	// Push uploads the Git OCI data model in its current state.
+	// Push uploads the Git OCI data model in its current state. If another
+	// client moved the remote tag since it was fetched, the updates are
+	// rebased onto the updated remote and pushed again, up to a bounded number
+	// of attempts. References updated by both clients are rejected with
+	// [ErrConcurrentUpdate], unless the update is a fast forward of theirs.
 	Push(ctx context.Context, referrerUpdates ...ReferrerUpdater) (ocispec.Descriptor, error)
+	// PushAtomic extends [Modeler.Push] to only tag the new manifest if the
+	// remote has not been updated since it was fetched. The tag is verified
+	// once updated, restoring the previous manifest on failure.
+	PushAtomic(ctx context.Context, referrerUpdates ...ReferrerUpdater) (ocispec.Descriptor, error)
+	// Lock acquires the advisory push lock of the remote, waiting while
+	// another client holds it, then discards the fetched state such that the
+	// remote is fetched anew under the lock. The lock is renewed until
+	// released. Throws [ErrLocked] if the lock is not acquired before the
+	// timeout of opts. Clients not locking the remote are not excluded.
+	Lock(ctx context.Context, opts LockOptions) (*Lock, error)
 	// AddPack adds a packfile as a layer to the Git OCI data model and updates
-	// the remote references whose objs are included in the packfile.
-	AddPack(ctx context.Context, path string, refs ...*plumbing.Reference) (ocispec.Descriptor, error)
+	// the remote references whose objs are included in the packfile. A non-empty
+	// base denotes a thin packfile, whose delta bases are resolved from the base
+	// layer and those before it. The commits within the packfile are indexed in
+	// the layer's annotations, allowing them to be fetched by hash. References
+	// that fail to update are reported as a [RefError] each, joined, while the
+	// packfile remains added.
+	AddPack(ctx context.Context, path string, base digest.Digest, commits []*object.Commit, refs ...*plumbing.Reference) (ocispec.Descriptor, error)
 	// UpdateRef updates a Git reference and the object it points to in the
 	// Git OCI data model. Useful for updating a reference where its object
 	// is within a packfile that already exists in the remote OCI registry.
 	UpdateRef(ctx context.Context, ref *plumbing.Reference, ociLayer digest.Digest) error
 	// DeleteRef removes a reference from the remote. The commit remains.
 	DeleteRef(ctx context.Context, refName plumbing.ReferenceName) error
+	// SetDefaultBranch updates the head reference HEAD points to. The branch
+	// must exist in the remote.
+	SetDefaultBranch(ctx context.Context, refName plumbing.ReferenceName) error
+	// SetPeeled records the object an annotated tag reference points to,
+	// allowing fetches to follow tags of fetched commits. The tag must exist
+	// in the remote, updating it clears the peeled object.
+	SetPeeled(ctx context.Context, refName plumbing.ReferenceName, target plumbing.Hash) error
+	// TagSnapshot tags the pushed Git manifest with an immutable tag for the
+	// current commit of a reference, namespaced by the remote tag, e.g.
+	// sync-refs-heads-main-<abbreviated commit>, and records it in the
+	// snapshot index. Returns the snapshot tag.
+	TagSnapshot(ctx context.Context, refName plumbing.ReferenceName) (string, error)
+	// SetObjectFormat records the hash algorithm of the remote repository's
+	// objects. The format of a remote with packfile layers cannot be changed.
+	SetObjectFormat(ctx context.Context, format formatcfg.ObjectFormat) error
+	// SetShallow records the shallow boundary of a truncated history. The
+	// boundary of a remote with packfile layers cannot be changed.
+	SetShallow(ctx context.Context, commits []plumbing.Hash) error
+	// PushMetadata pushes repository metadata referring to subject, replacing
+	// any previously fetched metadata. A non-nil readme is pushed as the
+	// README, otherwise the README of the previously fetched metadata is kept.
+	PushMetadata(ctx context.Context, subject ocispec.Descriptor, meta oci.Metadata, readme []byte) (ocispec.Descriptor, error)
+	// Sign pushes a signature referrer for the fetched Git manifest.
+	Sign(ctx context.Context, signer crypto.Signer) (ocispec.Descriptor, error)
+	// Delete deletes the fetched Git manifest, its config and layers, and its
+	// referrers, e.g. LFS manifests, signatures, and metadata, returning the
+	// content deleted in order. The history, snapshots, and releases of the tag
+	// are deleted alike, as they share its manifests and layers. Throws
+	// [ErrDeleteUnsupported] if the remote does not support deletion, unless a
+	// dry run.
+	Delete(ctx context.Context, opts DeleteOptions) ([]Deletion, error)
+	// Consolidate rebuilds all packfile layers into a single packfile containing
+	// only the objects reachable from the current references, then pushes the
+	// updated Git OCI data model. Superseded layers are deleted from the remote
+	// if supported.
+	Consolidate(ctx context.Context, referrerUpdates ...ReferrerUpdater) (ocispec.Descriptor, error)
 }
 
 // NewModeler initializes a new git modeler.
-func NewModeler(ref registry.Reference, fstore *file.Store, gt oras.GraphTarget) Modeler {
-	return &model{
+func NewModeler(ref registry.Reference, fstore *file.Store, gt Store, opts ...Option) Modeler {
+	m := &model{
 		ref:    ref,
 		gt:     gt,
 		fstore: fstore,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// NewReadOnlyModeler initializes a git modeler reading the Git OCI data model
+// of ref from gt. Unlike [NewModeler], no intermediate file store is
+// required, as nothing is added.
+func NewReadOnlyModeler(ref registry.Reference, gt Store, opts ...Option) ReadOnlyModeler {
+	return NewReadOnlyLFSModeler(ref, gt, opts...)
+}
+
+// DefaultConcurrency is the default maximum number of packfile layers
+// uploaded at once, matching that of oras.CopyGraph.
+const DefaultConcurrency = 3
+
+// WithConcurrency limits the number of packfile layers uploaded at once on
+// push, e.g. those of a push split into multiple layers. Defaults to
+// [DefaultConcurrency].
+func WithConcurrency(n int) Option {
+	return func(m *model) {
+		m.concurrency = n
+	}
 }
 
 // model implements Modeler.
@@ -100,39 +273,116 @@ func NewModeler(ref registry.Reference, fstore *file.Store, gt oras.GraphTarget)
 type model struct {
 	// OCI remote
 	ref registry.Reference
-	gt  oras.GraphTarget
+	gt  Store
 
 	// intermediate storage on push
 	fstore *file.Store
+	// algorithm of added layer digests, and the files of layers added with a
+	// non-canonical algorithm, which fstore cannot describe
+	digestAlg digest.Algorithm
+	blobPaths sync.Map // digest.Digest -> string
+	// compress packfile layers on push
+	zstdPacks bool
+	// push the pack index of packfile layers on push
+	packIndexes bool
+	// encrypt packfile and LFS layers with the first, decrypt with any
+	encryptionKeys []EncryptionKey
+	// delete pruned packfile layers from the remote on push
+	deleteOrphans bool
+	// repositories new packfile layers are mounted from on push
+	mountFrom []string
+	// user agent and version recorded in packfile layer annotations
+	creator string
+	// sign manifests on push
+	signer crypto.Signer
+	// keys trusted to sign fetched manifests
+	verifyKeys []crypto.PublicKey
+	// local cache of fetched blobs
+	cache *cache.Cache
+	// annotations of pushed manifests
+	createdAt       func() time.Time
+	sourceURL       string
+	userAnnotations Annotations
+	// maximum packfile layers uploaded at once
+	concurrency int
+	// record pushes in a history referrer rather than moving the tag
+	tagHistory bool
+	// notified of changes made to the remote, and the references changed
+	// since the last push
+	observers   []Observer
+	changedRefs map[plumbing.ReferenceName]struct{}
 
 	// populated on [model.Fetch]
-	fetched     bool
-	manDesc     ocispec.Descriptor
+	fetched bool
+	// populated on [model.FetchConfigOnly], along with manDesc and cfg
+	cfgFetched bool
+	manDesc    ocispec.Descriptor
+	// the descriptor the remote tag resolved to, and the config updates
+	// apply to, when fetched or last pushed, against which concurrent pushes
+	// are detected and rebased
+	tagDesc     ocispec.Descriptor
+	baseCfg     oci.ConfigGit
 	man         ocispec.Manifest
 	cfg         oci.ConfigGit
 	refsByLayer map[digest.Digest][]plumbing.Hash
 	newPacks    []ocispec.Descriptor
+	// the tagged manifest and Git manifests pushed since, if tagHistory, and
+	// the history index listing them, against which concurrent pushes are
+	// detected
+	root        ocispec.Descriptor
+	history     []ocispec.Descriptor
+	historyDesc ocispec.Descriptor
+	// commit-graph layer of the fetched manifest, separate from packfile
+	// layers, and its commit-graph, fetched on first use
+	graphDesc ocispec.Descriptor
+	graph     *commitGraph
+	// pack index layers by the digest of the packfile layer they index, and
+	// those added since fetching
+	indexes    map[digest.Digest]ocispec.Descriptor
+	newIndexes []ocispec.Descriptor
+
+	// populated on [model.FetchMetadata]
+	metaMan     ocispec.Manifest
+	metaManDesc ocispec.Descriptor
 
-	// populated on [model.FetchLFS]
+	// LFS files and manifests, if not stored in gt
+	lfsGT Store
+	// split LFS files into content-defined chunks, see [WithLFSChunking]
+	lfsChunking bool
+
+	// populated on [model.FetchLFS], layers are guarded by lfsMu as LFS files
+	// may be pushed concurrently
+	lfsMu      sync.Mutex
 	lfsMan     ocispec.Manifest
 	lfsManDesc ocispec.Descriptor
+	// LFS layers pruned since fetching, never merged back
+	lfsPruned map[digest.Digest]struct{}
 }
 
 func (m *model) Ref() registry.Reference {
 	return m.ref
 }
 
-func (m *model) Fetch(ctx context.Context) (ocispec.Descriptor, error) {
+func (m *model) Fetch(ctx context.Context) (_ ocispec.Descriptor, err error) {
 	if m.fetched {
 		m.cleanupTempManifest() // HACK
 		return m.manDesc, nil
 	}
 
-	slog.DebugContext(ctx, "resolving base manifest descriptor")
-	var err error
-	m.manDesc, err = m.gt.Resolve(ctx, m.ref.String())
-	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("resolving basae manifest descriptor for remote %s: %w", m.ref, err)
+	ctx, span := tracing.Start(ctx, "model.Fetch", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
+	// complete a config only fetch with the manifest references were listed from
+	if !m.cfgFetched {
+		if err := m.resolveManifest(ctx); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	if m.verifyKeys != nil {
+		if err := m.verify(ctx, m.manDesc, m.verifyKeys); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("verifying base manifest: %w", err)
+		}
 	}
 
 	slog.DebugContext(ctx, "fetching base manifest")
@@ -144,21 +394,175 @@ func (m *model) Fetch(ctx context.Context) (ocispec.Descriptor, error) {
 	if err := json.Unmarshal(manRaw, &m.man); err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("decoding base manifest: %w", err)
 	}
+	if m.man.ArtifactType != oci.ArtifactTypeGitManifest && m.man.Config.MediaType != oci.MediaTypeGitConfig {
+		return ocispec.Descriptor{}, fmt.Errorf("%w: %s holds artifact type %q", ErrNotGitManifest, m.ref, artifactType(m.man))
+	}
+	m.man.Layers, m.graphDesc = splitCommitGraph(m.man.Layers)
+	m.man.Layers, m.indexes = splitPackIndexes(m.man.Layers)
+
+	if !m.cfgFetched {
+		if err := m.fetchConfig(ctx, m.man.Config); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	m.sortRefsByLayer()
+	m.fetched = true
+	span.SetAttributes(tracing.Layer(m.manDesc)...)
+
+	return m.manDesc, nil
+}
 
+func (m *model) FetchConfigOnly(ctx context.Context) (_ ocispec.Descriptor, err error) {
+	if m.cfgFetched {
+		return m.manDesc, nil
+	}
+	if m.fetched || m.verifyKeys != nil {
+		// the config is only trusted once the whole manifest is verified
+		return m.Fetch(ctx)
+	}
+
+	ctx, span := tracing.Start(ctx, "model.FetchConfigOnly", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
+	if err := m.resolveManifest(ctx); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	slog.DebugContext(ctx, "fetching base manifest config descriptor")
+	rc, err := m.gt.Fetch(ctx, m.manDesc)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("fetching base manifest: %w", err)
+	}
+	defer rc.Close()
+
+	cfgDesc, err := decodeManifestConfig(rc)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("decoding base manifest: %w", err)
+	}
+	if cfgDesc.MediaType != oci.MediaTypeGitConfig {
+		return ocispec.Descriptor{}, fmt.Errorf("%w: %s holds config media type %q", ErrNotGitManifest, m.ref, cfgDesc.MediaType)
+	}
+
+	if err := m.fetchConfig(ctx, cfgDesc); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	m.cfgFetched = true
+	span.SetAttributes(tracing.Layer(m.manDesc)...)
+
+	return m.manDesc, nil
+}
+
+// pinned returns true if the remote references a manifest by digest rather
+// than by tag, e.g. "reg.example.com/repo@sha256:<hex>".
+func (m *model) pinned() bool {
+	return m.ref.ValidateReferenceAsDigest() == nil
+}
+
+// checkTagged returns [ErrDigestReference] if the remote is pinned, having no
+// tag to update, or [ErrReleaseExists] if the tag is a release, which is
+// immutable.
+func (m *model) checkTagged() error {
+	switch {
+	case m.pinned():
+		return fmt.Errorf("%w: %s cannot be updated, use a tag instead", ErrDigestReference, m.ref)
+	case m.tagDesc.MediaType == ocispec.MediaTypeImageIndex:
+		return fmt.Errorf("%w: %s cannot be updated, releases are immutable", ErrReleaseExists, m.ref)
+	}
+	return nil
+}
+
+// resolveManifest resolves the descriptor of the Git manifest tagged in the
+// remote, following releases to the Git manifest within.
+func (m *model) resolveManifest(ctx context.Context) error {
+	slog.DebugContext(ctx, "resolving base manifest descriptor")
+	var err error
+	m.manDesc, err = m.gt.Resolve(ctx, m.ref.String())
+	if err != nil {
+		return fmt.Errorf("resolving basae manifest descriptor for remote %s: %w", m.ref, err)
+	}
+	m.tagDesc = m.manDesc
+	// a digest references a single Git manifest, rather than the latest in
+	// the history of a tag
+	if m.tagHistory && !m.pinned() {
+		if err := m.resolveHistory(ctx); err != nil {
+			return err
+		}
+	}
+	if m.manDesc.MediaType == ocispec.MediaTypeImageIndex {
+		// a release, fetch the Git manifest within
+		m.manDesc, err = m.releaseManifest(ctx, m.manDesc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchConfig fetches and decodes the Git config of desc.
+func (m *model) fetchConfig(ctx context.Context, desc ocispec.Descriptor) error {
 	slog.DebugContext(ctx, "fetching config")
-	cfgRaw, err := content.FetchAll(ctx, m.gt, m.man.Config)
+	cfgRaw, err := content.FetchAll(ctx, m.gt, desc)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("fetching config: %w", err)
+		return fmt.Errorf("fetching config: %w", err)
 	}
 
 	if err := json.Unmarshal(cfgRaw, &m.cfg); err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("decoding config: %w", err)
+		return fmt.Errorf("decoding config: %w", err)
 	}
+	m.baseCfg = cloneConfig(m.cfg)
 
-	m.sortRefsByLayer()
-	m.fetched = true
+	return nil
+}
 
-	return m.manDesc, nil
+// artifactType returns the artifact type of man, its config media type if
+// unset, as defined by the OCI image spec.
+func artifactType(man ocispec.Manifest) string {
+	if man.ArtifactType != "" {
+		return man.ArtifactType
+	}
+	return man.Config.MediaType
+}
+
+// errManifestConfigNotFound indicates a manifest has no config descriptor.
+var errManifestConfigNotFound = errors.New("manifest config not found")
+
+// decodeManifestConfig decodes the config descriptor of the manifest read
+// from r, reading only until the config is decoded such that the remainder,
+// e.g. the layers of large repositories, is not downloaded. The manifest
+// digest is therefore not verified.
+func decodeManifestConfig(r io.Reader) (ocispec.Descriptor, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("reading manifest: %w", err)
+	}
+	if tok != json.Delim('{') {
+		return ocispec.Descriptor{}, fmt.Errorf("%w: expected manifest object, got %v", errManifestConfigNotFound, tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("reading manifest field: %w", err)
+		}
+		if tok != "config" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return ocispec.Descriptor{}, fmt.Errorf("reading manifest field %v: %w", tok, err)
+			}
+			continue
+		}
+
+		var desc ocispec.Descriptor
+		if err := dec.Decode(&desc); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("decoding manifest config: %w", err)
+		}
+		return desc, nil
+	}
+
+	return ocispec.Descriptor{}, errManifestConfigNotFound
 }
 
 func (m *model) cleanupTempManifest() {
@@ -169,17 +573,9 @@ func (m *model) FetchOrDefault(ctx context.Context) (ocispec.Descriptor, error)
 	slog.DebugContext(ctx, "fetching base manifest or defaulting")
 	manDesc, err := m.Fetch(ctx)
 	switch {
-	case errors.Is(err, errdef.ErrNotFound):
+	case errors.Is(err, errdef.ErrNotFound) && !m.pinned():
 		slog.InfoContext(ctx, "remote does not exist, initializing default git manifest and config")
-		m.cfg = oci.ConfigGit{
-			Heads: make(map[plumbing.ReferenceName]oci.ReferenceInfo, 0),
-			Tags:  make(map[plumbing.ReferenceName]oci.ReferenceInfo, 0),
-		}
-		m.man = ocispec.Manifest{
-			MediaType:    ocispec.MediaTypeImageManifest,
-			ArtifactType: oci.ArtifactTypeGitManifest,
-		}
-		m.refsByLayer = map[digest.Digest][]plumbing.Hash{}
+		m.initEmpty()
 
 		// HACK: temporarily push this so it's available to git-lfs-remote-oci
 		m.cfg.Heads[tempGitManifest] = oci.ReferenceInfo{Commit: time.Now().String()}
@@ -197,33 +593,113 @@ func (m *model) FetchOrDefault(ctx context.Context) (ocispec.Descriptor, error)
 	}
 }
 
-func (m *model) FetchLayer(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+func (m *model) FetchOrEmpty(ctx context.Context) (ocispec.Descriptor, error) {
+	slog.DebugContext(ctx, "fetching base manifest or initializing empty")
+	manDesc, err := m.Fetch(ctx)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound) && !m.pinned():
+		slog.InfoContext(ctx, "remote does not exist, initializing empty git manifest and config")
+		m.initEmpty()
+		m.fetched = true
+		return ocispec.Descriptor{}, nil
+	case err != nil:
+		return ocispec.Descriptor{}, fmt.Errorf("fetching remote metadata: %w", err)
+	default:
+		return manDesc, nil
+	}
+}
+
+// initEmpty initializes an empty Git manifest and config.
+func (m *model) initEmpty() {
+	m.cfg = oci.ConfigGit{
+		Heads: make(map[plumbing.ReferenceName]oci.ReferenceInfo, 0),
+		Tags:  make(map[plumbing.ReferenceName]oci.ReferenceInfo, 0),
+	}
+	m.man = ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: oci.ArtifactTypeGitManifest,
+	}
+	m.refsByLayer = map[digest.Digest][]plumbing.Hash{}
+	m.baseCfg = cloneConfig(m.cfg)
+}
+
+// resetFetched discards the fetched state of the remote, such that the next
+// fetch reads the remote anew.
+func (m *model) resetFetched() {
+	m.fetched = false
+	m.cfgFetched = false
+	m.cfg = oci.ConfigGit{}
+	m.man = ocispec.Manifest{}
+	m.graphDesc = ocispec.Descriptor{}
+	m.graph = nil
+	m.indexes = nil
+}
+
+func (m *model) FetchLayer(ctx context.Context, dgst digest.Digest) (_ io.ReadCloser, err error) {
 	slog.DebugContext(ctx, "fetching packfile OCI layer", slog.String("digest", dgst.String()))
 	// TODO: reverse iter? it is more likely we'll want to fetch newer layers
 	for _, desc := range m.man.Layers {
 		if desc.Digest == dgst {
-			rc, err := m.gt.Fetch(ctx, desc)
+			// the span ends once the layer is read and closed
+			ctx, span := tracing.Start(ctx, "model.FetchLayer", slices.Concat(tracing.Remote(m.ref), tracing.Layer(desc))...)
+			rc, err := m.fetchPackLayer(ctx, desc)
 			if err != nil {
-				return nil, fmt.Errorf("fetching layer: %w", err)
+				err = fmt.Errorf("fetching layer: %w", err)
+				tracing.End(span, &err)
+				return nil, err
 			}
-			return rc, nil
+			return tracing.EndOnClose(&timedReadCloser{ReadCloser: rc, ctx: ctx, desc: desc, start: time.Now()}, span), nil
 		}
 	}
 	return nil, fmt.Errorf("%w: %s", errLayerNotInManifest, dgst.String())
 }
 
 func (m *model) Push(ctx context.Context, referrerUpdates ...ReferrerUpdater) (ocispec.Descriptor, error) {
-	slog.DebugContext(ctx, "pushing git data model")
+	return m.push(ctx, false, referrerUpdates...)
+}
+
+func (m *model) PushAtomic(ctx context.Context, referrerUpdates ...ReferrerUpdater) (ocispec.Descriptor, error) {
+	return m.push(ctx, true, referrerUpdates...)
+}
+
+// push uploads the Git OCI data model, tagging the new manifest once all of
+// its content is in the remote.
+func (m *model) push(ctx context.Context, atomic bool, referrerUpdates ...ReferrerUpdater) (_ ocispec.Descriptor, err error) {
+	ctx, span := tracing.Start(ctx, "model.Push", append(tracing.Remote(m.ref), tracing.KeyAtomic.Bool(atomic), tracing.KeyLayers.Int(len(m.newPacks)))...)
+	defer tracing.End(span, &err)
+
+	if err := m.checkTagged(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	slog.DebugContext(ctx, "pushing git data model", slog.Bool("atomic", atomic))
 	// TODO: Perhaps we could make this more efficient, ONLY in the case where
 	// multiple packfiles are added, if we make a custom oras.CopyGraphOptions to
 	// skip existing packfiles - but that may be tricky as I believe the HEAD
 	// comes before the copy
 
-	p := pool.New().WithErrors().WithContext(ctx)
+	orphaned := m.pruneLayers(ctx)
+
+	fetcher := m.uploadFetcher(ctx)
+	concurrency := m.concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	p := pool.New().WithErrors().WithContext(ctx).WithMaxGoroutines(concurrency)
 	for _, desc := range m.newPacks {
 		slog.DebugContext(ctx, "pushing packfile", "digest", desc.Digest.String())
-		p.Go(func(ctx context.Context) error {
-			rc, err := m.fstore.Fetch(ctx, desc)
+		p.Go(func(ctx context.Context) (err error) {
+			ctx, span := tracing.Start(ctx, "model.PushLayer", tracing.Layer(desc)...)
+			defer tracing.End(span, &err)
+
+			if m.mount(ctx, desc) {
+				span.SetAttributes(tracing.KeyMounted.Bool(true))
+				m.observe(func(o Observer) { o.OnLayerPushed(ctx, desc) })
+				return nil
+			}
+
+			start := time.Now()
+			rc, err := fetcher.Fetch(ctx, desc)
 			if err != nil {
 				return fmt.Errorf("fetching packfile from temporary filestore: %w", err)
 			}
@@ -232,36 +708,113 @@ func (m *model) Push(ctx context.Context, referrerUpdates ...ReferrerUpdater) (o
 			if err := m.gt.Push(ctx, desc, rc); err != nil {
 				return fmt.Errorf("pushing packfile: %w", err)
 			}
+			logutil.Event(ctx, logutil.EventLayerUpload, slog.String("digest", desc.Digest.String()), slog.Int64("size", desc.Size), logutil.Since(start))
+			m.observe(func(o Observer) { o.OnLayerPushed(ctx, desc) })
 
 			return nil
 		})
 
 	}
+	for _, desc := range m.newPackIndexLayers() {
+		slog.DebugContext(ctx, "pushing pack index", "digest", desc.Digest.String())
+		p.Go(func(ctx context.Context) error {
+			rc, err := fetcher.Fetch(ctx, desc)
+			if err != nil {
+				return fmt.Errorf("fetching pack index from temporary filestore: %w", err)
+			}
+			defer rc.Close()
+
+			if err := (existingPusher{m.gt}).Push(ctx, desc, rc); err != nil {
+				return fmt.Errorf("pushing pack index: %w", err)
+			}
+			return nil
+		})
+	}
 	if err := p.Wait(); err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("pushing packfiles: %w", err)
 	}
 
-	cfgRaw, err := json.Marshal(m.cfg)
+	var manDesc, graphDesc ocispec.Descriptor
+	for attempt := 1; ; attempt++ {
+		manDesc, graphDesc, err = m.pushManifest(ctx, atomic, referrerUpdates...)
+		if !errors.Is(err, errTagMoved) {
+			break
+		}
+		if attempt >= maxPushAttempts {
+			return manDesc, fmt.Errorf("%w, after %d attempts", err, attempt)
+		}
+		slog.WarnContext(ctx, "remote updated concurrently, rebasing push", slog.Int("attempt", attempt), slog.String("reason", err.Error()))
+		if err := m.rebase(ctx); err != nil {
+			return manDesc, err
+		}
+		// layers pruned before rebasing may be referenced by the updated remote
+		orphaned = slices.DeleteFunc(orphaned, func(d ocispec.Descriptor) bool { return containsDigest(m.man.Layers, d.Digest) })
+		orphaned = append(orphaned, m.pruneLayers(ctx)...)
+	}
+	if err != nil {
+		return manDesc, err
+	}
+	m.manDesc = manDesc
+	m.tagDesc = manDesc
+	m.graphDesc = graphDesc
+	m.newIndexes = nil
+	m.baseCfg = cloneConfig(m.cfg)
+	span.SetAttributes(tracing.Layer(manDesc)...)
+
+	slog.DebugContext(ctx, "tagged git manifest", slog.String("digest", manDesc.Digest.String()), slog.String("reference", m.ref.String()))
+	logutil.Event(ctx, logutil.EventManifestTag, slog.String("digest", manDesc.Digest.String()), slog.String("reference", m.ref.String()))
+	m.observe(func(o Observer) { o.OnManifestTagged(ctx, manDesc, m.ref) })
+	m.observeRefs(ctx)
+
+	if m.deleteOrphans && len(orphaned) > 0 {
+		m.deleteLayers(ctx, ocispec.Descriptor{}, m.withPackIndexLayers(orphaned))
+	}
+
+	return manDesc, nil
+}
+
+// pushManifest pushes the Git config, commit-graph, and manifest of the
+// current packfile layers, then tags the manifest. Returns the manifest and
+// commit-graph descriptors. Throws errTagMoved if the manifest is not tagged as
+// the remote was updated since it was fetched.
+func (m *model) pushManifest(ctx context.Context, atomic bool, referrerUpdates ...ReferrerUpdater) (ocispec.Descriptor, ocispec.Descriptor, error) {
+	cfgRaw, err := canonicalJSON(m.cfg)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("encoding base manifest config")
+		return ocispec.Descriptor{}, ocispec.Descriptor{}, fmt.Errorf("encoding base manifest config: %w", err)
 	}
 	slog.DebugContext(ctx, "Pushing base config")
-	cfgDesc, err := oras.PushBytes(ctx, m.gt, oci.MediaTypeGitConfig, cfgRaw)
+	// references may be restored to a previous state, e.g. deleting a new branch
+	cfgDesc, err := oras.PushBytes(ctx, existingPusher{m.gt}, oci.MediaTypeGitConfig, cfgRaw)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("pushing base config to repository: %w", err)
+		return ocispec.Descriptor{}, ocispec.Descriptor{}, fmt.Errorf("pushing base config to repository: %w", err)
+	}
+	if len(m.userAnnotations.Config) > 0 {
+		cfgDesc.Annotations = maps.Clone(m.userAnnotations.Config)
+	}
+
+	// pack indexes, then the commit-graph, follow the packfile layers they index
+	layers := m.man.Layers // if a new bundle was made, it was already added to the manifest
+	if indexes := m.packIndexLayers(); len(indexes) > 0 {
+		layers = slices.Concat(layers, indexes)
+	}
+	graphDesc, ok, err := m.pushCommitGraph(ctx)
+	if err != nil {
+		return ocispec.Descriptor{}, ocispec.Descriptor{}, err
+	}
+	if ok {
+		layers = append(slices.Clone(layers), graphDesc)
 	}
 
 	slog.DebugContext(ctx, "Pushing base manifest")
 	manOpts := oras.PackManifestOptions{
-		Layers:              m.man.Layers, // if a new bundle was made, it was already added to the manifest
+		Layers:              layers,
 		ConfigDescriptor:    &cfgDesc,
-		ManifestAnnotations: map[string]string{ocispec.AnnotationCreated: "1970-01-01T00:00:00Z"}, // POSIX epoch
-		// TODO: add user agent/version to annotations?
+		ManifestAnnotations: m.manifestAnnotations(),
 	}
 
-	manDesc, err := oras.PackManifest(ctx, m.gt, oras.PackManifestVersion1_1, oci.ArtifactTypeGitManifest, manOpts)
+	manDesc, err := oras.PackManifest(ctx, existingPusher{m.gt}, oras.PackManifestVersion1_1, oci.ArtifactTypeGitManifest, manOpts)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("packing and pushing base manifest: %w", err)
+		return ocispec.Descriptor{}, ocispec.Descriptor{}, fmt.Errorf("packing and pushing base manifest: %w", err)
 	}
 
 	var updateErrs []error
@@ -272,31 +825,127 @@ func (m *model) Push(ctx context.Context, referrerUpdates ...ReferrerUpdater) (o
 		}
 	}
 	if len(updateErrs) > 0 {
-		return manDesc, fmt.Errorf("referrer updates failed: %w", errors.Join(updateErrs...))
+		return manDesc, graphDesc, fmt.Errorf("referrer updates failed: %w", errors.Join(updateErrs...))
+	}
+
+	if m.signer != nil {
+		// sign before tagging, such that the tag never refers to an unsigned manifest
+		if _, err := m.sign(ctx, m.signer, manDesc); err != nil {
+			return manDesc, graphDesc, fmt.Errorf("signing base manifest: %w", err)
+		}
+	}
+
+	if err := m.updateTag(ctx, manDesc, atomic); err != nil {
+		return manDesc, graphDesc, err
+	}
+
+	return manDesc, graphDesc, nil
+}
+
+// swapTag moves the remote tag from the fetched manifest to manDesc, provided
+// the tag has not moved since it was fetched. The tag is restored to the
+// fetched manifest if tagging fails or cannot be verified.
+func (m *model) swapTag(ctx context.Context, manDesc ocispec.Descriptor) error {
+	prev := m.manDesc
+	current, err := m.gt.Resolve(ctx, m.ref.String())
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		if prev.Digest != "" {
+			return fmt.Errorf("%w: tag %s was removed", ErrConcurrentUpdate, m.ref)
+		}
+	case err != nil:
+		return fmt.Errorf("resolving base manifest before tagging: %w", err)
+	case current.Digest != prev.Digest:
+		return fmt.Errorf("%w: tag %s moved from %s to %s", ErrConcurrentUpdate, m.ref, prev.Digest, current.Digest)
 	}
 
 	if err := m.gt.Tag(ctx, manDesc, m.ref.String()); err != nil {
-		return manDesc, fmt.Errorf("tagging base manifest: %w", err)
+		return errors.Join(fmt.Errorf("tagging base manifest: %w", err), m.rollbackTag(ctx, prev))
 	}
 
-	slog.DebugContext(ctx, "tagged git manifest", slog.String("digest", manDesc.Digest.String()), slog.String("reference", m.ref.String()))
+	got, err := m.gt.Resolve(ctx, m.ref.String())
+	switch {
+	case err != nil:
+		err = fmt.Errorf("verifying base manifest tag: %w", err)
+	case got.Digest != manDesc.Digest:
+		// another client tagged after us, restoring would discard its update
+		return fmt.Errorf("%w: tag %s resolved to %s after tagging %s", ErrConcurrentUpdate, m.ref, got.Digest, manDesc.Digest)
+	default:
+		return nil
+	}
 
-	return manDesc, nil
+	return errors.Join(err, m.rollbackTag(ctx, prev))
+}
+
+// rollbackTag restores the remote tag to the previous manifest, if any.
+func (m *model) rollbackTag(ctx context.Context, prev ocispec.Descriptor) error {
+	if prev.Digest == "" {
+		// nothing to restore, the remote did not exist
+		return nil
+	}
+
+	slog.WarnContext(ctx, "restoring previous git manifest", slog.String("digest", prev.Digest.String()), slog.String("reference", m.ref.String()))
+	// restore even if interrupted, the failed update is otherwise left tagged
+	if err := m.gt.Tag(context.WithoutCancel(ctx), prev, m.ref.String()); err != nil {
+		return fmt.Errorf("restoring previous base manifest tag: %w", err)
+	}
+
+	return nil
 }
 
-func (m *model) AddPack(ctx context.Context, path string, refs ...*plumbing.Reference) (ocispec.Descriptor, error) {
+func (m *model) AddPack(ctx context.Context, path string, base digest.Digest, commits []*object.Commit, refs ...*plumbing.Reference) (_ ocispec.Descriptor, err error) {
+	ctx, span := tracing.Start(ctx, "model.AddPack", tracing.KeyCommits.Int(len(commits)))
+	defer tracing.End(span, &err)
+
 	slog.DebugContext(ctx, "adding packfile to Git OCI manifest", "path", path)
-	// filepath.Base adds an annotation for the filename, without exposing a user's filesystem
-	desc, err := m.fstore.Add(ctx, filepath.Base(path), oci.MediaTypePackLayer, path)
+	desc, err := m.addPackLayer(ctx, path)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("adding packfile to intermediate file store: %w", err)
+		return ocispec.Descriptor{}, err
+	}
+	span.SetAttributes(tracing.Layer(desc)...)
+	hashes := make([]plumbing.Hash, 0, len(commits))
+	for _, c := range commits {
+		hashes = append(hashes, c.Hash)
 	}
+	annotations, err := m.provenanceAnnotations(hashes, refs)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if base != "" {
+		if !slices.ContainsFunc(m.man.Layers, func(d ocispec.Descriptor) bool { return d.Digest == base }) {
+			return ocispec.Descriptor{}, fmt.Errorf("%w: thin packfile base %s", errLayerNotInManifest, base)
+		}
+		annotations[oci.AnnotationPackBase] = base.String()
+	}
+	if index, ok := packCommitsIndex(hashes); ok {
+		annotations[oci.AnnotationPackCommits] = index
+	} else if len(commits) > 0 {
+		slog.DebugContext(ctx, "too many commits to index packfile layer", slog.Int("commits", len(commits)))
+	}
+	desc.Annotations = maps.Clone(desc.Annotations)
+	if desc.Annotations == nil {
+		desc.Annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(desc.Annotations, annotations)
+	if m.packIndexes && base == "" && len(m.encryptionKeys) == 0 {
+		// thin packfiles cannot be indexed alone, indexes of encrypted
+		// packfiles would expose their objects
+		desc, err = m.addPackIndex(ctx, path, desc)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	graph, err := m.commitGraph(ctx)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	graph.add(desc.Digest, commits)
 	m.man.Layers = append(m.man.Layers, desc)
 
 	updateErrs := make([]error, 0)
 	for _, ref := range refs {
 		if err := m.UpdateRef(ctx, ref, desc.Digest); err != nil {
-			updateErrs = append(updateErrs, err)
+			updateErrs = append(updateErrs, &RefError{Ref: ref.Name(), Err: err})
 		}
 	}
 
@@ -320,14 +969,29 @@ func (m *model) UpdateRef(ctx context.Context, ref *plumbing.Reference, ociLayer
 		}
 	}
 
-	switch {
-	case !found:
+	if !found {
 		return fmt.Errorf("%w: %s", errLayerNotInManifest, ociLayer.String())
+	}
+
+	info := oci.ReferenceInfo{Commit: ref.Hash().String(), Layer: ociLayer}
+	switch {
 	case ref.Name().IsBranch():
-		m.cfg.Heads[ref.Name()] = oci.ReferenceInfo{Commit: ref.Hash().String(), Layer: ociLayer}
+		info.Layers = m.historyLayers(ctx, ref.Hash(), ociLayer)
+		m.cfg.Heads[ref.Name()] = info
+		m.refChanged(ref.Name())
 		return nil
 	case ref.Name().IsTag():
-		m.cfg.Tags[ref.Name()] = oci.ReferenceInfo{Commit: ref.Hash().String(), Layer: ociLayer}
+		info.Layers = m.historyLayers(ctx, ref.Hash(), ociLayer)
+		m.cfg.Tags[ref.Name()] = info
+		m.refChanged(ref.Name())
+		return nil
+	case ref.Name().IsNote():
+		if m.cfg.Notes == nil {
+			m.cfg.Notes = make(map[plumbing.ReferenceName]oci.ReferenceInfo, 1)
+		}
+		info.Layers = m.historyLayers(ctx, ref.Hash(), ociLayer)
+		m.cfg.Notes[ref.Name()] = info
+		m.refChanged(ref.Name())
 		return nil
 	default:
 		slog.WarnContext(ctx, "skipping unknown remote reference type", "reference", ref.String())
@@ -337,22 +1001,93 @@ func (m *model) UpdateRef(ctx context.Context, ref *plumbing.Reference, ociLayer
 
 func (m *model) ResolveRef(ctx context.Context, refName plumbing.ReferenceName) (*plumbing.Reference, digest.Digest, error) {
 	slog.DebugContext(ctx, "resolving remote reference", slog.String("reference", refName.String()))
-	// TODO: go-git supports note references, we could too
+	rInfo, err := m.refInfo(refName)
+	if err != nil {
+		return nil, "", err
+	}
+	return plumbing.NewHashReference(refName, plumbing.NewHash(rInfo.Commit)), rInfo.Layer, nil
+}
+
+func (m *model) ResolveRefLayers(ctx context.Context, refName plumbing.ReferenceName) ([]digest.Digest, error) {
+	slog.DebugContext(ctx, "resolving layers of remote reference", slog.String("reference", refName.String()))
+	rInfo, err := m.refInfo(refName)
+	if err != nil {
+		return nil, err
+	}
+	return rInfo.AllLayers(), nil
+}
+
+// refInfo returns the info of a remote reference, HEAD being the default
+// branch.
+func (m *model) refInfo(refName plumbing.ReferenceName) (oci.ReferenceInfo, error) {
 	var ok bool
 	var rInfo oci.ReferenceInfo
 	switch {
+	case refName == plumbing.HEAD:
+		// a symbolic reference to the default branch
+		rInfo, ok = m.cfg.Heads[m.DefaultBranch()]
 	case refName.IsBranch():
 		rInfo, ok = m.cfg.Heads[refName]
 	case refName.IsTag():
 		rInfo, ok = m.cfg.Tags[refName]
+	case refName.IsNote():
+		rInfo, ok = m.cfg.Notes[refName]
 	default:
-		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedReferenceType, refName.String())
+		return oci.ReferenceInfo{}, fmt.Errorf("%w: %s", ErrUnsupportedReferenceType, refName.String())
 	}
 
 	if !ok {
-		return nil, "", fmt.Errorf("%w: %s", ErrReferenceNotFound, refName.String())
+		return oci.ReferenceInfo{}, fmt.Errorf("%w: %s", ErrReferenceNotFound, refName.String())
 	}
-	return plumbing.NewHashReference(refName, plumbing.NewHash(rInfo.Commit)), rInfo.Layer, nil
+	return rInfo, nil
+}
+
+func (m *model) ResolveCommit(ctx context.Context, commit plumbing.Hash) (digest.Digest, error) {
+	slog.DebugContext(ctx, "resolving layer containing commit", slog.String("commit", commit.String()))
+	for _, refs := range []map[plumbing.ReferenceName]oci.ReferenceInfo{m.cfg.Heads, m.cfg.Tags, m.cfg.Notes} {
+		for _, info := range refs {
+			if info.Layer != "" && info.Commit == commit.String() {
+				return info.Layer, nil
+			}
+		}
+	}
+
+	// newest to oldest, as recent commits are most likely to be requested
+	for _, desc := range slices.Backward(m.man.Layers) {
+		if slices.Contains(PackCommits(desc), commit) {
+			return desc.Digest, nil
+		}
+	}
+
+	// layers with many commits are only indexed by the commit-graph
+	graph, err := m.commitGraph(ctx)
+	if err != nil {
+		return "", err
+	}
+	if layer, ok := graph.layerOf(commit, m.man.Layers); ok {
+		return layer, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrCommitNotFound, commit.String())
+}
+
+func (m *model) IsAncestor(ctx context.Context, ancestor, commit plumbing.Hash) (bool, error) {
+	graph, err := m.commitGraph(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := graph.layerOf(commit, m.man.Layers); !ok {
+		return false, fmt.Errorf("%w: %s", ErrCommitNotFound, commit.String())
+	}
+	if graph.isAncestor(ancestor, commit) {
+		return true, nil
+	}
+	if !graph.complete(m.man.Layers) {
+		// the path may pass through commits not indexed
+		return false, fmt.Errorf("%w: ancestry of %s is not fully indexed", ErrCommitNotFound, commit.String())
+	}
+
+	return false, nil
 }
 
 func (m *model) DeleteRef(ctx context.Context, refName plumbing.ReferenceName) error {
@@ -361,18 +1096,66 @@ func (m *model) DeleteRef(ctx context.Context, refName plumbing.ReferenceName) e
 	switch {
 	case refName.IsBranch():
 		delete(m.cfg.Heads, refName)
+		m.refChanged(refName)
 		return nil
 	case refName.IsTag():
 		delete(m.cfg.Tags, refName)
+		m.refChanged(refName)
+		return nil
+	case refName.IsNote():
+		delete(m.cfg.Notes, refName)
+		m.refChanged(refName)
 		return nil
 	default:
 		return fmt.Errorf("%w: %s", ErrUnsupportedReferenceType, refName.String())
 	}
 }
 
-func (m *model) CommitExists(localRepo git.Repository, commit *object.Commit) (digest.Digest, error) {
-	// most efficient with a relatively new base layer containing few refs
-	// TODO: room for optimization?
+func (m *model) SetDefaultBranch(ctx context.Context, refName plumbing.ReferenceName) error {
+	slog.DebugContext(ctx, "setting default branch", slog.String("ref", refName.String()))
+
+	if !refName.IsBranch() {
+		return fmt.Errorf("%w: default branch %s", ErrUnsupportedReferenceType, refName.String())
+	}
+	if _, ok := m.cfg.Heads[refName]; !ok {
+		return fmt.Errorf("%w: %s", ErrReferenceNotFound, refName.String())
+	}
+	m.cfg.DefaultBranch = refName
+
+	return nil
+}
+
+func (m *model) SetPeeled(ctx context.Context, refName plumbing.ReferenceName, target plumbing.Hash) error {
+	slog.DebugContext(ctx, "setting peeled tag", slog.String("ref", refName.String()), slog.String("target", target.String()))
+
+	if !refName.IsTag() {
+		return fmt.Errorf("%w: peeled %s", ErrUnsupportedReferenceType, refName.String())
+	}
+	info, ok := m.cfg.Tags[refName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrReferenceNotFound, refName.String())
+	}
+	info.Peeled = target.String()
+	m.cfg.Tags[refName] = info
+
+	return nil
+}
+
+func (m *model) CommitExists(ctx context.Context, localRepo git.Repository, commit *object.Commit) (digest.Digest, error) {
+	graph, err := m.commitGraph(ctx)
+	if err != nil {
+		return "", err
+	}
+	if layer, ok := graph.layerOf(commit.Hash, m.man.Layers); ok {
+		return layer, nil
+	}
+	if graph.complete(m.man.Layers) {
+		return "", nil
+	}
+
+	// layers pushed without a commit-graph, walk the local history from each
+	// remote reference. Most efficient with a relatively new base layer
+	// containing few refs.
 	for _, layer := range m.man.Layers {
 		for _, c := range m.refsByLayer[layer.Digest] {
 			existingCommit, err := localRepo.CommitObject(c)
@@ -414,6 +1197,13 @@ func (m *model) sortRefsByLayer() {
 			seen[key] = struct{}{}
 		}
 	}
+	for _, info := range m.cfg.Notes {
+		key := info.Layer.String() + info.Commit
+		if _, ok := seen[key]; !ok {
+			m.refsByLayer[info.Layer] = append(m.refsByLayer[info.Layer], plumbing.NewHash(info.Commit))
+			seen[key] = struct{}{}
+		}
+	}
 }
 
 // TODO: these listing functions may be problematic if the remote has not yet been fetched.
@@ -424,6 +1214,13 @@ func (m *model) HeadRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
 	return m.cfg.Heads
 }
 
+func (m *model) DefaultBranch() plumbing.ReferenceName {
+	if _, ok := m.cfg.Heads[m.cfg.DefaultBranch]; !ok {
+		return ""
+	}
+	return m.cfg.DefaultBranch
+}
+
 func (m *model) TagRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
 	if m.cfg.Tags == nil {
 		return map[plumbing.ReferenceName]oci.ReferenceInfo{}
@@ -431,13 +1228,52 @@ func (m *model) TagRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
 	return m.cfg.Tags
 }
 
+func (m *model) NoteRefs() map[plumbing.ReferenceName]oci.ReferenceInfo {
+	if m.cfg.Notes == nil {
+		return map[plumbing.ReferenceName]oci.ReferenceInfo{}
+	}
+	return m.cfg.Notes
+}
+
 func (m *model) FetchLayersReverse(ctx context.Context) iter.Seq2[io.ReadCloser, error] {
 	return func(yield func(io.ReadCloser, error) bool) {
 		for i := len(m.man.Layers) - 1; i >= 0; i-- {
-			rc, err := m.gt.Fetch(ctx, m.man.Layers[i])
+			rc, err := m.fetchPackLayer(ctx, m.man.Layers[i])
 			if !yield(rc, err) {
 				return
 			}
 		}
 	}
 }
+
+func (m *model) Layers() []ocispec.Descriptor {
+	return m.man.Layers
+}
+
+// existingPusher tolerates pushing content that already exists.
+type existingPusher struct {
+	content.Pusher
+}
+
+// Push pushes content, returning nil if it already exists.
+func (p existingPusher) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if err := p.Pusher.Push(ctx, expected, r); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// timedReadCloser emits a layer download event once a packfile layer is closed.
+type timedReadCloser struct {
+	io.ReadCloser
+	ctx   context.Context //nolint:containedctx
+	desc  ocispec.Descriptor
+	start time.Time
+}
+
+// Close closes the layer stream and emits the download event.
+func (t *timedReadCloser) Close() error {
+	logutil.Event(t.ctx, logutil.EventLayerDownload, slog.String("digest", t.desc.Digest.String()), slog.Int64("size", t.desc.Size), logutil.Since(t.start))
+	return t.ReadCloser.Close() //nolint:wrapcheck
+}
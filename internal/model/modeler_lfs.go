@@ -7,19 +7,22 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"path/filepath"
-	"time"
+	"slices"
+	"strconv"
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sourcegraph/conc/pool"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
-	"oras.land/oras-go/v2/registry/remote"
 
 	"github.com/act3-ai/gnoci/internal/progress"
+	"github.com/act3-ai/gnoci/internal/tracing"
 	"github.com/act3-ai/gnoci/pkg/oci"
 )
 
@@ -35,6 +38,40 @@ type ReadOnlyLFSModeler interface {
 	FetchLFSOrDefault(ctx context.Context) (ocispec.Descriptor, error)
 	// FetchLFSLayer fetches an LFS file from a layer in the git-lfs OCI data model.
 	FetchLFSLayer(ctx context.Context, dgst digest.Digest, opts *FetchLFSOptions) (io.ReadCloser, error)
+	// ListLFSObjects lists the LFS files of the fetched git-lfs OCI data
+	// model, in layer order.
+	ListLFSObjects() []LFSObject
+	// PrefetchLFS fetches the LFS files of dgsts, layer digests or object IDs,
+	// all if none are given, into the local cache as stored. Returns the
+	// number of files not previously cached. Throws [ErrCacheDisabled] if no
+	// cache is enabled.
+	PrefetchLFS(ctx context.Context, dgsts ...digest.Digest) (int, error)
+	// Releases lists the releases recorded in the release index, oldest
+	// first.
+	Releases(ctx context.Context) ([]Release, error)
+	// LFSFilesExist reports, for each LFS file descriptor, whether the file
+	// is a layer of the fetched LFS manifest or a blob in the remote, checking
+	// the remote concurrently.
+	LFSFilesExist(ctx context.Context, descs []ocispec.Descriptor) ([]bool, error)
+}
+
+// LFSObject describes an LFS file stored as a layer of the git-lfs OCI data
+// model.
+type LFSObject struct {
+	// Oid is the SHA-256 object ID of the LFS file.
+	Oid string `json:"oid"`
+	// Size is the size of the LFS file in bytes.
+	Size int64 `json:"size"`
+	// Title is the title annotation of the layer, if any.
+	Title string `json:"title,omitempty"`
+	// Digest is the digest of the layer, differing from the object ID if
+	// encrypted.
+	Digest digest.Digest `json:"digest"`
+	// Encrypted is true if the layer is encrypted.
+	Encrypted bool `json:"encrypted,omitempty"`
+	// Chunked is true if the layer is an LFS recipe, listing the chunk
+	// layers of the LFS file.
+	Chunked bool `json:"chunked,omitempty"`
 }
 
 // LFSModeler extends [Modeler] with LFS support.
@@ -47,41 +84,109 @@ type LFSModeler interface {
 	// PushLFSFile adds a git-lfs file as a layer to the git-lfs OCI data model
 	// and pushes it to the remote.
 	PushLFSFile(ctx context.Context, path string, opts *PushLFSOptions) (ocispec.Descriptor, error)
+	// PruneLFS removes the layers of the fetched LFS manifest whose LFS files
+	// are no longer referenced by a pointer file reachable from the current
+	// references, then pushes the LFS manifest. Pruned layers are deleted from
+	// the remote if requested and supported, unless stored in a separate LFS
+	// store or referenced by a release. Returns the pruned LFS files.
+	PruneLFS(ctx context.Context, opts PruneLFSOptions) ([]LFSObject, error)
+	// TagRelease tags an image index of the fetched Git manifest and LFS
+	// manifest, if fetched, as an immutable release, and records it in the
+	// release index. Throws [ErrReleaseExists] if the tag exists. The tag is
+	// not updated atomically, a release tagged concurrently by another client
+	// is overwritten.
+	TagRelease(ctx context.Context, tag string) (ocispec.Descriptor, error)
 }
 
 // NewLFSModeler initializes a new git-lfs modeler.
-func NewLFSModeler(ref registry.Reference, fstore *file.Store, gt oras.GraphTarget) LFSModeler {
-	return &model{
+func NewLFSModeler(ref registry.Reference, fstore *file.Store, gt Store, opts ...Option) LFSModeler {
+	m := &model{
 		ref:    ref,
 		gt:     gt,
 		fstore: fstore,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// NewReadOnlyLFSModeler initializes a git-lfs modeler reading the Git and
+// git-lfs OCI data models of ref from gt, without an intermediate file store.
+func NewReadOnlyLFSModeler(ref registry.Reference, gt Store, opts ...Option) ReadOnlyLFSModeler {
+	return NewLFSModeler(ref, nil, gt, opts...)
+}
+
+// WithLFSStore stores LFS files and manifests in gt rather than alongside the
+// Git manifest, e.g. a repository shared as an LFS cache. LFS manifests refer
+// to the Git manifest by digest, which need not exist in gt.
+func WithLFSStore(gt Store) Option {
+	return func(m *model) {
+		m.lfsGT = gt
+	}
+}
+
+// lfsStore returns the store of LFS files and manifests.
+func (m *model) lfsStore() Store {
+	if m.lfsGT != nil {
+		return m.lfsGT
+	}
+	return m.gt
 }
 
 // ErrLFSManifestNotFound indicates an LFS manifest was not found.
 var ErrLFSManifestNotFound = fmt.Errorf("LFS manifest not found")
 
-func (m *model) FetchLFS(ctx context.Context) (ocispec.Descriptor, error) {
+func (m *model) FetchLFS(ctx context.Context) (_ ocispec.Descriptor, err error) {
+	ctx, span := tracing.Start(ctx, "model.FetchLFS", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
 	slog.DebugContext(ctx, "resolving git manifest referrers", slog.String("subjectDigest", m.manDesc.Digest.String()))
 
-	var err error
-	m.lfsManDesc, err = m.referrer(ctx)
-	if err != nil {
+	// filter by artifact type, other referrers may exist, e.g. signatures
+	referrers, err := m.lfsReferrers(ctx, m.manDesc)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		return ocispec.Descriptor{}, ErrLFSManifestNotFound
+	case err != nil:
 		return ocispec.Descriptor{}, fmt.Errorf("resolving LFS manifest: %w", err)
 	}
+	slog.DebugContext(ctx, "found git manifest referrers", slog.String("referrers", fmt.Sprintf("%v", referrers)))
+	if len(referrers) < 1 {
+		return ocispec.Descriptor{}, ErrLFSManifestNotFound
+	}
 
-	manRaw, err := content.FetchAll(ctx, m.gt, m.lfsManDesc)
+	m.lfsManDesc = referrers[0]
+	m.lfsMan, err = m.fetchLFSManifest(ctx, m.lfsManDesc)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("fetching LFS manifest: %w", err)
+		return ocispec.Descriptor{}, err
 	}
 
-	if err := json.Unmarshal(manRaw, &m.lfsMan); err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("decoding LFS manifest: %w", err)
+	// LFS manifests pushed concurrently may not have been merged yet
+	if err := m.mergeLFSLayers(ctx, referrers[1:]); err != nil {
+		return ocispec.Descriptor{}, err
 	}
+	span.SetAttributes(tracing.Layer(m.lfsManDesc)...)
 
 	return m.lfsManDesc, nil
 }
 
+// fetchLFSManifest fetches and decodes an LFS manifest.
+func (m *model) fetchLFSManifest(ctx context.Context, desc ocispec.Descriptor) (ocispec.Manifest, error) {
+	manRaw, err := content.FetchAll(ctx, m.lfsStore(), desc)
+	if err != nil {
+		return ocispec.Manifest{}, fmt.Errorf("fetching LFS manifest: %w", err)
+	}
+
+	var man ocispec.Manifest
+	if err := json.Unmarshal(manRaw, &man); err != nil {
+		return ocispec.Manifest{}, fmt.Errorf("decoding LFS manifest: %w", err)
+	}
+
+	return man, nil
+}
+
 func (m *model) FetchLFSOrDefault(ctx context.Context) (ocispec.Descriptor, error) {
 	slog.DebugContext(ctx, "fetching LFS manifest or defaulting")
 	manDesc, err := m.FetchLFS(ctx)
@@ -100,52 +205,163 @@ func (m *model) FetchLFSOrDefault(ctx context.Context) (ocispec.Descriptor, erro
 // FetchLFSOptions define optional parameters for fetching LFS files.
 type FetchLFSOptions struct {
 	Progress *ProgressOptions
+	// Offset resumes a partial download at the given byte offset. Range
+	// requests are used if supported by the registry, otherwise the skipped
+	// bytes are downloaded and discarded.
+	Offset int64
 }
 
 func (m *model) FetchLFSLayer(ctx context.Context, dgst digest.Digest, opts *FetchLFSOptions) (io.ReadCloser, error) {
 	slog.DebugContext(ctx, "fetching LFS file", slog.String("digest", dgst.String()))
 
 	for i := len(m.lfsMan.Layers) - 1; i >= 0; i-- {
-		if m.lfsMan.Layers[i].Digest.String() == dgst.String() {
-			rc, err := m.gt.Fetch(ctx, m.lfsMan.Layers[i])
+		if lfsObjectMatches(m.lfsMan.Layers[i], dgst) {
+			// the span ends once the file is read and closed
+			ctx, span := tracing.Start(ctx, "model.FetchLFSLayer", slices.Concat(tracing.Remote(m.ref), tracing.Layer(m.lfsMan.Layers[i]))...)
+			var rc io.ReadCloser
+			var err error
+			if isLFSRecipe(m.lfsMan.Layers[i]) {
+				rc, err = m.fetchLFSChunks(ctx, m.lfsMan.Layers[i])
+			} else {
+				rc, err = m.fetchBlob(ctx, m.lfsStore(), m.lfsMan.Layers[i])
+			}
+			if err == nil && isEncrypted(m.lfsMan.Layers[i].MediaType) {
+				rc, err = m.decryptLayer(rc, m.lfsMan.Layers[i])
+			}
 			if err != nil {
-				return nil, fmt.Errorf("fetching layer: %w", err)
+				err = fmt.Errorf("fetching layer: %w", err)
+				tracing.End(span, &err)
+				return nil, err
+			}
+			rc = tracing.EndOnClose(rc, span)
+			if opts == nil {
+				return rc, nil
 			}
 
-			return progressOrDefault(ctx, opts.Progress, rc), nil
+			if opts.Offset > 0 {
+				if err := resumeAt(ctx, rc, opts.Offset); err != nil {
+					rc.Close()
+					return nil, fmt.Errorf("resuming LFS file download at offset %d: %w", opts.Offset, err)
+				}
+			}
+
+			return progressOrDefault(opts.Progress, rc), nil
 		}
 	}
 
 	return nil, fmt.Errorf("%w: %s", errLayerNotInManifest, dgst.String())
 }
 
-func (m *model) PushLFSManifest(ctx context.Context, subject ocispec.Descriptor) (ocispec.Descriptor, error) {
+// maxLFSManifestAttempts bounds the attempts to push an LFS manifest while
+// other clients concurrently push LFS manifests referring to the same subject.
+const maxLFSManifestAttempts = 5
+
+// PushLFSManifest merges the layers of LFS manifests pushed by other clients
+// since fetching, rather than overwriting them. The LFS referrers of subject
+// are listed before and after pushing; if another client pushed in between,
+// the merge is retried. Merged manifests are then deleted, if supported.
+func (m *model) PushLFSManifest(ctx context.Context, subject ocispec.Descriptor) (_ ocispec.Descriptor, err error) {
+	ctx, span := tracing.Start(ctx, "model.PushLFSManifest", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
+	if err := m.checkTagged(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
 	slog.DebugContext(ctx, "pushing LFS data model")
 
-	if subject.Digest != "" {
-		// TODO: improve error handling
-		// TODO: We don't care it's this struct, only that we have access to Delete
-		r, ok := m.gt.(*remote.Repository)
-		if !ok {
-			slog.WarnContext(ctx, "graph target is not a remote repository")
-		} else {
-			// remove old referrer
-			if err := r.Delete(ctx, m.lfsManDesc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
-				return ocispec.Descriptor{}, fmt.Errorf("deleting old LFS referrer manifest: %w", err)
+	for attempt := 1; ; attempt++ {
+		merged, err := m.lfsReferrers(ctx, subject)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if err := m.mergeLFSLayers(ctx, merged); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+
+		lfsManDesc, err := m.packLFSManifest(ctx, subject)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+
+		current, err := m.lfsReferrers(ctx, subject)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		concurrent := slices.ContainsFunc(current, func(desc ocispec.Descriptor) bool {
+			return desc.Digest != lfsManDesc.Digest && !containsDigest(merged, desc.Digest)
+		})
+		if concurrent {
+			if attempt >= maxLFSManifestAttempts {
+				return ocispec.Descriptor{}, fmt.Errorf("%w: LFS manifest of subject %s, after %d attempts", ErrConcurrentUpdate, subject.Digest, attempt)
+			}
+			slog.WarnContext(ctx, "LFS manifest pushed concurrently, merging again", slog.Int("attempt", attempt))
+			continue
+		}
+
+		stale := merged
+		if m.lfsManDesc.Digest != "" && !containsDigest(stale, m.lfsManDesc.Digest) {
+			stale = append(stale, m.lfsManDesc)
+		}
+		m.deleteLFSManifests(ctx, subject, lfsManDesc, stale)
+		m.lfsManDesc = lfsManDesc
+		span.SetAttributes(tracing.Layer(lfsManDesc)...)
+
+		return lfsManDesc, nil
+	}
+}
+
+// lfsReferrers lists the LFS manifests referring to subject.
+func (m *model) lfsReferrers(ctx context.Context, subject ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	if subject.Digest == "" {
+		return nil, nil
+	}
+
+	referrers, err := listReferrers(ctx, m.lfsStore(), subject, oci.ArtifactTypeLFSManifest)
+	if err != nil {
+		return nil, fmt.Errorf("resolving LFS referrers of %s: %w", subject.Digest, err)
+	}
+
+	return referrers, nil
+}
+
+// mergeLFSLayers adds the layers of LFS manifests missing from the current
+// LFS data model, retaining its layer order.
+func (m *model) mergeLFSLayers(ctx context.Context, manDescs []ocispec.Descriptor) error {
+	for _, desc := range manDescs {
+		if desc.Digest == m.lfsManDesc.Digest {
+			continue
+		}
+
+		man, err := m.fetchLFSManifest(ctx, desc)
+		if err != nil {
+			return err
+		}
+		for _, layer := range man.Layers {
+			if _, ok := m.lfsPruned[layer.Digest]; ok {
+				continue
+			}
+			if !containsDigest(m.lfsMan.Layers, layer.Digest) {
+				slog.DebugContext(ctx, "merging concurrently pushed LFS file", slog.String("digest", layer.Digest.String()), slog.String("manifest", desc.Digest.String()))
+				m.lfsMan.Layers = append(m.lfsMan.Layers, layer)
 			}
 		}
 	}
 
+	return nil
+}
+
+// packLFSManifest packs and pushes the LFS data model as a referrer of subject.
+func (m *model) packLFSManifest(ctx context.Context, subject ocispec.Descriptor) (ocispec.Descriptor, error) {
 	slog.DebugContext(ctx, "pushing LFS manifest", slog.String("subjectDigest", subject.Digest.String()))
 	manOpts := oras.PackManifestOptions{
 		Subject:             &subject,
 		Layers:              m.lfsMan.Layers,
-		ConfigDescriptor:    nil,                                                                  // oras handles for us
-		ManifestAnnotations: map[string]string{ocispec.AnnotationCreated: "1970-01-01T00:00:00Z"}, // POSIX epoch
-		// TODO: add user agent/version to annotations?
+		ConfigDescriptor:    nil, // oras handles for us
+		ManifestAnnotations: map[string]string{ocispec.AnnotationCreated: m.created()},
 	}
 
-	lfsManDesc, err := oras.PackManifest(ctx, m.gt, oras.PackManifestVersion1_1, oci.ArtifactTypeLFSManifest, manOpts)
+	lfsManDesc, err := oras.PackManifest(ctx, m.lfsStore(), oras.PackManifestVersion1_1, oci.ArtifactTypeLFSManifest, manOpts)
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("packing and pushing LFS manifest: %w", err)
 	}
@@ -154,89 +370,393 @@ func (m *model) PushLFSManifest(ctx context.Context, subject ocispec.Descriptor)
 	return lfsManDesc, nil
 }
 
-const defaultProgressInterval = time.Second / 2
+// deleteLFSManifests deletes LFS manifests superseded by lfsManDesc, the LFS
+// manifest of subject, except those of releases and those referring to other
+// Git manifests of snapshots or the tag's history, e.g. the previous Git
+// manifest once snapshotted. Failures are logged, as stale LFS manifests are
+// merged by later pushes.
+func (m *model) deleteLFSManifests(ctx context.Context, subject, lfsManDesc ocispec.Descriptor, stale []ocispec.Descriptor) {
+	stale = slices.DeleteFunc(slices.Clone(stale), func(desc ocispec.Descriptor) bool { return desc.Digest == lfsManDesc.Digest })
+	if len(stale) == 0 {
+		return
+	}
+	d, ok := deleter(m.lfsStore())
+	if !ok {
+		slog.WarnContext(ctx, "remote does not support deletion, old LFS referrers remain")
+		return
+	}
+
+	released, err := m.releasedManifests(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "resolving manifests of releases, old LFS referrers remain", slog.String("error", err.Error()))
+		return
+	}
+	snapshots, err := m.fetchIndex(ctx, m.snapshotIndexTag(), oci.ArtifactTypeGitSnapshots)
+	if err != nil {
+		slog.WarnContext(ctx, "resolving manifests of snapshots, old LFS referrers remain", slog.String("error", err.Error()))
+		return
+	}
+	retained := make(map[digest.Digest]struct{}, len(m.history)+len(snapshots.Manifests))
+	for _, desc := range slices.Concat(m.history, snapshots.Manifests) {
+		retained[desc.Digest] = struct{}{}
+	}
+	delete(retained, subject.Digest)
+
+	for _, desc := range stale {
+		if _, ok := released[desc.Digest]; ok {
+			slog.DebugContext(ctx, "keeping old LFS referrer manifest of a release", slog.String("digest", desc.Digest.String()))
+			continue
+		}
+		if len(retained) > 0 {
+			man, err := m.fetchLFSManifest(ctx, desc)
+			if err != nil && !errors.Is(err, errdef.ErrNotFound) {
+				slog.WarnContext(ctx, "resolving subject of old LFS referrer manifest, keeping it", slog.String("digest", desc.Digest.String()), slog.String("error", err.Error()))
+				continue
+			}
+			if _, ok := retained[subjectDigest(man)]; ok {
+				slog.DebugContext(ctx, "keeping old LFS referrer manifest of a snapshot or history", slog.String("digest", desc.Digest.String()))
+				continue
+			}
+		}
+		if err := d.Delete(ctx, desc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+			slog.WarnContext(ctx, "deleting old LFS referrer manifest", slog.String("digest", desc.Digest.String()), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// containsDigest returns true if descs contains a descriptor with dgst.
+func containsDigest(descs []ocispec.Descriptor, dgst digest.Digest) bool {
+	return slices.ContainsFunc(descs, func(desc ocispec.Descriptor) bool {
+		return desc.Digest == dgst
+	})
+}
+
+// subjectDigest returns the digest of the subject of man, if any.
+func subjectDigest(man ocispec.Manifest) digest.Digest {
+	if man.Subject == nil {
+		return ""
+	}
+	return man.Subject.Digest
+}
 
 // PushLFSOptions define optional parameters for pushing LFS files.
 type PushLFSOptions struct {
 	Progress *ProgressOptions
+	// ChunkThreshold is the size, in bytes, above which LFS files are pushed
+	// with chunked uploads rather than a single monolithic upload. Defaults
+	// to 1 GiB.
+	ChunkThreshold int64
+	// ChunkSize is the size, in bytes, of each chunk of a chunked upload.
+	// Defaults to 64 MiB.
+	ChunkSize int64
+	// Oid and Size, if set, are the SHA-256 object ID and size of the LFS
+	// file, checked to exist in the remote before the file is read. Unused if
+	// LFS files are encrypted.
+	Oid  string
+	Size int64
 }
 
-// ProgressOptions allow for enabling and customizing LFS file push progress info.
+// ProgressOptions allow for enabling LFS file transfer progress info.
 type ProgressOptions struct {
-	// Info enable receiving status information on how many bytes has been pushed.
-	Info chan progress.Progress
-	// ProgressInterval is the sending tick rate for progress updates.
-	// Noop if Info is not set.
-	Interval time.Duration
+	// Reporter, if set, reports the bytes transferred. It is the caller's
+	// responsibility to stop it, see [progress.Reporter.Stop].
+	Reporter *progress.Reporter
 }
 
-func (m *model) PushLFSFile(ctx context.Context, path string, opts *PushLFSOptions) (ocispec.Descriptor, error) {
+func (m *model) PushLFSFile(ctx context.Context, path string, opts *PushLFSOptions) (_ ocispec.Descriptor, err error) {
+	ctx, span := tracing.Start(ctx, "model.PushLFSFile", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
 	slog.DebugContext(ctx, "pushing and adding LFS file to data model", slog.String("oid", filepath.Base(path)))
 
+	// identified by its object ID, the LFS file is only read if not yet pushed
+	if desc, ok := lfsFileDescriptor(path, opts); ok && len(m.encryptionKeys) == 0 {
+		if layer, ok, err := m.lfsLayer(desc); ok || err != nil {
+			return layer, err
+		}
+		exists, err := m.lfsFileExists(ctx, desc)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if exists {
+			slog.DebugContext(ctx, "LFS file exists in remote, skipping upload", slog.String("digest", desc.Digest.String()))
+			span.SetAttributes(tracing.Layer(desc)...)
+			return m.addLFSLayer(desc), nil
+		}
+	}
+
+	chunked, err := m.chunksLFSFile(path)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if chunked {
+		desc, err := m.pushLFSChunks(ctx, path, opts)
+		if err == nil {
+			span.SetAttributes(tracing.Layer(desc)...)
+		}
+		return desc, err
+	}
+
 	// adding to an OCI file store:
 	// 1. provides a descriptor needed on push.
 	// 2. if the file already exists in the oci data model ensure no corruption.
 	// 3. safer, in the case the file is removed before we can read.
-	newDesc, err := m.fstore.Add(ctx, filepath.Base(path), oci.MediaTypeLFSLayer, path)
+	newDesc, err := m.addBlob(ctx, filepath.Base(path), oci.MediaTypeLFSLayer, path)
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("adding LFS file to intermediate fstore: %w", err)
 	}
+	if newDesc.Digest.Algorithm() != LFSOidAlgorithm {
+		oid, err := lfsOid(path, opts)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		newDesc = withAnnotations(newDesc, map[string]string{oci.AnnotationLFSOid: oid.String()})
+	}
+	span.SetAttributes(tracing.Layer(newDesc)...)
 
 	// stay idempotent if the same LFS file is added multiple times.
-	for _, desc := range m.lfsMan.Layers {
-		if desc.Digest.Encoded() == newDesc.Digest.Encoded() {
-			// unlikely hash collision?
-			if desc.Size != newDesc.Size {
-				return ocispec.Descriptor{}, fmt.Errorf("found an existing LFS object digest with different size: digest = %s, existing file size = %d, got file size = %d", desc.Digest, desc.Size, newDesc.Size)
-			}
-			return desc, nil
+	if desc, ok, err := m.lfsLayer(newDesc); ok || err != nil {
+		return desc, err
+	}
+
+	if len(m.encryptionKeys) > 0 {
+		encPath, err := m.addEncryptedLFSFile(ctx, path, &newDesc)
+		if err != nil {
+			return ocispec.Descriptor{}, err
 		}
+		defer func() {
+			if err := os.Remove(encPath); err != nil {
+				slog.ErrorContext(ctx, "removing encrypted LFS file", slog.String("error", err.Error()))
+			}
+		}()
 	}
 
-	rc, err := m.fstore.Fetch(ctx, newDesc)
+	rc, err := m.fetchAdded(ctx, newDesc)
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("fetching LFS file from temporary filestore: %w", err)
 	}
-	rc = progressOrDefault(ctx, opts.Progress, rc)
+	rc = progressOrDefault(opts.Progress, rc)
 	defer rc.Close()
 
-	if err := m.gt.Push(ctx, newDesc, rc); err != nil {
+	if err := m.pushBlob(ctx, newDesc, rc, opts); err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("pushing LFS file: %w", err)
 	}
+	m.observe(func(o Observer) { o.OnLFSUploaded(ctx, newDesc) })
 
-	m.lfsMan.Layers = append(m.lfsMan.Layers, newDesc)
-	return newDesc, nil
+	return m.addLFSLayer(newDesc), nil
 }
 
-// referrer finds an LFS manifest referrer, if one exists. Throws [ErrLFSManifestNotFound]
-// if no referrer LFS manifest exists.
-func (m *model) referrer(ctx context.Context) (ocispec.Descriptor, error) {
-	referrers, err := registry.Referrers(ctx, m.gt, m.manDesc, "") // TODO: filter me, in case other referrers exist, e.g. a signed image
-	slog.DebugContext(ctx, "found git manifest referrers", slog.String("referrers", fmt.Sprintf("%v", referrers)))
+// addLFSLayer adds desc to the layers of the LFS manifest, unless it exists.
+func (m *model) addLFSLayer(desc ocispec.Descriptor) ocispec.Descriptor {
+	m.lfsMu.Lock()
+	defer m.lfsMu.Unlock()
+	if !slices.ContainsFunc(m.lfsMan.Layers, func(layer ocispec.Descriptor) bool { return layer.Digest == desc.Digest }) {
+		m.lfsMan.Layers = append(m.lfsMan.Layers, desc)
+	}
+	return desc
+}
+
+// lfsFileDescriptor returns the layer descriptor of the LFS file at path, as
+// added to the intermediate file store, if identified by opts.
+func lfsFileDescriptor(path string, opts *PushLFSOptions) (ocispec.Descriptor, bool) {
+	if opts == nil || opts.Oid == "" {
+		return ocispec.Descriptor{}, false
+	}
+	dgst := digest.NewDigestFromEncoded(LFSOidAlgorithm, opts.Oid)
+	if err := dgst.Validate(); err != nil {
+		return ocispec.Descriptor{}, false
+	}
+
+	return ocispec.Descriptor{
+		MediaType: oci.MediaTypeLFSLayer,
+		Digest:    dgst,
+		Size:      opts.Size,
+		Annotations: map[string]string{
+			ocispec.AnnotationTitle: filepath.Base(path),
+		},
+	}, true
+}
+
+// lfsOid returns the OID of the LFS file at path, from opts if set.
+func lfsOid(path string, opts *PushLFSOptions) (digest.Digest, error) {
+	if opts != nil && opts.Oid != "" {
+		dgst := digest.NewDigestFromEncoded(LFSOidAlgorithm, opts.Oid)
+		if err := dgst.Validate(); err != nil {
+			return "", fmt.Errorf("invalid LFS oid: %w", err)
+		}
+		return dgst, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening LFS file: %w", err)
+	}
+	defer f.Close()
+
+	dgst, err := LFSOidAlgorithm.FromReader(f)
+	if err != nil {
+		return "", fmt.Errorf("digesting LFS file: %w", err)
+	}
+	return dgst, nil
+}
+
+func (m *model) LFSFilesExist(ctx context.Context, descs []ocispec.Descriptor) (_ []bool, err error) {
+	ctx, span := tracing.Start(ctx, "model.LFSFilesExist", tracing.Remote(m.ref)...)
+	defer tracing.End(span, &err)
+
+	concurrency := m.concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	exists := make([]bool, len(descs))
+	p := pool.New().WithErrors().WithContext(ctx).WithMaxGoroutines(concurrency)
+	for i, desc := range descs {
+		p.Go(func(ctx context.Context) error {
+			var err error
+			exists[i], err = m.lfsFileExists(ctx, desc)
+			return err
+		})
+	}
+	if err := p.Wait(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return exists, nil
+}
+
+// lfsFileExists returns true if the LFS file of desc is a layer of the fetched
+// LFS manifest, or a blob in the remote.
+func (m *model) lfsFileExists(ctx context.Context, desc ocispec.Descriptor) (bool, error) {
+	if _, ok, err := m.lfsLayer(desc); ok || err != nil {
+		return ok && err == nil, err
+	}
+
+	exists, err := m.lfsStore().Exists(ctx, desc)
+	if err != nil {
+		return false, fmt.Errorf("checking existence of LFS file %s: %w", desc.Digest, err)
+	}
+	return exists, nil
+}
+
+// addEncryptedLFSFile adds an encrypted copy of the LFS file at path to the
+// intermediate file store, replacing desc with that of the encrypted layer.
+// The returned temporary file is to be removed once pushed.
+func (m *model) addEncryptedLFSFile(ctx context.Context, path string, desc *ocispec.Descriptor) (_ string, err error) {
+	f, err := os.CreateTemp("", "gnoci-lfs-*.enc")
+	if err != nil {
+		return "", fmt.Errorf("creating encrypted LFS file: %w", err)
+	}
+	encPath := f.Name()
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, os.Remove(encPath))
+		}
+	}()
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("creating encrypted LFS file: %w", err)
+	}
+
+	mediaType, annotations, err := m.encryptLayer(path, encPath, oci.MediaTypeLFSLayer)
+	if err != nil {
+		return "", fmt.Errorf("encrypting LFS file: %w", err)
+	}
+	annotations[oci.AnnotationEncryptionDigest] = desc.Digest.String()
+	if oid, ok := desc.Annotations[oci.AnnotationLFSOid]; ok {
+		annotations[oci.AnnotationLFSOid] = oid
+	}
+
+	encDesc, err := m.addBlob(ctx, filepath.Base(path)+".enc", mediaType, encPath)
+	if err != nil {
+		return "", fmt.Errorf("adding encrypted LFS file to intermediate fstore: %w", err)
+	}
+	*desc = withAnnotations(encDesc, annotations)
 
-	// we expect one LFS manifest referrer
+	return encPath, nil
+}
+
+func (m *model) ListLFSObjects() []LFSObject {
+	m.lfsMu.Lock()
+	defer m.lfsMu.Unlock()
+
+	objs := make([]LFSObject, 0, len(m.lfsMan.Layers))
+	for _, desc := range m.lfsMan.Layers {
+		if isLFSChunk(desc) {
+			continue
+		}
+		objs = append(objs, lfsObjectOf(desc))
+	}
+
+	return objs
+}
+
+// lfsObjectOf describes the LFS file of an LFS layer.
+func lfsObjectOf(desc ocispec.Descriptor) LFSObject {
+	obj := LFSObject{
+		Oid:       lfsObjectDigest(desc).Encoded(),
+		Size:      desc.Size,
+		Title:     desc.Annotations[ocispec.AnnotationTitle],
+		Digest:    desc.Digest,
+		Encrypted: isEncrypted(desc.MediaType),
+		Chunked:   isLFSRecipe(desc),
+	}
 	switch {
-	case len(referrers) < 1:
-		return ocispec.Descriptor{}, ErrLFSManifestNotFound
-	case len(referrers) > 1:
-		return ocispec.Descriptor{}, fmt.Errorf("expected 1 LFS referrer, got %d", len(referrers)) // should never hit
-	case err != nil:
-		return ocispec.Descriptor{}, fmt.Errorf("resolving commit manifest predecessors: %w", err)
+	case obj.Encrypted:
+		obj.Size = plaintextSize(desc.Size)
+	case obj.Chunked:
+		obj.Size, _ = strconv.ParseInt(desc.Annotations[oci.AnnotationLFSSize], 10, 64)
 	}
-	return referrers[0], nil
+	return obj
 }
 
-// progressOrDefault returns a [progress.Ticker] if ProgressOptions have it enabled.
-func progressOrDefault(ctx context.Context, opts *ProgressOptions, r io.ReadCloser) io.ReadCloser {
-	if opts != nil && opts.Info != nil {
-		d := defaultProgressInterval
-		if opts.Interval != 0 {
-			d = opts.Interval
+// lfsLayer returns the LFS layer with the digest of newDesc, if it exists.
+func (m *model) lfsLayer(newDesc ocispec.Descriptor) (ocispec.Descriptor, bool, error) {
+	m.lfsMu.Lock()
+	defer m.lfsMu.Unlock()
+
+	for _, desc := range m.lfsMan.Layers {
+		if lfsObjectMatches(desc, lfsObjectDigest(newDesc)) {
+			layerSize, size := desc.Size, newDesc.Size
+			switch {
+			case isEncrypted(desc.MediaType):
+				size = encryptedSize(size)
+			case isLFSRecipe(desc):
+				layerSize = lfsObjectOf(desc).Size
+			}
+			// unlikely hash collision?
+			if layerSize != size {
+				return ocispec.Descriptor{}, true, fmt.Errorf("found an existing LFS object digest with different size: digest = %s, existing layer size = %d, want layer size = %d", desc.Digest, layerSize, size)
+			}
+			return desc, true, nil
+		}
+	}
+
+	return ocispec.Descriptor{}, false, nil
+}
+
+// resumeAt advances rc to offset. If rc supports seeking, e.g. a blob fetched
+// from a registry supporting range requests, subsequent reads begin at offset.
+// Otherwise, bytes up to offset are read and discarded.
+func resumeAt(ctx context.Context, rc io.ReadCloser, offset int64) error {
+	if seeker, ok := rc.(io.Seeker); ok {
+		slog.DebugContext(ctx, "resuming download with range request", slog.Int64("offset", offset))
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("seeking to offset: %w", err)
 		}
+		return nil
+	}
+
+	slog.DebugContext(ctx, "range requests not supported, discarding previously downloaded bytes", slog.Int64("offset", offset))
+	if _, err := io.CopyN(io.Discard, rc, offset); err != nil {
+		return fmt.Errorf("discarding bytes: %w", err)
+	}
+	return nil
+}
 
-		pReader := progress.NewEvalReadCloser(r)
-		progress.NewTicker(ctx, pReader, d, opts.Info)
-		return pReader
+// progressOrDefault returns r tracked by the [progress.Reporter] of opts, if
+// enabled.
+func progressOrDefault(opts *ProgressOptions, r io.ReadCloser) io.ReadCloser {
+	if opts != nil && opts.Reporter != nil {
+		return opts.Reporter.Track(r)
 	}
 	return r
 }
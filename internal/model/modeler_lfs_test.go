@@ -2,12 +2,14 @@ package model
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/act3-ai/gnoci/internal/progress"
 	"github.com/act3-ai/gnoci/pkg/oci"
@@ -299,6 +301,36 @@ func Test_model_FetchLFSLayer(t *testing.T) {
 		err = fstore.Close()
 		assert.NoError(t, err)
 	})
+
+	t.Run("Success - Offset", func(t *testing.T) {
+		gt := memory.New()
+		gitManifest, gitConfig, lfsManifest := setupRemoteWithLFS(t, gt)
+
+		m := &model{
+			ref:         testRemote,
+			gt:          gt,
+			fetched:     true,
+			manDesc:     *lfsManifest.Subject,
+			man:         gitManifest,
+			cfg:         gitConfig,
+			refsByLayer: map[digest.Digest][]plumbing.Hash{},
+		}
+
+		_, err := m.FetchLFS(t.Context())
+		assert.NoError(t, err)
+
+		layer := lfsManifest.Layers[0]
+		expected, err := content.FetchAll(t.Context(), gt, layer)
+		assert.NoError(t, err)
+
+		rc, err := m.FetchLFSLayer(t.Context(), layer.Digest, &FetchLFSOptions{Offset: 2})
+		assert.NoError(t, err)
+		defer rc.Close()
+
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.Equal(t, expected[2:], got)
+	})
 }
 
 func Test_model_PushLFSManifest(t *testing.T) {
@@ -373,6 +405,90 @@ func Test_model_PushLFSManifest(t *testing.T) {
 		err = fstore.Close()
 		assert.NoError(t, err)
 	})
+
+	t.Run("Merge Concurrent Push", func(t *testing.T) {
+		// another client pushed an LFS manifest after ours was fetched
+		gt := memory.New()
+		gitManifest, gitConfig, concurrentMan := setupRemoteWithLFS(t, gt)
+		gitManDesc, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+
+		ours := ocispec.Descriptor{MediaType: oci.MediaTypeLFSLayer, Digest: digest.FromString("ours"), Size: 4}
+		err = gt.Push(t.Context(), ours, strings.NewReader("ours"))
+		assert.NoError(t, err)
+
+		m := &model{
+			ref:     testRemote,
+			gt:      gt,
+			fetched: true,
+			man:     gitManifest,
+			manDesc: gitManDesc,
+			cfg:     gitConfig,
+			lfsMan:  ocispec.Manifest{Layers: []ocispec.Descriptor{ours}},
+		}
+
+		lfsManDesc, err := m.PushLFSManifest(t.Context(), gitManDesc)
+		assert.NoError(t, err)
+
+		lfsMan, err := m.fetchLFSManifest(t.Context(), lfsManDesc)
+		assert.NoError(t, err)
+		assert.Equal(t, []ocispec.Descriptor{ours, concurrentMan.Layers[0]}, lfsMan.Layers)
+	})
+
+	t.Run("Retry Concurrent Push", func(t *testing.T) {
+		gt := memory.New()
+		gitManifest, gitConfig := setupRemote(t, gt)
+		gitManDesc, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		pushLFSConfig(t, gt)
+
+		ours := ocispec.Descriptor{MediaType: oci.MediaTypeLFSLayer, Digest: digest.FromString("ours"), Size: 4}
+		err = gt.Push(t.Context(), ours, strings.NewReader("ours"))
+		assert.NoError(t, err)
+		theirs := pushLFSLayer(t, gt)
+
+		// another client pushes while our first LFS manifest is pushed
+		racing := &racingTarget{GraphTarget: gt, race: func() {
+			pushLFSManifest(t, gt, &gitManDesc, []ocispec.Descriptor{theirs})
+		}}
+		m := &model{
+			ref:     testRemote,
+			gt:      racing,
+			fetched: true,
+			man:     gitManifest,
+			manDesc: gitManDesc,
+			cfg:     gitConfig,
+			lfsMan:  ocispec.Manifest{Layers: []ocispec.Descriptor{ours}},
+		}
+
+		lfsManDesc, err := m.PushLFSManifest(t.Context(), gitManDesc)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, racing.manifests)
+
+		lfsMan, err := m.fetchLFSManifest(t.Context(), lfsManDesc)
+		assert.NoError(t, err)
+		assert.Equal(t, []ocispec.Descriptor{ours, theirs}, lfsMan.Layers)
+	})
+}
+
+// racingTarget calls race once, when the first manifest is pushed.
+type racingTarget struct {
+	oras.GraphTarget
+	race      func()
+	manifests int
+}
+
+func (r *racingTarget) Push(ctx context.Context, desc ocispec.Descriptor, rd io.Reader) error {
+	if err := r.GraphTarget.Push(ctx, desc, rd); err != nil {
+		return err //nolint:wrapcheck
+	}
+	if desc.MediaType == ocispec.MediaTypeImageManifest {
+		r.manifests++
+		if r.manifests == 1 {
+			r.race()
+		}
+	}
+	return nil
 }
 
 func Test_model_PushLFSFile(t *testing.T) {
@@ -511,22 +627,179 @@ func Test_model_PushLFSFile(t *testing.T) {
 		assert.Equal(t, 1, len(m.lfsMan.Layers))
 
 	})
+
+	t.Run("Exists In Remote", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+
+		lfsFileContents := "example file contents"
+		existing := content.NewDescriptorFromBytes(oci.MediaTypeLFSLayer, []byte(lfsFileContents))
+		err := gt.Push(t.Context(), existing, strings.NewReader(lfsFileContents))
+		assert.NoError(t, err)
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		defer fstore.Close()
+
+		m := NewLFSModeler(testRemote, fstore, gt).(*model)
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = m.FetchLFSOrDefault(t.Context())
+		assert.NoError(t, err)
+
+		// never read, as the file exists in the remote
+		lfsFilePath := filepath.Join(t.TempDir(), existing.Digest.Encoded())
+		lfsDesc, err := m.PushLFSFile(t.Context(), lfsFilePath, &PushLFSOptions{Oid: existing.Digest.Encoded(), Size: existing.Size})
+		assert.NoError(t, err)
+		assert.Equal(t, existing.Digest, lfsDesc.Digest)
+		assert.Equal(t, filepath.Base(lfsFilePath), lfsDesc.Annotations[ocispec.AnnotationTitle])
+		assert.Equal(t, []ocispec.Descriptor{lfsDesc}, m.lfsMan.Layers)
+	})
+
+	t.Run("SHA-512", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		defer fstore.Close()
+
+		m := NewLFSModeler(testRemote, fstore, gt, WithDigestAlgorithm(digest.SHA512)).(*model)
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = m.FetchLFSOrDefault(t.Context())
+		assert.NoError(t, err)
+
+		lfsFileContents := "example file contents"
+		lfsFilePath := filepath.Join(t.TempDir(), "foolfs")
+		assert.NoError(t, os.WriteFile(lfsFilePath, []byte(lfsFileContents), 0o644))
+		oid := digest.FromString(lfsFileContents)
+
+		lfsDesc, err := m.PushLFSFile(t.Context(), lfsFilePath, &PushLFSOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, ocispec.Descriptor{
+			MediaType: oci.MediaTypeLFSLayer,
+			Digest:    digest.SHA512.FromString(lfsFileContents),
+			Size:      int64(len(lfsFileContents)),
+			Annotations: map[string]string{
+				ocispec.AnnotationTitle: filepath.Base(lfsFilePath),
+				oci.AnnotationLFSOid:    oid.String(),
+			},
+		}, lfsDesc)
+		assert.Equal(t, oid.Encoded(), m.ListLFSObjects()[0].Oid)
+
+		// identified by its oid, regardless of the layer digest
+		_, err = m.PushLFSFile(t.Context(), lfsFilePath, &PushLFSOptions{Oid: oid.Encoded(), Size: lfsDesc.Size})
+		assert.NoError(t, err)
+		assert.Len(t, m.lfsMan.Layers, 1)
+
+		for _, dgst := range []digest.Digest{oid, lfsDesc.Digest} {
+			rc, err := m.FetchLFSLayer(t.Context(), dgst, nil)
+			assert.NoError(t, err)
+			raw, err := io.ReadAll(rc)
+			assert.NoError(t, err)
+			assert.NoError(t, rc.Close())
+			assert.Equal(t, lfsFileContents, string(raw))
+		}
+	})
+}
+
+func Test_model_LFSFilesExist(t *testing.T) {
+	gt := memory.New()
+	setupRemote(t, gt)
+
+	pushed := content.NewDescriptorFromBytes(oci.MediaTypeLFSLayer, []byte("pushed"))
+	err := gt.Push(t.Context(), pushed, strings.NewReader("pushed"))
+	assert.NoError(t, err)
+	layer := content.NewDescriptorFromBytes(oci.MediaTypeLFSLayer, []byte("layer"))
+	missing := content.NewDescriptorFromBytes(oci.MediaTypeLFSLayer, []byte("missing"))
+
+	m := NewLFSModeler(testRemote, nil, gt).(*model)
+	m.lfsMan.Layers = []ocispec.Descriptor{layer}
+
+	exists, err := m.LFSFilesExist(t.Context(), []ocispec.Descriptor{pushed, layer, missing})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false}, exists)
+
+	t.Run("Size Mismatch", func(t *testing.T) {
+		_, err := m.LFSFilesExist(t.Context(), []ocispec.Descriptor{{MediaType: layer.MediaType, Digest: layer.Digest, Size: layer.Size + 1}})
+		assert.Error(t, err)
+	})
+}
+
+func Test_model_WithLFSStore(t *testing.T) {
+	gt := memory.New()
+	setupRemote(t, gt)
+	lfsGT := memory.New()
+
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	defer fstore.Close()
+
+	lfsFilePath := filepath.Join(t.TempDir(), "foolfs")
+	err = os.WriteFile(lfsFilePath, []byte("example file contents"), 0644)
+	assert.NoError(t, err)
+
+	m := NewLFSModeler(testRemote, fstore, gt, WithLFSStore(lfsGT))
+	subject, err := m.Fetch(t.Context())
+	assert.NoError(t, err)
+	_, err = m.FetchLFSOrDefault(t.Context())
+	assert.NoError(t, err)
+
+	lfsDesc, err := m.PushLFSFile(t.Context(), lfsFilePath, &PushLFSOptions{})
+	assert.NoError(t, err)
+	lfsManDesc, err := m.PushLFSManifest(t.Context(), subject)
+	assert.NoError(t, err)
+
+	// LFS content is only stored in the LFS store
+	for _, desc := range []ocispec.Descriptor{lfsDesc, lfsManDesc} {
+		exists, err := lfsGT.Exists(t.Context(), desc)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		exists, err = gt.Exists(t.Context(), desc)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	}
+
+	// discovered by the digest of the Git manifest
+	other := NewLFSModeler(testRemote, fstore, gt, WithLFSStore(lfsGT))
+	_, err = other.Fetch(t.Context())
+	assert.NoError(t, err)
+	got, err := other.FetchLFS(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, lfsManDesc, got)
+
+	rc, err := other.FetchLFSLayer(t.Context(), lfsDesc.Digest, nil)
+	assert.NoError(t, err)
+	defer rc.Close()
+	raw, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, "example file contents", string(raw))
 }
 
 func Test_progressOrDefault(t *testing.T) {
 	t.Run("Progress Enabled", func(t *testing.T) {
-		ch := make(chan progress.Progress)
+		var got progress.Progress
+		reporter := progress.NewReporter(t.Context(), time.Hour, func(p progress.Progress) error {
+			got = p
+			return nil
+		})
 		opts := ProgressOptions{
-			Info: ch,
+			Reporter: reporter,
 		}
 
 		rc := io.NopCloser(strings.NewReader("foo"))
 
-		gotRC := progressOrDefault(t.Context(), &opts, rc)
+		gotRC := progressOrDefault(&opts, rc)
 		assert.NotNil(t, gotRC)
 
 		_, ok := gotRC.(progress.EvalReadCloser)
 		assert.True(t, ok)
+
+		_, err := io.ReadAll(gotRC)
+		assert.NoError(t, err)
+		assert.NoError(t, reporter.Stop())
+		assert.Equal(t, progress.Progress{Total: 3, Delta: 3}, got)
 	})
 
 	t.Run("Progress Disabled", func(t *testing.T) {
@@ -534,10 +807,57 @@ func Test_progressOrDefault(t *testing.T) {
 
 		rc := io.NopCloser(strings.NewReader("foo"))
 
-		gotRC := progressOrDefault(t.Context(), &opts, rc)
+		gotRC := progressOrDefault(&opts, rc)
 		assert.NotNil(t, gotRC)
 
 		_, ok := gotRC.(progress.EvalReadCloser)
 		assert.False(t, ok)
 	})
 }
+
+// seekCloser is an [io.ReadSeekCloser] recording seeks.
+type seekCloser struct {
+	*bytes.Reader
+	seeked bool
+}
+
+func (s *seekCloser) Seek(offset int64, whence int) (int64, error) {
+	s.seeked = true
+	return s.Reader.Seek(offset, whence)
+}
+
+func (s *seekCloser) Close() error {
+	return nil
+}
+
+func Test_resumeAt(t *testing.T) {
+	t.Run("Seek", func(t *testing.T) {
+		rc := &seekCloser{Reader: bytes.NewReader([]byte("foobar"))}
+
+		err := resumeAt(t.Context(), rc, 3)
+		assert.NoError(t, err)
+		assert.True(t, rc.seeked)
+
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.Equal(t, "bar", string(got))
+	})
+
+	t.Run("Discard", func(t *testing.T) {
+		rc := io.NopCloser(strings.NewReader("foobar"))
+
+		err := resumeAt(t.Context(), rc, 3)
+		assert.NoError(t, err)
+
+		got, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.Equal(t, "bar", string(got))
+	})
+
+	t.Run("Offset Exceeds Size", func(t *testing.T) {
+		rc := io.NopCloser(strings.NewReader("foo"))
+
+		err := resumeAt(t.Context(), rc, 4)
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
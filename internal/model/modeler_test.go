@@ -3,25 +3,39 @@ package model
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content/file"
 	"oras.land/oras-go/v2/content/memory"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/credentials"
 
+	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/internal/tracing"
 	"github.com/act3-ai/gnoci/pkg/oci"
+	"github.com/act3-ai/gnoci/pkg/testutils/registrytest"
 )
 
 var testRemote = registry.Reference{
@@ -131,6 +145,24 @@ func TestNewModeler(t *testing.T) {
 	assert.Equal(t, gt, model.gt)
 }
 
+func TestNewReadOnlyModeler(t *testing.T) {
+	gt := memory.New()
+	manifest, _ := setupRemote(t, gt)
+
+	got := NewReadOnlyModeler(testRemote, gt)
+
+	model := got.(*model)
+	assert.Nil(t, model.fstore)
+
+	_, err := got.Fetch(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Layers, got.Layers())
+
+	rc, err := got.FetchLayer(t.Context(), manifest.Layers[0].Digest)
+	assert.NoError(t, err)
+	assert.NoError(t, rc.Close())
+}
+
 func Test_model_Fetch(t *testing.T) {
 	// sharing a remote between tests is safe as long as we only fetch from it.
 	// TODO: Consider mocking oras.GraphTarget. Note that at some level we're just
@@ -350,6 +382,208 @@ func Test_model_FetchOrDefault(t *testing.T) {
 	}
 }
 
+func Test_model_pinned(t *testing.T) {
+	gt := memory.New()
+	manifest, _ := setupRemote(t, gt)
+	tagged, err := gt.Resolve(t.Context(), testRemote.String())
+	assert.NoError(t, err)
+
+	pinnedRef := testRemote
+	pinnedRef.Reference = tagged.Digest.String()
+	// registries resolve digests, memory stores only tags
+	assert.NoError(t, gt.Tag(t.Context(), tagged, pinnedRef.String()))
+
+	t.Run("Fetch", func(t *testing.T) {
+		m := &model{ref: pinnedRef, gt: gt, tagHistory: true}
+		manDesc, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, tagged.Digest, manDesc.Digest)
+		assert.Equal(t, manifest.Layers, m.Layers())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		missing := testRemote
+		missing.Reference = digest.FromString("missing").String()
+		m := &model{ref: missing, gt: gt}
+		_, err := m.FetchOrEmpty(t.Context())
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+
+	t.Run("Push", func(t *testing.T) {
+		m := &model{ref: pinnedRef, gt: gt}
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.Push(t.Context())
+		assert.ErrorIs(t, err, ErrDigestReference)
+		_, err = m.PushLFSManifest(t.Context(), m.manDesc)
+		assert.ErrorIs(t, err, ErrDigestReference)
+		_, err = m.Lock(t.Context(), LockOptions{})
+		assert.ErrorIs(t, err, ErrDigestReference)
+		_, err = m.Delete(t.Context(), DeleteOptions{})
+		assert.ErrorIs(t, err, ErrDigestReference)
+
+		// the tag is unchanged
+		got, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		assert.Equal(t, tagged, got)
+	})
+}
+
+func Test_model_FetchOrEmpty(t *testing.T) {
+	gt := memory.New()
+	manifest, config := setupRemote(t, gt)
+
+	newModel := func(t *testing.T, remote registry.Reference) *model {
+		t.Helper()
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, fstore.Close())
+		})
+
+		return &model{
+			ref:         remote,
+			gt:          gt,
+			fstore:      fstore,
+			refsByLayer: map[digest.Digest][]plumbing.Hash{},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		m := newModel(t, testRemote)
+
+		_, err := m.FetchOrEmpty(t.Context())
+		assert.NoError(t, err)
+		assert.True(t, m.fetched)
+		assert.Equal(t, manifest, m.man)
+		assert.Equal(t, config, m.cfg)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		remote := registry.Reference{
+			Registry:   "reg.dne",
+			Repository: "doesnotexist",
+			Reference:  "empty",
+		}
+		m := newModel(t, remote)
+
+		desc, err := m.FetchOrEmpty(t.Context())
+		assert.NoError(t, err)
+		assert.Empty(t, desc)
+		assert.True(t, m.fetched)
+		assert.Equal(t, ocispec.Manifest{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: oci.ArtifactTypeGitManifest,
+		}, m.man)
+		assert.Empty(t, m.cfg.Heads)
+		assert.Empty(t, m.cfg.Tags)
+
+		// nothing is pushed
+		_, err = gt.Resolve(t.Context(), remote.String())
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+
+	t.Run("Not Git Manifest", func(t *testing.T) {
+		remote := registry.Reference{Registry: testRemote.Registry, Repository: testRemote.Repository, Reference: "image"}
+		manDesc, err := oras.PackManifest(t.Context(), gt, oras.PackManifestVersion1_1, "application/vnd.example.image", oras.PackManifestOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, gt.Tag(t.Context(), manDesc, remote.String()))
+		m := newModel(t, remote)
+
+		_, err = m.FetchOrEmpty(t.Context())
+		assert.ErrorIs(t, err, ErrNotGitManifest)
+		assert.False(t, m.fetched)
+
+		_, err = newModel(t, remote).FetchConfigOnly(t.Context())
+		assert.ErrorIs(t, err, ErrNotGitManifest)
+	})
+}
+
+func Test_model_FetchConfigOnly(t *testing.T) {
+	gt := memory.New()
+	manifest, config := setupRemote(t, gt)
+
+	t.Run("Success", func(t *testing.T) {
+		resolver := &resolveCounter{GraphTarget: gt}
+		m := &model{ref: testRemote, gt: resolver}
+
+		manDesc, err := m.FetchConfigOnly(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, config, m.cfg)
+		assert.True(t, m.cfgFetched)
+		assert.False(t, m.fetched)
+		assert.Empty(t, m.Layers())
+
+		again, err := m.FetchConfigOnly(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, manDesc, again)
+
+		// completes the manifest references were listed from
+		fullDesc, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, manDesc, fullDesc)
+		assert.Equal(t, manifest, m.man)
+		assert.Equal(t, config, m.cfg)
+		assert.True(t, m.fetched)
+		assert.Equal(t, int32(1), resolver.resolves.Load())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		m := &model{ref: registry.Reference{Registry: "reg.dne", Repository: "doesnotexist", Reference: "tag"}, gt: gt}
+
+		_, err := m.FetchConfigOnly(t.Context())
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+		assert.False(t, m.cfgFetched)
+	})
+}
+
+func Test_decodeManifestConfig(t *testing.T) {
+	cfgDesc := ocispec.Descriptor{MediaType: oci.MediaTypeGitConfig, Digest: digest.FromString("config"), Size: 6}
+
+	t.Run("Stops Reading After Config", func(t *testing.T) {
+		prefix := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"config":{"mediaType":%q,"digest":%q,"size":6},"layers":[`,
+			ocispec.MediaTypeImageManifest, cfgDesc.MediaType, cfgDesc.Digest)
+		// the remainder is never read
+		r := io.MultiReader(strings.NewReader(prefix), iotest.ErrReader(errors.New("read past config")))
+
+		got, err := decodeManifestConfig(r)
+		assert.NoError(t, err)
+		assert.Equal(t, cfgDesc, got)
+	})
+
+	t.Run("Config Last", func(t *testing.T) {
+		raw := fmt.Sprintf(`{"layers":[{"mediaType":"a","digest":%q,"size":1}],"config":{"mediaType":%q,"digest":%q,"size":6}}`,
+			digest.FromString("a"), cfgDesc.MediaType, cfgDesc.Digest)
+
+		got, err := decodeManifestConfig(strings.NewReader(raw))
+		assert.NoError(t, err)
+		assert.Equal(t, cfgDesc, got)
+	})
+
+	t.Run("No Config", func(t *testing.T) {
+		_, err := decodeManifestConfig(strings.NewReader(`{"schemaVersion":2}`))
+		assert.ErrorIs(t, err, errManifestConfigNotFound)
+	})
+
+	t.Run("Not An Object", func(t *testing.T) {
+		_, err := decodeManifestConfig(strings.NewReader(`[]`))
+		assert.ErrorIs(t, err, errManifestConfigNotFound)
+	})
+}
+
+// resolveCounter counts the tags resolved.
+type resolveCounter struct {
+	oras.GraphTarget
+	resolves atomic.Int32
+}
+
+func (r *resolveCounter) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	r.resolves.Add(1)
+	return r.GraphTarget.Resolve(ctx, reference) //nolint:wrapcheck
+}
+
 func Test_model_FetchLayer(t *testing.T) {
 	// sharing a remote between tests is safe as long as we only fetch from it.
 	gt := memory.New()
@@ -592,6 +826,259 @@ func Test_model_Push(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_model_Push_Mirror(t *testing.T) {
+	primaryReg := registrytest.New(t)
+	mirrorReg := registrytest.New(t)
+	ref := registry.Reference{Registry: primaryReg.Host(), Repository: testRemote.Repository, Reference: testRemote.Reference}
+
+	src := memory.New()
+	manifest, _ := setupRemote(t, src)
+	layer := manifest.Layers[0].Digest
+	primary := primaryReg.Repository(ref.Repository)
+	_, err := oras.Copy(t.Context(), src, testRemote.String(), primary, ref.Reference, oras.DefaultCopyOptions)
+	assert.NoError(t, err)
+
+	// the mirror caches the tag before another client pushes to the primary
+	_, err = oras.Copy(t.Context(), src, testRemote.String(), mirrorReg.Repository(ref.Repository), ref.Reference, oras.DefaultCopyOptions)
+	assert.NoError(t, err)
+
+	other := &model{ref: ref, gt: primary}
+	_, err = other.Fetch(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, other.UpdateRef(t.Context(), plumbing.NewHashReference("refs/heads/other", plumbing.ZeroHash), layer))
+	_, err = other.Push(t.Context())
+	assert.NoError(t, err)
+
+	gt, err := ociutil.NewGraphTarget(t.Context(), ref, &ociutil.RepositoryOptions{
+		UserAgent:     "gnoci-test",
+		PlainHTTP:     true,
+		RegistryCreds: credentials.NewMemoryStore(),
+		Mirrors:       []ociutil.Mirror{{Registry: mirrorReg.Host(), PlainHTTP: true}},
+	})
+	assert.NoError(t, err)
+
+	m := &model{ref: ref, gt: gt}
+	_, err = m.Fetch(t.Context())
+	assert.NoError(t, err)
+	assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/heads/mirrored", plumbing.ZeroHash), layer))
+	_, err = m.Push(t.Context())
+	assert.NoError(t, err)
+
+	// the push built on the primary, keeping the other client's reference
+	got := &model{ref: ref, gt: primary}
+	_, err = got.Fetch(t.Context())
+	assert.NoError(t, err)
+	assert.Contains(t, got.HeadRefs(), plumbing.ReferenceName("refs/heads/other"))
+	assert.Contains(t, got.HeadRefs(), plumbing.ReferenceName("refs/heads/mirrored"))
+}
+
+func Test_model_Push_Concurrency(t *testing.T) {
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, fstore.Close()) }()
+
+	var packs []ocispec.Descriptor
+	for i := range 6 {
+		contents := fmt.Sprintf("packfile %d", i)
+		desc := ocispec.Descriptor{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString(contents), Size: int64(len(contents))}
+		assert.NoError(t, fstore.Push(t.Context(), desc, strings.NewReader(contents)))
+		packs = append(packs, desc)
+	}
+
+	for _, tt := range []struct {
+		name        string
+		concurrency int
+		want        int32
+	}{
+		{name: "Default", want: DefaultConcurrency},
+		{name: "Configured", concurrency: 2, want: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gt := &concurrencyTarget{GraphTarget: memory.New()}
+			m := &model{
+				ref:         testRemote,
+				gt:          gt,
+				fstore:      fstore,
+				concurrency: tt.concurrency,
+				man:         ocispec.Manifest{Layers: packs},
+				cfg:         oci.ConfigGit{},
+				refsByLayer: map[digest.Digest][]plumbing.Hash{},
+				newPacks:    packs,
+			}
+
+			_, err := m.Push(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, gt.peak.Load())
+		})
+	}
+}
+
+func Test_model_Push_Tracing(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, fstore.Close()) }()
+
+	contents := "packfile"
+	pack := ocispec.Descriptor{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString(contents), Size: int64(len(contents))}
+	assert.NoError(t, fstore.Push(t.Context(), pack, strings.NewReader(contents)))
+
+	m := &model{
+		ref:         testRemote,
+		gt:          memory.New(),
+		fstore:      fstore,
+		man:         ocispec.Manifest{Layers: []ocispec.Descriptor{pack}},
+		cfg:         oci.ConfigGit{},
+		refsByLayer: map[digest.Digest][]plumbing.Hash{},
+		newPacks:    []ocispec.Descriptor{pack},
+	}
+
+	manDesc, err := m.Push(t.Context())
+	assert.NoError(t, err)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	pushLayer, ok := spans["model.PushLayer"]
+	assert.True(t, ok)
+	assert.Contains(t, pushLayer.Attributes(), tracing.KeyDigest.String(pack.Digest.String()))
+	assert.Contains(t, pushLayer.Attributes(), tracing.KeySize.Int64(pack.Size))
+
+	push, ok := spans["model.Push"]
+	assert.True(t, ok)
+	assert.Equal(t, push.SpanContext().SpanID(), pushLayer.Parent().SpanID())
+	assert.Contains(t, push.Attributes(), tracing.KeyRegistry.String(testRemote.Registry))
+	assert.Contains(t, push.Attributes(), tracing.KeyDigest.String(manDesc.Digest.String()))
+}
+
+// concurrencyTarget records the peak number of concurrent packfile layer
+// pushes.
+type concurrencyTarget struct {
+	oras.GraphTarget
+	active atomic.Int32
+	peak   atomic.Int32
+}
+
+func (c *concurrencyTarget) Push(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error {
+	if desc.MediaType == oci.MediaTypePackLayer {
+		n := c.active.Add(1)
+		defer c.active.Add(-1)
+		for {
+			peak := c.peak.Load()
+			if n <= peak || c.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		// allow other pushes to start
+		time.Sleep(20 * time.Millisecond)
+	}
+	return c.GraphTarget.Push(ctx, desc, r) //nolint:wrapcheck
+}
+
+// failTagTarget fails to tag any manifest other than allowed, after tagging.
+type failTagTarget struct {
+	oras.GraphTarget
+	allowed digest.Digest
+}
+
+func (f *failTagTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := f.GraphTarget.Tag(ctx, desc, reference); err != nil {
+		return err //nolint:wrapcheck
+	}
+	if desc.Digest != f.allowed {
+		return errors.New("tag failed")
+	}
+	return nil
+}
+
+func Test_model_PushAtomic(t *testing.T) {
+	newModel := func(t *testing.T, gt oras.GraphTarget) *model {
+		t.Helper()
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, fstore.Close())
+		})
+
+		m := &model{ref: testRemote, gt: gt, fstore: fstore}
+		_, err = m.FetchOrEmpty(t.Context())
+		assert.NoError(t, err)
+
+		// an update to push
+		err = m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/tags/new", plumbing.ZeroHash), m.man.Layers[0].Digest)
+		assert.NoError(t, err)
+
+		return m
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newModel(t, gt)
+
+		manDesc, err := m.PushAtomic(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, manDesc, m.manDesc)
+
+		got, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		assert.Equal(t, manDesc.Digest, got.Digest)
+	})
+
+	t.Run("Concurrent Update", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newModel(t, gt)
+
+		// another client pushes
+		other := newModel(t, gt)
+		err := other.UpdateRef(t.Context(), plumbing.NewHashReference("refs/tags/other", plumbing.ZeroHash), other.man.Layers[0].Digest)
+		assert.NoError(t, err)
+		otherDesc, err := other.PushAtomic(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.PushAtomic(t.Context())
+		assert.ErrorIs(t, err, ErrConcurrentUpdate)
+
+		got, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		assert.Equal(t, otherDesc.Digest, got.Digest)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		mem := memory.New()
+		setupRemote(t, mem)
+		prev, err := mem.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+
+		m := newModel(t, &failTagTarget{GraphTarget: mem, allowed: prev.Digest})
+
+		_, err = m.PushAtomic(t.Context())
+		assert.Error(t, err)
+
+		got, err := mem.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		assert.Equal(t, prev.Digest, got.Digest)
+		assert.Equal(t, prev.Digest, m.manDesc.Digest)
+	})
+}
+
+// hashCommits returns commits without parents.
+func hashCommits(hashes ...plumbing.Hash) []*object.Commit {
+	commits := make([]*object.Commit, 0, len(hashes))
+	for _, h := range hashes {
+		commits = append(commits, &object.Commit{Hash: h})
+	}
+	return commits
+}
+
 func Test_model_AddPack(t *testing.T) {
 	tmpDir := t.TempDir()
 	f, err := os.CreateTemp(tmpDir, "layer-file-*.pack")
@@ -612,10 +1099,20 @@ func Test_model_AddPack(t *testing.T) {
 	err = f.Close()
 	assert.NoError(t, err)
 
+	baseLayer := ocispec.Descriptor{
+		MediaType: oci.MediaTypePackLayer,
+		Digest:    digest.FromString("base"),
+		Size:      4,
+	}
+
 	tests := []struct {
 		name     string
+		layers   []ocispec.Descriptor
+		base     digest.Digest
+		commits  []*object.Commit
 		headRefs []*plumbing.Reference
 		tagRefs  []*plumbing.Reference
+		created  func() time.Time
 		wantFn   func(t *testing.T, m *model, packDesc ocispec.Descriptor, err error)
 	}{
 		{
@@ -643,9 +1140,19 @@ func Test_model_AddPack(t *testing.T) {
 				}
 
 				assert.NoError(t, err)
-				assert.Equal(t, expectedLayerDesc, packDesc)
+				assert.Equal(t, expectedLayerDesc.Digest, packDesc.Digest)
 				assert.Equal(t, expectedConfig, m.cfg)
-				assert.Equal(t, []ocispec.Descriptor{expectedLayerDesc}, m.newPacks)
+				assert.Equal(t, []ocispec.Descriptor{packDesc}, m.newPacks)
+				assert.Equal(t, PackProvenance{
+					Creator: "git-remote-oci/v0.1.0",
+					Refs: map[plumbing.ReferenceName]plumbing.Hash{
+						"refs/heads/main": plumbing.ZeroHash,
+						"refs/heads/foo":  plumbing.ZeroHash,
+						"refs/tags/foo":   plumbing.ZeroHash,
+						"refs/tags/bar":   plumbing.ZeroHash,
+					},
+					Commits: 0,
+				}, LayerProvenance(packDesc))
 			},
 		},
 		{
@@ -654,19 +1161,91 @@ func Test_model_AddPack(t *testing.T) {
 				t.Helper()
 
 				assert.Equal(t, oci.ConfigGit{Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{}, Tags: map[plumbing.ReferenceName]oci.ReferenceInfo{}}, m.cfg)
-				assert.Equal(t, []ocispec.Descriptor{expectedLayerDesc}, m.newPacks)
+				assert.Equal(t, []ocispec.Descriptor{packDesc}, m.newPacks)
+				assert.Equal(t, PackProvenance{Creator: "git-remote-oci/v0.1.0", Commits: 0}, LayerProvenance(packDesc))
 			},
 		},
 		{
 			name: "Unsupported Reference Type",
 			headRefs: []*plumbing.Reference{
-				plumbing.NewHashReference("refs/notes/foo", plumbing.ZeroHash),
+				plumbing.NewHashReference("refs/remotes/origin/foo", plumbing.ZeroHash),
 			},
 			wantFn: func(t *testing.T, m *model, packDesc ocispec.Descriptor, err error) {
 				t.Helper()
 
 				assert.ErrorIs(t, err, ErrUnsupportedReferenceType)
-				assert.Equal(t, []ocispec.Descriptor{expectedLayerDesc}, m.newPacks)
+				var refErr *RefError
+				assert.ErrorAs(t, err, &refErr)
+				assert.Equal(t, plumbing.ReferenceName("refs/remotes/origin/foo"), refErr.Ref)
+				assert.Equal(t, []ocispec.Descriptor{packDesc}, m.newPacks)
+			},
+		},
+		{
+			name:   "Thin Packfile",
+			layers: []ocispec.Descriptor{baseLayer},
+			base:   baseLayer.Digest,
+			wantFn: func(t *testing.T, m *model, packDesc ocispec.Descriptor, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				assert.Equal(t, baseLayer.Digest, PackBase(packDesc))
+				assert.Equal(t, []ocispec.Descriptor{baseLayer, packDesc}, m.man.Layers)
+			},
+		},
+		{
+			name: "Commit Index",
+			commits: hashCommits(
+				plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc"),
+				plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"),
+				plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc"),
+			),
+			wantFn: func(t *testing.T, m *model, packDesc ocispec.Descriptor, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				commits := []plumbing.Hash{
+					plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc"),
+					plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"),
+				}
+				assert.Equal(t, commits[0].String()+","+commits[1].String(), packDesc.Annotations[oci.AnnotationPackCommits])
+				assert.Equal(t, commits, PackCommits(packDesc))
+
+				layer, ok := m.graph.layerOf(plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"), m.man.Layers)
+				assert.True(t, ok)
+				assert.Equal(t, packDesc.Digest, layer)
+			},
+		},
+		{
+			name:    "Too Many Commits To Index",
+			commits: hashCommits(make([]plumbing.Hash, maxIndexedCommits+1)...),
+			wantFn: func(t *testing.T, m *model, packDesc ocispec.Descriptor, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				assert.Equal(t, expectedLayerDesc.Digest, packDesc.Digest)
+				assert.Nil(t, PackCommits(packDesc))
+				assert.Equal(t, maxIndexedCommits+1, LayerProvenance(packDesc).Commits)
+			},
+		},
+		{
+			name:    "Created",
+			created: func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) },
+			wantFn: func(t *testing.T, m *model, packDesc ocispec.Descriptor, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				assert.Equal(t, "2025-01-02T03:04:05Z", LayerProvenance(packDesc).Created)
+			},
+		},
+		{
+			name: "Thin Packfile Base Not In Manifest",
+			base: baseLayer.Digest,
+			wantFn: func(t *testing.T, m *model, packDesc ocispec.Descriptor, err error) {
+				t.Helper()
+
+				assert.ErrorIs(t, err, errLayerNotInManifest)
+				assert.Empty(t, m.man.Layers)
+				assert.Empty(t, m.newPacks)
 			},
 		},
 	}
@@ -689,7 +1268,7 @@ func Test_model_AddPack(t *testing.T) {
 				gt:     gt,
 				fstore: fstore,
 				man: ocispec.Manifest{
-					Layers: []ocispec.Descriptor{},
+					Layers: append([]ocispec.Descriptor{}, tt.layers...),
 				},
 				cfg: oci.ConfigGit{
 					Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{},
@@ -698,8 +1277,12 @@ func Test_model_AddPack(t *testing.T) {
 				refsByLayer: map[digest.Digest][]plumbing.Hash{},
 				newPacks:    nil,
 			}
+			WithCreator("git-remote-oci/v0.1.0")(m)
+			if tt.created != nil {
+				WithCreated(tt.created)(m)
+			}
 
-			packDesc, err := m.AddPack(t.Context(), layerPath, append(tt.headRefs, tt.tagRefs...)...)
+			packDesc, err := m.AddPack(t.Context(), layerPath, tt.base, tt.commits, append(tt.headRefs, tt.tagRefs...)...)
 
 			tt.wantFn(t, m, packDesc, err)
 
@@ -714,15 +1297,15 @@ func Test_model_UpdateRef(t *testing.T) {
 		digestAlpha = digest.Digest("sha256:ffbeaa9e113a29d9fc4f58f821e16f594e332033b277ea829eafab12ba148589")
 		digestBeta  = digest.Digest("sha256:60290b69da490356c62dc190efe44ca597ec538f792c2908a8a7ec352dc13e5e")
 		digestDNE   = digest.Digest("sha256:84290ebe369d11cd880bfa160ef8cbb7c0fc03b8093895534f30263b78f030b2")
-
-		commitAlpha = "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"
-		commitBeta  = "9f9daae4bb300543116a1508cd9ed87bafd9d5fc"
 	)
 
 	var (
+		commitAlpha = plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74").String()
+		commitBeta  = plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc").String()
+
 		headRefName    = plumbing.NewBranchReferenceName("branchfoo")
 		tagRefName     = plumbing.NewTagReferenceName("tagbar")
-		unsupportedRef = plumbing.NewNoteReferenceName("notesfoobar")
+		unsupportedRef = plumbing.NewRemoteReferenceName("origin", "foobar")
 	)
 
 	t.Run("Success - Branch Ref", func(t *testing.T) {
@@ -775,6 +1358,25 @@ func Test_model_UpdateRef(t *testing.T) {
 		assert.Equal(t, m.cfg.Tags[tagRefName].Layer, digestBeta)
 	})
 
+	t.Run("Success - Note Ref", func(t *testing.T) {
+		noteRefName := plumbing.NewNoteReferenceName("commits")
+		m := &model{
+			man: ocispec.Manifest{
+				Layers: []ocispec.Descriptor{
+					{Digest: digestAlpha},
+					{Digest: digestBeta},
+				},
+			},
+			cfg: oci.ConfigGit{}, // notes map is lazily initialized
+		}
+
+		err := m.UpdateRef(t.Context(), plumbing.NewHashReference(noteRefName, plumbing.NewHash(commitBeta)), digestBeta)
+
+		assert.NoError(t, err)
+		assert.Equal(t, m.cfg.Notes[noteRefName].Commit, commitBeta)
+		assert.Equal(t, m.cfg.Notes[noteRefName].Layer, digestBeta)
+	})
+
 	t.Run("Unsupported Ref Type", func(t *testing.T) {
 		m := &model{
 			man: ocispec.Manifest{
@@ -834,7 +1436,7 @@ func Test_model_ResolveRef(t *testing.T) {
 	var (
 		headRefName    = plumbing.NewBranchReferenceName("branchfoo")
 		tagRefName     = plumbing.NewTagReferenceName("tagbar")
-		unsupportedRef = plumbing.NewNoteReferenceName("notesfoobar")
+		unsupportedRef = plumbing.NewRemoteReferenceName("origin", "foobar")
 	)
 
 	t.Run("Success - Branch Ref", func(t *testing.T) {
@@ -877,6 +1479,62 @@ func Test_model_ResolveRef(t *testing.T) {
 		assert.Equal(t, digestAlpha, gotLayer)
 	})
 
+	t.Run("Success - Note Ref", func(t *testing.T) {
+		noteRefName := plumbing.NewNoteReferenceName("commits")
+		m := &model{
+			cfg: oci.ConfigGit{
+				Notes: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					noteRefName: {
+						Commit: commitAlpha,
+						Layer:  digestAlpha,
+					},
+				},
+			},
+		}
+
+		gotFullRef, gotLayer, err := m.ResolveRef(t.Context(), noteRefName)
+
+		expectedFullRef := plumbing.NewHashReference(noteRefName, plumbing.NewHash(commitAlpha))
+		assert.NoError(t, err)
+		assert.Equal(t, expectedFullRef, gotFullRef)
+		assert.Equal(t, digestAlpha, gotLayer)
+	})
+
+	t.Run("Success - HEAD", func(t *testing.T) {
+		m := &model{
+			cfg: oci.ConfigGit{
+				Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					headRefName:     {Commit: commitAlpha, Layer: digestAlpha},
+					plumbing.Master: {Commit: commitBeta, Layer: digestBeta},
+				},
+				DefaultBranch: headRefName,
+			},
+		}
+
+		gotFullRef, gotLayer, err := m.ResolveRef(t.Context(), plumbing.HEAD)
+
+		expectedFullRef := plumbing.NewHashReference(plumbing.HEAD, plumbing.NewHash(commitAlpha))
+		assert.NoError(t, err)
+		assert.Equal(t, expectedFullRef, gotFullRef)
+		assert.Equal(t, digestAlpha, gotLayer)
+	})
+
+	t.Run("HEAD Without Default Branch", func(t *testing.T) {
+		m := &model{
+			cfg: oci.ConfigGit{
+				Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					headRefName: {Commit: commitAlpha, Layer: digestAlpha},
+				},
+			},
+		}
+
+		gotFullRef, gotLayer, err := m.ResolveRef(t.Context(), plumbing.HEAD)
+
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+		assert.Nil(t, gotFullRef)
+		assert.Equal(t, digest.Digest(""), gotLayer)
+	})
+
 	t.Run("Unsupported Ref Type", func(t *testing.T) {
 		m := &model{
 			cfg: oci.ConfigGit{
@@ -918,6 +1576,50 @@ func Test_model_ResolveRef(t *testing.T) {
 	})
 }
 
+func Test_model_ResolveCommit(t *testing.T) {
+	const (
+		digestAlpha = digest.Digest("sha256:ffbeaa9e113a29d9fc4f58f821e16f594e332033b277ea829eafab12ba148589")
+		digestBeta  = digest.Digest("sha256:60290b69da490356c62dc190efe44ca597ec538f792c2908a8a7ec352dc13e5e")
+	)
+
+	var (
+		commitAlpha = plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74").String()
+		commitBeta  = plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc").String()
+		commitDNE   = plumbing.NewHash("84290ebe369d11cd880bfa160ef8cbb7c0fc03b8").String()
+	)
+
+	m := &model{
+		man: ocispec.Manifest{
+			Layers: []ocispec.Descriptor{
+				{Digest: digestAlpha, Annotations: map[string]string{oci.AnnotationPackCommits: commitAlpha + "," + commitBeta}},
+				{Digest: digestBeta},
+			},
+		},
+		cfg: oci.ConfigGit{
+			Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+				plumbing.NewBranchReferenceName("main"): {Commit: commitBeta, Layer: digestBeta},
+			},
+		},
+	}
+
+	t.Run("Referenced Commit", func(t *testing.T) {
+		gotLayer, err := m.ResolveCommit(t.Context(), plumbing.NewHash(commitBeta))
+		assert.NoError(t, err)
+		assert.Equal(t, digestBeta, gotLayer)
+	})
+
+	t.Run("Indexed Commit", func(t *testing.T) {
+		gotLayer, err := m.ResolveCommit(t.Context(), plumbing.NewHash(commitAlpha))
+		assert.NoError(t, err)
+		assert.Equal(t, digestAlpha, gotLayer)
+	})
+
+	t.Run("Commit Not Found", func(t *testing.T) {
+		_, err := m.ResolveCommit(t.Context(), plumbing.NewHash(commitDNE))
+		assert.ErrorIs(t, err, ErrCommitNotFound)
+	})
+}
+
 func Test_model_DeleteRef(t *testing.T) {
 	const (
 		digestAlpha = digest.Digest("sha256:ffbeaa9e113a29d9fc4f58f821e16f594e332033b277ea829eafab12ba148589")
@@ -931,7 +1633,7 @@ func Test_model_DeleteRef(t *testing.T) {
 	var (
 		headRefName    = plumbing.NewBranchReferenceName("branchfoo")
 		tagRefName     = plumbing.NewTagReferenceName("tagbar")
-		unsupportedRef = plumbing.NewNoteReferenceName("notesfoobar")
+		unsupportedRef = plumbing.NewRemoteReferenceName("origin", "foobar")
 	)
 
 	t.Run("Success - Branch Ref", func(t *testing.T) {
@@ -1237,3 +1939,126 @@ func Test_model_TagRefs(t *testing.T) {
 		assert.Equal(t, 0, len(got))
 	})
 }
+
+func Test_model_NoteRefs(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		expected := map[plumbing.ReferenceName]oci.ReferenceInfo{
+			plumbing.NewNoteReferenceName("commits"): {
+				Commit: "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74",
+				Layer:  digest.Digest("sha256:ffbeaa9e113a29d9fc4f58f821e16f594e332033b277ea829eafab12ba148589"),
+			},
+		}
+
+		m := &model{
+			cfg: oci.ConfigGit{
+				Notes: expected,
+			},
+		}
+
+		assert.Equal(t, expected, m.NoteRefs())
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		m := &model{}
+
+		got := m.NoteRefs()
+
+		assert.NotNil(t, got)
+		assert.Equal(t, 0, len(got))
+	})
+}
+
+func Test_model_SetDefaultBranch(t *testing.T) {
+	const commit = "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"
+	layer := digest.FromString("layer")
+
+	newModel := func() *model {
+		return &model{
+			cfg: oci.ConfigGit{
+				Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					plumbing.Main: {Commit: commit, Layer: layer},
+				},
+			},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		m := newModel()
+		assert.Empty(t, m.DefaultBranch())
+
+		err := m.SetDefaultBranch(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.Main, m.DefaultBranch())
+	})
+
+	t.Run("Branch Deleted", func(t *testing.T) {
+		m := newModel()
+		err := m.SetDefaultBranch(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+
+		err = m.DeleteRef(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		assert.Empty(t, m.DefaultBranch())
+	})
+
+	t.Run("Branch Not Found", func(t *testing.T) {
+		m := newModel()
+		err := m.SetDefaultBranch(t.Context(), plumbing.Master)
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+	})
+
+	t.Run("Not A Branch", func(t *testing.T) {
+		m := newModel()
+		err := m.SetDefaultBranch(t.Context(), plumbing.NewTagReferenceName("v1"))
+		assert.ErrorIs(t, err, ErrUnsupportedReferenceType)
+	})
+}
+
+func Test_model_SetPeeled(t *testing.T) {
+	var (
+		tag    = plumbing.NewHash("8ab686eafeb1f44702738c8b0f24f2567c36da6d").String()
+		commit = plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74").String()
+	)
+	layer := digest.FromString("layer")
+	v1 := plumbing.NewTagReferenceName("v1")
+
+	newModel := func() *model {
+		return &model{
+			man: ocispec.Manifest{Layers: []ocispec.Descriptor{{Digest: layer}}},
+			cfg: oci.ConfigGit{
+				Tags: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					v1: {Commit: tag, Layer: layer},
+				},
+			},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		m := newModel()
+		err := m.SetPeeled(t.Context(), v1, plumbing.NewHash(commit))
+		assert.NoError(t, err)
+		assert.Equal(t, oci.ReferenceInfo{Commit: tag, Layer: layer, Peeled: commit}, m.TagRefs()[v1])
+	})
+
+	t.Run("Tag Updated", func(t *testing.T) {
+		m := newModel()
+		err := m.SetPeeled(t.Context(), v1, plumbing.NewHash(commit))
+		assert.NoError(t, err)
+
+		err = m.UpdateRef(t.Context(), plumbing.NewHashReference(v1, plumbing.NewHash(commit)), layer)
+		assert.NoError(t, err)
+		assert.Empty(t, m.TagRefs()[v1].Peeled)
+	})
+
+	t.Run("Tag Not Found", func(t *testing.T) {
+		m := newModel()
+		err := m.SetPeeled(t.Context(), plumbing.NewTagReferenceName("v2"), plumbing.NewHash(commit))
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+	})
+
+	t.Run("Not A Tag", func(t *testing.T) {
+		m := newModel()
+		err := m.SetPeeled(t.Context(), plumbing.Main, plumbing.NewHash(commit))
+		assert.ErrorIs(t, err, ErrUnsupportedReferenceType)
+	})
+}
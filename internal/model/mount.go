@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry"
+)
+
+// errNotMounted indicates a registry declined to mount a blob, responding with
+// an upload session instead.
+var errNotMounted = errors.New("blob not mounted")
+
+// WithMountFrom attempts to mount new packfile layers from repositories in
+// the same registry before uploading them, e.g. when pushing a Git repository
+// already stored in another OCI repository. Sources are tried in order.
+func WithMountFrom(repositories ...string) Option {
+	return func(m *model) {
+		m.mountFrom = repositories
+	}
+}
+
+// mount attempts to mount desc from each of the configured source
+// repositories, returning true if the blob was mounted. Registries and stores
+// not supporting cross-repository mounts are not an error.
+func (m *model) mount(ctx context.Context, desc ocispec.Descriptor) bool {
+	if len(m.mountFrom) == 0 {
+		return false
+	}
+
+	mounter, ok := storeMounter(m.gt)
+	if !ok {
+		slog.DebugContext(ctx, "store does not support cross-repository mounts")
+		return false
+	}
+
+	for _, from := range m.mountFrom {
+		err := mounter.Mount(ctx, desc, from, func() (io.ReadCloser, error) {
+			return nil, errNotMounted
+		})
+		if err == nil {
+			slog.InfoContext(ctx, "mounted packfile layer", slog.String("digest", desc.Digest.String()), slog.String("from", from))
+			return true
+		}
+		slog.DebugContext(ctx, "mounting packfile layer", slog.String("digest", desc.Digest.String()),
+			slog.String("from", from), slog.String("error", err.Error()))
+	}
+
+	return false
+}
+
+// storeMounter returns the cross-repository mounter written to by gt, if any.
+func storeMounter(gt Store) (registry.Mounter, bool) {
+	if mirrored, ok := gt.(interface{ Primary() oras.GraphTarget }); ok {
+		gt = mirrored.Primary()
+	}
+	mounter, ok := gt.(registry.Mounter)
+	return mounter, ok
+}
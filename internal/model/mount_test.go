@@ -0,0 +1,95 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// mountingTarget mounts blobs from other in-memory repositories, requesting
+// the content otherwise as a registry would.
+type mountingTarget struct {
+	oras.GraphTarget
+	repos   map[string]oras.GraphTarget
+	mounted []string
+}
+
+func (mt *mountingTarget) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	if from, ok := mt.repos[fromRepo]; ok {
+		if exists, err := from.Exists(ctx, desc); err == nil && exists {
+			raw, err := content.FetchAll(ctx, from, desc)
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+			mt.mounted = append(mt.mounted, fromRepo)
+			return mt.Push(ctx, desc, bytes.NewReader(raw))
+		}
+	}
+
+	rc, err := getContent()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return mt.Push(ctx, desc, rc)
+}
+
+func Test_model_mount(t *testing.T) {
+	source := orasmemory.New()
+	desc, err := oras.PushBytes(t.Context(), source, oci.MediaTypePackLayer, []byte("packfile"))
+	assert.NoError(t, err)
+
+	newTarget := func() *mountingTarget {
+		return &mountingTarget{
+			GraphTarget: orasmemory.New(),
+			repos:       map[string]oras.GraphTarget{"team/source": source, "team/empty": orasmemory.New()},
+		}
+	}
+
+	t.Run("Mounted", func(t *testing.T) {
+		gt := newTarget()
+		m := &model{gt: gt}
+		WithMountFrom("team/empty", "team/source")(m)
+
+		assert.True(t, m.mount(t.Context(), desc))
+		assert.Equal(t, []string{"team/source"}, gt.mounted)
+		exists, err := gt.Exists(t.Context(), desc)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("Not Mounted", func(t *testing.T) {
+		gt := newTarget()
+		m := &model{gt: gt}
+		WithMountFrom("team/empty", "team/dne")(m)
+
+		assert.False(t, m.mount(t.Context(), desc))
+		exists, err := gt.Exists(t.Context(), desc)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		m := &model{gt: orasmemory.New()}
+		WithMountFrom("team/source")(m)
+
+		assert.False(t, m.mount(t.Context(), desc))
+	})
+
+	t.Run("Not Configured", func(t *testing.T) {
+		gt := newTarget()
+		m := &model{gt: gt}
+
+		assert.False(t, m.mount(t.Context(), desc))
+		assert.Empty(t, gt.mounted)
+	})
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+
+	"github.com/act3-ai/gnoci/internal/git"
+)
+
+var (
+	// ErrUnsupportedObjectFormat indicates a repository's hash algorithm is
+	// not the one gnoci was built with. SHA-256 repositories require
+	// building with the "sha256" tag.
+	ErrUnsupportedObjectFormat = errors.New("unsupported object format")
+	// ErrObjectFormatMismatch indicates the hash algorithms of a local and
+	// remote repository differ.
+	ErrObjectFormatMismatch = errors.New("object format mismatch")
+)
+
+// SupportedObjectFormat returns the hash algorithm gnoci was built with.
+func SupportedObjectFormat() formatcfg.ObjectFormat {
+	return git.ObjectFormat()
+}
+
+// CheckObjectFormat returns [ErrUnsupportedObjectFormat] if format is not the
+// hash algorithm gnoci was built with. An empty format indicates SHA-1.
+func CheckObjectFormat(format formatcfg.ObjectFormat) error {
+	if format == "" {
+		format = formatcfg.DefaultObjectFormat
+	}
+	if format != SupportedObjectFormat() {
+		return fmt.Errorf("%w: %s, built with %s", ErrUnsupportedObjectFormat, format, SupportedObjectFormat())
+	}
+
+	return nil
+}
+
+func (m *model) ObjectFormat() formatcfg.ObjectFormat {
+	if m.cfg.ObjectFormat == "" {
+		return formatcfg.DefaultObjectFormat
+	}
+	return m.cfg.ObjectFormat
+}
+
+func (m *model) SetObjectFormat(ctx context.Context, format formatcfg.ObjectFormat) error {
+	slog.DebugContext(ctx, "setting object format", slog.String("format", string(format)))
+	if format == "" {
+		format = formatcfg.DefaultObjectFormat
+	}
+	if format == m.ObjectFormat() {
+		return nil
+	}
+	if len(m.man.Layers) > 0 {
+		return fmt.Errorf("%w: remote is %s, got %s", ErrObjectFormatMismatch, m.ObjectFormat(), format)
+	}
+
+	// SHA-1 is not recorded, remaining compatible with older clients
+	m.cfg.ObjectFormat = format
+	if format == formatcfg.DefaultObjectFormat {
+		m.cfg.ObjectFormat = ""
+	}
+
+	return nil
+}
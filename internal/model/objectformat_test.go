@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func TestCheckObjectFormat(t *testing.T) {
+	assert.NoError(t, CheckObjectFormat(SupportedObjectFormat()))
+	if SupportedObjectFormat() == formatcfg.SHA1 {
+		assert.NoError(t, CheckObjectFormat(""))
+		assert.ErrorIs(t, CheckObjectFormat(formatcfg.SHA256), ErrUnsupportedObjectFormat)
+	} else {
+		// built with the sha256 tag, an empty format indicates SHA-1
+		assert.ErrorIs(t, CheckObjectFormat(""), ErrUnsupportedObjectFormat)
+		assert.ErrorIs(t, CheckObjectFormat(formatcfg.SHA1), ErrUnsupportedObjectFormat)
+	}
+	assert.ErrorIs(t, CheckObjectFormat("md5"), ErrUnsupportedObjectFormat)
+}
+
+func Test_model_SetObjectFormat(t *testing.T) {
+	t.Run("New Remote", func(t *testing.T) {
+		m := &model{}
+
+		err := m.SetObjectFormat(t.Context(), formatcfg.SHA256)
+		assert.NoError(t, err)
+		assert.Equal(t, formatcfg.SHA256, m.ObjectFormat())
+		assert.Equal(t, formatcfg.SHA256, m.cfg.ObjectFormat)
+	})
+
+	t.Run("SHA-1 Not Recorded", func(t *testing.T) {
+		m := &model{}
+
+		err := m.SetObjectFormat(t.Context(), formatcfg.SHA1)
+		assert.NoError(t, err)
+		assert.Equal(t, formatcfg.SHA1, m.ObjectFormat())
+		assert.Empty(t, m.cfg.ObjectFormat)
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		m := &model{
+			man: ocispec.Manifest{Layers: []ocispec.Descriptor{{MediaType: oci.MediaTypePackLayer}}},
+		}
+
+		err := m.SetObjectFormat(t.Context(), formatcfg.SHA256)
+		assert.ErrorIs(t, err, ErrObjectFormatMismatch)
+		assert.Equal(t, formatcfg.SHA1, m.ObjectFormat())
+	})
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"context"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Observer is notified of changes made to the OCI remote by a [Modeler] or
+// [LFSModeler], such that embedding applications may react to progress without
+// parsing logs. Methods may be called concurrently, and should return promptly
+// as they block the push.
+type Observer interface {
+	// OnLayerPushed is called once a packfile layer is in the remote, whether
+	// uploaded or mounted from another repository.
+	OnLayerPushed(ctx context.Context, desc ocispec.Descriptor)
+	// OnRefUpdated is called for each reference updated or deleted since the
+	// last push, once the Git manifest referring to it is tagged. Deleted
+	// references have a zero hash and an empty layer digest.
+	OnRefUpdated(ctx context.Context, ref *plumbing.Reference, layer digest.Digest)
+	// OnManifestTagged is called once a Git manifest is tagged as ref.
+	OnManifestTagged(ctx context.Context, desc ocispec.Descriptor, ref registry.Reference)
+	// OnLFSUploaded is called once an LFS file is uploaded to the remote. LFS
+	// files already in the remote are not reported.
+	OnLFSUploaded(ctx context.Context, desc ocispec.Descriptor)
+}
+
+// NopObserver is an [Observer] ignoring all events, embedded by observers
+// interested in only some of them.
+type NopObserver struct{}
+
+// OnLayerPushed implements [Observer].
+func (NopObserver) OnLayerPushed(context.Context, ocispec.Descriptor) {}
+
+// OnRefUpdated implements [Observer].
+func (NopObserver) OnRefUpdated(context.Context, *plumbing.Reference, digest.Digest) {}
+
+// OnManifestTagged implements [Observer].
+func (NopObserver) OnManifestTagged(context.Context, ocispec.Descriptor, registry.Reference) {}
+
+// OnLFSUploaded implements [Observer].
+func (NopObserver) OnLFSUploaded(context.Context, ocispec.Descriptor) {}
+
+// WithObserver notifies o of changes made to the remote. May be given multiple
+// times, observers are notified in order.
+func WithObserver(o Observer) Option {
+	return func(m *model) {
+		if o != nil {
+			m.observers = append(m.observers, o)
+		}
+	}
+}
+
+// observe notifies each observer with notify.
+func (m *model) observe(notify func(Observer)) {
+	for _, o := range m.observers {
+		notify(o)
+	}
+}
+
+// refChanged records a reference updated or deleted since the last push.
+func (m *model) refChanged(name plumbing.ReferenceName) {
+	if len(m.observers) == 0 {
+		return
+	}
+	if m.changedRefs == nil {
+		m.changedRefs = make(map[plumbing.ReferenceName]struct{})
+	}
+	m.changedRefs[name] = struct{}{}
+}
+
+// observeRefs notifies observers of the references changed since the last
+// push, in order of name.
+func (m *model) observeRefs(ctx context.Context) {
+	names := make([]plumbing.ReferenceName, 0, len(m.changedRefs))
+	for name := range m.changedRefs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	m.changedRefs = nil
+
+	for _, name := range names {
+		ref, layer, err := m.ResolveRef(ctx, name)
+		if err != nil {
+			// deleted
+			ref, layer = plumbing.NewHashReference(name, plumbing.ZeroHash), ""
+		}
+		m.observe(func(o Observer) { o.OnRefUpdated(ctx, ref, layer) })
+	}
+}
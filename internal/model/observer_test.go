@@ -0,0 +1,121 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// recordingObserver records the events it is notified of, e.g.
+// "layer sha256:...".
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingObserver) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingObserver) OnLayerPushed(_ context.Context, desc ocispec.Descriptor) {
+	r.record("layer " + desc.Digest.String())
+}
+
+func (r *recordingObserver) OnRefUpdated(_ context.Context, ref *plumbing.Reference, layer digest.Digest) {
+	r.record("ref " + ref.Name().String() + " " + ref.Hash().String() + " " + layer.String())
+}
+
+func (r *recordingObserver) OnManifestTagged(_ context.Context, _ ocispec.Descriptor, ref registry.Reference) {
+	r.record("tag " + ref.String())
+}
+
+func (r *recordingObserver) OnLFSUploaded(_ context.Context, desc ocispec.Descriptor) {
+	r.record("lfs " + desc.Digest.String())
+}
+
+func Test_model_Observer(t *testing.T) {
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, fstore.Close()) }()
+
+	t.Run("Push", func(t *testing.T) {
+		gt := memory.New()
+		gitManifest, gitConfig := setupRemote(t, gt)
+
+		contents := "packfile"
+		pack := ocispec.Descriptor{MediaType: oci.MediaTypePackLayer, Digest: digest.FromString(contents), Size: int64(len(contents))}
+		assert.NoError(t, fstore.Push(t.Context(), pack, strings.NewReader(contents)))
+
+		obs := &recordingObserver{}
+		m := NewModeler(testRemote, fstore, gt, WithObserver(obs)).(*model)
+		m.fetched = true
+		m.tagDesc, err = gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		m.man = gitManifest
+		m.man.Layers = append(m.man.Layers, pack)
+		m.cfg = gitConfig
+		m.refsByLayer = map[digest.Digest][]plumbing.Hash{}
+		m.newPacks = []ocispec.Descriptor{pack}
+
+		commit := plumbing.NewHash("1111111111111111111111111111111111111111")
+		assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/heads/feature", commit), pack.Digest))
+		assert.NoError(t, m.DeleteRef(t.Context(), plumbing.Main))
+
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"layer " + pack.Digest.String(),
+			"tag " + testRemote.String(),
+			"ref refs/heads/feature " + commit.String() + " " + pack.Digest.String(),
+			"ref refs/heads/main " + plumbing.ZeroHash.String() + " ",
+		}, obs.events)
+
+		// changed references are reported once
+		obs.events = nil
+		m.newPacks = nil
+		_, err = m.Push(t.Context())
+		assert.NoError(t, err)
+		assert.NotContains(t, strings.Join(obs.events, "\n"), "ref ")
+	})
+
+	t.Run("LFS Upload", func(t *testing.T) {
+		gt := memory.New()
+		gitManifest, gitConfig := setupRemote(t, gt)
+
+		obs := &recordingObserver{}
+		m := NewLFSModeler(testRemote, fstore, gt, WithObserver(obs)).(*model)
+		m.fetched = true
+		m.man = gitManifest
+		m.cfg = gitConfig
+
+		lfsFileContents := "observed lfs file"
+		lfsFilePath := filepath.Join(t.TempDir(), "observed")
+		assert.NoError(t, os.WriteFile(lfsFilePath, []byte(lfsFileContents), 0o644))
+
+		_, err := m.PushLFSFile(t.Context(), lfsFilePath, &PushLFSOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"lfs " + digest.FromString(lfsFileContents).String()}, obs.events)
+	})
+
+	t.Run("Nop", func(t *testing.T) {
+		var _ Observer = NopObserver{}
+		m := &model{}
+		WithObserver(nil)(m)
+		assert.Empty(t, m.observers)
+	})
+}
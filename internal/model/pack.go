@@ -0,0 +1,235 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// PackBase returns the digest of the newest layer a thin packfile layer
+// depends on, or an empty digest if the packfile is self-contained.
+func PackBase(desc ocispec.Descriptor) digest.Digest {
+	return digest.Digest(desc.Annotations[oci.AnnotationPackBase])
+}
+
+// PackWindow is the delta window of encoded packfiles, git's default, see
+// https://git-scm.com/docs/git-pack-objects#Documentation/git-pack-objects.txt---windown
+const PackWindow = 10
+
+// SortHashes sorts hashes in place. Packfiles encoded from sorted objects are
+// byte-identical regardless of the order the objects were resolved in, e.g.
+// the map iteration order of [revlist.Objects].
+func SortHashes(hashes []plumbing.Hash) {
+	slices.SortFunc(hashes, func(a, b plumbing.Hash) int { return bytes.Compare(a[:], b[:]) })
+}
+
+// maxIndexedCommits is the most commits indexed in a packfile layer
+// annotation, bounding the size of the Git manifest.
+const maxIndexedCommits = 256
+
+// PackCommits returns the commits indexed in a packfile layer's annotations.
+// An unindexed layer returns nil, such layers may contain any commit.
+func PackCommits(desc ocispec.Descriptor) []plumbing.Hash {
+	index := desc.Annotations[oci.AnnotationPackCommits]
+	if index == "" {
+		return nil
+	}
+
+	fields := strings.Split(index, ",")
+	commits := make([]plumbing.Hash, 0, len(fields))
+	for _, f := range fields {
+		commits = append(commits, plumbing.NewHash(f))
+	}
+
+	return commits
+}
+
+// packCommitsIndex encodes commits as a packfile layer annotation value,
+// returning false if there are too many to index.
+func packCommitsIndex(commits []plumbing.Hash) (string, bool) {
+	if len(commits) == 0 || len(commits) > maxIndexedCommits {
+		return "", false
+	}
+
+	hexes := make([]string, 0, len(commits))
+	for _, c := range commits {
+		hexes = append(hexes, c.String())
+	}
+	slices.Sort(hexes)
+
+	return strings.Join(slices.Compact(hexes), ","), true
+}
+
+// WithCreator records creator, the user agent and version of the client, in
+// the annotations of added packfile layers, e.g. "git-remote-oci/v0.1.0".
+func WithCreator(creator string) Option {
+	return func(m *model) {
+		m.creator = creator
+	}
+}
+
+// PackProvenance describes how a packfile layer was pushed.
+type PackProvenance struct {
+	// Creator is the user agent and version of the client that pushed the
+	// layer, if recorded.
+	Creator string
+	// Refs map the references pushed with the layer to their tip commits.
+	Refs map[plumbing.ReferenceName]plumbing.Hash
+	// Commits is the number of commits within the layer, -1 if not recorded.
+	Commits int
+	// Created is the time the layer was pushed, RFC 3339 formatted, if
+	// recorded.
+	Created string
+}
+
+// LayerProvenance returns the provenance recorded in a packfile layer's
+// annotations. Layers pushed by older clients may not record provenance.
+func LayerProvenance(desc ocispec.Descriptor) PackProvenance {
+	prov := PackProvenance{
+		Creator: desc.Annotations[oci.AnnotationPackCreator],
+		Commits: -1,
+		Created: desc.Annotations[ocispec.AnnotationCreated],
+	}
+
+	if raw, ok := desc.Annotations[oci.AnnotationPackRefs]; ok {
+		var refs map[plumbing.ReferenceName]string
+		if err := json.Unmarshal([]byte(raw), &refs); err == nil {
+			prov.Refs = make(map[plumbing.ReferenceName]plumbing.Hash, len(refs))
+			for name, tip := range refs {
+				prov.Refs[name] = plumbing.NewHash(tip)
+			}
+		}
+	}
+
+	if n, err := strconv.Atoi(desc.Annotations[oci.AnnotationPackCommitCount]); err == nil {
+		prov.Commits = n
+	}
+
+	return prov
+}
+
+// provenanceAnnotations returns the annotations recording the provenance of a
+// packfile layer containing commits, pushed with refs.
+func (m *model) provenanceAnnotations(commits []plumbing.Hash, refs []*plumbing.Reference) (map[string]string, error) {
+	annotations := maps.Clone(m.userAnnotations.Layers)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[oci.AnnotationPackCommitCount] = strconv.Itoa(len(commits))
+	if m.creator != "" {
+		annotations[oci.AnnotationPackCreator] = m.creator
+	}
+	// the default epoch timestamp says nothing about when a layer was pushed
+	if m.createdAt != nil {
+		annotations[ocispec.AnnotationCreated] = m.created()
+	}
+
+	if len(refs) > 0 {
+		tips := make(map[plumbing.ReferenceName]string, len(refs))
+		for _, ref := range refs {
+			tips[ref.Name()] = ref.Hash().String()
+		}
+		raw, err := json.Marshal(tips)
+		if err != nil {
+			return nil, fmt.Errorf("encoding packfile references: %w", err)
+		}
+		annotations[oci.AnnotationPackRefs] = string(raw)
+	}
+
+	return annotations, nil
+}
+
+// UnpackPack writes the objects of a packfile to object storage. Delta bases
+// of thin packfiles are resolved from objects already in st, a missing base
+// results in a [plumbing.ErrObjectNotFound] error.
+func UnpackPack(st storer.Storer, r io.Reader, thin bool) error {
+	if !thin {
+		return writePack(st, r)
+	}
+
+	// unlike UpdateObjectStorage, never write the packfile as-is as thin
+	// packfiles are not valid within a repository
+	p, err := packfile.NewParserWithStorage(packfile.NewScanner(r), st)
+	if err != nil {
+		return fmt.Errorf("initializing packfile parser: %w", err)
+	}
+	if _, err := p.Parse(); err != nil {
+		return fmt.Errorf("parsing thin packfile: %w", err)
+	}
+
+	return nil
+}
+
+// writePack writes a self-contained packfile to st. A repository's storage
+// writes the packfile as-is, leaving a partially written temporary packfile
+// behind if reading r fails, e.g. when interrupted, which is removed.
+func writePack(st storer.Storer, r io.Reader) error {
+	fst, ok := st.(*filesystem.Storage)
+	if !ok {
+		return packfile.UpdateObjectStorage(st, r) //nolint:wrapcheck
+	}
+
+	fsys := fst.Filesystem()
+	packDir := fsys.Join("objects", "pack")
+	existing, err := tmpPacks(fst)
+	if err != nil {
+		return err
+	}
+
+	err = packfile.UpdateObjectStorage(st, r)
+	if err == nil {
+		return nil
+	}
+
+	partial, listErr := tmpPacks(fst)
+	if listErr != nil {
+		return errors.Join(err, listErr)
+	}
+	for _, name := range partial {
+		if slices.Contains(existing, name) {
+			// another writer's
+			continue
+		}
+		if rmErr := fsys.Remove(fsys.Join(packDir, name)); rmErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing partial packfile: %w", rmErr))
+		}
+	}
+
+	return err //nolint:wrapcheck
+}
+
+// tmpPacks returns the names of temporary packfiles in a repository.
+func tmpPacks(st *filesystem.Storage) ([]string, error) {
+	fsys := st.Filesystem()
+	entries, err := fsys.ReadDir(fsys.Join("objects", "pack"))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("listing packfiles: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "tmp_pack_") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
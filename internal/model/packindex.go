@@ -0,0 +1,324 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/idxfile"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/hash"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+var (
+	// ErrPackIndexNotFound indicates a packfile layer has no pack index layer.
+	ErrPackIndexNotFound = errors.New("pack index not found")
+	// errPackIndexMismatch indicates a pack index does not index a packfile.
+	errPackIndexMismatch = errors.New("pack index does not match packfile")
+)
+
+// WithPackIndexes pushes the Git pack index of each added packfile layer as
+// a companion layer, such that fetches install it rather than indexing the
+// packfile locally. Thin and encrypted packfiles are not indexed.
+func WithPackIndexes() Option {
+	return func(m *model) {
+		m.packIndexes = true
+	}
+}
+
+// PackIndex returns the digest of the pack index layer of a packfile layer,
+// empty if it has none.
+func PackIndex(desc ocispec.Descriptor) digest.Digest {
+	return digest.Digest(desc.Annotations[oci.AnnotationPackIndex])
+}
+
+// splitPackIndexes separates the pack index layers of a Git manifest from its
+// packfile layers, returning the indexes by the digest of the packfile they
+// index.
+func splitPackIndexes(layers []ocispec.Descriptor) ([]ocispec.Descriptor, map[digest.Digest]ocispec.Descriptor) {
+	var indexes map[digest.Digest]ocispec.Descriptor
+	layers = slices.DeleteFunc(layers, func(desc ocispec.Descriptor) bool {
+		if desc.MediaType != oci.MediaTypePackIndex {
+			return false
+		}
+		if indexes == nil {
+			indexes = make(map[digest.Digest]ocispec.Descriptor)
+		}
+		indexes[digest.Digest(desc.Annotations[oci.AnnotationPackIndexPack])] = desc
+		return true
+	})
+	return layers, indexes
+}
+
+// packIndexLayers returns the pack index layers of the current packfile
+// layers, in the order of the packfile layers. Indexes of pruned packfile
+// layers are dropped.
+func (m *model) packIndexLayers() []ocispec.Descriptor {
+	var descs []ocispec.Descriptor
+	for _, desc := range m.man.Layers {
+		if idx, ok := m.indexes[desc.Digest]; ok {
+			descs = append(descs, idx)
+		}
+	}
+	return descs
+}
+
+// newPackIndexLayers returns the pack index layers added since fetching, of
+// the current packfile layers.
+func (m *model) newPackIndexLayers() []ocispec.Descriptor {
+	current := m.packIndexLayers()
+	return slices.DeleteFunc(slices.Clone(m.newIndexes), func(desc ocispec.Descriptor) bool {
+		return !slices.ContainsFunc(current, func(d ocispec.Descriptor) bool { return d.Digest == desc.Digest })
+	})
+}
+
+// withPackIndexLayers returns packs followed by their pack index layers, e.g.
+// for deletion alongside them.
+func (m *model) withPackIndexLayers(packs []ocispec.Descriptor) []ocispec.Descriptor {
+	descs := slices.Clone(packs)
+	for _, desc := range packs {
+		if idx, ok := m.indexes[desc.Digest]; ok {
+			descs = append(descs, idx)
+		}
+	}
+	return descs
+}
+
+// addPackIndex indexes the packfile at path, of the packfile layer pack, adding
+// the index to the intermediate file store. pack is annotated with the digest
+// of the index.
+func (m *model) addPackIndex(ctx context.Context, path string, pack ocispec.Descriptor) (ocispec.Descriptor, error) {
+	idxPath, err := writePackIndex(path)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := m.addBlob(ctx, filepath.Base(idxPath), oci.MediaTypePackIndex, idxPath)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("adding pack index to intermediate file store: %w", err)
+	}
+	desc = withAnnotations(desc, map[string]string{oci.AnnotationPackIndexPack: pack.Digest.String()})
+
+	if m.indexes == nil {
+		m.indexes = make(map[digest.Digest]ocispec.Descriptor, 1)
+	}
+	m.indexes[pack.Digest] = desc
+	m.newIndexes = append(m.newIndexes, desc)
+	slog.DebugContext(ctx, "added pack index", slog.String("pack", pack.Digest.String()), slog.String("digest", desc.Digest.String()))
+
+	return withAnnotations(pack, map[string]string{oci.AnnotationPackIndex: desc.Digest.String()}), nil
+}
+
+// writePackIndex writes the Git pack index of the packfile at path alongside
+// it, returning its path.
+func writePackIndex(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening packfile: %w", err)
+	}
+	defer f.Close()
+
+	rawIdx, err := indexPack(f)
+	if err != nil {
+		return "", err
+	}
+
+	idxPath := path + ".idx"
+	if err := os.WriteFile(idxPath, rawIdx, 0o644); err != nil {
+		return "", fmt.Errorf("writing pack index: %w", err)
+	}
+
+	return idxPath, nil
+}
+
+// indexPack returns the encoded Git pack index of the packfile read from r.
+func indexPack(r io.Reader) ([]byte, error) {
+	w := new(idxfile.Writer)
+	p, err := packfile.NewParser(packfile.NewScanner(r), w)
+	if err != nil {
+		return nil, fmt.Errorf("initializing packfile parser: %w", err)
+	}
+	if _, err := p.Parse(); err != nil {
+		return nil, fmt.Errorf("indexing packfile: %w", err)
+	}
+	idx, err := w.Index()
+	if err != nil {
+		return nil, fmt.Errorf("indexing packfile: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := idxfile.NewEncoder(buf).Encode(idx); err != nil {
+		return nil, fmt.Errorf("encoding pack index: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *model) FetchPackIndex(ctx context.Context, layer digest.Digest) (io.ReadCloser, error) {
+	desc, ok := m.indexes[layer]
+	if !ok {
+		return nil, fmt.Errorf("%w: packfile layer %s", ErrPackIndexNotFound, layer)
+	}
+	slog.DebugContext(ctx, "fetching pack index", slog.String("pack", layer.String()), slog.String("digest", desc.Digest.String()))
+
+	rc, err := m.fetchBlob(ctx, m.gt, desc)
+	if err != nil {
+		return nil, fmt.Errorf("fetching pack index: %w", err)
+	}
+	return rc, nil
+}
+
+// InstallPack writes a self-contained packfile and its pack index to the
+// object storage of a repository as-is, rather than indexing the packfile. If
+// the index does not match the packfile, the packfile is indexed instead.
+func InstallPack(ctx context.Context, st *filesystem.Storage, pack io.Reader, idx io.Reader) error {
+	if err := WritePack(ctx, st, pack, idx); err != nil {
+		return err
+	}
+	st.Reindex()
+
+	return nil
+}
+
+// WritePack writes a self-contained packfile and its pack index to the object
+// storage of a repository, indexing the packfile locally if idx is nil or
+// does not match it. Unlike [InstallPack], the objects are not visible
+// through st until it is reindexed, such that packfiles may be written
+// concurrently before reindexing st once.
+func WritePack(ctx context.Context, st *filesystem.Storage, pack io.Reader, idx io.Reader) error {
+	var rawIdx []byte
+	memIdx := idxfile.NewMemoryIndex()
+	if idx != nil {
+		var err error
+		rawIdx, err = io.ReadAll(idx)
+		if err != nil {
+			return fmt.Errorf("reading pack index: %w", err)
+		}
+		if err := idxfile.NewDecoder(bytes.NewReader(rawIdx)).Decode(memIdx); err != nil {
+			return fmt.Errorf("decoding pack index: %w", err)
+		}
+	}
+
+	fsys := st.Filesystem()
+	packDir := fsys.Join("objects", "pack")
+	if err := fsys.MkdirAll(packDir, 0o755); err != nil {
+		return fmt.Errorf("creating packfile directory: %w", err)
+	}
+	tmp, err := fsys.TempFile(packDir, "tmp_pack_")
+	if err != nil {
+		return fmt.Errorf("creating temporary packfile: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// no-op once renamed
+		_ = fsys.Remove(tmpPath)
+	}()
+
+	pw := &packWriter{w: tmp}
+	if _, err := io.Copy(pw, pack); err != nil {
+		return errors.Join(fmt.Errorf("writing packfile: %w", err), tmp.Close())
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing packfile: %w", err)
+	}
+
+	if rawIdx != nil {
+		if err := pw.matches(memIdx); err != nil {
+			slog.WarnContext(ctx, "indexing packfile locally", slog.String("error", err.Error()))
+			rawIdx = nil
+		}
+	}
+	if rawIdx == nil {
+		f, err := fsys.Open(tmpPath)
+		if err != nil {
+			return fmt.Errorf("opening packfile: %w", err)
+		}
+		rawIdx, err = indexPack(f)
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("closing packfile: %w", cerr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Git ignores indexes without a packfile, but not the reverse
+	base := fsys.Join(packDir, "pack-"+plumbing.Hash(pw.trailer).String())
+	if err := writeFile(fsys, base+".idx", rawIdx); err != nil {
+		return err
+	}
+	if err := fsys.Rename(tmpPath, base+".pack"); err != nil {
+		return errors.Join(fmt.Errorf("installing packfile: %w", err), fsys.Remove(base+".idx"))
+	}
+
+	return nil
+}
+
+// packWriter writes a packfile, recording its header and trailing checksum.
+type packWriter struct {
+	w       io.Writer
+	header  []byte
+	trailer [hash.Size]byte
+	n       int64
+}
+
+func (p *packWriter) Write(b []byte) (int, error) {
+	if missing := 12 - len(p.header); missing > 0 {
+		p.header = append(p.header, b[:min(missing, len(b))]...)
+	}
+	// shift the trailing checksum by the written bytes
+	if len(b) >= hash.Size {
+		copy(p.trailer[:], b[len(b)-hash.Size:])
+	} else {
+		copy(p.trailer[:], p.trailer[len(b):])
+		copy(p.trailer[hash.Size-len(b):], b)
+	}
+	p.n += int64(len(b))
+	return p.w.Write(b) //nolint:wrapcheck
+}
+
+// matches returns an error if idx does not index the written packfile.
+func (p *packWriter) matches(idx *idxfile.MemoryIndex) error {
+	if len(p.header) < 12 || p.n < 12+hash.Size || string(p.header[:4]) != "PACK" {
+		return fmt.Errorf("%w: invalid packfile", errPackIndexMismatch)
+	}
+	if p.trailer != idx.PackfileChecksum {
+		return fmt.Errorf("%w: checksum %s of index, %s of packfile", errPackIndexMismatch, plumbing.Hash(idx.PackfileChecksum), plumbing.Hash(p.trailer))
+	}
+	count, err := idx.Count()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPackIndexMismatch, err)
+	}
+	if objs := binary.BigEndian.Uint32(p.header[8:12]); int64(objs) != count {
+		return fmt.Errorf("%w: %d objects indexed, %d in packfile", errPackIndexMismatch, count, objs)
+	}
+	return nil
+}
+
+// writeFile writes data to the file at path of fsys.
+func writeFile(fsys billy.Filesystem, path string, data []byte) error {
+	f, err := fsys.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return errors.Join(fmt.Errorf("writing %s: %w", path, err), f.Close())
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package model
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_PackIndex(t *testing.T) {
+	builder, err := testutils.NewRepoBuilder(t.TempDir())
+	assert.NoError(t, err)
+	first, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	second, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	src := builder.Repo().Storer
+	dir := t.TempDir()
+
+	writePackFile := func(tips, ignore []plumbing.Hash) string {
+		t.Helper()
+		objs, err := revlist.Objects(src, tips, ignore)
+		assert.NoError(t, err)
+		buf := new(bytes.Buffer)
+		_, err = packfile.NewEncoder(buf, src, false).Encode(objs, 10)
+		assert.NoError(t, err)
+
+		f, err := os.CreateTemp(dir, "pack-*.pack")
+		assert.NoError(t, err)
+		_, err = f.Write(buf.Bytes())
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+		return f.Name()
+	}
+
+	gt := memory.New()
+	setupRemote(t, gt)
+
+	newModel := func(t *testing.T, opts ...Option) *model {
+		t.Helper()
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, fstore.Close())
+		})
+
+		m := NewModeler(testRemote, fstore, gt, opts...).(*model)
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+		return m
+	}
+
+	firstPack := writePackFile([]plumbing.Hash{first}, nil)
+	secondPack := writePackFile([]plumbing.Hash{second}, []plumbing.Hash{first})
+
+	m := newModel(t, WithPackIndexes())
+	packDesc, err := m.AddPack(t.Context(), firstPack, "", []*object.Commit{{Hash: first}}, plumbing.NewHashReference(plumbing.Main, first))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, PackIndex(packDesc))
+
+	// thin packfiles are not indexed
+	thinDesc, err := m.AddPack(t.Context(), secondPack, packDesc.Digest, []*object.Commit{{Hash: second, ParentHashes: []plumbing.Hash{first}}})
+	assert.NoError(t, err)
+	assert.Empty(t, PackIndex(thinDesc))
+
+	_, err = m.Push(t.Context())
+	assert.NoError(t, err)
+
+	m = newModel(t)
+	assert.Contains(t, m.man.Layers, packDesc)
+	for _, desc := range m.man.Layers {
+		assert.NotEqual(t, oci.MediaTypePackIndex, desc.MediaType)
+	}
+
+	_, err = m.FetchPackIndex(t.Context(), thinDesc.Digest)
+	assert.ErrorIs(t, err, ErrPackIndexNotFound)
+
+	install := func(t *testing.T, idxLayer digest.Digest) *git.Repository {
+		t.Helper()
+
+		repo, err := git.PlainInit(t.TempDir(), true)
+		assert.NoError(t, err)
+		st, ok := repo.Storer.(*filesystem.Storage)
+		assert.True(t, ok)
+
+		idx, err := m.FetchPackIndex(t.Context(), idxLayer)
+		assert.NoError(t, err)
+		defer idx.Close()
+		pack, err := m.FetchLayer(t.Context(), packDesc.Digest)
+		assert.NoError(t, err)
+		defer pack.Close()
+
+		assert.NoError(t, InstallPack(t.Context(), st, pack, idx))
+		return repo
+	}
+
+	t.Run("Matching Index", func(t *testing.T) {
+		repo := install(t, packDesc.Digest)
+
+		_, err := repo.CommitObject(first)
+		assert.NoError(t, err)
+
+		packs, err := repo.Storer.(*filesystem.Storage).ObjectPacks()
+		assert.NoError(t, err)
+		assert.Len(t, packs, 1)
+
+		idx, err := m.FetchPackIndex(t.Context(), packDesc.Digest)
+		assert.NoError(t, err)
+		defer idx.Close()
+		want, err := io.ReadAll(idx)
+		assert.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(repo.Storer.(*filesystem.Storage).Filesystem().Root(), "objects", "pack", "pack-"+packs[0].String()+".idx"))
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "index installed as-is")
+	})
+
+	t.Run("Mismatched Index", func(t *testing.T) {
+		repo, err := git.PlainInit(t.TempDir(), true)
+		assert.NoError(t, err)
+		st := repo.Storer.(*filesystem.Storage)
+
+		// the index of another packfile
+		otherPack := writePackFile([]plumbing.Hash{second}, nil)
+		idxPath, err := writePackIndex(otherPack)
+		assert.NoError(t, err)
+		idx, err := os.Open(idxPath)
+		assert.NoError(t, err)
+		defer idx.Close()
+
+		pack, err := m.FetchLayer(t.Context(), packDesc.Digest)
+		assert.NoError(t, err)
+		defer pack.Close()
+
+		assert.NoError(t, InstallPack(t.Context(), st, pack, idx))
+		_, err = repo.CommitObject(first)
+		assert.NoError(t, err, "packfile indexed locally")
+	})
+	t.Run("Write Without Index", func(t *testing.T) {
+		repo, err := git.PlainInit(t.TempDir(), true)
+		assert.NoError(t, err)
+		st := repo.Storer.(*filesystem.Storage)
+
+		pack, err := m.FetchLayer(t.Context(), packDesc.Digest)
+		assert.NoError(t, err)
+		defer pack.Close()
+
+		assert.NoError(t, WritePack(t.Context(), st, pack, nil))
+		st.Reindex()
+		_, err = repo.CommitObject(first)
+		assert.NoError(t, err, "packfile indexed locally")
+	})
+}
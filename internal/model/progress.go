@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// UploadTracker wraps a packfile layer as it is read for upload, reporting its
+// progress. The returned function is called once the layer is uploaded.
+type UploadTracker func(ctx context.Context, rc io.ReadCloser) (io.ReadCloser, func())
+
+// uploadTrackerKey is the context key of an [UploadTracker].
+type uploadTrackerKey struct{}
+
+// WithUploadTracker returns a copy of ctx reporting the progress of packfile
+// layers uploaded by [Modeler.Push] and [Modeler.PushAtomic] to track.
+func WithUploadTracker(ctx context.Context, track UploadTracker) context.Context {
+	return context.WithValue(ctx, uploadTrackerKey{}, track)
+}
+
+// uploadFetcher returns a fetcher for packfile layers pending upload, tracking
+// their progress if ctx has an [UploadTracker].
+func (m *model) uploadFetcher(ctx context.Context) content.Fetcher {
+	track, ok := ctx.Value(uploadTrackerKey{}).(UploadTracker)
+	if !ok || track == nil {
+		return m.addedBlobs()
+	}
+	return &progressFetcher{Fetcher: m.addedBlobs(), track: track}
+}
+
+// progressFetcher extends a [content.Fetcher] with progress tracking.
+type progressFetcher struct {
+	content.Fetcher
+	track UploadTracker
+}
+
+// Fetch fetches the content identified by desc, tracking its progress until closed.
+func (f *progressFetcher) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := f.Fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	trc, stop := f.track(ctx, rc)
+	return &trackedReadCloser{ReadCloser: trc, stop: stop}, nil
+}
+
+// trackedReadCloser stops progress tracking once closed.
+type trackedReadCloser struct {
+	io.ReadCloser
+	stop func()
+}
+
+// Close closes the underlying stream and stops tracking.
+func (t *trackedReadCloser) Close() error {
+	err := t.ReadCloser.Close()
+	t.stop()
+	return err //nolint:wrapcheck
+}
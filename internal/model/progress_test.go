@@ -0,0 +1,58 @@
+package model
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_uploadFetcher(t *testing.T) {
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() { fstore.Close() })
+	m := &model{fstore: fstore}
+
+	t.Run("No Tracker", func(t *testing.T) {
+		assert.Equal(t, fstore, m.uploadFetcher(t.Context()))
+	})
+
+	t.Run("Tracker", func(t *testing.T) {
+		ctx := WithUploadTracker(t.Context(), func(context.Context, io.ReadCloser) (io.ReadCloser, func()) {
+			return nil, func() {}
+		})
+		assert.IsType(t, &progressFetcher{}, m.uploadFetcher(ctx))
+	})
+}
+
+func Test_progressFetcher_Fetch(t *testing.T) {
+	store := orasmemory.New()
+	desc, err := oras.PushBytes(t.Context(), store, oci.MediaTypePackLayer, []byte("packfile"))
+	assert.NoError(t, err)
+
+	var tracked, stopped int
+	f := &progressFetcher{
+		Fetcher: store,
+		track: func(_ context.Context, rc io.ReadCloser) (io.ReadCloser, func()) {
+			tracked++
+			return rc, func() { stopped++ }
+		},
+	}
+
+	rc, err := f.Fetch(t.Context(), desc)
+	assert.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, "packfile", string(got))
+	assert.Equal(t, 0, stopped)
+
+	assert.NoError(t, rc.Close())
+	assert.Equal(t, 1, tracked)
+	assert.Equal(t, 1, stopped)
+}
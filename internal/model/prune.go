@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// WithOrphanedLayerDeletion deletes packfile layers pruned from the Git
+// manifest from the remote, if supported. Pruned layers otherwise remain in
+// the remote until garbage collected by the registry.
+func WithOrphanedLayerDeletion() Option {
+	return func(m *model) {
+		m.deleteOrphans = true
+	}
+}
+
+// pruneLayers drops packfile layers newer than the newest layer referenced by
+// a remote reference, e.g. after the only branch pointing into it is deleted.
+// Older layers are retained, as they hold the history of newer ones. Returns
+// the pruned layers previously pushed to the remote.
+func (m *model) pruneLayers(ctx context.Context) []ocispec.Descriptor {
+	newest := -1
+	for _, refs := range []map[plumbing.ReferenceName]oci.ReferenceInfo{m.cfg.Heads, m.cfg.Tags, m.cfg.Notes} {
+		for _, info := range refs {
+			// not backed by a packfile if no layers, e.g. the temporary LFS manifest ref
+			for _, layer := range info.AllLayers() {
+				idx := slices.IndexFunc(m.man.Layers, func(desc ocispec.Descriptor) bool {
+					return desc.Digest == layer
+				})
+				newest = max(newest, idx)
+			}
+		}
+	}
+	if newest < 0 || newest == len(m.man.Layers)-1 {
+		// a manifest must have at least one layer, keep all if nothing is referenced
+		return nil
+	}
+
+	pruned := m.man.Layers[newest+1:]
+	m.man.Layers = m.man.Layers[:newest+1]
+
+	orphaned := make([]ocispec.Descriptor, 0, len(pruned))
+	for _, desc := range pruned {
+		slog.InfoContext(ctx, "pruning unreferenced packfile layer", slog.String("digest", desc.Digest.String()))
+		i := slices.IndexFunc(m.newPacks, func(d ocispec.Descriptor) bool { return d.Digest == desc.Digest })
+		if i >= 0 {
+			// never pushed
+			m.newPacks = slices.Delete(m.newPacks, i, i+1)
+			continue
+		}
+		orphaned = append(orphaned, desc)
+	}
+	m.sortRefsByLayer()
+
+	return orphaned
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_pruneLayers(t *testing.T) {
+	const (
+		commitAlpha = "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"
+		commitBeta  = "9f9daae4bb300543116a1508cd9ed87bafd9d5fc"
+	)
+
+	pushLayers := func(t *testing.T, gt oras.GraphTarget) []ocispec.Descriptor {
+		t.Helper()
+
+		layers := make([]ocispec.Descriptor, 0, 3)
+		for _, content := range []string{"layer0", "layer1", "layer2"} {
+			desc, err := oras.PushBytes(t.Context(), gt, oci.MediaTypePackLayer, []byte(content))
+			assert.NoError(t, err)
+			layers = append(layers, desc)
+		}
+		return layers
+	}
+
+	newModel := func(t *testing.T, gt oras.GraphTarget, layers []ocispec.Descriptor, opts ...Option) *model {
+		t.Helper()
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() { fstore.Close() })
+
+		m := &model{
+			ref:     testRemote,
+			gt:      gt,
+			fstore:  fstore,
+			fetched: true,
+			man:     ocispec.Manifest{Layers: layers},
+			cfg: oci.ConfigGit{
+				Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					plumbing.Main: {Commit: commitBeta, Layer: layers[1].Digest},
+				},
+				Tags: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					"refs/tags/v1": {Commit: commitAlpha, Layer: layers[0].Digest},
+				},
+			},
+			refsByLayer: map[digest.Digest][]plumbing.Hash{},
+		}
+		for _, opt := range opts {
+			opt(m)
+		}
+		return m
+	}
+
+	t.Run("Drops Newer Unreferenced Layers", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		layers := pushLayers(t, gt)
+		m := newModel(t, gt, layers)
+
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+
+		// the first layer holds the history of the second
+		assert.Equal(t, layers[:2], m.man.Layers)
+		assert.Empty(t, gt.deleted)
+	})
+
+	t.Run("Deletes Orphaned Layers", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		layers := pushLayers(t, gt)
+		m := newModel(t, gt, layers, WithOrphanedLayerDeletion())
+
+		_, err := m.Push(t.Context())
+		assert.NoError(t, err)
+
+		assert.Equal(t, layers[:2], m.man.Layers)
+		assert.Equal(t, []digest.Digest{layers[2].Digest}, gt.deleted)
+	})
+
+	t.Run("Unpushed Layer Not Deleted", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		layers := pushLayers(t, gt)
+		m := newModel(t, gt, layers, WithOrphanedLayerDeletion())
+		m.newPacks = []ocispec.Descriptor{layers[2]}
+
+		orphaned := m.pruneLayers(t.Context())
+		assert.Empty(t, orphaned)
+		assert.Empty(t, m.newPacks)
+		assert.Equal(t, layers[:2], m.man.Layers)
+	})
+
+	t.Run("No References", func(t *testing.T) {
+		gt := &deleterTarget{GraphTarget: orasmemory.New()}
+		layers := pushLayers(t, gt)
+		m := newModel(t, gt, layers)
+		m.cfg.Heads = map[plumbing.ReferenceName]oci.ReferenceInfo{}
+		m.cfg.Tags = map[plumbing.ReferenceName]oci.ReferenceInfo{}
+
+		orphaned := m.pruneLayers(t.Context())
+		assert.Empty(t, orphaned)
+		assert.Equal(t, layers, m.man.Layers)
+	})
+}
@@ -0,0 +1,187 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// maxPushAttempts bounds the attempts of [Modeler.Push] to tag its manifest,
+// each after rebasing the pushed updates onto the remote as updated by another
+// client.
+const maxPushAttempts = 5
+
+// errTagMoved indicates the remote tag moved since it was fetched, such that
+// tagging would discard the updates of another client.
+var errTagMoved = fmt.Errorf("%w since fetched", ErrConcurrentUpdate)
+
+// tagUnchanged points the remote tag at manDesc, provided the tag has not
+// moved since it was fetched. Throws errTagMoved otherwise.
+func (m *model) tagUnchanged(ctx context.Context, manDesc ocispec.Descriptor) error {
+	current, err := m.gt.Resolve(ctx, m.ref.String())
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		if m.tagDesc.Digest != "" {
+			return fmt.Errorf("%w: tag %s was removed", errTagMoved, m.ref)
+		}
+	case err != nil:
+		return fmt.Errorf("resolving base manifest before tagging: %w", err)
+	case current.Digest != m.tagDesc.Digest:
+		return fmt.Errorf("%w: tag %s moved from %s to %s", errTagMoved, m.ref, m.tagDesc.Digest, current.Digest)
+	}
+
+	if err := m.gt.Tag(ctx, manDesc, m.ref.String()); err != nil {
+		return fmt.Errorf("tagging base manifest: %w", err)
+	}
+
+	return nil
+}
+
+// rebase fetches the remote anew, then reapplies the updates made since it was
+// last fetched: packfile layers are appended to those of the updated remote,
+// and references are updated where the other client left them unchanged or
+// the update is a fast forward of theirs, per the commit-graph. Conflicting
+// updates are reported as a [RefError] each, joined, wrapping
+// [ErrConcurrentUpdate].
+func (m *model) rebase(ctx context.Context) error {
+	base, ours := m.baseCfg, m.cfg
+	ourGraph, err := m.commitGraph(ctx)
+	if err != nil {
+		return err
+	}
+	ourIndexes := m.indexes
+	newPacks, newIndexes := m.newPacks, m.newIndexes
+
+	m.resetFetched()
+	if _, err := m.FetchOrEmpty(ctx); err != nil {
+		return fmt.Errorf("fetching updated remote: %w", err)
+	}
+	graph, err := m.commitGraph(ctx)
+	if err != nil {
+		return err
+	}
+	graph.merge(ourGraph)
+
+	for _, desc := range newPacks {
+		if !containsDigest(m.man.Layers, desc.Digest) {
+			m.man.Layers = append(m.man.Layers, desc)
+		}
+		if idx, ok := ourIndexes[desc.Digest]; ok {
+			if m.indexes == nil {
+				m.indexes = make(map[digest.Digest]ocispec.Descriptor, 1)
+			}
+			m.indexes[desc.Digest] = idx
+		}
+	}
+	m.newPacks, m.newIndexes = newPacks, newIndexes
+
+	if m.cfg.Notes == nil {
+		m.cfg.Notes = make(map[plumbing.ReferenceName]oci.ReferenceInfo)
+	}
+	conflicts := slices.Concat(
+		m.rebaseRefs(ctx, base.Heads, ours.Heads, m.cfg.Heads),
+		m.rebaseRefs(ctx, base.Tags, ours.Tags, m.cfg.Tags),
+		m.rebaseRefs(ctx, base.Notes, ours.Notes, m.cfg.Notes),
+	)
+
+	if ours.DefaultBranch != base.DefaultBranch {
+		switch m.cfg.DefaultBranch {
+		case base.DefaultBranch, ours.DefaultBranch:
+			m.cfg.DefaultBranch = ours.DefaultBranch
+		default:
+			conflicts = append(conflicts, fmt.Errorf("%w: default branch set to %s", ErrConcurrentUpdate, m.cfg.DefaultBranch))
+		}
+	}
+	if ours.ObjectFormat != base.ObjectFormat {
+		switch m.cfg.ObjectFormat {
+		case base.ObjectFormat, ours.ObjectFormat:
+			m.cfg.ObjectFormat = ours.ObjectFormat
+		default:
+			conflicts = append(conflicts, fmt.Errorf("%w: object format set to %s", ErrConcurrentUpdate, m.cfg.ObjectFormat))
+		}
+	}
+	if !slices.Equal(ours.Shallow, base.Shallow) {
+		switch {
+		case slices.Equal(m.cfg.Shallow, base.Shallow), slices.Equal(m.cfg.Shallow, ours.Shallow):
+			m.cfg.Shallow = ours.Shallow
+		default:
+			conflicts = append(conflicts, fmt.Errorf("%w: shallow boundary updated", ErrConcurrentUpdate))
+		}
+	}
+	m.sortRefsByLayer()
+
+	return errors.Join(conflicts...)
+}
+
+// rebaseRefs reapplies the updates of references from base to ours onto
+// theirs, the references of the updated remote. Returns a [RefError] for each
+// reference updated differently by both.
+func (m *model) rebaseRefs(ctx context.Context, base, ours, theirs map[plumbing.ReferenceName]oci.ReferenceInfo) []error {
+	names := slices.Sorted(maps.Keys(ours))
+	for name := range base {
+		if _, ok := ours[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	var conflicts []error
+	for _, name := range names {
+		b, inBase := base[name]
+		o, inOurs := ours[name]
+		if inBase == inOurs && (!inBase || refInfoEqual(b, o)) {
+			// not updated since fetched
+			continue
+		}
+
+		t, inTheirs := theirs[name]
+		theirsUnchanged := inTheirs == inBase && (!inBase || t.Commit == b.Commit)
+		switch {
+		case inTheirs == inOurs && (!inOurs || (t.Commit == o.Commit && t.Peeled == o.Peeled)):
+			// updated identically by both
+		case !inOurs && theirsUnchanged:
+			delete(theirs, name)
+		case inOurs && (theirsUnchanged || inTheirs && m.graph.isAncestor(plumbing.NewHash(t.Commit), plumbing.NewHash(o.Commit))):
+			// a fast forward of theirs
+			if i := slices.IndexFunc(o.AllLayers(), func(d digest.Digest) bool { return !containsDigest(m.man.Layers, d) }); i >= 0 {
+				conflicts = append(conflicts, &RefError{Ref: name, Err: fmt.Errorf("%w: packfile layer %s was pruned", ErrConcurrentUpdate, o.AllLayers()[i])})
+				continue
+			}
+			slog.DebugContext(ctx, "rebasing reference update", slog.String("reference", name.String()), slog.String("commit", o.Commit))
+			o.Layers = m.historyLayers(ctx, plumbing.NewHash(o.Commit), o.Layer)
+			theirs[name] = o
+		default:
+			got := "deleted"
+			if inTheirs {
+				got = "updated to " + t.Commit
+			}
+			conflicts = append(conflicts, &RefError{Ref: name, Err: fmt.Errorf("%w: %s", ErrConcurrentUpdate, got)})
+		}
+	}
+
+	return conflicts
+}
+
+// refInfoEqual returns true if a and b are identical.
+func refInfoEqual(a, b oci.ReferenceInfo) bool {
+	return a.Commit == b.Commit && a.Layer == b.Layer && a.Peeled == b.Peeled && slices.Equal(a.Layers, b.Layers)
+}
+
+// cloneConfig returns a copy of cfg, whose references may be updated without
+// affecting cfg.
+func cloneConfig(cfg oci.ConfigGit) oci.ConfigGit {
+	cfg.Heads = maps.Clone(cfg.Heads)
+	cfg.Tags = maps.Clone(cfg.Tags)
+	cfg.Notes = maps.Clone(cfg.Notes)
+	cfg.Shallow = slices.Clone(cfg.Shallow)
+	return cfg
+}
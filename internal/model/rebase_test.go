@@ -0,0 +1,108 @@
+package model
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func Test_model_Push_Rebase(t *testing.T) {
+	newModel := func(t *testing.T, gt oras.GraphTarget) *model {
+		t.Helper()
+
+		fstore, err := file.New(t.TempDir())
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, fstore.Close())
+		})
+
+		m := &model{ref: testRemote, gt: gt, fstore: fstore}
+		_, err = m.FetchOrDefault(t.Context())
+		assert.NoError(t, err)
+		return m
+	}
+	commitX := plumbing.NewHash("1111111111111111111111111111111111111111")
+	commitY := plumbing.NewHash("2222222222222222222222222222222222222222")
+
+	t.Run("Rebase", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newModel(t, gt)
+		layer := m.man.Layers[0].Digest
+
+		// another client pushes after m fetched
+		other := newModel(t, gt)
+		assert.NoError(t, other.UpdateRef(t.Context(), plumbing.NewHashReference("refs/tags/other", commitX), layer))
+		assert.NoError(t, other.DeleteRef(t.Context(), "refs/tags/foobar"))
+		_, err := other.Push(t.Context())
+		assert.NoError(t, err)
+
+		assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/tags/new", commitY), layer))
+		assert.NoError(t, m.SetDefaultBranch(t.Context(), plumbing.Main))
+		manDesc, err := m.Push(t.Context())
+		assert.NoError(t, err)
+
+		got, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		assert.Equal(t, manDesc.Digest, got.Digest)
+
+		// both updates are kept
+		fetched := newModel(t, gt)
+		assert.ElementsMatch(t, []plumbing.ReferenceName{"refs/tags/other", "refs/tags/new"}, slices.Collect(maps.Keys(fetched.TagRefs())))
+		assert.Equal(t, plumbing.Main, fetched.DefaultBranch())
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newModel(t, gt)
+		layer := m.man.Layers[0].Digest
+
+		other := newModel(t, gt)
+		assert.NoError(t, other.UpdateRef(t.Context(), plumbing.NewHashReference(plumbing.Main, commitX), layer))
+		otherDesc, err := other.Push(t.Context())
+		assert.NoError(t, err)
+
+		assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference(plumbing.Main, commitY), layer))
+		_, err = m.Push(t.Context())
+		assert.ErrorIs(t, err, ErrConcurrentUpdate)
+		var refErr *RefError
+		assert.ErrorAs(t, err, &refErr)
+		assert.Equal(t, plumbing.Main, refErr.Ref)
+
+		got, err := gt.Resolve(t.Context(), testRemote.String())
+		assert.NoError(t, err)
+		assert.Equal(t, otherDesc.Digest, got.Digest)
+	})
+
+	t.Run("Fast Forward", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newModel(t, gt)
+		layer := m.man.Layers[0].Digest
+
+		other := newModel(t, gt)
+		assert.NoError(t, other.UpdateRef(t.Context(), plumbing.NewHashReference(plumbing.Main, commitX), layer))
+		_, err := other.Push(t.Context())
+		assert.NoError(t, err)
+
+		// commitY is a child of the commit pushed by the other client
+		m.graph = newCommitGraph()
+		m.graph.add(layer, []*object.Commit{{Hash: commitY, ParentHashes: []plumbing.Hash{commitX}}})
+		assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference(plumbing.Main, commitY), layer))
+		_, err = m.Push(t.Context())
+		assert.NoError(t, err)
+
+		fetched := newModel(t, gt)
+		ref, _, err := fetched.ResolveRef(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		assert.Equal(t, commitY, ref.Hash())
+	})
+}
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/errcode"
 )
 
 // ReferrerUpdater updates referring manifests to a new subject descriptor.
@@ -33,3 +37,52 @@ func UpdateLFSReferrer(m LFSModeler) ReferrerUpdater {
 		return nil
 	}
 }
+
+// listReferrers lists the referrers of subject with artifactType in gt.
+//
+// The distribution spec requires registries without the Referrers API to
+// respond not found, which oras handles by falling back to the referrers tag
+// schema. Registries rejecting the request with another status are retried
+// with the tag schema.
+func listReferrers(ctx context.Context, gt Store, subject ocispec.Descriptor, artifactType string) ([]ocispec.Descriptor, error) {
+	referrers, err := registry.Referrers(ctx, gt, subject, artifactType)
+	if err == nil || !referrersUnsupported(err) {
+		return referrers, err //nolint:wrapcheck
+	}
+
+	repo, ok := remoteRepository(gt)
+	if !ok {
+		return nil, err //nolint:wrapcheck
+	}
+	if serr := repo.SetReferrersCapability(false); serr != nil {
+		// capability already detected, the failure is unrelated
+		return nil, err //nolint:wrapcheck
+	}
+	slog.WarnContext(ctx, "registry rejected the referrers API, falling back to the referrers tag schema",
+		slog.String("error", err.Error()))
+
+	referrers, err = registry.Referrers(ctx, gt, subject, artifactType)
+	if err != nil {
+		return nil, fmt.Errorf("listing referrers with the tag schema: %w", err)
+	}
+	return referrers, nil
+}
+
+// referrersUnsupported returns true if err indicates a registry does not
+// support the Referrers API.
+func referrersUnsupported(err error) bool {
+	if errors.Is(err, errdef.ErrUnsupported) {
+		return true
+	}
+
+	var errResp *errcode.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return false
+	}
+	switch errResp.StatusCode {
+	case http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// noReferrersRegistry is a minimal registry without the Referrers API,
+// responding to referrers requests with referrersStatus. Subjects of pushed
+// manifests are not acknowledged, such that clients index referrers with the
+// tag schema.
+type noReferrersRegistry struct {
+	mu              sync.Mutex
+	referrersStatus int
+	blobs           map[digest.Digest][]byte
+	manifests       map[digest.Digest][]byte
+	mediaTypes      map[digest.Digest]string
+	tags            map[string]digest.Digest
+	referrersCalls  int
+}
+
+func newNoReferrersRegistry(status int) *noReferrersRegistry {
+	return &noReferrersRegistry{
+		referrersStatus: status,
+		blobs:           map[digest.Digest][]byte{},
+		manifests:       map[digest.Digest][]byte{},
+		mediaTypes:      map[digest.Digest]string{},
+		tags:            map[string]digest.Digest{},
+	}
+}
+
+func (reg *noReferrersRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	const location = "/v2/repo/blobs/uploads/session"
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/v2/repo/referrers/"):
+		reg.referrersCalls++
+		w.WriteHeader(reg.referrersStatus)
+	case r.Method == http.MethodPost && path == "/v2/repo/blobs/uploads/":
+		w.Header().Set("Location", location)
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && path == location:
+		body, _ := io.ReadAll(r.Body)
+		reg.blobs[digest.FromBytes(body)] = body
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "/v2/repo/blobs/"):
+		blob, ok := reg.blobs[digest.Digest(strings.TrimPrefix(path, "/v2/repo/blobs/"))]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		reg.write(w, r, "application/octet-stream", blob)
+	case strings.HasPrefix(path, "/v2/repo/manifests/"):
+		reg.serveManifest(w, r, strings.TrimPrefix(path, "/v2/repo/manifests/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (reg *noReferrersRegistry) serveManifest(w http.ResponseWriter, r *http.Request, ref string) {
+	dgst, err := digest.Parse(ref)
+	if err != nil {
+		dgst = reg.tags[ref]
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		dgst = digest.FromBytes(body)
+		reg.manifests[dgst] = body
+		reg.mediaTypes[dgst] = r.Header.Get("Content-Type")
+		if ref != dgst.String() {
+			reg.tags[ref] = dgst
+		}
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		delete(reg.manifests, dgst)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		man, ok := reg.manifests[dgst]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		reg.write(w, r, reg.mediaTypes[dgst], man)
+	}
+}
+
+func (reg *noReferrersRegistry) write(w http.ResponseWriter, r *http.Request, mediaType string, body []byte) {
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(body).String())
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+func Test_model_referrers(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusBadRequest, http.StatusMethodNotAllowed} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			reg := newNoReferrersRegistry(status)
+			srv := httptest.NewServer(reg)
+			defer srv.Close()
+
+			u, err := url.Parse(srv.URL)
+			assert.NoError(t, err)
+			newRepo := func() *remote.Repository {
+				repo, err := remote.NewRepository(u.Host + "/repo")
+				assert.NoError(t, err)
+				repo.PlainHTTP = true
+				return repo
+			}
+
+			subject, err := oras.PackManifest(t.Context(), newRepo(), oras.PackManifestVersion1_1, oci.ArtifactTypeGitManifest, oras.PackManifestOptions{})
+			assert.NoError(t, err)
+
+			fstore, err := file.New(t.TempDir())
+			assert.NoError(t, err)
+			defer fstore.Close()
+
+			lfsFile := filepath.Join(t.TempDir(), "oid")
+			assert.NoError(t, os.WriteFile(lfsFile, []byte("example file contents"), 0o644))
+
+			pusher := &model{ref: testRemote, gt: newRepo(), fstore: fstore, fetched: true, manDesc: subject}
+			lfsDesc, err := pusher.PushLFSFile(t.Context(), lfsFile, &PushLFSOptions{})
+			assert.NoError(t, err)
+			lfsManDesc, err := pusher.PushLFSManifest(t.Context(), subject)
+			assert.NoError(t, err)
+
+			// a new client detects the capability again
+			fetcher := &model{ref: testRemote, gt: newRepo(), fstore: fstore, fetched: true, manDesc: subject}
+			got, err := fetcher.FetchLFS(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, lfsManDesc.Digest, got.Digest)
+			assert.Equal(t, []ocispec.Descriptor{lfsDesc}, fetcher.lfsMan.Layers)
+			assert.Positive(t, reg.referrersCalls)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+// releaseIndexSuffix is appended to the remote tag to form the tag of the
+// release index.
+const releaseIndexSuffix = "-releases"
+
+// ErrReleaseExists indicates a release tag already exists, releases are
+// immutable.
+var ErrReleaseExists = errors.New("release already exists")
+
+// Release is a release of a repository, an image index of a Git manifest and
+// its LFS manifest.
+type Release struct {
+	// Tag is the tag of the release index.
+	Tag string `json:"tag"`
+	// Digest is the digest of the release index.
+	Digest digest.Digest `json:"digest"`
+	// Revision is the commit of the default branch when released, if any.
+	Revision string `json:"revision,omitempty"`
+	// Created is the creation time of the release, RFC 3339 formatted.
+	Created string `json:"created"`
+}
+
+func (m *model) TagRelease(ctx context.Context, tag string) (ocispec.Descriptor, error) {
+	if m.manDesc.Digest == "" {
+		return ocispec.Descriptor{}, errManifestNotPushed
+	}
+
+	releaseRef := m.ref
+	releaseRef.Reference = tag
+	if err := releaseRef.ValidateReferenceAsTag(); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("validating release tag: %w", err)
+	}
+	// registries cannot tag conditionally, such that a release tagged
+	// concurrently between resolving and tagging is overwritten
+	_, err := m.gt.Resolve(ctx, releaseRef.String())
+	switch {
+	case err == nil:
+		return ocispec.Descriptor{}, fmt.Errorf("%w: %s", ErrReleaseExists, tag)
+	case !errors.Is(err, errdef.ErrNotFound):
+		return ocispec.Descriptor{}, fmt.Errorf("resolving release tag: %w", err)
+	}
+
+	manifests := []ocispec.Descriptor{m.manDesc}
+	switch {
+	case m.lfsManDesc.Digest == "":
+	case m.lfsGT != nil:
+		// an index may only reference manifests of its own repository
+		slog.WarnContext(ctx, "LFS manifest is stored in a separate repository, not included in release")
+	default:
+		manifests = append(manifests, m.lfsManDesc)
+	}
+
+	annotations := m.manifestAnnotations()
+	annotations[ocispec.AnnotationRefName] = tag
+	idx := ocispec.Index{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: oci.ArtifactTypeGitRelease,
+		Manifests:    manifests,
+		Annotations:  annotations,
+	}
+	idxRaw, err := json.Marshal(idx)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("encoding release index: %w", err)
+	}
+	idxDesc, err := oras.PushBytes(ctx, existingPusher{m.gt}, ocispec.MediaTypeImageIndex, idxRaw)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("pushing release index: %w", err)
+	}
+	slog.DebugContext(ctx, "tagging release", slog.String("tag", tag), slog.String("digest", idxDesc.Digest.String()))
+	if err := m.gt.Tag(ctx, idxDesc, releaseRef.String()); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("tagging release %s: %w", tag, err)
+	}
+
+	release := idxDesc
+	release.ArtifactType = oci.ArtifactTypeGitRelease
+	release.Annotations = maps.Clone(annotations)
+	delete(release.Annotations, ocispec.AnnotationSource)
+	if err := m.recordInIndex(ctx, m.releaseIndexTag(), oci.ArtifactTypeGitReleases, release); err != nil {
+		return idxDesc, fmt.Errorf("updating release index: %w", err)
+	}
+
+	return idxDesc, nil
+}
+
+func (m *model) Releases(ctx context.Context) ([]Release, error) {
+	idx, err := m.fetchIndex(ctx, m.releaseIndexTag(), oci.ArtifactTypeGitReleases)
+	if err != nil {
+		return nil, fmt.Errorf("fetching release index: %w", err)
+	}
+
+	releases := make([]Release, 0, len(idx.Manifests))
+	for _, desc := range idx.Manifests {
+		releases = append(releases, Release{
+			Tag:      desc.Annotations[ocispec.AnnotationRefName],
+			Digest:   desc.Digest,
+			Revision: desc.Annotations[ocispec.AnnotationRevision],
+			Created:  desc.Annotations[ocispec.AnnotationCreated],
+		})
+	}
+
+	return releases, nil
+}
+
+// errNotRelease indicates an image index is not a release.
+var errNotRelease = errors.New("image index is not a release")
+
+// releaseManifest returns the Git manifest of the release index desc, the
+// first manifest of the index.
+func (m *model) releaseManifest(ctx context.Context, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	idxRaw, err := content.FetchAll(ctx, m.gt, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("fetching release index: %w", err)
+	}
+	var idx ocispec.Index
+	if err := json.Unmarshal(idxRaw, &idx); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("decoding release index: %w", err)
+	}
+	if idx.ArtifactType != oci.ArtifactTypeGitRelease || len(idx.Manifests) < 1 {
+		return ocispec.Descriptor{}, fmt.Errorf("%w: artifact type %q", errNotRelease, idx.ArtifactType)
+	}
+
+	slog.DebugContext(ctx, "resolved release", slog.String("tag", idx.Annotations[ocispec.AnnotationRefName]))
+	return idx.Manifests[0], nil
+}
+
+// releasedManifests returns the digests of the Git and LFS manifests of the
+// releases recorded in the release index.
+func (m *model) releasedManifests(ctx context.Context) (map[digest.Digest]struct{}, error) {
+	idx, err := m.fetchIndex(ctx, m.releaseIndexTag(), oci.ArtifactTypeGitReleases)
+	if err != nil {
+		return nil, fmt.Errorf("fetching release index: %w", err)
+	}
+
+	released := make(map[digest.Digest]struct{}, 2*len(idx.Manifests))
+	for _, desc := range idx.Manifests {
+		releaseRaw, err := content.FetchAll(ctx, m.gt, desc)
+		switch {
+		case errors.Is(err, errdef.ErrNotFound):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("fetching release %s: %w", desc.Digest, err)
+		}
+		var release ocispec.Index
+		if err := json.Unmarshal(releaseRaw, &release); err != nil {
+			return nil, fmt.Errorf("decoding release %s: %w", desc.Digest, err)
+		}
+		for _, man := range release.Manifests {
+			released[man.Digest] = struct{}{}
+		}
+	}
+
+	return released, nil
+}
+
+// releaseIndexTag returns the tag of the release index, e.g. sync-releases.
+func (m *model) releaseIndexTag() string {
+	idxRef := m.ref
+	idxRef.Reference += releaseIndexSuffix
+	return idxRef.String()
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_TagRelease(t *testing.T) {
+	const commitAlpha = "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"
+
+	newModel := func(t *testing.T, gt oras.GraphTarget) *model {
+		t.Helper()
+
+		manDesc, err := oras.PushBytes(t.Context(), gt, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+		assert.NoError(t, err)
+
+		return &model{
+			ref:     testRemote,
+			gt:      gt,
+			fetched: true,
+			manDesc: manDesc,
+			cfg: oci.ConfigGit{
+				DefaultBranch: plumbing.Main,
+				Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					plumbing.Main: {Commit: commitAlpha},
+				},
+			},
+		}
+	}
+
+	fetchRelease := func(t *testing.T, gt oras.GraphTarget, tag string) ocispec.Index {
+		t.Helper()
+
+		releaseRef := testRemote
+		releaseRef.Reference = tag
+		idxDesc, err := gt.Resolve(t.Context(), releaseRef.String())
+		assert.NoError(t, err)
+		idxRaw, err := content.FetchAll(t.Context(), gt, idxDesc)
+		assert.NoError(t, err)
+
+		var idx ocispec.Index
+		assert.NoError(t, json.Unmarshal(idxRaw, &idx))
+		return idx
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := newModel(t, gt)
+
+		idxDesc, err := m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+		assert.Equal(t, ocispec.MediaTypeImageIndex, idxDesc.MediaType)
+
+		idx := fetchRelease(t, gt, "v1.0.0")
+		assert.Equal(t, oci.ArtifactTypeGitRelease, idx.ArtifactType)
+		assert.Equal(t, []ocispec.Descriptor{m.manDesc}, idx.Manifests)
+		assert.Equal(t, "v1.0.0", idx.Annotations[ocispec.AnnotationRefName])
+		assert.Equal(t, commitAlpha, idx.Annotations[ocispec.AnnotationRevision])
+
+		releases, err := m.Releases(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, []Release{{
+			Tag:      "v1.0.0",
+			Digest:   idxDesc.Digest,
+			Revision: commitAlpha,
+			Created:  "1970-01-01T00:00:00Z",
+		}}, releases)
+	})
+
+	t.Run("With LFS Manifest", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := newModel(t, gt)
+		lfsManDesc, err := oras.PushBytes(t.Context(), gt, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"artifactType":"lfs"}`))
+		assert.NoError(t, err)
+		m.lfsManDesc = lfsManDesc
+
+		_, err = m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+
+		idx := fetchRelease(t, gt, "v1.0.0")
+		assert.Equal(t, []ocispec.Descriptor{m.manDesc, lfsManDesc}, idx.Manifests)
+	})
+
+	t.Run("Separate LFS Store", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := newModel(t, gt)
+		m.lfsGT = orasmemory.New()
+		m.lfsManDesc = ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03", Size: 6}
+
+		_, err := m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+
+		idx := fetchRelease(t, gt, "v1.0.0")
+		assert.Equal(t, []ocispec.Descriptor{m.manDesc}, idx.Manifests)
+	})
+
+	t.Run("Index Accumulates Releases", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := newModel(t, gt)
+
+		_, err := m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+		_, err = m.TagRelease(t.Context(), "v1.1.0")
+		assert.NoError(t, err)
+
+		releases, err := m.Releases(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, releases, 2)
+		assert.Equal(t, "v1.0.0", releases[0].Tag)
+		assert.Equal(t, "v1.1.0", releases[1].Tag)
+	})
+
+	t.Run("Release Exists", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := newModel(t, gt)
+
+		_, err := m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+		_, err = m.TagRelease(t.Context(), "v1.0.0")
+		assert.ErrorIs(t, err, ErrReleaseExists)
+	})
+
+	t.Run("Push To Release", func(t *testing.T) {
+		gt := orasmemory.New()
+		setupRemote(t, gt)
+		m := &model{ref: testRemote, gt: gt}
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+		idxDesc, err := m.TagRelease(t.Context(), "v1.0.0")
+		assert.NoError(t, err)
+
+		releaseRef := testRemote
+		releaseRef.Reference = "v1.0.0"
+		m = &model{ref: releaseRef, gt: gt}
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+		assert.NoError(t, m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/heads/overwrite", plumbing.ZeroHash), m.Layers()[0].Digest))
+
+		_, err = m.Push(t.Context())
+		assert.ErrorIs(t, err, ErrReleaseExists)
+		_, err = m.PushLFSManifest(t.Context(), m.manDesc)
+		assert.ErrorIs(t, err, ErrReleaseExists)
+
+		// the release is unchanged
+		got, err := gt.Resolve(t.Context(), releaseRef.String())
+		assert.NoError(t, err)
+		assert.Equal(t, idxDesc.Digest, got.Digest)
+	})
+
+	t.Run("Invalid Tag", func(t *testing.T) {
+		m := newModel(t, orasmemory.New())
+
+		_, err := m.TagRelease(t.Context(), "v1.0.0+build")
+		assert.Error(t, err)
+	})
+
+	t.Run("Not Pushed", func(t *testing.T) {
+		m := &model{ref: testRemote, gt: orasmemory.New()}
+
+		_, err := m.TagRelease(t.Context(), "v1.0.0")
+		assert.ErrorIs(t, err, errManifestNotPushed)
+	})
+
+	t.Run("No Releases", func(t *testing.T) {
+		m := newModel(t, orasmemory.New())
+
+		releases, err := m.Releases(t.Context())
+		assert.NoError(t, err)
+		assert.Empty(t, releases)
+	})
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ErrShallowExisting indicates the shallow boundary of a remote with packfile
+// layers was changed.
+var ErrShallowExisting = errors.New("shallow boundary of an existing remote cannot be changed")
+
+func (m *model) Shallow() []plumbing.Hash {
+	if len(m.cfg.Shallow) == 0 {
+		return nil
+	}
+
+	commits := make([]plumbing.Hash, 0, len(m.cfg.Shallow))
+	for _, c := range m.cfg.Shallow {
+		commits = append(commits, plumbing.NewHash(c))
+	}
+	return commits
+}
+
+func (m *model) SetShallow(ctx context.Context, commits []plumbing.Hash) error {
+	slog.DebugContext(ctx, "setting shallow boundary", slog.Int("commits", len(commits)))
+	if len(m.man.Layers) > 0 {
+		return fmt.Errorf("%w: remote has %d packfile layers", ErrShallowExisting, len(m.man.Layers))
+	}
+
+	hexes := make([]string, 0, len(commits))
+	for _, c := range commits {
+		hexes = append(hexes, c.String())
+	}
+	slices.Sort(hexes)
+	m.cfg.Shallow = slices.Compact(hexes)
+	if len(m.cfg.Shallow) == 0 {
+		m.cfg.Shallow = nil
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_model_SetShallow(t *testing.T) {
+	a := plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74")
+	b := plumbing.NewHash("21023d360200012cefcd8f077b3b24aea7cb20f2")
+
+	t.Run("New Remote", func(t *testing.T) {
+		m := &model{}
+
+		err := m.SetShallow(t.Context(), []plumbing.Hash{a, b, a})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{b.String(), a.String()}, m.cfg.Shallow)
+		assert.Equal(t, []plumbing.Hash{b, a}, m.Shallow())
+	})
+
+	t.Run("Empty Boundary", func(t *testing.T) {
+		m := &model{}
+
+		err := m.SetShallow(t.Context(), nil)
+		assert.NoError(t, err)
+		assert.Nil(t, m.cfg.Shallow)
+		assert.Nil(t, m.Shallow())
+	})
+
+	t.Run("Existing Remote", func(t *testing.T) {
+		m := &model{
+			man: ocispec.Manifest{Layers: []ocispec.Descriptor{{MediaType: oci.MediaTypePackLayer}}},
+		}
+
+		err := m.SetShallow(t.Context(), []plumbing.Hash{a})
+		assert.ErrorIs(t, err, ErrShallowExisting)
+		assert.Nil(t, m.cfg.Shallow)
+	})
+}
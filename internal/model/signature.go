@@ -0,0 +1,271 @@
+package model
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+var (
+	// ErrSignatureNotFound indicates a Git manifest has no signature referrers.
+	ErrSignatureNotFound = errors.New("signature not found")
+	// ErrSignatureInvalid indicates no signature of a Git manifest could be
+	// verified with the trusted keys.
+	ErrSignatureInvalid = errors.New("signature verification failed")
+)
+
+// WithSigner signs each pushed Git manifest before it is tagged.
+func WithSigner(signer crypto.Signer) Option {
+	return func(m *model) {
+		m.signer = signer
+	}
+}
+
+// WithVerifyPolicy requires fetched Git manifests to be signed by one of keys.
+// Fetching fails if the signature cannot be verified.
+func WithVerifyPolicy(keys ...crypto.PublicKey) Option {
+	return func(m *model) {
+		m.verifyKeys = keys
+	}
+}
+
+func (m *model) Sign(ctx context.Context, signer crypto.Signer) (ocispec.Descriptor, error) {
+	if m.manDesc.Digest == "" {
+		return ocispec.Descriptor{}, errors.New("git manifest must be fetched before signing")
+	}
+
+	return m.sign(ctx, signer, m.manDesc)
+}
+
+// sign pushes a signature referrer manifest for subject.
+func (m *model) sign(ctx context.Context, signer crypto.Signer, subject ocispec.Descriptor) (ocispec.Descriptor, error) {
+	slog.DebugContext(ctx, "signing git manifest", slog.String("subjectDigest", subject.Digest.String()))
+
+	payload, err := json.Marshal(oci.SignaturePayload{Subject: subject.Digest})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("encoding signature payload: %w", err)
+	}
+
+	sig, err := signPayload(signer, payload)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	payloadDesc, err := oras.PushBytes(ctx, m.gt, oci.MediaTypeSignaturePayload, payload)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("pushing signature payload: %w", err)
+	}
+	payloadDesc.Annotations = map[string]string{oci.AnnotationSignature: base64.StdEncoding.EncodeToString(sig)}
+
+	manOpts := oras.PackManifestOptions{
+		Subject:             &subject,
+		Layers:              []ocispec.Descriptor{payloadDesc},
+		ManifestAnnotations: map[string]string{ocispec.AnnotationCreated: m.created()},
+	}
+
+	sigManDesc, err := oras.PackManifest(ctx, m.gt, oras.PackManifestVersion1_1, oci.ArtifactTypeSignature, manOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("packing and pushing signature manifest: %w", err)
+	}
+	slog.DebugContext(ctx, "pushed signature manifest", slog.String("digest", sigManDesc.Digest.String()))
+
+	return sigManDesc, nil
+}
+
+func (m *model) Verify(ctx context.Context, keys ...crypto.PublicKey) error {
+	if m.manDesc.Digest == "" {
+		return errors.New("git manifest must be fetched before verifying")
+	}
+
+	return m.verify(ctx, m.manDesc, keys)
+}
+
+// verify succeeds if any signature referrer of subject is verified by any of
+// keys.
+func (m *model) verify(ctx context.Context, subject ocispec.Descriptor, keys []crypto.PublicKey) error {
+	slog.DebugContext(ctx, "verifying git manifest signature", slog.String("subjectDigest", subject.Digest.String()))
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: no trusted keys", ErrSignatureInvalid)
+	}
+
+	referrers, err := listReferrers(ctx, m.gt, subject, oci.ArtifactTypeSignature)
+	if err != nil {
+		return fmt.Errorf("resolving signature referrers: %w", err)
+	}
+	if len(referrers) == 0 {
+		return fmt.Errorf("%w: git manifest %s", ErrSignatureNotFound, subject.Digest)
+	}
+
+	var errs []error
+	for _, desc := range referrers {
+		err := m.verifySignatureManifest(ctx, subject, desc, keys)
+		if err == nil {
+			slog.DebugContext(ctx, "verified git manifest signature", slog.String("signatureDigest", desc.Digest.String()))
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("signature manifest %s: %w", desc.Digest, err))
+	}
+
+	return fmt.Errorf("%w: git manifest %s: %w", ErrSignatureInvalid, subject.Digest, errors.Join(errs...))
+}
+
+// verifySignatureManifest verifies the signature manifest described by desc
+// is a signature of subject by any of keys.
+func (m *model) verifySignatureManifest(ctx context.Context, subject, desc ocispec.Descriptor, keys []crypto.PublicKey) error {
+	manRaw, err := content.FetchAll(ctx, m.gt, desc)
+	if err != nil {
+		return fmt.Errorf("fetching manifest: %w", err)
+	}
+
+	var man ocispec.Manifest
+	if err := json.Unmarshal(manRaw, &man); err != nil {
+		return fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	for _, layer := range man.Layers {
+		if layer.MediaType != oci.MediaTypeSignaturePayload {
+			continue
+		}
+
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[oci.AnnotationSignature])
+		if err != nil {
+			return fmt.Errorf("decoding signature: %w", err)
+		}
+
+		payload, err := content.FetchAll(ctx, m.gt, layer)
+		if err != nil {
+			return fmt.Errorf("fetching signature payload: %w", err)
+		}
+
+		var p oci.SignaturePayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("decoding signature payload: %w", err)
+		}
+		if p.Subject != subject.Digest {
+			return fmt.Errorf("payload signs %s", p.Subject)
+		}
+
+		for _, key := range keys {
+			if err := verifyPayload(key, payload, sig); err == nil {
+				return nil
+			}
+		}
+		return errors.New("not signed by a trusted key")
+	}
+
+	return errors.New("no signature payload")
+}
+
+// signPayload signs payload, hashing with SHA-256 unless the key signs
+// messages directly.
+func signPayload(signer crypto.Signer, payload []byte) ([]byte, error) {
+	var sig []byte
+	var err error
+	switch signer.Public().(type) {
+	case ed25519.PublicKey:
+		sig, err = signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		h := sha256.Sum256(payload)
+		sig, err = signer.Sign(rand.Reader, h[:], crypto.SHA256)
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", signer.Public())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing payload: %w", err)
+	}
+
+	return sig, nil
+}
+
+// verifyPayload verifies sig is a signature of payload by key, as created by
+// [signPayload].
+func verifyPayload(key crypto.PublicKey, payload, sig []byte) error {
+	h := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return ErrSignatureInvalid
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, h[:], sig) {
+			return ErrSignatureInvalid
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig); err != nil {
+			return ErrSignatureInvalid
+		}
+	default:
+		return fmt.Errorf("unsupported verification key type %T", key)
+	}
+
+	return nil
+}
+
+// LoadSigner reads a PEM encoded PKCS #8 private key. ECDSA, Ed25519, and RSA
+// keys are supported.
+func LoadSigner(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key %s: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+// LoadPublicKey reads a PEM encoded PKIX public key. ECDSA, Ed25519, and RSA
+// keys are supported.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key %s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key file: %w", err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	return block, nil
+}
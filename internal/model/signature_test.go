@@ -0,0 +1,198 @@
+package model
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func newSignatureModel(t *testing.T, gt oras.GraphTarget, opts ...Option) *model {
+	t.Helper()
+
+	fstore, err := file.New(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, fstore.Close())
+	})
+
+	return NewModeler(testRemote, fstore, gt, opts...).(*model)
+}
+
+func Test_model_SignVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt)
+
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.Sign(t.Context(), key)
+		assert.NoError(t, err)
+
+		err = m.Verify(t.Context(), other.Public(), key.Public())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Untrusted Key", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt)
+
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		_, err = m.Sign(t.Context(), key)
+		assert.NoError(t, err)
+
+		err = m.Verify(t.Context(), other.Public())
+		assert.ErrorIs(t, err, ErrSignatureInvalid)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt)
+
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+
+		err = m.Verify(t.Context(), key.Public())
+		assert.ErrorIs(t, err, ErrSignatureNotFound)
+	})
+
+	t.Run("Verify Policy", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+
+		m := newSignatureModel(t, gt, WithVerifyPolicy(key.Public()))
+		_, err := m.Fetch(t.Context())
+		assert.ErrorIs(t, err, ErrSignatureNotFound)
+		assert.False(t, m.fetched)
+
+		signer := newSignatureModel(t, gt)
+		_, err = signer.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = signer.Sign(t.Context(), key)
+		assert.NoError(t, err)
+
+		m = newSignatureModel(t, gt, WithVerifyPolicy(key.Public()))
+		_, err = m.Fetch(t.Context())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Sign On Push", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt, WithSigner(key))
+
+		_, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+		err = m.UpdateRef(t.Context(), plumbing.NewHashReference("refs/tags/new", plumbing.ZeroHash), m.man.Layers[0].Digest)
+		assert.NoError(t, err)
+
+		_, err = m.Push(t.Context())
+		assert.NoError(t, err)
+
+		verifier := newSignatureModel(t, gt, WithVerifyPolicy(key.Public()))
+		_, err = verifier.Fetch(t.Context())
+		assert.NoError(t, err)
+		assert.Contains(t, verifier.TagRefs(), plumbing.ReferenceName("refs/tags/new"))
+	})
+
+	t.Run("LFS Referrer Alongside Signature", func(t *testing.T) {
+		gt := memory.New()
+		setupRemote(t, gt)
+		m := newSignatureModel(t, gt)
+
+		manDesc, err := m.Fetch(t.Context())
+		assert.NoError(t, err)
+		_, err = m.Sign(t.Context(), key)
+		assert.NoError(t, err)
+		lfsManDesc, err := m.PushLFSManifest(t.Context(), manDesc)
+		assert.NoError(t, err)
+
+		got, err := m.FetchLFS(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, lfsManDesc.Digest, got.Digest)
+	})
+}
+
+func Test_signPayload(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	payload := []byte(`{"subject":"sha256:0000000000000000000000000000000000000000000000000000000000000000"}`)
+
+	for name, signer := range map[string]crypto.Signer{"ECDSA": ecKey, "Ed25519": edKey, "RSA": rsaKey} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := signPayload(signer, payload)
+			assert.NoError(t, err)
+
+			err = verifyPayload(signer.Public(), payload, sig)
+			assert.NoError(t, err)
+
+			err = verifyPayload(signer.Public(), []byte("tampered"), sig)
+			assert.ErrorIs(t, err, ErrSignatureInvalid)
+		})
+	}
+}
+
+func TestLoadSigner(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	privRaw, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	privPath := filepath.Join(dir, "signing.key")
+	err = os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privRaw}), 0o600)
+	assert.NoError(t, err)
+
+	pubRaw, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.NoError(t, err)
+	pubPath := filepath.Join(dir, "signing.pub")
+	err = os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubRaw}), 0o600)
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		signer, err := LoadSigner(privPath)
+		assert.NoError(t, err)
+
+		pub, err := LoadPublicKey(pubPath)
+		assert.NoError(t, err)
+		assert.Equal(t, signer.Public(), pub)
+	})
+
+	t.Run("Not PEM", func(t *testing.T) {
+		path := filepath.Join(dir, "garbage")
+		err := os.WriteFile(path, []byte("garbage"), 0o600)
+		assert.NoError(t, err)
+
+		_, err = LoadSigner(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+const (
+	// snapshotHashLen is the length of the abbreviated commit in snapshot tags.
+	snapshotHashLen = 12
+	// maxTagLen is the maximum length of an OCI tag.
+	maxTagLen = 128
+	// snapshotIndexSuffix is appended to the remote tag to form the tag of
+	// the snapshot index.
+	snapshotIndexSuffix = oci.SnapshotIndexSuffix
+)
+
+// errManifestNotPushed indicates the Git manifest has not been fetched or pushed.
+var errManifestNotPushed = errors.New("git manifest not pushed")
+
+// invalidTagChars matches characters not allowed in OCI tags.
+var invalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+func (m *model) TagSnapshot(ctx context.Context, refName plumbing.ReferenceName) (string, error) {
+	if m.manDesc.Digest == "" {
+		return "", errManifestNotPushed
+	}
+
+	ref, _, err := m.ResolveRef(ctx, refName)
+	if err != nil {
+		return "", err
+	}
+
+	tag := snapshotTag(m.ref.Reference, ref)
+	slog.DebugContext(ctx, "tagging snapshot", slog.String("reference", refName.String()), slog.String("tag", tag))
+	snapshotRef := m.ref
+	snapshotRef.Reference = tag
+	if err := m.gt.Tag(ctx, m.manDesc, snapshotRef.String()); err != nil {
+		return "", fmt.Errorf("tagging snapshot %s: %w", tag, err)
+	}
+
+	if err := m.indexSnapshot(ctx, ref, tag); err != nil {
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// snapshotTag returns the tag of a snapshot of a reference, namespaced by the
+// remote tag, e.g. sync-refs-heads-main-<abbreviated commit>. Namespacing
+// keeps the snapshots of each tag of a repository from colliding.
+func snapshotTag(remoteTag string, ref *plumbing.Reference) string {
+	hash := ref.Hash().String()[:snapshotHashLen]
+	name := remoteTag + "-" + invalidTagChars.ReplaceAllString(ref.Name().String(), "-")
+	if len(name) > maxTagLen-len(hash)-1 {
+		name = name[:maxTagLen-len(hash)-1]
+	}
+	return name + "-" + hash
+}
+
+// indexSnapshot records a snapshot of a reference in the snapshot index,
+// replacing any previous snapshot with the same tag.
+func (m *model) indexSnapshot(ctx context.Context, ref *plumbing.Reference, tag string) error {
+	snapshot := m.manDesc
+	snapshot.Annotations = map[string]string{
+		ocispec.AnnotationRefName:    tag,
+		oci.AnnotationSnapshotRef:    ref.Name().String(),
+		oci.AnnotationSnapshotCommit: ref.Hash().String(),
+	}
+	if err := m.recordInIndex(ctx, m.snapshotIndexTag(), oci.ArtifactTypeGitSnapshots, snapshot); err != nil {
+		return fmt.Errorf("updating snapshot index: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotIndexTag returns the tag of the snapshot index, e.g. sync-snapshots.
+func (m *model) snapshotIndexTag() string {
+	idxRef := m.ref
+	idxRef.Reference += snapshotIndexSuffix
+	return idxRef.String()
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	orasmemory "oras.land/oras-go/v2/content/memory"
+
+	"github.com/act3-ai/gnoci/pkg/oci"
+)
+
+func Test_snapshotTag(t *testing.T) {
+	const commit = "eaba08b8fae96b96fe68d88dd311ffb8ca22ba74"
+
+	tests := []struct {
+		name    string
+		refName plumbing.ReferenceName
+		want    string
+	}{
+		{name: "Branch", refName: plumbing.Main, want: "sync-refs-heads-main-eaba08b8fae9"},
+		{name: "Invalid Characters", refName: "refs/heads/feature/foo@bar+1", want: "sync-refs-heads-feature-foo-bar-1-eaba08b8fae9"},
+		{name: "Truncated", refName: plumbing.NewBranchReferenceName(strings.Repeat("a", 200)), want: "sync-refs-heads-" + strings.Repeat("a", 99) + "-eaba08b8fae9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snapshotTag("sync", plumbing.NewHashReference(tt.refName, plumbing.NewHash(commit)))
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, len(got), maxTagLen)
+		})
+	}
+}
+
+func Test_model_TagSnapshot(t *testing.T) {
+	var (
+		commitAlpha = plumbing.NewHash("eaba08b8fae96b96fe68d88dd311ffb8ca22ba74").String()
+		commitBeta  = plumbing.NewHash("9f9daae4bb300543116a1508cd9ed87bafd9d5fc").String()
+	)
+
+	newModel := func(t *testing.T, gt oras.GraphTarget) *model {
+		t.Helper()
+
+		manDesc, err := oras.PushBytes(t.Context(), gt, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+		assert.NoError(t, err)
+
+		return &model{
+			ref:     testRemote,
+			gt:      gt,
+			fetched: true,
+			manDesc: manDesc,
+			cfg: oci.ConfigGit{
+				Heads: map[plumbing.ReferenceName]oci.ReferenceInfo{
+					plumbing.Main: {Commit: commitAlpha},
+				},
+			},
+		}
+	}
+
+	fetchIndex := func(t *testing.T, gt oras.GraphTarget) ocispec.Index {
+		t.Helper()
+
+		idxRef := testRemote
+		idxRef.Reference += snapshotIndexSuffix
+		idxDesc, err := gt.Resolve(t.Context(), idxRef.String())
+		assert.NoError(t, err)
+		idxRaw, err := content.FetchAll(t.Context(), gt, idxDesc)
+		assert.NoError(t, err)
+
+		var idx ocispec.Index
+		assert.NoError(t, json.Unmarshal(idxRaw, &idx))
+		return idx
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := newModel(t, gt)
+
+		tag, err := m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		assert.Equal(t, "tag-refs-heads-main-eaba08b8fae9", tag)
+
+		snapshotRef := testRemote
+		snapshotRef.Reference = tag
+		desc, err := gt.Resolve(t.Context(), snapshotRef.String())
+		assert.NoError(t, err)
+		assert.Equal(t, m.manDesc.Digest, desc.Digest)
+
+		idx := fetchIndex(t, gt)
+		assert.Equal(t, oci.ArtifactTypeGitSnapshots, idx.ArtifactType)
+		assert.Len(t, idx.Manifests, 1)
+		assert.Equal(t, m.manDesc.Digest, idx.Manifests[0].Digest)
+		assert.Equal(t, map[string]string{
+			ocispec.AnnotationRefName:    tag,
+			oci.AnnotationSnapshotRef:    plumbing.Main.String(),
+			oci.AnnotationSnapshotCommit: commitAlpha,
+		}, idx.Manifests[0].Annotations)
+	})
+
+	t.Run("Index Accumulates Snapshots", func(t *testing.T) {
+		gt := orasmemory.New()
+		m := newModel(t, gt)
+
+		_, err := m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		// snapshotting the same commit again replaces its entry
+		_, err = m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+
+		m.cfg.Heads[plumbing.Main] = oci.ReferenceInfo{Commit: commitBeta}
+		tag, err := m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.NoError(t, err)
+		assert.Equal(t, "tag-refs-heads-main-9f9daae4bb30", tag)
+
+		idx := fetchIndex(t, gt)
+		assert.Len(t, idx.Manifests, 2)
+		assert.Equal(t, commitBeta, idx.Manifests[1].Annotations[oci.AnnotationSnapshotCommit])
+	})
+
+	t.Run("Reference Not Found", func(t *testing.T) {
+		m := newModel(t, orasmemory.New())
+
+		_, err := m.TagSnapshot(t.Context(), plumbing.NewBranchReferenceName("feature"))
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+	})
+
+	t.Run("Manifest Not Pushed", func(t *testing.T) {
+		m := newModel(t, orasmemory.New())
+		m.manDesc = ocispec.Descriptor{}
+
+		_, err := m.TagSnapshot(t.Context(), plumbing.Main)
+		assert.ErrorIs(t, err, errManifestNotPushed)
+	})
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Store is the storage backend of the Git OCI data model, such as a remote
+// registry repository or a local OCI image layout.
+//
+// Manifests and blobs are fetched and pushed by descriptor, tagged and
+// resolved by reference, and referrers are discovered as predecessors of
+// their subject. Stores supporting deletion additionally implement
+// [content.Deleter], which is used to clean up superseded content.
+type Store interface {
+	// Fetch, Push, and Exists manifests and blobs.
+	content.Storage
+	// Tag and Resolve references.
+	content.TagResolver
+	// Predecessors discovers referrers.
+	content.PredecessorFinder
+}
+
+// deleter returns the store as a [content.Deleter], if deletion is supported.
+// Stores writing to a primary target, e.g. registries with mirrors, support
+// deletion if their primary does.
+func deleter(s Store) (content.Deleter, bool) {
+	if p, ok := s.(interface{ Primary() oras.GraphTarget }); ok {
+		if _, ok := p.Primary().(content.Deleter); !ok {
+			return nil, false
+		}
+	}
+	d, ok := s.(content.Deleter)
+	return d, ok
+}
+
+// tagLister returns the store as a [registry.TagLister], if listing tags is
+// supported. Stores writing to a primary target, e.g. registries with
+// mirrors, list the tags of their primary.
+func tagLister(s Store) (registry.TagLister, bool) {
+	if p, ok := s.(interface{ Primary() oras.GraphTarget }); ok {
+		l, ok := p.Primary().(registry.TagLister)
+		return l, ok
+	}
+	l, ok := s.(registry.TagLister)
+	return l, ok
+}
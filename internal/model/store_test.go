@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+// primaryStore writes to a primary target, as a registry with mirrors.
+type primaryStore struct {
+	oras.GraphTarget
+	primary oras.GraphTarget
+}
+
+func (s *primaryStore) Primary() oras.GraphTarget {
+	return s.primary
+}
+
+func (s *primaryStore) Delete(context.Context, ocispec.Descriptor) error {
+	return nil
+}
+
+func Test_deleter(t *testing.T) {
+	layout, err := oci.New(t.TempDir())
+	assert.NoError(t, err)
+
+	_, ok := deleter(layout)
+	assert.True(t, ok)
+
+	_, ok = deleter(memory.New())
+	assert.False(t, ok)
+
+	_, ok = deleter(&primaryStore{GraphTarget: layout, primary: layout})
+	assert.True(t, ok)
+
+	// deletion by the primary is unsupported
+	_, ok = deleter(&primaryStore{GraphTarget: layout, primary: memory.New()})
+	assert.False(t, ok)
+}
+
+func Test_tagLister(t *testing.T) {
+	layout, err := oci.New(t.TempDir())
+	assert.NoError(t, err)
+
+	_, ok := tagLister(layout)
+	assert.True(t, ok)
+
+	_, ok = tagLister(memory.New())
+	assert.False(t, ok)
+
+	_, ok = tagLister(&primaryStore{GraphTarget: memory.New(), primary: layout})
+	assert.True(t, ok)
+
+	// the tags of the primary are listed
+	_, ok = tagLister(&primaryStore{GraphTarget: layout, primary: memory.New()})
+	assert.False(t, ok)
+}
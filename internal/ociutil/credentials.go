@@ -0,0 +1,147 @@
+package ociutil
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/adrg/xdg"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// NewCredentialStore creates a credential store for authenticating with
+// registries. Credentials are read from the standard $DOCKER_CONFIG/config.json,
+// falling back to the auth files used by podman and other containers tools.
+// Registries with a credential helper, a map of registry to helper name, always
+// invoke the docker-credential-<name> executable instead.
+func NewCredentialStore(ctx context.Context, helpers map[string]string) credentials.Store {
+	storeOpts := credentials.StoreOptions{}
+	var store credentials.Store
+	store, err := credentials.NewStoreFromDocker(storeOpts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get default docker credential store", slog.String("error", err.Error()))
+		store = credentials.NewMemoryStore()
+	}
+
+	var fallbacks []credentials.Store
+	for _, path := range authFiles() {
+		fallback, err := credentials.NewStore(path, storeOpts)
+		if err != nil {
+			slog.WarnContext(ctx, "ignoring invalid auth file", slog.String("path", path), slog.String("error", err.Error()))
+			continue
+		}
+		slog.DebugContext(ctx, "using fallback auth file", slog.String("path", path))
+		fallbacks = append(fallbacks, fallback)
+	}
+	if len(fallbacks) > 0 {
+		store = credentials.NewStoreWithFallbacks(store, fallbacks...)
+	}
+
+	if len(helpers) == 0 {
+		return store
+	}
+
+	hs := &helperStore{
+		Store:   store,
+		helpers: make(map[string]credentials.Store, len(helpers)),
+	}
+	for reg, helper := range helpers {
+		hs.helpers[credentials.ServerAddressFromRegistry(reg)] = credentials.NewNativeStore(helper)
+	}
+	return hs
+}
+
+// authFiles returns the existing auth files of podman, skopeo, and buildah in
+// order of precedence.
+func authFiles() []string {
+	var paths []string
+	if path := os.Getenv("REGISTRY_AUTH_FILE"); path != "" {
+		paths = append(paths, path)
+	}
+	if xdg.RuntimeDir != "" {
+		paths = append(paths, filepath.Join(xdg.RuntimeDir, "containers", "auth.json"))
+	}
+	paths = append(paths, filepath.Join(xdg.ConfigHome, "containers", "auth.json"))
+
+	return slices.DeleteFunc(slices.Compact(paths), func(path string) bool {
+		fi, err := os.Stat(path)
+		return err != nil || fi.IsDir()
+	})
+}
+
+// helperStore is a [credentials.Store] invoking a credential helper for
+// specific registries.
+type helperStore struct {
+	credentials.Store
+	helpers map[string]credentials.Store // server address to helper store
+}
+
+// Get retrieves credentials for serverAddress.
+func (s *helperStore) Get(ctx context.Context, serverAddress string) (auth.Credential, error) {
+	cred, err := s.store(serverAddress).Get(ctx, serverAddress)
+	if err != nil {
+		return auth.EmptyCredential, fmt.Errorf("getting credentials for %s: %w", serverAddress, err)
+	}
+	return cred, nil
+}
+
+// Put saves credentials for serverAddress.
+func (s *helperStore) Put(ctx context.Context, serverAddress string, cred auth.Credential) error {
+	if err := s.store(serverAddress).Put(ctx, serverAddress, cred); err != nil {
+		return fmt.Errorf("saving credentials for %s: %w", serverAddress, err)
+	}
+	return nil
+}
+
+// Delete removes credentials for serverAddress.
+func (s *helperStore) Delete(ctx context.Context, serverAddress string) error {
+	if err := s.store(serverAddress).Delete(ctx, serverAddress); err != nil {
+		return fmt.Errorf("deleting credentials for %s: %w", serverAddress, err)
+	}
+	return nil
+}
+
+// store returns the credential store responsible for serverAddress.
+func (s *helperStore) store(serverAddress string) credentials.Store {
+	if helper, ok := s.helpers[serverAddress]; ok {
+		return helper
+	}
+	return s.Store
+}
+
+// providerStore is a [credentials.Store] obtaining the credentials of specific
+// registries from an [AuthProvider].
+type providerStore struct {
+	credentials.Store
+	providers map[string]AuthProvider // server address to provider
+}
+
+// newProviderStore wraps store, obtaining the credentials of registries with a
+// provider, a map of registry to provider, from the provider instead.
+func newProviderStore(store credentials.Store, providers map[string]AuthProvider) *providerStore {
+	ps := &providerStore{
+		Store:     store,
+		providers: make(map[string]AuthProvider, len(providers)),
+	}
+	for reg, provider := range providers {
+		ps.providers[credentials.ServerAddressFromRegistry(reg)] = provider
+	}
+	return ps
+}
+
+// Get retrieves credentials for serverAddress.
+func (s *providerStore) Get(ctx context.Context, serverAddress string) (auth.Credential, error) {
+	provider, ok := s.providers[serverAddress]
+	if !ok {
+		return s.Store.Get(ctx, serverAddress) //nolint:wrapcheck
+	}
+	cred, err := provider.Credential(ctx)
+	if err != nil {
+		return auth.EmptyCredential, fmt.Errorf("getting credentials for %s: %w", serverAddress, err)
+	}
+	return cred, nil
+}
@@ -0,0 +1,108 @@
+package ociutil
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// isolateCredentials points all credential sources at an empty temporary
+// directory.
+func isolateCredentials(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("REGISTRY_AUTH_FILE", "")
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(dir, "run"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	return dir
+}
+
+// writeAuthFile writes a Docker style auth file with a single credential.
+func writeAuthFile(t *testing.T, path, registry, username, password string) {
+	t.Helper()
+	encoded := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(`{"auths":{"`+registry+`":{"auth":"`+encoded+`"}}}`), 0o600))
+}
+
+func TestNewCredentialStore(t *testing.T) {
+	t.Run("Docker Config", func(t *testing.T) {
+		dir := isolateCredentials(t)
+		writeAuthFile(t, filepath.Join(dir, "config.json"), "example.com", "docker", "secret")
+
+		store := NewCredentialStore(t.Context(), nil)
+		cred, err := store.Get(t.Context(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "docker", Password: "secret"}, cred)
+	})
+
+	t.Run("Podman Auth File", func(t *testing.T) {
+		dir := isolateCredentials(t)
+		writeAuthFile(t, filepath.Join(dir, "run", "containers", "auth.json"), "example.com", "podman", "secret")
+
+		store := NewCredentialStore(t.Context(), nil)
+		cred, err := store.Get(t.Context(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "podman", Password: "secret"}, cred)
+	})
+
+	t.Run("Registry Auth File", func(t *testing.T) {
+		dir := isolateCredentials(t)
+		path := filepath.Join(dir, "auth.json")
+		writeAuthFile(t, path, "example.com", "registry", "secret")
+		t.Setenv("REGISTRY_AUTH_FILE", path)
+
+		store := NewCredentialStore(t.Context(), nil)
+		cred, err := store.Get(t.Context(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "registry", Password: "secret"}, cred)
+	})
+
+	t.Run("Docker Config Precedence", func(t *testing.T) {
+		dir := isolateCredentials(t)
+		writeAuthFile(t, filepath.Join(dir, "config.json"), "example.com", "docker", "secret")
+		writeAuthFile(t, filepath.Join(dir, "config", "containers", "auth.json"), "example.com", "podman", "secret")
+
+		store := NewCredentialStore(t.Context(), nil)
+		cred, err := store.Get(t.Context(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "docker", cred.Username)
+	})
+
+	t.Run("Credential Helper", func(t *testing.T) {
+		dir := isolateCredentials(t)
+		writeAuthFile(t, filepath.Join(dir, "config.json"), "other.example.com", "docker", "secret")
+
+		bin := filepath.Join(dir, "bin")
+		assert.NoError(t, os.MkdirAll(bin, 0o755))
+		helper := "#!/bin/sh\ncat > /dev/null\necho '{\"ServerURL\":\"example.com\",\"Username\":\"helper\",\"Secret\":\"secret\"}'\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(bin, "docker-credential-test"), []byte(helper), 0o755)) //nolint:gosec
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		store := NewCredentialStore(t.Context(), map[string]string{"example.com": "test"})
+		cred, err := store.Get(t.Context(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "helper", Password: "secret"}, cred)
+
+		cred, err = store.Get(t.Context(), "other.example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "docker", cred.Username)
+	})
+
+	t.Run("No Credentials", func(t *testing.T) {
+		isolateCredentials(t)
+
+		store := NewCredentialStore(t.Context(), nil)
+		cred, err := store.Get(t.Context(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, auth.EmptyCredential, cred)
+	})
+}
@@ -0,0 +1,227 @@
+package ociutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// Scheme is the URL scheme of remote registry repositories.
+	Scheme = "oci://"
+	// HTTPScheme is the URL scheme of remote registry repositories served
+	// over plain HTTP, e.g. local registries.
+	HTTPScheme = "oci+http://"
+	// LayoutScheme is the URL scheme of local OCI image layout directories.
+	LayoutScheme = "oci+layout://"
+	// TarScheme is the URL scheme of local OCI image layout tarballs.
+	TarScheme = "oci+tar://"
+
+	// layoutRegistry and layoutRepository are placeholders used to reference
+	// content within an OCI image layout, which has neither.
+	layoutRegistry   = "layout.local"
+	layoutRepository = "git"
+
+	// DefaultTag is the tag of addresses with neither a tag nor a digest.
+	DefaultTag = "latest"
+)
+
+// ErrInvalidAddress indicates a remote address cannot be parsed.
+var ErrInvalidAddress = errors.New("invalid OCI address")
+
+// Address is a parsed remote address.
+type Address struct {
+	// Ref is the reference of the remote. OCI image layouts use a placeholder
+	// registry and repository.
+	Ref registry.Reference
+	// Layout is the path of an OCI image layout directory or tarball, empty
+	// if the remote is a registry repository.
+	Layout string
+	// Tar indicates Layout is an OCI image layout tarball.
+	Tar bool
+	// PlainHTTP indicates the registry is connected to over plain HTTP.
+	PlainHTTP bool
+}
+
+// String returns the URL of the address.
+func (a Address) String() string {
+	switch {
+	case a.Layout == "" && a.PlainHTTP:
+		return HTTPScheme + a.Ref.String()
+	case a.Layout == "":
+		return Scheme + a.Ref.String()
+	case a.Tar:
+		return TarScheme + a.Layout + a.layoutReference()
+	default:
+		return LayoutScheme + a.Layout + a.layoutReference()
+	}
+}
+
+// layoutReference returns the tag or digest suffix of an OCI image layout
+// address.
+func (a Address) layoutReference() string {
+	if a.Ref.ValidateReferenceAsDigest() == nil {
+		return "@" + a.Ref.Reference
+	}
+	return ":" + a.Ref.Reference
+}
+
+// HasScheme returns true if address begins with the URL scheme of a
+// registry repository or OCI image layout.
+func HasScheme(address string) bool {
+	return strings.HasPrefix(address, Scheme) || strings.HasPrefix(address, HTTPScheme) ||
+		strings.HasPrefix(address, LayoutScheme) || strings.HasPrefix(address, TarScheme)
+}
+
+// ParseAddress parses an "oci://" registry reference, an "oci+http://"
+// registry reference served over plain HTTP, an "oci+layout://" OCI image
+// layout directory address of the form "oci+layout://<path>[:<tag>|@<digest>]",
+// or an "oci+tar://" OCI image layout tarball address of the form
+// "oci+tar://<path>[:<tag>|@<digest>]". Addresses without a tag or digest
+// reference [DefaultTag].
+func ParseAddress(address string) (Address, error) {
+	return ParseAddressDefaultTag(address, DefaultTag)
+}
+
+// ParseAddressDefaultTag extends [ParseAddress], referencing defaultTag if
+// address has neither a tag nor a digest. An empty defaultTag is
+// [DefaultTag].
+func ParseAddressDefaultTag(address, defaultTag string) (Address, error) {
+	if defaultTag == "" {
+		defaultTag = DefaultTag
+	}
+	if err := (registry.Reference{Reference: defaultTag}).ValidateReferenceAsTag(); err != nil {
+		return Address{}, fmt.Errorf("%w: default tag: %w", ErrInvalidAddress, err)
+	}
+
+	if path, ok := strings.CutPrefix(address, LayoutScheme); ok {
+		return parseLayoutAddress(address, path, defaultTag, false)
+	}
+	if path, ok := strings.CutPrefix(address, TarScheme); ok {
+		return parseLayoutAddress(address, path, defaultTag, true)
+	}
+
+	reference, plainHTTP := strings.CutPrefix(address, HTTPScheme)
+	reference = strings.TrimPrefix(reference, Scheme)
+	if scheme, _, ok := strings.Cut(reference, "://"); ok {
+		return Address{}, fmt.Errorf("%w %s: unsupported scheme %q, expected one of %s, %s, %s, or %s",
+			ErrInvalidAddress, address, scheme, Scheme, HTTPScheme, LayoutScheme, TarScheme)
+	}
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w %s: expected <registry>/<repository>[:<tag>|@<digest>]%s: %w",
+			ErrInvalidAddress, address, referenceHint(reference), err)
+	}
+	if ref.Reference == "" {
+		ref.Reference = defaultTag
+	}
+	return Address{Ref: ref, PlainHTTP: plainHTTP}, nil
+}
+
+// referenceHint returns a hint of a common mistake in an invalid reference,
+// prefixed with a comma, if any.
+func referenceHint(reference string) string {
+	_, repository, ok := strings.Cut(reference, "/")
+	if !ok {
+		return ", missing repository"
+	}
+	repository, _, _ = strings.Cut(repository, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	if strings.ToLower(repository) != repository {
+		return ", repository must be lowercase"
+	}
+	return ""
+}
+
+// parseLayoutAddress parses the path and optional tag or digest of an OCI
+// image layout address.
+func parseLayoutAddress(address, path, defaultTag string, tar bool) (Address, error) {
+	ref := registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: defaultTag}
+	// a tag or digest may only follow the final path element, of either slash
+	// or backslash separated paths, e.g. a Windows path with a drive letter
+	sep := strings.LastIndexAny(path, `/\`)
+	if i := strings.LastIndex(path, "@"); i > sep && hasDigestAlgorithm(path[i+1:]) {
+		path, ref.Reference = path[:i], path[i+1:]
+		if err := ref.ValidateReferenceAsDigest(); err != nil {
+			return Address{}, fmt.Errorf("%w %s: OCI image layout digest: %w", ErrInvalidAddress, address, err)
+		}
+	} else if i := strings.LastIndex(path, ":"); i > sep {
+		path, ref.Reference = path[:i], path[i+1:]
+		if err := ref.ValidateReferenceAsTag(); err != nil {
+			return Address{}, fmt.Errorf("%w %s: OCI image layout tag: %w", ErrInvalidAddress, address, err)
+		}
+	}
+	if path == "" {
+		return Address{}, fmt.Errorf("%w %s: OCI image layout address has no path", ErrInvalidAddress, address)
+	}
+
+	return Address{Ref: ref, Layout: filepath.Clean(path), Tar: tar}, nil
+}
+
+// hasDigestAlgorithm returns true if s is prefixed by an available digest algorithm,
+// e.g. "sha256:", rather than a tag of a path containing "@".
+func hasDigestAlgorithm(s string) bool {
+	alg, _, ok := strings.Cut(s, ":")
+	return ok && digest.Algorithm(alg).Available()
+}
+
+// OpenLayout creates an oras.GraphTarget backed by the OCI image layout
+// directory or tarball of addr. Tarball targets must be closed, see
+// [TarTarget.Close].
+func OpenLayout(addr Address) (oras.GraphTarget, error) {
+	if addr.Tar {
+		return NewTarTarget(addr.Layout)
+	}
+	return NewLayoutTarget(addr.Layout)
+}
+
+// NewLayoutTarget creates an oras.GraphTarget backed by the OCI image layout
+// at dir, creating the layout if it does not exist. Full references, as
+// used with registry repositories, are tagged and resolved by their tag or
+// digest.
+func NewLayoutTarget(dir string) (oras.GraphTarget, error) {
+	return newLayoutTarget(dir)
+}
+
+func newLayoutTarget(dir string) (*layoutTarget, error) {
+	store, err := oci.New(dir)
+	if err != nil {
+		return nil, fmt.Errorf("initializing OCI image layout %s: %w", dir, err)
+	}
+	return &layoutTarget{Store: store}, nil
+}
+
+// layoutTarget is an OCI image layout store accepting full references.
+type layoutTarget struct {
+	*oci.Store
+}
+
+// Tag tags the described content with the tag or digest of reference.
+func (lt *layoutTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	return lt.Store.Tag(ctx, desc, layoutReference(reference)) //nolint:wrapcheck
+}
+
+// Resolve resolves the tag or digest of reference.
+func (lt *layoutTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	return lt.Store.Resolve(ctx, layoutReference(reference)) //nolint:wrapcheck
+}
+
+// layoutReference returns the tag or digest of a full reference, or
+// reference itself if it is not a full reference.
+func layoutReference(reference string) string {
+	ref, err := registry.ParseReference(reference)
+	if err != nil || ref.Reference == "" {
+		return reference
+	}
+	return ref.Reference
+}
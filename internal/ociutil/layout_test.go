@@ -0,0 +1,185 @@
+package ociutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry"
+)
+
+// testDigest is a valid digest referenced by addresses.
+const testDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		address   string
+		want      Address
+		wantError bool
+	}{
+		{
+			name:    "Registry",
+			address: "oci://reg.example.com/repo:tag",
+			want:    Address{Ref: registry.Reference{Registry: "reg.example.com", Repository: "repo", Reference: "tag"}},
+		},
+		{
+			name:    "Registry Without Scheme",
+			address: "reg.example.com/repo:tag",
+			want:    Address{Ref: registry.Reference{Registry: "reg.example.com", Repository: "repo", Reference: "tag"}},
+		},
+		{
+			name:    "Plain HTTP Registry",
+			address: "oci+http://localhost:5000/repo:tag",
+			want:    Address{Ref: registry.Reference{Registry: "localhost:5000", Repository: "repo", Reference: "tag"}, PlainHTTP: true},
+		},
+		{
+			name:    "Registry Default Tag",
+			address: "oci://reg.example.com/repo",
+			want:    Address{Ref: registry.Reference{Registry: "reg.example.com", Repository: "repo", Reference: DefaultTag}},
+		},
+		{
+			name:    "Registry Digest",
+			address: "oci://reg.example.com/repo@" + testDigest,
+			want:    Address{Ref: registry.Reference{Registry: "reg.example.com", Repository: "repo", Reference: testDigest}},
+		},
+		{
+			name:    "Layout",
+			address: "oci+layout:///tmp/repo:sync",
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: "sync"}, Layout: filepath.FromSlash("/tmp/repo")},
+		},
+		{
+			name:    "Layout Default Tag",
+			address: "oci+layout://relative/repo",
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: DefaultTag}, Layout: filepath.FromSlash("relative/repo")},
+		},
+		{
+			name:    "Layout Colon In Directory",
+			address: "oci+layout:///tmp/a:b/repo",
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: DefaultTag}, Layout: filepath.FromSlash("/tmp/a:b/repo")},
+		},
+		{
+			name:    "Layout Windows Path",
+			address: `oci+layout://C:\repos\repo:sync`,
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: "sync"}, Layout: filepath.Clean(`C:\repos\repo`)},
+		},
+		{
+			name:    "Layout Windows Path Default Tag",
+			address: `oci+layout://C:\repos\repo`,
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: DefaultTag}, Layout: filepath.Clean(`C:\repos\repo`)},
+		},
+		{
+			name:    "Tar",
+			address: "oci+tar:///tmp/repo.tar:sync",
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: "sync"}, Layout: filepath.FromSlash("/tmp/repo.tar"), Tar: true},
+		},
+		{
+			name:    "Layout Digest",
+			address: "oci+layout:///tmp/repo@" + testDigest,
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: testDigest}, Layout: filepath.FromSlash("/tmp/repo")},
+		},
+		{
+			name:    "Layout At In Directory",
+			address: "oci+layout:///tmp/repo@v1:sync",
+			want:    Address{Ref: registry.Reference{Registry: layoutRegistry, Repository: layoutRepository, Reference: "sync"}, Layout: filepath.FromSlash("/tmp/repo@v1")},
+		},
+		{name: "Layout Without Path", address: "oci+layout://", wantError: true},
+		{name: "Tar Without Path", address: "oci+tar://:sync", wantError: true},
+		{name: "Layout Invalid Tag", address: "oci+layout:///tmp/repo:in valid", wantError: true},
+		{name: "Layout Invalid Digest", address: "oci+layout:///tmp/repo@sha256:abc", wantError: true},
+		{name: "Invalid Reference", address: "oci://in valid", wantError: true},
+		{name: "Invalid Digest", address: "oci://reg.example.com/repo@sha256:abc", wantError: true},
+		{name: "Missing Repository", address: "oci://reg.example.com", wantError: true},
+		{name: "Unsupported Scheme", address: "https://reg.example.com/repo", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAddress(tt.address)
+			if tt.wantError {
+				assert.ErrorIs(t, err, ErrInvalidAddress)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			reparsed, err := ParseAddress(got.String())
+			assert.NoError(t, err)
+			assert.Equal(t, got, reparsed)
+		})
+	}
+}
+
+func TestParseAddressDefaultTag(t *testing.T) {
+	t.Run("Registry", func(t *testing.T) {
+		got, err := ParseAddressDefaultTag("oci://reg.example.com/repo", "main")
+		assert.NoError(t, err)
+		assert.Equal(t, "main", got.Ref.Reference)
+	})
+
+	t.Run("Layout", func(t *testing.T) {
+		got, err := ParseAddressDefaultTag("oci+layout:///tmp/repo", "main")
+		assert.NoError(t, err)
+		assert.Equal(t, "main", got.Ref.Reference)
+	})
+
+	t.Run("Explicit Tag", func(t *testing.T) {
+		got, err := ParseAddressDefaultTag("oci://reg.example.com/repo:tag", "main")
+		assert.NoError(t, err)
+		assert.Equal(t, "tag", got.Ref.Reference)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		got, err := ParseAddressDefaultTag("oci://reg.example.com/repo", "")
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultTag, got.Ref.Reference)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ParseAddressDefaultTag("oci://reg.example.com/repo", "in valid")
+		assert.ErrorIs(t, err, ErrInvalidAddress)
+	})
+}
+
+func Test_referenceHint(t *testing.T) {
+	assert.Equal(t, ", missing repository", referenceHint("reg.example.com"))
+	assert.Equal(t, ", repository must be lowercase", referenceHint("reg.example.com/Repo:tag"))
+	assert.Empty(t, referenceHint("reg.example.com/repo:in valid"))
+}
+
+func TestNewLayoutTarget(t *testing.T) {
+	ctx := t.Context()
+	dir := filepath.Join(t.TempDir(), "layout")
+
+	gt, err := NewLayoutTarget(dir)
+	assert.NoError(t, err)
+
+	desc, err := oras.PushBytes(ctx, gt, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+	assert.NoError(t, err)
+
+	addr, err := ParseAddress("oci+layout://" + dir + ":sync")
+	assert.NoError(t, err)
+	ref := addr.Ref
+	assert.NoError(t, gt.Tag(ctx, desc, ref.String()))
+
+	t.Run("Resolve Full Reference", func(t *testing.T) {
+		got, err := gt.Resolve(ctx, ref.String())
+		assert.NoError(t, err)
+		assert.Equal(t, desc.Digest, got.Digest)
+	})
+
+	t.Run("Resolve Tag", func(t *testing.T) {
+		got, err := gt.Resolve(ctx, "sync")
+		assert.NoError(t, err)
+		assert.Equal(t, desc.Digest, got.Digest)
+	})
+
+	t.Run("Persisted", func(t *testing.T) {
+		reopened, err := NewLayoutTarget(dir)
+		assert.NoError(t, err)
+		got, err := reopened.Resolve(ctx, ref.String())
+		assert.NoError(t, err)
+		assert.Equal(t, desc.Digest, got.Digest)
+	})
+}
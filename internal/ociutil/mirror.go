@@ -0,0 +1,208 @@
+package ociutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Mirror is a registry mirroring the repositories of another, e.g. a
+// pull-through cache.
+type Mirror struct {
+	// Registry is the host of the mirror, e.g. "mirror.example.com:5000".
+	Registry string
+	// PlainHTTP enables basic HTTP
+	PlainHTTP bool
+	// NonCompliant indicates the mirror is not OCI compliant.
+	NonCompliant bool
+	// ReferrersTagSchema forces the referrers tag schema rather than the
+	// Referrers API.
+	ReferrersTagSchema bool
+	// Proxy configures the proxy of requests to the mirror.
+	Proxy Proxy
+	// Socket is the path of a Unix domain socket connections to the mirror
+	// are dialed through.
+	Socket string
+	// TLS configures the TLS connections to the mirror.
+	TLS TLS
+}
+
+// mirrorTarget is a named read-only target of a mirror.
+type mirrorTarget struct {
+	registry string
+	target   oras.ReadOnlyGraphTarget
+}
+
+// MirroredTarget is an oras.GraphTarget that reads from mirrors, in order,
+// before falling back to the primary. All writes go to the primary, which
+// also resolves tags unless it is unreachable.
+type MirroredTarget struct {
+	primary oras.GraphTarget
+	mirrors []mirrorTarget
+}
+
+// newMirroredTarget creates a MirroredTarget for ref, with the repository
+// and reference of ref in each mirror.
+func newMirroredTarget(ctx context.Context, primary oras.GraphTarget, ref registry.Reference, opts *RepositoryOptions) (*MirroredTarget, error) {
+	mt := &MirroredTarget{
+		primary: primary,
+		mirrors: make([]mirrorTarget, 0, len(opts.Mirrors)),
+	}
+
+	for _, mirror := range opts.Mirrors {
+		mirrorRef := ref
+		mirrorRef.Registry = mirror.Registry
+		if err := mirrorRef.ValidateRegistry(); err != nil {
+			return nil, fmt.Errorf("invalid mirror %s: %w", mirror.Registry, err)
+		}
+
+		mirrorOpts := &RepositoryOptions{
+			UserAgent:          opts.UserAgent,
+			PlainHTTP:          mirror.PlainHTTP,
+			NonCompliant:       mirror.NonCompliant,
+			ReferrersTagSchema: mirror.ReferrersTagSchema,
+			RegistryCreds:      opts.RegistryCreds,
+			Retry:              opts.Retry,
+			Proxy:              mirror.Proxy,
+			Socket:             mirror.Socket,
+			TLS:                mirror.TLS,
+		}
+		gt, err := create(ctx, mirrorRef, mirrorOpts)
+		if err != nil {
+			return nil, fmt.Errorf("creating mirror %s: %w", mirror.Registry, err)
+		}
+
+		mt.mirrors = append(mt.mirrors, mirrorTarget{registry: mirror.Registry, target: gt})
+	}
+
+	return mt, nil
+}
+
+// Primary returns the target written to.
+func (mt *MirroredTarget) Primary() oras.GraphTarget {
+	return mt.primary
+}
+
+// Fetch fetches the content identified by the descriptor from the first
+// mirror containing it, otherwise the primary.
+func (mt *MirroredTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	for _, mirror := range mt.mirrors {
+		rc, err := mirror.target.Fetch(ctx, target)
+		if err == nil {
+			return rc, nil
+		}
+		logMirrorMiss(ctx, mirror.registry, target.Digest.String(), err)
+	}
+
+	return mt.primary.Fetch(ctx, target) //nolint:wrapcheck
+}
+
+// Exists returns true if the described content exists in the primary. Mirrors
+// are not consulted, as existence determines what must be written.
+func (mt *MirroredTarget) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	return mt.primary.Exists(ctx, target) //nolint:wrapcheck
+}
+
+// Push pushes the content to the primary.
+func (mt *MirroredTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	return mt.primary.Push(ctx, expected, r) //nolint:wrapcheck
+}
+
+// Resolve resolves a digest reference using the first mirror able to,
+// otherwise the primary. Tags are resolved by the primary, as mirrors may serve
+// stale tags, and resolved tags are the base of updates, falling back to the
+// mirrors only if the primary is unreachable.
+func (mt *MirroredTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	// mirrors reject fully qualified references to the primary
+	mirrorReference := reference
+	if ref, err := registry.ParseReference(reference); err == nil {
+		mirrorReference = ref.Reference
+	}
+
+	if (registry.Reference{Reference: mirrorReference}).ValidateReferenceAsDigest() == nil {
+		for _, mirror := range mt.mirrors {
+			desc, err := mirror.target.Resolve(ctx, mirrorReference)
+			if err == nil {
+				return desc, nil
+			}
+			logMirrorMiss(ctx, mirror.registry, reference, err)
+		}
+		return mt.primary.Resolve(ctx, reference) //nolint:wrapcheck
+	}
+
+	desc, err := mt.primary.Resolve(ctx, reference)
+	if err == nil || !isUnreachable(ctx, err) {
+		return desc, err //nolint:wrapcheck
+	}
+
+	for _, mirror := range mt.mirrors {
+		desc, mirrorErr := mirror.target.Resolve(ctx, mirrorReference)
+		if mirrorErr == nil {
+			slog.WarnContext(ctx, "registry unreachable, resolved tag from mirror, which may be stale",
+				slog.String("mirror", mirror.registry), slog.String("reference", reference), slog.String("error", err.Error()))
+			return desc, nil
+		}
+		logMirrorMiss(ctx, mirror.registry, reference, mirrorErr)
+	}
+
+	return ocispec.Descriptor{}, err //nolint:wrapcheck
+}
+
+// Tag tags the descriptor with the reference in the primary.
+func (mt *MirroredTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	return mt.primary.Tag(ctx, desc, reference) //nolint:wrapcheck
+}
+
+// Predecessors returns the nodes directly pointing to the current node from
+// the first mirror with any, otherwise the primary. Mirrors often do not
+// cache referrers, so an empty result is not conclusive and errors of the
+// primary are returned.
+func (mt *MirroredTarget) Predecessors(ctx context.Context, node ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	for _, mirror := range mt.mirrors {
+		preds, err := mirror.target.Predecessors(ctx, node)
+		if err != nil {
+			logMirrorMiss(ctx, mirror.registry, node.Digest.String(), err)
+			continue
+		}
+		if len(preds) > 0 {
+			return preds, nil
+		}
+	}
+
+	return mt.primary.Predecessors(ctx, node) //nolint:wrapcheck
+}
+
+// Delete removes the described content from the primary, returning
+// [errdef.ErrUnsupported] if the primary does not support deletion.
+func (mt *MirroredTarget) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	deleter, ok := mt.primary.(content.Deleter)
+	if !ok {
+		return fmt.Errorf("%w: primary target does not support deletion", errdef.ErrUnsupported)
+	}
+
+	return deleter.Delete(ctx, target) //nolint:wrapcheck
+}
+
+func logMirrorMiss(ctx context.Context, mirror, target string, err error) {
+	slog.DebugContext(ctx, "mirror unable to serve request, trying next",
+		slog.String("mirror", mirror), slog.String("target", target), slog.String("error", err.Error()))
+}
+
+// isUnreachable returns true if err indicates a registry could not be
+// reached, rather than responding with an error, e.g. not found.
+func isUnreachable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,187 @@
+package ociutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+func TestNewGraphTarget_Mirrors(t *testing.T) {
+	opts := &RepositoryOptions{
+		Mirrors: []Mirror{{Registry: "mirror.example.com", PlainHTTP: true}},
+	}
+	gt, err := NewGraphTarget(t.Context(), testRemote, opts)
+	assert.NoError(t, err)
+
+	mt, ok := gt.(*MirroredTarget)
+	assert.True(t, ok)
+	assert.Len(t, mt.mirrors, 1)
+
+	mirror, ok := mt.mirrors[0].target.(*remote.Repository)
+	assert.True(t, ok)
+	assert.Equal(t, "mirror.example.com", mirror.Reference.Registry)
+	assert.Equal(t, testRemote.Repository, mirror.Reference.Repository)
+	assert.True(t, mirror.PlainHTTP)
+
+	primary, ok := mt.Primary().(*remote.Repository)
+	assert.True(t, ok)
+	assert.Equal(t, testRemote.Registry, primary.Reference.Registry)
+
+	t.Run("Invalid Mirror", func(t *testing.T) {
+		opts := &RepositoryOptions{
+			Mirrors: []Mirror{{Registry: "in valid"}},
+		}
+		_, err := NewGraphTarget(t.Context(), testRemote, opts)
+		assert.Error(t, err)
+	})
+}
+
+func TestMirroredTarget(t *testing.T) {
+	blob := []byte("mirrored")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	primaryOnly := []byte("primary")
+	primaryDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, primaryOnly)
+
+	newTargets := func(t *testing.T) (*MirroredTarget, oras.GraphTarget, oras.GraphTarget) {
+		t.Helper()
+		primary := memory.New()
+		empty := memory.New()
+		mirror := memory.New()
+		assert.NoError(t, mirror.Push(t.Context(), desc, bytes.NewReader(blob)))
+		assert.NoError(t, mirror.Tag(t.Context(), desc, "tag"))
+		assert.NoError(t, primary.Push(t.Context(), primaryDesc, bytes.NewReader(primaryOnly)))
+
+		mt := &MirroredTarget{
+			primary: primary,
+			mirrors: []mirrorTarget{
+				{registry: "empty", target: empty},
+				{registry: "mirror", target: mirror},
+			},
+		}
+		return mt, primary, mirror
+	}
+
+	t.Run("Fetch From Mirror", func(t *testing.T) {
+		mt, _, _ := newTargets(t)
+		got, err := content.FetchAll(t.Context(), mt, desc)
+		assert.NoError(t, err)
+		assert.Equal(t, blob, got)
+	})
+
+	t.Run("Fetch Fallback To Primary", func(t *testing.T) {
+		mt, _, _ := newTargets(t)
+		got, err := content.FetchAll(t.Context(), mt, primaryDesc)
+		assert.NoError(t, err)
+		assert.Equal(t, primaryOnly, got)
+	})
+
+	t.Run("Resolve Digest From Mirror", func(t *testing.T) {
+		mt, _, mirror := newTargets(t)
+		// memory stores resolve tags only
+		assert.NoError(t, mirror.Tag(t.Context(), desc, desc.Digest.String()))
+		got, err := mt.Resolve(t.Context(), testRemote.Registry+"/"+testRemote.Repository+"@"+desc.Digest.String())
+		assert.NoError(t, err)
+		assert.Equal(t, desc.Digest, got.Digest)
+	})
+
+	t.Run("Resolve Tag From Primary", func(t *testing.T) {
+		mt, primary, _ := newTargets(t)
+		// the mirror lags behind the primary
+		assert.NoError(t, primary.Tag(t.Context(), primaryDesc, "tag"))
+		got, err := mt.Resolve(t.Context(), "tag")
+		assert.NoError(t, err)
+		assert.Equal(t, primaryDesc.Digest, got.Digest)
+	})
+
+	t.Run("Resolve Tag Not In Primary", func(t *testing.T) {
+		mt, _, _ := newTargets(t)
+		_, err := mt.Resolve(t.Context(), "tag")
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+
+	t.Run("Resolve Tag From Mirror If Primary Unreachable", func(t *testing.T) {
+		mt, primary, _ := newTargets(t)
+		mt.primary = &unreachableTarget{GraphTarget: primary}
+		got, err := mt.Resolve(t.Context(), "tag")
+		assert.NoError(t, err)
+		assert.Equal(t, desc.Digest, got.Digest)
+	})
+
+	t.Run("Resolve Tag Unreachable Not In Mirrors", func(t *testing.T) {
+		mt, primary, _ := newTargets(t)
+		mt.primary = &unreachableTarget{GraphTarget: primary}
+		_, err := mt.Resolve(t.Context(), "dne")
+		var opErr *net.OpError
+		assert.ErrorAs(t, err, &opErr)
+	})
+
+	t.Run("Resolve Not Found", func(t *testing.T) {
+		mt, _, _ := newTargets(t)
+		_, err := mt.Resolve(t.Context(), "dne")
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+
+	t.Run("Exists In Primary Only", func(t *testing.T) {
+		mt, _, _ := newTargets(t)
+		exists, err := mt.Exists(t.Context(), desc)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Push To Primary", func(t *testing.T) {
+		mt, primary, mirror := newTargets(t)
+		pushed := []byte("pushed")
+		pushedDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, pushed)
+		assert.NoError(t, mt.Push(t.Context(), pushedDesc, bytes.NewReader(pushed)))
+		assert.NoError(t, mt.Tag(t.Context(), pushedDesc, "pushed"))
+
+		exists, err := primary.Exists(t.Context(), pushedDesc)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		_, err = primary.Resolve(t.Context(), "pushed")
+		assert.NoError(t, err)
+
+		exists, err = mirror.Exists(t.Context(), pushedDesc)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Delete Unsupported", func(t *testing.T) {
+		mt, _, _ := newTargets(t)
+		assert.ErrorIs(t, mt.Delete(t.Context(), primaryDesc), errdef.ErrUnsupported)
+	})
+
+	t.Run("Predecessors Primary Error", func(t *testing.T) {
+		mt, primary, _ := newTargets(t)
+		mt.primary = &unreachableTarget{GraphTarget: primary}
+		// mirrors have no referrers of desc, which is not conclusive
+		preds, err := mt.Predecessors(t.Context(), desc)
+		var opErr *net.OpError
+		assert.ErrorAs(t, err, &opErr)
+		assert.Empty(t, preds)
+	})
+}
+
+// unreachableTarget fails to resolve references and find predecessors as if
+// its registry refused connections.
+type unreachableTarget struct {
+	oras.GraphTarget
+}
+
+func (*unreachableTarget) Resolve(context.Context, string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+}
+
+func (*unreachableTarget) Predecessors(context.Context, ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+}
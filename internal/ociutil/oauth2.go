@@ -0,0 +1,422 @@
+package ociutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// AuthProvider provides the credentials of a registry in place of those of
+// its credential store, e.g. by executing an OAuth2 flow.
+type AuthProvider interface {
+	// Credential returns the credentials of the registry.
+	Credential(ctx context.Context) (auth.Credential, error)
+}
+
+// OAuth2Flow is an OAuth2 grant executed by an [OAuth2Provider].
+type OAuth2Flow string
+
+const (
+	// OAuth2DeviceCode is the device authorization grant of RFC 8628, for
+	// interactive use. The user is prompted to authorize the device in a
+	// browser.
+	OAuth2DeviceCode OAuth2Flow = "deviceCode"
+	// OAuth2ClientCredentials is the client credentials grant of RFC 6749,
+	// for non-interactive use, e.g. workload identity.
+	OAuth2ClientCredentials OAuth2Flow = "clientCredentials"
+)
+
+const (
+	// grantTypeDeviceCode is the grant type of device access token requests.
+	grantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+	// clientAssertionTypeJWT is the type of JWT client assertions, RFC 7523.
+	clientAssertionTypeJWT = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// defaultPollInterval is the wait between device access token requests
+	// if the authorization server does not specify one.
+	defaultPollInterval = 5 * time.Second
+	// expiryDelta is the time before their expiry tokens are renewed, such
+	// that they do not expire during a request.
+	expiryDelta = time.Minute
+)
+
+var (
+	// errOAuth2 indicates an OAuth2 authorization server returned an error.
+	errOAuth2 = errors.New("oauth2 error")
+	// errUnsupportedFlow indicates an OAuth2 flow is not supported.
+	errUnsupportedFlow = errors.New("unsupported oauth2 flow")
+	// errAuthorizationPending indicates the user has not yet authorized the
+	// device.
+	errAuthorizationPending = fmt.Errorf("%w: authorization_pending", errOAuth2)
+	// errSlowDown indicates device access token requests must be sent less
+	// frequently.
+	errSlowDown = fmt.Errorf("%w: slow_down", errOAuth2)
+)
+
+// DefaultTokenCacheDir returns the default directory of cached OAuth2 tokens.
+func DefaultTokenCacheDir() string {
+	return filepath.Join(xdg.StateHome, "gnoci", "tokens")
+}
+
+// OAuth2Provider is an [AuthProvider] executing an OAuth2 flow, presenting the
+// access token to the registry. Tokens are cached in memory and on disk until
+// they expire, after which they are refreshed if possible, otherwise the flow
+// is executed again.
+type OAuth2Provider struct {
+	// Flow is the OAuth2 flow executed to obtain tokens.
+	Flow OAuth2Flow
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string
+	// DeviceAuthorizationURL is the device authorization endpoint of the
+	// authorization server, required by [OAuth2DeviceCode].
+	DeviceAuthorizationURL string
+	// ClientID identifies the client to the authorization server.
+	ClientID string
+	// ClientSecretFile is the path to a file containing the client secret.
+	ClientSecretFile string
+	// ClientAssertionFile is the path to a file containing a JWT
+	// authenticating the client, e.g. a projected service account token,
+	// read for each token request.
+	ClientAssertionFile string
+	// Scopes are the requested scopes.
+	Scopes []string
+	// Username presents the access token as the password of this user, e.g.
+	// "oauth2accesstoken" for Google Artifact Registry. Otherwise it is sent
+	// to the registry as a bearer token.
+	Username string
+	// CacheDir is the directory of cached tokens, caching in memory only if
+	// empty.
+	CacheDir string
+	// Prompt is where the user is instructed to authorize a device, stderr
+	// by default.
+	Prompt io.Writer
+	// Client sends requests to the authorization server, the default client
+	// if nil.
+	Client *http.Client
+
+	mu    sync.Mutex
+	token *oauth2Token
+}
+
+// oauth2Token is a token obtained by an [OAuth2Provider].
+type oauth2Token struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+}
+
+// valid returns true if the token has not expired. Tokens without an expiry
+// never expire.
+func (t *oauth2Token) valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry))
+}
+
+// tokenResponse is a response of the token endpoint, RFC 6749 section 5.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// deviceAuthResponse is a response of the device authorization endpoint, RFC
+// 8628 section 3.2.
+type deviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// Credential returns the access token of the provider, obtaining a new one if
+// the cached token has expired.
+func (p *OAuth2Provider) Credential(ctx context.Context) (auth.Credential, error) {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return auth.EmptyCredential, err
+	}
+	if p.Username != "" {
+		return auth.Credential{Username: p.Username, Password: token}, nil
+	}
+	return auth.Credential{AccessToken: token}, nil
+}
+
+// accessToken returns a valid access token.
+func (p *OAuth2Provider) accessToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token == nil {
+		p.token = p.readCache(ctx)
+	}
+	if p.token.valid() {
+		return p.token.AccessToken, nil
+	}
+
+	var token *oauth2Token
+	if p.token != nil && p.token.RefreshToken != "" {
+		var err error
+		token, err = p.refresh(ctx, p.token.RefreshToken)
+		if err != nil {
+			slog.DebugContext(ctx, "refreshing oauth2 token", slog.String("error", err.Error()))
+		}
+	}
+	if token == nil {
+		var err error
+		switch p.Flow {
+		case OAuth2DeviceCode:
+			token, err = p.deviceCode(ctx)
+		case OAuth2ClientCredentials:
+			token, err = p.clientCredentials(ctx)
+		default:
+			err = fmt.Errorf("%w: %q", errUnsupportedFlow, p.Flow)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	p.token = token
+	p.writeCache(ctx)
+	return token.AccessToken, nil
+}
+
+// clientCredentials executes the client credentials grant.
+func (p *OAuth2Provider) clientCredentials(ctx context.Context) (*oauth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(p.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.Scopes, " "))
+	}
+	token, err := p.requestToken(ctx, form)
+	if err != nil {
+		return nil, fmt.Errorf("requesting client credentials token: %w", err)
+	}
+	return token, nil
+}
+
+// refresh exchanges a refresh token for a new token.
+func (p *OAuth2Provider) refresh(ctx context.Context, refreshToken string) (*oauth2Token, error) {
+	token, err := p.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("requesting refreshed token: %w", err)
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// deviceCode executes the device authorization grant, prompting the user to
+// authorize the device and polling for the token until they do.
+func (p *OAuth2Provider) deviceCode(ctx context.Context) (*oauth2Token, error) {
+	if p.DeviceAuthorizationURL == "" {
+		return nil, fmt.Errorf("%w: device authorization URL is required by the %s flow", errUnsupportedFlow, OAuth2DeviceCode)
+	}
+
+	form := url.Values{"client_id": {p.ClientID}}
+	if len(p.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.Scopes, " "))
+	}
+	var da deviceAuthResponse
+	if err := p.postForm(ctx, p.DeviceAuthorizationURL, form, &da); err != nil {
+		return nil, fmt.Errorf("requesting device authorization: %w", err)
+	}
+
+	prompt := p.Prompt
+	if prompt == nil {
+		prompt = os.Stderr
+	}
+	if da.VerificationURIComplete != "" {
+		_, err := fmt.Fprintf(prompt, "To authorize access to the registry, visit %s\n", da.VerificationURIComplete)
+		if err != nil {
+			return nil, fmt.Errorf("prompting for device authorization: %w", err)
+		}
+	} else {
+		_, err := fmt.Fprintf(prompt, "To authorize access to the registry, visit %s and enter the code %s\n", da.VerificationURI, da.UserCode)
+		if err != nil {
+			return nil, fmt.Errorf("prompting for device authorization: %w", err)
+		}
+	}
+
+	interval := defaultPollInterval
+	if da.Interval > 0 {
+		interval = time.Duration(da.Interval) * time.Second
+	}
+	if da.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(da.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	form = url.Values{
+		"grant_type":  {grantTypeDeviceCode},
+		"device_code": {da.DeviceCode},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for device authorization: %w", context.Cause(ctx))
+		case <-time.After(interval):
+		}
+
+		token, err := p.requestToken(ctx, form)
+		switch {
+		case err == nil:
+			return token, nil
+		case errors.Is(err, errAuthorizationPending):
+		case errors.Is(err, errSlowDown):
+			interval += 5 * time.Second
+		default:
+			return nil, fmt.Errorf("requesting device access token: %w", err)
+		}
+	}
+}
+
+// requestToken requests a token from the token endpoint, authenticating the
+// client.
+func (p *OAuth2Provider) requestToken(ctx context.Context, form url.Values) (*oauth2Token, error) {
+	form.Set("client_id", p.ClientID)
+	if p.ClientSecretFile != "" {
+		secret, err := os.ReadFile(p.ClientSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client secret: %w", err)
+		}
+		form.Set("client_secret", strings.TrimSpace(string(secret)))
+	}
+	if p.ClientAssertionFile != "" {
+		assertion, err := os.ReadFile(p.ClientAssertionFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client assertion: %w", err)
+		}
+		form.Set("client_assertion_type", clientAssertionTypeJWT)
+		form.Set("client_assertion", strings.TrimSpace(string(assertion)))
+	}
+
+	var tr tokenResponse
+	if err := p.postForm(ctx, p.TokenURL, form, &tr); err != nil {
+		return nil, err
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token in response", errOAuth2)
+	}
+
+	token := &oauth2Token{AccessToken: tr.AccessToken, RefreshToken: tr.RefreshToken}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// postForm posts form to endpoint, decoding the JSON response into v. OAuth2
+// error responses are returned as errors.
+func (p *OAuth2Provider) postForm(ctx context.Context, endpoint string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var tr tokenResponse
+		if json.Unmarshal(body, &tr) == nil && tr.Error != "" {
+			switch tr.Error {
+			case "authorization_pending":
+				return errAuthorizationPending
+			case "slow_down":
+				return errSlowDown
+			}
+			return fmt.Errorf("%w: %s: %s", errOAuth2, tr.Error, tr.ErrorDescription)
+		}
+		return fmt.Errorf("%w: unexpected status %s", errOAuth2, resp.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// cachePath returns the path of the cached token of the provider, identified
+// by the authorization server, client, and scopes.
+func (p *OAuth2Provider) cachePath() string {
+	h := sha256.New()
+	for _, s := range append([]string{string(p.Flow), p.TokenURL, p.ClientID}, p.Scopes...) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(p.CacheDir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+// readCache reads the cached token of the provider, if any.
+func (p *OAuth2Provider) readCache(ctx context.Context) *oauth2Token {
+	if p.CacheDir == "" {
+		return nil
+	}
+	b, err := os.ReadFile(p.cachePath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.WarnContext(ctx, "reading cached oauth2 token", slog.String("error", err.Error()))
+		}
+		return nil
+	}
+	var token oauth2Token
+	if err := json.Unmarshal(b, &token); err != nil {
+		slog.WarnContext(ctx, "ignoring invalid cached oauth2 token", slog.String("error", err.Error()))
+		return nil
+	}
+	return &token
+}
+
+// writeCache caches the token of the provider, readable only by the user.
+// Failing to cache is not fatal, the token is obtained again next time.
+func (p *OAuth2Provider) writeCache(ctx context.Context) {
+	if p.CacheDir == "" {
+		return
+	}
+	b, err := json.Marshal(p.token)
+	if err != nil {
+		slog.WarnContext(ctx, "encoding oauth2 token", slog.String("error", err.Error()))
+		return
+	}
+	if err := os.MkdirAll(p.CacheDir, 0o700); err != nil {
+		slog.WarnContext(ctx, "creating oauth2 token cache", slog.String("error", err.Error()))
+		return
+	}
+	if err := os.WriteFile(p.cachePath(), b, 0o600); err != nil {
+		slog.WarnContext(ctx, "caching oauth2 token", slog.String("error", err.Error()))
+	}
+}
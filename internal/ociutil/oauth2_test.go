@@ -0,0 +1,272 @@
+package ociutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// fakeAuthServer is an OAuth2 authorization server issuing sequential access
+// tokens, e.g. "token-1".
+type fakeAuthServer struct {
+	*httptest.Server
+	issued  atomic.Int32
+	pending atomic.Int32 // device access token requests left pending
+	forms   []map[string]string
+}
+
+func newFakeAuthServer(t *testing.T, expiresIn int) *fakeAuthServer {
+	t.Helper()
+	s := &fakeAuthServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /device", func(w http.ResponseWriter, _ *http.Request) {
+		writeTestJSON(w, http.StatusOK, map[string]any{
+			"device_code":      "device",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": s.URL + "/verify",
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		form := make(map[string]string, len(r.PostForm))
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		s.forms = append(s.forms, form)
+
+		if form["grant_type"] == grantTypeDeviceCode && s.pending.Add(-1) >= 0 {
+			writeTestJSON(w, http.StatusBadRequest, map[string]any{"error": "authorization_pending"})
+			return
+		}
+		if form["grant_type"] == "refresh_token" && form["refresh_token"] == "revoked" {
+			writeTestJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant", "error_description": "revoked"})
+			return
+		}
+		n := s.issued.Add(1)
+		writeTestJSON(w, http.StatusOK, map[string]any{
+			"access_token":  "token-" + strconv.Itoa(int(n)),
+			"refresh_token": "refresh",
+			"expires_in":    expiresIn,
+		})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestOAuth2Provider_Credential(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Client Credentials", func(t *testing.T) {
+		srv := newFakeAuthServer(t, 3600)
+		secretFile := filepath.Join(t.TempDir(), "secret")
+		assert.NoError(t, os.WriteFile(secretFile, []byte("s3cret\n"), 0o600))
+
+		p := &OAuth2Provider{
+			Flow:             OAuth2ClientCredentials,
+			TokenURL:         srv.URL + "/token",
+			ClientID:         "gnoci",
+			ClientSecretFile: secretFile,
+			Scopes:           []string{"registry:pull", "registry:push"},
+		}
+		cred, err := p.Credential(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{AccessToken: "token-1"}, cred)
+		assert.Equal(t, map[string]string{
+			"grant_type":    "client_credentials",
+			"client_id":     "gnoci",
+			"client_secret": "s3cret",
+			"scope":         "registry:pull registry:push",
+		}, srv.forms[0])
+
+		// the token is reused until it expires
+		cred, err = p.Credential(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{AccessToken: "token-1"}, cred)
+		assert.Len(t, srv.forms, 1)
+	})
+
+	t.Run("Client Assertion", func(t *testing.T) {
+		srv := newFakeAuthServer(t, 3600)
+		assertionFile := filepath.Join(t.TempDir(), "token")
+		assert.NoError(t, os.WriteFile(assertionFile, []byte("header.payload.signature"), 0o600))
+
+		p := &OAuth2Provider{
+			Flow:                OAuth2ClientCredentials,
+			TokenURL:            srv.URL + "/token",
+			ClientID:            "gnoci",
+			ClientAssertionFile: assertionFile,
+			Username:            "oauth2accesstoken",
+		}
+		cred, err := p.Credential(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "oauth2accesstoken", Password: "token-1"}, cred)
+		assert.Equal(t, clientAssertionTypeJWT, srv.forms[0]["client_assertion_type"])
+		assert.Equal(t, "header.payload.signature", srv.forms[0]["client_assertion"])
+	})
+
+	t.Run("Device Code", func(t *testing.T) {
+		srv := newFakeAuthServer(t, 3600)
+		srv.pending.Store(1)
+
+		prompt := new(bytes.Buffer)
+		p := &OAuth2Provider{
+			Flow:                   OAuth2DeviceCode,
+			TokenURL:               srv.URL + "/token",
+			DeviceAuthorizationURL: srv.URL + "/device",
+			ClientID:               "gnoci",
+			Prompt:                 prompt,
+		}
+		cred, err := p.Credential(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{AccessToken: "token-1"}, cred)
+		assert.Contains(t, prompt.String(), srv.URL+"/verify")
+		assert.Contains(t, prompt.String(), "ABCD-EFGH")
+
+		// polled until authorized
+		assert.Len(t, srv.forms, 2)
+		assert.Equal(t, "device", srv.forms[1]["device_code"])
+	})
+
+	t.Run("Device Code Canceled", func(t *testing.T) {
+		srv := newFakeAuthServer(t, 3600)
+		srv.pending.Store(100)
+
+		p := &OAuth2Provider{
+			Flow:                   OAuth2DeviceCode,
+			TokenURL:               srv.URL + "/token",
+			DeviceAuthorizationURL: srv.URL + "/device",
+			Prompt:                 new(bytes.Buffer),
+		}
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := p.Credential(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Expired Token Refreshed", func(t *testing.T) {
+		// tokens expire within the expiry delta, so are always renewed
+		srv := newFakeAuthServer(t, 1)
+
+		p := &OAuth2Provider{
+			Flow:     OAuth2ClientCredentials,
+			TokenURL: srv.URL + "/token",
+		}
+		_, err := p.Credential(ctx)
+		assert.NoError(t, err)
+		cred, err := p.Credential(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{AccessToken: "token-2"}, cred)
+		assert.Equal(t, "refresh_token", srv.forms[1]["grant_type"])
+		assert.Equal(t, "refresh", srv.forms[1]["refresh_token"])
+	})
+
+	t.Run("Failed Refresh Reruns Flow", func(t *testing.T) {
+		srv := newFakeAuthServer(t, 3600)
+
+		p := &OAuth2Provider{
+			Flow:     OAuth2ClientCredentials,
+			TokenURL: srv.URL + "/token",
+			token:    &oauth2Token{AccessToken: "expired", RefreshToken: "revoked", Expiry: time.Now()},
+		}
+		cred, err := p.Credential(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{AccessToken: "token-1"}, cred)
+		assert.Equal(t, "client_credentials", srv.forms[1]["grant_type"])
+	})
+
+	t.Run("Disk Cache", func(t *testing.T) {
+		srv := newFakeAuthServer(t, 3600)
+		cacheDir := filepath.Join(t.TempDir(), "tokens")
+
+		newProvider := func() *OAuth2Provider {
+			return &OAuth2Provider{
+				Flow:     OAuth2ClientCredentials,
+				TokenURL: srv.URL + "/token",
+				ClientID: "gnoci",
+				CacheDir: cacheDir,
+			}
+		}
+		_, err := newProvider().Credential(ctx)
+		assert.NoError(t, err)
+
+		entries, err := os.ReadDir(cacheDir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		fi, err := entries[0].Info()
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+		// a new provider, e.g. of the next git invocation, reads the cache
+		cred, err := newProvider().Credential(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Credential{AccessToken: "token-1"}, cred)
+		assert.Len(t, srv.forms, 1)
+	})
+
+	t.Run("Error Response", func(t *testing.T) {
+		srv := newFakeAuthServer(t, 3600)
+
+		p := &OAuth2Provider{
+			Flow:     OAuth2ClientCredentials,
+			TokenURL: srv.URL + "/missing",
+		}
+		_, err := p.Credential(ctx)
+		assert.ErrorIs(t, err, errOAuth2)
+	})
+
+	t.Run("Unsupported Flow", func(t *testing.T) {
+		_, err := (&OAuth2Provider{Flow: "implicit"}).Credential(ctx)
+		assert.ErrorIs(t, err, errUnsupportedFlow)
+	})
+}
+
+// staticProvider is an [AuthProvider] returning a fixed credential.
+type staticProvider auth.Credential
+
+func (p staticProvider) Credential(context.Context) (auth.Credential, error) {
+	return auth.Credential(p), nil
+}
+
+func Test_providerStore(t *testing.T) {
+	ctx := context.Background()
+	store := credentials.NewMemoryStore()
+	assert.NoError(t, store.Put(ctx, "other.example.com", auth.Credential{Username: "user", Password: "pass"}))
+
+	ps := newProviderStore(store, map[string]AuthProvider{
+		"example.com": staticProvider{AccessToken: "token"},
+		"docker.io":   staticProvider{AccessToken: "hub"},
+	})
+	credFunc := credentials.Credential(ps)
+
+	cred, err := credFunc(ctx, "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Credential{AccessToken: "token"}, cred)
+
+	cred, err = credFunc(ctx, "registry-1.docker.io")
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Credential{AccessToken: "hub"}, cred)
+
+	cred, err = credFunc(ctx, "other.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Credential{Username: "user", Password: "pass"}, cred)
+}
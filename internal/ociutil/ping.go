@@ -0,0 +1,109 @@
+package ociutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// ErrRegistryUnavailable indicates a registry failed the preflight check of
+// its API.
+var ErrRegistryUnavailable = errors.New("registry unavailable")
+
+// PingError is the failure of a registry preflight check, with a hint of the
+// configuration likely to resolve it.
+type PingError struct {
+	// Registry is the host of the registry.
+	Registry string
+	// Hint suggests how to resolve the failure, if known.
+	Hint string
+	// Err is the underlying failure.
+	Err error
+}
+
+// Error returns the failure and its hint.
+func (e *PingError) Error() string {
+	msg := fmt.Sprintf("%s: %s: %v", ErrRegistryUnavailable, e.Registry, e.Err)
+	if e.Hint != "" {
+		msg += "; " + e.Hint
+	}
+	return msg
+}
+
+// Unwrap returns [ErrRegistryUnavailable] and the underlying failure.
+func (e *PingError) Unwrap() []error {
+	return []error{ErrRegistryUnavailable, e.Err}
+}
+
+// Ping checks the registry of ref supports the OCI distribution API and
+// accepts the configured credentials, e.g. before a long push. Common
+// failures are returned as a [*PingError] hinting at the configuration
+// resolving them.
+func Ping(ctx context.Context, ref registry.Reference, opts *RepositoryOptions) error {
+	opts.defaulter(ctx)
+
+	reg, err := newRegistry(ctx, ref, opts)
+	if err != nil {
+		return err
+	}
+	if err := reg.Ping(ctx); err != nil {
+		return &PingError{Registry: ref.Registry, Hint: pingHint(ref.Registry, opts.PlainHTTP, err), Err: err}
+	}
+	return nil
+}
+
+// pingHint returns a suggestion resolving the failed ping of host, or an
+// empty string if the failure is not recognized.
+func pingHint(host string, plainHTTP bool, err error) string {
+	var errResp *errcode.ErrorResponse
+	if errors.As(err, &errResp) {
+		switch errResp.StatusCode {
+		case http.StatusUnauthorized:
+			return fmt.Sprintf("check the credentials of %s, e.g. with \"docker login %s\", or configure its credential helper with \"credHelpers\"", host, host)
+		case http.StatusForbidden:
+			return fmt.Sprintf("the credentials of %s are not permitted to access it", host)
+		case http.StatusBadRequest:
+			if plainHTTP {
+				return fmt.Sprintf("the registry may require HTTPS, unset \"registries.%s.plainHTTP\"", host)
+			}
+		}
+		return ""
+	}
+
+	if errors.Is(err, errdef.ErrNotFound) {
+		return fmt.Sprintf("%s does not serve the OCI distribution API, check the registry address", host)
+	}
+
+	var (
+		certErr      *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &certErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return fmt.Sprintf("trust the certificate of %s with \"registries.%s.tls.certDir\", or skip its verification with \"registries.%s.tls.insecureSkipVerify\"", host, host, host)
+	}
+
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &recordErr) || strings.Contains(err.Error(), "server gave HTTP response to HTTPS client") {
+		return fmt.Sprintf("the registry may only serve HTTP, set \"registries.%s.plainHTTP: true\"", host)
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial") {
+		return fmt.Sprintf("check %s is reachable, or configure its proxy with \"registries.%s.proxyURL\"", host, host)
+	}
+
+	return ""
+}
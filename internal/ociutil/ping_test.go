@@ -0,0 +1,86 @@
+package ociutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+func TestPing(t *testing.T) {
+	handler := func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+	}
+
+	tests := []struct {
+		name      string
+		tls       bool
+		status    int
+		plainHTTP bool
+		wantErr   bool
+		wantHint  string
+	}{
+		{name: "OK", status: http.StatusOK, plainHTTP: true},
+		{name: "Unauthorized", status: http.StatusUnauthorized, plainHTTP: true, wantErr: true, wantHint: "docker login"},
+		{name: "Forbidden", status: http.StatusForbidden, plainHTTP: true, wantErr: true, wantHint: "not permitted"},
+		{name: "Not Found", status: http.StatusNotFound, plainHTTP: true, wantErr: true, wantHint: "OCI distribution API"},
+		{name: "Plain HTTP Server", status: http.StatusOK, wantErr: true, wantHint: "plainHTTP: true"},
+		{name: "Untrusted Certificate", tls: true, status: http.StatusOK, wantErr: true, wantHint: "tls.certDir"},
+		{name: "Plain HTTP Client", tls: true, status: http.StatusBadRequest, plainHTTP: true, wantErr: true, wantHint: "unset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var srv *httptest.Server
+			if tt.tls {
+				srv = httptest.NewTLSServer(handler(tt.status))
+			} else {
+				srv = httptest.NewServer(handler(tt.status))
+			}
+			defer srv.Close()
+			u, err := url.Parse(srv.URL)
+			assert.NoError(t, err)
+
+			opts := &RepositoryOptions{
+				UserAgent:     "test",
+				PlainHTTP:     tt.plainHTTP,
+				RegistryCreds: credentials.NewMemoryStore(),
+				Retry:         RetryPolicy{MaxAttempts: 1},
+				TLS:           TLS{CertDir: t.TempDir()},
+			}
+			err = Ping(t.Context(), registry.Reference{Registry: u.Host, Repository: "repo"}, opts)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrRegistryUnavailable)
+			var pingErr *PingError
+			if assert.ErrorAs(t, err, &pingErr) {
+				assert.Contains(t, pingErr.Hint, tt.wantHint)
+			}
+		})
+	}
+
+	t.Run("Unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(handler(http.StatusOK))
+		host := srv.Listener.Addr().String()
+		srv.Close()
+
+		opts := &RepositoryOptions{
+			UserAgent:     "test",
+			PlainHTTP:     true,
+			RegistryCreds: credentials.NewMemoryStore(),
+			Retry:         RetryPolicy{MaxAttempts: 1},
+		}
+		err := Ping(t.Context(), registry.Reference{Registry: host, Repository: "repo"}, opts)
+		var pingErr *PingError
+		if assert.ErrorAs(t, err, &pingErr) {
+			assert.Contains(t, pingErr.Hint, "reachable")
+		}
+	})
+}
@@ -26,6 +26,7 @@ import (
 	"oras.land/oras-go/v2/registry/remote/retry"
 
 	"github.com/act3-ai/gnoci/internal/logutil"
+	"github.com/act3-ai/gnoci/internal/tracing"
 )
 
 // This file is a modification of the contents of https://github.com/act3-ai/data-tool/blob/v1.16.1/internal/registry/registry.go
@@ -35,6 +36,8 @@ const (
 	GitUserAgent = "git-remote-oci"
 	// GitLFSUserAgent is used by git-lfs-remote-oci.
 	GitLFSUserAgent = "git-lfs-remote-oci"
+	// GnociUserAgent is used by the gnoci command.
+	GnociUserAgent = "gnoci-cli"
 	// gnociUserAgent is a fallback user agent if none is explicitly provided.
 	// Used to differentiate developer bugs and intentional uses of [GitUserAgent]
 	// and [GitLFSUserAgent].
@@ -51,10 +54,31 @@ type RepositoryOptions struct {
 	// NonCompliant indicates a registry is not OCI compliant. Primarily used
 	// for non-compliant auth handling, e.g. artifactory.
 	NonCompliant bool
+	// ReferrersTagSchema forces the referrers tag schema rather than the
+	// Referrers API.
+	ReferrersTagSchema bool
 	// RegistryCreds is a credential store for bearer tokens used for authenticating
-	// with private registries. The standard $DOCKER_CONFIG/config.json, defaulting
-	// to $HOME/.docker/config.json, is used if empty.
+	// with private registries. Defaults to [NewCredentialStore] if empty.
 	RegistryCreds credentials.Store
+	// CredHelpers maps registries to the name of the credential helper invoked
+	// when RegistryCreds is defaulted, e.g. "ecr-login" for docker-credential-ecr-login.
+	CredHelpers map[string]string
+	// AuthProviders maps registries to the provider of their credentials when
+	// RegistryCreds is defaulted, taking precedence over credential helpers
+	// and auth files.
+	AuthProviders map[string]AuthProvider
+	// Mirrors are read from, in order, before the registry itself.
+	Mirrors []Mirror
+	// Retry configures the retry of failed requests.
+	Retry RetryPolicy
+	// Proxy configures the proxy of requests, the environment's by default.
+	Proxy Proxy
+	// Socket is the path of a Unix domain socket connections to the registry
+	// are dialed through, e.g. of a registry side-car, rather than its host.
+	// Requests are never proxied.
+	Socket string
+	// TLS configures the TLS connections to the registry.
+	TLS TLS
 }
 
 // defaulter defaults options that are not required by users but necessary for
@@ -66,26 +90,65 @@ func (r *RepositoryOptions) defaulter(ctx context.Context) {
 	}
 
 	if r.RegistryCreds == nil {
-		var err error
-		storeOpts := credentials.StoreOptions{}
-		r.RegistryCreds, err = credentials.NewStoreFromDocker(storeOpts)
-		if err != nil {
-			slog.ErrorContext(ctx, "failed to get default docker credential store", slog.String("error", err.Error()))
-			r.RegistryCreds = credentials.NewMemoryStore()
+		r.RegistryCreds = NewCredentialStore(ctx, r.CredHelpers)
+		if len(r.AuthProviders) > 0 {
+			r.RegistryCreds = newProviderStore(r.RegistryCreds, r.AuthProviders)
 		}
 	}
 }
 
-// NewGraphTarget creates an oras.GraphTarget.
+// NewGraphTarget creates an oras.GraphTarget. If mirrors are configured, a
+// [MirroredTarget] is returned.
 //
 // TODO: Due to a need to support special use cases, we'll likely need to define a configuration file.
 func NewGraphTarget(ctx context.Context, ref registry.Reference, opts *RepositoryOptions) (oras.GraphTarget, error) {
 	opts.defaulter(ctx)
 
-	return create(ctx, ref, opts)
+	gt, err := create(ctx, ref, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Mirrors) == 0 {
+		return gt, nil
+	}
+
+	return newMirroredTarget(ctx, gt, ref, opts)
+}
+
+// NewRegistry creates a client for the registry at host, e.g. for listing its
+// repositories.
+func NewRegistry(ctx context.Context, host string, opts *RepositoryOptions) (*remote.Registry, error) {
+	opts.defaulter(ctx)
+	return newRegistry(ctx, registry.Reference{Registry: host}, opts)
 }
 
 func create(ctx context.Context, ref registry.Reference, opts *RepositoryOptions) (oras.GraphTarget, error) {
+	reg, err := newRegistry(ctx, ref, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := reg.Repository(ctx, ref.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("creating registry repository: %w", err)
+	}
+
+	r, ok := repo.(*remote.Repository)
+	if !ok {
+		return nil, fmt.Errorf("error creating registry repository: %s", ref)
+	}
+	if opts.ReferrersTagSchema {
+		if err := r.SetReferrersCapability(false); err != nil {
+			return nil, fmt.Errorf("forcing referrers tag schema: %w", err)
+		}
+	}
+
+	return oras.GraphTarget(r), nil
+}
+
+// newRegistry creates the registry client of ref.
+func newRegistry(ctx context.Context, ref registry.Reference, opts *RepositoryOptions) (*remote.Registry, error) {
 	log := logger.FromContext(ctx)
 
 	var cache auth.Cache
@@ -96,7 +159,7 @@ func create(ctx context.Context, ref registry.Reference, opts *RepositoryOptions
 		cache = auth.DefaultCache
 	}
 
-	c, err := newHTTPClientWithOps(ref.Registry, "") // TODO: plumbing for custom TLS cert paths?
+	c, err := newHTTPClientWithOps(ref.Registry, ref.Repository, opts.TLS, opts.Retry, opts.Proxy, opts.Socket)
 	if err != nil {
 		return nil, err
 	}
@@ -118,29 +181,27 @@ func create(ctx context.Context, ref registry.Reference, opts *RepositoryOptions
 		},
 	}
 
-	repo, err := reg.Repository(ctx, ref.Repository)
-	if err != nil {
-		return nil, fmt.Errorf("creating registry repository: %w", err)
-	}
+	return reg, nil
+}
 
-	r, ok := repo.(*remote.Repository)
-	if !ok {
-		return nil, fmt.Errorf("error creating registry repository: %s", ref)
+// newHTTPClientWithOps returns a client with a logging transport wrapped in a retry transport.
+// TLS certificates are loaded from the configured directory, otherwise the standard locations of hostName,
+// presenting the client certificate configured for repository, if any.
+// Requests are routed through the configured proxy, or the environment's, unless socket is set, in which case
+// every connection dials the Unix domain socket at that path.
+func newHTTPClientWithOps(hostName, repository string, tlsOpts TLS, retryPolicy RetryPolicy, proxyOpts Proxy, socket string) (*http.Client, error) {
+	proxyFunc, err := proxyOpts.proxyFunc()
+	if err != nil {
+		return nil, fmt.Errorf("configuring proxy of %s: %w", hostName, err)
 	}
 
-	return oras.GraphTarget(r), nil
-}
-
-// if a nil TLS is passed, return a client with a logging transport wrapped in a retry transport.
-// if a TLS config exists, search for TLS certs and append to client.
-func newHTTPClientWithOps(hostName, customCertPath string) (*http.Client, error) {
 	nd := &net.Dialer{
 		Timeout:   30 * time.Second,
 		KeepAlive: 30 * time.Second,
 	}
 	// defaultTransport is a new instance of the default transport
 	var defaultTransport = &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
+		Proxy:                 proxyFunc,
 		DialContext:           nd.DialContext,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
@@ -149,38 +210,39 @@ func newHTTPClientWithOps(hostName, customCertPath string) (*http.Client, error)
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
-	var certLocation string
-	if customCertPath == "" {
-		cLocation, err := resolveTLSCertLocation(getStandardCertLocations(hostName))
-		if err != nil {
-			return nil, err
-		}
-		certLocation = cLocation
-	}
-
-	ssl, err := fetchCertsFromLocation(certLocation)
+	ssl, err := tlsOpts.config(hostName, repository)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("configuring TLS of %s: %w", hostName, err)
 	}
 
 	defaultTransport.TLSClientConfig = ssl
 
-	// get the proxy from the environment
+	// get the proxy from the environment, unless explicitly configured
 	dialer := proxy.FromEnvironment()
 
-	if dialer != nil {
+	switch {
+	case socket != "":
+		defaultTransport.Proxy = nil
+		defaultTransport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return nd.DialContext(ctx, "unix", socket)
+		}
+	case dialer != nil && proxyOpts.URL == "":
 		defaultTransport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
 			return dialer.Dial(network, addr)
 		}
 	}
 
-	// log requests to the logger (if verbosity is high enough)
+	// log requests to the logger (if verbosity is high enough), tracing each attempt
 	lt := &logutil.LoggingTransport{
-		Base: defaultTransport,
+		Base: tracing.Transport(defaultTransport),
 	}
 
 	// we still want retry
-	rt := retry.NewTransport(lt)
+	policy := retryPolicy.policy()
+	rt := &retry.Transport{
+		Base:   lt,
+		Policy: func() retry.Policy { return policy },
+	}
 
 	return &http.Client{
 		Transport: rt,
@@ -227,12 +289,13 @@ func fetchCertsFromLocation(certDir string) (*tls.Config, error) {
 	if certDir != "" {
 		// Load client cert
 		cert, err := tls.LoadX509KeyPair(certFilePath, keyFilePath)
-		if err != nil {
-			if !errors.Is(err, fs.ErrNotExist) {
-				return nil, fmt.Errorf("error reading the certificate and key files: %w", err)
-			}
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("error reading the certificate and key files: %w", err)
+		default:
+			tlscfg.Certificates = []tls.Certificate{cert}
 		}
-		tlscfg.Certificates = []tls.Certificate{cert}
 
 		// Load CA cert
 		caCert, err := os.ReadFile(caFilePath)
@@ -8,10 +8,11 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
-	"reflect"
 	"testing"
 	"time"
 
@@ -120,6 +121,20 @@ func Test_create(t *testing.T) {
 		assert.Equal(t, auth.DefaultCache, client.Cache)
 	})
 
+	t.Run("Referrers Tag Schema", func(t *testing.T) {
+		opts := RepositoryOptions{
+			ReferrersTagSchema: true,
+		}
+
+		gt, err := create(t.Context(), testRemote, &opts)
+		assert.NoError(t, err)
+
+		repo, ok := gt.(*remote.Repository)
+		assert.True(t, ok)
+		// the capability is already set
+		assert.ErrorIs(t, repo.SetReferrersCapability(true), remote.ErrReferrersCapabilityAlreadySet)
+	})
+
 	t.Run("NonCompliant Auth Cache", func(t *testing.T) {
 		opts := RepositoryOptions{
 			NonCompliant: true,
@@ -174,32 +189,84 @@ func Test_create(t *testing.T) {
 }
 
 func Test_newHTTPClientWithOps(t *testing.T) {
-	type args struct {
-		hostName       string
-		customCertPath string
-	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	caDir := t.TempDir()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(filepath.Join(caDir, "ca.pem"), caPEM, 0o600))
+
 	tests := []struct {
-		name    string
-		args    args
-		want    *http.Client
-		wantErr bool
+		name       string
+		tls        TLS
+		wantErr    bool
+		wantReqErr bool
 	}{
-		// TODO: Add test cases.
+		{name: "Untrusted", tls: TLS{CertDir: t.TempDir()}, wantReqErr: true},
+		{name: "Custom CA", tls: TLS{CertDir: caDir}},
+		{name: "Insecure Skip Verify", tls: TLS{InsecureSkipVerify: true}},
+		{name: "Minimum Version", tls: TLS{CertDir: caDir, MinVersion: "1.3"}},
+		{name: "Unsupported Version", tls: TLS{MinVersion: "1.1"}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := newHTTPClientWithOps(tt.args.hostName, tt.args.customCertPath)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("newHTTPClientWithOps() error = %v, wantErr %v", err, tt.wantErr)
+			c, err := newHTTPClientWithOps("registry.example.com", "", tt.tls, RetryPolicy{MaxAttempts: 1}, Proxy{}, "")
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errUnsupportedTLSVersion)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("newHTTPClientWithOps() = %v, want %v", got, tt.want)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL, nil)
+			assert.NoError(t, err)
+			resp, err := c.Do(req)
+			if tt.wantReqErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.NoError(t, resp.Body.Close())
 			}
 		})
 	}
 }
 
+func Test_newHTTPClientWithOps_Socket(t *testing.T) {
+	// unix socket paths are limited in length, t.TempDir is not
+	dir, err := os.MkdirTemp("", "gnoci")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	l, err := net.Listen("unix", filepath.Join(dir, "registry.sock"))
+	assert.NoError(t, err)
+	var gotHost string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	// the socket is dialed directly, never through a proxy
+	t.Setenv("HTTP_PROXY", "http://proxy.invalid:3128")
+
+	c, err := newHTTPClientWithOps("registry.example.com", "", TLS{}, RetryPolicy{MaxAttempts: 1}, Proxy{URL: "http://proxy.invalid:3128"}, l.Addr().String())
+	assert.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://registry.example.com/v2/", nil)
+	assert.NoError(t, err)
+	resp, err := c.Do(req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NoError(t, resp.Body.Close())
+	}
+	assert.Equal(t, "registry.example.com", gotHost)
+}
+
 func Test_resolveTLSCertLocation(t *testing.T) {
 	t.Run("Success On First", func(t *testing.T) {
 		filePath := filepath.Join(t.TempDir(), "foo.pem")
@@ -0,0 +1,117 @@
+package ociutil
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+const (
+	defaultMaxAttempts    = 6
+	defaultInitialBackoff = 250 * time.Millisecond
+	defaultMaxBackoff     = 3 * time.Second
+)
+
+// RetryPolicy configures the retry of failed registry requests. Zero values
+// are defaulted.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request, including
+	// the first. Defaults to 6, 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubling for each
+	// subsequent retry. Defaults to 250ms.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the wait between retries. Defaults to 3s.
+	MaxBackoff time.Duration
+	// NoRetryTooManyRequests fails requests rate limited with 429 Too Many
+	// Requests, rather than retrying them after the wait requested by the
+	// Retry-After header.
+	NoRetryTooManyRequests bool
+}
+
+// retryPolicy is a [retry.Policy] logging a warning for each retry.
+type retryPolicy struct {
+	maxRetry        int
+	backoff         retry.Backoff
+	maxBackoff      time.Duration
+	tooManyRequests bool
+}
+
+// policy returns the [retry.Policy] of p.
+func (p RetryPolicy) policy() *retryPolicy {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	return &retryPolicy{
+		maxRetry:        maxAttempts - 1,
+		backoff:         retry.ExponentialBackoff(initial, 2, 0.1),
+		maxBackoff:      maxBackoff,
+		tooManyRequests: !p.NoRetryTooManyRequests,
+	}
+}
+
+// Retry returns the duration to wait before retrying a request, or a negative
+// duration if it should not be retried. Waits requested by the Retry-After
+// header of rate limited requests are not limited by the maximum backoff.
+func (p *retryPolicy) Retry(attempt int, resp *http.Response, respErr error) (time.Duration, error) {
+	if attempt >= p.maxRetry {
+		return -1, nil
+	}
+
+	var wait time.Duration
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if !p.tooManyRequests {
+			return -1, nil
+		}
+		if seconds, err := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64); err == nil && seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if wait == 0 {
+		ok, err := retry.DefaultPredicate(resp, respErr)
+		if err != nil {
+			return -1, err //nolint:wrapcheck
+		}
+		if !ok {
+			return -1, nil
+		}
+		wait = min(p.backoff(attempt, nil), p.maxBackoff)
+	}
+
+	warnRetry(attempt, resp, respErr, wait)
+	return wait, nil
+}
+
+// warnRetry surfaces a retried request, such that users understand slow
+// operations.
+func warnRetry(attempt int, resp *http.Response, respErr error, wait time.Duration) {
+	ctx := context.Background()
+	attrs := []any{slog.Int("attempt", attempt+1), slog.Duration("wait", wait)}
+	if resp != nil {
+		if resp.Request != nil {
+			ctx = resp.Request.Context()
+			attrs = append(attrs, slog.String("method", resp.Request.Method), slog.String("url", resp.Request.URL.Redacted()))
+		}
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if respErr != nil {
+		attrs = append(attrs, slog.String("error", respErr.Error()))
+	}
+
+	slog.WarnContext(ctx, "retrying registry request", attrs...)
+}
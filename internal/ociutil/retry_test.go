@@ -0,0 +1,111 @@
+package ociutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_retryPolicy_Retry(t *testing.T) {
+	response := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	t.Run("Server Error", func(t *testing.T) {
+		p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 1500 * time.Millisecond}.policy()
+
+		wait, err := p.Retry(0, response(http.StatusServiceUnavailable, ""), nil)
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Second, wait, float64(100*time.Millisecond))
+
+		// limited by the maximum backoff
+		wait, err = p.Retry(3, response(http.StatusServiceUnavailable, ""), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1500*time.Millisecond, wait)
+	})
+
+	t.Run("Client Error", func(t *testing.T) {
+		p := RetryPolicy{}.policy()
+
+		wait, err := p.Retry(0, response(http.StatusNotFound, ""), nil)
+		assert.NoError(t, err)
+		assert.Negative(t, wait)
+	})
+
+	t.Run("Max Attempts", func(t *testing.T) {
+		p := RetryPolicy{MaxAttempts: 2}.policy()
+
+		wait, err := p.Retry(0, response(http.StatusBadGateway, ""), nil)
+		assert.NoError(t, err)
+		assert.Positive(t, wait)
+
+		wait, err = p.Retry(1, response(http.StatusBadGateway, ""), nil)
+		assert.NoError(t, err)
+		assert.Negative(t, wait)
+	})
+
+	t.Run("Retry After", func(t *testing.T) {
+		p := RetryPolicy{}.policy()
+
+		// not limited by the maximum backoff
+		wait, err := p.Retry(0, response(http.StatusTooManyRequests, "30"), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, wait)
+
+		wait, err = p.Retry(0, response(http.StatusTooManyRequests, ""), nil)
+		assert.NoError(t, err)
+		assert.Positive(t, wait)
+	})
+
+	t.Run("No Retry Too Many Requests", func(t *testing.T) {
+		p := RetryPolicy{NoRetryTooManyRequests: true}.policy()
+
+		wait, err := p.Retry(0, response(http.StatusTooManyRequests, "1"), nil)
+		assert.NoError(t, err)
+		assert.Negative(t, wait)
+	})
+}
+
+func Test_newHTTPClientWithOps_Retry(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	t.Run("Retried", func(t *testing.T) {
+		requests.Store(0)
+		c, err := newHTTPClientWithOps("127.0.0.1", "", TLS{}, RetryPolicy{InitialBackoff: time.Millisecond}, Proxy{}, "")
+		assert.NoError(t, err)
+
+		resp, err := c.Get(srv.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		requests.Store(0)
+		c, err := newHTTPClientWithOps("127.0.0.1", "", TLS{}, RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}, Proxy{}, "")
+		assert.NoError(t, err)
+
+		resp, err := c.Get(srv.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+}
@@ -0,0 +1,161 @@
+package ociutil
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TarTarget is an oras.GraphTarget backed by an OCI image layout tarball.
+//
+// The tarball is extracted to a temporary directory when opened, and written
+// back by [TarTarget.Close] if its content was modified.
+type TarTarget struct {
+	*layoutTarget
+
+	path     string
+	dir      string
+	modified atomic.Bool
+}
+
+// NewTarTarget opens the OCI image layout tarball at path, which is created
+// on close if it does not exist.
+func NewTarTarget(path string) (*TarTarget, error) {
+	dir, err := os.MkdirTemp("", "GnOCI-layout-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary directory for OCI image layout: %w", err)
+	}
+
+	if err := extractTar(path, dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Join(fmt.Errorf("extracting OCI image layout tarball %s: %w", path, err), os.RemoveAll(dir))
+	}
+
+	lt, err := newLayoutTarget(dir)
+	if err != nil {
+		return nil, errors.Join(err, os.RemoveAll(dir))
+	}
+	return &TarTarget{layoutTarget: lt, path: path, dir: dir}, nil
+}
+
+// Push pushes content to the OCI image layout.
+func (tt *TarTarget) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	tt.modified.Store(true)
+	return tt.layoutTarget.Push(ctx, expected, content) //nolint:wrapcheck
+}
+
+// Tag tags the described content with the tag or digest of reference.
+func (tt *TarTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	tt.modified.Store(true)
+	return tt.layoutTarget.Tag(ctx, desc, reference)
+}
+
+// Delete removes the described content from the OCI image layout.
+func (tt *TarTarget) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	tt.modified.Store(true)
+	return tt.layoutTarget.Delete(ctx, target) //nolint:wrapcheck
+}
+
+// Close writes the OCI image layout tarball if modified, and removes the
+// temporary layout directory.
+func (tt *TarTarget) Close() error {
+	var errs []error
+	if tt.modified.Load() {
+		if err := writeTar(tt.dir, tt.path); err != nil {
+			errs = append(errs, fmt.Errorf("writing OCI image layout tarball %s: %w", tt.path, err))
+		}
+	}
+	if err := os.RemoveAll(tt.dir); err != nil {
+		errs = append(errs, fmt.Errorf("removing temporary OCI image layout: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// extractTar extracts the regular files and directories of the tarball at
+// path into dir.
+func extractTar(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar header: %w", err)
+		}
+
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("tar entry %s is outside of the OCI image layout", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("creating directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, target); err != nil {
+				return fmt.Errorf("extracting %s: %w", hdr.Name, err)
+			}
+		}
+	}
+}
+
+// extractFile writes the content of r to a new file at target.
+func extractFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("creating parent directory: %w", err)
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		return errors.Join(fmt.Errorf("writing file: %w", err), f.Close())
+	}
+	return f.Close() //nolint:wrapcheck
+}
+
+// writeTar archives dir to the tarball at path, replacing it atomically.
+func writeTar(dir, path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".gnoci-*.tar")
+	if err != nil {
+		return fmt.Errorf("creating temporary tarball: %w", err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // no-op once renamed
+
+	if err := f.Chmod(0o644); err != nil {
+		return errors.Join(fmt.Errorf("setting tarball permissions: %w", err), f.Close())
+	}
+
+	tw := tar.NewWriter(f)
+	if err := tw.AddFS(os.DirFS(dir)); err != nil {
+		return errors.Join(fmt.Errorf("archiving OCI image layout: %w", err), f.Close())
+	}
+	if err := tw.Close(); err != nil {
+		return errors.Join(fmt.Errorf("finalizing tarball: %w", err), f.Close())
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing tarball: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("replacing tarball: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package ociutil
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+)
+
+func TestTarTarget(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "repo.tar")
+
+	tt, err := NewTarTarget(path)
+	assert.NoError(t, err)
+	desc, err := oras.PushBytes(ctx, tt, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+	assert.NoError(t, err)
+	assert.NoError(t, tt.Tag(ctx, desc, "layout.local/git:sync"))
+	assert.NoError(t, tt.Close())
+	assert.FileExists(t, path)
+	assert.NoDirExists(t, tt.dir)
+
+	t.Run("Reopen", func(t *testing.T) {
+		reopened, err := NewTarTarget(path)
+		assert.NoError(t, err)
+		got, err := reopened.Resolve(ctx, "layout.local/git:sync")
+		assert.NoError(t, err)
+		assert.Equal(t, desc.Digest, got.Digest)
+
+		// unmodified tarballs are not rewritten
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.NoError(t, reopened.Close())
+		after, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, info.ModTime(), after.ModTime())
+	})
+
+	t.Run("Entry Outside Layout", func(t *testing.T) {
+		evil := filepath.Join(t.TempDir(), "evil.tar")
+		f, err := os.Create(evil)
+		assert.NoError(t, err)
+		tw := tar.NewWriter(f)
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0o644}))
+		assert.NoError(t, tw.Close())
+		assert.NoError(t, f.Close())
+
+		_, err = NewTarTarget(evil)
+		assert.Error(t, err)
+	})
+}
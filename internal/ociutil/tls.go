@@ -0,0 +1,154 @@
+package ociutil
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// errUnsupportedTLSVersion indicates a TLS version is not supported.
+	errUnsupportedTLSVersion = errors.New("unsupported TLS version")
+	// ErrInvalidClientCertificate indicates a configured client certificate
+	// could not be loaded.
+	ErrInvalidClientCertificate = errors.New("invalid client certificate")
+)
+
+// TLS configures the TLS connections to a registry. The zero value searches
+// the containerd and docker certificate directories of the registry.
+type TLS struct {
+	// CertDir is a directory holding any of the CA certificate "ca.pem",
+	// trusted in addition to the system certificates, and the client
+	// certificate "cert.pem" and key "key.pem". If set, the containerd and
+	// docker certificate directories are not searched.
+	CertDir string
+	// InsecureSkipVerify disables verification of the registry's certificate.
+	InsecureSkipVerify bool
+	// MinVersion is the minimum TLS version, "1.2" or "1.3". Defaults to
+	// "1.2".
+	MinVersion string
+	// ClientCertificates are the client certificates presented for
+	// repositories of the registry, overriding that of the certificate
+	// directory. The certificate of the longest matching repository prefix
+	// is presented.
+	ClientCertificates []ClientCertificate
+}
+
+// ClientCertificate is a client certificate presented to a registry for the
+// repositories under a prefix, such that namespaces of the same registry may
+// be accessed with separate identities. The certificate and key are read from
+// files, or from environment variables holding them PEM encoded.
+type ClientCertificate struct {
+	// Repository is the prefix of the repositories the certificate is
+	// presented for, matching whole path components, e.g. "team-a" matches
+	// "team-a/repo" but not "team-ab/repo". Empty matches all repositories.
+	Repository string
+	// CertFile is the path to the PEM encoded certificate.
+	CertFile string
+	// KeyFile is the path to the PEM encoded private key.
+	KeyFile string
+	// CertEnv is the environment variable holding the PEM encoded
+	// certificate, used if CertFile is not set.
+	CertEnv string
+	// KeyEnv is the environment variable holding the PEM encoded private key,
+	// used if KeyFile is not set.
+	KeyEnv string
+}
+
+// matches reports whether c is presented for repository.
+func (c ClientCertificate) matches(repository string) bool {
+	prefix := strings.Trim(c.Repository, "/")
+	return prefix == "" || repository == prefix || strings.HasPrefix(repository, prefix+"/")
+}
+
+// load loads the certificate and key of c.
+func (c ClientCertificate) load() (tls.Certificate, error) {
+	certPEM, err := readPEM(c.CertFile, c.CertEnv)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%w for %q: reading certificate: %w", ErrInvalidClientCertificate, c.Repository, err)
+	}
+	keyPEM, err := readPEM(c.KeyFile, c.KeyEnv)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%w for %q: reading key: %w", ErrInvalidClientCertificate, c.Repository, err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%w for %q: %w", ErrInvalidClientCertificate, c.Repository, err)
+	}
+	return cert, nil
+}
+
+// readPEM reads the PEM data of file, or otherwise of the environment
+// variable env.
+func readPEM(file, env string) ([]byte, error) {
+	switch {
+	case file != "":
+		return os.ReadFile(file)
+	case env == "":
+		return nil, errors.New("neither file nor environment variable configured")
+	}
+	data, ok := os.LookupEnv(env)
+	if !ok || data == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", env)
+	}
+	return []byte(data), nil
+}
+
+// clientCertificate returns the client certificate presented for repository,
+// that of the longest matching prefix, if any. Clients are thereby keyed by
+// the registry and repository prefix, the identity presented.
+func (t TLS) clientCertificate(repository string) (ClientCertificate, bool) {
+	var (
+		selected ClientCertificate
+		found    bool
+	)
+	for _, c := range t.ClientCertificates {
+		if !c.matches(repository) {
+			continue
+		}
+		if !found || len(strings.Trim(c.Repository, "/")) > len(strings.Trim(selected.Repository, "/")) {
+			selected, found = c, true
+		}
+	}
+	return selected, found
+}
+
+// config returns the TLS configuration of connections to repository of
+// hostName, repository may be empty for registry-wide requests.
+func (t TLS) config(hostName, repository string) (*tls.Config, error) {
+	certDir := t.CertDir
+	if certDir == "" {
+		var err error
+		certDir, err = resolveTLSCertLocation(getStandardCertLocations(hostName))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := fetchCertsFromLocation(certDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if c, ok := t.clientCertificate(repository); ok {
+		cert, err := c.load()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	switch t.MinVersion {
+	case "", "1.2":
+		cfg.MinVersion = tls.VersionTLS12
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedTLSVersion, t.MinVersion)
+	}
+	cfg.InsecureSkipVerify = t.InsecureSkipVerify //nolint:gosec // opt-in, e.g. for registries with self-signed certificates
+
+	return cfg, nil
+}
@@ -0,0 +1,131 @@
+package ociutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newClientCertificate returns a PEM encoded self-signed client certificate
+// and key with the common name cn.
+func newClientCertificate(t *testing.T, cn string) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestTLS_clientCertificate(t *testing.T) {
+	tlsOpts := TLS{
+		ClientCertificates: []ClientCertificate{
+			{CertFile: "default.pem"},
+			{Repository: "team-a", CertFile: "team-a.pem"},
+			{Repository: "team-a/special/", CertFile: "special.pem"},
+		},
+	}
+
+	tests := []struct {
+		repository string
+		want       string
+	}{
+		{repository: "team-a", want: "team-a.pem"},
+		{repository: "team-a/repo", want: "team-a.pem"},
+		{repository: "team-a/special/repo", want: "special.pem"},
+		{repository: "team-ab/repo", want: "default.pem"},
+		{repository: "", want: "default.pem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			got, ok := tlsOpts.clientCertificate(tt.repository)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got.CertFile)
+		})
+	}
+
+	t.Run("No Match", func(t *testing.T) {
+		_, ok := TLS{ClientCertificates: []ClientCertificate{{Repository: "team-a"}}}.clientCertificate("team-b/repo")
+		assert.False(t, ok)
+	})
+}
+
+func Test_newHTTPClientWithOps_clientCertificates(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	certA, keyA := newClientCertificate(t, "team-a")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "team-a.crt"), certA, 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "team-a.key"), keyA, 0o600))
+	certB, keyB := newClientCertificate(t, "team-b")
+	t.Setenv("TEAM_B_CERT", string(certB))
+	t.Setenv("TEAM_B_KEY", string(keyB))
+
+	tlsOpts := TLS{
+		InsecureSkipVerify: true,
+		ClientCertificates: []ClientCertificate{
+			{Repository: "team-a", CertFile: filepath.Join(dir, "team-a.crt"), KeyFile: filepath.Join(dir, "team-a.key")},
+			{Repository: "team-b", CertEnv: "TEAM_B_CERT", KeyEnv: "TEAM_B_KEY"},
+		},
+	}
+
+	for _, tt := range []struct{ repository, want string }{
+		{repository: "team-a/repo", want: "team-a"},
+		{repository: "team-b/repo", want: "team-b"},
+	} {
+		t.Run(tt.repository, func(t *testing.T) {
+			c, err := newHTTPClientWithOps("registry.example.com", tt.repository, tlsOpts, RetryPolicy{MaxAttempts: 1}, Proxy{}, "")
+			assert.NoError(t, err)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL, nil)
+			assert.NoError(t, err)
+			resp, err := c.Do(req)
+			if assert.NoError(t, err) {
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, resp.Body.Close())
+				assert.Equal(t, tt.want, string(body))
+			}
+		})
+	}
+
+	t.Run("Unset Environment Variable", func(t *testing.T) {
+		opts := TLS{ClientCertificates: []ClientCertificate{{CertEnv: "GNOCI_TEST_UNSET_CERT", KeyEnv: "GNOCI_TEST_UNSET_KEY"}}}
+		_, err := newHTTPClientWithOps("registry.example.com", "repo", opts, RetryPolicy{}, Proxy{}, "")
+		assert.ErrorIs(t, err, ErrInvalidClientCertificate)
+	})
+}
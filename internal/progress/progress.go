@@ -3,7 +3,10 @@ package progress
 import (
 	"context"
 	"io"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 //go:generate go tool mockgen -typed -package progressmock -destination ./progressmock/progressmock.gen.go github.com/act3-ai/gnoci/internal/progress Evaluator
@@ -32,32 +35,99 @@ type Progress struct {
 	Delta int
 }
 
-// Ticker holds a channel that delivers "ticks" of [Progress] at intervals.
-type Ticker struct {
-	C <-chan Progress
+// Reporter reports the combined [Progress] of its [Evaluator]s on an interval,
+// until stopped. Reporting is done by a single goroutine, which always exits
+// once [Reporter.Stop] returns, regardless of whether anything was tracked or
+// read to completion.
+type Reporter struct {
+	report func(Progress) error
+	cancel context.CancelFunc
+	group  *errgroup.Group
+
+	mu    sync.Mutex
+	evals []Evaluator
+	total int
+
+	stopOnce sync.Once
+	err      error
 }
 
-// NewTicker returns a [Ticker] reporting an [Evaluator]'s [Progress] on an interval.
-func NewTicker(ctx context.Context, eval Evaluator, d time.Duration, ch chan<- Progress) {
-	t := time.NewTicker(d)
-	go func() {
-		defer close(ch)
+// NewReporter starts reporting progress to report every interval, until ctx
+// is done or [Reporter.Stop] is called. Reporting stops at the first error
+// returned by report, which is returned by [Reporter.Stop]. Intervals without
+// progress are not reported.
+func NewReporter(ctx context.Context, interval time.Duration, report func(Progress) error) *Reporter {
+	ctx, cancel := context.WithCancel(ctx)
+	group, ctx := errgroup.WithContext(ctx)
+	r := &Reporter{
+		report: report,
+		cancel: cancel,
+		group:  group,
+	}
+
+	group.Go(func() error {
+		t := time.NewTicker(interval)
+		defer t.Stop()
 		for {
 			select {
 			case <-ctx.Done():
-				return
+				return nil
 			case <-t.C:
-				total, delta, err := eval.Progress()
-				p := Progress{
-					Total: total,
-					Delta: delta,
-				}
-
-				ch <- p
-				if err != nil { // io.EOF, or other issues
-					return
+				if err := r.tick(); err != nil {
+					return err
 				}
 			}
 		}
-	}()
+	})
+
+	return r
+}
+
+// Track returns rc, reporting the bytes read from it.
+func (r *Reporter) Track(rc io.ReadCloser) io.ReadCloser {
+	erc := NewEvalReadCloser(rc)
+	r.Add(erc)
+	return erc
+}
+
+// Add reports the progress of eval, in addition to that already tracked.
+func (r *Reporter) Add(eval Evaluator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.evals = append(r.evals, eval)
+}
+
+// Stop stops reporting, waits for the reporting goroutine to exit, and
+// reports any progress made since the last interval. Returns the first error
+// reporting progress. Stop may be called more than once, e.g. deferred as well
+// as called on success, returning the same error.
+func (r *Reporter) Stop() error {
+	r.stopOnce.Do(func() {
+		r.cancel()
+		r.err = r.group.Wait()
+		if r.err == nil {
+			r.err = r.tick()
+		}
+	})
+	return r.err
+}
+
+// tick reports the progress made since the last tick, if any.
+func (r *Reporter) tick() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var delta int
+	for _, eval := range r.evals {
+		// read errors are surfaced by the reader itself
+		_, d, _ := eval.Progress()
+		delta += d
+	}
+	if delta == 0 {
+		return nil
+	}
+
+	r.total += delta
+	return r.report(Progress{Total: r.total, Delta: delta})
 }
@@ -1,8 +1,9 @@
 package progress
 
 import (
-	"context"
 	"errors"
+	"io"
+	"strings"
 	"testing"
 	"testing/synctest"
 	"time"
@@ -12,74 +13,82 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
-func TestNewTicker(t *testing.T) {
+func TestReporter(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		synctest.Test(t, func(t *testing.T) {
 			t.Helper()
 
 			ctrl := gomock.NewController(t)
 			evaluatorMock := progressmock.NewMockEvaluator(ctrl)
+			gomock.InOrder(
+				evaluatorMock.EXPECT().Progress().Return(5, 5, nil),
+				evaluatorMock.EXPECT().Progress().Return(10, 5, nil),
+				evaluatorMock.EXPECT().Progress().Return(10, 0, io.EOF).AnyTimes(),
+			)
 
-			expectedTotal := 10
-			expectedDelta := 5
-			evaluatorMock.EXPECT().
-				Progress().
-				Return(expectedTotal, expectedDelta, nil).
-				MinTimes(1)
+			var got []Progress
+			r := NewReporter(t.Context(), time.Second, func(p Progress) error {
+				got = append(got, p)
+				return nil
+			})
+			r.Add(evaluatorMock)
 
-			ch := make(chan Progress)
-			done := make(chan struct{})
-			go func() {
-				for p := range ch {
-					assert.Equal(t, expectedTotal, p.Total)
-					assert.Equal(t, expectedDelta, p.Delta)
-				}
-				close(done)
-			}()
+			time.Sleep(3 * time.Second)
+			synctest.Wait()
+			assert.NoError(t, r.Stop())
+			assert.Equal(t, []Progress{{Total: 5, Delta: 5}, {Total: 10, Delta: 5}}, got)
+		})
+	})
 
-			ctx, cancel := context.WithCancel(t.Context())
-			NewTicker(ctx, evaluatorMock, time.Nanosecond, ch)
+	t.Run("Final Progress", func(t *testing.T) {
+		var got []Progress
+		r := NewReporter(t.Context(), time.Hour, func(p Progress) error {
+			got = append(got, p)
+			return nil
+		})
+		rc := r.Track(io.NopCloser(strings.NewReader("foo")))
+		_, err := io.ReadAll(rc)
+		assert.NoError(t, err)
 
-			time.Sleep(time.Nanosecond * 5)
-			synctest.Wait()
-			cancel()
-			<-done
+		// reported on stop, rather than the next interval
+		assert.NoError(t, r.Stop())
+		assert.Equal(t, []Progress{{Total: 3, Delta: 3}}, got)
+	})
+
+	t.Run("Nothing Tracked", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			t.Helper()
+
+			r := NewReporter(t.Context(), time.Second, func(p Progress) error {
+				t.Errorf("unexpected progress %v", p)
+				return nil
+			})
+			time.Sleep(3 * time.Second)
+
+			// the reporting goroutine exits, or synctest fails the test
+			assert.NoError(t, r.Stop())
 		})
 	})
 
-	t.Run("Error", func(t *testing.T) {
+	t.Run("Report Error", func(t *testing.T) {
 		synctest.Test(t, func(t *testing.T) {
 			t.Helper()
 
 			ctrl := gomock.NewController(t)
 			evaluatorMock := progressmock.NewMockEvaluator(ctrl)
+			evaluatorMock.EXPECT().Progress().Return(5, 5, nil).Times(1)
 
-			expectedTotal := 10
-			expectedDelta := 5
-			expectedErr := errors.New("progress error")
-			evaluatorMock.EXPECT().
-				Progress().
-				Return(expectedTotal, expectedDelta, expectedErr).
-				MinTimes(1)
-
-			ch := make(chan Progress)
-			done := make(chan struct{})
-			go func() {
-				for p := range ch {
-					// ensure progress messages are sent, despite errors
-					assert.Equal(t, expectedTotal, p.Total)
-					assert.Equal(t, expectedDelta, p.Delta)
-				}
-				close(done)
-			}()
-
-			ctx, cancel := context.WithCancel(t.Context())
-			NewTicker(ctx, evaluatorMock, time.Nanosecond, ch)
+			expectedErr := errors.New("report error")
+			r := NewReporter(t.Context(), time.Second, func(p Progress) error {
+				return expectedErr
+			})
+			r.Add(evaluatorMock)
 
-			time.Sleep(time.Nanosecond * 5)
+			// reporting stops at the first error
+			time.Sleep(3 * time.Second)
 			synctest.Wait()
-			cancel()
-			<-done
+			assert.ErrorIs(t, r.Stop(), expectedErr)
+			assert.ErrorIs(t, r.Stop(), expectedErr)
 		})
 	})
 }
@@ -8,12 +8,98 @@ import (
 	"log/slog"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/opencontainers/go-digest"
 
 	"github.com/act3-ai/gnoci/internal/git"
 	"github.com/act3-ai/gnoci/internal/model"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
 )
 
+// ErrStaleLease indicates a remote reference does not point to the commit
+// expected by push --force-with-lease, e.g. it was updated by another client.
+// The message is recognized by Git, reported as a rejected "stale info" update.
+var ErrStaleLease = errors.New("stale info")
+
+// Rejections of updates that are not a fast forward. The messages are
+// recognized by Git, which reports the update as rejected with its own advice.
+var (
+	// ErrNonFastForward indicates the remote commit is in the local
+	// repository, but is not an ancestor of the local commit.
+	ErrNonFastForward = errors.New("non-fast forward")
+	// ErrFetchFirst indicates the remote commit is not in the local
+	// repository, so must be fetched before it may be integrated.
+	ErrFetchFirst = errors.New("fetch first")
+)
+
+// DivergedError indicates the update of a remote reference is not a fast
+// forward of the local reference, i.e. the remote has commits the local
+// reference does not.
+type DivergedError struct {
+	// Local is the local reference.
+	Local *plumbing.Reference
+	// Remote is the remote reference.
+	Remote *plumbing.Reference
+	// Ahead is the number of local commits not in the remote history, -1 if
+	// unknown.
+	Ahead int
+	// Behind is the number of remote commits not in the local history, -1 if
+	// unknown.
+	Behind int
+	// RemoteMissing indicates the remote commit is not in the local
+	// repository.
+	RemoteMissing bool
+}
+
+func (e *DivergedError) Error() string {
+	msg := fmt.Sprintf("remote reference %s update is not a fast forward of local ref %s", e.Remote.Name(), e.Local.Name())
+	switch {
+	case e.Ahead >= 0 && e.Behind >= 0:
+		return fmt.Sprintf("%s: local is %d ahead and %d behind", msg, e.Ahead, e.Behind)
+	case e.RemoteMissing:
+		return fmt.Sprintf("%s: remote commit %s is not in the local repository", msg, e.Remote.Hash())
+	default:
+		return msg
+	}
+}
+
+// Unwrap returns the rejection recognized by Git, [ErrFetchFirst] if the
+// remote commit is not in the local repository, otherwise
+// [ErrNonFastForward].
+func (e *DivergedError) Unwrap() error {
+	if e.RemoteMissing {
+		return ErrFetchFirst
+	}
+	return ErrNonFastForward
+}
+
+// Advice describes how the user may resolve the rejection, one sentence per
+// line.
+func (e *DivergedError) Advice() []string {
+	remote, local := e.Remote.Name().Short(), e.Local.Name().Short()
+	var advice []string
+	switch {
+	case e.Ahead >= 0 && e.Behind >= 0:
+		advice = append(advice, fmt.Sprintf("The remote %s has %s not in your local %s, which has %s not in the remote.", remote, commits(e.Behind), local, commits(e.Ahead)))
+	case e.RemoteMissing:
+		advice = append(advice, fmt.Sprintf("The remote %s has commits not in your local repository.", remote))
+	default:
+		advice = append(advice, fmt.Sprintf("The remote %s has commits not in your local %s.", remote, local))
+	}
+	return append(advice,
+		"Integrate the remote changes, e.g. 'git pull', before pushing again.",
+		"Or overwrite them with 'git push --force-with-lease', if they may be discarded.",
+	)
+}
+
+// commits formats a number of commits.
+func commits(n int) string {
+	if n == 1 {
+		return "1 commit"
+	}
+	return fmt.Sprintf("%d commits", n)
+}
+
 // Status represents the result of a reference comparison.
 type Status uint8
 
@@ -30,8 +116,9 @@ const (
 
 // RefComparer provides utilities for comparing local and remote references.
 type RefComparer interface {
-	// Compare resolves the status of a remote reference.
-	Compare(ctx context.Context, force bool, localName plumbing.ReferenceName, remoteName plumbing.ReferenceName) (RefPair, error)
+	// Compare resolves the status of the remote reference of a push request,
+	// failing with [ErrStaleLease] if the request's lease does not hold.
+	Compare(ctx context.Context, req gittypes.PushRequest) (RefPair, error)
 	// GetStatus returns the status of a remote reference.
 	// GetStatus(remoteName plumbing.ReferenceName) (status, bool)
 }
@@ -65,8 +152,9 @@ func NewCachedRefComparer(local git.Repository, remote model.Modeler) RefCompare
 	}
 }
 
-// Compare compares a local and remote refence by name.
-func (rc *refCompareCached) Compare(ctx context.Context, force bool, localName, remoteName plumbing.ReferenceName) (RefPair, error) {
+// Compare compares the local and remote references of a push request by name.
+func (rc *refCompareCached) Compare(ctx context.Context, req gittypes.PushRequest) (RefPair, error) {
+	localName, remoteName := req.Src, req.Remote
 	rp, ok := rc.refs[remoteName]
 	if ok {
 		return rp, nil
@@ -93,7 +181,11 @@ func (rc *refCompareCached) Compare(ctx context.Context, force bool, localName,
 		slog.InfoContext(ctx, "resolved remote reference", "ref", remoteName.String(), "hash", remoteRef.Hash().String())
 	}
 
-	rp, err = rc.compare(force, localRef, remoteRef)
+	if req.Lease && remoteRef.Hash() != req.Expected {
+		return RefPair{}, fmt.Errorf("%w: remote reference %s is at %s, expected %s", ErrStaleLease, remoteName, remoteRef.Hash(), req.Expected)
+	}
+
+	rp, err = rc.compare(ctx, req.Force, localRef, remoteRef)
 	if err != nil {
 		return RefPair{}, fmt.Errorf("comparing local and remote refs: %w", err)
 	}
@@ -102,7 +194,7 @@ func (rc *refCompareCached) Compare(ctx context.Context, force bool, localName,
 	return rp, nil
 }
 
-func (rc *refCompareCached) compare(force bool, localRef, remoteRef *plumbing.Reference) (RefPair, error) {
+func (rc *refCompareCached) compare(ctx context.Context, force bool, localRef, remoteRef *plumbing.Reference) (RefPair, error) {
 	rp := RefPair{
 		Local:  localRef,
 		Remote: remoteRef,
@@ -124,7 +216,7 @@ func (rc *refCompareCached) compare(force bool, localRef, remoteRef *plumbing.Re
 		return RefPair{}, fmt.Errorf("resolving commit object %s from hash for local ref %s: %w", localRef.Hash().String(), localRef.Name().String(), err)
 	}
 
-	layer, err := rc.remote.CommitExists(rc.local, localCommit)
+	layer, err := rc.remote.CommitExists(ctx, rc.local, localCommit)
 	if err != nil {
 		return RefPair{}, fmt.Errorf("resolving existence of commit %s in remote: %w", localCommit, err)
 	}
@@ -142,21 +234,108 @@ func (rc *refCompareCached) compare(force bool, localRef, remoteRef *plumbing.Re
 		return rp, nil
 	}
 
-	remoteCommit, err := rc.local.CommitObject(remoteRef.Hash())
-	if err != nil {
-		return RefPair{}, fmt.Errorf("resolving commit object from hash for remote ref: %w", err)
-	}
-
-	isAncestor, err := remoteCommit.IsAncestor(localCommit)
-	if err != nil {
-		return RefPair{}, fmt.Errorf("resolving remote commit ancestor status of local: %w", err)
+	isAncestor, remoteCommit, err := rc.isAncestor(ctx, remoteRef.Hash(), localCommit)
+	switch {
+	case errors.Is(err, plumbing.ErrObjectNotFound) && force:
+		// overwriting history unknown to the local repository
+		return rp, nil
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		return RefPair{}, &DivergedError{Local: localRef, Remote: remoteRef, Ahead: -1, Behind: -1, RemoteMissing: true}
+	case err != nil:
+		return RefPair{}, err
 	}
 	if isAncestor {
 		rp.Status |= StatusUpdateRef
 	} else if !force {
 		// commit histories have diverged, and we're not overwriting the remote
-		return RefPair{}, fmt.Errorf("remote reference %s update is not a fast forward of local ref %s", remoteRef.Name().String(), localRef.Name().String())
+		return RefPair{}, rc.diverged(ctx, localRef, remoteRef, localCommit, remoteCommit)
 	}
 
 	return rp, nil
 }
+
+// diverged describes the divergence of the local and remote histories. The
+// remote commit is resolved from the local repository if nil.
+func (rc *refCompareCached) diverged(ctx context.Context, localRef, remoteRef *plumbing.Reference, localCommit, remoteCommit *object.Commit) *DivergedError {
+	derr := &DivergedError{Local: localRef, Remote: remoteRef, Ahead: -1, Behind: -1}
+	if remoteCommit == nil {
+		var err error
+		remoteCommit, err = rc.local.CommitObject(remoteRef.Hash())
+		if err != nil {
+			derr.RemoteMissing = errors.Is(err, plumbing.ErrObjectNotFound)
+			return derr
+		}
+	}
+
+	ahead, behind, err := countDivergence(localCommit, remoteCommit)
+	if err != nil {
+		// e.g. a shallow local history, the rejection stands without counts
+		slog.DebugContext(ctx, "counting diverged commits", slog.String("error", err.Error()))
+		return derr
+	}
+	derr.Ahead, derr.Behind = ahead, behind
+	return derr
+}
+
+// countDivergence returns the number of commits reachable from local but not
+// remote, and from remote but not local.
+func countDivergence(local, remote *object.Commit) (ahead, behind int, err error) {
+	localAncestors, err := ancestors(local)
+	if err != nil {
+		return 0, 0, err
+	}
+	remoteAncestors, err := ancestors(remote)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for h := range localAncestors {
+		if _, ok := remoteAncestors[h]; !ok {
+			ahead++
+		}
+	}
+	for h := range remoteAncestors {
+		if _, ok := localAncestors[h]; !ok {
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// ancestors returns the commits reachable from c, including c.
+func ancestors(c *object.Commit) (map[plumbing.Hash]struct{}, error) {
+	seen := make(map[plumbing.Hash]struct{})
+	err := object.NewCommitPreorderIter(c, nil, nil).ForEach(func(c *object.Commit) error {
+		seen[c.Hash] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking history of %s: %w", c.Hash, err)
+	}
+	return seen, nil
+}
+
+// isAncestor reports whether the remote commit is an ancestor of the local
+// commit, per the remote's commit-graph if it can decide, otherwise by walking
+// the local history. The remote commit is returned if resolved from the local
+// repository.
+func (rc *refCompareCached) isAncestor(ctx context.Context, remoteHash plumbing.Hash, localCommit *object.Commit) (bool, *object.Commit, error) {
+	isAncestor, err := rc.remote.IsAncestor(ctx, remoteHash, localCommit.Hash)
+	switch {
+	case err == nil:
+		return isAncestor, nil, nil
+	case !errors.Is(err, model.ErrCommitNotFound):
+		return false, nil, fmt.Errorf("resolving remote commit ancestor status of local from commit-graph: %w", err)
+	}
+
+	remoteCommit, err := rc.local.CommitObject(remoteHash)
+	if err != nil {
+		return false, nil, fmt.Errorf("resolving commit object from hash for remote ref: %w", err)
+	}
+
+	isAncestor, err = remoteCommit.IsAncestor(localCommit)
+	if err != nil {
+		return false, nil, fmt.Errorf("resolving remote commit ancestor status of local: %w", err)
+	}
+	return isAncestor, remoteCommit, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/act3-ai/gnoci/internal/mocks/modelmock"
 	"github.com/act3-ai/gnoci/internal/model"
 	"github.com/act3-ai/gnoci/internal/testutils"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/opencontainers/go-digest"
@@ -67,7 +68,7 @@ func Test_refCompareCached_Compare(t *testing.T) {
 			},
 		}
 
-		rp, err := rc.Compare(t.Context(), false, localBranchRefName, remoteBranchRefName)
+		rp, err := rc.Compare(t.Context(), gittypes.PushRequest{Cmd: gittypes.Push, Src: localBranchRefName, Remote: remoteBranchRefName})
 		assert.Nil(t, err)
 		assert.Equal(t, expectedRefPair, rp)
 	})
@@ -88,7 +89,84 @@ func Test_refCompareCached_Compare(t *testing.T) {
 		force      bool
 		localName  plumbing.ReferenceName
 		remoteName plumbing.ReferenceName
+		lease      bool
+		expected   plumbing.Hash
 	}{
+		{name: "Stale Lease",
+			setupFn: func(t *testing.T,
+				repoBuilder *testutils.RepoBuilder,
+				repoMock *gitmock.MockRepository,
+				modelMock *modelmock.MockModeler) wantFunc {
+				t.Helper()
+
+				localHash, err := repoBuilder.CreateRandomCommit(10)
+				assert.NoError(t, err)
+				_, err = repoBuilder.CreateBranch(localBranchName, localHash)
+				assert.NoError(t, err)
+
+				localRef, err := repoBuilder.Repo().Reference(localBranchRefName, true)
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					Reference(localBranchRefName, true).
+					Return(localRef, nil)
+
+				// updated by another client since the lease was taken
+				remoteRef := plumbing.NewHashReference(
+					remoteBranchRefName,
+					plumbing.ComputeHash(plumbing.CommitObject, []byte("concurrent")))
+
+				modelMock.EXPECT().
+					ResolveRef(gomock.Any(), remoteBranchRefName).
+					Return(remoteRef, digest.FromString("foo"), nil)
+
+				return func(t *testing.T, refPair RefPair, err error) {
+					t.Helper()
+
+					assert.ErrorIs(t, err, ErrStaleLease)
+				}
+			},
+			force:      true,
+			localName:  localBranchRefName,
+			remoteName: remoteBranchRefName,
+			lease:      true,
+			expected:   plumbing.ComputeHash(plumbing.CommitObject, []byte("leased")),
+		},
+		{name: "Stale Lease Remote Created",
+			setupFn: func(t *testing.T,
+				repoBuilder *testutils.RepoBuilder,
+				repoMock *gitmock.MockRepository,
+				modelMock *modelmock.MockModeler) wantFunc {
+				t.Helper()
+
+				localHash, err := repoBuilder.CreateRandomCommit(10)
+				assert.NoError(t, err)
+				_, err = repoBuilder.CreateBranch(localBranchName, localHash)
+				assert.NoError(t, err)
+
+				localRef, err := repoBuilder.Repo().Reference(localBranchRefName, true)
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					Reference(localBranchRefName, true).
+					Return(localRef, nil)
+
+				modelMock.EXPECT().
+					ResolveRef(gomock.Any(), remoteBranchRefName).
+					Return(plumbing.NewHashReference(remoteBranchRefName, localHash), digest.FromString("foo"), nil)
+
+				return func(t *testing.T, refPair RefPair, err error) {
+					t.Helper()
+
+					assert.ErrorIs(t, err, ErrStaleLease)
+				}
+			},
+			force:      true,
+			localName:  localBranchRefName,
+			remoteName: remoteBranchRefName,
+			lease:      true,
+			expected:   plumbing.ZeroHash,
+		},
 		{name: "Remote DNE",
 			setupFn: func(t *testing.T,
 				repoBuilder *testutils.RepoBuilder,
@@ -122,7 +200,7 @@ func Test_refCompareCached_Compare(t *testing.T) {
 					Return(localCommitObj, nil)
 
 				modelMock.EXPECT().
-					CommitExists(repoMock, localCommitObj).
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
 					Return("", nil)
 
 				return func(t *testing.T, refPair RefPair, err error) {
@@ -177,9 +255,13 @@ func Test_refCompareCached_Compare(t *testing.T) {
 					Return(localCommitObj, nil)
 
 				modelMock.EXPECT().
-					CommitExists(repoMock, localCommitObj).
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
 					Return("", nil)
 
+				modelMock.EXPECT().
+					IsAncestor(gomock.Any(), remoteHash, localRef.Hash()).
+					Return(false, model.ErrCommitNotFound)
+
 				repoMock.EXPECT().
 					CommitObject(remoteHash).
 					DoAndReturn(func(h plumbing.Hash) (*object.Commit, error) {
@@ -199,6 +281,63 @@ func Test_refCompareCached_Compare(t *testing.T) {
 			localName:  localBranchRefName,
 			remoteName: remoteBranchRefName,
 		},
+		{name: "Remote Is Ancestor Per Commit-Graph",
+			setupFn: func(t *testing.T,
+				repoBuilder *testutils.RepoBuilder,
+				repoMock *gitmock.MockRepository,
+				modelMock *modelmock.MockModeler) wantFunc {
+				t.Helper()
+
+				remoteHash, err := repoBuilder.CreateRandomCommit(10)
+				assert.NoError(t, err)
+				localHash, err := repoBuilder.CreateRandomCommit(10)
+				assert.NoError(t, err)
+				_, err = repoBuilder.CreateBranch(localBranchName, localHash)
+				assert.NoError(t, err)
+
+				localRef, err := repoBuilder.Repo().Reference(localBranchRefName, true)
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					Reference(localBranchRefName, true).
+					Return(localRef, nil)
+
+				remoteRef := plumbing.NewHashReference(
+					remoteBranchRefName,
+					remoteHash)
+
+				modelMock.EXPECT().
+					ResolveRef(gomock.Any(), remoteBranchRefName).
+					Return(remoteRef, digest.FromString("foo"), nil)
+
+				localCommitObj, err := repoBuilder.Repo().CommitObject(localRef.Hash())
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					CommitObject(localRef.Hash()).
+					Return(localCommitObj, nil)
+
+				modelMock.EXPECT().
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
+					Return("", nil)
+
+				modelMock.EXPECT().
+					IsAncestor(gomock.Any(), remoteHash, localRef.Hash()).
+					Return(true, nil)
+
+				return func(t *testing.T, refPair RefPair, err error) {
+					t.Helper()
+
+					assert.NoError(t, err)
+					assert.Equal(t, localRef, refPair.Local)
+					assert.Equal(t, remoteRef, refPair.Remote)
+					assert.Equal(t, StatusAddCommit|StatusUpdateRef, refPair.Status)
+				}
+			},
+			force:      false,
+			localName:  localBranchRefName,
+			remoteName: remoteBranchRefName,
+		},
 		{name: "Remote Is Not Ancestor",
 			setupFn: func(t *testing.T,
 				repoBuilder *testutils.RepoBuilder,
@@ -237,9 +376,13 @@ func Test_refCompareCached_Compare(t *testing.T) {
 					Return(localCommitObj, nil)
 
 				modelMock.EXPECT().
-					CommitExists(repoMock, localCommitObj).
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
 					Return("", nil)
 
+				modelMock.EXPECT().
+					IsAncestor(gomock.Any(), remoteRef.Hash(), localRef.Hash()).
+					Return(false, model.ErrCommitNotFound)
+
 				repoMock.EXPECT().
 					CommitObject(remoteRef.Hash()).
 					DoAndReturn(func(h plumbing.Hash) (*object.Commit, error) {
@@ -251,8 +394,71 @@ func Test_refCompareCached_Compare(t *testing.T) {
 
 					assert.Nil(t, refPair.Local)
 					assert.Nil(t, refPair.Remote)
-					assert.Error(t, err)
+					assert.ErrorIs(t, err, ErrNonFastForward)
 					assert.Equal(t, Status(0), refPair.Status)
+
+					var derr *DivergedError
+					assert.ErrorAs(t, err, &derr)
+					assert.Equal(t, 0, derr.Ahead)
+					assert.Equal(t, 1, derr.Behind)
+				}
+			},
+			force:      false,
+			localName:  localBranchRefName,
+			remoteName: remoteBranchRefName,
+		},
+		{name: "Remote Unknown",
+			setupFn: func(t *testing.T,
+				repoBuilder *testutils.RepoBuilder,
+				repoMock *gitmock.MockRepository,
+				modelMock *modelmock.MockModeler) wantFunc {
+				t.Helper()
+
+				localHash, err := repoBuilder.CreateRandomCommit(10)
+				assert.NoError(t, err)
+				_, err = repoBuilder.CreateBranch(localBranchName, localHash)
+				assert.NoError(t, err)
+
+				localRef, err := repoBuilder.Repo().Reference(localBranchRefName, true)
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					Reference(localBranchRefName, true).
+					Return(localRef, nil)
+
+				// the remote commit does not exist locally
+				remoteRef := plumbing.NewHashReference(
+					remoteBranchRefName,
+					plumbing.NewHash("1111111111111111111111111111111111111111"))
+
+				modelMock.EXPECT().
+					ResolveRef(gomock.Any(), remoteBranchRefName).
+					Return(remoteRef, digest.FromString("foo"), nil)
+
+				localCommitObj, err := repoBuilder.Repo().CommitObject(localRef.Hash())
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					CommitObject(localRef.Hash()).
+					Return(localCommitObj, nil)
+
+				modelMock.EXPECT().
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
+					Return("", nil)
+
+				modelMock.EXPECT().
+					IsAncestor(gomock.Any(), remoteRef.Hash(), localRef.Hash()).
+					Return(false, model.ErrCommitNotFound)
+
+				repoMock.EXPECT().
+					CommitObject(remoteRef.Hash()).
+					Return(nil, plumbing.ErrObjectNotFound)
+
+				return func(t *testing.T, refPair RefPair, err error) {
+					t.Helper()
+
+					assert.ErrorIs(t, err, ErrFetchFirst)
+					assert.Equal(t, RefPair{}, refPair)
 				}
 			},
 			force:      false,
@@ -297,9 +503,13 @@ func Test_refCompareCached_Compare(t *testing.T) {
 					Return(localCommitObj, nil)
 
 				modelMock.EXPECT().
-					CommitExists(repoMock, localCommitObj).
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
 					Return("", nil)
 
+				modelMock.EXPECT().
+					IsAncestor(gomock.Any(), remoteRef.Hash(), localRef.Hash()).
+					Return(false, model.ErrCommitNotFound)
+
 				repoMock.EXPECT().
 					CommitObject(remoteRef.Hash()).
 					DoAndReturn(func(h plumbing.Hash) (*object.Commit, error) {
@@ -319,6 +529,66 @@ func Test_refCompareCached_Compare(t *testing.T) {
 			localName:  localBranchRefName,
 			remoteName: remoteBranchRefName,
 		},
+		{name: "Remote Unknown but Force",
+			setupFn: func(t *testing.T,
+				repoBuilder *testutils.RepoBuilder,
+				repoMock *gitmock.MockRepository,
+				modelMock *modelmock.MockModeler) wantFunc {
+				t.Helper()
+
+				localHash, err := repoBuilder.CreateRandomCommit(10)
+				assert.NoError(t, err)
+				_, err = repoBuilder.CreateBranch(localBranchName, localHash)
+				assert.NoError(t, err)
+
+				localRef, err := repoBuilder.Repo().Reference(localBranchRefName, true)
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					Reference(localBranchRefName, true).
+					Return(localRef, nil)
+
+				// the remote commit does not exist locally
+				remoteRef := plumbing.NewHashReference(
+					remoteBranchRefName,
+					plumbing.NewHash("1111111111111111111111111111111111111111"))
+
+				modelMock.EXPECT().
+					ResolveRef(gomock.Any(), remoteBranchRefName).
+					Return(remoteRef, digest.FromString("foo"), nil)
+
+				localCommitObj, err := repoBuilder.Repo().CommitObject(localRef.Hash())
+				assert.NoError(t, err)
+
+				repoMock.EXPECT().
+					CommitObject(localRef.Hash()).
+					Return(localCommitObj, nil)
+
+				modelMock.EXPECT().
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
+					Return("", nil)
+
+				modelMock.EXPECT().
+					IsAncestor(gomock.Any(), remoteRef.Hash(), localRef.Hash()).
+					Return(false, model.ErrCommitNotFound)
+
+				repoMock.EXPECT().
+					CommitObject(remoteRef.Hash()).
+					Return(nil, plumbing.ErrObjectNotFound)
+
+				return func(t *testing.T, refPair RefPair, err error) {
+					t.Helper()
+
+					assert.NoError(t, err)
+					assert.Equal(t, localRef, refPair.Local)
+					assert.Equal(t, remoteRef, refPair.Remote)
+					assert.Equal(t, StatusForce|StatusUpdateRef|StatusAddCommit, refPair.Status)
+				}
+			},
+			force:      true,
+			localName:  localBranchRefName,
+			remoteName: remoteBranchRefName,
+		},
 		{name: "Remote Is Ancestor - Commit Exists",
 			setupFn: func(t *testing.T,
 				repoBuilder *testutils.RepoBuilder,
@@ -356,9 +626,13 @@ func Test_refCompareCached_Compare(t *testing.T) {
 					Return(localCommitObj, nil)
 
 				modelMock.EXPECT().
-					CommitExists(repoMock, localCommitObj).
+					CommitExists(gomock.Any(), repoMock, localCommitObj).
 					Return(digest.FromString("foo"), nil)
 
+				modelMock.EXPECT().
+					IsAncestor(gomock.Any(), remoteHash, localRef.Hash()).
+					Return(false, model.ErrCommitNotFound)
+
 				repoMock.EXPECT().
 					CommitObject(remoteHash).
 					DoAndReturn(func(h plumbing.Hash) (*object.Commit, error) {
@@ -499,8 +773,41 @@ func Test_refCompareCached_Compare(t *testing.T) {
 				refs:   map[plumbing.ReferenceName]RefPair{},
 			}
 
-			gotRefPair, err := rc.Compare(t.Context(), tt.force, tt.localName, tt.remoteName)
+			req := gittypes.PushRequest{
+				Cmd:      gittypes.Push,
+				Force:    tt.force,
+				Src:      tt.localName,
+				Remote:   tt.remoteName,
+				Lease:    tt.lease,
+				Expected: tt.expected,
+			}
+			gotRefPair, err := rc.Compare(t.Context(), req)
 			wantFn(t, gotRefPair, err)
 		})
 	}
 }
+
+func TestDivergedError(t *testing.T) {
+	local := plumbing.NewHashReference("refs/heads/feature", plumbing.ZeroHash)
+	remote := plumbing.NewHashReference("refs/heads/main", plumbing.NewHash("1111111111111111111111111111111111111111"))
+
+	t.Run("Counts", func(t *testing.T) {
+		err := &DivergedError{Local: local, Remote: remote, Ahead: 3, Behind: 1}
+		assert.ErrorIs(t, err, ErrNonFastForward)
+		assert.Equal(t, "remote reference refs/heads/main update is not a fast forward of local ref refs/heads/feature: local is 3 ahead and 1 behind", err.Error())
+		assert.Equal(t, "The remote main has 1 commit not in your local feature, which has 3 commits not in the remote.", err.Advice()[0])
+	})
+
+	t.Run("Remote Missing", func(t *testing.T) {
+		err := &DivergedError{Local: local, Remote: remote, Ahead: -1, Behind: -1, RemoteMissing: true}
+		assert.ErrorIs(t, err, ErrFetchFirst)
+		assert.Contains(t, err.Error(), "remote commit "+remote.Hash().String()+" is not in the local repository")
+		assert.Equal(t, "The remote main has commits not in your local repository.", err.Advice()[0])
+	})
+
+	t.Run("Unknown Counts", func(t *testing.T) {
+		err := &DivergedError{Local: local, Remote: remote, Ahead: -1, Behind: -1}
+		assert.ErrorIs(t, err, ErrNonFastForward)
+		assert.Equal(t, "The remote main has commits not in your local feature.", err.Advice()[0])
+	})
+}
@@ -0,0 +1,146 @@
+// Package scan provides scanners of packfiles for content which should not be
+// pushed, e.g. secrets.
+package scan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+)
+
+// maxObjectSize is the size above which objects are not scanned, as they are
+// rarely text.
+const maxObjectSize = 16 << 20
+
+// Finding is content of a packfile matching a scanner rule.
+type Finding struct {
+	// Rule is the name of the matched rule.
+	Rule string
+	// Type is the type of the packfile object, a delta if deltified.
+	Type plumbing.ObjectType
+	// Object is the hash of the packfile object, zero if deltified.
+	Object plumbing.Hash
+	// Offset is the offset of the object within the packfile.
+	Offset int64
+	// Line is the line of the match within the object, or within the
+	// instructions of a deltified object.
+	Line int
+}
+
+// String describes the location of f, never the matched content.
+func (f Finding) String() string {
+	if f.Object.IsZero() {
+		return fmt.Sprintf("%s in %s at packfile offset %d, line %d", f.Rule, f.Type, f.Offset, f.Line)
+	}
+	return fmt.Sprintf("%s in %s %s, line %d", f.Rule, f.Type, f.Object, f.Line)
+}
+
+// Scanner scans packfiles before they are pushed.
+type Scanner interface {
+	// ScanPack scans the objects of the packfile at packPath, returning its
+	// findings.
+	ScanPack(ctx context.Context, packPath string) ([]Finding, error)
+}
+
+// Rule is a named regular expression matched by a [RegexScanner].
+type Rule struct {
+	// Name identifies the rule in findings.
+	Name string
+	// Pattern matches the content found by the rule.
+	Pattern *regexp.Regexp
+}
+
+// DefaultRules match common credentials with distinctive formats, keeping
+// false positives rare.
+var DefaultRules = []Rule{
+	{Name: "aws-access-key-id", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z-]{10,}`)},
+	{Name: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+}
+
+// RegexScanner is a [Scanner] matching the content of each packfile object
+// against regular expressions. Deltified objects are matched by the literal
+// content of their delta instructions, such that content added by a delta is
+// found without resolving its base.
+type RegexScanner struct {
+	rules []Rule
+}
+
+// NewRegexScanner returns a [RegexScanner] matching [DefaultRules] and
+// additional rules, mapping names to regular expressions.
+func NewRegexScanner(rules map[string]string) (*RegexScanner, error) {
+	s := &RegexScanner{rules: slices.Clone(DefaultRules)}
+	for _, name := range slices.Sorted(maps.Keys(rules)) {
+		pattern, err := regexp.Compile(rules[name])
+		if err != nil {
+			return nil, fmt.Errorf("compiling rule %s: %w", name, err)
+		}
+		s.rules = append(s.rules, Rule{Name: name, Pattern: pattern})
+	}
+	return s, nil
+}
+
+func (s *RegexScanner) ScanPack(ctx context.Context, packPath string) ([]Finding, error) {
+	f, err := os.Open(packPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening packfile: %w", err)
+	}
+	defer f.Close()
+
+	scanner := packfile.NewScanner(f)
+	_, count, err := scanner.Header()
+	if err != nil {
+		return nil, fmt.Errorf("reading packfile header: %w", err)
+	}
+
+	var findings []Finding
+	buf := new(bytes.Buffer)
+	for range count {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("scanning packfile: %w", context.Cause(ctx))
+		}
+
+		hdr, err := scanner.NextObjectHeader()
+		if err != nil {
+			return nil, fmt.Errorf("reading packfile object header: %w", err)
+		}
+		if hdr.Length > maxObjectSize {
+			if _, _, err := scanner.NextObject(io.Discard); err != nil {
+				return nil, fmt.Errorf("reading packfile object at offset %d: %w", hdr.Offset, err)
+			}
+			continue
+		}
+		buf.Reset()
+		if _, _, err := scanner.NextObject(buf); err != nil {
+			return nil, fmt.Errorf("reading packfile object at offset %d: %w", hdr.Offset, err)
+		}
+
+		var obj plumbing.Hash
+		if !hdr.Type.IsDelta() {
+			obj = plumbing.ComputeHash(hdr.Type, buf.Bytes())
+		}
+		for _, rule := range s.rules {
+			for _, loc := range rule.Pattern.FindAllIndex(buf.Bytes(), -1) {
+				findings = append(findings, Finding{
+					Rule:   rule.Name,
+					Type:   hdr.Type,
+					Object: obj,
+					Offset: hdr.Offset,
+					Line:   bytes.Count(buf.Bytes()[:loc[0]], []byte("\n")) + 1,
+				})
+			}
+		}
+	}
+
+	return findings, nil
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+
+	gnocigit "github.com/act3-ai/gnoci/internal/git"
 )
 
 // RepoBuilder provides methods for building a git repository.
@@ -21,7 +23,7 @@ type RepoBuilder struct {
 // NewRepoBuilder initializes a RepoBuilder.
 func NewRepoBuilder(dir string) (*RepoBuilder, error) {
 	// will create if dir dne
-	repo, err := git.PlainInit(dir, false)
+	repo, err := gnocigit.PlainInit(dir, false)
 	if err != nil {
 		return nil, fmt.Errorf("initializing plain git repository: %w", err)
 	}
@@ -43,6 +43,9 @@ type ResponseReceiver interface {
 	ReceiveListResponse() error
 	// ReceivePushResponse receives a batch of responses to a batch of [git.PushRequest]s.
 	ReceivePushResponseBatch() error
+	// ReceiveConnectivityOK receives the connectivity-ok line preceding a
+	// response to a batch of [git.FetchRequest]s.
+	ReceiveConnectivityOK() error
 	// ReceiveFetchResponse receives a response to a batch of [git.FetchRequest]s.
 	ReceiveFetchResponse() error
 }
@@ -188,6 +191,8 @@ func (c *reverseCommunicator) ReceiveCapabilitiesResponse() error {
 			fetch = true
 		case git.CapabilityPush:
 			push = true
+		case git.CapabilityCheckConnectivity, git.CapabilityObjectFormat:
+			// optional
 		default:
 			return fmt.Errorf("unrecognized capability %s", line)
 		}
@@ -263,6 +268,21 @@ func (c *reverseCommunicator) ReceivePushResponseBatch() error {
 
 }
 
+// ReceiveConnectivityOK receives the connectivity-ok line preceding a
+// response to a batch of [git.FetchRequest]s.
+func (c *reverseCommunicator) ReceiveConnectivityOK() error {
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+
+	if line != git.ConnectivityOK {
+		return fmt.Errorf("unexpected connectivity response: %s", line)
+	}
+
+	return nil
+}
+
 // ReceiveFetchResponse receives a response to a batch of [git.FetchRequest]s.
 func (c *reverseCommunicator) ReceiveFetchResponse() error {
 	line, err := c.readLine()
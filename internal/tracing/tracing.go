@@ -0,0 +1,173 @@
+// Package tracing instruments the remote helpers with OpenTelemetry spans.
+//
+// Spans are always created, but only exported once [Setup] installs a tracer
+// provider, which it does if an OTLP endpoint is configured with the standard
+// OpenTelemetry environment variables.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"oras.land/oras-go/v2/registry"
+)
+
+// instrumentationName is the name of the tracer creating gnoci spans.
+const instrumentationName = "github.com/act3-ai/gnoci"
+
+// Environment variables enabling the export of spans, see
+// https://opentelemetry.io/docs/specs/otel/protocol/exporter/.
+const (
+	// EndpointEnv is the OTLP endpoint of all signals.
+	EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	// TracesEndpointEnv is the OTLP endpoint of traces, overriding [EndpointEnv].
+	TracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// shutdownTimeout bounds the time spent flushing spans on exit.
+const shutdownTimeout = 5 * time.Second
+
+// Span attribute keys.
+const (
+	// KeyRegistry is the host of the OCI registry.
+	KeyRegistry = attribute.Key("oci.registry")
+	// KeyRepository is the OCI repository within the registry.
+	KeyRepository = attribute.Key("oci.repository")
+	// KeyDigest is the digest of an OCI layer or manifest.
+	KeyDigest = attribute.Key("oci.digest")
+	// KeySize is the size of an OCI layer or manifest, in bytes.
+	KeySize = attribute.Key("oci.size")
+	// KeyMediaType is the media type of an OCI layer or manifest.
+	KeyMediaType = attribute.Key("oci.media_type")
+	// KeyMounted reports whether a layer was mounted rather than uploaded.
+	KeyMounted = attribute.Key("oci.mounted")
+	// KeyLayers is the number of packfile layers pushed or fetched.
+	KeyLayers = attribute.Key("gnoci.layers")
+	// KeyCommits is the number of commits indexed by a packfile layer.
+	KeyCommits = attribute.Key("gnoci.commits")
+	// KeyRequests is the number of requests in a batch sent by Git.
+	KeyRequests = attribute.Key("gnoci.requests")
+	// KeyAtomic reports whether a push is atomic.
+	KeyAtomic = attribute.Key("gnoci.atomic")
+)
+
+// Enabled reports whether an OTLP endpoint is configured in the environment.
+func Enabled() bool {
+	return os.Getenv(EndpointEnv) != "" || os.Getenv(TracesEndpointEnv) != ""
+}
+
+// Setup installs a global tracer provider exporting spans over OTLP/HTTP, if
+// [Enabled]. The exporter is otherwise configured by the standard
+// OTEL_EXPORTER_OTLP_* environment variables. The returned function flushes
+// pending spans and must be called before exiting.
+func Setup(ctx context.Context, service, version string) (func(context.Context) error, error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exp, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initializing OTLP trace exporter: %w", err)
+	}
+
+	// the environment, e.g. OTEL_SERVICE_NAME, takes precedence
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(service), semconv.ServiceVersion(version)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("initializing trace resource: %w", err), exp.Shutdown(ctx))
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		// spans are flushed even if the command was canceled
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		if err := tp.Shutdown(ctx); err != nil {
+			return fmt.Errorf("shutting down tracer provider: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// Start starts a span with attrs, a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error pointed to by errp, if any, and ends span. It is
+// intended to be deferred with a named error result.
+func End(span trace.Span, errp *error) {
+	if errp != nil && *errp != nil {
+		span.RecordError(*errp)
+		span.SetStatus(codes.Error, (*errp).Error())
+	}
+	span.End()
+}
+
+// Remote returns the attributes of an OCI repository.
+func Remote(ref registry.Reference) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		KeyRegistry.String(ref.Registry),
+		KeyRepository.String(ref.Repository),
+	}
+}
+
+// Layer returns the attributes of an OCI layer or manifest.
+func Layer(desc ocispec.Descriptor) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		KeyDigest.String(desc.Digest.String()),
+		KeySize.Int64(desc.Size),
+		KeyMediaType.String(desc.MediaType),
+	}
+}
+
+// Transport wraps base, creating a span for each HTTP request and
+// propagating the trace context to the registry.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
+
+// EndOnClose returns rc, ending span once it is closed, such that the span
+// covers reading a streamed blob.
+func EndOnClose(rc io.ReadCloser, span trace.Span) io.ReadCloser {
+	return &spanReadCloser{ReadCloser: rc, span: span}
+}
+
+// spanReadCloser ends a span once closed.
+type spanReadCloser struct {
+	io.ReadCloser
+	span trace.Span
+}
+
+// Close closes the underlying stream and ends the span, recording any error
+// closing it.
+func (s *spanReadCloser) Close() error {
+	err := s.ReadCloser.Close()
+	End(s.span, &err)
+	return err //nolint:wrapcheck
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"oras.land/oras-go/v2/registry"
+)
+
+// recordSpans installs a tracer provider recording ended spans, restoring the
+// global provider once t completes.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv(EndpointEnv, "")
+		t.Setenv(TracesEndpointEnv, "")
+		prev := otel.GetTracerProvider()
+
+		shutdown, err := Setup(t.Context(), "git-remote-oci", "v0.0.0")
+		assert.NoError(t, err)
+		assert.Equal(t, prev, otel.GetTracerProvider())
+		assert.NoError(t, shutdown(t.Context()))
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Setenv(EndpointEnv, "http://127.0.0.1:4318")
+		prev := otel.GetTracerProvider()
+		t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+		shutdown, err := Setup(t.Context(), "git-remote-oci", "v0.0.0")
+		assert.NoError(t, err)
+		assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+		// nothing to export
+		assert.NoError(t, shutdown(t.Context()))
+	})
+
+	t.Run("Traces Endpoint", func(t *testing.T) {
+		t.Setenv(EndpointEnv, "")
+		t.Setenv(TracesEndpointEnv, "http://127.0.0.1:4318/v1/traces")
+		assert.True(t, Enabled())
+	})
+}
+
+func TestEnd(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		recorder := recordSpans(t)
+
+		_, span := Start(t.Context(), "op", Remote(registry.Reference{Registry: "reg.example.com", Repository: "repo"})...)
+		var err error
+		End(span, &err)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "op", spans[0].Name())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Contains(t, spans[0].Attributes(), KeyRegistry.String("reg.example.com"))
+		assert.Contains(t, spans[0].Attributes(), KeyRepository.String("repo"))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		recorder := recordSpans(t)
+
+		_, span := Start(t.Context(), "op")
+		err := errors.New("registry unavailable")
+		End(span, &err)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "registry unavailable", spans[0].Status().Description)
+		assert.Len(t, spans[0].Events(), 1)
+	})
+}
+
+func TestEndOnClose(t *testing.T) {
+	recorder := recordSpans(t)
+
+	desc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03", Size: 6}
+	_, span := Start(t.Context(), "fetch", Layer(desc)...)
+	rc := EndOnClose(io.NopCloser(strings.NewReader("hello\n")), span)
+
+	got, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\n", string(got))
+	assert.Empty(t, recorder.Ended())
+
+	assert.NoError(t, rc.Close())
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), KeyDigest.String(desc.Digest.String()))
+	assert.Contains(t, spans[0].Attributes(), KeySize.Int64(6))
+	assert.Contains(t, spans[0].Attributes(), KeyMediaType.String(ocispec.MediaTypeImageLayer))
+}
@@ -4,12 +4,15 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +kubebuilder:object:root=true
 
 // Configuration type is used to store a user's current configuration settings.
+// Configuration files of this version are still loaded, converted to v1alpha2,
+// but are deprecated.
 type Configuration struct {
 	metav1.TypeMeta `json:",inline"`
 
@@ -18,12 +21,203 @@ type Configuration struct {
 
 // ConfigurationSpec is the actual configuration values.
 type ConfigurationSpec struct {
-	RegistryConfig RegistryConfig `json:"registryConfig,omitempty"`
+	RegistryConfig RegistryConfig   `json:"registryConfig,omitempty"`
+	Push           PushConfig       `json:"push,omitempty"`
+	VerifyPolicy   VerifyPolicy     `json:"verifyPolicy,omitempty"`
+	Retry          RetryConfig      `json:"retry,omitempty"`
+	Cache          CacheConfig      `json:"cache,omitempty"`
+	Encryption     EncryptionConfig `json:"encryption,omitempty"`
 }
 
+// EncryptionConfig holds the client-side encryption of packfile and LFS
+// layers, such that their content is confidential to holders of the keys
+// rather than to anyone with read access to the registry. Git manifests and
+// configs, listing references and commits, remain unencrypted.
+type EncryptionConfig struct {
+	// Keys are base64 encoded 256-bit AES keys. The first encrypts pushed
+	// layers, while layers encrypted with any of them are decrypted on fetch,
+	// allowing keys to be rotated. Layers pushed before encryption was
+	// enabled remain unencrypted.
+	Keys []EncryptionKey `json:"keys,omitempty"`
+}
+
+// EncryptionKey is the source of an encryption key. Exactly one of File or
+// Command must be set.
+type EncryptionKey struct {
+	// ID identifies the key in the annotations of the layers it encrypts,
+	// selecting it to decrypt them. Defaults to a fingerprint of the key.
+	ID string `json:"id,omitempty"`
+
+	// File is the path to a file containing the key.
+	File string `json:"file,omitempty"`
+
+	// Command prints the key to standard output, e.g. retrieving it from a
+	// key management service. The first element is the executable, the rest
+	// its arguments.
+	Command []string `json:"command,omitempty"`
+}
+
+// CacheConfig holds the configuration of the local cache of fetched packfile
+// layers and LFS files, shared by all repositories.
+type CacheConfig struct {
+	// Enabled fetches packfile layers and LFS files through the cache, such
+	// that repeated fetches of the same layers are not downloaded again.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Dir is the cache directory. Defaults to "gnoci" within the XDG cache
+	// directory, e.g. "~/.cache/gnoci".
+	Dir string `json:"dir,omitempty"`
+
+	// MaxSize limits the total size of cached blobs, e.g. "10Gi", evicting
+	// the least recently used. Defaults to 5Gi.
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+}
+
+// RetryConfig holds the retry policy of failed registry requests, e.g. server
+// errors and timeouts. Each retry is logged as a warning.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts of a request, including
+	// the first. Defaults to 6, 1 disables retries.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+
+	// InitialBackoff is the wait before the first retry, doubling for each
+	// subsequent retry, e.g. "500ms". Defaults to 250ms.
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+
+	// MaxBackoff limits the wait between retries, e.g. "10s". Defaults to 3s.
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+
+	// RetryTooManyRequests retries requests rate limited with 429 Too Many
+	// Requests after the wait requested by their Retry-After header, which is
+	// not limited by MaxBackoff. Defaults to true.
+	RetryTooManyRequests *bool `json:"retryTooManyRequests,omitempty"`
+}
+
+// PushConfig holds the configuration for pushing to registries.
+type PushConfig struct {
+	// Atomic updates all references of a push, or none of them, only moving
+	// the remote tag if it has not been updated by another client. Equivalent
+	// to Git's push.atomic, which is honored regardless.
+	Atomic bool `json:"atomic,omitempty"`
+
+	// Compression is the algorithm used to compress packfile layers as they
+	// are pushed, one of "none" or "zstd". Defaults to "none". Compressed
+	// layers are always decompressed on fetch.
+	Compression Compression `json:"compression,omitempty"`
+
+	// SigningKey is the path to a PEM encoded PKCS #8 private key. If set,
+	// each pushed Git manifest is signed before the remote tag is updated.
+	// ECDSA, Ed25519, and RSA keys are supported.
+	SigningKey string `json:"signingKey,omitempty"`
+
+	// MaxPackLayerSize splits pushes into multiple packfile layers, each with
+	// objects totaling at most this size uncompressed, e.g. "1Gi". Useful for
+	// registries limiting blob sizes. A single commit is never split, so its
+	// layer may exceed this size. Unset pushes a single layer.
+	MaxPackLayerSize *resource.Quantity `json:"maxPackLayerSize,omitempty"`
+
+	// Concurrency is the maximum number of packfile layers uploaded at once,
+	// e.g. those of a push split by MaxPackLayerSize. Defaults to 3.
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// DeleteOrphanedLayers deletes packfile layers no longer needed by any
+	// reference from the registry, if supported, e.g. after deleting a branch.
+	// Such layers are always dropped from the Git manifest.
+	DeleteOrphanedLayers bool `json:"deleteOrphanedLayers,omitempty"`
+
+	// Snapshots additionally tags each pushed Git manifest once per updated
+	// branch, e.g. "<tag>-refs-heads-main-<abbreviated commit>", recording the tags
+	// in an image index tagged "<tag>-snapshots". Consumers may pin the state
+	// of a branch by its snapshot tag.
+	Snapshots bool `json:"snapshots,omitempty"`
+
+	// Depth truncates the history of an initial push to the given number of
+	// commits from each pushed reference, recording the shallow boundary in
+	// the Git manifest such that clones are shallow. Pushes to an existing
+	// remote are not truncated. Unset pushes full history.
+	Depth int `json:"depth,omitempty"`
+
+	// MountFrom are repositories in the same registry, e.g. "team/project",
+	// from which new packfile layers are mounted before uploading them. Useful
+	// when pushing a Git repository already stored in another OCI repository,
+	// e.g. a fork. Layers missing from every source are uploaded as usual.
+	MountFrom []string `json:"mountFrom,omitempty"`
+
+	// Policy restricts the reference updates of pushes, rejecting violating
+	// references before anything is uploaded.
+	Policy PushPolicy `json:"policy,omitempty"`
+
+	// Timestamp is the creation time recorded in the
+	// org.opencontainers.image.created annotation of pushed manifests, one of
+	// "reproducible" or "now". Defaults to "reproducible", the time given by
+	// the SOURCE_DATE_EPOCH environment variable if set, otherwise the POSIX
+	// epoch, such that pushing identical content produces identical manifests.
+	Timestamp Timestamp `json:"timestamp,omitempty"`
+
+	// SourceURL is recorded in the org.opencontainers.image.source annotation
+	// of pushed Git manifests, e.g. the URL of the upstream Git repository.
+	// Defaults to the source of gnoci mirror, otherwise omitted.
+	SourceURL string `json:"sourceURL,omitempty"`
+}
+
+// PushPolicy holds client-side protections of the references of a remote.
+// Rejected references are reported by Git as failed updates.
+type PushPolicy struct {
+	// ProtectedBranches are patterns of branch names, excluding "refs/heads/",
+	// which may not be deleted or rewritten by a force push, e.g. "main" or
+	// "release/*". Patterns use the syntax of Go's path.Match.
+	ProtectedBranches []string `json:"protectedBranches,omitempty"`
+
+	// DenyForcePush rejects force pushes rewriting the history of any
+	// existing reference. Forced fast forwards are allowed.
+	DenyForcePush bool `json:"denyForcePush,omitempty"`
+
+	// ImmutableTags rejects moving or deleting existing tags.
+	ImmutableTags bool `json:"immutableTags,omitempty"`
+
+	// MaxPackSize rejects pushes whose new objects total more than this size
+	// uncompressed, e.g. "500Mi", failing the references requiring them.
+	// Unset allows pushes of any size.
+	MaxPackSize *resource.Quantity `json:"maxPackSize,omitempty"`
+}
+
+// VerifyPolicy holds the configuration for verifying the signatures of
+// fetched Git manifests.
+type VerifyPolicy struct {
+	// Keys are paths to PEM encoded PKIX public keys. If any are set, fetching
+	// fails unless the Git manifest is signed by one of them.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// Compression is a packfile layer compression algorithm.
+type Compression string
+
+const (
+	// CompressionNone pushes uncompressed packfile layers.
+	CompressionNone Compression = "none"
+	// CompressionZstd pushes zstd compressed packfile layers.
+	CompressionZstd Compression = "zstd"
+)
+
+// Timestamp is the creation time recorded in pushed manifests.
+type Timestamp string
+
+const (
+	// TimestampReproducible records the time given by SOURCE_DATE_EPOCH, or
+	// the POSIX epoch.
+	TimestampReproducible Timestamp = "reproducible"
+	// TimestampNow records the time of the push.
+	TimestampNow Timestamp = "now"
+)
+
 // RegistryConfig holds the custom configuration data for registries and repositories.
 type RegistryConfig struct {
 	Registries map[string]Registry `json:"registries"`
+
+	// CredHelpers maps registries to the name of an external credential
+	// helper, e.g. "ecr-login" invokes docker-credential-ecr-login. Takes
+	// precedence over credentials in Docker and podman auth files.
+	CredHelpers map[string]string `json:"credHelpers,omitempty"`
 }
 
 // Registry contains the custom configuration for a registry.
@@ -33,6 +227,34 @@ type Registry struct {
 
 	// NonCompliant indicates a registry is not OCI compliant.
 	NonCompliant bool `json:"noncompliant,omitempty"`
+
+	// ReferrersTagSchema forces the referrers tag schema, rather than the
+	// Referrers API, for registries with a broken or partial implementation
+	// of the Referrers API.
+	ReferrersTagSchema bool `json:"referrersTagSchema,omitempty"`
+
+	// TagHistory supports registries rejecting tag overwrites, e.g. with tag
+	// immutability enabled. Only the first push tags the remote, later Git
+	// manifests are pushed by digest and recorded in a history referrer of
+	// the tagged manifest, which fetches follow to the latest push. Must be
+	// set by every client of the remote.
+	TagHistory bool `json:"tagHistory,omitempty"`
+
+	// Mirrors are registry hosts mirroring this registry, e.g. pull-through
+	// caches. Reads are attempted from each mirror in order before this
+	// registry, while writes always go to this registry. A mirror's own
+	// entry in registries, if any, configures its connection.
+	Mirrors []string `json:"mirrors,omitempty"`
+
+	// ProxyURL is the proxy requests to this registry are routed through,
+	// e.g. "http://proxy.example.com:3128", overriding the HTTPS_PROXY and
+	// HTTP_PROXY environment variables. Supports http, https, and socks5.
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// NoProxy are hosts connected to directly rather than through a proxy,
+	// in addition to those of the NO_PROXY environment variable and in the
+	// same format, e.g. the blob storage this registry redirects to.
+	NoProxy []string `json:"noProxy,omitempty"`
 }
 
 // ConfigurationDefault defaults the fields in [Configuration].
@@ -5,9 +5,30 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheConfig) DeepCopyInto(out *CacheConfig) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheConfig.
+func (in *CacheConfig) DeepCopy() *CacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Configuration) DeepCopyInto(out *Configuration) {
 	*out = *in
@@ -37,6 +58,11 @@ func (in *Configuration) DeepCopyObject() runtime.Object {
 func (in *ConfigurationSpec) DeepCopyInto(out *ConfigurationSpec) {
 	*out = *in
 	in.RegistryConfig.DeepCopyInto(&out.RegistryConfig)
+	in.Push.DeepCopyInto(&out.Push)
+	in.VerifyPolicy.DeepCopyInto(&out.VerifyPolicy)
+	in.Retry.DeepCopyInto(&out.Retry)
+	in.Cache.DeepCopyInto(&out.Cache)
+	in.Encryption.DeepCopyInto(&out.Encryption)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigurationSpec.
@@ -49,9 +75,112 @@ func (in *ConfigurationSpec) DeepCopy() *ConfigurationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionConfig) DeepCopyInto(out *EncryptionConfig) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]EncryptionKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionConfig.
+func (in *EncryptionConfig) DeepCopy() *EncryptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionKey) DeepCopyInto(out *EncryptionKey) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKey.
+func (in *EncryptionKey) DeepCopy() *EncryptionKey {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PushConfig) DeepCopyInto(out *PushConfig) {
+	*out = *in
+	if in.MaxPackLayerSize != nil {
+		in, out := &in.MaxPackLayerSize, &out.MaxPackLayerSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MountFrom != nil {
+		in, out := &in.MountFrom, &out.MountFrom
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Policy.DeepCopyInto(&out.Policy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PushConfig.
+func (in *PushConfig) DeepCopy() *PushConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PushConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PushPolicy) DeepCopyInto(out *PushPolicy) {
+	*out = *in
+	if in.ProtectedBranches != nil {
+		in, out := &in.ProtectedBranches, &out.ProtectedBranches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxPackSize != nil {
+		in, out := &in.MaxPackSize, &out.MaxPackSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PushPolicy.
+func (in *PushPolicy) DeepCopy() *PushPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PushPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Registry) DeepCopyInto(out *Registry) {
 	*out = *in
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Registry.
@@ -70,6 +199,13 @@ func (in *RegistryConfig) DeepCopyInto(out *RegistryConfig) {
 	if in.Registries != nil {
 		in, out := &in.Registries, &out.Registries
 		*out = make(map[string]Registry, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.CredHelpers != nil {
+		in, out := &in.CredHelpers, &out.CredHelpers
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
@@ -85,3 +221,53 @@ func (in *RegistryConfig) DeepCopy() *RegistryConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryConfig) DeepCopyInto(out *RetryConfig) {
+	*out = *in
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetryTooManyRequests != nil {
+		in, out := &in.RetryTooManyRequests, &out.RetryTooManyRequests
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryConfig.
+func (in *RetryConfig) DeepCopy() *RetryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerifyPolicy) DeepCopyInto(out *VerifyPolicy) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerifyPolicy.
+func (in *VerifyPolicy) DeepCopy() *VerifyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VerifyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
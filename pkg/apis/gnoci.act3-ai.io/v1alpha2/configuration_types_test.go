@@ -0,0 +1,76 @@
+// Package v1alpha2 defines the v1alpha2 schema.
+//
+// +kubebuilder:object:generate=true
+package v1alpha2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigurationDefault(t *testing.T) {
+	t.Run("Unset", func(t *testing.T) {
+		in := &Configuration{}
+
+		ConfigurationDefault(in)
+
+		retryTooManyRequests := true
+		maxSize := resource.MustParse(DefaultCacheMaxSize)
+		assert.Equal(t, &Configuration{
+			TypeMeta: v1.TypeMeta{
+				Kind:       "Configuration",
+				APIVersion: GroupVersion.String(),
+			},
+			ConfigurationSpec: ConfigurationSpec{
+				DefaultTag: DefaultTag,
+				Transfer: TransferConfig{
+					Concurrency: DefaultConcurrency,
+					Retry: RetryConfig{
+						MaxAttempts:          DefaultMaxAttempts,
+						InitialBackoff:       &v1.Duration{Duration: DefaultInitialBackoff},
+						MaxBackoff:           &v1.Duration{Duration: DefaultMaxBackoff},
+						RetryTooManyRequests: &retryTooManyRequests,
+					},
+				},
+				Push: PushConfig{
+					Compression: CompressionNone,
+					Timestamp:   TimestampReproducible,
+				},
+				Cache: CacheConfig{MaxSize: &maxSize},
+			},
+		}, in)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		retryTooManyRequests := false
+		in := &Configuration{
+			ConfigurationSpec: ConfigurationSpec{
+				DefaultTag: "main",
+				Transfer: TransferConfig{
+					Concurrency: 8,
+					Retry: RetryConfig{
+						MaxAttempts:          1,
+						InitialBackoff:       &v1.Duration{Duration: time.Second},
+						RetryTooManyRequests: &retryTooManyRequests,
+					},
+				},
+				Push: PushConfig{Compression: CompressionZstd, Timestamp: TimestampNow},
+			},
+		}
+
+		ConfigurationDefault(in)
+
+		assert.Equal(t, "main", in.DefaultTag)
+		assert.Equal(t, 8, in.Transfer.Concurrency)
+		assert.Equal(t, 1, in.Transfer.Retry.MaxAttempts)
+		assert.Equal(t, time.Second, in.Transfer.Retry.InitialBackoff.Duration)
+		assert.Equal(t, DefaultMaxBackoff, in.Transfer.Retry.MaxBackoff.Duration)
+		assert.False(t, *in.Transfer.Retry.RetryTooManyRequests)
+		assert.Equal(t, CompressionZstd, in.Push.Compression)
+		assert.Equal(t, TimestampNow, in.Push.Timestamp)
+	})
+}
@@ -0,0 +1,137 @@
+// Package v1alpha2 defines the v1alpha2 schema.
+//
+// +kubebuilder:object:generate=true
+package v1alpha2
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+)
+
+// RegisterConversions adds the conversions between v1alpha1 and v1alpha2 to
+// the given scheme, such that v1alpha1 configuration files are loaded into a
+// v1alpha2 [Configuration].
+func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddConversionFunc((*v1alpha1.Configuration)(nil), (*Configuration)(nil), func(a, b any, scope conversion.Scope) error {
+		return Convert_v1alpha1_Configuration_To_v1alpha2_Configuration(a.(*v1alpha1.Configuration), b.(*Configuration), scope)
+	}); err != nil {
+		return fmt.Errorf("registering v1alpha1 to v1alpha2 conversion: %w", err)
+	}
+	if err := s.AddConversionFunc((*Configuration)(nil), (*v1alpha1.Configuration)(nil), func(a, b any, scope conversion.Scope) error {
+		return Convert_v1alpha2_Configuration_To_v1alpha1_Configuration(a.(*Configuration), b.(*v1alpha1.Configuration), scope)
+	}); err != nil {
+		return fmt.Errorf("registering v1alpha2 to v1alpha1 conversion: %w", err)
+	}
+	return nil
+}
+
+// Convert_v1alpha1_Configuration_To_v1alpha2_Configuration converts a v1alpha1
+// [v1alpha1.Configuration] to a v1alpha2 [Configuration]. The push concurrency
+// becomes the transfer concurrency.
+func Convert_v1alpha1_Configuration_To_v1alpha2_Configuration(in *v1alpha1.Configuration, out *Configuration, _ conversion.Scope) error { //nolint:revive,staticcheck
+	in = in.DeepCopy()
+
+	out.TypeMeta = in.TypeMeta
+	out.APIVersion = GroupVersion.String()
+
+	if in.RegistryConfig.Registries != nil {
+		out.Registries = make(map[string]Registry, len(in.RegistryConfig.Registries))
+		for host, reg := range in.RegistryConfig.Registries {
+			out.Registries[host] = Registry{
+				PlainHTTP:          reg.PlainHTTP,
+				NonCompliant:       reg.NonCompliant,
+				ReferrersTagSchema: reg.ReferrersTagSchema,
+				TagHistory:         reg.TagHistory,
+				Mirrors:            reg.Mirrors,
+				ProxyURL:           reg.ProxyURL,
+				NoProxy:            reg.NoProxy,
+			}
+		}
+	}
+	out.CredHelpers = in.RegistryConfig.CredHelpers
+
+	out.Transfer = TransferConfig{
+		Concurrency: in.Push.Concurrency,
+		Retry:       RetryConfig(in.Retry),
+	}
+
+	out.Push = PushConfig{
+		Atomic:               in.Push.Atomic,
+		Compression:          Compression(in.Push.Compression),
+		SigningKey:           in.Push.SigningKey,
+		MaxPackLayerSize:     in.Push.MaxPackLayerSize,
+		DeleteOrphanedLayers: in.Push.DeleteOrphanedLayers,
+		Snapshots:            in.Push.Snapshots,
+		Depth:                in.Push.Depth,
+		MountFrom:            in.Push.MountFrom,
+		Policy:               PushPolicy(in.Push.Policy),
+		Timestamp:            Timestamp(in.Push.Timestamp),
+		SourceURL:            in.Push.SourceURL,
+	}
+	out.VerifyPolicy = VerifyPolicy(in.VerifyPolicy)
+	out.Cache = CacheConfig(in.Cache)
+
+	out.Encryption = EncryptionConfig{}
+	for _, key := range in.Encryption.Keys {
+		out.Encryption.Keys = append(out.Encryption.Keys, EncryptionKey(key))
+	}
+
+	return nil
+}
+
+// Convert_v1alpha2_Configuration_To_v1alpha1_Configuration converts a v1alpha2
+// [Configuration] to a v1alpha1 [v1alpha1.Configuration]. The transfer
+// concurrency becomes the push concurrency, while settings without a v1alpha1
+// equivalent, e.g. the OAuth2 flow of a registry, are dropped.
+func Convert_v1alpha2_Configuration_To_v1alpha1_Configuration(in *Configuration, out *v1alpha1.Configuration, _ conversion.Scope) error { //nolint:revive,staticcheck
+	in = in.DeepCopy()
+
+	out.TypeMeta = in.TypeMeta
+	out.APIVersion = v1alpha1.GroupVersion.String()
+
+	out.RegistryConfig = v1alpha1.RegistryConfig{CredHelpers: in.CredHelpers}
+	if in.Registries != nil {
+		out.RegistryConfig.Registries = make(map[string]v1alpha1.Registry, len(in.Registries))
+		for host, reg := range in.Registries {
+			out.RegistryConfig.Registries[host] = v1alpha1.Registry{
+				PlainHTTP:          reg.PlainHTTP,
+				NonCompliant:       reg.NonCompliant,
+				ReferrersTagSchema: reg.ReferrersTagSchema,
+				TagHistory:         reg.TagHistory,
+				Mirrors:            reg.Mirrors,
+				ProxyURL:           reg.ProxyURL,
+				NoProxy:            reg.NoProxy,
+			}
+		}
+	}
+
+	out.Retry = v1alpha1.RetryConfig(in.Transfer.Retry)
+
+	out.Push = v1alpha1.PushConfig{
+		Atomic:               in.Push.Atomic,
+		Compression:          v1alpha1.Compression(in.Push.Compression),
+		SigningKey:           in.Push.SigningKey,
+		MaxPackLayerSize:     in.Push.MaxPackLayerSize,
+		Concurrency:          in.Transfer.Concurrency,
+		DeleteOrphanedLayers: in.Push.DeleteOrphanedLayers,
+		Snapshots:            in.Push.Snapshots,
+		Depth:                in.Push.Depth,
+		MountFrom:            in.Push.MountFrom,
+		Policy:               v1alpha1.PushPolicy(in.Push.Policy),
+		Timestamp:            v1alpha1.Timestamp(in.Push.Timestamp),
+		SourceURL:            in.Push.SourceURL,
+	}
+	out.VerifyPolicy = v1alpha1.VerifyPolicy(in.VerifyPolicy)
+	out.Cache = v1alpha1.CacheConfig(in.Cache)
+
+	out.Encryption = v1alpha1.EncryptionConfig{}
+	for _, key := range in.Encryption.Keys {
+		out.Encryption.Keys = append(out.Encryption.Keys, v1alpha1.EncryptionKey(key))
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package v1alpha2 defines the v1alpha2 schema.
+//
+// +kubebuilder:object:generate=true
+package v1alpha2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+)
+
+func TestRegisterConversions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, AddToScheme(scheme))
+	assert.NoError(t, RegisterConversions(scheme))
+
+	maxPackLayerSize := resource.MustParse("1Gi")
+	in := &v1alpha1.Configuration{
+		ConfigurationSpec: v1alpha1.ConfigurationSpec{
+			RegistryConfig: v1alpha1.RegistryConfig{
+				Registries: map[string]v1alpha1.Registry{
+					"example.com": {PlainHTTP: true, Mirrors: []string{"mirror.example.com"}},
+				},
+				CredHelpers: map[string]string{"example.com": "ecr-login"},
+			},
+			Push: v1alpha1.PushConfig{
+				Atomic:           true,
+				Compression:      v1alpha1.CompressionZstd,
+				MaxPackLayerSize: &maxPackLayerSize,
+				Concurrency:      8,
+				Policy:           v1alpha1.PushPolicy{ProtectedBranches: []string{"main"}},
+			},
+			Retry:      v1alpha1.RetryConfig{MaxAttempts: 2},
+			Cache:      v1alpha1.CacheConfig{Enabled: true},
+			Encryption: v1alpha1.EncryptionConfig{Keys: []v1alpha1.EncryptionKey{{ID: "key", File: "key.b64"}}},
+		},
+	}
+
+	out := &Configuration{}
+	assert.NoError(t, scheme.Convert(in, out, nil))
+	assert.Equal(t, GroupVersion.String(), out.APIVersion)
+	assert.Equal(t, map[string]Registry{
+		"example.com": {PlainHTTP: true, Mirrors: []string{"mirror.example.com"}},
+	}, out.Registries)
+	assert.Equal(t, map[string]string{"example.com": "ecr-login"}, out.CredHelpers)
+	assert.Equal(t, TransferConfig{Concurrency: 8, Retry: RetryConfig{MaxAttempts: 2}}, out.Transfer)
+	assert.True(t, out.Push.Atomic)
+	assert.Equal(t, CompressionZstd, out.Push.Compression)
+	assert.Equal(t, []string{"main"}, out.Push.Policy.ProtectedBranches)
+	assert.True(t, out.Cache.Enabled)
+	assert.Equal(t, []EncryptionKey{{ID: "key", File: "key.b64"}}, out.Encryption.Keys)
+
+	// conversions do not alias the source
+	out.Registries["example.com"].Mirrors[0] = "changed"
+	assert.Equal(t, "mirror.example.com", in.RegistryConfig.Registries["example.com"].Mirrors[0])
+
+	out.Registries["example.com"].Mirrors[0] = "mirror.example.com"
+
+	back := &v1alpha1.Configuration{}
+	assert.NoError(t, scheme.Convert(out, back, nil))
+	in.APIVersion = v1alpha1.GroupVersion.String()
+	assert.Equal(t, in, back)
+}
@@ -6,13 +6,17 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
 	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha1"
+	"github.com/act3-ai/gnoci/pkg/apis/gnoci.act3-ai.io/v1alpha2"
 )
 
-// NewScheme creates the "scheme" for the API.
+// NewScheme creates the "scheme" for the API. Configurations of the deprecated
+// v1alpha1 version are converted to v1alpha2, the preferred version.
 func NewScheme() *runtime.Scheme {
 	// schemeBuilder is used to add go types to the GroupVersionKind scheme
 	schemeBuilder := runtime.NewSchemeBuilder(
 		v1alpha1.AddToScheme,
+		v1alpha2.AddToScheme,
+		v1alpha2.RegisterConversions,
 	)
 
 	// addToScheme adds the types in this group-version to the given scheme.
@@ -20,6 +24,7 @@ func NewScheme() *runtime.Scheme {
 
 	scheme := runtime.NewScheme()
 	utilruntime.Must(addToScheme(scheme))
+	utilruntime.Must(scheme.SetVersionPriority(v1alpha2.GroupVersion, v1alpha1.GroupVersion))
 
 	return scheme
 }
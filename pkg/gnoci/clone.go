@@ -0,0 +1,212 @@
+package gnoci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/act3-ai/gnoci/internal/cmd"
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// CloneOptions configure [Clone].
+type CloneOptions struct {
+	RemoteOptions
+
+	// Bare creates a bare repository, without a worktree.
+	Bare bool
+	// Depth limits the cloned history to the given number of commits from
+	// each reference. Zero clones the full history.
+	Depth int
+	// RemoteName is the name of the remote configured in the cloned
+	// repository. Defaults to "origin".
+	RemoteName string
+	// Branch is the branch checked out. Defaults to the remote's default
+	// branch, otherwise "main" or "master" if present in the remote, otherwise
+	// the first branch by name.
+	Branch string
+}
+
+// Clone creates a repository at destPath from the Git OCI artifact at
+// ociRef. The remote is configured with an "oci://" or "oci+layout://" URL,
+// such that subsequent Git operations use git-remote-oci. If the clone fails, destPath is removed
+// unless it existed beforehand.
+func Clone(ctx context.Context, ociRef, destPath string, opts *CloneOptions) (err error) {
+	if opts == nil {
+		opts = &CloneOptions{}
+	}
+	remoteName := opts.RemoteName
+	if remoteName == "" {
+		remoteName = gogit.DefaultRemoteName
+	}
+
+	remote, remoteURL, cleanup, err := connectReadOnly(ctx, ociRef, &opts.RemoteOptions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, cleanup())
+	}()
+
+	if _, err := remote.Fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	branch, err := defaultBranch(remote.HeadRefs(), remote.DefaultBranch(), opts.Branch)
+	if err != nil {
+		return err
+	}
+
+	if _, statErr := os.Stat(destPath); errors.Is(statErr, fs.ErrNotExist) {
+		defer func() {
+			if err != nil {
+				err = errors.Join(err, os.RemoveAll(destPath))
+			}
+		}()
+	}
+
+	r, err := git.PlainInit(destPath, opts.Bare)
+	if err != nil {
+		return fmt.Errorf("initializing repository: %w", err)
+	}
+	local := git.NewRepository(r)
+
+	_, err = local.CreateRemote(&config.RemoteConfig{
+		Name:  remoteName,
+		URLs:  []string{remoteURL},
+		Fetch: []config.RefSpec{config.RefSpec(fmt.Sprintf(config.DefaultFetchRefSpec, remoteName))},
+	})
+	if err != nil {
+		return fmt.Errorf("configuring remote: %w", err)
+	}
+
+	reqs, refs := cloneRefs(remote, remoteName)
+
+	cmdOpts := cmdOptions(&opts.RemoteOptions)
+	cmdOpts.Depth = opts.Depth
+	if err := cmd.Fetch(ctx, local, remote, reqs, cmdOpts); err != nil {
+		return fmt.Errorf("fetching packfiles: %w", err)
+	}
+
+	for _, ref := range refs {
+		if err := local.Storer().SetReference(ref); err != nil {
+			return fmt.Errorf("setting reference %s: %w", ref.Name(), err)
+		}
+	}
+
+	if _, err := cmd.RecordPinned(ctx, local, remoteName, remote); err != nil {
+		return err
+	}
+
+	if branch == "" {
+		// nothing to check out
+		return nil
+	}
+
+	return checkoutBranch(r, remote, remoteName, branch, opts.Bare)
+}
+
+// cloneRefs returns fetch requests for all remote references, and the
+// references as they are stored in a cloned repository; branches as
+// remote-tracking references.
+func cloneRefs(remote model.ReadOnlyModeler, remoteName string) ([]gittypes.FetchRequest, []*plumbing.Reference) {
+	heads := remote.HeadRefs()
+	tags := remote.TagRefs()
+	notes := remote.NoteRefs()
+
+	reqs := make([]gittypes.FetchRequest, 0, len(heads)+len(tags)+len(notes))
+	refs := make([]*plumbing.Reference, 0, len(heads)+len(tags)+len(notes))
+	for _, m := range []map[plumbing.ReferenceName]oci.ReferenceInfo{heads, tags, notes} {
+		for name, info := range m {
+			if info.Layer == "" {
+				// not backed by a packfile, e.g. the temporary LFS manifest ref
+				continue
+			}
+			hash := plumbing.NewHash(info.Commit)
+			reqs = append(reqs, gittypes.FetchRequest{Cmd: gittypes.Fetch, Ref: plumbing.NewHashReference(name, hash)})
+
+			if name.IsBranch() {
+				name = plumbing.NewRemoteReferenceName(remoteName, name.Short())
+			}
+			refs = append(refs, plumbing.NewHashReference(name, hash))
+		}
+	}
+
+	return reqs, refs
+}
+
+// defaultBranch selects the branch to check out, returning an empty name if
+// the remote has no branches.
+func defaultBranch(heads map[plumbing.ReferenceName]oci.ReferenceInfo, remoteDefault plumbing.ReferenceName, want string) (plumbing.ReferenceName, error) {
+	if want != "" {
+		name := plumbing.NewBranchReferenceName(want)
+		if _, ok := heads[name]; !ok {
+			return "", fmt.Errorf("%w: remote branch %s", model.ErrReferenceNotFound, want)
+		}
+		return name, nil
+	}
+	if remoteDefault != "" {
+		return remoteDefault, nil
+	}
+
+	names := slices.Sorted(maps.Keys(heads))
+	names = slices.DeleteFunc(names, func(name plumbing.ReferenceName) bool {
+		return heads[name].Layer == ""
+	})
+	for _, name := range []plumbing.ReferenceName{plumbing.Main, plumbing.Master} {
+		if slices.Contains(names, name) {
+			return name, nil
+		}
+	}
+	if len(names) > 0 {
+		return names[0], nil
+	}
+
+	return "", nil
+}
+
+// checkoutBranch creates a local branch tracking the remote branch, pointing
+// HEAD at it. The worktree is populated unless the repository is bare.
+func checkoutBranch(r *gogit.Repository, remote model.ReadOnlyModeler, remoteName string, branch plumbing.ReferenceName, bare bool) error {
+	commit := plumbing.NewHash(remote.HeadRefs()[branch].Commit)
+	if err := r.Storer.SetReference(plumbing.NewHashReference(branch, commit)); err != nil {
+		return fmt.Errorf("creating branch %s: %w", branch, err)
+	}
+	if err := r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		return fmt.Errorf("updating HEAD: %w", err)
+	}
+
+	err := r.CreateBranch(&config.Branch{
+		Name:   branch.Short(),
+		Remote: remoteName,
+		Merge:  branch,
+	})
+	if err != nil {
+		return fmt.Errorf("configuring branch %s: %w", branch, err)
+	}
+
+	if bare {
+		return nil
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		return fmt.Errorf("opening worktree: %w", err)
+	}
+	if err := wt.Reset(&gogit.ResetOptions{Commit: commit, Mode: gogit.HardReset}); err != nil {
+		return fmt.Errorf("checking out %s: %w", branch, err)
+	}
+
+	return nil
+}
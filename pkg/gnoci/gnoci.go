@@ -0,0 +1,161 @@
+// Package gnoci provides a Go API for pushing Git repositories to, and cloning
+// them from, OCI registries without the Git remote helper protocol.
+package gnoci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+
+	"github.com/act3-ai/gnoci/internal/model"
+	"github.com/act3-ai/gnoci/internal/ociutil"
+)
+
+// RemoteOptions configure the connection to an OCI registry.
+type RemoteOptions struct {
+	// PlainHTTP enables http endpoints.
+	PlainHTTP bool
+	// NonCompliant indicates a registry is not OCI compliant.
+	NonCompliant bool
+	// Credentials is a credential store used to authenticate with private
+	// registries. Defaults to the Docker credential store, falling back to
+	// podman auth files.
+	Credentials credentials.Store
+	// UserAgent used for outbound HTTP requests. Defaults to "gnoci".
+	UserAgent string
+	// DefaultTag is the tag of OCI references with neither a tag nor a
+	// digest. Defaults to "latest".
+	DefaultTag string
+	// Progress, if set, receives progress reports in the style of Git.
+	Progress io.Writer
+	// Observer, if set, is notified of changes made to the OCI remote, e.g.
+	// each packfile layer pushed.
+	Observer Observer
+}
+
+// Observer is notified of changes made to an OCI remote, such that embedding
+// applications may react to progress without parsing logs. Methods may be
+// called concurrently. Embed [NopObserver] to handle only some events.
+type Observer = model.Observer
+
+// NopObserver is an [Observer] ignoring all events.
+type NopObserver = model.NopObserver
+
+// ErrRefRejected indicates one or more references could not be updated.
+var ErrRefRejected = errors.New("reference rejected")
+
+// ErrDigestReference indicates a push to an OCI reference by digest, e.g.
+// "oci://reg.example.com/repo@sha256:<hex>", which is immutable.
+var ErrDigestReference = model.ErrDigestReference
+
+// newGraphTarget initializes the OCI remote, overridden in tests.
+var newGraphTarget = ociutil.NewGraphTarget
+
+// connect initializes a modeler for the OCI reference, an "oci://" registry
+// reference or "oci+layout://" or "oci+tar://" OCI image layout. The Git
+// remote URL of the reference is returned alongside the modeler. The returned
+// cleanup function must be called once the modeler is no longer needed.
+func connect(ctx context.Context, ociRef string, opts *RemoteOptions) (model.Modeler, string, func() error, error) {
+	addr, gt, closeTarget, err := openTarget(ctx, ociRef, opts)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	fstorePath, err := os.MkdirTemp("", "GnOCI-fstore-*")
+	if err != nil {
+		return nil, "", nil, errors.Join(fmt.Errorf("creating temporary directory for intermediate OCI file store: %w", err), closeTarget())
+	}
+
+	fstore, err := file.New(fstorePath)
+	if err != nil {
+		return nil, "", nil, errors.Join(fmt.Errorf("initializing OCI filestore: %w", err), os.RemoveAll(fstorePath), closeTarget())
+	}
+
+	cleanup := func() error {
+		var errs []error
+		if err := fstore.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing OCI file store: %w", err))
+		}
+		if err := os.RemoveAll(fstorePath); err != nil {
+			errs = append(errs, fmt.Errorf("removing temporary files: %w", err))
+		}
+		if err := closeTarget(); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	return model.NewModeler(addr.Ref, fstore, gt, modelOpts(opts)...), addr.String(), cleanup, nil
+}
+
+// connectReadOnly extends [connect] for operations only reading the OCI
+// reference, e.g. cloning, without an intermediate file store.
+func connectReadOnly(ctx context.Context, ociRef string, opts *RemoteOptions) (model.ReadOnlyModeler, string, func() error, error) {
+	addr, gt, closeTarget, err := openTarget(ctx, ociRef, opts)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return model.NewReadOnlyModeler(addr.Ref, gt, modelOpts(opts)...), addr.String(), closeTarget, nil
+}
+
+// openTarget initializes the graph target of the OCI reference, see
+// [connect]. The returned function closes the graph target.
+func openTarget(ctx context.Context, ociRef string, opts *RemoteOptions) (ociutil.Address, oras.GraphTarget, func() error, error) {
+	var defaultTag string
+	if opts != nil {
+		defaultTag = opts.DefaultTag
+	}
+	addr, err := ociutil.ParseAddressDefaultTag(ociRef, defaultTag)
+	if err != nil {
+		return ociutil.Address{}, nil, nil, fmt.Errorf("parsing remote address: %w", err)
+	}
+
+	repoOpts := &ociutil.RepositoryOptions{}
+	if opts != nil {
+		repoOpts.PlainHTTP = opts.PlainHTTP
+		repoOpts.NonCompliant = opts.NonCompliant
+		repoOpts.RegistryCreds = opts.Credentials
+		repoOpts.UserAgent = opts.UserAgent
+	}
+
+	var gt oras.GraphTarget
+	if addr.Layout != "" {
+		addr.Layout, err = filepath.Abs(addr.Layout)
+		if err != nil {
+			return ociutil.Address{}, nil, nil, fmt.Errorf("resolving OCI image layout path: %w", err)
+		}
+		gt, err = ociutil.OpenLayout(addr)
+	} else {
+		gt, err = newGraphTarget(ctx, addr.Ref, repoOpts)
+	}
+	if err != nil {
+		return ociutil.Address{}, nil, nil, fmt.Errorf("initializing remote graph target: %w", err)
+	}
+	closeTarget := func() error {
+		if closer, ok := gt.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				return fmt.Errorf("closing OCI remote: %w", err)
+			}
+		}
+		return nil
+	}
+
+	return addr, gt, closeTarget, nil
+}
+
+// modelOpts returns the model options of opts.
+func modelOpts(opts *RemoteOptions) []model.Option {
+	var modelOpts []model.Option
+	if opts != nil && opts.Observer != nil {
+		modelOpts = append(modelOpts, model.WithObserver(opts.Observer))
+	}
+	return modelOpts
+}
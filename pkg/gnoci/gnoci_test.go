@@ -0,0 +1,681 @@
+package gnoci
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+
+	"github.com/act3-ai/gnoci/internal/git"
+	"github.com/act3-ai/gnoci/internal/ociutil"
+	"github.com/act3-ai/gnoci/internal/testutils"
+	"github.com/act3-ai/gnoci/pkg/oci"
+	"github.com/act3-ai/gnoci/pkg/testutils/registrytest"
+)
+
+const testOCIRef = "oci://reg.example.com/repo:latest"
+
+// useMemoryRemote replaces the OCI remote with an in-memory store for the
+// duration of the test.
+func useMemoryRemote(t *testing.T) {
+	t.Helper()
+
+	gt := memory.New()
+	orig := newGraphTarget
+	newGraphTarget = func(context.Context, registry.Reference, *ociutil.RepositoryOptions) (oras.GraphTarget, error) {
+		return gt, nil
+	}
+	t.Cleanup(func() { newGraphTarget = orig })
+}
+
+func TestPushClone(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	first, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+	_, err = builder.CreateTag("v1.0.0", commit)
+	assert.NoError(t, err)
+
+	t.Run("Push Dry Run", func(t *testing.T) {
+		err := Push(ctx, srcDir, testOCIRef, []string{"main"}, &PushOptions{DryRun: true})
+		assert.NoError(t, err)
+
+		err = Clone(ctx, testOCIRef, filepath.Join(t.TempDir(), "clone"), nil)
+		assert.Error(t, err)
+	})
+
+	err = Push(ctx, srcDir, testOCIRef, []string{"main", "refs/tags/v1.0.0"}, nil)
+	assert.NoError(t, err)
+
+	t.Run("Clone", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "clone")
+		err := Clone(ctx, testOCIRef, dst, nil)
+		assert.NoError(t, err)
+
+		r, err := gogit.PlainOpen(dst)
+		assert.NoError(t, err)
+
+		head, err := r.Head()
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.Main, head.Name())
+		assert.Equal(t, commit, head.Hash())
+
+		tag, err := r.Reference(plumbing.NewTagReferenceName("v1.0.0"), true)
+		assert.NoError(t, err)
+		assert.Equal(t, commit, tag.Hash())
+
+		tracking, err := r.Reference(plumbing.NewRemoteReferenceName(gogit.DefaultRemoteName, "main"), true)
+		assert.NoError(t, err)
+		assert.Equal(t, commit, tracking.Hash())
+
+		remote, err := r.Remote(gogit.DefaultRemoteName)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{testOCIRef}, remote.Config().URLs)
+
+		status, err := worktreeStatus(r)
+		assert.NoError(t, err)
+		assert.True(t, status)
+	})
+
+	t.Run("Clone Bare", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "clone.git")
+		err := Clone(ctx, testOCIRef, dst, &CloneOptions{Bare: true})
+		assert.NoError(t, err)
+
+		r, err := gogit.PlainOpen(dst)
+		assert.NoError(t, err)
+		_, err = r.Worktree()
+		assert.ErrorIs(t, err, gogit.ErrIsBareRepository)
+
+		_, err = r.CommitObject(commit)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Clone Missing Branch", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "clone")
+		err := Clone(ctx, testOCIRef, dst, &CloneOptions{Branch: "dne"})
+		assert.Error(t, err)
+		assert.NoDirExists(t, dst)
+	})
+
+	t.Run("Push Atomic Rejected", func(t *testing.T) {
+		_, err := builder.CreateBranch("stale", first)
+		assert.NoError(t, err)
+		_, err = builder.CreateTag("v2.0.0", commit)
+		assert.NoError(t, err)
+
+		// non-fast-forward
+		err = Push(ctx, srcDir, testOCIRef, []string{"stale:main", "v2.0.0"}, &PushOptions{Atomic: true})
+		assert.ErrorIs(t, err, ErrRefRejected)
+
+		dst := filepath.Join(t.TempDir(), "clone")
+		err = Clone(ctx, testOCIRef, dst, &CloneOptions{Bare: true})
+		assert.NoError(t, err)
+
+		r, err := gogit.PlainOpen(dst)
+		assert.NoError(t, err)
+		_, err = r.Reference(plumbing.NewTagReferenceName("v2.0.0"), true)
+		assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+	})
+
+	t.Run("Push Delete", func(t *testing.T) {
+		err := Push(ctx, srcDir, testOCIRef, []string{":refs/tags/v1.0.0"}, nil)
+		assert.NoError(t, err)
+
+		dst := filepath.Join(t.TempDir(), "clone")
+		err = Clone(ctx, testOCIRef, dst, nil)
+		assert.NoError(t, err)
+
+		r, err := gogit.PlainOpen(dst)
+		assert.NoError(t, err)
+		_, err = r.Reference(plumbing.NewTagReferenceName("v1.0.0"), true)
+		assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+	})
+}
+
+func TestPushCloneLayout(t *testing.T) {
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		layout func(dir string) (ref, written string)
+	}{
+		{
+			name: "Directory",
+			layout: func(dir string) (string, string) {
+				return "oci+layout://" + dir + ":sync", filepath.Join(dir, "index.json")
+			},
+		},
+		{
+			name: "Tarball",
+			layout: func(dir string) (string, string) {
+				path := filepath.Join(dir, "repo.tar")
+				return "oci+tar://" + path + ":sync", path
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layoutRef, written := tt.layout(t.TempDir())
+			err := Push(ctx, srcDir, layoutRef, []string{"main"}, nil)
+			assert.NoError(t, err)
+			assert.FileExists(t, written)
+
+			dst := filepath.Join(t.TempDir(), "clone")
+			err = Clone(ctx, layoutRef, dst, nil)
+			assert.NoError(t, err)
+
+			r, err := gogit.PlainOpen(dst)
+			assert.NoError(t, err)
+			head, err := r.Head()
+			assert.NoError(t, err)
+			assert.Equal(t, commit, head.Hash())
+
+			remote, err := r.Remote(gogit.DefaultRemoteName)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{layoutRef}, remote.Config().URLs)
+		})
+	}
+}
+
+func TestPushClonePinned(t *testing.T) {
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+
+	layoutDir := t.TempDir()
+	err = Push(ctx, srcDir, "oci+layout://"+layoutDir+":sync", []string{"main"}, nil)
+	assert.NoError(t, err)
+
+	remote, _, cleanup, err := connectReadOnly(ctx, "oci+layout://"+layoutDir+":sync", nil)
+	assert.NoError(t, err)
+	manDesc, err := remote.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, cleanup())
+
+	pinnedRef := "oci+layout://" + layoutDir + "@" + manDesc.Digest.String()
+	dst := filepath.Join(t.TempDir(), "clone")
+	err = Clone(ctx, pinnedRef, dst, nil)
+	assert.NoError(t, err)
+
+	r, err := gogit.PlainOpen(dst)
+	assert.NoError(t, err)
+	head, err := r.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, commit, head.Hash())
+
+	// the pinned digest is recorded for provenance
+	cfg, err := r.Config()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pinnedRef}, cfg.Remotes[gogit.DefaultRemoteName].URLs)
+	assert.Equal(t, manDesc.Digest.String(), cfg.Raw.Section("remote").Subsection(gogit.DefaultRemoteName).Option("ocimanifest"))
+
+	// digests are immutable
+	err = Push(ctx, srcDir, pinnedRef, []string{"main"}, nil)
+	assert.ErrorIs(t, err, ErrDigestReference)
+}
+
+func TestPushSplitLayers(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	var commits []plumbing.Hash
+	for range 3 {
+		commit, err := builder.CreateRandomCommit(64)
+		assert.NoError(t, err)
+		commits = append(commits, commit)
+	}
+	_, err = builder.CreateBranch("main", commits[2])
+	assert.NoError(t, err)
+
+	// every commit exceeds the limit
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, &PushOptions{MaxPackLayerSize: 1})
+	assert.NoError(t, err)
+
+	remote, _, cleanup, err := connectReadOnly(ctx, testOCIRef, nil)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cleanup())
+	}()
+	_, err = remote.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, remote.Layers(), 3)
+	assert.Equal(t, remote.Layers()[2].Digest, remote.HeadRefs()[plumbing.Main].Layer)
+
+	dst := filepath.Join(t.TempDir(), "clone")
+	err = Clone(ctx, testOCIRef, dst, nil)
+	assert.NoError(t, err)
+
+	r, err := gogit.PlainOpen(dst)
+	assert.NoError(t, err)
+	for _, commit := range commits {
+		_, err := r.CommitObject(commit)
+		assert.NoError(t, err)
+	}
+}
+
+func TestPushSplitLayersThin(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	var commit plumbing.Hash
+	for range 4 {
+		commit, err = builder.CreateRandomCommit(64)
+		assert.NoError(t, err)
+	}
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, nil)
+	assert.NoError(t, err)
+
+	// each packfile is deltified against the trees of the one before it
+	for range 3 {
+		commit, err = builder.CreateRandomCommit(64)
+		assert.NoError(t, err)
+	}
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, &PushOptions{MaxPackLayerSize: 1})
+	assert.NoError(t, err)
+
+	remote, _, cleanup, err := connectReadOnly(ctx, testOCIRef, nil)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cleanup())
+	}()
+	_, err = remote.Fetch(ctx)
+	assert.NoError(t, err)
+	layers := remote.Layers()
+	assert.Len(t, layers, 4)
+	for i := 2; i < len(layers); i++ {
+		assert.Equal(t, layers[i-1].Digest.String(), layers[i].Annotations[oci.AnnotationPackBase])
+	}
+
+	// shallow fetches resolve the bases of the tip's packfile
+	dst := filepath.Join(t.TempDir(), "clone")
+	err = Clone(ctx, testOCIRef, dst, &CloneOptions{Depth: 1})
+	assert.NoError(t, err)
+
+	r, err := gogit.PlainOpen(dst)
+	assert.NoError(t, err)
+	head, err := r.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, commit, head.Hash())
+	status, err := worktreeStatus(r)
+	assert.NoError(t, err)
+	assert.True(t, status)
+}
+
+func TestPushProgress(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+
+	progress := &bytes.Buffer{}
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, &PushOptions{RemoteOptions: RemoteOptions{Progress: progress}})
+	assert.NoError(t, err)
+	assert.Contains(t, progress.String(), "Writing objects: 100%")
+	assert.Regexp(t, `Uploading packfile layers: 1, \d+(\.\d+)? (bytes|KiB).*, done\.\n`, progress.String())
+}
+
+func TestPushDeletePrunesLayers(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	base, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", base)
+	assert.NoError(t, err)
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, nil)
+	assert.NoError(t, err)
+
+	feature, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("feature", feature)
+	assert.NoError(t, err)
+	err = Push(ctx, srcDir, testOCIRef, []string{"feature"}, nil)
+	assert.NoError(t, err)
+
+	// the layer holding the feature branch is no longer needed
+	err = Push(ctx, srcDir, testOCIRef, []string{":feature"}, nil)
+	assert.NoError(t, err)
+
+	remote, _, cleanup, err := connectReadOnly(ctx, testOCIRef, nil)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cleanup())
+	}()
+	_, err = remote.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, remote.Layers(), 1)
+	assert.Equal(t, remote.Layers()[0].Digest, remote.HeadRefs()[plumbing.Main].Layer)
+	assert.NotContains(t, remote.HeadRefs(), plumbing.NewBranchReferenceName("feature"))
+}
+
+func TestPushSnapshot(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+	_, err = builder.CreateTag("v1", commit)
+	assert.NoError(t, err)
+	err = Push(ctx, srcDir, testOCIRef, []string{"main", "v1"}, &PushOptions{Snapshot: true})
+	assert.NoError(t, err)
+
+	// the snapshot pins the branch state
+	snapshot, _, cleanup, err := connectReadOnly(ctx, "oci://reg.example.com/repo:latest-refs-heads-main-"+commit.String()[:12], nil)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cleanup())
+	}()
+	_, err = snapshot.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, commit.String(), snapshot.HeadRefs()[plumbing.Main].Commit)
+
+	// only branches are snapshotted
+	tagSnapshot, _, tagCleanup, err := connectReadOnly(ctx, "oci://reg.example.com/repo:refs-tags-v1-"+commit.String()[:12], nil)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, tagCleanup())
+	}()
+	_, err = tagSnapshot.Fetch(ctx)
+	assert.Error(t, err)
+}
+
+func TestPushCloneDefaultTag(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+
+	untagged := "oci://reg.example.com/repo"
+	err = Push(ctx, srcDir, untagged, []string{"main"}, &PushOptions{RemoteOptions: RemoteOptions{DefaultTag: "main"}})
+	assert.NoError(t, err)
+
+	dst := filepath.Join(t.TempDir(), "clone")
+	err = Clone(ctx, "oci://reg.example.com/repo:main", dst, nil)
+	assert.NoError(t, err)
+
+	// the default tag is recorded in the remote URL
+	dst = filepath.Join(t.TempDir(), "clone-default")
+	err = Clone(ctx, untagged, dst, &CloneOptions{RemoteOptions: RemoteOptions{DefaultTag: "main"}})
+	assert.NoError(t, err)
+	r, err := gogit.PlainOpen(dst)
+	assert.NoError(t, err)
+	remote, err := r.Remote(gogit.DefaultRemoteName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"oci://reg.example.com/repo:main"}, remote.Config().URLs)
+}
+
+func TestPushShallow(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	var commits []plumbing.Hash
+	for range 3 {
+		commit, err := builder.CreateRandomCommit(64)
+		assert.NoError(t, err)
+		commits = append(commits, commit)
+	}
+	_, err = builder.CreateBranch("main", commits[2])
+	assert.NoError(t, err)
+
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, &PushOptions{Depth: 2})
+	assert.NoError(t, err)
+
+	remote, _, cleanup, err := connectReadOnly(ctx, testOCIRef, nil)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cleanup())
+	}()
+	_, err = remote.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{commits[1]}, remote.Shallow())
+
+	// clones are shallow, lacking the truncated history
+	dst := filepath.Join(t.TempDir(), "clone")
+	err = Clone(ctx, testOCIRef, dst, nil)
+	assert.NoError(t, err)
+
+	r, err := gogit.PlainOpen(dst)
+	assert.NoError(t, err)
+	_, err = r.CommitObject(commits[1])
+	assert.NoError(t, err)
+	_, err = r.CommitObject(commits[0])
+	assert.ErrorIs(t, err, plumbing.ErrObjectNotFound)
+	shallow, err := r.Storer.Shallow()
+	assert.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{commits[1]}, shallow)
+
+	// later pushes are not truncated, building on the shallow history
+	next, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", next)
+	assert.NoError(t, err)
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, &PushOptions{Depth: 1})
+	assert.NoError(t, err)
+
+	updated, _, updatedCleanup, err := connectReadOnly(ctx, testOCIRef, nil)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, updatedCleanup())
+	}()
+	_, err = updated.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, updated.Layers(), 2)
+	assert.Equal(t, []plumbing.Hash{commits[1]}, updated.Shallow())
+}
+
+// worktreeStatus reports whether the worktree is clean.
+func worktreeStatus(r *gogit.Repository) (bool, error) {
+	wt, err := r.Worktree()
+	if err != nil {
+		return false, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return false, err
+	}
+	return status.IsClean(), nil
+}
+
+func Test_parseRefspec(t *testing.T) {
+	builder, err := testutils.NewRepoBuilder(t.TempDir())
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(16)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("feature", commit)
+	assert.NoError(t, err)
+	_, err = builder.CreateTag("v1", commit)
+	assert.NoError(t, err)
+	local := git.NewRepository(builder.Repo())
+
+	tests := []struct {
+		name       string
+		spec       string
+		wantSrc    plumbing.ReferenceName
+		wantRemote plumbing.ReferenceName
+		wantForce  bool
+		wantErr    bool
+	}{
+		{name: "Short Branch", spec: "feature", wantSrc: "refs/heads/feature", wantRemote: "refs/heads/feature"},
+		{name: "Short Tag", spec: "v1", wantSrc: "refs/tags/v1", wantRemote: "refs/tags/v1"},
+		{name: "Full Name", spec: "refs/heads/feature", wantSrc: "refs/heads/feature", wantRemote: "refs/heads/feature"},
+		{name: "Force Rename", spec: "+feature:main", wantSrc: "refs/heads/feature", wantRemote: "refs/heads/main", wantForce: true},
+		{name: "Tag Rename", spec: "v1:v2", wantSrc: "refs/tags/v1", wantRemote: "refs/tags/v2"},
+		{name: "Delete", spec: ":refs/heads/old", wantRemote: "refs/heads/old"},
+		{name: "Missing Destination", spec: "feature:", wantErr: true},
+		{name: "Unknown Source", spec: "dne", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseRefspec(local, tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSrc, req.Src)
+			assert.Equal(t, tt.wantRemote, req.Remote)
+			assert.Equal(t, tt.wantForce, req.Force)
+		})
+	}
+}
+
+func Test_defaultBranch(t *testing.T) {
+	info := oci.ReferenceInfo{Commit: "abc", Layer: "sha256:abc"}
+	tests := []struct {
+		name    string
+		heads   map[plumbing.ReferenceName]oci.ReferenceInfo
+		remote  plumbing.ReferenceName
+		want    string
+		expect  plumbing.ReferenceName
+		wantErr bool
+	}{
+		{name: "Prefer Main", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{"refs/heads/a": info, plumbing.Master: info, plumbing.Main: info}, expect: plumbing.Main},
+		{name: "Master", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{"refs/heads/a": info, plumbing.Master: info}, expect: plumbing.Master},
+		{name: "First By Name", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{"refs/heads/b": info, "refs/heads/a": info}, expect: "refs/heads/a"},
+		{name: "Skip Without Layer", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{plumbing.Main: {Commit: "abc"}, "refs/heads/b": info}, expect: "refs/heads/b"},
+		{name: "Remote Default", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{plumbing.Main: info, "refs/heads/b": info}, remote: "refs/heads/b", expect: "refs/heads/b"},
+		{name: "Requested", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{plumbing.Main: info, "refs/heads/b": info}, want: "b", expect: "refs/heads/b"},
+		{name: "Requested Missing", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{plumbing.Main: info}, want: "b", wantErr: true},
+		{name: "Empty", heads: map[plumbing.ReferenceName]oci.ReferenceInfo{}, expect: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := defaultBranch(tt.heads, tt.remote, tt.want)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestPushCloneRegistry(t *testing.T) {
+	ctx := context.Background()
+	reg := registrytest.New(t)
+	ociRef := "oci://" + reg.Reference("repo", "latest")
+	opts := RemoteOptions{PlainHTTP: true, Credentials: credentials.NewMemoryStore()}
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+	_, err = builder.CreateTag("v1.0.0", commit)
+	assert.NoError(t, err)
+
+	err = Push(ctx, srcDir, ociRef, []string{"main", "v1.0.0"}, &PushOptions{RemoteOptions: opts})
+	assert.NoError(t, err)
+	assert.Contains(t, reg.Requests(), "PUT /v2/repo/manifests/latest")
+
+	dst := filepath.Join(t.TempDir(), "clone")
+	err = Clone(ctx, ociRef, dst, &CloneOptions{RemoteOptions: opts})
+	assert.NoError(t, err)
+
+	r, err := gogit.PlainOpen(dst)
+	assert.NoError(t, err)
+	head, err := r.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, commit, head.Hash())
+	tag, err := r.Tag("v1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, commit, tag.Hash())
+}
+
+// tagObserver records the Git manifests tagged.
+type tagObserver struct {
+	NopObserver
+	tagged []string
+}
+
+func (o *tagObserver) OnManifestTagged(_ context.Context, _ ocispec.Descriptor, ref registry.Reference) {
+	o.tagged = append(o.tagged, ref.String())
+}
+
+func TestPushObserver(t *testing.T) {
+	useMemoryRemote(t)
+	ctx := context.Background()
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	builder, err := testutils.NewRepoBuilder(srcDir)
+	assert.NoError(t, err)
+	commit, err := builder.CreateRandomCommit(64)
+	assert.NoError(t, err)
+	_, err = builder.CreateBranch("main", commit)
+	assert.NoError(t, err)
+
+	obs := &tagObserver{}
+	err = Push(ctx, srcDir, testOCIRef, []string{"main"}, &PushOptions{RemoteOptions: RemoteOptions{Observer: obs}})
+	assert.NoError(t, err)
+	assert.Contains(t, obs.tagged, "reg.example.com/repo:latest")
+}
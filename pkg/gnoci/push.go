@@ -0,0 +1,184 @@
+package gnoci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/act3-ai/gnoci/internal/cmd"
+	"github.com/act3-ai/gnoci/internal/git"
+	gittypes "github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// PushOptions configure [Push].
+type PushOptions struct {
+	RemoteOptions
+
+	// Force permits non-fast-forward reference updates, as if each refspec
+	// were prefixed with "+".
+	Force bool
+	// DryRun compares references without updating the remote.
+	DryRun bool
+	// Atomic updates all references, or none of them, only moving the remote
+	// tag if it has not been updated since it was fetched.
+	Atomic bool
+	// MaxPackLayerSize splits the push into multiple packfile layers, each
+	// with objects totaling at most this many bytes uncompressed, avoiding
+	// registry blob size limits. Zero pushes a single layer.
+	MaxPackLayerSize int64
+	// Snapshot additionally tags the pushed Git manifest for each updated
+	// branch, e.g. "latest-refs-heads-main-<abbreviated commit>", pinning its
+	// state.
+	Snapshot bool
+	// Depth truncates the history of an initial push to the given number of
+	// commits from each reference, as with "git clone --depth". Clones of
+	// the remote are shallow. Ignored if the remote already exists.
+	Depth int
+}
+
+// Push updates the Git OCI artifact at ociRef with references of the local
+// repository at repoPath, creating the artifact if it does not exist.
+//
+// Refs are Git refspecs in the form "[+]<src>[:<dst>]", e.g. "main",
+// "refs/tags/v1.0.0", or "+feature:refs/heads/main". An empty src, e.g.
+// ":refs/heads/old", deletes the reference from the remote. Short names are
+// resolved to local branches, then tags.
+//
+// If any reference is rejected, an error wrapping [ErrRefRejected] is returned
+// for each, while accepted references are still pushed.
+func Push(ctx context.Context, repoPath, ociRef string, refs []string, opts *PushOptions) (err error) {
+	if opts == nil {
+		opts = &PushOptions{}
+	}
+
+	r, err := gogit.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("opening local repository: %w", err)
+	}
+	local := git.NewRepository(r)
+
+	reqs := make([]gittypes.PushRequest, 0, len(refs))
+	for _, spec := range refs {
+		req, err := parseRefspec(local, spec)
+		if err != nil {
+			return err
+		}
+		req.Force = req.Force || opts.Force
+		reqs = append(reqs, req)
+	}
+
+	remote, _, cleanup, err := connect(ctx, ociRef, &opts.RemoteOptions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, cleanup())
+	}()
+
+	fetch := remote.FetchOrDefault
+	if opts.DryRun {
+		fetch = remote.FetchOrEmpty
+	}
+	if _, err := fetch(ctx); err != nil {
+		return fmt.Errorf("fetching remote metadata: %w", err)
+	}
+
+	cmdOpts := cmdOptions(&opts.RemoteOptions)
+	cmdOpts.DryRun = opts.DryRun
+	cmdOpts.Atomic = opts.Atomic
+	cmdOpts.MaxPackLayerSize = opts.MaxPackLayerSize
+	cmdOpts.Snapshot = opts.Snapshot
+	cmdOpts.PushDepth = opts.Depth
+	results, err := cmd.Push(ctx, local, remote, reqs, cmdOpts)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, result := range results {
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrRefRejected, result.Remote, result.Error))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// parseRefspec converts a push refspec into a push request.
+func parseRefspec(local git.Repository, spec string) (gittypes.PushRequest, error) {
+	req := gittypes.PushRequest{Cmd: gittypes.Push}
+
+	spec, req.Force = strings.CutPrefix(spec, "+")
+	src, dst, hasDst := strings.Cut(spec, ":")
+	if src != "" {
+		srcName, err := expandLocalRef(local, src)
+		if err != nil {
+			return req, fmt.Errorf("resolving refspec %q: %w", spec, err)
+		}
+		req.Src = srcName
+	}
+
+	switch {
+	case !hasDst:
+		req.Remote = req.Src
+	case dst == "":
+		return req, fmt.Errorf("invalid refspec %q: missing destination", spec)
+	case strings.HasPrefix(dst, "refs/"):
+		req.Remote = plumbing.ReferenceName(dst)
+	case req.Src.IsTag():
+		req.Remote = plumbing.NewTagReferenceName(dst)
+	default:
+		req.Remote = plumbing.NewBranchReferenceName(dst)
+	}
+
+	if req.Remote == "" {
+		return req, fmt.Errorf("invalid refspec %q", spec)
+	}
+
+	return req, nil
+}
+
+// expandLocalRef resolves a short reference name to a full local reference
+// name, preferring branches over tags.
+func expandLocalRef(local git.Repository, name string) (plumbing.ReferenceName, error) {
+	if name == plumbing.HEAD.String() {
+		head, err := local.Head()
+		if err != nil {
+			return "", fmt.Errorf("resolving HEAD: %w", err)
+		}
+		return head.Name(), nil
+	}
+
+	candidates := []plumbing.ReferenceName{plumbing.ReferenceName(name)}
+	if !strings.HasPrefix(name, "refs/") {
+		candidates = []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(name),
+			plumbing.NewTagReferenceName(name),
+		}
+	}
+
+	for _, candidate := range candidates {
+		_, err := local.Reference(candidate, false)
+		switch {
+		case errors.Is(err, plumbing.ErrReferenceNotFound):
+			continue
+		case err != nil:
+			return "", fmt.Errorf("resolving reference %s: %w", candidate, err)
+		}
+		return candidate, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", plumbing.ErrReferenceNotFound, name)
+}
+
+// cmdOptions converts options to those used by remote helper commands.
+func cmdOptions(opts *RemoteOptions) *cmd.Options {
+	return &cmd.Options{
+		Progress:    opts.Progress != nil,
+		ProgressOut: opts.Progress,
+	}
+}
@@ -2,7 +2,10 @@
 package oci
 
 import (
+	"strings"
+
 	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/opencontainers/go-digest"
 )
 
@@ -16,11 +19,112 @@ const (
 
 	// MediaTypePackLayer is the media type for a Git packfile stored as an OCI layer.
 	MediaTypePackLayer = "application/vnd.ai.act3.git.pack.v1"
+	// MediaTypePackLayerZstd is the media type for a zstd compressed Git
+	// packfile stored as an OCI layer.
+	MediaTypePackLayerZstd = "application/vnd.ai.act3.git.pack.v1+zstd"
+	// MediaTypePackLayerEncrypted is the media type for an encrypted Git
+	// packfile stored as an OCI layer.
+	MediaTypePackLayerEncrypted = MediaTypePackLayer + MediaTypeSuffixEncrypted
+	// MediaTypePackLayerZstdEncrypted is the media type for a zstd
+	// compressed, then encrypted, Git packfile stored as an OCI layer.
+	MediaTypePackLayerZstdEncrypted = MediaTypePackLayerZstd + MediaTypeSuffixEncrypted
+
+	// AnnotationPackBase is the key for the packfile layer annotation denoting the digest of the newest layer a
+	// thin packfile depends on. Delta bases are resolved from objects within that layer and all layers before it.
+	AnnotationPackBase = "vnd.ai.act3.git.pack.base"
+
+	// AnnotationPackCommits is the key for the packfile layer annotation listing the commits within a packfile,
+	// as comma separated hex hashes. Layers with many commits are not indexed.
+	AnnotationPackCommits = "vnd.ai.act3.git.pack.commits"
+
+	// AnnotationPackCreator is the key for the packfile layer annotation denoting the user agent and version of
+	// the client that pushed the layer, e.g. "git-remote-oci/v0.1.0".
+	AnnotationPackCreator = "vnd.ai.act3.git.pack.creator"
+
+	// AnnotationPackRefs is the key for the packfile layer annotation mapping the references pushed with the
+	// layer to their tip commits, as a JSON object.
+	AnnotationPackRefs = "vnd.ai.act3.git.pack.refs"
+
+	// AnnotationPackCommitCount is the key for the packfile layer annotation denoting the number of commits
+	// within a packfile.
+	AnnotationPackCommitCount = "vnd.ai.act3.git.pack.commit-count"
 
 	// AnnotationGitRemoteOCIVersion is the key for the annotation to denote the git-remote-oci version used during the most recent operation.
 	AnnotationGitRemoteOCIVersion = "vnd.ai.act3.git-remote-oci.version"
 )
 
+// Pack index OCI layers.
+const (
+	// MediaTypePackIndex is the media type for the Git pack index, version 2,
+	// of a packfile layer, stored as a companion layer following the packfile
+	// layers. Indexes describe the uncompressed, decrypted packfile.
+	MediaTypePackIndex = "application/vnd.ai.act3.git.pack-index.v2"
+
+	// AnnotationPackIndex is the key for the packfile layer annotation denoting the digest of its pack index layer.
+	AnnotationPackIndex = "vnd.ai.act3.git.pack.index"
+
+	// AnnotationPackIndexPack is the key for the pack index layer annotation denoting the digest of the packfile
+	// layer it indexes.
+	AnnotationPackIndexPack = "vnd.ai.act3.git.pack-index.pack"
+)
+
+// Commit-graph OCI layers.
+const (
+	// MediaTypeCommitGraph is the media type for the zstd compressed, JSON
+	// encoded [CommitGraph] of a Git manifest, stored as its last layer.
+	MediaTypeCommitGraph = "application/vnd.ai.act3.git.commit-graph.v1+json+zstd"
+)
+
+// CommitGraph maps the commits of the packfile layers of a Git manifest to
+// the layer containing them and their parents, such that clients may resolve
+// commits and their ancestry without fetching packfiles.
+type CommitGraph struct {
+	// Layers are the digests of the packfile layers whose commits are
+	// indexed, in manifest order. Commits of other layers, e.g. those pushed
+	// before the commit-graph was introduced, are not indexed.
+	Layers []digest.Digest `json:"layers"`
+
+	// Commits are the indexed commits, sorted by hash.
+	Commits []CommitGraphEntry `json:"commits"`
+}
+
+// CommitGraphEntry is a commit indexed by a [CommitGraph].
+type CommitGraphEntry struct {
+	// Commit is the commit hash.
+	Commit string `json:"commit"`
+
+	// Layer is the index in [CommitGraph.Layers] of the layer containing the
+	// commit.
+	Layer int `json:"layer"`
+
+	// Parents are the indices in [CommitGraph.Commits] of the commit's
+	// parents. Parents not indexed, e.g. beyond a shallow boundary, are
+	// omitted.
+	Parents []int `json:"parents,omitempty"`
+}
+
+// Encrypted OCI layers.
+const (
+	// MediaTypeSuffixEncrypted is the suffix of the media type of a layer
+	// encrypted with AES-256-GCM by the client, appended to the media type of
+	// its plaintext.
+	MediaTypeSuffixEncrypted = "+encrypted"
+
+	// AnnotationEncryptionKeyID is the key for the encrypted layer annotation
+	// identifying the key it is encrypted with.
+	AnnotationEncryptionKeyID = "vnd.ai.act3.git.encryption.key-id"
+
+	// AnnotationEncryptionDigest is the key for the encrypted LFS layer
+	// annotation denoting the digest of its plaintext, the OID of the LFS
+	// file, by which it is fetched.
+	AnnotationEncryptionDigest = "vnd.ai.act3.git.encryption.digest"
+
+	// AnnotationLFSOid is the key for the LFS layer annotation denoting the
+	// OID of its LFS file, e.g. "sha256:<oid>", if the layer is digested with
+	// another algorithm or is an LFS recipe.
+	AnnotationLFSOid = "vnd.ai.act3.git.lfs.oid"
+)
+
 // ConfigGit is an OCI manifest config, containing information about a Git repository's references.
 type ConfigGit struct {
 	// Heads map Git head references to commit OID and layer digest pairs.
@@ -28,6 +132,22 @@ type ConfigGit struct {
 
 	// Tags map Git tag references to commit OID and layer digest pairs.
 	Tags map[plumbing.ReferenceName]ReferenceInfo `json:"tags"`
+
+	// Notes map Git notes references to commit OID and layer digest pairs.
+	Notes map[plumbing.ReferenceName]ReferenceInfo `json:"notes,omitempty"`
+
+	// DefaultBranch is the head reference HEAD points to, checked out when
+	// cloning.
+	DefaultBranch plumbing.ReferenceName `json:"defaultBranch,omitempty"`
+
+	// ObjectFormat is the hash algorithm of the repository's objects. Empty
+	// indicates SHA-1.
+	ObjectFormat formatcfg.ObjectFormat `json:"objectFormat,omitempty"`
+
+	// Shallow lists the commits on the shallow boundary of a truncated
+	// history, whose parents are not within any packfile layer. Clones mark
+	// these commits as shallow.
+	Shallow []string `json:"shallow,omitempty"`
 }
 
 // ReferenceInfo holds informations about Git references stored in bundle layers.
@@ -37,6 +157,153 @@ type ReferenceInfo struct {
 
 	// OCI layer, the packfile containing Commit
 	Layer digest.Digest `json:"layer"`
+
+	// Layers are the packfile layers newer than Layer holding objects
+	// reachable from Commit, e.g. if Commit was pushed before its history.
+	// Fetches of the reference fetch the union of Layer and Layers.
+	Layers []digest.Digest `json:"layers,omitempty"`
+
+	// Peeled is the object an annotated tag points to, if Commit is a tag
+	// object. Allows fetches to follow tags without the tag object.
+	Peeled string `json:"peeled,omitempty"`
+}
+
+// AllLayers returns the packfile layers holding the objects reachable from
+// Commit, Layer followed by Layers. Returns nil if the reference is not backed
+// by a packfile.
+func (r ReferenceInfo) AllLayers() []digest.Digest {
+	if r.Layer == "" {
+		return nil
+	}
+	return append([]digest.Digest{r.Layer}, r.Layers...)
+}
+
+// Snapshot OCI artifacts.
+const (
+	// ArtifactTypeGitSnapshots is the artifact type for an image index listing
+	// the Git manifests tagged as snapshots of a reference.
+	ArtifactTypeGitSnapshots = "application/vnd.ai.act3.git.snapshots.v1+json"
+
+	// AnnotationSnapshotRef is the key for the snapshot index annotation denoting the Git reference
+	// a snapshot was tagged for.
+	AnnotationSnapshotRef = "vnd.ai.act3.git.snapshot.ref"
+
+	// AnnotationSnapshotCommit is the key for the snapshot index annotation denoting the commit
+	// the Git reference pointed to when the snapshot was tagged.
+	AnnotationSnapshotCommit = "vnd.ai.act3.git.snapshot.commit"
+
+	// SnapshotIndexSuffix is appended to the tag of a Git manifest to form
+	// the tag of its snapshot index.
+	SnapshotIndexSuffix = "-snapshots"
+)
+
+// History OCI artifacts.
+const (
+	// ArtifactTypeGitHistory is the artifact type for an image index listing
+	// the Git manifests pushed to a remote whose tag is never moved, oldest
+	// first, referring to the tagged manifest as its subject.
+	ArtifactTypeGitHistory = "application/vnd.ai.act3.git.history.v1+json"
+
+	// AnnotationHistoryLength is the key for the history index annotation denoting
+	// the number of Git manifests it lists, the longest history being the latest.
+	AnnotationHistoryLength = "vnd.ai.act3.git.history.length"
+)
+
+// Lock OCI artifacts.
+const (
+	// ArtifactTypeGitLock is the artifact type for the advisory lock of a
+	// remote, tagged "<tag>-lock" while a client pushes to the remote.
+	ArtifactTypeGitLock = "application/vnd.ai.act3.git.lock.v1+json"
+
+	// AnnotationLockOwner is the key for the lock manifest annotation
+	// identifying the client holding the lock.
+	AnnotationLockOwner = "vnd.ai.act3.git.lock.owner"
+
+	// AnnotationLockExpires is the key for the lock manifest annotation
+	// denoting when the lock expires, in RFC 3339 format. Expired locks may be
+	// taken over by other clients.
+	AnnotationLockExpires = "vnd.ai.act3.git.lock.expires"
+)
+
+// Release OCI artifacts.
+const (
+	// ArtifactTypeGitRelease is the artifact type for an image index of a
+	// release, referencing a Git manifest and its LFS manifest, if any.
+	ArtifactTypeGitRelease = "application/vnd.ai.act3.git.release.v1+json"
+
+	// ArtifactTypeGitReleases is the artifact type for an image index listing
+	// the releases of a repository.
+	ArtifactTypeGitReleases = "application/vnd.ai.act3.git.releases.v1+json"
+)
+
+// Metadata OCI artifacts.
+const (
+	// ArtifactTypeGitMetadata is the artifact type for human-facing Git
+	// repository metadata, referring to a Git manifest as its subject.
+	ArtifactTypeGitMetadata = "application/vnd.ai.act3.git.metadata.v1+json"
+
+	// MediaTypeGitMetadata is the media type for a [Metadata] config.
+	MediaTypeGitMetadata = "application/vnd.ai.act3.git.metadata.config.v1+json"
+
+	// MediaTypeReadme is the media type for a repository README layer.
+	MediaTypeReadme = "text/markdown"
+)
+
+// Metadata is an OCI manifest config, containing human-facing information
+// about a Git repository.
+type Metadata struct {
+	// Description is a short summary of the repository.
+	Description string `json:"description,omitempty"`
+
+	// Website is the URL of the repository's homepage.
+	Website string `json:"website,omitempty"`
+
+	// Topics classify the repository.
+	Topics []string `json:"topics,omitempty"`
+
+	// Readme is the digest of the README layer, if any.
+	Readme digest.Digest `json:"readme,omitempty"`
+
+	// SubmoduleURLs map the URL prefixes of submodules, as in .gitmodules, to
+	// the prefixes replacing them, typically oci:// remotes, like Git's
+	// url.<base>.insteadOf. The longest matching prefix is replaced.
+	SubmoduleURLs map[string]string `json:"submoduleURLs,omitempty"`
+}
+
+// SubmoduleURL rewrites a submodule URL by the longest matching prefix of
+// SubmoduleURLs, returning url unchanged if none match.
+func (m Metadata) SubmoduleURL(url string) string {
+	var longest string
+	for prefix := range m.SubmoduleURLs {
+		if strings.HasPrefix(url, prefix) && len(prefix) > len(longest) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return url
+	}
+	return m.SubmoduleURLs[longest] + strings.TrimPrefix(url, longest)
+}
+
+// Signature OCI artifacts.
+const (
+	// ArtifactTypeSignature is the artifact type for a signature of a Git
+	// manifest, referring to the signed manifest as its subject.
+	ArtifactTypeSignature = "application/vnd.ai.act3.git.signature.v1+json"
+
+	// MediaTypeSignaturePayload is the media type for the signed payload
+	// layer of a signature, a [SignaturePayload].
+	MediaTypeSignaturePayload = "application/vnd.ai.act3.git.signature.payload.v1+json"
+
+	// AnnotationSignature is the key for the signature payload layer
+	// annotation containing the base64 encoded signature of the payload.
+	AnnotationSignature = "vnd.ai.act3.git.signature"
+)
+
+// SignaturePayload is the content signed by a Git manifest signature.
+type SignaturePayload struct {
+	// Subject is the digest of the signed Git manifest.
+	Subject digest.Digest `json:"subject"`
 }
 
 // LFS OCI artifacts.
@@ -46,4 +313,42 @@ const (
 
 	// MediaTypeLFSLayer is the media type used for Git LFS layers.
 	MediaTypeLFSLayer = "application/vnd.ai.act3.git-lfs.object.v1"
+	// MediaTypeLFSLayerEncrypted is the media type used for encrypted Git LFS
+	// layers.
+	MediaTypeLFSLayerEncrypted = MediaTypeLFSLayer + MediaTypeSuffixEncrypted
 )
+
+// Chunked LFS OCI layers.
+const (
+	// MediaTypeLFSRecipe is the media type for the [LFSRecipe] layer of an LFS
+	// file split into content-defined chunks, standing for the LFS file in an
+	// LFS manifest. Its chunks are layers of the same LFS manifest.
+	MediaTypeLFSRecipe = "application/vnd.ai.act3.git-lfs.recipe.v1+json"
+
+	// MediaTypeLFSChunk is the media type for a content-defined chunk of one
+	// or more LFS files, stored as a layer of an LFS manifest alongside the
+	// recipes listing it.
+	MediaTypeLFSChunk = "application/vnd.ai.act3.git-lfs.chunk.v1"
+
+	// AnnotationLFSSize is the key for the LFS recipe layer annotation
+	// denoting the size of its LFS file in bytes.
+	AnnotationLFSSize = "vnd.ai.act3.git.lfs.size"
+)
+
+// LFSRecipe lists the chunks whose concatenation, in order, is an LFS file.
+type LFSRecipe struct {
+	// Size is the size of the LFS file in bytes.
+	Size int64 `json:"size"`
+
+	// Chunks are the chunks of the LFS file, in order.
+	Chunks []LFSChunk `json:"chunks"`
+}
+
+// LFSChunk is a chunk of an LFS file listed by an [LFSRecipe].
+type LFSChunk struct {
+	// Digest is the digest of the chunk layer.
+	Digest digest.Digest `json:"digest"`
+
+	// Size is the size of the chunk in bytes.
+	Size int64 `json:"size"`
+}
@@ -18,6 +18,12 @@ const (
 	// CapabilityOption indicates a git remote helper is capable
 	// of handling push commands.
 	CapabilityPush Capability = "push"
+	// CapabilityCheckConnectivity indicates a git remote helper is capable
+	// of verifying a clone is self-contained and connected.
+	CapabilityCheckConnectivity Capability = "check-connectivity"
+	// CapabilityObjectFormat indicates a git remote helper is capable of
+	// reporting the hash algorithm of the remote repository.
+	CapabilityObjectFormat Capability = "object-format"
 )
 
 // CapabilitiesRequest is a command received from Git requesting a list of
@@ -13,6 +13,8 @@ import (
 	"github.com/act3-ai/gnoci/pkg/protocol/git"
 )
 
+//go:generate go tool mockgen -typed -package commsmock -destination ./commsmock/commsmock.gen.go github.com/act3-ai/gnoci/pkg/protocol/git/comms Communicator
+
 // Communicator provides handling of Git remote helper protocol
 // requests and responses.
 type Communicator interface {
@@ -51,6 +53,10 @@ type ResponseWriter interface {
 	// WritePushResponse lists the results of push actions. The response to one
 	// or more [git.PushRequest]s.
 	WritePushResponse(resp []git.PushResponse) error
+	// WriteConnectivityOK indicates the fetched objects are self-contained
+	// and connected, such that Git may skip its own connectivity check. It
+	// must be written before [ResponseWriter.WriteFetchResponse].
+	WriteConnectivityOK() error
 	// WriteFetchResponse indicates fetching has completed. The response to one
 	// or more [git.FetchRequest]s.
 	WriteFetchResponse() error
@@ -310,6 +316,16 @@ func (c *defaultCommunicator) WritePushResponse(resps []git.PushResponse) error
 	return nil
 }
 
+// WriteConnectivityOK indicates the fetched objects are self-contained and
+// connected, such that Git may skip its own connectivity check.
+func (c *defaultCommunicator) WriteConnectivityOK() error {
+	if _, err := fmt.Fprintln(c.out, git.ConnectivityOK); err != nil {
+		return fmt.Errorf("writing connectivity-ok: %w", err)
+	}
+
+	return nil
+}
+
 // WriteFetchResponse indicates fetching has completed. The response to one
 // or more [git.FetchRequest]s.
 func (c *defaultCommunicator) WriteFetchResponse() error {
@@ -903,6 +903,29 @@ func Test_defaultCommunicator_WritePushResponse(t *testing.T) {
 	})
 }
 
+func Test_defaultCommunicator_WriteConnectivityOK(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		in := new(bytes.Buffer)
+		out := new(bytes.Buffer)
+
+		comm := &defaultCommunicator{
+			in:  *bufio.NewScanner(in),
+			out: out,
+		}
+		revcomm := testutils.NewReverseCommunicator(out, in)
+
+		err := comm.WriteConnectivityOK()
+		assert.NoError(t, err)
+		err = comm.WriteFetchResponse()
+		assert.NoError(t, err)
+
+		err = revcomm.ReceiveConnectivityOK()
+		assert.NoError(t, err)
+		err = revcomm.ReceiveFetchResponse()
+		assert.NoError(t, err)
+	})
+}
+
 func Test_defaultCommunicator_WriteFetchResponse(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		in := new(bytes.Buffer)
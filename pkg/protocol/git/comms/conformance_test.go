@@ -0,0 +1,12 @@
+package comms_test
+
+import (
+	"testing"
+
+	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
+	"github.com/act3-ai/gnoci/pkg/protocol/git/commstest"
+)
+
+func TestConformance(t *testing.T) {
+	commstest.Run(t, comms.NewCommunicator)
+}
@@ -0,0 +1,170 @@
+package commstest
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+
+	"github.com/act3-ai/gnoci/pkg/protocol/git"
+)
+
+// Commits of the repository the transcripts were captured with.
+const (
+	// first is the commit of refs/tags/v1, and originally every reference.
+	first = "dc015d3b137eaa7d55f985daef7106df2f3f4e26"
+	// second is the commit refs/heads/master is first updated to.
+	second = "31d9042b6a1224811a5ac596918319f2d1315158"
+	// third is the commit refs/heads/master is updated to with a lease.
+	third = "f4d57561336d83254de0dbef90ce25b56c079d6c"
+)
+
+const (
+	master  plumbing.ReferenceName = "refs/heads/master"
+	feature plumbing.ReferenceName = "refs/heads/feature"
+	v1      plumbing.ReferenceName = "refs/tags/v1"
+)
+
+// Cases returns the conformance cases, one per golden transcript.
+func Cases() []Case {
+	return []Case{
+		{
+			Name: "list",
+			Steps: slices.Concat(handshake(), []Step{
+				LookAhead(git.List),
+				ParseList(false),
+				WriteList(
+					git.ListResponse{ObjectFormat: formatcfg.SHA1},
+					git.ListResponse{Reference: plumbing.HEAD, Symref: master},
+					git.ListResponse{Reference: master, Commit: second},
+					git.ListResponse{Reference: v1, Commit: first},
+				),
+				EndOfCommands(),
+			}),
+		},
+		{
+			Name: "clone",
+			Steps: slices.Concat(handshake(), []Step{
+				ParseList(false),
+				WriteList(
+					git.ListResponse{ObjectFormat: formatcfg.SHA1},
+					git.ListResponse{Reference: plumbing.HEAD, Symref: master},
+					git.ListResponse{Reference: master, Commit: second},
+					git.ListResponse{Reference: v1, Commit: first},
+				),
+				ParseOption(git.CheckConnectivity, "true"),
+				WriteOption(true),
+				ParseOption("cloning", "true"),
+				WriteOption(false),
+				LookAhead(git.Fetch),
+				ParseFetch(
+					fetchRequest(master, second),
+					fetchRequest(master, second),
+					fetchRequest(v1, first),
+				),
+				WriteConnectivityOK(),
+				WriteFetch(),
+				EndOfCommands(),
+			}),
+		},
+		{
+			Name: "push",
+			Steps: slices.Concat(handshake(), []Step{
+				ParseList(true),
+				WriteList(
+					git.ListResponse{ObjectFormat: formatcfg.SHA1},
+					git.ListResponse{Reference: feature, Commit: first},
+					git.ListResponse{Reference: master, Commit: first},
+					git.ListResponse{Reference: v1, Commit: first},
+				),
+				LookAhead(git.Push),
+				ParsePush(
+					git.PushRequest{Cmd: git.Push, Remote: feature},
+					git.PushRequest{Cmd: git.Push, Src: master, Remote: master},
+				),
+				WritePush(
+					git.PushResponse{Remote: feature},
+					git.PushResponse{Remote: master},
+				),
+				EndOfCommands(),
+			}),
+		},
+		{
+			Name: "push-lease",
+			Steps: slices.Concat(handshake(), []Step{
+				ParseList(true),
+				WriteList(
+					git.ListResponse{ObjectFormat: formatcfg.SHA1},
+					git.ListResponse{Reference: master, Commit: second},
+					git.ListResponse{Reference: v1, Commit: first},
+				),
+				LookAhead(git.Options),
+				ParseOption(git.CAS, master.String()+":"+second),
+				WriteOption(true),
+				ParsePush(git.PushRequest{Cmd: git.Push, Src: master, Remote: master}),
+				WritePush(git.PushResponse{Remote: master}),
+				EndOfCommands(),
+			}),
+		},
+		{
+			Name: "push-atomic",
+			Steps: slices.Concat(handshake(), []Step{
+				ParseList(true),
+				WriteList(
+					git.ListResponse{ObjectFormat: formatcfg.SHA1},
+					git.ListResponse{Reference: master, Commit: third},
+					git.ListResponse{Reference: v1, Commit: first},
+				),
+				ParseOption(git.DryRun, "true"),
+				WriteOption(true),
+				ParseOption(git.Atomic, "true"),
+				WriteOption(true),
+				ParsePush(git.PushRequest{Cmd: git.Push, Src: master, Remote: "refs/heads/other"}),
+				WritePush(git.PushResponse{Remote: "refs/heads/other"}),
+				EndOfCommands(),
+			}),
+		},
+		{
+			Name: "push-rejected",
+			Steps: slices.Concat(handshake(), []Step{
+				ParseList(true),
+				WriteList(
+					git.ListResponse{ObjectFormat: formatcfg.SHA1},
+					git.ListResponse{Reference: master, Commit: third},
+					git.ListResponse{Reference: v1, Commit: first},
+				),
+				ParsePush(git.PushRequest{Cmd: git.Push, Force: true, Src: "refs/heads/old", Remote: master}),
+				WritePush(git.PushResponse{Remote: master, Error: errors.New("denied by push policy: force pushing master")}),
+				EndOfCommands(),
+			}),
+		},
+	}
+}
+
+// handshake returns the steps of the capabilities and options Git sends
+// before every command.
+func handshake() []Step {
+	return []Step{
+		LookAhead(git.Capabilities),
+		ParseCapabilities(),
+		WriteCapabilities(
+			git.CapabilityOption,
+			git.CapabilityFetch,
+			git.CapabilityPush,
+			git.CapabilityCheckConnectivity,
+			git.CapabilityObjectFormat,
+		),
+		ParseOption(git.Progress, "false"),
+		WriteOption(true),
+		ParseOption(git.Verbosity, "1"),
+		WriteOption(true),
+		ParseOption(git.ObjectFormat, "true"),
+		WriteOption(true),
+	}
+}
+
+// fetchRequest returns the request fetching commit for name.
+func fetchRequest(name plumbing.ReferenceName, commit string) git.FetchRequest {
+	return git.FetchRequest{Cmd: git.Fetch, Ref: plumbing.NewHashReference(name, plumbing.NewHash(commit))}
+}
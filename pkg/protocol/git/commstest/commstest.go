@@ -0,0 +1,201 @@
+// Package commstest provides a conformance suite for implementations of
+// [comms.Communicator], validating them against golden transcripts of the
+// Git remote helpers protocol captured from real git invocations.
+//
+// Implementations of alternative transports may run the suite in their own
+// tests:
+//
+//	func TestConformance(t *testing.T) {
+//		commstest.Run(t, mytransport.NewCommunicator)
+//	}
+package commstest
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/hash"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/gnoci/pkg/protocol/git"
+	"github.com/act3-ai/gnoci/pkg/protocol/git/comms"
+)
+
+// NewCommunicatorFunc initializes a [comms.Communicator] reading requests
+// from in and writing responses to out.
+type NewCommunicatorFunc func(in io.Reader, out io.Writer) comms.Communicator
+
+// Case drives a [comms.Communicator] through a golden transcript, as a remote
+// helper would.
+type Case struct {
+	// Name is the name of the case and its transcript.
+	Name string
+	// Steps are performed in order.
+	Steps []Step
+}
+
+// Step parses a request or writes a response with a [comms.Communicator].
+type Step func(t testing.TB, comm comms.Communicator)
+
+// Run runs each of [Cases] as a subtest against communicators initialized by
+// newComm. Each case fails if a request is not parsed as expected, or if
+// the responses written differ from the transcript.
+func Run(t *testing.T, newComm NewCommunicatorFunc) {
+	t.Helper()
+
+	for _, c := range Cases() {
+		t.Run(c.Name, func(t *testing.T) {
+			RunCase(t, newComm, c)
+		})
+	}
+}
+
+// RunCase runs a single [Case] against a communicator initialized by newComm.
+// Transcripts are of SHA-1 repositories, cases are skipped when built with
+// the "sha256" tag.
+func RunCase(t *testing.T, newComm NewCommunicatorFunc, c Case) {
+	t.Helper()
+	if hash.CryptoType != crypto.SHA1 {
+		t.Skip("transcripts are of SHA-1 repositories")
+	}
+
+	tr, err := LoadTranscript(c.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(bytes.Buffer)
+	comm := newComm(bytes.NewBufferString(tr.Requests), out)
+	for _, step := range c.Steps {
+		step(t, comm)
+		if t.Failed() {
+			// later steps are unlikely to be meaningful
+			return
+		}
+	}
+
+	assert.Equal(t, tr.Responses, out.String())
+}
+
+// LookAhead expects the next request to be cmd, leaving it to be parsed by
+// the following step.
+func LookAhead(cmd git.Command) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		got, err := comm.LookAhead()
+		assert.NoError(t, err)
+		assert.Equal(t, cmd, got)
+	}
+}
+
+// EndOfCommands expects the blank line Git sends after its final command,
+// followed by the end of input.
+func EndOfCommands() Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		_, err := comm.LookAhead()
+		assert.ErrorIs(t, err, git.ErrEmptyRequest)
+		_, err = comm.LookAhead()
+		assert.ErrorIs(t, err, git.ErrEndOfInput)
+	}
+}
+
+// ParseCapabilities expects a [git.CapabilitiesRequest].
+func ParseCapabilities() Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		req, err := comm.ParseCapabilitiesRequest()
+		assert.NoError(t, err)
+		assert.Equal(t, &git.CapabilitiesRequest{Cmd: git.Capabilities}, req)
+	}
+}
+
+// ParseOption expects a [git.OptionRequest] setting opt to value.
+func ParseOption(opt git.Option, value string) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		req, err := comm.ParseOptionRequest()
+		assert.NoError(t, err)
+		assert.Equal(t, &git.OptionRequest{Cmd: git.Options, Opt: opt, Value: value}, req)
+	}
+}
+
+// ParseList expects a [git.ListRequest].
+func ParseList(forPush bool) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		req, err := comm.ParseListRequest()
+		assert.NoError(t, err)
+		assert.Equal(t, &git.ListRequest{Cmd: git.List, ForPush: forPush}, req)
+	}
+}
+
+// ParseFetch expects a batch of [git.FetchRequest]s.
+func ParseFetch(reqs ...git.FetchRequest) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		got, err := comm.ParseFetchRequestBatch()
+		assert.NoError(t, err)
+		assert.Equal(t, reqs, got)
+	}
+}
+
+// ParsePush expects a batch of [git.PushRequest]s.
+func ParsePush(reqs ...git.PushRequest) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		got, err := comm.ParsePushRequestBatch()
+		assert.NoError(t, err)
+		assert.Equal(t, reqs, got)
+	}
+}
+
+// WriteCapabilities writes a capabilities response.
+func WriteCapabilities(capabilities ...git.Capability) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		assert.NoError(t, comm.WriteCapabilitiesResponse(capabilities))
+	}
+}
+
+// WriteOption writes an option response.
+func WriteOption(supported bool) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		assert.NoError(t, comm.WriteOptionResponse(supported))
+	}
+}
+
+// WriteList writes a list response.
+func WriteList(resps ...git.ListResponse) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		assert.NoError(t, comm.WriteListResponse(resps))
+	}
+}
+
+// WritePush writes a push response.
+func WritePush(resps ...git.PushResponse) Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		assert.NoError(t, comm.WritePushResponse(resps))
+	}
+}
+
+// WriteConnectivityOK writes connectivity-ok, ahead of a fetch response.
+func WriteConnectivityOK() Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		assert.NoError(t, comm.WriteConnectivityOK())
+	}
+}
+
+// WriteFetch writes a fetch response.
+func WriteFetch() Step {
+	return func(t testing.TB, comm comms.Communicator) {
+		t.Helper()
+		assert.NoError(t, comm.WriteFetchResponse())
+	}
+}
@@ -0,0 +1,71 @@
+package commstest
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// transcripts are captured from real git invocations, with git-remote-oci as
+// the remote helper.
+//
+//go:embed transcripts/*.txt
+var transcripts embed.FS
+
+// transcriptsDir is the directory, within transcripts, of the golden files.
+const transcriptsDir = "transcripts"
+
+// Transcript is a golden exchange between Git and a remote helper.
+//
+// Transcripts are recorded in text files, one line of the exchange per line
+// in the order it was sent. Lines sent by Git are prefixed with "> ", lines
+// written by the remote helper with "< ", and blank lines are a bare ">" or
+// "<". Lines beginning with "#" are comments.
+type Transcript struct {
+	// Requests are the lines sent by Git.
+	Requests string
+	// Responses are the lines written by the remote helper.
+	Responses string
+}
+
+// LoadTranscript loads the golden transcript name, e.g. "push".
+func LoadTranscript(name string) (Transcript, error) {
+	f, err := transcripts.Open(path.Join(transcriptsDir, name+".txt"))
+	if err != nil {
+		return Transcript{}, fmt.Errorf("opening transcript %s: %w", name, err)
+	}
+	defer f.Close()
+
+	tr, err := ParseTranscript(f)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("parsing transcript %s: %w", name, err)
+	}
+	return tr, nil
+}
+
+// ParseTranscript parses a transcript from r.
+func ParseTranscript(r io.Reader) (Transcript, error) {
+	var requests, responses strings.Builder
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case line == ">" || strings.HasPrefix(line, "> "):
+			requests.WriteString(strings.TrimPrefix(line[1:], " ") + "\n")
+		case line == "<" || strings.HasPrefix(line, "< "):
+			responses.WriteString(strings.TrimPrefix(line[1:], " ") + "\n")
+		default:
+			return Transcript{}, fmt.Errorf("line %d: missing direction prefix: %q", n, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Transcript{}, fmt.Errorf("reading transcript: %w", err)
+	}
+
+	return Transcript{Requests: requests.String(), Responses: responses.String()}, nil
+}
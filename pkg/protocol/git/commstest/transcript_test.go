@@ -0,0 +1,40 @@
+package commstest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTranscript(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		in := "# git ls-remote\n> capabilities\n< option\n<\n\n> list\n<\n>\n"
+
+		tr, err := ParseTranscript(strings.NewReader(in))
+		assert.NoError(t, err)
+		assert.Equal(t, "capabilities\nlist\n\n", tr.Requests)
+		assert.Equal(t, "option\n\n\n", tr.Responses)
+	})
+
+	t.Run("Missing Direction", func(t *testing.T) {
+		_, err := ParseTranscript(strings.NewReader("> capabilities\noption\n"))
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
+
+func TestLoadTranscript(t *testing.T) {
+	t.Run("Every Case", func(t *testing.T) {
+		for _, c := range Cases() {
+			tr, err := LoadTranscript(c.Name)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tr.Requests)
+			assert.NotEmpty(t, tr.Responses)
+		}
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		_, err := LoadTranscript("foo")
+		assert.Error(t, err)
+	})
+}
@@ -6,6 +6,11 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 )
 
+// ConnectivityOK is written in response to a batch of [FetchRequest]s,
+// before the terminating blank line, if the fetched objects are
+// self-contained and connected.
+const ConnectivityOK = "connectivity-ok"
+
 // FetchRequest is a command received from Git requesting a fetch operation.
 //
 // https://git-scm.com/docs/gitremote-helpers#Documentation/gitremote-helpers.txt-fetchsha1name.
@@ -31,6 +36,9 @@ func (r *FetchRequest) Parse(fields []string) error {
 
 	hash := fields[1]
 	name := fields[2]
+	if !plumbing.IsHash(hash) {
+		return fmt.Errorf("%w: fetch object %q is not a hash", ErrBadRequest, hash)
+	}
 	r.Ref = plumbing.NewHashReference(
 		plumbing.ReferenceName(name),
 		plumbing.NewHash(hash),
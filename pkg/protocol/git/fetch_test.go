@@ -38,6 +38,14 @@ func TestFetchRequest_Parse(t *testing.T) {
 		assert.ErrorIs(t, err, ErrBadRequest)
 	})
 
+	t.Run("Invalid Hash", func(t *testing.T) {
+		fields := []string{string(Fetch), "dc015d3b", "foo"}
+
+		var req FetchRequest
+		err := req.Parse(fields)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+
 	t.Run("Unexpected Request", func(t *testing.T) {
 		hash := plumbing.ComputeHash(plumbing.CommitObject, []byte("foobar"))
 		refName := "foo"
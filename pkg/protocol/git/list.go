@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
 )
 
 // ListRequest is a command received from Git requesting a list of references.
@@ -48,13 +49,26 @@ func (r *ListRequest) String() string {
 	return str
 }
 
-// ListResponse is a reference and it's commit.
+// ListResponse is a reference and it's commit, or the reference a symbolic
+// reference points to.
 type ListResponse struct {
 	Reference plumbing.ReferenceName
 	Commit    string
+	// Symref is the target of a symbolic reference, e.g. HEAD. Takes
+	// precedence over Commit.
+	Symref plumbing.ReferenceName
+	// ObjectFormat, if set, is written as the object-format attribute rather
+	// than a reference. Takes precedence over all other fields.
+	ObjectFormat formatcfg.ObjectFormat
 }
 
 // String condenses the response into a format readable by Git.
 func (r *ListResponse) String() string {
+	if r.ObjectFormat != "" {
+		return fmt.Sprintf(":object-format %s", r.ObjectFormat)
+	}
+	if r.Symref != "" {
+		return fmt.Sprintf("@%s %s", r.Symref.String(), r.Reference.String())
+	}
 	return fmt.Sprintf("%s %s", r.Commit, r.Reference.String())
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -86,4 +87,23 @@ func TestListResponse_String(t *testing.T) {
 		str := resp.String()
 		assert.Equal(t, fmt.Sprintf("%s %s", hash.String(), refName.String()), str)
 	})
+
+	t.Run("Symref", func(t *testing.T) {
+		resp := ListResponse{
+			Reference: plumbing.HEAD,
+			Symref:    plumbing.Main,
+		}
+
+		str := resp.String()
+		assert.Equal(t, "@refs/heads/main HEAD", str)
+	})
+
+	t.Run("Object Format", func(t *testing.T) {
+		resp := ListResponse{
+			ObjectFormat: formatcfg.SHA256,
+		}
+
+		str := resp.String()
+		assert.Equal(t, ":object-format sha256", str)
+	})
 }
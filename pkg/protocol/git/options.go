@@ -13,6 +13,19 @@ type Option string
 // Supported Git options.
 const (
 	Verbosity Option = "verbosity"
+	Depth     Option = "depth"
+	Progress  Option = "progress"
+	DryRun    Option = "dry-run"
+	Atomic    Option = "atomic"
+
+	CheckConnectivity Option = "check-connectivity"
+	ObjectFormat      Option = "object-format"
+	// FollowTags requests fetches to include the annotated tags pointing
+	// to fetched commits.
+	FollowTags Option = "followtags"
+	// CAS is sent by push --force-with-lease, once per leased reference,
+	// before the batch of push commands.
+	CAS Option = "cas"
 )
 
 const (
@@ -38,6 +51,10 @@ type OptionRequest struct {
 //
 // Implements [Parsable].
 func (r *OptionRequest) Parse(fields []string) error {
+	if len(fields) == 2 && Option(fields[1]) == ObjectFormat {
+		// git sends object-format without a value, implying "true"
+		fields = append(fields, "true")
+	}
 	if len(fields) < 3 {
 		return fmt.Errorf("%w: invalid fields for options request: got %v", ErrBadRequest, fields)
 	}
@@ -51,13 +68,54 @@ func (r *OptionRequest) Parse(fields []string) error {
 	}
 	r.Cmd = cmd
 
-	// TODO: switch statement when more options are added
-	if opt == Verbosity {
+	switch opt {
+	case Verbosity:
 		// ensure valid int
 		_, err := strconv.Atoi(val)
 		if err != nil {
 			return fmt.Errorf("unable to convert verbosity value to int: %w", err)
 		}
+	case Depth:
+		// ensure valid, positive, int
+		depth, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("unable to convert depth value to int: %w", err)
+		}
+		if depth < 1 {
+			return fmt.Errorf("%w: depth must be a positive integer, got %d", ErrBadRequest, depth)
+		}
+	case Progress:
+		// ensure valid bool, git only sends "true" or "false"
+		if val != "true" && val != "false" {
+			return fmt.Errorf("%w: progress must be true or false, got %q", ErrBadRequest, val)
+		}
+	case DryRun:
+		// ensure valid bool, git only sends "true" or "false"
+		if val != "true" && val != "false" {
+			return fmt.Errorf("%w: dry-run must be true or false, got %q", ErrBadRequest, val)
+		}
+	case Atomic:
+		// ensure valid bool, git only sends "true" or "false"
+		if val != "true" && val != "false" {
+			return fmt.Errorf("%w: atomic must be true or false, got %q", ErrBadRequest, val)
+		}
+	case CheckConnectivity:
+		// ensure valid bool, git only sends "true" or "false"
+		if val != "true" && val != "false" {
+			return fmt.Errorf("%w: check-connectivity must be true or false, got %q", ErrBadRequest, val)
+		}
+	case FollowTags:
+		// ensure valid bool, git only sends "true" or "false"
+		if val != "true" && val != "false" {
+			return fmt.Errorf("%w: followtags must be true or false, got %q", ErrBadRequest, val)
+		}
+	case ObjectFormat:
+		// "true" requests the object-format attribute, otherwise the value
+		// is a hash algorithm, answered as supported or not by the helper
+	case CAS:
+		if _, _, err := ParseLease(val); err != nil {
+			return err
+		}
 	}
 	r.Opt = opt
 
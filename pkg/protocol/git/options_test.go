@@ -26,6 +26,135 @@ func TestOptionRequest_Parse(t *testing.T) {
 		assert.Equal(t, expectedReq, req)
 	})
 
+	t.Run("Success - Depth", func(t *testing.T) {
+		opt := Depth
+		value := "1"
+
+		expectedReq := OptionRequest{
+			Cmd:   Options,
+			Opt:   opt,
+			Value: value,
+		}
+		fields := []string{string(Options), string(opt), value}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReq, req)
+	})
+
+	t.Run("Depth Not Positive", func(t *testing.T) {
+		fields := []string{string(Options), string(Depth), "0"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+
+	t.Run("Depth Invalid Value", func(t *testing.T) {
+		fields := []string{string(Options), string(Depth), "foo"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.Error(t, err)
+	})
+
+	t.Run("Success - Progress", func(t *testing.T) {
+		opt := Progress
+		value := "true"
+
+		expectedReq := OptionRequest{
+			Cmd:   Options,
+			Opt:   opt,
+			Value: value,
+		}
+		fields := []string{string(Options), string(opt), value}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReq, req)
+	})
+
+	t.Run("Progress Invalid Value", func(t *testing.T) {
+		fields := []string{string(Options), string(Progress), "1"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+
+	t.Run("Success - Dry Run", func(t *testing.T) {
+		opt := DryRun
+		value := "true"
+
+		expectedReq := OptionRequest{
+			Cmd:   Options,
+			Opt:   opt,
+			Value: value,
+		}
+		fields := []string{string(Options), string(opt), value}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReq, req)
+	})
+
+	t.Run("Dry Run Invalid Value", func(t *testing.T) {
+		fields := []string{string(Options), string(DryRun), "yes"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+
+	t.Run("Atomic Invalid Value", func(t *testing.T) {
+		fields := []string{string(Options), string(Atomic), "1"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+
+	t.Run("Check Connectivity Invalid Value", func(t *testing.T) {
+		fields := []string{string(Options), string(CheckConnectivity), "yes"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+
+	t.Run("Follow Tags Invalid Value", func(t *testing.T) {
+		fields := []string{string(Options), string(FollowTags), "1"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+
+	t.Run("Object Format Algorithm", func(t *testing.T) {
+		fields := []string{string(Options), string(ObjectFormat), "sha256"}
+
+		expectedReq := OptionRequest{Cmd: Options, Opt: ObjectFormat, Value: "sha256"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReq, req)
+	})
+
+	t.Run("Object Format Without Value", func(t *testing.T) {
+		fields := []string{string(Options), string(ObjectFormat)}
+
+		expectedReq := OptionRequest{Cmd: Options, Opt: ObjectFormat, Value: "true"}
+
+		var req OptionRequest
+		err := req.Parse(fields)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReq, req)
+	})
+
 	t.Run("Insufficient Fields", func(t *testing.T) {
 		fields := []string{string(Options), string(Verbosity)}
 
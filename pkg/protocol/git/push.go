@@ -2,6 +2,7 @@ package git
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing"
@@ -15,6 +16,41 @@ type PushRequest struct {
 	Force  bool
 	Src    plumbing.ReferenceName
 	Remote plumbing.ReferenceName
+
+	// Lease indicates the update is conditional on the remote reference
+	// pointing to Expected, set by push --force-with-lease with a preceding
+	// [CAS] option rather than the push command itself.
+	Lease bool
+	// Expected is the commit the remote reference is expected to point to
+	// if Lease is set, the zero hash if it is expected not to exist.
+	Expected plumbing.Hash
+}
+
+// ParseLease decodes the value of a [CAS] option, "<refname>:<expected>",
+// returning the remote reference and the commit it is expected to point to.
+// An empty or zero expected value indicates the reference must not exist.
+func ParseLease(value string) (plumbing.ReferenceName, plumbing.Hash, error) {
+	if strings.HasPrefix(value, `"`) {
+		// quoted by Git if the value contains special characters
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("%w: unquoting cas value %s: %w", ErrBadRequest, value, err)
+		}
+		value = unquoted
+	}
+
+	name, expected, ok := strings.Cut(value, ":")
+	if !ok || name == "" {
+		return "", plumbing.ZeroHash, fmt.Errorf("%w: cas value %q, expected <refname>:<expected>", ErrBadRequest, value)
+	}
+	if expected == "" {
+		return plumbing.ReferenceName(name), plumbing.ZeroHash, nil
+	}
+
+	if !plumbing.IsHash(expected) {
+		return "", plumbing.ZeroHash, fmt.Errorf("%w: cas expected value %q is not a hash", ErrBadRequest, expected)
+	}
+	return plumbing.ReferenceName(name), plumbing.NewHash(expected), nil
 }
 
 // Parse decodes request fields ensuring the [PushRequest] is of the correct type, is supported,
@@ -127,3 +127,34 @@ func TestPushResponse_String(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("error %s %s", remote, err.Error()), str)
 	})
 }
+
+func TestParseLease(t *testing.T) {
+	hash := plumbing.ComputeHash(plumbing.CommitObject, []byte("foo"))
+
+	tests := []struct {
+		name         string
+		value        string
+		wantName     plumbing.ReferenceName
+		wantExpected plumbing.Hash
+		wantErr      bool
+	}{
+		{name: "Expected Commit", value: "refs/heads/main:" + hash.String(), wantName: "refs/heads/main", wantExpected: hash},
+		{name: "Expected Missing", value: "refs/heads/main:" + plumbing.ZeroHash.String(), wantName: "refs/heads/main", wantExpected: plumbing.ZeroHash},
+		{name: "Empty Expected", value: "refs/heads/main:", wantName: "refs/heads/main", wantExpected: plumbing.ZeroHash},
+		{name: "Quoted", value: `"refs/heads/main:` + hash.String() + `"`, wantName: "refs/heads/main", wantExpected: hash},
+		{name: "Missing Separator", value: "refs/heads/main", wantErr: true},
+		{name: "Invalid Hash", value: "refs/heads/main:foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, expected, err := ParseLease(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrBadRequest)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantExpected, expected)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 
 	"github.com/act3-ai/gnoci/pkg/protocol/lfs"
 )
@@ -30,7 +31,8 @@ type RequestHandler interface {
 	ReceiveTransferRequest(ctx context.Context) (*lfs.TransferRequest, error)
 }
 
-// ResponseHandler sends git-lfs transfer protocol responses.
+// ResponseHandler sends git-lfs transfer protocol responses. Responses may be
+// written concurrently, e.g. the progress of concurrent transfers.
 type ResponseHandler interface {
 	// WriteInitResponse responds to a [lfs.TransferRequest].
 	// Returns err joined with any response handling errors.
@@ -56,9 +58,12 @@ func NewCommunicator(in io.Reader, out io.Writer) Communicator {
 	}
 }
 
-// defaultCommunicator is the default implementation of [Communicator].
+// defaultCommunicator is the default implementation of [Communicator]. Writes
+// are safe for concurrent use, e.g. progress of concurrent transfers.
 type defaultCommunicator struct {
-	in  *bufio.Scanner
+	in *bufio.Scanner
+
+	mu  sync.Mutex
 	out io.Writer
 }
 
@@ -121,7 +126,7 @@ func (c *defaultCommunicator) WriteInitResponse(ctx context.Context, initErr err
 		}
 	}
 
-	if _, err := c.out.Write(withNewline(raw)); err != nil {
+	if _, err := c.write(withNewline(raw)); err != nil {
 		return errors.Join(initErr, fmt.Errorf("writing init response: %w", err))
 	}
 
@@ -153,7 +158,7 @@ func (c *defaultCommunicator) WriteProgress(ctx context.Context, oid string, soF
 		return fmt.Errorf("encoding progress response: %w", err)
 	}
 
-	if _, err := c.out.Write(withNewline(raw)); err != nil {
+	if _, err := c.write(withNewline(raw)); err != nil {
 		return fmt.Errorf("writing progress response: %w", err)
 	}
 
@@ -187,7 +192,7 @@ func (c *defaultCommunicator) WriteTransferUploadResponse(ctx context.Context, o
 		return errors.Join(uploadErr, fmt.Errorf("encoding transfer response: %w", err))
 	}
 
-	if _, err := c.out.Write(withNewline(raw)); err != nil {
+	if _, err := c.write(withNewline(raw)); err != nil {
 		return errors.Join(uploadErr, fmt.Errorf("writing transfer response: %w", err))
 	}
 
@@ -222,13 +227,20 @@ func (c *defaultCommunicator) WriteTransferDownloadResponse(ctx context.Context,
 		return errors.Join(downloadErr, fmt.Errorf("encoding transfer response: %w", err))
 	}
 
-	if _, err := c.out.Write(withNewline(raw)); err != nil {
+	if _, err := c.write(withNewline(raw)); err != nil {
 		return errors.Join(downloadErr, fmt.Errorf("writing transfer response: %w", err))
 	}
 
 	return downloadErr
 }
 
+// write writes a single message to git-lfs.
+func (c *defaultCommunicator) write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.out.Write(p) //nolint:wrapcheck
+}
+
 func (c *defaultCommunicator) readLine() ([]byte, error) {
 	ok := c.in.Scan()
 	switch {
@@ -0,0 +1,634 @@
+// Package registrytest provides an in-process OCI registry for tests. The
+// registry implements the OCI distribution spec, including chunked uploads,
+// cross-repository mounts, and the referrers API, such that the push, fetch,
+// and LFS flows of gnoci may be exercised with plain "go test".
+//
+// Reference: https://github.com/opencontainers/distribution-spec/blob/main/spec.md
+package registrytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// Error codes of the OCI distribution spec.
+const (
+	codeBlobUnknown       = "BLOB_UNKNOWN"
+	codeBlobUploadUnknown = "BLOB_UPLOAD_UNKNOWN"
+	codeDigestInvalid     = "DIGEST_INVALID"
+	codeManifestInvalid   = "MANIFEST_INVALID"
+	codeManifestUnknown   = "MANIFEST_UNKNOWN"
+	codeNameUnknown       = "NAME_UNKNOWN"
+	codeUnsupported       = "UNSUPPORTED"
+)
+
+// routePattern matches the endpoints of a repository, whose name may contain
+// slashes.
+var routePattern = regexp.MustCompile(`^/v2/(.+?)/(blobs/uploads/[^/]*|blobs/[^/]+|manifests/[^/]+|tags/list|referrers/[^/]+)$`)
+
+// Option configures a [Registry].
+type Option func(*Registry)
+
+// WithoutReferrers responds to referrers API requests with 404 Not Found, and
+// does not acknowledge the subject of pushed manifests, as registries without
+// the referrers API do. Clients fall back to the referrers tag schema.
+func WithoutReferrers() Option {
+	return func(r *Registry) {
+		r.noReferrers = true
+	}
+}
+
+// WithoutDeletion responds to blob and manifest deletions with 405 Method Not
+// Allowed, as registries with deletion disabled do.
+func WithoutDeletion() Option {
+	return func(r *Registry) {
+		r.noDeletion = true
+	}
+}
+
+// Registry is an in-process OCI registry served over HTTP. Content is held in
+// memory, and discarded once the registry is closed.
+type Registry struct {
+	server *httptest.Server
+
+	noReferrers bool
+	noDeletion  bool
+
+	mu       sync.Mutex
+	repos    map[string]*repository
+	uploads  map[string]*upload
+	nextID   int
+	requests []string
+}
+
+// repository is the content of a single registry repository.
+type repository struct {
+	blobs     map[digest.Digest][]byte
+	manifests map[digest.Digest]*manifest
+	tags      map[string]digest.Digest
+}
+
+// manifest is a pushed manifest, with the fields needed to list it as a
+// referrer.
+type manifest struct {
+	mediaType    string
+	content      []byte
+	artifactType string
+	subject      digest.Digest
+	annotations  map[string]string
+}
+
+// upload is an in-progress blob upload session.
+type upload struct {
+	repo string
+	data []byte
+}
+
+// New starts a registry, closed once the test completes.
+func New(t testing.TB, opts ...Option) *Registry {
+	t.Helper()
+
+	r := NewUnstarted(opts...)
+	r.server = httptest.NewServer(r)
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+// NewUnstarted initializes a registry without serving it, e.g. to wrap it in
+// another [http.Handler].
+func NewUnstarted(opts ...Option) *Registry {
+	r := &Registry{
+		repos:   make(map[string]*repository),
+		uploads: make(map[string]*upload),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Host returns the host and port of the registry, e.g. "127.0.0.1:5000".
+func (r *Registry) Host() string {
+	if r.server == nil {
+		return ""
+	}
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// Reference returns the reference of a tag within a repository of the
+// registry, e.g. "127.0.0.1:5000/repo:tag".
+func (r *Registry) Reference(repo, tag string) string {
+	return fmt.Sprintf("%s/%s:%s", r.Host(), repo, tag)
+}
+
+// Repository returns a client of a repository of the registry.
+func (r *Registry) Repository(name string) *remote.Repository {
+	return &remote.Repository{
+		Client: r.server.Client(),
+		Reference: registry.Reference{
+			Registry:   r.Host(),
+			Repository: name,
+		},
+		PlainHTTP: true,
+	}
+}
+
+// Requests returns the requests served so far, e.g. "GET /v2/repo/tags/list".
+func (r *Registry) Requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.requests)
+}
+
+// Blobs returns the digests of the blobs within a repository.
+func (r *Registry) Blobs(repo string) []digest.Digest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rp, ok := r.repos[repo]
+	if !ok {
+		return nil
+	}
+	dgsts := make([]digest.Digest, 0, len(rp.blobs))
+	for dgst := range rp.blobs {
+		dgsts = append(dgsts, dgst)
+	}
+	slices.Sort(dgsts)
+	return dgsts
+}
+
+// ServeHTTP implements [http.Handler].
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+
+	switch req.URL.Path {
+	case "/v2/", "/v2":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+		return
+	case "/v2/_catalog":
+		r.serveCatalog(w, req)
+		return
+	}
+
+	m := routePattern.FindStringSubmatch(req.URL.Path)
+	if m == nil {
+		writeError(w, http.StatusNotFound, codeNameUnknown, "unknown endpoint")
+		return
+	}
+	name, route := m[1], m[2]
+
+	switch {
+	case strings.HasPrefix(route, "blobs/uploads/"):
+		r.serveUpload(w, req, name, strings.TrimPrefix(route, "blobs/uploads/"))
+	case strings.HasPrefix(route, "blobs/"):
+		r.serveBlob(w, req, name, strings.TrimPrefix(route, "blobs/"))
+	case strings.HasPrefix(route, "manifests/"):
+		r.serveManifest(w, req, name, strings.TrimPrefix(route, "manifests/"))
+	case route == "tags/list":
+		r.serveTags(w, req, name)
+	case strings.HasPrefix(route, "referrers/"):
+		r.serveReferrers(w, req, name, strings.TrimPrefix(route, "referrers/"))
+	}
+}
+
+// repo returns a repository, creating it if create is true.
+func (r *Registry) repo(name string, create bool) *repository {
+	rp, ok := r.repos[name]
+	if !ok && create {
+		rp = &repository{
+			blobs:     make(map[digest.Digest][]byte),
+			manifests: make(map[digest.Digest]*manifest),
+			tags:      make(map[string]digest.Digest),
+		}
+		r.repos[name] = rp
+	}
+	return rp
+}
+
+// serveCatalog lists the repositories of the registry.
+func (r *Registry) serveCatalog(w http.ResponseWriter, req *http.Request) {
+	names := make([]string, 0, len(r.repos))
+	for name := range r.repos {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	page, next := paginate(names, req.URL.Query())
+	if next != "" {
+		setNextLink(w, req, next)
+	}
+	writeJSON(w, http.StatusOK, "application/json", map[string][]string{"repositories": page})
+}
+
+// serveBlob serves requests for an existing blob.
+func (r *Registry) serveBlob(w http.ResponseWriter, req *http.Request, name, ref string) {
+	dgst, err := digest.Parse(ref)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeDigestInvalid, err.Error())
+		return
+	}
+	rp := r.repo(name, false)
+	if rp == nil {
+		writeError(w, http.StatusNotFound, codeBlobUnknown, dgst.String())
+		return
+	}
+	blob, ok := rp.blobs[dgst]
+	if !ok {
+		writeError(w, http.StatusNotFound, codeBlobUnknown, dgst.String())
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		// supports range requests, resuming downloads
+		http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(blob))
+	case http.MethodDelete:
+		if r.noDeletion {
+			writeError(w, http.StatusMethodNotAllowed, codeUnsupported, "deletion disabled")
+			return
+		}
+		delete(rp.blobs, dgst)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveUpload serves the blob upload API, monolithic, chunked, or mounted.
+func (r *Registry) serveUpload(w http.ResponseWriter, req *http.Request, name, id string) {
+	query := req.URL.Query()
+
+	if id == "" {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if mount, from := query.Get("mount"), query.Get("from"); mount != "" && from != "" {
+			if r.mount(name, from, digest.Digest(mount)) {
+				w.Header().Set("Location", blobLocation(name, digest.Digest(mount)))
+				w.Header().Set("Docker-Content-Digest", mount)
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			// fall back to an upload session, as registries do
+		}
+		if dgst := query.Get("digest"); dgst != "" {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, codeBlobUploadUnknown, err.Error())
+				return
+			}
+			r.completeUpload(w, name, dgst, body)
+			return
+		}
+
+		r.nextID++
+		id := strconv.Itoa(r.nextID)
+		r.uploads[id] = &upload{repo: name}
+		w.Header().Set("Location", uploadLocation(name, id))
+		w.Header().Set("Docker-Upload-UUID", id)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	u, ok := r.uploads[id]
+	if !ok || u.repo != name {
+		writeError(w, http.StatusNotFound, codeBlobUploadUnknown, id)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		setUploadRange(w, u)
+		w.Header().Set("Location", uploadLocation(name, id))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPatch:
+		if rng := req.Header.Get("Content-Range"); rng != "" {
+			start, _, _ := strings.Cut(rng, "-")
+			if offset, err := strconv.Atoi(start); err != nil || offset != len(u.data) {
+				setUploadRange(w, u)
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+		}
+		body, err := io.ReadAll(req.Body)
+		u.data = append(u.data, body...)
+		if err != nil {
+			// received part of the chunk
+			writeError(w, http.StatusBadRequest, codeBlobUploadUnknown, err.Error())
+			return
+		}
+		setUploadRange(w, u)
+		w.Header().Set("Location", uploadLocation(name, id))
+		w.Header().Set("Docker-Upload-UUID", id)
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeBlobUploadUnknown, err.Error())
+			return
+		}
+		delete(r.uploads, id)
+		r.completeUpload(w, name, query.Get("digest"), append(u.data, body...))
+	case http.MethodDelete:
+		delete(r.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// completeUpload stores the content of a finished upload, if it matches its
+// expected digest.
+func (r *Registry) completeUpload(w http.ResponseWriter, name, expected string, data []byte) {
+	dgst, err := digest.Parse(expected)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeDigestInvalid, err.Error())
+		return
+	}
+	if !dgst.Algorithm().Available() || dgst.Algorithm().FromBytes(data) != dgst {
+		writeError(w, http.StatusBadRequest, codeDigestInvalid, "content does not match digest "+dgst.String())
+		return
+	}
+
+	r.repo(name, true).blobs[dgst] = data
+	w.Header().Set("Location", blobLocation(name, dgst))
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	w.WriteHeader(http.StatusCreated)
+}
+
+// mount copies a blob from another repository, returning false if it does not
+// exist there.
+func (r *Registry) mount(name, from string, dgst digest.Digest) bool {
+	src := r.repo(from, false)
+	if src == nil {
+		return false
+	}
+	blob, ok := src.blobs[dgst]
+	if !ok {
+		return false
+	}
+	r.repo(name, true).blobs[dgst] = blob
+	return true
+}
+
+// serveManifest serves requests for manifests, by tag or digest.
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, name, ref string) {
+	if req.Method == http.MethodPut {
+		r.putManifest(w, req, name, ref)
+		return
+	}
+
+	rp := r.repo(name, false)
+	if rp == nil {
+		writeError(w, http.StatusNotFound, codeManifestUnknown, ref)
+		return
+	}
+	dgst, err := digest.Parse(ref)
+	if err != nil {
+		dgst = rp.tags[ref]
+	}
+	man, ok := rp.manifests[dgst]
+	if !ok {
+		writeError(w, http.StatusNotFound, codeManifestUnknown, ref)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		w.Header().Set("Content-Type", man.mediaType)
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(man.content)))
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(man.content)
+		}
+	case http.MethodDelete:
+		if r.noDeletion {
+			writeError(w, http.StatusMethodNotAllowed, codeUnsupported, "deletion disabled")
+			return
+		}
+		if ref != dgst.String() {
+			// deleting a tag leaves the manifest
+			delete(rp.tags, ref)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		delete(rp.manifests, dgst)
+		for tag, tagged := range rp.tags {
+			if tagged == dgst {
+				delete(rp.tags, tag)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// putManifest stores a manifest, tagging it if referenced by tag.
+func (r *Registry) putManifest(w http.ResponseWriter, req *http.Request, name, ref string) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeManifestInvalid, err.Error())
+		return
+	}
+
+	dgst := digest.FromBytes(body)
+	if expected, err := digest.Parse(ref); err == nil {
+		if !expected.Algorithm().Available() || expected.Algorithm().FromBytes(body) != expected {
+			writeError(w, http.StatusBadRequest, codeDigestInvalid, "content does not match digest "+ref)
+			return
+		}
+		dgst = expected
+	}
+
+	// fields of both image manifests and image indexes
+	var fields struct {
+		ArtifactType string              `json:"artifactType"`
+		Config       *ocispec.Descriptor `json:"config"`
+		Subject      *ocispec.Descriptor `json:"subject"`
+		Annotations  map[string]string   `json:"annotations"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		writeError(w, http.StatusBadRequest, codeManifestInvalid, err.Error())
+		return
+	}
+	man := &manifest{
+		mediaType:    req.Header.Get("Content-Type"),
+		content:      body,
+		artifactType: fields.ArtifactType,
+		annotations:  fields.Annotations,
+	}
+	if man.artifactType == "" && fields.Config != nil {
+		man.artifactType = fields.Config.MediaType
+	}
+
+	rp := r.repo(name, true)
+	rp.manifests[dgst] = man
+	if ref != dgst.String() {
+		rp.tags[ref] = dgst
+	}
+
+	if fields.Subject != nil && !r.noReferrers {
+		man.subject = fields.Subject.Digest
+		w.Header().Set("OCI-Subject", fields.Subject.Digest.String())
+	}
+	w.Header().Set("Location", fmt.Sprintf("/v2/%s/manifests/%s", name, dgst))
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	w.WriteHeader(http.StatusCreated)
+}
+
+// serveTags lists the tags of a repository.
+func (r *Registry) serveTags(w http.ResponseWriter, req *http.Request, name string) {
+	rp := r.repo(name, false)
+	if rp == nil {
+		writeError(w, http.StatusNotFound, codeNameUnknown, name)
+		return
+	}
+
+	tags := make([]string, 0, len(rp.tags))
+	for tag := range rp.tags {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	page, next := paginate(tags, req.URL.Query())
+	if next != "" {
+		setNextLink(w, req, next)
+	}
+	writeJSON(w, http.StatusOK, "application/json", map[string]any{"name": name, "tags": page})
+}
+
+// serveReferrers lists the manifests referring to a subject, optionally
+// filtered by artifact type.
+func (r *Registry) serveReferrers(w http.ResponseWriter, req *http.Request, name, ref string) {
+	if r.noReferrers {
+		writeError(w, http.StatusNotFound, codeUnsupported, "referrers API disabled")
+		return
+	}
+	subject, err := digest.Parse(ref)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeDigestInvalid, err.Error())
+		return
+	}
+
+	artifactType := req.URL.Query().Get("artifactType")
+	referrers := []ocispec.Descriptor{}
+	if rp := r.repo(name, false); rp != nil {
+		for dgst, man := range rp.manifests {
+			if man.subject != subject || (artifactType != "" && man.artifactType != artifactType) {
+				continue
+			}
+			referrers = append(referrers, ocispec.Descriptor{
+				MediaType:    man.mediaType,
+				ArtifactType: man.artifactType,
+				Digest:       dgst,
+				Size:         int64(len(man.content)),
+				Annotations:  man.annotations,
+			})
+		}
+	}
+	slices.SortFunc(referrers, func(a, b ocispec.Descriptor) int {
+		return strings.Compare(a.Digest.String(), b.Digest.String())
+	})
+
+	if artifactType != "" {
+		w.Header().Set("OCI-Filters-Applied", "artifactType")
+	}
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: referrers,
+	}
+	writeJSON(w, http.StatusOK, ocispec.MediaTypeImageIndex, index)
+}
+
+// paginate returns the page of sorted names requested by the "n" and "last"
+// query parameters, and the last name of the page if more remain.
+func paginate(names []string, query url.Values) ([]string, string) {
+	if last := query.Get("last"); last != "" {
+		i, _ := slices.BinarySearch(names, last)
+		for i < len(names) && names[i] <= last {
+			i++
+		}
+		names = names[i:]
+	}
+	n, err := strconv.Atoi(query.Get("n"))
+	if err != nil || n <= 0 || n >= len(names) {
+		return names, ""
+	}
+	return names[:n], names[n-1]
+}
+
+// setNextLink links the next page of a paginated list.
+func setNextLink(w http.ResponseWriter, req *http.Request, last string) {
+	query := req.URL.Query()
+	query.Set("last", last)
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.URL.Path, query.Encode()))
+}
+
+// setUploadRange reports the bytes received by an upload session.
+func setUploadRange(w http.ResponseWriter, u *upload) {
+	if len(u.data) > 0 {
+		w.Header().Set("Range", fmt.Sprintf("0-%d", len(u.data)-1))
+	}
+}
+
+func blobLocation(name string, dgst digest.Digest) string {
+	return fmt.Sprintf("/v2/%s/blobs/%s", name, dgst)
+}
+
+func uploadLocation(name, id string) string {
+	return fmt.Sprintf("/v2/%s/blobs/uploads/%s", name, id)
+}
+
+// writeJSON writes a JSON response body.
+func writeJSON(w http.ResponseWriter, status int, mediaType string, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// writeError writes an error response in the format of the OCI distribution
+// spec.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	type errorInfo struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	writeJSON(w, status, "application/json", map[string][]errorInfo{
+		"errors": {{Code: code, Message: message}},
+	})
+}
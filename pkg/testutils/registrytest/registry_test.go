@@ -0,0 +1,252 @@
+package registrytest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// pushManifest pushes an image manifest of a single layer, referring to
+// subject if non-nil.
+func pushManifest(t *testing.T, repo oras.GraphTarget, layer []byte, artifactType string, subject *ocispec.Descriptor) ocispec.Descriptor {
+	t.Helper()
+	ctx := context.Background()
+
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	assert.NoError(t, repo.Push(ctx, layerDesc, bytes.NewReader(layer)))
+
+	desc, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Layers:  []ocispec.Descriptor{layerDesc},
+		Subject: subject,
+	})
+	assert.NoError(t, err)
+	return desc
+}
+
+func TestRegistry_Blobs(t *testing.T) {
+	ctx := context.Background()
+	reg := New(t)
+	repo := reg.Repository("test/repo")
+
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	assert.NoError(t, repo.Push(ctx, desc, bytes.NewReader(blob)))
+
+	exists, err := repo.Exists(ctx, desc)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	got, err := content.FetchAll(ctx, repo, desc)
+	assert.NoError(t, err)
+	assert.Equal(t, blob, got)
+	assert.Equal(t, []digest.Digest{desc.Digest}, reg.Blobs("test/repo"))
+
+	t.Run("Range", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://"+reg.Host()+"/v2/test/repo/blobs/"+desc.Digest.String(), nil)
+		assert.NoError(t, err)
+		req.Header.Set("Range", "bytes=6-")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, "world", string(body))
+	})
+
+	t.Run("Digest Mismatch", func(t *testing.T) {
+		bad := desc
+		bad.Digest = digest.FromString("other")
+		err := repo.Push(ctx, bad, bytes.NewReader(blob))
+		assert.Error(t, err)
+	})
+
+	t.Run("Mount", func(t *testing.T) {
+		other := reg.Repository("other")
+		assert.NoError(t, other.Mount(ctx, desc, "test/repo", nil))
+		assert.Equal(t, []digest.Digest{desc.Digest}, reg.Blobs("other"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, repo.Delete(ctx, desc))
+		_, err := repo.Fetch(ctx, desc)
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+}
+
+func TestRegistry_ChunkedUpload(t *testing.T) {
+	reg := New(t)
+	base := "http://" + reg.Host()
+	blob := []byte("chunked upload")
+	dgst := digest.FromBytes(blob)
+
+	do := func(method, url, contentRange string, body []byte) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		assert.NoError(t, err)
+		if contentRange != "" {
+			req.Header.Set("Content-Range", contentRange)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		return resp
+	}
+
+	resp := do(http.MethodPost, base+"/v2/repo/blobs/uploads/", "", nil)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	location := base + resp.Header.Get("Location")
+
+	resp = do(http.MethodPatch, location, "0-6", blob[:7])
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "0-6", resp.Header.Get("Range"))
+
+	// chunks out of order are rejected
+	resp = do(http.MethodPatch, location, "3-9", blob[3:])
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+
+	resp = do(http.MethodGet, location, "", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "0-6", resp.Header.Get("Range"))
+
+	resp = do(http.MethodPatch, location, "7-13", blob[7:])
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp = do(http.MethodPut, location+"?digest="+dgst.String(), "", nil)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []digest.Digest{dgst}, reg.Blobs("repo"))
+
+	// the session is closed once complete
+	resp = do(http.MethodGet, location, "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRegistry_Manifests(t *testing.T) {
+	ctx := context.Background()
+	reg := New(t)
+	repo := reg.Repository("repo")
+
+	desc := pushManifest(t, repo, []byte("layer"), "application/vnd.test", nil)
+	assert.NoError(t, repo.Tag(ctx, desc, "v1"))
+	assert.NoError(t, repo.Tag(ctx, desc, "v2"))
+
+	resolved, err := repo.Resolve(ctx, "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, desc.Digest, resolved.Digest)
+	assert.Equal(t, ocispec.MediaTypeImageManifest, resolved.MediaType)
+
+	t.Run("Tags", func(t *testing.T) {
+		var tags []string
+		repo := reg.Repository("repo")
+		repo.TagListPageSize = 1
+		err := repo.Tags(ctx, "", func(page []string) error {
+			tags = append(tags, page...)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"v1", "v2"}, tags)
+	})
+
+	t.Run("Catalog", func(t *testing.T) {
+		resp, err := http.Get("http://" + reg.Host() + "/v2/_catalog")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var catalog struct {
+			Repositories []string `json:"repositories"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&catalog))
+		assert.Equal(t, []string{"repo"}, catalog.Repositories)
+	})
+
+	t.Run("Delete Tag", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "http://"+reg.Host()+"/v2/repo/manifests/v2", nil)
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		_, err = repo.Resolve(ctx, "v2")
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+		_, err = repo.Resolve(ctx, "v1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, repo.Delete(ctx, desc))
+		_, err := repo.Resolve(ctx, "v1")
+		assert.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+}
+
+func TestRegistry_Referrers(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "Referrers API"},
+		{name: "Referrers Tag Schema", opts: []Option{WithoutReferrers()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := New(t, tt.opts...)
+			repo := reg.Repository("repo")
+
+			subject := pushManifest(t, repo, []byte("subject"), "application/vnd.subject", nil)
+			sig := pushManifest(t, repo, []byte("signature"), "application/vnd.signature", &subject)
+			_ = pushManifest(t, repo, []byte("sbom"), "application/vnd.sbom", &subject)
+
+			var referrers []ocispec.Descriptor
+			err := repo.Referrers(ctx, subject, "application/vnd.signature", func(page []ocispec.Descriptor) error {
+				referrers = append(referrers, page...)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Len(t, referrers, 1)
+			assert.Equal(t, sig.Digest, referrers[0].Digest)
+			assert.Equal(t, "application/vnd.signature", referrers[0].ArtifactType)
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		reg := New(t, WithoutReferrers())
+		resp, err := http.Get("http://" + reg.Host() + "/v2/repo/referrers/" + digest.FromString("x").String())
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestRegistry_WithoutDeletion(t *testing.T) {
+	ctx := context.Background()
+	reg := New(t, WithoutDeletion())
+	repo := reg.Repository("repo")
+
+	desc := pushManifest(t, repo, []byte("layer"), "application/vnd.test", nil)
+	assert.Error(t, repo.Delete(ctx, desc))
+	_, err := repo.Resolve(ctx, desc.Digest.String())
+	assert.NoError(t, err)
+}
+
+func Test_paginate(t *testing.T) {
+	names := []string{"a", "b", "c"}
+
+	page, next := paginate(names, map[string][]string{"n": {"2"}})
+	assert.Equal(t, []string{"a", "b"}, page)
+	assert.Equal(t, "b", next)
+
+	page, next = paginate(names, map[string][]string{"n": {"2"}, "last": {"b"}})
+	assert.Equal(t, []string{"c"}, page)
+	assert.Empty(t, next)
+}